@@ -13,6 +13,7 @@ flashlight)
 	service_name='flashlight-cr'
 	sentry_dsn_key='flashlight-sentry-dsn'
 	auth_challenge_signing_keys_key='flashlight-auth-challenge-signing-keys'
+	admin_api_keys_key='flashlight-admin-api-keys'
 	environment='production'
 	image_name='flashlight'
 	;;
@@ -20,6 +21,7 @@ flashlight-test)
 	service_name='flashlight-test-cr'
 	sentry_dsn_key='flashlight-test-sentry-dsn'
 	auth_challenge_signing_keys_key='flashlight-test-auth-challenge-signing-keys'
+	admin_api_keys_key='flashlight-test-admin-api-keys'
 	environment='staging'
 	image_name='flashlight-test'
 	;;
@@ -46,6 +48,7 @@ SERVICE_NAME="$service_name" \
 	FLASHLIGHT_ENVIRONMENT="$environment" \
 	SENTRY_DSN_KEY="$sentry_dsn_key" \
 	AUTH_CHALLENGE_SIGNING_KEYS_KEY="$auth_challenge_signing_keys_key" \
+	ADMIN_API_KEYS_KEY="$admin_api_keys_key" \
 	COLLECTOR_IMAGE="$sidecar_image" \
 	envsubst <"$script_dir/service.tmpl.yaml" >"$script_dir/service.yaml"
 
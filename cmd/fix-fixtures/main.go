@@ -19,6 +19,8 @@ import (
 const hypixelAPIResponsesDir = "./fixtures/hypixel_api_responses/"
 const expectedPlayersDir = "./internal/adapters/playerprovider/testdata/expected_players/"
 const expectedHypixelStyleResponsesDir = "./internal/ports/testdata/expected_hypixel_style_responses/"
+const hypixelGuildResponsesDir = "./fixtures/hypixel_guild_responses/"
+const expectedGuildsDir = "./internal/adapters/playerprovider/testdata/expected_guilds/"
 
 func getUUID(ctx context.Context, hypixelAPIResponse []byte) (string, error) {
 	parsedAPIResponse, err := playerprovider.ParseHypixelAPIResponse(ctx, hypixelAPIResponse)
@@ -133,4 +135,41 @@ func main() {
 			continue
 		}
 	}
+
+	guildResponseFiles, err := os.ReadDir(hypixelGuildResponsesDir)
+	if err != nil {
+		log.Fatalf("Error reading hypixel guild responses directory: %s", err.Error())
+	}
+
+	for _, guildResponseFile := range guildResponseFiles {
+		if guildResponseFile.IsDir() {
+			continue
+		}
+
+		fileName := guildResponseFile.Name()
+
+		guildResponse, err := os.ReadFile(path.Join(hypixelGuildResponsesDir, fileName))
+		if err != nil {
+			log.Printf("Error reading hypixel guild response file %s: %s", fileName, err.Error())
+			continue
+		}
+
+		guild, err := playerprovider.HypixelGuildResponseToGuild(ctx, playerQueriedAt, guildResponse, 200)
+		if err != nil {
+			log.Printf("Error parsing hypixel guild response %s: %s", fileName, err.Error())
+			continue
+		}
+
+		// Fix expected guilds
+		guildJSON, err := json.Marshal(guild)
+		if err != nil {
+			log.Printf("Error marshaling guild to JSON: %s", err.Error())
+			continue
+		}
+		err = indentAndWrite(guildJSON, path.Join(expectedGuildsDir, fileName))
+		if err != nil {
+			log.Printf("Error indenting and writing guild JSON: %s", err.Error())
+			continue
+		}
+	}
 }
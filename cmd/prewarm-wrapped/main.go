@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Amund211/flashlight/internal/adapters/database"
+	"github.com/Amund211/flashlight/internal/adapters/playerrepository"
+	"github.com/Amund211/flashlight/internal/adapters/statusrepository"
+	"github.com/Amund211/flashlight/internal/adapters/wrappedrepository"
+	"github.com/Amund211/flashlight/internal/app"
+)
+
+// Computes and stores the wrapped of every active player, so the requests when
+// wrapped is released are served from storage
+func main() {
+	connectionString := flag.String("db", database.LocalConnectionString, "postgres connection string")
+	schema := flag.String("schema", database.MainSchema, "schema of the repositories")
+	year := flag.Int("year", 0, "year to compute wrapped for (default the year of the ongoing wrapped season)")
+	minStats := flag.Int("min-stats", 100, "only include players with at least this many stored stats in the year")
+	concurrency := flag.Int("concurrency", 4, "how many players to compute at once")
+	flag.Parse()
+
+	if *year == 0 {
+		seasonYear, ok := app.WrappedSeason(time.Now())
+		if !ok {
+			log.Fatalf("No wrapped season is ongoing, pass -year")
+		}
+		*year = seasonYear
+	}
+
+	ctx := context.Background()
+
+	db, err := database.NewPostgresDatabase(*connectionString)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	playerRepo := playerrepository.NewPostgresPlayerRepository(db, *schema)
+	wrappedRepo := wrappedrepository.NewPostgres(db, *schema)
+	statusRepo := statusrepository.NewPostgres(db, *schema)
+
+	// Only use the stored stats
+	noopUpdatePlayerInInterval := func(ctx context.Context, uuid string, start, end time.Time) error {
+		return nil
+	}
+	getPlayerPITs := app.BuildGetPlayerPITs(playerRepo, noopUpdatePlayerInInterval)
+	computeSessions := app.BuildComputeSessionsWithOnlineTransitions(
+		app.BuildComputeSessions(time.Now),
+		app.BuildGetOnlineTransitions(statusRepo),
+	)
+	getWrapped := app.BuildGetWrapped(getPlayerPITs, computeSessions, wrappedRepo, time.Now)
+
+	yearStart := time.Date(*year, 1, 1, 0, 0, 0, 0, time.UTC)
+	nextYearStart := time.Date(*year+1, 1, 1, 0, 0, 0, 0, time.UTC)
+	playerUUIDs, err := playerRepo.ListActivePlayers(ctx, yearStart, nextYearStart, *minStats)
+	if err != nil {
+		log.Fatalf("Failed to list active players: %v", err)
+	}
+	log.Printf("Computing wrapped %d for %d players", *year, len(playerUUIDs))
+
+	uuids := make(chan string)
+	var done, failed atomic.Int64
+	var wg sync.WaitGroup
+	for range max(*concurrency, 1) {
+		wg.Go(func() {
+			for uuid := range uuids {
+				_, err := getWrapped(ctx, uuid, *year)
+				if err != nil {
+					failed.Add(1)
+					log.Printf("Failed to compute wrapped for %s: %v", uuid, err)
+				}
+				if count := done.Add(1); count%100 == 0 {
+					log.Printf("Computed %d/%d players", count, len(playerUUIDs))
+				}
+			}
+		})
+	}
+
+	for _, uuid := range playerUUIDs {
+		uuids <- uuid
+	}
+	close(uuids)
+	wg.Wait()
+
+	log.Printf("Computed wrapped for %d players, %d failures", done.Load(), failed.Load())
+}
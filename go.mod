@@ -3,6 +3,7 @@ module github.com/Amund211/flashlight
 go 1.26.6
 
 require (
+	github.com/andybalholm/brotli v1.0.4
 	github.com/getsentry/sentry-go v0.47.0
 	github.com/golang-migrate/migrate/v4 v4.19.1
 	github.com/jellydator/ttlcache/v3 v3.4.1
@@ -0,0 +1,184 @@
+package accountprovider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/Amund211/flashlight/internal/domain"
+)
+
+// validUsernameRx matches the usernames Mojang accepts. Mojang rejects a bulk
+// request with any other username in it.
+var validUsernameRx = regexp.MustCompile(`^[A-Za-z0-9_]{1,16}$`)
+
+type bulkAccountProvider interface {
+	GetAccountByUsername(ctx context.Context, username string) (domain.Account, error)
+	GetAccountsByUsernames(ctx context.Context, usernames []string) (map[string]domain.Account, error)
+}
+
+// UsernameBatcher coalesces concurrent username lookups into bulk requests.
+//
+// Lookups arriving within window of the first lookup in a batch are sent
+// together, and a batch is sent early once it reaches MaxBulkUsernames.
+type UsernameBatcher struct {
+	provider  bulkAccountProvider
+	window    time.Duration
+	afterFunc func(time.Duration) <-chan time.Time
+
+	mu      sync.Mutex
+	pending *usernameBatch
+
+	batchSize metric.Int64Histogram
+}
+
+type usernameBatch struct {
+	// ctx is the context of the first lookup, detached from its cancellation
+	ctx context.Context
+
+	// usernames are lowercased and unique
+	usernames []string
+	full      chan struct{}
+
+	done     chan struct{}
+	accounts map[string]domain.Account
+	// errs holds errors for single usernames when the batch fell back to
+	// individual lookups
+	errs map[string]error
+	err  error
+}
+
+func NewUsernameBatcher(provider bulkAccountProvider, window time.Duration, afterFunc func(time.Duration) <-chan time.Time) (*UsernameBatcher, error) {
+	meter := otel.Meter("flashlight/accountprovider/usernamebatcher")
+
+	batchSize, err := meter.Int64Histogram("accountprovider/username_batcher/batch_size")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create batch size metric: %w", err)
+	}
+
+	return &UsernameBatcher{
+		provider:  provider,
+		window:    window,
+		afterFunc: afterFunc,
+
+		batchSize: batchSize,
+	}, nil
+}
+
+// GetAccountByUsername resolves the username as part of the current batch
+func (b *UsernameBatcher) GetAccountByUsername(ctx context.Context, username string) (domain.Account, error) {
+	if !validUsernameRx.MatchString(username) {
+		// No account can have the username, so don't spoil a batch with it
+		return domain.Account{}, domain.ErrUsernameNotFound
+	}
+
+	key := strings.ToLower(username)
+	batch := b.join(ctx, key)
+
+	select {
+	case <-batch.done:
+	case <-ctx.Done():
+		return domain.Account{}, fmt.Errorf("%w: %w", domain.ErrTemporarilyUnavailable, ctx.Err())
+	}
+
+	if batch.err != nil {
+		return domain.Account{}, batch.err
+	}
+
+	if err, ok := batch.errs[key]; ok {
+		return domain.Account{}, err
+	}
+
+	account, ok := batch.accounts[key]
+	if !ok {
+		return domain.Account{}, domain.ErrUsernameNotFound
+	}
+
+	return account, nil
+}
+
+// join adds the username to the pending batch, starting a new one if needed
+func (b *UsernameBatcher) join(ctx context.Context, key string) *usernameBatch {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.pending == nil {
+		b.pending = &usernameBatch{
+			ctx:       context.WithoutCancel(ctx),
+			usernames: make([]string, 0, MaxBulkUsernames),
+			full:      make(chan struct{}),
+			done:      make(chan struct{}),
+		}
+		go b.run(b.pending)
+	}
+
+	batch := b.pending
+
+	if !slices.Contains(batch.usernames, key) {
+		batch.usernames = append(batch.usernames, key)
+	}
+
+	if len(batch.usernames) >= MaxBulkUsernames {
+		// Later lookups go in a new batch
+		b.pending = nil
+		close(batch.full)
+	}
+
+	return batch
+}
+
+func (b *UsernameBatcher) run(batch *usernameBatch) {
+	select {
+	case <-b.afterFunc(b.window):
+	case <-batch.full:
+	}
+
+	b.mu.Lock()
+	if b.pending == batch {
+		b.pending = nil
+	}
+	usernames := batch.usernames
+	b.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(batch.ctx, 5*time.Second)
+	defer cancel()
+
+	b.batchSize.Record(ctx, int64(len(usernames)))
+
+	batch.accounts, batch.err = b.provider.GetAccountsByUsernames(ctx, usernames)
+	if errors.Is(batch.err, errBulkInputRejected) && len(usernames) > 1 {
+		// One username Mojang doesn't accept fails the whole bulk request, so
+		// fall back to looking up each username on its own. Other errors go to
+		// every lookup, so an unhealthy Mojang doesn't get more requests.
+		// NOTE: The provider handles its own error reporting
+		batch.accounts, batch.errs = b.getIndividually(ctx, usernames)
+		batch.err = nil
+	}
+
+	close(batch.done)
+}
+
+func (b *UsernameBatcher) getIndividually(ctx context.Context, usernames []string) (map[string]domain.Account, map[string]error) {
+	accounts := make(map[string]domain.Account, len(usernames))
+	errs := make(map[string]error)
+	for _, username := range usernames {
+		account, err := b.provider.GetAccountByUsername(ctx, username)
+		if errors.Is(err, domain.ErrUsernameNotFound) {
+			continue
+		} else if err != nil {
+			errs[username] = err
+			continue
+		}
+		accounts[username] = account
+	}
+
+	return accounts, errs
+}
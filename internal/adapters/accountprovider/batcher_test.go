@@ -0,0 +1,266 @@
+package accountprovider
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Amund211/flashlight/internal/domain"
+)
+
+type mockedBulkAccountProvider struct {
+	mu sync.Mutex
+
+	accounts map[string]domain.Account
+	bulkErr  error
+	errs     map[string]error
+
+	bulkCalls   [][]string
+	singleCalls []string
+}
+
+func (m *mockedBulkAccountProvider) GetAccountsByUsernames(ctx context.Context, usernames []string) (map[string]domain.Account, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.bulkCalls = append(m.bulkCalls, slices.Clone(usernames))
+	if m.bulkErr != nil {
+		return nil, m.bulkErr
+	}
+
+	accounts := make(map[string]domain.Account)
+	for _, username := range usernames {
+		if account, ok := m.accounts[username]; ok {
+			accounts[username] = account
+		}
+	}
+	return accounts, nil
+}
+
+func (m *mockedBulkAccountProvider) GetAccountByUsername(ctx context.Context, username string) (domain.Account, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.singleCalls = append(m.singleCalls, username)
+	if err, ok := m.errs[username]; ok {
+		return domain.Account{}, err
+	}
+	if account, ok := m.accounts[username]; ok {
+		return account, nil
+	}
+	return domain.Account{}, domain.ErrUsernameNotFound
+}
+
+func TestUsernameBatcher(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	skydeath := domain.Account{
+		UUID:      "a937646b-f115-44c3-8dbf-9ae4a65669a0",
+		Username:  "Skydeath",
+		QueriedAt: now,
+	}
+	dinnerbone := domain.Account{
+		UUID:      "61699b2e-d327-4a01-9f1e-0ea8c3f06bc6",
+		Username:  "Dinnerbone",
+		QueriedAt: now,
+	}
+
+	type result struct {
+		account domain.Account
+		err     error
+	}
+
+	// lookup starts the lookups concurrently and waits until the pending batch
+	// holds pending usernames
+	lookup := func(t *testing.T, ctx context.Context, batcher *UsernameBatcher, usernames []string, pending int) []chan result {
+		t.Helper()
+
+		results := make([]chan result, len(usernames))
+		for i, username := range usernames {
+			results[i] = make(chan result, 1)
+			go func() {
+				account, err := batcher.GetAccountByUsername(ctx, username)
+				results[i] <- result{account: account, err: err}
+			}()
+		}
+
+		if pending > 0 {
+			require.Eventually(t, func() bool {
+				batcher.mu.Lock()
+				defer batcher.mu.Unlock()
+				return batcher.pending != nil && len(batcher.pending.usernames) == pending
+			}, time.Second, time.Millisecond)
+		}
+
+		return results
+	}
+
+	newBatcher := func(t *testing.T, provider *mockedBulkAccountProvider) (*UsernameBatcher, chan time.Time) {
+		t.Helper()
+
+		timer := make(chan time.Time)
+		batcher, err := NewUsernameBatcher(provider, 50*time.Millisecond, func(d time.Duration) <-chan time.Time {
+			require.Equal(t, 50*time.Millisecond, d)
+			return timer
+		})
+		require.NoError(t, err)
+		return batcher, timer
+	}
+
+	t.Run("coalesces concurrent lookups", func(t *testing.T) {
+		t.Parallel()
+
+		provider := &mockedBulkAccountProvider{
+			accounts: map[string]domain.Account{
+				"skydeath":   skydeath,
+				"dinnerbone": dinnerbone,
+			},
+		}
+		batcher, timer := newBatcher(t, provider)
+
+		results := lookup(t, t.Context(), batcher, []string{"Skydeath", "dinnerbone", "skydeath", "somenickeduser"}, 3)
+
+		timer <- now
+
+		expected := []result{
+			{account: skydeath},
+			{account: dinnerbone},
+			{account: skydeath},
+			{err: domain.ErrUsernameNotFound},
+		}
+		for i, ch := range results {
+			r := <-ch
+			if expected[i].err != nil {
+				require.ErrorIs(t, r.err, expected[i].err)
+				continue
+			}
+			require.NoError(t, r.err)
+			require.Equal(t, expected[i].account, r.account)
+		}
+
+		require.Len(t, provider.bulkCalls, 1)
+		require.ElementsMatch(t, []string{"skydeath", "dinnerbone", "somenickeduser"}, provider.bulkCalls[0])
+		require.Empty(t, provider.singleCalls)
+	})
+
+	t.Run("sends full batches right away", func(t *testing.T) {
+		t.Parallel()
+
+		provider := &mockedBulkAccountProvider{}
+		batcher, _ := newBatcher(t, provider)
+
+		usernames := make([]string, MaxBulkUsernames)
+		for i := range usernames {
+			usernames[i] = fmt.Sprintf("player%d", i)
+		}
+
+		// The timer never fires
+		results := lookup(t, t.Context(), batcher, usernames, 0)
+		for _, ch := range results {
+			require.ErrorIs(t, (<-ch).err, domain.ErrUsernameNotFound)
+		}
+
+		require.Len(t, provider.bulkCalls, 1)
+		require.ElementsMatch(t, usernames, provider.bulkCalls[0])
+	})
+
+	t.Run("falls back to single lookups when the bulk input is rejected", func(t *testing.T) {
+		t.Parallel()
+
+		provider := &mockedBulkAccountProvider{
+			accounts: map[string]domain.Account{
+				"skydeath": skydeath,
+			},
+			bulkErr: fmt.Errorf("%w: invalid profile name", errBulkInputRejected),
+			errs: map[string]error{
+				"broken": assert.AnError,
+			},
+		}
+		batcher, timer := newBatcher(t, provider)
+
+		results := lookup(t, t.Context(), batcher, []string{"skydeath", "Broken", "somenickeduser"}, 3)
+
+		timer <- now
+
+		r := <-results[0]
+		require.NoError(t, r.err)
+		require.Equal(t, skydeath, r.account)
+
+		require.ErrorIs(t, (<-results[1]).err, assert.AnError)
+		require.ErrorIs(t, (<-results[2]).err, domain.ErrUsernameNotFound)
+
+		require.Len(t, provider.bulkCalls, 1)
+		require.ElementsMatch(t, []string{"skydeath", "broken", "somenickeduser"}, provider.singleCalls)
+	})
+
+	t.Run("invalid usernames are not looked up", func(t *testing.T) {
+		t.Parallel()
+
+		provider := &mockedBulkAccountProvider{}
+		batcher, _ := newBatcher(t, provider)
+
+		for _, username := range []string{"§ko01Y", "", "a_very_long_username", "with space", "semi;colon"} {
+			_, err := batcher.GetAccountByUsername(t.Context(), username)
+			require.ErrorIs(t, err, domain.ErrUsernameNotFound)
+		}
+
+		require.Empty(t, provider.bulkCalls)
+		require.Empty(t, provider.singleCalls)
+	})
+
+	for name, bulkErr := range map[string]error{
+		"temporary errors are passed through": fmt.Errorf("%w: mojang down", domain.ErrTemporarilyUnavailable),
+		"other errors are passed through":     assert.AnError,
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			provider := &mockedBulkAccountProvider{
+				bulkErr: bulkErr,
+			}
+			batcher, timer := newBatcher(t, provider)
+
+			results := lookup(t, t.Context(), batcher, []string{"skydeath", "dinnerbone"}, 2)
+
+			timer <- now
+
+			for _, ch := range results {
+				require.ErrorIs(t, (<-ch).err, bulkErr)
+			}
+			require.Len(t, provider.bulkCalls, 1)
+			require.Empty(t, provider.singleCalls)
+		})
+	}
+
+	t.Run("cancelled lookups return early", func(t *testing.T) {
+		t.Parallel()
+
+		provider := &mockedBulkAccountProvider{
+			accounts: map[string]domain.Account{
+				"skydeath": skydeath,
+			},
+		}
+		batcher, timer := newBatcher(t, provider)
+
+		ctx, cancel := context.WithCancel(t.Context())
+		results := lookup(t, ctx, batcher, []string{"skydeath"}, 1)
+		cancel()
+
+		require.ErrorIs(t, (<-results[0]).err, context.Canceled)
+
+		// The batch is still sent for the other lookups in it
+		timer <- now
+		require.Eventually(t, func() bool {
+			provider.mu.Lock()
+			defer provider.mu.Unlock()
+			return len(provider.bulkCalls) == 1
+		}, time.Second, time.Millisecond)
+	})
+}
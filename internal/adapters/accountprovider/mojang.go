@@ -1,6 +1,7 @@
 package accountprovider
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -8,6 +9,7 @@ import (
 	"io"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"go.opentelemetry.io/otel"
@@ -15,6 +17,7 @@ import (
 
 	"github.com/Amund211/flashlight/internal/constants"
 	"github.com/Amund211/flashlight/internal/domain"
+	"github.com/Amund211/flashlight/internal/logging"
 	"github.com/Amund211/flashlight/internal/ratelimiting"
 	"github.com/Amund211/flashlight/internal/reporting"
 	"github.com/Amund211/flashlight/internal/strutils"
@@ -22,6 +25,13 @@ import (
 
 const getAccountMinOperationTime = 150 * time.Millisecond
 
+// MaxBulkUsernames is the most usernames Mojang resolves in one bulk request
+const MaxBulkUsernames = 10
+
+// errBulkInputRejected is returned when Mojang rejects the usernames in a bulk
+// request, e.g. because one of them is not a valid username
+var errBulkInputRejected = errors.New("mojang rejected the bulk request input")
+
 type HTTPClient interface {
 	Do(req *http.Request) (*http.Response, error)
 }
@@ -82,17 +92,91 @@ func (m *Mojang) getProfile(ctx context.Context, url string) (domain.Account, er
 		return domain.Account{}, err
 	}
 
+	resp, data, err := m.send(ctx, req, "MojangAPI.getProfile")
+	if err != nil {
+		return domain.Account{}, err
+	}
+
+	identity, err := accountFromMojangResponse(resp.StatusCode, data, m.nowFunc())
+	if err != nil {
+		if errors.Is(err, domain.ErrUsernameNotFound) {
+			// Pass through error but don't report
+			return domain.Account{}, err
+		}
+
+		err := fmt.Errorf("failed to get identity from mojang response: %w", err)
+		reporting.Report(ctx, err, responseExtra(resp, data))
+		return domain.Account{}, err
+	}
+
+	return identity, nil
+}
+
+// GetAccountsByUsernames resolves up to MaxBulkUsernames usernames in a single
+// request. The result is keyed by the lowercased username. Usernames without
+// an account are left out.
+func (m *Mojang) GetAccountsByUsernames(ctx context.Context, usernames []string) (map[string]domain.Account, error) {
+	ctx, span := m.tracer.Start(ctx, "Mojang.GetAccountsByUsernames")
+	defer span.End()
+
+	if len(usernames) == 0 || len(usernames) > MaxBulkUsernames {
+		err := fmt.Errorf("invalid number of usernames for bulk lookup")
+		reporting.Report(ctx, err, map[string]string{
+			"count": strconv.Itoa(len(usernames)),
+		})
+		return nil, err
+	}
+
+	body, err := json.Marshal(usernames)
+	if err != nil {
+		err := fmt.Errorf("failed to marshal usernames: %w", err)
+		reporting.Report(ctx, err)
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", "https://api.minecraftservices.com/minecraft/profile/lookup/bulk/byname", bytes.NewReader(body))
+	if err != nil {
+		err := fmt.Errorf("failed to create request: %w", err)
+		reporting.Report(ctx, err)
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, data, err := m.send(ctx, req, "MojangAPI.getProfilesByName")
+	if err != nil {
+		return nil, err
+	}
+
+	accounts, err := accountsFromMojangBulkResponse(resp.StatusCode, data, m.nowFunc())
+	if errors.Is(err, errBulkInputRejected) {
+		// Caused by the input -> pass through error but don't report
+		logging.FromContext(ctx).WarnContext(ctx, "Mojang rejected bulk request", "error", err.Error(), "usernames", strings.Join(usernames, ","))
+		return nil, err
+	} else if err != nil {
+		err := fmt.Errorf("failed to get identities from mojang bulk response: %w", err)
+		extra := responseExtra(resp, data)
+		extra["usernames"] = strings.Join(usernames, ",")
+		reporting.Report(ctx, err, extra)
+		return nil, err
+	}
+
+	return accounts, nil
+}
+
+// send performs the request within the rate limits and reads the body
+func (m *Mojang) send(ctx context.Context, req *http.Request, spanName string) (*http.Response, []byte, error) {
 	req.Header.Set("User-Agent", constants.UserAgent)
 
 	var resp *http.Response
 	var data []byte
+	var err error
 	ran := m.limiter.Limit(ctx, getAccountMinOperationTime, func(ctx context.Context) {
-		ctx, span := m.tracer.Start(ctx, "MojangAPI.getProfile")
+		ctx, span := m.tracer.Start(ctx, spanName)
 		defer span.End()
 
 		resp, err = m.httpClient.Do(req) // nolint:bodyclose // Closed via deferred Close inside this closure
 		if err != nil {
-			err := fmt.Errorf("failed to send request: %w", err)
+			err = fmt.Errorf("failed to send request: %w", err)
 			reporting.Report(ctx, err)
 			return
 		}
@@ -100,46 +184,38 @@ func (m *Mojang) getProfile(ctx context.Context, url string) (domain.Account, er
 		defer resp.Body.Close()
 		data, err = io.ReadAll(resp.Body)
 		if err != nil {
-			err := fmt.Errorf("failed to read response body: %w", err)
+			err = fmt.Errorf("failed to read response body: %w", err)
 			reporting.Report(ctx, err)
 			return
 		}
 	})
 	if !ran {
-		return domain.Account{}, fmt.Errorf("%w: too many requests to mojang API", domain.ErrTemporarilyUnavailable)
+		return nil, nil, fmt.Errorf("%w: too many requests to mojang API", domain.ErrTemporarilyUnavailable)
 	}
 
 	if err != nil {
-		return domain.Account{}, err
+		return nil, nil, err
 	}
 
-	identity, err := accountFromMojangResponse(resp.StatusCode, data, m.nowFunc())
-	if err != nil {
-		if errors.Is(err, domain.ErrUsernameNotFound) {
-			// Pass through error but don't report
-			return domain.Account{}, err
-		}
+	return resp, data, nil
+}
 
-		err := fmt.Errorf("failed to get identity from mojang response: %w", err)
-		extra := map[string]string{
-			"data":   string(data),
-			"status": strconv.Itoa(resp.StatusCode),
-		}
-		for header, values := range resp.Header {
-			switch len(values) {
-			case 0:
-				extra["header_"+header] = "<empty slice>"
-			case 1:
-				extra["header_"+header] = values[0]
-			default:
-				extra["header_"+header] = fmt.Sprintf("list: %v", values)
-			}
+func responseExtra(resp *http.Response, data []byte) map[string]string {
+	extra := map[string]string{
+		"data":   string(data),
+		"status": strconv.Itoa(resp.StatusCode),
+	}
+	for header, values := range resp.Header {
+		switch len(values) {
+		case 0:
+			extra["header_"+header] = "<empty slice>"
+		case 1:
+			extra["header_"+header] = values[0]
+		default:
+			extra["header_"+header] = fmt.Sprintf("list: %v", values)
 		}
-		reporting.Report(ctx, err, extra)
-		return domain.Account{}, err
 	}
-
-	return identity, nil
+	return extra
 }
 
 type mojangResponse struct {
@@ -183,3 +259,43 @@ func accountFromMojangResponse(statusCode int, data []byte, queriedAt time.Time)
 		QueriedAt: queriedAt,
 	}, nil
 }
+
+func accountsFromMojangBulkResponse(statusCode int, data []byte, queriedAt time.Time) (map[string]domain.Account, error) {
+	switch statusCode {
+	case http.StatusTooManyRequests,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return nil, fmt.Errorf("%w: mojang API returned status code %d", domain.ErrTemporarilyUnavailable, statusCode)
+	case http.StatusBadRequest:
+		return nil, fmt.Errorf("%w: %s", errBulkInputRejected, string(data))
+	}
+
+	if statusCode != http.StatusOK {
+		var response mojangResponse
+		if err := json.Unmarshal(data, &response); err != nil {
+			return nil, fmt.Errorf("mojang API returned status code %d", statusCode)
+		}
+		return nil, fmt.Errorf("mojang API returned error response (status %d): error=%q errorMessage=%q", statusCode, response.Error, response.ErrorMessage)
+	}
+
+	var response []mojangResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse mojang bulk response: %w", err)
+	}
+
+	accounts := make(map[string]domain.Account, len(response))
+	for _, profile := range response {
+		uuid, err := strutils.NormalizeUUID(profile.UUID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to normalize UUID from mojang: %w", err)
+		}
+
+		accounts[strings.ToLower(profile.Username)] = domain.Account{
+			Username:  profile.Username,
+			UUID:      uuid,
+			QueriedAt: queriedAt,
+		}
+	}
+
+	return accounts, nil
+}
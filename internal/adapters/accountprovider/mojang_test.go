@@ -156,6 +156,100 @@ func TestAccountFromMojangResponseSurfacesErrorMessage(t *testing.T) {
 	require.NotContains(t, err.Error(), "failed to normalize UUID")
 }
 
+func TestAccountsFromMojangBulkResponse(t *testing.T) {
+	t.Parallel()
+
+	queriedAt := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		response   []byte
+		statusCode int
+		expected   map[string]domain.Account
+		err        error
+	}{
+		{
+			name: "some found",
+			response: []byte(`[
+  {
+    "id" : "a937646bf11544c38dbf9ae4a65669a0",
+    "name" : "Skydeath"
+  },
+  {
+    "id" : "61699b2ed3274a019f1e0ea8c3f06bc6",
+    "name" : "Dinnerbone"
+  }
+]`),
+			statusCode: 200,
+			expected: map[string]domain.Account{
+				"skydeath": {
+					UUID:      "a937646b-f115-44c3-8dbf-9ae4a65669a0",
+					Username:  "Skydeath",
+					QueriedAt: queriedAt,
+				},
+				"dinnerbone": {
+					UUID:      "61699b2e-d327-4a01-9f1e-0ea8c3f06bc6",
+					Username:  "Dinnerbone",
+					QueriedAt: queriedAt,
+				},
+			},
+		},
+		{
+			name:       "none found",
+			response:   []byte(`[]`),
+			statusCode: 200,
+			expected:   map[string]domain.Account{},
+		},
+		{
+			name:       "429",
+			response:   []byte(``),
+			statusCode: 429,
+			err:        domain.ErrTemporarilyUnavailable,
+		},
+		{
+			name: "400 invalid username",
+			response: []byte(`{
+  "path" : "/minecraft/profile/lookup/bulk/byname",
+  "error" : "CONSTRAINT_VIOLATION",
+  "errorMessage" : "getProfileIdsByName.names[0].<list element>: Invalid profile name"
+}`),
+			statusCode: 400,
+			err:        errBulkInputRejected,
+		},
+		{
+			name:       "invalid uuid",
+			response:   []byte(`[{"id": "invalid", "name": "Skydeath"}]`),
+			statusCode: 200,
+			err:        assert.AnError,
+		},
+		{
+			name:       "Invalid JSON",
+			response:   []byte(`[{"id":"invalid-json"`),
+			statusCode: 200,
+			err:        assert.AnError,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			accounts, err := accountsFromMojangBulkResponse(tc.statusCode, tc.response, queriedAt)
+			if tc.err != nil {
+				if errors.Is(tc.err, assert.AnError) {
+					require.Error(t, err)
+				} else {
+					require.ErrorIs(t, err, tc.err)
+				}
+				return
+			}
+			require.NoError(t, err)
+
+			require.Equal(t, tc.expected, accounts)
+		})
+	}
+}
+
 type mockedClient struct {
 	responseData []byte
 	statusCode   int
@@ -216,4 +310,20 @@ func TestMojang(t *testing.T) {
 
 	_, err = provider.GetAccountByUsername(ctx, "skydeath")
 	require.ErrorIs(t, err, assert.AnError)
+
+	client.err = nil
+	client.responseData = []byte(`[{"id": "a937646bf11544c38dbf9ae4a65669a0", "name": "Skydeath"}]`)
+
+	accounts, err := provider.GetAccountsByUsernames(ctx, []string{"skydeath", "somenickeduser"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]domain.Account{
+		"skydeath": {
+			UUID:      "a937646b-f115-44c3-8dbf-9ae4a65669a0",
+			Username:  "Skydeath",
+			QueriedAt: now,
+		},
+	}, accounts)
+
+	_, err = provider.GetAccountsByUsernames(ctx, make([]string, MaxBulkUsernames+1))
+	require.Error(t, err)
 }
@@ -42,9 +42,10 @@ type dbUsernamesEntry struct {
 }
 
 type dbUsernameQueriesEntry struct {
-	PlayerUUID    string    `db:"player_uuid"`
-	Username      string    `db:"username"`
-	LastQueriedAt time.Time `db:"last_queried_at"`
+	PlayerUUID     string    `db:"player_uuid"`
+	Username       string    `db:"username"`
+	FirstQueriedAt time.Time `db:"first_queried_at"`
+	LastQueriedAt  time.Time `db:"last_queried_at"`
 }
 
 func (p *Postgres) StoreAccount(ctx context.Context, account domain.Account) error {
@@ -77,14 +78,16 @@ func (p *Postgres) StoreAccount(ctx context.Context, account domain.Account) err
 	}
 
 	// Insert or update entry in username_queries table
+	// NOTE: Widen the observed interval, as stores can arrive out of order
 	_, err = txx.ExecContext(
 		ctx,
 		`INSERT INTO username_queries
-		(player_uuid, username, last_queried_at)
-		VALUES ($1, $2, $3)
+		(player_uuid, username, first_queried_at, last_queried_at)
+		VALUES ($1, $2, $3, $3)
 		ON CONFLICT (player_uuid, username)
 		DO UPDATE SET
-			last_queried_at = EXCLUDED.last_queried_at`,
+			first_queried_at = LEAST(username_queries.first_queried_at, EXCLUDED.first_queried_at),
+			last_queried_at = GREATEST(username_queries.last_queried_at, EXCLUDED.last_queried_at)`,
 		account.UUID,
 		account.Username,
 		account.QueriedAt,
@@ -262,3 +265,78 @@ func (p *Postgres) GetAccountByUsername(ctx context.Context, username string) (d
 		QueriedAt: entry.QueriedAt.UTC(),
 	}, nil
 }
+
+// GetUsernameHistory returns every username seen on the account, ordered by
+// when it was first seen
+func (p *Postgres) GetUsernameHistory(ctx context.Context, uuid string) ([]domain.UsernameObservation, error) {
+	ctx, span := p.tracer.Start(ctx, "Postgres.GetUsernameHistory")
+	defer span.End()
+
+	if !strutils.UUIDIsNormalized(uuid) {
+		err := fmt.Errorf("uuid is not normalized")
+		reporting.Report(ctx, err, map[string]string{
+			"uuid": uuid,
+		})
+		return nil, err
+	}
+
+	var entries []dbUsernameQueriesEntry
+	err := p.db.SelectContext(ctx, &entries, fmt.Sprintf(`SELECT
+		player_uuid, username, first_queried_at, last_queried_at
+		FROM %s.username_queries
+		WHERE player_uuid = $1
+		ORDER BY first_queried_at ASC`,
+		pq.QuoteIdentifier(p.schema),
+	),
+		uuid,
+	)
+	if err != nil {
+		err := fmt.Errorf("failed to select username_queries entries: %w", err)
+		reporting.Report(ctx, err, map[string]string{
+			"uuid": uuid,
+		})
+		return nil, err
+	}
+
+	return usernameQueriesEntriesToObservations(entries), nil
+}
+
+// GetUsernameObservations returns every account seen with the username (case
+// insensitive), ordered by when it was first seen with it
+func (p *Postgres) GetUsernameObservations(ctx context.Context, username string) ([]domain.UsernameObservation, error) {
+	ctx, span := p.tracer.Start(ctx, "Postgres.GetUsernameObservations")
+	defer span.End()
+
+	var entries []dbUsernameQueriesEntry
+	err := p.db.SelectContext(ctx, &entries, fmt.Sprintf(`SELECT
+		player_uuid, username, first_queried_at, last_queried_at
+		FROM %s.username_queries
+		WHERE lower(username) = lower($1)
+		ORDER BY first_queried_at ASC`,
+		pq.QuoteIdentifier(p.schema),
+	),
+		username,
+	)
+	if err != nil {
+		err := fmt.Errorf("failed to select username_queries entries: %w", err)
+		reporting.Report(ctx, err, map[string]string{
+			"username": username,
+		})
+		return nil, err
+	}
+
+	return usernameQueriesEntriesToObservations(entries), nil
+}
+
+func usernameQueriesEntriesToObservations(entries []dbUsernameQueriesEntry) []domain.UsernameObservation {
+	observations := make([]domain.UsernameObservation, 0, len(entries))
+	for _, entry := range entries {
+		observations = append(observations, domain.UsernameObservation{
+			UUID:      entry.PlayerUUID,
+			Username:  entry.Username,
+			FirstSeen: entry.FirstQueriedAt.UTC(),
+			LastSeen:  entry.LastQueriedAt.UTC(),
+		})
+	}
+	return observations
+}
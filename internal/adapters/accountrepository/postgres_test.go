@@ -733,4 +733,85 @@ func TestPostgres(t *testing.T) {
 			require.WithinDuration(t, now.Add(-2*time.Hour), account.QueriedAt, 1*time.Millisecond)
 		})
 	})
+	t.Run("username history", func(t *testing.T) {
+		t.Parallel()
+		p := newPostgres(t, db, "username_history")
+
+		t1 := now.Add(-72 * time.Hour).Truncate(time.Millisecond).UTC()
+		t2 := now.Add(-48 * time.Hour).Truncate(time.Millisecond).UTC()
+		t3 := now.Add(-24 * time.Hour).Truncate(time.Millisecond).UTC()
+		t4 := now.Truncate(time.Millisecond).UTC()
+
+		store := func(uuid, username string, queriedAt time.Time) {
+			err := p.StoreAccount(ctx, domain.Account{
+				UUID:      uuid,
+				Username:  username,
+				QueriedAt: queriedAt,
+			})
+			require.NoError(t, err)
+		}
+
+		// Leto goes by Muad'Dib for a while, after which Ghanima takes the name
+		store(makeUUID(1), "Leto", t1)
+		store(makeUUID(1), "MuadDib", t2)
+		store(makeUUID(1), "Leto", t3)
+		store(makeUUID(2), "muaddib", t4)
+		// Arriving out of order only widens the observed interval
+		store(makeUUID(1), "Leto", t2)
+
+		t.Run("GetUsernameHistory", func(t *testing.T) {
+			t.Parallel()
+
+			history, err := p.GetUsernameHistory(ctx, makeUUID(1))
+			require.NoError(t, err)
+			require.Equal(t, []domain.UsernameObservation{
+				{UUID: makeUUID(1), Username: "Leto", FirstSeen: t1, LastSeen: t3},
+				{UUID: makeUUID(1), Username: "MuadDib", FirstSeen: t2, LastSeen: t2},
+			}, history)
+		})
+
+		t.Run("GetUsernameHistory missing", func(t *testing.T) {
+			t.Parallel()
+
+			history, err := p.GetUsernameHistory(ctx, makeUUID(123))
+			require.NoError(t, err)
+			require.Empty(t, history)
+		})
+
+		t.Run("GetUsernameObservations", func(t *testing.T) {
+			t.Parallel()
+
+			observations, err := p.GetUsernameObservations(ctx, "MUADDIB")
+			require.NoError(t, err)
+			require.Equal(t, []domain.UsernameObservation{
+				{UUID: makeUUID(1), Username: "MuadDib", FirstSeen: t2, LastSeen: t2},
+				{UUID: makeUUID(2), Username: "muaddib", FirstSeen: t4, LastSeen: t4},
+			}, observations)
+		})
+
+		t.Run("history survives RemoveUsername", func(t *testing.T) {
+			t.Parallel()
+
+			p := newPostgres(t, db, "username_history_remove")
+
+			err := p.StoreAccount(ctx, domain.Account{
+				UUID:      makeUUID(1),
+				Username:  "Siona",
+				QueriedAt: t1,
+			})
+			require.NoError(t, err)
+
+			err = p.RemoveUsername(ctx, "siona")
+			require.NoError(t, err)
+
+			_, err = p.GetAccountByUsername(ctx, "Siona")
+			require.ErrorIs(t, err, domain.ErrUsernameNotFound)
+
+			observations, err := p.GetUsernameObservations(ctx, "Siona")
+			require.NoError(t, err)
+			require.Equal(t, []domain.UsernameObservation{
+				{UUID: makeUUID(1), Username: "Siona", FirstSeen: t1, LastSeen: t1},
+			}, observations)
+		})
+	})
 }
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS prism_notices;
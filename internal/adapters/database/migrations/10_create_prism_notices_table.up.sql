@@ -0,0 +1,53 @@
+CREATE TABLE IF NOT EXISTS prism_notices (
+    id                  TEXT PRIMARY KEY,
+    message             TEXT NOT NULL,
+    url                 TEXT NOT NULL,
+    severity            TEXT NOT NULL,
+    duration_seconds    DOUBLE PRECISION,
+    active_from         TIMESTAMPTZ,
+    active_until        TIMESTAMPTZ,
+    target_user_ids     TEXT[] NOT NULL,
+    min_prism_version   TEXT NOT NULL,
+    max_prism_version   TEXT NOT NULL,
+    target_client_types TEXT[] NOT NULL,
+    rollout_percentage  INTEGER NOT NULL,
+    created_at          TIMESTAMPTZ NOT NULL,
+    updated_at          TIMESTAMPTZ NOT NULL
+);
+
+-- The notice that used to be hardcoded in app.BuildGetPrismNotices. The
+-- wrapped notice is seasonal, so it is still computed there.
+INSERT INTO prism_notices
+    (id, message, url, severity, duration_seconds, active_from, active_until,
+     target_user_ids, min_prism_version, max_prism_version, target_client_types, rollout_percentage,
+     created_at, updated_at)
+VALUES
+    (
+        'b6c0f5a4-4f7e-4d1c-9a51-0c6f2d8e7a10',
+        E'We''ve detected a potential issue with your Prism client.\nPlease click here to create a ticket in the discord server',
+        'https://discord.gg/NGpRrdh6Fx',
+        'warning',
+        120,
+        NULL,
+        NULL,
+        ARRAY[
+            'b1b6ead3b357467298c0a186a891940f',
+            'e104fb8b4b8a4a40ba70334e8239c0e1',
+            'b3c71ddfb808414d80e932110dae5716',
+            '9c90ae7b927347a787ddb9c9e85cca16',
+            'a3ec8094a2bb427f81c11faadb33c2ba',
+            'ea2aa5221a614dc1a502f01e33f4ceaa',
+            '47e7859bb33246ef8494fb81a9ac4e01',
+            '426d836cdc7740bd9ff887d1d8a358f3',
+            '3eedaf7ed5964d8981835b8f0de2c9d4',
+            'bb683d98dc634a5783be9a4895ab75af',
+            'a55dfa5ddaa7426b87f2a5dbc3ad5254'
+        ]::TEXT[],
+        '',
+        '',
+        ARRAY[]::TEXT[],
+        100,
+        NOW(),
+        NOW()
+    )
+ON CONFLICT (id) DO NOTHING;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS prism_notice_views;
+
+ALTER TABLE prism_notices DROP COLUMN IF EXISTS frequency;
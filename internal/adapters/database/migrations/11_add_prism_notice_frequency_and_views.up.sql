@@ -0,0 +1,20 @@
+ALTER TABLE prism_notices
+    ADD COLUMN IF NOT EXISTS frequency TEXT NOT NULL DEFAULT 'always';
+
+-- Prism showed this on every launch. The warning stays until the user has
+-- dealt with it, but once a day is enough of a reminder.
+UPDATE prism_notices SET frequency = 'daily'
+ WHERE id = 'b6c0f5a4-4f7e-4d1c-9a51-0c6f2d8e7a10';
+
+-- One row per user and notice they have been shown or have acknowledged.
+-- Only written for notices with a frequency other than 'always'.
+CREATE TABLE IF NOT EXISTS prism_notice_views (
+    user_id         TEXT NOT NULL,
+    notice_id       TEXT NOT NULL REFERENCES prism_notices (id) ON DELETE CASCADE,
+    last_shown_at   TIMESTAMPTZ,
+    acknowledged_at TIMESTAMPTZ,
+    PRIMARY KEY (user_id, notice_id)
+);
+
+CREATE INDEX IF NOT EXISTS prism_notice_views_notice_id
+    ON prism_notice_views (notice_id);
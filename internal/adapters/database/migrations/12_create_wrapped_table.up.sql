@@ -0,0 +1,11 @@
+-- Wrapped computations. Completed years hold the finished state, the ongoing
+-- year holds the state its computation resumes from.
+CREATE TABLE IF NOT EXISTS wrapped (
+    player_uuid TEXT NOT NULL,
+    year        INTEGER NOT NULL,
+    version     INTEGER NOT NULL,
+    complete    BOOLEAN NOT NULL,
+    data        JSONB NOT NULL,
+    computed_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (player_uuid, year, version)
+);
@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_guilds_guild_id_and_queried_at;
+
+DROP TABLE IF EXISTS guilds;
+
+COMMIT;
@@ -0,0 +1,15 @@
+BEGIN;
+
+-- Guild snapshots. A new row is only stored when the guild has changed since
+-- its previous snapshot.
+CREATE TABLE IF NOT EXISTS guilds (
+    id TEXT PRIMARY KEY,
+    guild_id TEXT NOT NULL,
+    queried_at timestamptz NOT NULL,
+    data_format_version INTEGER NOT NULL,
+    guild_data JSONB NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_guilds_guild_id_and_queried_at ON guilds (guild_id, queried_at);
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_username_queries_username_lowercase;
+
+ALTER TABLE username_queries DROP COLUMN IF EXISTS first_queried_at;
+
+COMMIT;
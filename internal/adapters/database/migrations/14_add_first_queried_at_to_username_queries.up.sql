@@ -0,0 +1,13 @@
+BEGIN;
+
+-- username_queries doubles as the username history of each account. Rows
+-- stored before this only have their latest observation.
+ALTER TABLE username_queries ADD COLUMN IF NOT EXISTS first_queried_at timestamptz;
+
+UPDATE username_queries SET first_queried_at = last_queried_at WHERE first_queried_at IS NULL;
+
+ALTER TABLE username_queries ALTER COLUMN first_queried_at SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_username_queries_username_lowercase ON username_queries (lower(username));
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_online_transitions_player_uuid_and_observed_at;
+
+DROP TABLE IF EXISTS online_transitions;
+
+COMMIT;
@@ -0,0 +1,14 @@
+BEGIN;
+
+-- Observed changes in players' online status. A row is only stored when the
+-- status differs from the player's previous row.
+CREATE TABLE IF NOT EXISTS online_transitions (
+    id TEXT PRIMARY KEY,
+    player_uuid TEXT NOT NULL,
+    observed_at timestamptz NOT NULL,
+    online BOOLEAN NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_online_transitions_player_uuid_and_observed_at ON online_transitions (player_uuid, observed_at);
+
+COMMIT;
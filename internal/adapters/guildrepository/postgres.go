@@ -0,0 +1,274 @@
+package guildrepository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/Amund211/flashlight/internal/domain"
+	"github.com/Amund211/flashlight/internal/logging"
+	"github.com/Amund211/flashlight/internal/reporting"
+	"github.com/Amund211/flashlight/internal/strutils"
+)
+
+const dataFormatVersion = 1
+
+type Postgres struct {
+	db     *sqlx.DB
+	schema string
+	tracer trace.Tracer
+}
+
+func NewPostgres(db *sqlx.DB, schema string) *Postgres {
+	return &Postgres{
+		db:     db,
+		schema: schema,
+		tracer: otel.Tracer("flashlight/guildrepository/postgres"),
+	}
+}
+
+type guildMemberDataStorage struct {
+	UUID   string     `json:"uuid"`
+	Rank   string     `json:"rank"`
+	Joined *time.Time `json:"joined,omitempty"`
+}
+
+type guildDataStorage struct {
+	Name     string                   `json:"name"`
+	Tag      *string                  `json:"tag,omitempty"`
+	TagColor *string                  `json:"tagColor,omitempty"`
+	Members  []guildMemberDataStorage `json:"members"`
+}
+
+type dbGuild struct {
+	ID                string    `db:"id"`
+	GuildID           string    `db:"guild_id"`
+	QueriedAt         time.Time `db:"queried_at"`
+	DataFormatVersion int       `db:"data_format_version"`
+	GuildData         []byte    `db:"guild_data"`
+}
+
+func guildToDataStorage(guild *domain.Guild) ([]byte, error) {
+	members := make([]guildMemberDataStorage, 0, len(guild.Members))
+	for _, member := range guild.Members {
+		members = append(members, guildMemberDataStorage{
+			UUID:   member.UUID,
+			Rank:   member.Rank,
+			Joined: member.Joined,
+		})
+	}
+
+	return json.Marshal(guildDataStorage{
+		Name:     guild.Name,
+		Tag:      guild.Tag,
+		TagColor: guild.TagColor,
+		Members:  members,
+	})
+}
+
+func dbGuildToGuild(row dbGuild) (domain.Guild, error) {
+	if row.DataFormatVersion != dataFormatVersion {
+		return domain.Guild{}, fmt.Errorf("unsupported data format version: %d", row.DataFormatVersion)
+	}
+
+	var data guildDataStorage
+	if err := json.Unmarshal(row.GuildData, &data); err != nil {
+		return domain.Guild{}, fmt.Errorf("failed to unmarshal guild data: %w", err)
+	}
+
+	members := make([]domain.GuildMember, 0, len(data.Members))
+	for _, member := range data.Members {
+		var joined *time.Time
+		if member.Joined != nil {
+			joined = new(member.Joined.UTC())
+		}
+		members = append(members, domain.GuildMember{
+			UUID:   member.UUID,
+			Rank:   member.Rank,
+			Joined: joined,
+		})
+	}
+
+	return domain.Guild{
+		ID:        row.GuildID,
+		QueriedAt: row.QueriedAt.UTC(),
+		Name:      data.Name,
+		Tag:       data.Tag,
+		TagColor:  data.TagColor,
+		Members:   members,
+	}, nil
+}
+
+// StoreGuild stores a snapshot of the guild, unless it is unchanged since the
+// most recently stored snapshot.
+func (p *Postgres) StoreGuild(ctx context.Context, guild *domain.Guild) error {
+	ctx, span := p.tracer.Start(ctx, "Postgres.StoreGuild")
+	defer span.End()
+
+	if guild == nil {
+		err := fmt.Errorf("guild is nil")
+		reporting.Report(ctx, err)
+		return err
+	}
+
+	if guild.ID == "" {
+		err := fmt.Errorf("guild id is empty")
+		reporting.Report(ctx, err)
+		return err
+	}
+
+	for _, member := range guild.Members {
+		if !strutils.UUIDIsNormalized(member.UUID) {
+			err := fmt.Errorf("member uuid is not normalized")
+			reporting.Report(ctx, err, map[string]string{
+				"guildID": guild.ID,
+				"uuid":    member.UUID,
+			})
+			return err
+		}
+	}
+
+	guildData, err := guildToDataStorage(guild)
+	if err != nil {
+		err := fmt.Errorf("failed to convert guild to data storage: %w", err)
+		reporting.Report(ctx, err)
+		return err
+	}
+
+	dbID, err := uuid.NewV7()
+	if err != nil {
+		err := fmt.Errorf("failed to generate db id: %w", err)
+		reporting.Report(ctx, err)
+		return err
+	}
+
+	txx, err := p.db.BeginTxx(ctx, nil)
+	if err != nil {
+		err := fmt.Errorf("failed to start transaction: %w", err)
+		reporting.Report(ctx, err)
+		return err
+	}
+	defer txx.Rollback()
+
+	_, err = txx.ExecContext(ctx, fmt.Sprintf("SET search_path TO %s", pq.QuoteIdentifier(p.schema)))
+	if err != nil {
+		err := fmt.Errorf("failed to set search path: %w", err)
+		reporting.Report(ctx, err, map[string]string{
+			"schema": p.schema,
+		})
+		return err
+	}
+
+	// Don't store consecutive duplicate snapshots
+	var lastGuildData []byte
+	var lastDataFormatVersion int
+	err = txx.QueryRowxContext(
+		ctx,
+		`SELECT
+			data_format_version, guild_data
+		FROM guilds
+		WHERE guild_id = $1
+		ORDER BY queried_at DESC LIMIT 1`,
+		guild.ID,
+	).Scan(&lastDataFormatVersion, &lastGuildData)
+	if err == nil {
+		if lastDataFormatVersion == dataFormatVersion {
+			equal, err := strutils.JSONStringsEqual(guildData, lastGuildData)
+			if err != nil {
+				err := fmt.Errorf("failed to compare guild data to previously stored data: %w", err)
+				reporting.Report(ctx, err, map[string]string{
+					"guildData":     string(guildData),
+					"lastGuildData": string(lastGuildData),
+				})
+				return err
+			}
+			if equal {
+				// Unchanged -> don't store
+				return nil
+			}
+		}
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		err := fmt.Errorf("failed to query last guild data: %w", err)
+		reporting.Report(ctx, err)
+		return err
+	}
+
+	_, err = txx.ExecContext(
+		ctx,
+		`INSERT INTO guilds
+		(id, guild_id, queried_at, data_format_version, guild_data)
+		VALUES ($1, $2, $3, $4, $5)`,
+		dbID.String(),
+		guild.ID,
+		guild.QueriedAt,
+		dataFormatVersion,
+		guildData,
+	)
+	if err != nil {
+		err := fmt.Errorf("failed to insert guild: %w", err)
+		reporting.Report(ctx, err)
+		return err
+	}
+
+	err = txx.Commit()
+	if err != nil {
+		err := fmt.Errorf("failed to commit transaction: %w", err)
+		reporting.Report(ctx, err)
+		return err
+	}
+
+	logging.FromContext(ctx).InfoContext(ctx, "Stored guild", "guildID", guild.ID, "dataFormatVersion", dataFormatVersion)
+
+	return nil
+}
+
+// GetGuildHistory returns the stored snapshots of the guild in [start, end],
+// oldest first
+func (p *Postgres) GetGuildHistory(ctx context.Context, guildID string, start, end time.Time) ([]domain.Guild, error) {
+	ctx, span := p.tracer.Start(ctx, "Postgres.GetGuildHistory")
+	defer span.End()
+
+	var rows []dbGuild
+	err := p.db.SelectContext(
+		ctx,
+		&rows,
+		fmt.Sprintf(`SELECT id, guild_id, queried_at, data_format_version, guild_data
+		FROM %s.guilds
+		WHERE guild_id = $1 AND queried_at BETWEEN $2 AND $3
+		ORDER BY queried_at ASC`,
+			pq.QuoteIdentifier(p.schema)),
+		guildID, start, end,
+	)
+	if err != nil {
+		err := fmt.Errorf("failed to select guilds: %w", err)
+		reporting.Report(ctx, err, map[string]string{
+			"guildID": guildID,
+		})
+		return nil, err
+	}
+
+	guilds := make([]domain.Guild, 0, len(rows))
+	for _, row := range rows {
+		guild, err := dbGuildToGuild(row)
+		if err != nil {
+			err := fmt.Errorf("failed to convert stored guild: %w", err)
+			reporting.Report(ctx, err, map[string]string{
+				"guildID": guildID,
+				"id":      row.ID,
+			})
+			return nil, err
+		}
+		guilds = append(guilds, guild)
+	}
+
+	return guilds, nil
+}
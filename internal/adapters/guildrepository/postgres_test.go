@@ -0,0 +1,125 @@
+package guildrepository
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Amund211/flashlight/internal/adapters/database"
+	"github.com/Amund211/flashlight/internal/domain"
+	"github.com/Amund211/flashlight/internal/domaintest"
+)
+
+func newPostgres(t *testing.T, db *sqlx.DB, schemaSuffix string) *Postgres {
+	require.NotEmpty(t, schemaSuffix, "schemaSuffix must not be empty")
+	schema := fmt.Sprintf("guild_repo_test_%s", schemaSuffix)
+
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+	db.MustExec(fmt.Sprintf("DROP SCHEMA IF EXISTS %s CASCADE", pq.QuoteIdentifier(schema)))
+
+	migrator := database.NewDatabaseMigrator(db, logger)
+
+	err := migrator.Migrate(t.Context(), schema)
+	require.NoError(t, err)
+
+	return NewPostgres(db, schema)
+}
+
+func TestPostgresGuilds(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping db tests in short mode.")
+	}
+	t.Parallel()
+
+	db, err := database.NewPostgresDatabase(database.LocalConnectionString)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	p := newPostgres(t, db, "guilds")
+
+	now := time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC)
+
+	makeGuild := func(t *testing.T, id string, queriedAt time.Time, ranks ...string) *domain.Guild {
+		t.Helper()
+
+		members := make([]domain.GuildMember, 0, len(ranks))
+		for _, rank := range ranks {
+			members = append(members, domain.GuildMember{
+				UUID:   domaintest.NewUUID(t),
+				Rank:   rank,
+				Joined: new(now.Add(-24 * time.Hour)),
+			})
+		}
+		return &domain.Guild{
+			ID:        id,
+			QueriedAt: queriedAt,
+			Name:      "Guild " + id,
+			Tag:       new("TAG"),
+			TagColor:  new("DARK_AQUA"),
+			Members:   members,
+		}
+	}
+
+	t.Run("store and get history", func(t *testing.T) {
+		t.Parallel()
+		ctx := t.Context()
+
+		first := makeGuild(t, "history", now, "Guild Master", "Member")
+		require.NoError(t, p.StoreGuild(ctx, first))
+
+		// Unchanged -> not stored
+		unchanged := *first
+		unchanged.QueriedAt = now.Add(time.Hour)
+		require.NoError(t, p.StoreGuild(ctx, &unchanged))
+
+		// Changed -> stored
+		changed := *first
+		changed.QueriedAt = now.Add(2 * time.Hour)
+		changed.Members = first.Members[:1]
+		require.NoError(t, p.StoreGuild(ctx, &changed))
+
+		history, err := p.GetGuildHistory(ctx, "history", now.Add(-time.Hour), now.Add(3*time.Hour))
+		require.NoError(t, err)
+		require.Equal(t, []domain.Guild{*first, changed}, history)
+
+		history, err = p.GetGuildHistory(ctx, "history", now.Add(time.Hour), now.Add(3*time.Hour))
+		require.NoError(t, err)
+		require.Equal(t, []domain.Guild{changed}, history)
+	})
+
+	t.Run("guilds are separate", func(t *testing.T) {
+		t.Parallel()
+		ctx := t.Context()
+
+		require.NoError(t, p.StoreGuild(ctx, makeGuild(t, "a", now, "Guild Master")))
+		require.NoError(t, p.StoreGuild(ctx, makeGuild(t, "b", now, "Guild Master")))
+
+		history, err := p.GetGuildHistory(ctx, "a", now.Add(-time.Hour), now.Add(time.Hour))
+		require.NoError(t, err)
+		require.Len(t, history, 1)
+		require.Equal(t, "a", history[0].ID)
+
+		history, err = p.GetGuildHistory(ctx, "missing", now.Add(-time.Hour), now.Add(time.Hour))
+		require.NoError(t, err)
+		require.Empty(t, history)
+	})
+
+	t.Run("rejects invalid guilds", func(t *testing.T) {
+		t.Parallel()
+		ctx := t.Context()
+
+		require.Error(t, p.StoreGuild(ctx, nil))
+		require.Error(t, p.StoreGuild(ctx, makeGuild(t, "", now)))
+
+		guild := makeGuild(t, "invalid", now, "Member")
+		guild.Members[0].UUID = "not-a-uuid"
+		require.Error(t, p.StoreGuild(ctx, guild))
+	})
+}
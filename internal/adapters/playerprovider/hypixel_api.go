@@ -33,6 +33,8 @@ type RequestLimiter interface {
 
 type HypixelAPI interface {
 	GetPlayerData(ctx context.Context, uuid string) ([]byte, int, time.Time, error)
+	GetStatus(ctx context.Context, uuid string) ([]byte, int, time.Time, error)
+	GetGuildByPlayer(ctx context.Context, uuid string) ([]byte, int, time.Time, error)
 }
 
 type hypixelAPIMetricsCollection struct {
@@ -80,6 +82,14 @@ func (hypixelAPI *mockedHypixelAPI) GetPlayerData(ctx context.Context, uuid stri
 	return []byte(fmt.Sprintf(`{"success":true,"player":{"uuid":"%s"}}`, uuid)), 200, time.Now(), nil
 }
 
+func (hypixelAPI *mockedHypixelAPI) GetStatus(ctx context.Context, uuid string) ([]byte, int, time.Time, error) {
+	return []byte(fmt.Sprintf(`{"success":true,"uuid":"%s","session":{"online":false}}`, uuid)), 200, time.Now(), nil
+}
+
+func (hypixelAPI *mockedHypixelAPI) GetGuildByPlayer(ctx context.Context, uuid string) ([]byte, int, time.Time, error) {
+	return []byte(`{"success":true,"guild":null}`), 200, time.Now(), nil
+}
+
 type hypixelAPIImpl struct {
 	httpClient HTTPClient
 	limiter    RequestLimiter
@@ -94,8 +104,26 @@ func (hypixelAPI hypixelAPIImpl) GetPlayerData(ctx context.Context, uuid string)
 	ctx, span := hypixelAPI.tracer.Start(ctx, "HypixelAPI.GetPlayerData")
 	defer span.End()
 
-	url := fmt.Sprintf("https://api.hypixel.net/v2/player?uuid=%s", uuid)
+	return hypixelAPI.get(ctx, "player", fmt.Sprintf("https://api.hypixel.net/v2/player?uuid=%s", uuid))
+}
+
+func (hypixelAPI hypixelAPIImpl) GetStatus(ctx context.Context, uuid string) ([]byte, int, time.Time, error) {
+	ctx, span := hypixelAPI.tracer.Start(ctx, "HypixelAPI.GetStatus")
+	defer span.End()
+
+	return hypixelAPI.get(ctx, "status", fmt.Sprintf("https://api.hypixel.net/v2/status?uuid=%s", uuid))
+}
+
+func (hypixelAPI hypixelAPIImpl) GetGuildByPlayer(ctx context.Context, uuid string) ([]byte, int, time.Time, error) {
+	ctx, span := hypixelAPI.tracer.Start(ctx, "HypixelAPI.GetGuildByPlayer")
+	defer span.End()
+
+	return hypixelAPI.get(ctx, "guild", fmt.Sprintf("https://api.hypixel.net/v2/guild?player=%s", uuid))
+}
 
+// get requests url from the Hypixel API. All endpoints share the rate limit
+// of the API key, so they share the limiter as well.
+func (hypixelAPI hypixelAPIImpl) get(ctx context.Context, endpoint string, url string) ([]byte, int, time.Time, error) {
 	req, err := http.NewRequestWithContext(ctx, "GET", url, http.NoBody)
 	if err != nil {
 		err := fmt.Errorf("failed to create request: %w", err)
@@ -173,6 +201,7 @@ func (hypixelAPI hypixelAPIImpl) GetPlayerData(ctx context.Context, uuid string)
 
 		hypixelAPI.metrics.requestCount.Add(ctx, 1, metric.WithAttributes(
 			attribute.String("status_code", fmt.Sprintf("%d", resp.StatusCode)),
+			attribute.String("endpoint", endpoint),
 		))
 	})
 	if !ran {
@@ -0,0 +1,94 @@
+package playerprovider
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/Amund211/flashlight/internal/domain"
+	"github.com/Amund211/flashlight/internal/logging"
+	"github.com/Amund211/flashlight/internal/reporting"
+	"github.com/Amund211/flashlight/internal/strutils"
+)
+
+type hypixelGuildProvider struct {
+	hypixelAPI HypixelAPI
+
+	metrics hypixelGuildProviderMetricsCollection
+}
+
+func NewHypixelGuildProvider(hypixelAPI HypixelAPI) (GuildProvider, error) {
+	meter := otel.Meter("playerprovider/hypixel_guild_provider")
+	metrics, err := setupHypixelGuildProviderMetrics(meter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up metrics: %w", err)
+	}
+
+	return &hypixelGuildProvider{
+		hypixelAPI: hypixelAPI,
+
+		metrics: metrics,
+	}, nil
+}
+
+func (h *hypixelGuildProvider) GetGuildByPlayer(ctx context.Context, uuid string) (*domain.Guild, error) {
+	type trackingInfo struct {
+		success      bool
+		gotGuild     bool
+		invalidInput bool
+	}
+
+	track := func(ctx context.Context, info trackingInfo) {
+		h.metrics.requestCount.Add(ctx, 1, metric.WithAttributes(
+			attribute.Bool("success", info.success),
+			attribute.Bool("got_guild", info.gotGuild),
+			attribute.Bool("invalid_input", info.invalidInput),
+		))
+	}
+
+	if !strutils.UUIDIsNormalized(uuid) {
+		logging.FromContext(ctx).ErrorContext(ctx, "UUID is not normalized", "uuid", uuid)
+		err := fmt.Errorf("UUID is not normalized")
+		reporting.Report(ctx, err, map[string]string{
+			"uuid": uuid,
+		})
+		track(ctx, trackingInfo{success: false, invalidInput: true})
+		return nil, err
+	}
+
+	guildData, statusCode, queriedAt, err := h.hypixelAPI.GetGuildByPlayer(ctx, uuid)
+	if err != nil {
+		// NOTE: HypixelAPI implementations handle their own error reporting
+		track(ctx, trackingInfo{success: false})
+		return nil, fmt.Errorf("failed to get guild data: %w", err)
+	}
+
+	guild, err := HypixelGuildResponseToGuild(ctx, queriedAt, guildData, statusCode)
+	if err != nil {
+		// NOTE: HypixelGuildResponseToGuild handles its own error reporting
+		track(ctx, trackingInfo{success: false})
+		return nil, fmt.Errorf("failed to convert hypixel guild response: %w", err)
+	}
+
+	track(ctx, trackingInfo{success: true, gotGuild: guild != nil})
+
+	return guild, nil
+}
+
+type hypixelGuildProviderMetricsCollection struct {
+	requestCount metric.Int64Counter
+}
+
+func setupHypixelGuildProviderMetrics(meter metric.Meter) (hypixelGuildProviderMetricsCollection, error) {
+	requestCount, err := meter.Int64Counter("playerprovider/hypixel_guild_provider/returned_guilds")
+	if err != nil {
+		return hypixelGuildProviderMetricsCollection{}, fmt.Errorf("failed to create metric: %w", err)
+	}
+
+	return hypixelGuildProviderMetricsCollection{
+		requestCount: requestCount,
+	}, nil
+}
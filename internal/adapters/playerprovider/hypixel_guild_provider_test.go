@@ -0,0 +1,84 @@
+package playerprovider_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Amund211/flashlight/internal/adapters/playerprovider"
+	"github.com/Amund211/flashlight/internal/domain"
+)
+
+func TestHypixelGuildProvider(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+
+	t.Run("GetGuildByPlayer", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("in a guild", func(t *testing.T) {
+			t.Parallel()
+
+			hypixelAPI := &mockedHypixelAPI{
+				t:          t,
+				data:       []byte(`{"success":true,"guild":{"_id":"abc","name":"Guild","members":[{"uuid":"0123456789abcdef0123456789abcdef","rank":"Guild Master"}]}}`),
+				statusCode: 200,
+				queriedAt:  now,
+			}
+			provider, err := playerprovider.NewHypixelGuildProvider(hypixelAPI)
+			require.NoError(t, err)
+			guild, err := provider.GetGuildByPlayer(t.Context(), UUID)
+			require.NoError(t, err)
+
+			require.Equal(t, &domain.Guild{
+				ID:        "abc",
+				QueriedAt: now,
+				Name:      "Guild",
+				Members:   []domain.GuildMember{{UUID: UUID, Rank: "Guild Master"}},
+			}, guild)
+		})
+
+		t.Run("not in a guild", func(t *testing.T) {
+			t.Parallel()
+
+			hypixelAPI := &mockedHypixelAPI{
+				t:          t,
+				data:       []byte(`{"success":true,"guild":null}`),
+				statusCode: 200,
+				queriedAt:  now,
+			}
+			provider, err := playerprovider.NewHypixelGuildProvider(hypixelAPI)
+			require.NoError(t, err)
+			guild, err := provider.GetGuildByPlayer(t.Context(), UUID)
+			require.NoError(t, err)
+			require.Nil(t, guild)
+		})
+
+		t.Run("only accepts normalized ids", func(t *testing.T) {
+			t.Parallel()
+
+			hypixelAPI := &mockedHypixelAPI{t: t}
+			provider, err := playerprovider.NewHypixelGuildProvider(hypixelAPI)
+			require.NoError(t, err)
+			_, err = provider.GetGuildByPlayer(t.Context(), "0123456789abcdef0123456789abcdef")
+			require.Error(t, err)
+		})
+
+		t.Run("error from hypixel", func(t *testing.T) {
+			t.Parallel()
+
+			hypixelAPI := &mockedHypixelAPI{
+				t:          t,
+				statusCode: -1,
+				err:        assert.AnError,
+			}
+			provider, err := playerprovider.NewHypixelGuildProvider(hypixelAPI)
+			require.NoError(t, err)
+			_, err = provider.GetGuildByPlayer(t.Context(), UUID)
+			require.ErrorIs(t, err, assert.AnError)
+		})
+	})
+}
@@ -0,0 +1,136 @@
+package playerprovider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Amund211/flashlight/internal/domain"
+	"github.com/Amund211/flashlight/internal/logging"
+	"github.com/Amund211/flashlight/internal/reporting"
+	"github.com/Amund211/flashlight/internal/strutils"
+)
+
+// legacyGuildMasterRank is used by the owners of older guilds instead of
+// guildMasterRank
+const legacyGuildMasterRank = "GUILDMASTER"
+const guildMasterRank = "Guild Master"
+
+type hypixelGuildResponse struct {
+	Success bool             `json:"success"`
+	Guild   *HypixelAPIGuild `json:"guild"`
+	Cause   *string          `json:"cause,omitempty"`
+}
+
+type HypixelAPIGuild struct {
+	ID       string                  `json:"_id"`
+	Name     string                  `json:"name"`
+	Tag      *string                 `json:"tag,omitempty"`
+	TagColor *string                 `json:"tagColor,omitempty"`
+	Members  []HypixelAPIGuildMember `json:"members"`
+}
+
+type HypixelAPIGuildMember struct {
+	UUID   string `json:"uuid"`
+	Rank   string `json:"rank"`
+	Joined *int64 `json:"joined,omitempty"`
+}
+
+func ParseHypixelGuildResponse(ctx context.Context, data []byte) (*hypixelGuildResponse, error) {
+	response := new(hypixelGuildResponse)
+
+	err := json.Unmarshal(data, response)
+	if err != nil {
+		logging.FromContext(ctx).ErrorContext(ctx, "Failed to unmarshal guild data", "error", err)
+		return nil, err
+	}
+	return response, nil
+}
+
+// HypixelGuildResponseToGuild returns nil when the player is not in a guild
+func HypixelGuildResponseToGuild(ctx context.Context, queriedAt time.Time, guildData []byte, statusCode int) (*domain.Guild, error) {
+	reportError := func(err error) {
+		reporting.Report(
+			ctx,
+			err,
+			map[string]string{
+				"statusCode": fmt.Sprint(statusCode),
+				"data":       string(guildData),
+			},
+		)
+	}
+
+	if err := checkForHypixelError(ctx, statusCode, guildData); err != nil {
+		reportError(err)
+		logging.FromContext(ctx).ErrorContext(
+			ctx,
+			"Got guild response from hypixel",
+			"status", "error",
+			"error", err.Error(),
+			"data", string(guildData),
+			"statusCode", statusCode,
+			"contentLength", len(guildData),
+		)
+		return nil, err
+	}
+
+	response, err := ParseHypixelGuildResponse(ctx, guildData)
+	if err != nil {
+		err = fmt.Errorf("failed to parse guild data: %w", err)
+		reportError(err)
+		return nil, err
+	}
+
+	if !response.Success {
+		cause := "unknown error (flashlight)"
+		if response.Cause != nil {
+			cause = *response.Cause
+		}
+		err := fmt.Errorf("got success=false from Hypixel: %s", cause)
+		reportError(err)
+		return nil, err
+	}
+
+	if response.Guild == nil {
+		return nil, nil
+	}
+
+	apiGuild := response.Guild
+
+	members := make([]domain.GuildMember, 0, len(apiGuild.Members))
+	for _, apiMember := range apiGuild.Members {
+		memberUUID, err := strutils.NormalizeUUID(apiMember.UUID)
+		if err != nil {
+			err = fmt.Errorf("invalid guild member uuid: %w", err)
+			reportError(err)
+			return nil, err
+		}
+
+		rank := apiMember.Rank
+		if rank == legacyGuildMasterRank {
+			rank = guildMasterRank
+		}
+
+		var joined *time.Time
+		if apiMember.Joined != nil {
+			j := time.UnixMilli(*apiMember.Joined).UTC()
+			joined = &j
+		}
+
+		members = append(members, domain.GuildMember{
+			UUID:   memberUUID,
+			Rank:   rank,
+			Joined: joined,
+		})
+	}
+
+	return &domain.Guild{
+		ID:        apiGuild.ID,
+		QueriedAt: queriedAt,
+		Name:      apiGuild.Name,
+		Tag:       apiGuild.Tag,
+		TagColor:  apiGuild.TagColor,
+		Members:   members,
+	}, nil
+}
@@ -0,0 +1,158 @@
+package playerprovider
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Amund211/flashlight/internal/domain"
+)
+
+const hypixelGuildResponsesDir = "../../../fixtures/hypixel_guild_responses/"
+const expectedGuildsDir = "testdata/expected_guilds/"
+
+func TestHypixelGuildResponseToGuild(t *testing.T) {
+	t.Parallel()
+
+	queriedAt := time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC)
+
+	t.Run("literals", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("guild", func(t *testing.T) {
+			t.Parallel()
+
+			guild, err := HypixelGuildResponseToGuild(t.Context(), queriedAt, []byte(`{
+				"success": true,
+				"guild": {
+					"_id": "abc",
+					"name": "Guild",
+					"tag": "G",
+					"tagColor": "YELLOW",
+					"members": [
+						{"uuid": "0123456789abcdef0123456789abcdef", "rank": "GUILDMASTER", "joined": 1700000000000},
+						{"uuid": "fedcba98-7654-3210-fedc-ba9876543210", "rank": "Member"}
+					]
+				}
+			}`), 200)
+			require.NoError(t, err)
+			require.Equal(t, &domain.Guild{
+				ID:        "abc",
+				QueriedAt: queriedAt,
+				Name:      "Guild",
+				Tag:       new("G"),
+				TagColor:  new("YELLOW"),
+				Members: []domain.GuildMember{
+					{UUID: "01234567-89ab-cdef-0123-456789abcdef", Rank: "Guild Master", Joined: new(time.UnixMilli(1700000000000).UTC())},
+					{UUID: "fedcba98-7654-3210-fedc-ba9876543210", Rank: "Member"},
+				},
+			}, guild)
+		})
+
+		t.Run("not in a guild", func(t *testing.T) {
+			t.Parallel()
+
+			guild, err := HypixelGuildResponseToGuild(t.Context(), queriedAt, []byte(`{"success":true,"guild":null}`), 200)
+			require.NoError(t, err)
+			require.Nil(t, guild)
+		})
+
+		t.Run("invalid member uuid", func(t *testing.T) {
+			t.Parallel()
+
+			_, err := HypixelGuildResponseToGuild(t.Context(), queriedAt, []byte(`{"success":true,"guild":{"_id":"abc","name":"Guild","members":[{"uuid":"nope","rank":"Member"}]}}`), 200)
+			require.Error(t, err)
+		})
+
+		t.Run("success=false", func(t *testing.T) {
+			t.Parallel()
+
+			_, err := HypixelGuildResponseToGuild(t.Context(), queriedAt, []byte(`{"success":false,"cause":"Invalid API key"}`), 200)
+			require.Error(t, err)
+			require.NotErrorIs(t, err, domain.ErrTemporarilyUnavailable)
+		})
+
+		t.Run("empty object", func(t *testing.T) {
+			t.Parallel()
+
+			_, err := HypixelGuildResponseToGuild(t.Context(), queriedAt, []byte(`{}`), 200)
+			require.Error(t, err)
+		})
+
+		t.Run("ratelimited", func(t *testing.T) {
+			t.Parallel()
+
+			_, err := HypixelGuildResponseToGuild(t.Context(), queriedAt, []byte(`{"success":false}`), 429)
+			require.ErrorIs(t, err, domain.ErrTemporarilyUnavailable)
+		})
+	})
+
+	t.Run("real responses", func(t *testing.T) {
+		t.Parallel()
+
+		// Must match the time used by cmd/fix-fixtures
+		fixtureQueriedAt, err := time.Parse(time.RFC3339, "2021-11-25T23:33:47+01:00")
+		require.NoError(t, err)
+
+		guildResponseFiles, err := os.ReadDir(hypixelGuildResponsesDir)
+		require.NoError(t, err)
+		guildResponseFileNames := make([]string, 0, len(guildResponseFiles))
+		for _, file := range guildResponseFiles {
+			if file.IsDir() {
+				continue
+			}
+			guildResponseFileNames = append(guildResponseFileNames, file.Name())
+		}
+
+		expectedGuildFiles, err := os.ReadDir(expectedGuildsDir)
+		require.NoError(t, err)
+		expectedGuildFileNames := make([]string, 0, len(expectedGuildFiles))
+		for _, file := range expectedGuildFiles {
+			if file.IsDir() {
+				continue
+			}
+			expectedGuildFileNames = append(expectedGuildFileNames, file.Name())
+		}
+
+		require.ElementsMatch(
+			t,
+			guildResponseFileNames,
+			expectedGuildFileNames,
+			"All hypixel guild response files must have a corresponding expected guild file",
+		)
+
+		for _, name := range guildResponseFileNames {
+			t.Run(name, func(t *testing.T) {
+				t.Parallel()
+				guildResponse, err := os.ReadFile(path.Join(hypixelGuildResponsesDir, name))
+				require.NoError(t, err)
+				expectedGuildJSON, err := os.ReadFile(path.Join(expectedGuildsDir, name))
+				require.NoError(t, err)
+
+				// Parse expected guilds from go default JSON serialization of the struct
+				var expectedGuild *domain.Guild
+				err = json.Unmarshal(expectedGuildJSON, &expectedGuild)
+				require.NoError(t, err)
+
+				guild, err := HypixelGuildResponseToGuild(t.Context(), fixtureQueriedAt, guildResponse, 200)
+				require.NoError(t, err)
+
+				if expectedGuild == nil {
+					require.Nil(t, guild)
+					return
+				}
+				require.NotNil(t, guild)
+
+				// Compare times separately to ignore the location
+				require.WithinDuration(t, expectedGuild.QueriedAt, guild.QueriedAt, 0)
+				guild.QueriedAt = expectedGuild.QueriedAt
+
+				require.Equal(t, expectedGuild, guild)
+			})
+		}
+	})
+}
@@ -30,6 +30,22 @@ func (m *mockedHypixelAPI) GetPlayerData(ctx context.Context, uuid string) ([]by
 	return m.data, m.statusCode, m.queriedAt, m.err
 }
 
+func (m *mockedHypixelAPI) GetGuildByPlayer(ctx context.Context, uuid string) ([]byte, int, time.Time, error) {
+	m.t.Helper()
+
+	require.Equal(m.t, UUID, uuid)
+
+	return m.data, m.statusCode, m.queriedAt, m.err
+}
+
+func (m *mockedHypixelAPI) GetStatus(ctx context.Context, uuid string) ([]byte, int, time.Time, error) {
+	m.t.Helper()
+
+	require.Equal(m.t, UUID, uuid)
+
+	return m.data, m.statusCode, m.queriedAt, m.err
+}
+
 func TestHypixelPlayerProvider(t *testing.T) {
 	t.Parallel()
 
@@ -4,7 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"maps"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/Amund211/flashlight/internal/domain"
@@ -19,20 +21,111 @@ type hypixelAPIResponse struct {
 }
 
 type HypixelAPIPlayer struct {
-	UUID        *string          `json:"uuid,omitempty"`
-	Displayname *string          `json:"displayname,omitempty"`
-	LastLogin   *int64           `json:"lastLogin,omitempty"`
-	LastLogout  *int64           `json:"lastLogout,omitempty"`
-	Stats       *HypixelAPIStats `json:"stats,omitempty"`
+	UUID        *string `json:"uuid,omitempty"`
+	Displayname *string `json:"displayname,omitempty"`
+	LastLogin   *int64  `json:"lastLogin,omitempty"`
+	LastLogout  *int64  `json:"lastLogout,omitempty"`
+
+	Rank               *string  `json:"rank,omitempty"`
+	NewPackageRank     *string  `json:"newPackageRank,omitempty"`
+	PackageRank        *string  `json:"packageRank,omitempty"`
+	MonthlyPackageRank *string  `json:"monthlyPackageRank,omitempty"`
+	RankPlusColor      *string  `json:"rankPlusColor,omitempty"`
+	NetworkExp         *float64 `json:"networkExp,omitempty"`
+
+	Stats *HypixelAPIStats `json:"stats,omitempty"`
 }
 
 type HypixelAPIStats struct {
 	Bedwars *HypixelAPIBedwarsStats `json:"Bedwars,omitempty"`
+	SkyWars *HypixelAPISkyWarsStats `json:"SkyWars,omitempty"`
+	Duels   *HypixelAPIDuelsStats   `json:"Duels,omitempty"`
+}
+
+type HypixelAPISkyWarsStats struct {
+	Experience *float64 `json:"skywars_experience,omitempty"`
+
+	Winstreak   *int `json:"win_streak,omitempty"`
+	GamesPlayed int  `json:"games_played_skywars,omitempty"`
+	Wins        int  `json:"wins,omitempty"`
+	Losses      int  `json:"losses,omitempty"`
+	Kills       int  `json:"kills,omitempty"`
+	Deaths      int  `json:"deaths,omitempty"`
+
+	SoloWins   int `json:"wins_solo,omitempty"`
+	SoloLosses int `json:"losses_solo,omitempty"`
+	SoloKills  int `json:"kills_solo,omitempty"`
+	SoloDeaths int `json:"deaths_solo,omitempty"`
+
+	TeamsWins   int `json:"wins_team,omitempty"`
+	TeamsLosses int `json:"losses_team,omitempty"`
+	TeamsKills  int `json:"kills_team,omitempty"`
+	TeamsDeaths int `json:"deaths_team,omitempty"`
+}
+
+type HypixelAPIDuelsStats struct {
+	Winstreak   *int `json:"current_winstreak,omitempty"`
+	GamesPlayed int  `json:"games_played_duels,omitempty"`
+	Wins        int  `json:"wins,omitempty"`
+	Losses      int  `json:"losses,omitempty"`
+	Kills       int  `json:"kills,omitempty"`
+	Deaths      int  `json:"deaths,omitempty"`
+
+	UHCWinstreak *int `json:"current_winstreak_mode_uhc_duel,omitempty"`
+	UHCWins      int  `json:"uhc_duel_wins,omitempty"`
+	UHCLosses    int  `json:"uhc_duel_losses,omitempty"`
+	UHCKills     int  `json:"uhc_duel_kills,omitempty"`
+	UHCDeaths    int  `json:"uhc_duel_deaths,omitempty"`
+
+	SkyWarsWinstreak *int `json:"current_winstreak_mode_sw_duel,omitempty"`
+	SkyWarsWins      int  `json:"sw_duel_wins,omitempty"`
+	SkyWarsLosses    int  `json:"sw_duel_losses,omitempty"`
+	SkyWarsKills     int  `json:"sw_duel_kills,omitempty"`
+	SkyWarsDeaths    int  `json:"sw_duel_deaths,omitempty"`
+
+	ClassicWinstreak *int `json:"current_winstreak_mode_classic_duel,omitempty"`
+	ClassicWins      int  `json:"classic_duel_wins,omitempty"`
+	ClassicLosses    int  `json:"classic_duel_losses,omitempty"`
+	ClassicKills     int  `json:"classic_duel_kills,omitempty"`
+	ClassicDeaths    int  `json:"classic_duel_deaths,omitempty"`
+
+	// NOTE: Bridge tracks kills and deaths under its own names
+	BridgeWinstreak *int `json:"current_winstreak_mode_bridge_duel,omitempty"`
+	BridgeWins      int  `json:"bridge_duel_wins,omitempty"`
+	BridgeLosses    int  `json:"bridge_duel_losses,omitempty"`
+	BridgeKills     int  `json:"bridge_duel_bridge_kills,omitempty"`
+	BridgeDeaths    int  `json:"bridge_duel_bridge_deaths,omitempty"`
+
+	SumoWinstreak *int `json:"current_winstreak_mode_sumo_duel,omitempty"`
+	SumoWins      int  `json:"sumo_duel_wins,omitempty"`
+	SumoLosses    int  `json:"sumo_duel_losses,omitempty"`
+	SumoKills     int  `json:"sumo_duel_kills,omitempty"`
+	SumoDeaths    int  `json:"sumo_duel_deaths,omitempty"`
+
+	OPWinstreak *int `json:"current_winstreak_mode_op_duel,omitempty"`
+	OPWins      int  `json:"op_duel_wins,omitempty"`
+	OPLosses    int  `json:"op_duel_losses,omitempty"`
+	OPKills     int  `json:"op_duel_kills,omitempty"`
+	OPDeaths    int  `json:"op_duel_deaths,omitempty"`
+
+	BowWinstreak *int `json:"current_winstreak_mode_bow_duel,omitempty"`
+	BowWins      int  `json:"bow_duel_wins,omitempty"`
+	BowLosses    int  `json:"bow_duel_losses,omitempty"`
+	BowKills     int  `json:"bow_duel_kills,omitempty"`
+	BowDeaths    int  `json:"bow_duel_deaths,omitempty"`
 }
 
 type HypixelAPIBedwarsStats struct {
 	Experience *float64 `json:"Experience,omitempty"`
 
+	Coins               int `json:"coins,omitempty"`
+	ChallengesCompleted int `json:"total_challenges_completed,omitempty"`
+	ItemsPurchased      int `json:"items_purchased_bedwars,omitempty"`
+	IronCollected       int `json:"iron_resources_collected_bedwars,omitempty"`
+	GoldCollected       int `json:"gold_resources_collected_bedwars,omitempty"`
+	DiamondsCollected   int `json:"diamond_resources_collected_bedwars,omitempty"`
+	EmeraldsCollected   int `json:"emerald_resources_collected_bedwars,omitempty"`
+
 	Winstreak   *int `json:"winstreak,omitempty"`
 	GamesPlayed int  `json:"games_played_bedwars,omitempty"`
 	Wins        int  `json:"wins_bedwars,omitempty"`
@@ -98,6 +191,207 @@ type HypixelAPIBedwarsStats struct {
 	Fourv4FinalDeaths int  `json:"two_four_final_deaths_bedwars,omitempty"`
 	Fourv4Kills       int  `json:"two_four_kills_bedwars,omitempty"`
 	Fourv4Deaths      int  `json:"two_four_deaths_bedwars,omitempty"`
+
+	// DreamModes holds the stats of the dream modes present in the response.
+	// Populated by UnmarshalJSON from the fields prefixed by the mode.
+	DreamModes map[domain.DreamMode]HypixelAPIGamemodeStats `json:"-"`
+}
+
+// HypixelAPIGamemodeStats are the stats of a single mode, with the prefix of
+// the mode removed from the field names
+type HypixelAPIGamemodeStats struct {
+	Winstreak   *int `json:"winstreak,omitempty"`
+	GamesPlayed int  `json:"games_played_bedwars,omitempty"`
+	Wins        int  `json:"wins_bedwars,omitempty"`
+	Losses      int  `json:"losses_bedwars,omitempty"`
+	BedsBroken  int  `json:"beds_broken_bedwars,omitempty"`
+	BedsLost    int  `json:"beds_lost_bedwars,omitempty"`
+	FinalKills  int  `json:"final_kills_bedwars,omitempty"`
+	FinalDeaths int  `json:"final_deaths_bedwars,omitempty"`
+	Kills       int  `json:"kills_bedwars,omitempty"`
+	Deaths      int  `json:"deaths_bedwars,omitempty"`
+}
+
+// hypixelDreamModePrefixes maps the prefix of the fields of each dream mode
+// in the Hypixel API to the mode
+var hypixelDreamModePrefixes = map[string]domain.DreamMode{
+	"castle_":               domain.DreamModeCastle,
+	"eight_one_rush_":       domain.DreamModeRushSolo,
+	"eight_two_rush_":       domain.DreamModeRushDoubles,
+	"four_four_rush_":       domain.DreamModeRushFours,
+	"eight_one_ultimate_":   domain.DreamModeUltimateSolo,
+	"eight_two_ultimate_":   domain.DreamModeUltimateDoubles,
+	"four_four_ultimate_":   domain.DreamModeUltimateFours,
+	"eight_two_lucky_":      domain.DreamModeLuckyDoubles,
+	"four_four_lucky_":      domain.DreamModeLuckyFours,
+	"eight_two_voidless_":   domain.DreamModeVoidlessDoubles,
+	"four_four_voidless_":   domain.DreamModeVoidlessFours,
+	"eight_two_armed_":      domain.DreamModeArmedDoubles,
+	"four_four_armed_":      domain.DreamModeArmedFours,
+	"eight_two_swap_":       domain.DreamModeSwapDoubles,
+	"four_four_swap_":       domain.DreamModeSwapFours,
+	"eight_two_underworld_": domain.DreamModeUnderworldDoubles,
+	"four_four_underworld_": domain.DreamModeUnderworldFours,
+}
+
+// dreamModeOfField returns the dream mode of a field in the Hypixel API, and
+// the name of the field with the prefix of the mode removed
+func dreamModeOfField(name string) (domain.DreamMode, string, bool) {
+	if field, ok := strings.CutPrefix(name, "castle_"); ok {
+		return domain.DreamModeCastle, field, true
+	}
+
+	// The other prefixes are the team size followed by the mode, e.g. eight_two_rush_
+	end := 0
+	for range 3 {
+		i := strings.IndexByte(name[end:], '_')
+		if i == -1 {
+			return "", "", false
+		}
+		end += i + 1
+	}
+
+	mode, ok := hypixelDreamModePrefixes[name[:end]]
+	if !ok {
+		return "", "", false
+	}
+	return mode, name[end:], true
+}
+
+// field returns the stat stored under the name of a field with the prefix of
+// the mode removed, or nil if it isn't tracked
+func (s *HypixelAPIGamemodeStats) field(name string) any {
+	switch name {
+	case "winstreak":
+		return &s.Winstreak
+	case "games_played_bedwars":
+		return &s.GamesPlayed
+	case "wins_bedwars":
+		return &s.Wins
+	case "losses_bedwars":
+		return &s.Losses
+	case "beds_broken_bedwars":
+		return &s.BedsBroken
+	case "beds_lost_bedwars":
+		return &s.BedsLost
+	case "final_kills_bedwars":
+		return &s.FinalKills
+	case "final_deaths_bedwars":
+		return &s.FinalDeaths
+	case "kills_bedwars":
+		return &s.Kills
+	case "deaths_bedwars":
+		return &s.Deaths
+	}
+	return nil
+}
+
+func (s *HypixelAPIBedwarsStats) UnmarshalJSON(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	// The alias doesn't have this method, so the fields are decoded as usual
+	type bedwarsStats HypixelAPIBedwarsStats
+	if err := json.Unmarshal(data, (*bedwarsStats)(s)); err != nil {
+		return err
+	}
+
+	s.DreamModes = nil
+	for name, value := range fields {
+		mode, field, ok := dreamModeOfField(name)
+		if !ok {
+			continue
+		}
+
+		stats := s.DreamModes[mode]
+		target := stats.field(field)
+		if target == nil {
+			// Fields we don't track, like deaths by cause
+			continue
+		}
+		if err := json.Unmarshal(value, target); err != nil {
+			return fmt.Errorf("failed to unmarshal %s: %w", name, err)
+		}
+
+		if s.DreamModes == nil {
+			s.DreamModes = map[domain.DreamMode]HypixelAPIGamemodeStats{}
+		}
+		s.DreamModes[mode] = stats
+	}
+
+	// Modes where every tracked stat is zero
+	maps.DeleteFunc(s.DreamModes, func(_ domain.DreamMode, stats HypixelAPIGamemodeStats) bool {
+		return stats == HypixelAPIGamemodeStats{}
+	})
+	if len(s.DreamModes) == 0 {
+		s.DreamModes = nil
+	}
+
+	return nil
+}
+
+// newGameModeStats returns the stats of a mode of a game other than bedwars,
+// leaving the winstreak out when it is hidden
+func newGameModeStats(winstreak *int, wins, losses, kills, deaths int) domain.GameModeStatsPIT {
+	stats := domain.GameModeStatsPIT{
+		domain.StatWins:   wins,
+		domain.StatLosses: losses,
+		domain.StatKills:  kills,
+		domain.StatDeaths: deaths,
+	}
+	if winstreak != nil {
+		stats[domain.StatWinstreak] = *winstreak
+	}
+	return stats
+}
+
+// addPlayedMode adds the stats of mode to stats if the player has played it
+func addPlayedMode(stats domain.GameStatsPIT, mode string, modeStats domain.GameModeStatsPIT) {
+	for _, value := range modeStats {
+		if value != 0 {
+			stats[mode] = modeStats
+			return
+		}
+	}
+}
+
+func skyWarsStatsToGameStats(sw *HypixelAPISkyWarsStats) domain.GameStatsPIT {
+	if sw == nil {
+		sw = &HypixelAPISkyWarsStats{}
+	}
+
+	overall := newGameModeStats(sw.Winstreak, sw.Wins, sw.Losses, sw.Kills, sw.Deaths)
+	overall[domain.StatGamesPlayed] = sw.GamesPlayed
+	overall[domain.StatExperience] = 0
+	if sw.Experience != nil {
+		overall[domain.StatExperience] = int(*sw.Experience)
+	}
+
+	stats := domain.GameStatsPIT{domain.GameModeOverall: overall}
+	addPlayedMode(stats, domain.SkyWarsModeSolo, newGameModeStats(nil, sw.SoloWins, sw.SoloLosses, sw.SoloKills, sw.SoloDeaths))
+	addPlayedMode(stats, domain.SkyWarsModeTeams, newGameModeStats(nil, sw.TeamsWins, sw.TeamsLosses, sw.TeamsKills, sw.TeamsDeaths))
+	return stats
+}
+
+func duelsStatsToGameStats(duels *HypixelAPIDuelsStats) domain.GameStatsPIT {
+	if duels == nil {
+		duels = &HypixelAPIDuelsStats{}
+	}
+
+	overall := newGameModeStats(duels.Winstreak, duels.Wins, duels.Losses, duels.Kills, duels.Deaths)
+	overall[domain.StatGamesPlayed] = duels.GamesPlayed
+
+	stats := domain.GameStatsPIT{domain.GameModeOverall: overall}
+	addPlayedMode(stats, domain.DuelsModeUHC, newGameModeStats(duels.UHCWinstreak, duels.UHCWins, duels.UHCLosses, duels.UHCKills, duels.UHCDeaths))
+	addPlayedMode(stats, domain.DuelsModeSkyWars, newGameModeStats(duels.SkyWarsWinstreak, duels.SkyWarsWins, duels.SkyWarsLosses, duels.SkyWarsKills, duels.SkyWarsDeaths))
+	addPlayedMode(stats, domain.DuelsModeClassic, newGameModeStats(duels.ClassicWinstreak, duels.ClassicWins, duels.ClassicLosses, duels.ClassicKills, duels.ClassicDeaths))
+	addPlayedMode(stats, domain.DuelsModeBridge, newGameModeStats(duels.BridgeWinstreak, duels.BridgeWins, duels.BridgeLosses, duels.BridgeKills, duels.BridgeDeaths))
+	addPlayedMode(stats, domain.DuelsModeSumo, newGameModeStats(duels.SumoWinstreak, duels.SumoWins, duels.SumoLosses, duels.SumoKills, duels.SumoDeaths))
+	addPlayedMode(stats, domain.DuelsModeOP, newGameModeStats(duels.OPWinstreak, duels.OPWins, duels.OPLosses, duels.OPKills, duels.OPDeaths))
+	addPlayedMode(stats, domain.DuelsModeBow, newGameModeStats(duels.BowWinstreak, duels.BowWins, duels.BowLosses, duels.BowKills, duels.BowDeaths))
+	return stats
 }
 
 func ParseHypixelAPIResponse(ctx context.Context, data []byte) (*hypixelAPIResponse, error) {
@@ -218,6 +512,9 @@ func HypixelAPIResponseToPlayerPIT(ctx context.Context, uuid string, queriedAt t
 
 	var experience int64 = 500
 	var solo, doubles, threes, fours, fourv4, overall domain.GamemodeStatsPIT
+	var dreamModes map[domain.DreamMode]domain.GamemodeStatsPIT
+	// Missing economy stats are all zero, as with the other stats
+	economy := &domain.EconomyStatsPIT{}
 
 	if apiPlayer.Stats != nil && apiPlayer.Stats.Bedwars != nil {
 		bw := apiPlayer.Stats.Bedwars
@@ -303,6 +600,58 @@ func HypixelAPIResponseToPlayerPIT(ctx context.Context, uuid string, queriedAt t
 			Kills:       bw.Kills,
 			Deaths:      bw.Deaths,
 		}
+
+		economy = &domain.EconomyStatsPIT{
+			Coins:               bw.Coins,
+			ChallengesCompleted: bw.ChallengesCompleted,
+			ItemsPurchased:      bw.ItemsPurchased,
+			IronCollected:       bw.IronCollected,
+			GoldCollected:       bw.GoldCollected,
+			DiamondsCollected:   bw.DiamondsCollected,
+			EmeraldsCollected:   bw.EmeraldsCollected,
+		}
+
+		for mode, stats := range bw.DreamModes {
+			if dreamModes == nil {
+				dreamModes = make(map[domain.DreamMode]domain.GamemodeStatsPIT, len(bw.DreamModes))
+			}
+			dreamModes[mode] = domain.GamemodeStatsPIT{
+				Winstreak:   stats.Winstreak,
+				GamesPlayed: stats.GamesPlayed,
+				Wins:        stats.Wins,
+				Losses:      stats.Losses,
+				BedsBroken:  stats.BedsBroken,
+				BedsLost:    stats.BedsLost,
+				FinalKills:  stats.FinalKills,
+				FinalDeaths: stats.FinalDeaths,
+				Kills:       stats.Kills,
+				Deaths:      stats.Deaths,
+			}
+		}
+	}
+
+	// Missing stats in other games are all zero, as with the bedwars stats
+	var skyWars *HypixelAPISkyWarsStats
+	var duels *HypixelAPIDuelsStats
+	if apiPlayer.Stats != nil {
+		skyWars = apiPlayer.Stats.SkyWars
+		duels = apiPlayer.Stats.Duels
+	}
+	games := map[domain.Game]domain.GameStatsPIT{
+		domain.GameSkyWars: skyWarsStatsToGameStats(skyWars),
+		domain.GameDuels:   duelsStatsToGameStats(duels),
+	}
+
+	// Players who bought their rank before 2016 only have packageRank
+	packageRank := apiPlayer.NewPackageRank
+	if packageRank == nil {
+		packageRank = apiPlayer.PackageRank
+	}
+	rank := &domain.RankPIT{
+		StaffRank:          apiPlayer.Rank,
+		PackageRank:        packageRank,
+		MonthlyPackageRank: apiPlayer.MonthlyPackageRank,
+		RankPlusColor:      apiPlayer.RankPlusColor,
 	}
 
 	return &domain.PlayerPIT{
@@ -318,6 +667,9 @@ func HypixelAPIResponseToPlayerPIT(ctx context.Context, uuid string, queriedAt t
 		LastLogin:   lastLogin,
 		LastLogout:  lastLogout,
 
+		Rank:              rank,
+		NetworkExperience: apiPlayer.NetworkExp,
+
 		Experience: experience,
 		Solo:       solo,
 		Doubles:    doubles,
@@ -325,5 +677,8 @@ func HypixelAPIResponseToPlayerPIT(ctx context.Context, uuid string, queriedAt t
 		Fours:      fours,
 		Fourv4:     fourv4,
 		Overall:    overall,
+		DreamModes: dreamModes,
+		Economy:    economy,
+		Games:      games,
 	}, nil
 }
@@ -31,6 +31,31 @@ const expectedPlayersDir = "testdata/expected_players/"
 
 var errAnyError = fmt.Errorf("any error")
 
+// emptyGames are the stats in the other games of a player who hasn't played them
+func emptyGames() map[domain.Game]domain.GameStatsPIT {
+	return map[domain.Game]domain.GameStatsPIT{
+		domain.GameSkyWars: {
+			domain.GameModeOverall: {
+				domain.StatExperience:  0,
+				domain.StatGamesPlayed: 0,
+				domain.StatWins:        0,
+				domain.StatLosses:      0,
+				domain.StatKills:       0,
+				domain.StatDeaths:      0,
+			},
+		},
+		domain.GameDuels: {
+			domain.GameModeOverall: {
+				domain.StatGamesPlayed: 0,
+				domain.StatWins:        0,
+				domain.StatLosses:      0,
+				domain.StatKills:       0,
+				domain.StatDeaths:      0,
+			},
+		},
+	}
+}
+
 func runHypixelAPIResponseToPlayerTest(t *testing.T, test hypixelAPIResponseToPlayerTest) {
 	t.Helper()
 
@@ -99,7 +124,7 @@ func TestHypixelAPIResponseToPlayerPIT(t *testing.T) {
 					}
 				}`),
 				hypixelStatusCode: 200,
-				result:            domaintest.NewPlayerBuilder("12345678-90ab-cdef-1234-567890abcdef").WithExperience(1087).BuildPtr(now),
+				result:            domaintest.NewPlayerBuilder("12345678-90ab-cdef-1234-567890abcdef").WithExperience(1087).WithEconomy(domain.EconomyStatsPIT{}).WithGames(emptyGames()).WithRank(domain.RankPIT{}).BuildPtr(now),
 			},
 			{
 				name:      "float experience - scientific notation",
@@ -117,7 +142,7 @@ func TestHypixelAPIResponseToPlayerPIT(t *testing.T) {
 					}
 				}`),
 				hypixelStatusCode: 200,
-				result:            domaintest.NewPlayerBuilder("12345678-90ab-cdef-1234-567890abcdef").WithExperience(12_227_806).BuildPtr(later),
+				result:            domaintest.NewPlayerBuilder("12345678-90ab-cdef-1234-567890abcdef").WithExperience(12_227_806).WithEconomy(domain.EconomyStatsPIT{}).WithGames(emptyGames()).WithRank(domain.RankPIT{}).BuildPtr(later),
 			},
 			{
 				name:               "not found",
@@ -158,6 +183,9 @@ func TestHypixelAPIResponseToPlayerPIT(t *testing.T) {
 					UUID:       "12345678-90ab-cdef-1234-567890abcdef",
 					QueriedAt:  now,
 					Experience: 500,
+					Economy:    &domain.EconomyStatsPIT{},
+					Games:      emptyGames(),
+					Rank:       &domain.RankPIT{},
 					Fourv4: domain.GamemodeStatsPIT{
 						Winstreak:   new(5),
 						GamesPlayed: 72,
@@ -172,6 +200,221 @@ func TestHypixelAPIResponseToPlayerPIT(t *testing.T) {
 					},
 				},
 			},
+			{
+				// Dream mode stats don't count towards the core modes, and modes
+				// with only untracked fields are left out
+				name:      "dream mode stats",
+				uuid:      "12345678-90ab-cdef-1234-567890abcdef",
+				queriedAt: now,
+				hypixelAPIResponse: []byte(`{
+					"success": true,
+					"player": {
+						"uuid":"1234567890abcdef1234567890abcdef",
+						"stats": {
+							"Bedwars": {
+								"eight_two_wins_bedwars": 3,
+								"castle_winstreak": 1,
+								"castle_games_played_bedwars": 4,
+								"castle_wins_bedwars": 2,
+								"castle_losses_bedwars": 2,
+								"eight_two_rush_games_played_bedwars": 9,
+								"eight_two_rush_final_kills_bedwars": 11,
+								"eight_two_rush_deaths_bedwars": 7,
+								"four_four_swap_void_deaths_bedwars": 3
+							}
+						}
+					}
+				}`),
+				hypixelStatusCode: 200,
+				result: &domain.PlayerPIT{
+					UUID:       "12345678-90ab-cdef-1234-567890abcdef",
+					QueriedAt:  now,
+					Experience: 500,
+					Economy:    &domain.EconomyStatsPIT{},
+					Games:      emptyGames(),
+					Rank:       &domain.RankPIT{},
+					Doubles: domain.GamemodeStatsPIT{
+						Wins: 3,
+					},
+					DreamModes: map[domain.DreamMode]domain.GamemodeStatsPIT{
+						domain.DreamModeCastle: {
+							Winstreak:   new(1),
+							GamesPlayed: 4,
+							Wins:        2,
+							Losses:      2,
+						},
+						domain.DreamModeRushDoubles: {
+							GamesPlayed: 9,
+							FinalKills:  11,
+							Deaths:      7,
+						},
+					},
+				},
+			},
+			{
+				name:      "economy stats",
+				uuid:      "12345678-90ab-cdef-1234-567890abcdef",
+				queriedAt: now,
+				hypixelAPIResponse: []byte(`{
+					"success": true,
+					"player": {
+						"uuid":"1234567890abcdef1234567890abcdef",
+						"stats": {
+							"Bedwars": {
+								"coins": 16526129,
+								"total_challenges_completed": 140,
+								"items_purchased_bedwars": 935023,
+								"_items_purchased_bedwars": 870240,
+								"iron_resources_collected_bedwars": 5495270,
+								"gold_resources_collected_bedwars": 753474,
+								"diamond_resources_collected_bedwars": 74643,
+								"emerald_resources_collected_bedwars": 19721
+							}
+						}
+					}
+				}`),
+				hypixelStatusCode: 200,
+				result: domaintest.NewPlayerBuilder("12345678-90ab-cdef-1234-567890abcdef").WithEconomy(domain.EconomyStatsPIT{
+					Coins:               16_526_129,
+					ChallengesCompleted: 140,
+					ItemsPurchased:      935_023,
+					IronCollected:       5_495_270,
+					GoldCollected:       753_474,
+					DiamondsCollected:   74_643,
+					EmeraldsCollected:   19_721,
+				}).WithGames(emptyGames()).WithRank(domain.RankPIT{}).BuildPtr(now),
+			},
+			{
+				// Modes the player hasn't played are left out, and the
+				// winstreak is left out when hidden
+				name:      "skywars and duels stats",
+				uuid:      "12345678-90ab-cdef-1234-567890abcdef",
+				queriedAt: now,
+				hypixelAPIResponse: []byte(`{
+					"success": true,
+					"player": {
+						"uuid":"1234567890abcdef1234567890abcdef",
+						"stats": {
+							"SkyWars": {
+								"skywars_experience": 17939.0,
+								"win_streak": 2,
+								"games_played_skywars": 5444,
+								"wins": 1081,
+								"losses": 4211,
+								"kills": 6028,
+								"deaths": 4241,
+								"wins_solo": 901,
+								"losses_solo": 3631,
+								"kills_solo": 4933,
+								"deaths_solo": 3621
+							},
+							"Duels": {
+								"games_played_duels": 120,
+								"wins": 70,
+								"losses": 50,
+								"kills": 65,
+								"deaths": 52,
+								"bridge_duel_wins": 20,
+								"bridge_duel_losses": 10,
+								"bridge_duel_bridge_kills": 45,
+								"bridge_duel_bridge_deaths": 30,
+								"sumo_duel_melee_swings": 14
+							}
+						}
+					}
+				}`),
+				hypixelStatusCode: 200,
+				result: domaintest.NewPlayerBuilder("12345678-90ab-cdef-1234-567890abcdef").WithEconomy(domain.EconomyStatsPIT{}).WithGames(map[domain.Game]domain.GameStatsPIT{
+					domain.GameSkyWars: {
+						domain.GameModeOverall: {
+							domain.StatExperience:  17_939,
+							domain.StatWinstreak:   2,
+							domain.StatGamesPlayed: 5444,
+							domain.StatWins:        1081,
+							domain.StatLosses:      4211,
+							domain.StatKills:       6028,
+							domain.StatDeaths:      4241,
+						},
+						domain.SkyWarsModeSolo: {
+							domain.StatWins:   901,
+							domain.StatLosses: 3631,
+							domain.StatKills:  4933,
+							domain.StatDeaths: 3621,
+						},
+					},
+					domain.GameDuels: {
+						domain.GameModeOverall: {
+							domain.StatGamesPlayed: 120,
+							domain.StatWins:        70,
+							domain.StatLosses:      50,
+							domain.StatKills:       65,
+							domain.StatDeaths:      52,
+						},
+						domain.DuelsModeBridge: {
+							domain.StatWins:   20,
+							domain.StatLosses: 10,
+							domain.StatKills:  45,
+							domain.StatDeaths: 30,
+						},
+					},
+				}).WithRank(domain.RankPIT{}).BuildPtr(now),
+			},
+			{
+				// Players who bought their rank before 2016 only have
+				// packageRank
+				name:      "rank and network experience",
+				uuid:      "12345678-90ab-cdef-1234-567890abcdef",
+				queriedAt: now,
+				hypixelAPIResponse: []byte(`{
+					"success": true,
+					"player": {
+						"uuid":"1234567890abcdef1234567890abcdef",
+						"packageRank": "VIP",
+						"newPackageRank": "MVP_PLUS",
+						"monthlyPackageRank": "SUPERSTAR",
+						"rankPlusColor": "DARK_GREEN",
+						"networkExp": 12345678.5
+					}
+				}`),
+				hypixelStatusCode: 200,
+				result: domaintest.NewPlayerBuilder("12345678-90ab-cdef-1234-567890abcdef").WithEconomy(domain.EconomyStatsPIT{}).WithGames(emptyGames()).WithRank(domain.RankPIT{
+					PackageRank:        new("MVP_PLUS"),
+					MonthlyPackageRank: new("SUPERSTAR"),
+					RankPlusColor:      new("DARK_GREEN"),
+				}).WithNetworkExperience(12_345_678.5).BuildPtr(now),
+			},
+			{
+				name:      "legacy package rank and staff rank",
+				uuid:      "12345678-90ab-cdef-1234-567890abcdef",
+				queriedAt: now,
+				hypixelAPIResponse: []byte(`{
+					"success": true,
+					"player": {
+						"uuid":"1234567890abcdef1234567890abcdef",
+						"packageRank": "MVP_PLUS",
+						"rank": "YOUTUBER"
+					}
+				}`),
+				hypixelStatusCode: 200,
+				result: domaintest.NewPlayerBuilder("12345678-90ab-cdef-1234-567890abcdef").WithEconomy(domain.EconomyStatsPIT{}).WithGames(emptyGames()).WithRank(domain.RankPIT{
+					StaffRank:   new("YOUTUBER"),
+					PackageRank: new("MVP_PLUS"),
+				}).BuildPtr(now),
+			},
+			{
+				name:      "invalid dream mode stats",
+				uuid:      "12345678-90ab-cdef-1234-567890abcdef",
+				queriedAt: now,
+				hypixelAPIResponse: []byte(`{
+					"success": true,
+					"player": {
+						"uuid":"1234567890abcdef1234567890abcdef",
+						"stats": {"Bedwars": {"castle_wins_bedwars": "many"}}
+					}
+				}`),
+				hypixelStatusCode: 200,
+				error:             errAnyError,
+			},
 			{
 				name:               "hypixel 500",
 				uuid:               "12345678-90ab-cdef-1234-567890abcdef",
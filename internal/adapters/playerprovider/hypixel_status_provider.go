@@ -0,0 +1,94 @@
+package playerprovider
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/Amund211/flashlight/internal/domain"
+	"github.com/Amund211/flashlight/internal/logging"
+	"github.com/Amund211/flashlight/internal/reporting"
+	"github.com/Amund211/flashlight/internal/strutils"
+)
+
+type hypixelStatusProvider struct {
+	hypixelAPI HypixelAPI
+
+	metrics hypixelStatusProviderMetricsCollection
+}
+
+func NewHypixelStatusProvider(hypixelAPI HypixelAPI) (StatusProvider, error) {
+	meter := otel.Meter("playerprovider/hypixel_status_provider")
+	metrics, err := setupHypixelStatusProviderMetrics(meter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up metrics: %w", err)
+	}
+
+	return &hypixelStatusProvider{
+		hypixelAPI: hypixelAPI,
+
+		metrics: metrics,
+	}, nil
+}
+
+func (h *hypixelStatusProvider) GetStatus(ctx context.Context, uuid string) (domain.PlayerStatus, error) {
+	type trackingInfo struct {
+		success      bool
+		online       bool
+		invalidInput bool
+	}
+
+	track := func(ctx context.Context, info trackingInfo) {
+		h.metrics.requestCount.Add(ctx, 1, metric.WithAttributes(
+			attribute.Bool("success", info.success),
+			attribute.Bool("online", info.online),
+			attribute.Bool("invalid_input", info.invalidInput),
+		))
+	}
+
+	if !strutils.UUIDIsNormalized(uuid) {
+		logging.FromContext(ctx).ErrorContext(ctx, "UUID is not normalized", "uuid", uuid)
+		err := fmt.Errorf("UUID is not normalized")
+		reporting.Report(ctx, err, map[string]string{
+			"uuid": uuid,
+		})
+		track(ctx, trackingInfo{success: false, invalidInput: true})
+		return domain.PlayerStatus{}, err
+	}
+
+	statusData, statusCode, queriedAt, err := h.hypixelAPI.GetStatus(ctx, uuid)
+	if err != nil {
+		// NOTE: HypixelAPI implementations handle their own error reporting
+		track(ctx, trackingInfo{success: false})
+		return domain.PlayerStatus{}, fmt.Errorf("failed to get status: %w", err)
+	}
+
+	status, err := HypixelStatusResponseToPlayerStatus(ctx, uuid, queriedAt, statusData, statusCode)
+	if err != nil {
+		// NOTE: HypixelStatusResponseToPlayerStatus handles its own error reporting
+		track(ctx, trackingInfo{success: false})
+		return domain.PlayerStatus{}, fmt.Errorf("failed to convert hypixel status response: %w", err)
+	}
+
+	track(ctx, trackingInfo{success: true, online: status.Online})
+
+	return status, nil
+}
+
+type hypixelStatusProviderMetricsCollection struct {
+	requestCount metric.Int64Counter
+}
+
+func setupHypixelStatusProviderMetrics(meter metric.Meter) (hypixelStatusProviderMetricsCollection, error) {
+	requestCount, err := meter.Int64Counter("playerprovider/hypixel_status_provider/returned_statuses")
+	if err != nil {
+		return hypixelStatusProviderMetricsCollection{}, fmt.Errorf("failed to create metric: %w", err)
+	}
+
+	return hypixelStatusProviderMetricsCollection{
+		requestCount: requestCount,
+	}, nil
+}
@@ -0,0 +1,127 @@
+package playerprovider_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Amund211/flashlight/internal/adapters/playerprovider"
+	"github.com/Amund211/flashlight/internal/domain"
+)
+
+func TestHypixelStatusProvider(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+
+	t.Run("GetStatus", func(t *testing.T) {
+		t.Parallel()
+
+		getStatus := func(t *testing.T, data string, statusCode int) (domain.PlayerStatus, error) {
+			t.Helper()
+
+			hypixelAPI := &mockedHypixelAPI{
+				t:          t,
+				data:       []byte(data),
+				statusCode: statusCode,
+				queriedAt:  now,
+				err:        nil,
+			}
+			provider, err := playerprovider.NewHypixelStatusProvider(hypixelAPI)
+			require.NoError(t, err)
+			return provider.GetStatus(t.Context(), UUID)
+		}
+
+		t.Run("online", func(t *testing.T) {
+			t.Parallel()
+
+			status, err := getStatus(t, `{"success":true,"uuid":"0123456789abcdef0123456789abcdef","session":{"online":true,"gameType":"BEDWARS","mode":"BEDWARS_EIGHT_ONE","map":"Lighthouse"}}`, 200)
+			require.NoError(t, err)
+
+			require.Equal(t, domain.PlayerStatus{
+				UUID:      UUID,
+				QueriedAt: now,
+				Online:    true,
+				GameType:  new("BEDWARS"),
+				Mode:      new("BEDWARS_EIGHT_ONE"),
+				Map:       new("Lighthouse"),
+			}, status)
+		})
+
+		t.Run("in lobby", func(t *testing.T) {
+			t.Parallel()
+
+			status, err := getStatus(t, `{"success":true,"uuid":"0123456789abcdef0123456789abcdef","session":{"online":true,"gameType":"BEDWARS","mode":"LOBBY"}}`, 200)
+			require.NoError(t, err)
+
+			require.Equal(t, domain.PlayerStatus{
+				UUID:      UUID,
+				QueriedAt: now,
+				Online:    true,
+				GameType:  new("BEDWARS"),
+				Mode:      new("LOBBY"),
+			}, status)
+		})
+
+		t.Run("offline", func(t *testing.T) {
+			t.Parallel()
+
+			status, err := getStatus(t, `{"success":true,"uuid":"0123456789abcdef0123456789abcdef","session":{"online":false}}`, 200)
+			require.NoError(t, err)
+
+			require.Equal(t, domain.PlayerStatus{
+				UUID:      UUID,
+				QueriedAt: now,
+			}, status)
+		})
+
+		t.Run("missing session", func(t *testing.T) {
+			t.Parallel()
+
+			status, err := getStatus(t, `{"success":true}`, 200)
+			require.NoError(t, err)
+			require.False(t, status.Online)
+		})
+
+		t.Run("only accepts normalized ids", func(t *testing.T) {
+			t.Parallel()
+
+			hypixelAPI := &mockedHypixelAPI{t: t}
+			provider, err := playerprovider.NewHypixelStatusProvider(hypixelAPI)
+			require.NoError(t, err)
+			_, err = provider.GetStatus(t.Context(), "0123456789abcdef0123456789abcdef")
+			require.Error(t, err)
+		})
+
+		t.Run("success=false from Hypixel", func(t *testing.T) {
+			t.Parallel()
+
+			_, err := getStatus(t, `{"success":false,"cause":"Invalid API key"}`, 200)
+			require.Error(t, err)
+			require.NotErrorIs(t, err, domain.ErrTemporarilyUnavailable)
+		})
+
+		t.Run("ratelimited", func(t *testing.T) {
+			t.Parallel()
+
+			_, err := getStatus(t, `{"success":false,"cause":"Key throttle"}`, 429)
+			require.ErrorIs(t, err, domain.ErrTemporarilyUnavailable)
+		})
+
+		t.Run("error from hypixel", func(t *testing.T) {
+			t.Parallel()
+
+			hypixelAPI := &mockedHypixelAPI{
+				t:          t,
+				statusCode: -1,
+				err:        assert.AnError,
+			}
+			provider, err := playerprovider.NewHypixelStatusProvider(hypixelAPI)
+			require.NoError(t, err)
+			_, err = provider.GetStatus(t.Context(), UUID)
+			require.ErrorIs(t, err, assert.AnError)
+		})
+	})
+}
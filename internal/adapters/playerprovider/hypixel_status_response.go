@@ -0,0 +1,84 @@
+package playerprovider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Amund211/flashlight/internal/domain"
+	"github.com/Amund211/flashlight/internal/logging"
+	"github.com/Amund211/flashlight/internal/reporting"
+)
+
+type hypixelStatusResponse struct {
+	Success bool                  `json:"success"`
+	Session *HypixelStatusSession `json:"session"`
+	Cause   *string               `json:"cause,omitempty"`
+}
+
+type HypixelStatusSession struct {
+	Online   bool    `json:"online"`
+	GameType *string `json:"gameType,omitempty"`
+	Mode     *string `json:"mode,omitempty"`
+	Map      *string `json:"map,omitempty"`
+}
+
+func HypixelStatusResponseToPlayerStatus(ctx context.Context, uuid string, queriedAt time.Time, statusData []byte, statusCode int) (domain.PlayerStatus, error) {
+	reportError := func(err error) {
+		reporting.Report(
+			ctx,
+			err,
+			map[string]string{
+				"statusCode": fmt.Sprint(statusCode),
+				"data":       string(statusData),
+			},
+		)
+	}
+
+	if err := checkForHypixelError(ctx, statusCode, statusData); err != nil {
+		reportError(err)
+		logging.FromContext(ctx).ErrorContext(
+			ctx,
+			"Got status response from hypixel",
+			"status", "error",
+			"error", err.Error(),
+			"data", string(statusData),
+			"statusCode", statusCode,
+			"contentLength", len(statusData),
+		)
+		return domain.PlayerStatus{}, err
+	}
+
+	response := new(hypixelStatusResponse)
+	if err := json.Unmarshal(statusData, response); err != nil {
+		err = fmt.Errorf("failed to parse status data: %w", err)
+		reportError(err)
+		return domain.PlayerStatus{}, err
+	}
+
+	if !response.Success {
+		cause := "unknown error (flashlight)"
+		if response.Cause != nil {
+			cause = *response.Cause
+		}
+		err := fmt.Errorf("got success=false from Hypixel: %s", cause)
+		reportError(err)
+		return domain.PlayerStatus{}, err
+	}
+
+	status := domain.PlayerStatus{
+		UUID:      uuid,
+		QueriedAt: queriedAt,
+	}
+
+	// A missing session is treated as offline
+	if response.Session != nil && response.Session.Online {
+		status.Online = true
+		status.GameType = response.Session.GameType
+		status.Mode = response.Session.Mode
+		status.Map = response.Session.Map
+	}
+
+	return status, nil
+}
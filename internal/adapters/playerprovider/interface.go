@@ -12,3 +12,15 @@ type PlayerProvider interface {
 	// Raises domain.ErrTemporarilyUnavailable if the provider implementation receives an error believed to be intermittent. The call may be retried later.
 	GetPlayer(ctx context.Context, uuid string) (*domain.PlayerPIT, error)
 }
+
+type StatusProvider interface {
+	// Raises domain.ErrTemporarilyUnavailable if the provider implementation receives an error believed to be intermittent. The call may be retried later.
+	GetStatus(ctx context.Context, uuid string) (domain.PlayerStatus, error)
+}
+
+type GuildProvider interface {
+	// Returns nil if the player is not in a guild
+	//
+	// Raises domain.ErrTemporarilyUnavailable if the provider implementation receives an error believed to be intermittent. The call may be retried later.
+	GetGuildByPlayer(ctx context.Context, uuid string) (*domain.Guild, error)
+}
@@ -22,7 +22,7 @@ import (
 	"github.com/Amund211/flashlight/internal/strutils"
 )
 
-const dataFormatVersion = 2
+const dataFormatVersion = 6
 
 type PostgresPlayerRepository struct {
 	db     *sqlx.DB
@@ -49,6 +49,147 @@ type playerDataStorage struct {
 	Fours      statsDataStorage `json:"4"`
 	Fourv4     statsDataStorage `json:"4v4"`
 	Overall    statsDataStorage `json:"all"`
+
+	// Added in data format version 3
+	DreamModes map[string]statsDataStorage `json:"dream,omitempty"`
+
+	// Added in data format version 4
+	Economy *economyDataStorage `json:"eco,omitempty"`
+
+	// Added in data format version 5
+	Games map[string]gameDataStorage `json:"games,omitempty"`
+
+	// Added in data format version 6
+	Rank              *rankDataStorage `json:"rank,omitempty"`
+	NetworkExperience *float64         `json:"nxp,omitempty"`
+}
+
+type rankDataStorage struct {
+	StaffRank          *string `json:"s,omitempty"`
+	PackageRank        *string `json:"p,omitempty"`
+	MonthlyPackageRank *string `json:"m,omitempty"`
+	RankPlusColor      *string `json:"c,omitempty"`
+}
+
+// gameDataStorage maps each mode of a game to the stats in it, all by their
+// storage keys
+type gameDataStorage map[string]map[string]int
+
+type economyDataStorage struct {
+	Coins               int `json:"c,omitempty"`
+	ChallengesCompleted int `json:"ch,omitempty"`
+	ItemsPurchased      int `json:"ip,omitempty"`
+	IronCollected       int `json:"fe,omitempty"`
+	GoldCollected       int `json:"au,omitempty"`
+	DiamondsCollected   int `json:"dia,omitempty"`
+	EmeraldsCollected   int `json:"em,omitempty"`
+}
+
+// dreamModeStorageKeys are the keys of the dream modes in playerDataStorage.
+// Kept distinct from domain.DreamMode so stored data doesn't depend on how
+// the domain constants are spelled.
+var dreamModeStorageKeys = map[domain.DreamMode]string{
+	domain.DreamModeCastle:            "castle",
+	domain.DreamModeRushSolo:          "rush1",
+	domain.DreamModeRushDoubles:       "rush2",
+	domain.DreamModeRushFours:         "rush4",
+	domain.DreamModeUltimateSolo:      "ultimate1",
+	domain.DreamModeUltimateDoubles:   "ultimate2",
+	domain.DreamModeUltimateFours:     "ultimate4",
+	domain.DreamModeLuckyDoubles:      "lucky2",
+	domain.DreamModeLuckyFours:        "lucky4",
+	domain.DreamModeVoidlessDoubles:   "voidless2",
+	domain.DreamModeVoidlessFours:     "voidless4",
+	domain.DreamModeArmedDoubles:      "armed2",
+	domain.DreamModeArmedFours:        "armed4",
+	domain.DreamModeSwapDoubles:       "swap2",
+	domain.DreamModeSwapFours:         "swap4",
+	domain.DreamModeUnderworldDoubles: "underworld2",
+	domain.DreamModeUnderworldFours:   "underworld4",
+}
+
+// gameStorageKeys, gameModeStorageKeys and gameStatStorageKeys are the keys
+// of the games other than bedwars, their modes and their stats in
+// playerDataStorage, kept distinct from the domain for the same reason.
+var gameStorageKeys = map[domain.Game]string{
+	domain.GameSkyWars: "sw",
+	domain.GameDuels:   "duels",
+}
+
+var gameModeStorageKeys = map[string]string{
+	domain.GameModeOverall:  "all",
+	domain.SkyWarsModeSolo:  "1",
+	domain.SkyWarsModeTeams: "t",
+	domain.DuelsModeUHC:     "uhc",
+	domain.DuelsModeSkyWars: "sw",
+	domain.DuelsModeClassic: "classic",
+	domain.DuelsModeBridge:  "bridge",
+	domain.DuelsModeSumo:    "sumo",
+	domain.DuelsModeOP:      "op",
+	domain.DuelsModeBow:     "bow",
+}
+
+var gameStatStorageKeys = map[domain.Stat]string{
+	domain.StatExperience:  "xp",
+	domain.StatWinstreak:   "ws",
+	domain.StatGamesPlayed: "gp",
+	domain.StatWins:        "w",
+	domain.StatLosses:      "l",
+	domain.StatBedsBroken:  "bb",
+	domain.StatBedsLost:    "bl",
+	domain.StatFinalKills:  "fk",
+	domain.StatFinalDeaths: "fd",
+	domain.StatKills:       "k",
+	domain.StatDeaths:      "d",
+}
+
+// fromStorageKey looks up the domain value stored as key in storageKeys
+func fromStorageKey[T comparable](storageKeys map[T]string, key string) (T, bool) {
+	for value, storageKey := range storageKeys {
+		if storageKey == key {
+			return value, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+func gameStatsToDataStorage(stats domain.GameStatsPIT) (gameDataStorage, error) {
+	data := make(gameDataStorage, len(stats))
+	for mode, modeStats := range stats {
+		modeKey, ok := gameModeStorageKeys[mode]
+		if !ok {
+			return nil, fmt.Errorf("unknown game mode: %q", mode)
+		}
+		data[modeKey] = make(map[string]int, len(modeStats))
+		for stat, value := range modeStats {
+			statKey, ok := gameStatStorageKeys[stat]
+			if !ok {
+				return nil, fmt.Errorf("unknown stat in %s: %q", mode, stat)
+			}
+			data[modeKey][statKey] = value
+		}
+	}
+	return data, nil
+}
+
+func gameStatsFromDataStorage(data gameDataStorage) (domain.GameStatsPIT, error) {
+	stats := make(domain.GameStatsPIT, len(data))
+	for modeKey, modeData := range data {
+		mode, ok := fromStorageKey(gameModeStorageKeys, modeKey)
+		if !ok {
+			return nil, fmt.Errorf("unknown game mode: %q", modeKey)
+		}
+		stats[mode] = make(domain.GameModeStatsPIT, len(modeData))
+		for statKey, value := range modeData {
+			stat, ok := fromStorageKey(gameStatStorageKeys, statKey)
+			if !ok {
+				return nil, fmt.Errorf("unknown stat in %s: %q", mode, statKey)
+			}
+			stats[mode][stat] = value
+		}
+	}
+	return stats, nil
 }
 
 type statsDataStorage struct {
@@ -105,6 +246,55 @@ func playerToDataStorage(player *domain.PlayerPIT) ([]byte, error) {
 		Fours:      gamemodeStatsToDataStorage(&player.Fours),
 		Fourv4:     gamemodeStatsToDataStorage(&player.Fourv4),
 		Overall:    gamemodeStatsToDataStorage(&player.Overall),
+
+		NetworkExperience: player.NetworkExperience,
+	}
+
+	if player.Rank != nil {
+		data.Rank = &rankDataStorage{
+			StaffRank:          player.Rank.StaffRank,
+			PackageRank:        player.Rank.PackageRank,
+			MonthlyPackageRank: player.Rank.MonthlyPackageRank,
+			RankPlusColor:      player.Rank.RankPlusColor,
+		}
+	}
+
+	if player.Economy != nil {
+		data.Economy = &economyDataStorage{
+			Coins:               player.Economy.Coins,
+			ChallengesCompleted: player.Economy.ChallengesCompleted,
+			ItemsPurchased:      player.Economy.ItemsPurchased,
+			IronCollected:       player.Economy.IronCollected,
+			GoldCollected:       player.Economy.GoldCollected,
+			DiamondsCollected:   player.Economy.DiamondsCollected,
+			EmeraldsCollected:   player.Economy.EmeraldsCollected,
+		}
+	}
+
+	for mode, stats := range player.DreamModes {
+		key, ok := dreamModeStorageKeys[mode]
+		if !ok {
+			return nil, fmt.Errorf("unknown dream mode: %q", mode)
+		}
+		if data.DreamModes == nil {
+			data.DreamModes = make(map[string]statsDataStorage, len(player.DreamModes))
+		}
+		data.DreamModes[key] = gamemodeStatsToDataStorage(&stats)
+	}
+
+	for game, stats := range player.Games {
+		key, ok := gameStorageKeys[game]
+		if !ok {
+			return nil, fmt.Errorf("unknown game: %q", game)
+		}
+		gameData, err := gameStatsToDataStorage(stats)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert %s stats: %w", game, err)
+		}
+		if data.Games == nil {
+			data.Games = make(map[string]gameDataStorage, len(player.Games))
+		}
+		data.Games[key] = gameData
 	}
 
 	json, err := json.Marshal(data)
@@ -141,6 +331,57 @@ func dbStatToPlayerPIT(dbStat dbStat) (*domain.PlayerPIT, error) {
 		experience = *playerData.Experience
 	}
 
+	var dreamModes map[domain.DreamMode]domain.GamemodeStatsPIT
+	for key, stats := range playerData.DreamModes {
+		mode, ok := fromStorageKey(dreamModeStorageKeys, key)
+		if !ok {
+			return nil, fmt.Errorf("unknown dream mode in player data: %q", key)
+		}
+		if dreamModes == nil {
+			dreamModes = make(map[domain.DreamMode]domain.GamemodeStatsPIT, len(playerData.DreamModes))
+		}
+		dreamModes[mode] = *gamemodeStatsPITFromDataStorage(&stats)
+	}
+
+	var games map[domain.Game]domain.GameStatsPIT
+	for key, gameData := range playerData.Games {
+		game, ok := fromStorageKey(gameStorageKeys, key)
+		if !ok {
+			return nil, fmt.Errorf("unknown game in player data: %q", key)
+		}
+		stats, err := gameStatsFromDataStorage(gameData)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert %s stats in player data: %w", game, err)
+		}
+		if games == nil {
+			games = make(map[domain.Game]domain.GameStatsPIT, len(playerData.Games))
+		}
+		games[game] = stats
+	}
+
+	var economy *domain.EconomyStatsPIT
+	if playerData.Economy != nil {
+		economy = &domain.EconomyStatsPIT{
+			Coins:               playerData.Economy.Coins,
+			ChallengesCompleted: playerData.Economy.ChallengesCompleted,
+			ItemsPurchased:      playerData.Economy.ItemsPurchased,
+			IronCollected:       playerData.Economy.IronCollected,
+			GoldCollected:       playerData.Economy.GoldCollected,
+			DiamondsCollected:   playerData.Economy.DiamondsCollected,
+			EmeraldsCollected:   playerData.Economy.EmeraldsCollected,
+		}
+	}
+
+	var rank *domain.RankPIT
+	if playerData.Rank != nil {
+		rank = &domain.RankPIT{
+			StaffRank:          playerData.Rank.StaffRank,
+			PackageRank:        playerData.Rank.PackageRank,
+			MonthlyPackageRank: playerData.Rank.MonthlyPackageRank,
+			RankPlusColor:      playerData.Rank.RankPlusColor,
+		}
+	}
+
 	return &domain.PlayerPIT{
 		DBID: &dbStat.ID,
 
@@ -155,6 +396,9 @@ func dbStatToPlayerPIT(dbStat dbStat) (*domain.PlayerPIT, error) {
 		LastLogin:   nil,
 		LastLogout:  nil,
 
+		Rank:              rank,
+		NetworkExperience: playerData.NetworkExperience,
+
 		Experience: experience,
 		Solo:       *gamemodeStatsPITFromDataStorage(&playerData.Solo),
 		Doubles:    *gamemodeStatsPITFromDataStorage(&playerData.Doubles),
@@ -162,6 +406,9 @@ func dbStatToPlayerPIT(dbStat dbStat) (*domain.PlayerPIT, error) {
 		Fours:      *gamemodeStatsPITFromDataStorage(&playerData.Fours),
 		Fourv4:     *gamemodeStatsPITFromDataStorage(&playerData.Fourv4),
 		Overall:    *gamemodeStatsPITFromDataStorage(&playerData.Overall),
+		DreamModes: dreamModes,
+		Economy:    economy,
+		Games:      games,
 	}, nil
 }
 
@@ -400,6 +647,46 @@ func (p *PostgresPlayerRepository) CountStats(ctx context.Context, playerUUID st
 	return count, nil
 }
 
+// ListActivePlayers returns the players with at least minStats stored stats
+// in [start, end), most active first.
+func (p *PostgresPlayerRepository) ListActivePlayers(ctx context.Context, start, end time.Time, minStats int) ([]string, error) {
+	ctx, span := p.tracer.Start(ctx, "PostgresPlayerRepository.ListActivePlayers")
+	defer span.End()
+
+	if !start.Before(end) {
+		err := fmt.Errorf("end time must be after start time")
+		reporting.Report(ctx, err, map[string]string{
+			"start": start.Format(time.RFC3339),
+			"end":   end.Format(time.RFC3339),
+		})
+		return nil, err
+	}
+
+	playerUUIDs := []string{}
+	err := p.db.SelectContext(
+		ctx,
+		&playerUUIDs,
+		fmt.Sprintf(`select player_uuid
+		from %s.stats
+		where queried_at >= $1 and queried_at < $2
+		group by player_uuid
+		having count(*) >= $3
+		order by count(*) desc, player_uuid asc`,
+			pq.QuoteIdentifier(p.schema)),
+		start, end, minStats)
+	if err != nil {
+		err := fmt.Errorf("failed to list active players: %w", err)
+		reporting.Report(ctx, err, map[string]string{
+			"start":    start.Format(time.RFC3339),
+			"end":      end.Format(time.RFC3339),
+			"minStats": strconv.Itoa(minStats),
+		})
+		return nil, err
+	}
+
+	return playerUUIDs, nil
+}
+
 func (p *PostgresPlayerRepository) GetHistory(ctx context.Context, playerUUID string, start, end time.Time, limit int) ([]domain.PlayerPIT, error) {
 	ctx, span := p.tracer.Start(ctx, "PostgresPlayerRepository.GetHistory")
 	defer span.End()
@@ -668,30 +955,12 @@ func (p *PostgresPlayerRepository) FindMilestoneAchievements(ctx context.Context
 
 	// Initialize variables for dynamic SQL building
 	defaultValue := 0
-	selector := "player_data->"
-
-	// Handle gamemode
-	switch gamemode {
-	case domain.GamemodeOverall:
-		selector = selector + "'all'" // nolint:staticcheck,ineffassign // Left for future implementation
-	default:
-		err := fmt.Errorf("only overall gamemode is supported")
-		reporting.Report(ctx, err)
-		return nil, err
-	}
-
-	// Handle stat
-	switch stat {
-	case domain.StatExperience:
-		if gamemode != domain.GamemodeOverall {
-			err := fmt.Errorf("only overall gamemode is supported for stat experience")
-			reporting.Report(ctx, err)
-			return nil, err
-		}
-		selector = "player_data->'xp'"
-	default:
-		err := fmt.Errorf("only experience stat is supported")
-		reporting.Report(ctx, err)
+	selector, err := milestoneSelector(gamemode, stat)
+	if err != nil {
+		reporting.Report(ctx, err, map[string]string{
+			"stat":     string(stat),
+			"gamemode": string(gamemode),
+		})
 		return nil, err
 	}
 
@@ -813,6 +1082,53 @@ func (p *PostgresPlayerRepository) FindMilestoneAchievements(ctx context.Context
 	return results, nil
 }
 
+// milestoneSelector returns the JSONB path to the stat in the player_data
+// column, matching the keys of playerDataStorage and statsDataStorage
+func milestoneSelector(gamemode domain.Gamemode, stat domain.Stat) (string, error) {
+	if stat == domain.StatExperience {
+		if gamemode != domain.GamemodeOverall {
+			return "", fmt.Errorf("only overall gamemode is supported for stat experience")
+		}
+		return "player_data->'xp'", nil
+	}
+
+	var gamemodeKey string
+	switch gamemode {
+	case domain.GamemodeSolo:
+		gamemodeKey = "1"
+	case domain.GamemodeDoubles:
+		gamemodeKey = "2"
+	case domain.GamemodeThrees:
+		gamemodeKey = "3"
+	case domain.GamemodeFours:
+		gamemodeKey = "4"
+	case domain.GamemodeFourv4:
+		gamemodeKey = "4v4"
+	case domain.GamemodeOverall:
+		gamemodeKey = "all"
+	default:
+		return "", fmt.Errorf("unsupported gamemode: %q", gamemode)
+	}
+
+	var statKey string
+	switch stat {
+	case domain.StatWins:
+		statKey = "w"
+	case domain.StatFinalKills:
+		statKey = "fk"
+	case domain.StatBedsBroken:
+		statKey = "bb"
+	case domain.StatKills:
+		statKey = "k"
+	case domain.StatGamesPlayed:
+		statKey = "gp"
+	default:
+		return "", fmt.Errorf("unsupported stat: %q", stat)
+	}
+
+	return fmt.Sprintf("player_data->'%s'->'%s'", gamemodeKey, statKey), nil
+}
+
 type StubPlayerRepository struct{}
 
 func (p *StubPlayerRepository) StorePlayer(ctx context.Context, player *domain.PlayerPIT) error {
@@ -486,6 +486,37 @@ func TestPostgresPlayerRepository(t *testing.T) {
 		})
 	})
 
+	t.Run("ListActivePlayers", func(t *testing.T) {
+		t.Parallel()
+		p := newPostgresPlayerRepository(t, db, "list_active_players_tests")
+
+		start := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+		end := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+		storeStats := func(t *testing.T, playerUUID string, queriedAt ...time.Time) {
+			t.Helper()
+			for i, at := range queriedAt {
+				require.NoError(t, p.StorePlayer(ctx, domaintest.NewPlayerBuilder(playerUUID).Fours().WithGamesPlayed(i+1).BuildPtr(at)))
+			}
+		}
+
+		mostActive := domaintest.NewUUID(t)
+		storeStats(t, mostActive, start, start.Add(time.Hour), start.Add(2*time.Hour))
+		active := domaintest.NewUUID(t)
+		storeStats(t, active, start.Add(24*time.Hour), end.Add(-time.Hour))
+		inactive := domaintest.NewUUID(t)
+		storeStats(t, inactive, start.Add(time.Hour))
+		outside := domaintest.NewUUID(t)
+		storeStats(t, outside, start.Add(-time.Hour), end, end.Add(time.Hour))
+
+		playerUUIDs, err := p.ListActivePlayers(ctx, start, end, 2)
+		require.NoError(t, err)
+		require.Equal(t, []string{mostActive, active}, playerUUIDs)
+
+		_, err = p.ListActivePlayers(ctx, end, start, 2)
+		require.Error(t, err)
+	})
+
 	t.Run("GetPlayerPITs", func(t *testing.T) {
 		t.Parallel()
 		p := newPostgresPlayerRepository(t, db, "get_player_pits_tests")
@@ -623,6 +654,60 @@ func TestPostgresPlayerRepository(t *testing.T) {
 					Kills:       408,
 					Deaths:      409,
 				},
+				DreamModes: map[domain.DreamMode]domain.GamemodeStatsPIT{
+					domain.DreamModeCastle: {
+						Winstreak:   new(500),
+						GamesPlayed: 501,
+						Wins:        502,
+						Losses:      503,
+						BedsBroken:  504,
+						BedsLost:    505,
+						FinalKills:  506,
+						FinalDeaths: 507,
+						Kills:       508,
+						Deaths:      509,
+					},
+					domain.DreamModeUnderworldFours: {
+						GamesPlayed: 601,
+						Wins:        602,
+					},
+				},
+				Economy: &domain.EconomyStatsPIT{
+					Coins:               701,
+					ChallengesCompleted: 702,
+					ItemsPurchased:      703,
+					IronCollected:       704,
+					GoldCollected:       705,
+					DiamondsCollected:   706,
+					EmeraldsCollected:   707,
+				},
+				Games: map[domain.Game]domain.GameStatsPIT{
+					domain.GameSkyWars: {
+						domain.GameModeOverall: {
+							domain.StatExperience: 801,
+							domain.StatWinstreak:  0,
+							domain.StatWins:       802,
+						},
+						domain.SkyWarsModeTeams: {
+							domain.StatKills: 803,
+						},
+					},
+					domain.GameDuels: {
+						domain.GameModeOverall: {
+							domain.StatWins: 901,
+						},
+						domain.DuelsModeBridge: {
+							domain.StatWinstreak: 902,
+							domain.StatDeaths:    903,
+						},
+					},
+				},
+				Rank: &domain.RankPIT{
+					PackageRank:        new("MVP_PLUS"),
+					MonthlyPackageRank: new("SUPERSTAR"),
+					RankPlusColor:      new("GOLD"),
+				},
+				NetworkExperience: new(1_234_567.5),
 			}
 
 			storePlayers(t, p, player)
@@ -642,6 +727,13 @@ func TestPostgresPlayerRepository(t *testing.T) {
 			domaintest.RequireEqualStats(t, player.Fours, result.Fours)
 			domaintest.RequireEqualStats(t, player.Fourv4, result.Fourv4)
 			domaintest.RequireEqualStats(t, player.Overall, result.Overall)
+			require.Len(t, result.DreamModes, 2)
+			domaintest.RequireEqualStats(t, player.DreamModes[domain.DreamModeCastle], result.DreamModes[domain.DreamModeCastle])
+			domaintest.RequireEqualStats(t, player.DreamModes[domain.DreamModeUnderworldFours], result.DreamModes[domain.DreamModeUnderworldFours])
+			require.Equal(t, player.Economy, result.Economy)
+			require.Equal(t, player.Games, result.Games)
+			require.Equal(t, player.Rank, result.Rank)
+			require.Equal(t, player.NetworkExperience, result.NetworkExperience)
 
 			// Not stored to postgres
 			require.Empty(t, result.Displayname)
@@ -1144,14 +1236,77 @@ func TestPostgresPlayerRepository(t *testing.T) {
 			}
 		})
 
+		t.Run("Gamemode stats", func(t *testing.T) {
+			t.Parallel()
+			p := newPostgresPlayerRepository(t, db, "find_milestone_gamemode_stats")
+			playerUUID := domaintest.NewUUID(t)
+
+			// NOTE: Builders are mutable, so each stored player gets a new one
+			playerWith := func(fourWins, soloFinalKills int, queriedAt time.Time) *domain.PlayerPIT {
+				b := domaintest.NewPlayerBuilder(playerUUID)
+				b.Fours().WithWins(fourWins).WithGamesPlayed(fourWins * 2)
+				b.Solo().WithFinalKills(soloFinalKills)
+				return b.BuildPtr(queriedAt)
+			}
+			stored := storePlayers(t, p,
+				playerWith(0, 10, time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC)),
+				playerWith(90, 10, time.Date(2024, time.January, 2, 12, 0, 0, 0, time.UTC)),
+				playerWith(120, 55, time.Date(2024, time.January, 3, 12, 0, 0, 0, time.UTC)),
+			)
+
+			requireAchievements := func(t *testing.T, achievements []domain.MilestoneAchievement, expected map[int64]int) {
+				t.Helper()
+				require.Len(t, achievements, len(expected))
+				for _, achievement := range achievements {
+					storedIndex, ok := expected[achievement.Milestone]
+					require.True(t, ok, "unexpected milestone %d", achievement.Milestone)
+					require.NotNil(t, achievement.After)
+					require.Equal(t, *stored[storedIndex].DBID, *achievement.After.Player.DBID)
+				}
+			}
+
+			achievements, err := p.FindMilestoneAchievements(ctx, playerUUID, domain.GamemodeFours, domain.StatWins, []int64{50, 100, 200})
+			require.NoError(t, err)
+			requireAchievements(t, achievements, map[int64]int{50: 1, 100: 2})
+			require.Equal(t, int64(90), achievements[0].After.Value)
+
+			achievements, err = p.FindMilestoneAchievements(ctx, playerUUID, domain.GamemodeFours, domain.StatGamesPlayed, []int64{200})
+			require.NoError(t, err)
+			requireAchievements(t, achievements, map[int64]int{200: 2})
+
+			achievements, err = p.FindMilestoneAchievements(ctx, playerUUID, domain.GamemodeSolo, domain.StatFinalKills, []int64{10, 50})
+			require.NoError(t, err)
+			requireAchievements(t, achievements, map[int64]int{10: 0, 50: 2})
+
+			// Overall sums the modes
+			achievements, err = p.FindMilestoneAchievements(ctx, playerUUID, domain.GamemodeOverall, domain.StatWins, []int64{100})
+			require.NoError(t, err)
+			requireAchievements(t, achievements, map[int64]int{100: 2})
+
+			// Missing stats count as 0
+			achievements, err = p.FindMilestoneAchievements(ctx, playerUUID, domain.GamemodeDoubles, domain.StatBedsBroken, []int64{1})
+			require.NoError(t, err)
+			require.Empty(t, achievements)
+		})
+
+		t.Run("Experience in a gamemode", func(t *testing.T) {
+			t.Parallel()
+			p := newPostgresPlayerRepository(t, db, "find_milestone_experience_gamemode")
+			playerUUID := domaintest.NewUUID(t)
+
+			_, err := p.FindMilestoneAchievements(ctx, playerUUID, domain.GamemodeFours, domain.StatExperience, []int64{1000})
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "only overall gamemode is supported for stat experience")
+		})
+
 		t.Run("Unsupported gamemode", func(t *testing.T) {
 			t.Parallel()
 			p := newPostgresPlayerRepository(t, db, "find_milestone_unsupported_gamemode")
 			playerUUID := domaintest.NewUUID(t)
 
-			_, err := p.FindMilestoneAchievements(ctx, playerUUID, domain.Gamemode("UNSUPPORTED"), domain.StatExperience, []int64{1000})
+			_, err := p.FindMilestoneAchievements(ctx, playerUUID, domain.Gamemode("UNSUPPORTED"), domain.StatWins, []int64{1000})
 			require.Error(t, err)
-			require.Contains(t, err.Error(), "only overall gamemode is supported")
+			require.Contains(t, err.Error(), "unsupported gamemode")
 		})
 
 		t.Run("Unsupported stat", func(t *testing.T) {
@@ -1161,7 +1316,7 @@ func TestPostgresPlayerRepository(t *testing.T) {
 
 			_, err := p.FindMilestoneAchievements(ctx, playerUUID, domain.GamemodeOverall, domain.Stat("UNSUPPORTED"), []int64{1000})
 			require.Error(t, err)
-			require.Contains(t, err.Error(), "only experience stat is supported")
+			require.Contains(t, err.Error(), "unsupported stat")
 		})
 
 		t.Run("Empty milestones", func(t *testing.T) {
@@ -0,0 +1,421 @@
+package prismnoticerepository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/Amund211/flashlight/internal/domain"
+	"github.com/Amund211/flashlight/internal/reporting"
+)
+
+type Postgres struct {
+	db     *sqlx.DB
+	schema string
+	tracer trace.Tracer
+}
+
+func NewPostgres(db *sqlx.DB, schema string) *Postgres {
+	return &Postgres{
+		db:     db,
+		schema: schema,
+		tracer: otel.Tracer("flashlight/prismnoticerepository/postgres"),
+	}
+}
+
+const noticeColumns = `id, message, url, severity, duration_seconds, frequency, active_from, active_until,
+	target_user_ids, min_prism_version, max_prism_version, target_client_types, rollout_percentage,
+	created_at, updated_at`
+
+type dbPrismNotice struct {
+	ID                string          `db:"id"`
+	Message           string          `db:"message"`
+	URL               string          `db:"url"`
+	Severity          string          `db:"severity"`
+	DurationSeconds   sql.NullFloat64 `db:"duration_seconds"`
+	Frequency         string          `db:"frequency"`
+	ActiveFrom        sql.NullTime    `db:"active_from"`
+	ActiveUntil       sql.NullTime    `db:"active_until"`
+	TargetUserIDs     pq.StringArray  `db:"target_user_ids"`
+	MinPrismVersion   string          `db:"min_prism_version"`
+	MaxPrismVersion   string          `db:"max_prism_version"`
+	TargetClientTypes pq.StringArray  `db:"target_client_types"`
+	RolloutPercentage int             `db:"rollout_percentage"`
+	CreatedAt         time.Time       `db:"created_at"`
+	UpdatedAt         time.Time       `db:"updated_at"`
+}
+
+func severityFromDB(s string) (domain.NoticeSeverity, error) {
+	switch domain.NoticeSeverity(s) {
+	case domain.NoticeSeverityInfo, domain.NoticeSeverityUpdate, domain.NoticeSeverityWarning, domain.NoticeSeverityCritical:
+		return domain.NoticeSeverity(s), nil
+	default:
+		return "", fmt.Errorf("unknown severity in db: %q", s)
+	}
+}
+
+func frequencyFromDB(s string) (domain.NoticeFrequency, error) {
+	switch domain.NoticeFrequency(s) {
+	case domain.NoticeFrequencyAlways, domain.NoticeFrequencyOnce, domain.NoticeFrequencyDaily, domain.NoticeFrequencyUntilAcknowledged:
+		return domain.NoticeFrequency(s), nil
+	default:
+		return "", fmt.Errorf("unknown frequency in db: %q", s)
+	}
+}
+
+func nullTimeToPtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	utc := t.Time.UTC()
+	return &utc
+}
+
+func ptrToNullTime(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: *t, Valid: true}
+}
+
+func (r dbPrismNotice) toDomain() (domain.PrismNotice, error) {
+	severity, err := severityFromDB(r.Severity)
+	if err != nil {
+		return domain.PrismNotice{}, err
+	}
+	frequency, err := frequencyFromDB(r.Frequency)
+	if err != nil {
+		return domain.PrismNotice{}, err
+	}
+
+	var durationSeconds *float64
+	if r.DurationSeconds.Valid {
+		durationSeconds = &r.DurationSeconds.Float64
+	}
+
+	return domain.PrismNotice{
+		ID:              r.ID,
+		Message:         r.Message,
+		URL:             r.URL,
+		Severity:        severity,
+		DurationSeconds: durationSeconds,
+		Frequency:       frequency,
+		ActiveFrom:      nullTimeToPtr(r.ActiveFrom),
+		ActiveUntil:     nullTimeToPtr(r.ActiveUntil),
+		Targeting: domain.NoticeTargeting{
+			UserIDs:           []string(r.TargetUserIDs),
+			MinPrismVersion:   r.MinPrismVersion,
+			MaxPrismVersion:   r.MaxPrismVersion,
+			ClientTypes:       []string(r.TargetClientTypes),
+			RolloutPercentage: r.RolloutPercentage,
+		},
+		CreatedAt: r.CreatedAt.UTC(),
+		UpdatedAt: r.UpdatedAt.UTC(),
+	}, nil
+}
+
+func toDB(notice domain.PrismNotice) dbPrismNotice {
+	var durationSeconds sql.NullFloat64
+	if notice.DurationSeconds != nil {
+		durationSeconds = sql.NullFloat64{Float64: *notice.DurationSeconds, Valid: true}
+	}
+
+	// The array columns are NOT NULL, and pq encodes a nil slice as NULL
+	userIDs := pq.StringArray{}
+	userIDs = append(userIDs, notice.Targeting.UserIDs...)
+	clientTypes := pq.StringArray{}
+	clientTypes = append(clientTypes, notice.Targeting.ClientTypes...)
+
+	return dbPrismNotice{
+		ID:                notice.ID,
+		Message:           notice.Message,
+		URL:               notice.URL,
+		Severity:          string(notice.Severity),
+		DurationSeconds:   durationSeconds,
+		Frequency:         string(notice.Frequency),
+		ActiveFrom:        ptrToNullTime(notice.ActiveFrom),
+		ActiveUntil:       ptrToNullTime(notice.ActiveUntil),
+		TargetUserIDs:     userIDs,
+		MinPrismVersion:   notice.Targeting.MinPrismVersion,
+		MaxPrismVersion:   notice.Targeting.MaxPrismVersion,
+		TargetClientTypes: clientTypes,
+		RolloutPercentage: notice.Targeting.RolloutPercentage,
+		CreatedAt:         notice.CreatedAt,
+		UpdatedAt:         notice.UpdatedAt,
+	}
+}
+
+// ListPrismNotices returns every notice, active or not, oldest first.
+func (p *Postgres) ListPrismNotices(ctx context.Context) ([]domain.PrismNotice, error) {
+	ctx, span := p.tracer.Start(ctx, "Postgres.ListPrismNotices")
+	defer span.End()
+
+	var rows []dbPrismNotice
+	err := p.db.SelectContext(
+		ctx,
+		&rows,
+		fmt.Sprintf(`SELECT %s FROM %s.prism_notices ORDER BY created_at, id`,
+			noticeColumns,
+			pq.QuoteIdentifier(p.schema)),
+	)
+	if err != nil {
+		err := fmt.Errorf("failed to list prism notices: %w", err)
+		reporting.Report(ctx, err)
+		return nil, err
+	}
+
+	notices := make([]domain.PrismNotice, 0, len(rows))
+	for _, row := range rows {
+		notice, err := row.toDomain()
+		if err != nil {
+			err := fmt.Errorf("failed to decode prism notice: %w", err)
+			reporting.Report(ctx, err, map[string]string{
+				"noticeID": row.ID,
+			})
+			return nil, err
+		}
+		notices = append(notices, notice)
+	}
+
+	return notices, nil
+}
+
+// GetPrismNotice returns domain.ErrPrismNoticeNotFound for an unknown id.
+func (p *Postgres) GetPrismNotice(ctx context.Context, id string) (domain.PrismNotice, error) {
+	ctx, span := p.tracer.Start(ctx, "Postgres.GetPrismNotice")
+	defer span.End()
+
+	var row dbPrismNotice
+	err := p.db.QueryRowxContext(
+		ctx,
+		fmt.Sprintf(`SELECT %s FROM %s.prism_notices WHERE id = $1`,
+			noticeColumns,
+			pq.QuoteIdentifier(p.schema)),
+		id,
+	).StructScan(&row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.PrismNotice{}, domain.ErrPrismNoticeNotFound
+	}
+	if err != nil {
+		err := fmt.Errorf("failed to get prism notice: %w", err)
+		reporting.Report(ctx, err, map[string]string{
+			"noticeID": id,
+		})
+		return domain.PrismNotice{}, err
+	}
+
+	notice, err := row.toDomain()
+	if err != nil {
+		err := fmt.Errorf("failed to decode prism notice: %w", err)
+		reporting.Report(ctx, err, map[string]string{
+			"noticeID": id,
+		})
+		return domain.PrismNotice{}, err
+	}
+	return notice, nil
+}
+
+// CreatePrismNotice inserts a complete notice. The caller fills in every
+// field, including a unique ID and the timestamps.
+func (p *Postgres) CreatePrismNotice(ctx context.Context, notice domain.PrismNotice) error {
+	ctx, span := p.tracer.Start(ctx, "Postgres.CreatePrismNotice")
+	defer span.End()
+
+	_, err := p.db.NamedExecContext(
+		ctx,
+		fmt.Sprintf(`INSERT INTO %s.prism_notices (%s)
+		VALUES (:id, :message, :url, :severity, :duration_seconds, :frequency, :active_from, :active_until,
+			:target_user_ids, :min_prism_version, :max_prism_version, :target_client_types, :rollout_percentage,
+			:created_at, :updated_at)`,
+			pq.QuoteIdentifier(p.schema),
+			noticeColumns),
+		toDB(notice),
+	)
+	if err != nil {
+		err := fmt.Errorf("failed to insert prism notice: %w", err)
+		reporting.Report(ctx, err, map[string]string{
+			"noticeID": notice.ID,
+		})
+		return err
+	}
+	return nil
+}
+
+// UpdatePrismNotice replaces every field of an existing notice except
+// CreatedAt. Returns domain.ErrPrismNoticeNotFound for an unknown id.
+func (p *Postgres) UpdatePrismNotice(ctx context.Context, notice domain.PrismNotice) error {
+	ctx, span := p.tracer.Start(ctx, "Postgres.UpdatePrismNotice")
+	defer span.End()
+
+	result, err := p.db.NamedExecContext(
+		ctx,
+		fmt.Sprintf(`UPDATE %s.prism_notices SET
+			message = :message,
+			url = :url,
+			severity = :severity,
+			duration_seconds = :duration_seconds,
+			frequency = :frequency,
+			active_from = :active_from,
+			active_until = :active_until,
+			target_user_ids = :target_user_ids,
+			min_prism_version = :min_prism_version,
+			max_prism_version = :max_prism_version,
+			target_client_types = :target_client_types,
+			rollout_percentage = :rollout_percentage,
+			updated_at = :updated_at
+		WHERE id = :id`,
+			pq.QuoteIdentifier(p.schema)),
+		toDB(notice),
+	)
+	if err != nil {
+		err := fmt.Errorf("failed to update prism notice: %w", err)
+		reporting.Report(ctx, err, map[string]string{
+			"noticeID": notice.ID,
+		})
+		return err
+	}
+
+	return p.requireOneRow(ctx, result, notice.ID)
+}
+
+// DeletePrismNotice returns domain.ErrPrismNoticeNotFound for an unknown id.
+func (p *Postgres) DeletePrismNotice(ctx context.Context, id string) error {
+	ctx, span := p.tracer.Start(ctx, "Postgres.DeletePrismNotice")
+	defer span.End()
+
+	result, err := p.db.ExecContext(
+		ctx,
+		fmt.Sprintf(`DELETE FROM %s.prism_notices WHERE id = $1`,
+			pq.QuoteIdentifier(p.schema)),
+		id,
+	)
+	if err != nil {
+		err := fmt.Errorf("failed to delete prism notice: %w", err)
+		reporting.Report(ctx, err, map[string]string{
+			"noticeID": id,
+		})
+		return err
+	}
+
+	return p.requireOneRow(ctx, result, id)
+}
+
+func (p *Postgres) requireOneRow(ctx context.Context, result sql.Result, id string) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		err := fmt.Errorf("failed to get rows affected: %w", err)
+		reporting.Report(ctx, err, map[string]string{
+			"noticeID": id,
+		})
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrPrismNoticeNotFound
+	}
+	return nil
+}
+
+// GetPrismNoticeViews returns what userID has seen of each of noticeIDs.
+// Notices the user has never been shown are left out.
+func (p *Postgres) GetPrismNoticeViews(ctx context.Context, userID string, noticeIDs []string) (map[string]domain.PrismNoticeView, error) {
+	ctx, span := p.tracer.Start(ctx, "Postgres.GetPrismNoticeViews")
+	defer span.End()
+
+	var rows []struct {
+		NoticeID       string       `db:"notice_id"`
+		LastShownAt    sql.NullTime `db:"last_shown_at"`
+		AcknowledgedAt sql.NullTime `db:"acknowledged_at"`
+	}
+	err := p.db.SelectContext(
+		ctx,
+		&rows,
+		fmt.Sprintf(`SELECT notice_id, last_shown_at, acknowledged_at
+		FROM %s.prism_notice_views
+		WHERE user_id = $1 AND notice_id = ANY($2)`,
+			pq.QuoteIdentifier(p.schema)),
+		userID,
+		pq.StringArray(noticeIDs),
+	)
+	if err != nil {
+		err := fmt.Errorf("failed to get prism notice views: %w", err)
+		reporting.Report(ctx, err, map[string]string{
+			"userID": userID,
+		})
+		return nil, err
+	}
+
+	views := make(map[string]domain.PrismNoticeView, len(rows))
+	for _, row := range rows {
+		views[row.NoticeID] = domain.PrismNoticeView{
+			LastShownAt:    nullTimeToPtr(row.LastShownAt),
+			AcknowledgedAt: nullTimeToPtr(row.AcknowledgedAt),
+		}
+	}
+	return views, nil
+}
+
+// RecordPrismNoticesShown sets when userID was last shown each of noticeIDs.
+// Ids of notices that have since been deleted are skipped.
+func (p *Postgres) RecordPrismNoticesShown(ctx context.Context, userID string, noticeIDs []string, shownAt time.Time) error {
+	ctx, span := p.tracer.Start(ctx, "Postgres.RecordPrismNoticesShown")
+	defer span.End()
+
+	// Selecting the ids from prism_notices keeps a notice deleted since it
+	// was cached from failing the foreign key for the whole batch. The
+	// casts are needed since parameters in a select list have no type.
+	_, err := p.db.ExecContext(
+		ctx,
+		fmt.Sprintf(`INSERT INTO %[1]s.prism_notice_views (user_id, notice_id, last_shown_at)
+		SELECT $1::TEXT, id, $3::TIMESTAMPTZ FROM %[1]s.prism_notices WHERE id = ANY($2)
+		ON CONFLICT (user_id, notice_id) DO UPDATE SET last_shown_at = EXCLUDED.last_shown_at`,
+			pq.QuoteIdentifier(p.schema)),
+		userID,
+		pq.StringArray(noticeIDs),
+		shownAt,
+	)
+	if err != nil {
+		err := fmt.Errorf("failed to record prism notices shown: %w", err)
+		reporting.Report(ctx, err, map[string]string{
+			"userID": userID,
+		})
+		return err
+	}
+	return nil
+}
+
+// AcknowledgePrismNotice records that userID acknowledged the notice. The
+// first acknowledgement is kept. Returns domain.ErrPrismNoticeNotFound for
+// an unknown notice id.
+func (p *Postgres) AcknowledgePrismNotice(ctx context.Context, userID string, noticeID string, acknowledgedAt time.Time) error {
+	ctx, span := p.tracer.Start(ctx, "Postgres.AcknowledgePrismNotice")
+	defer span.End()
+
+	result, err := p.db.ExecContext(
+		ctx,
+		fmt.Sprintf(`INSERT INTO %[1]s.prism_notice_views (user_id, notice_id, acknowledged_at)
+		SELECT $1::TEXT, id, $3::TIMESTAMPTZ FROM %[1]s.prism_notices WHERE id = $2
+		ON CONFLICT (user_id, notice_id) DO UPDATE
+		SET acknowledged_at = COALESCE(prism_notice_views.acknowledged_at, EXCLUDED.acknowledged_at)`,
+			pq.QuoteIdentifier(p.schema)),
+		userID,
+		noticeID,
+		acknowledgedAt,
+	)
+	if err != nil {
+		err := fmt.Errorf("failed to acknowledge prism notice: %w", err)
+		reporting.Report(ctx, err, map[string]string{
+			"userID":   userID,
+			"noticeID": noticeID,
+		})
+		return err
+	}
+
+	return p.requireOneRow(ctx, result, noticeID)
+}
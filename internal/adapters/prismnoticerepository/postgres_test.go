@@ -0,0 +1,200 @@
+package prismnoticerepository
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Amund211/flashlight/internal/adapters/database"
+	"github.com/Amund211/flashlight/internal/domain"
+)
+
+func newPostgres(t *testing.T, db *sqlx.DB, schemaSuffix string) *Postgres {
+	require.NotEmpty(t, schemaSuffix, "schemaSuffix must not be empty")
+	schema := fmt.Sprintf("prism_notices_repo_test_%s", schemaSuffix)
+
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+	db.MustExec(fmt.Sprintf("DROP SCHEMA IF EXISTS %s CASCADE", pq.QuoteIdentifier(schema)))
+
+	migrator := database.NewDatabaseMigrator(db, logger)
+
+	err := migrator.Migrate(t.Context(), schema)
+	require.NoError(t, err)
+
+	return NewPostgres(db, schema)
+}
+
+func TestPostgresPrismNotices(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping db tests in short mode.")
+	}
+	t.Parallel()
+
+	db, err := database.NewPostgresDatabase(database.LocalConnectionString)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	now := time.Date(2026, time.October, 1, 12, 0, 0, 0, time.UTC)
+
+	requireEqualNotices := func(t *testing.T, expected, actual domain.PrismNotice) {
+		t.Helper()
+		// Time can get truncated when round-tripping to the database
+		require.WithinDuration(t, expected.CreatedAt, actual.CreatedAt, time.Millisecond)
+		require.WithinDuration(t, expected.UpdatedAt, actual.UpdatedAt, time.Millisecond)
+		expected.CreatedAt, actual.CreatedAt = time.Time{}, time.Time{}
+		expected.UpdatedAt, actual.UpdatedAt = time.Time{}, time.Time{}
+		require.Equal(t, expected, actual)
+	}
+
+	t.Run("seeded notices", func(t *testing.T) {
+		t.Parallel()
+		p := newPostgres(t, db, "seeded")
+
+		notices, err := p.ListPrismNotices(t.Context())
+		require.NoError(t, err)
+		require.Len(t, notices, 1)
+
+		warning := notices[0]
+		require.Equal(t, "b6c0f5a4-4f7e-4d1c-9a51-0c6f2d8e7a10", warning.ID)
+		require.Equal(t, domain.NoticeSeverityWarning, warning.Severity)
+		require.Nil(t, warning.ActiveFrom)
+		require.Len(t, warning.Targeting.UserIDs, 11)
+		require.Equal(t, 100, warning.Targeting.RolloutPercentage)
+		require.Equal(t, domain.NoticeFrequencyDaily, warning.Frequency)
+	})
+
+	t.Run("create, get, update, delete", func(t *testing.T) {
+		t.Parallel()
+		ctx := t.Context()
+		p := newPostgres(t, db, "crud")
+
+		activeFrom := now.Add(time.Hour)
+		notice := domain.PrismNotice{
+			ID:              "7a4b2c1d-0000-4000-8000-000000000001",
+			Message:         "Hypixel is having issues",
+			URL:             "https://status.hypixel.net",
+			Severity:        domain.NoticeSeverityCritical,
+			DurationSeconds: new(30.0),
+			Frequency:       domain.NoticeFrequencyOnce,
+			ActiveFrom:      &activeFrom,
+			Targeting: domain.NoticeTargeting{
+				UserIDs:           []string{"user-1", "user-2"},
+				MinPrismVersion:   "v1.12.0",
+				ClientTypes:       []string{"prism"},
+				RolloutPercentage: 50,
+			},
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+
+		require.NoError(t, p.CreatePrismNotice(ctx, notice))
+
+		stored, err := p.GetPrismNotice(ctx, notice.ID)
+		require.NoError(t, err)
+		requireEqualNotices(t, notice, stored)
+
+		notice.Message = "Hypixel is back"
+		notice.DurationSeconds = nil
+		notice.Frequency = domain.NoticeFrequencyAlways
+		notice.ActiveFrom = nil
+		notice.Targeting = domain.NoticeTargeting{UserIDs: []string{}, ClientTypes: []string{}, RolloutPercentage: 100}
+		notice.UpdatedAt = now.Add(time.Minute)
+		require.NoError(t, p.UpdatePrismNotice(ctx, notice))
+
+		stored, err = p.GetPrismNotice(ctx, notice.ID)
+		require.NoError(t, err)
+		requireEqualNotices(t, notice, stored)
+
+		notices, err := p.ListPrismNotices(ctx)
+		require.NoError(t, err)
+		require.Len(t, notices, 2)
+
+		require.NoError(t, p.DeletePrismNotice(ctx, notice.ID))
+		_, err = p.GetPrismNotice(ctx, notice.ID)
+		require.ErrorIs(t, err, domain.ErrPrismNoticeNotFound)
+	})
+
+	t.Run("unknown ids", func(t *testing.T) {
+		t.Parallel()
+		ctx := t.Context()
+		p := newPostgres(t, db, "unknown")
+
+		_, err := p.GetPrismNotice(ctx, "does-not-exist")
+		require.ErrorIs(t, err, domain.ErrPrismNoticeNotFound)
+
+		err = p.UpdatePrismNotice(ctx, domain.PrismNotice{ID: "does-not-exist", Severity: domain.NoticeSeverityInfo, Frequency: domain.NoticeFrequencyAlways, UpdatedAt: now})
+		require.ErrorIs(t, err, domain.ErrPrismNoticeNotFound)
+
+		err = p.DeletePrismNotice(ctx, "does-not-exist")
+		require.ErrorIs(t, err, domain.ErrPrismNoticeNotFound)
+	})
+
+	t.Run("views", func(t *testing.T) {
+		t.Parallel()
+		ctx := t.Context()
+		p := newPostgres(t, db, "views")
+
+		const noticeID = "7a4b2c1d-0000-4000-8000-000000000002"
+		const warningID = "b6c0f5a4-4f7e-4d1c-9a51-0c6f2d8e7a10"
+		ids := []string{noticeID, warningID, "does-not-exist"}
+
+		require.NoError(t, p.CreatePrismNotice(ctx, domain.PrismNotice{
+			ID:        noticeID,
+			Message:   "Click here to view your Prism Wrapped 2026",
+			URL:       "https://prismoverlay.com/wrapped",
+			Severity:  domain.NoticeSeverityInfo,
+			Frequency: domain.NoticeFrequencyUntilAcknowledged,
+			Targeting: domain.NoticeTargeting{UserIDs: []string{}, ClientTypes: []string{}, RolloutPercentage: 100},
+			CreatedAt: now,
+			UpdatedAt: now,
+		}))
+
+		views, err := p.GetPrismNoticeViews(ctx, "user", ids)
+		require.NoError(t, err)
+		require.Empty(t, views)
+
+		// The unknown id is skipped rather than failing the batch
+		require.NoError(t, p.RecordPrismNoticesShown(ctx, "user", ids, now))
+		require.NoError(t, p.RecordPrismNoticesShown(ctx, "user", []string{noticeID}, now.Add(time.Hour)))
+
+		views, err = p.GetPrismNoticeViews(ctx, "user", ids)
+		require.NoError(t, err)
+		require.Len(t, views, 2)
+		require.WithinDuration(t, now.Add(time.Hour), *views[noticeID].LastShownAt, time.Millisecond)
+		require.WithinDuration(t, now, *views[warningID].LastShownAt, time.Millisecond)
+		require.Nil(t, views[noticeID].AcknowledgedAt)
+
+		require.NoError(t, p.AcknowledgePrismNotice(ctx, "user", noticeID, now.Add(2*time.Hour)))
+		require.NoError(t, p.AcknowledgePrismNotice(ctx, "user", noticeID, now.Add(3*time.Hour)))
+		require.NoError(t, p.AcknowledgePrismNotice(ctx, "other-user", warningID, now))
+
+		views, err = p.GetPrismNoticeViews(ctx, "user", ids)
+		require.NoError(t, err)
+		require.WithinDuration(t, now.Add(2*time.Hour), *views[noticeID].AcknowledgedAt, time.Millisecond,
+			"the first acknowledgement is kept")
+		require.WithinDuration(t, now.Add(time.Hour), *views[noticeID].LastShownAt, time.Millisecond)
+		require.Nil(t, views[warningID].AcknowledgedAt, "views are per user")
+
+		views, err = p.GetPrismNoticeViews(ctx, "other-user", ids)
+		require.NoError(t, err)
+		require.Len(t, views, 1)
+		require.Nil(t, views[warningID].LastShownAt)
+		require.NotNil(t, views[warningID].AcknowledgedAt)
+
+		err = p.AcknowledgePrismNotice(ctx, "user", "does-not-exist", now)
+		require.ErrorIs(t, err, domain.ErrPrismNoticeNotFound)
+
+		// Deleting a notice deletes its views
+		require.NoError(t, p.DeletePrismNotice(ctx, noticeID))
+		views, err = p.GetPrismNoticeViews(ctx, "user", ids)
+		require.NoError(t, err)
+		require.Len(t, views, 1)
+	})
+}
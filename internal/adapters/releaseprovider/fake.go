@@ -0,0 +1,52 @@
+package releaseprovider
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/Amund211/flashlight/internal/domain"
+)
+
+// Fake is an in-memory release provider for tests
+type Fake struct {
+	mu       sync.Mutex
+	releases []domain.PrismRelease
+	err      error
+	calls    int
+}
+
+func NewFake(releases ...domain.PrismRelease) *Fake {
+	return &Fake{releases: releases}
+}
+
+func (f *Fake) ListPrismReleases(ctx context.Context) ([]domain.PrismRelease, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return slices.Clone(f.releases), nil
+}
+
+// SetReleases replaces the feed, as when a release is published
+func (f *Fake) SetReleases(releases ...domain.PrismRelease) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.releases = releases
+}
+
+// SetError makes every following call fail with err, until cleared with nil
+func (f *Fake) SetError(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.err = err
+}
+
+// Calls is the number of times the feed has been read
+func (f *Fake) Calls() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls
+}
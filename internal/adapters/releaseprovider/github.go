@@ -0,0 +1,138 @@
+package releaseprovider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/Amund211/flashlight/internal/constants"
+	"github.com/Amund211/flashlight/internal/domain"
+	"github.com/Amund211/flashlight/internal/reporting"
+)
+
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// GitHub reads the releases feed of a GitHub repository
+type GitHub struct {
+	httpClient HTTPClient
+	repository string
+
+	tracer trace.Tracer
+}
+
+// NewGitHub returns a release provider for repository, given as owner/name
+func NewGitHub(httpClient HTTPClient, repository string) (*GitHub, error) {
+	owner, name, ok := strings.Cut(repository, "/")
+	if !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+		return nil, fmt.Errorf("invalid repository %q: expected owner/name", repository)
+	}
+
+	return &GitHub{
+		httpClient: httpClient,
+		repository: repository,
+
+		tracer: otel.Tracer("flashlight/releaseprovider/github"),
+	}, nil
+}
+
+func (g *GitHub) ListPrismReleases(ctx context.Context) ([]domain.PrismRelease, error) {
+	ctx, span := g.tracer.Start(ctx, "GitHub.ListPrismReleases")
+	defer span.End()
+
+	// The feed is sorted newest first, so one page covers every release
+	// still in use
+	url := fmt.Sprintf("https://api.github.com/repos/%s/releases?per_page=30", g.repository)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, http.NoBody)
+	if err != nil {
+		err := fmt.Errorf("failed to create request: %w", err)
+		reporting.Report(ctx, err)
+		return nil, err
+	}
+
+	req.Header.Set("User-Agent", constants.UserAgent)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+
+	resp, err := g.httpClient.Do(req)
+	if err != nil {
+		err := fmt.Errorf("%w: failed to send request: %w", domain.ErrTemporarilyUnavailable, err)
+		// Don't report to sentry, the next refresh will try again
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		err := fmt.Errorf("failed to read response body: %w", err)
+		reporting.Report(ctx, err)
+		return nil, err
+	}
+
+	releases, err := releasesFromGitHubResponse(resp.StatusCode, data)
+	if err != nil {
+		err := fmt.Errorf("failed to get releases from github response: %w", err)
+		reporting.Report(ctx, err, map[string]string{
+			"repository": g.repository,
+			"status":     strconv.Itoa(resp.StatusCode),
+		})
+		return nil, err
+	}
+
+	return releases, nil
+}
+
+type gitHubRelease struct {
+	TagName     string    `json:"tag_name"`
+	Name        string    `json:"name"`
+	HTMLURL     string    `json:"html_url"`
+	Draft       bool      `json:"draft"`
+	Prerelease  bool      `json:"prerelease"`
+	PublishedAt time.Time `json:"published_at"`
+}
+
+func releasesFromGitHubResponse(statusCode int, data []byte) ([]domain.PrismRelease, error) {
+	switch statusCode {
+	case http.StatusForbidden, // Unauthenticated requests are rate limited with a 403
+		http.StatusTooManyRequests,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return nil, fmt.Errorf("%w: github API returned status code %d", domain.ErrTemporarilyUnavailable, statusCode)
+	}
+
+	if statusCode != http.StatusOK {
+		return nil, fmt.Errorf("github API returned status code %d", statusCode)
+	}
+
+	var response []gitHubRelease
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse github response: %w", err)
+	}
+
+	releases := make([]domain.PrismRelease, 0, len(response))
+	for _, release := range response {
+		if release.Draft || release.TagName == "" {
+			continue
+		}
+		releases = append(releases, domain.PrismRelease{
+			Version:     release.TagName,
+			Name:        release.Name,
+			URL:         release.HTMLURL,
+			Prerelease:  release.Prerelease,
+			PublishedAt: release.PublishedAt,
+		})
+	}
+
+	return releases, nil
+}
@@ -0,0 +1,156 @@
+package releaseprovider
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Amund211/flashlight/internal/domain"
+)
+
+// Trimmed from https://api.github.com/repos/Amund211/prism/releases
+const gitHubReleasesResponse = `[
+  {
+    "html_url": "https://github.com/Amund211/prism/releases/tag/v1.13.0-rc1",
+    "tag_name": "v1.13.0-rc1",
+    "name": "Prism v1.13.0 RC 1",
+    "draft": false,
+    "prerelease": true,
+    "published_at": "2026-09-20T18:02:11Z"
+  },
+  {
+    "html_url": "https://github.com/Amund211/prism/releases/tag/untagged-5b0c2d",
+    "tag_name": "v1.13.0",
+    "name": "Prism v1.13.0",
+    "draft": true,
+    "prerelease": false,
+    "published_at": null
+  },
+  {
+    "html_url": "https://github.com/Amund211/prism/releases/tag/v1.12.0",
+    "tag_name": "v1.12.0",
+    "name": "Prism v1.12.0",
+    "draft": false,
+    "prerelease": false,
+    "published_at": "2026-06-01T12:30:00Z"
+  }
+]`
+
+func TestReleasesFromGitHubResponse(t *testing.T) {
+	t.Parallel()
+
+	t.Run("drafts are skipped", func(t *testing.T) {
+		t.Parallel()
+
+		releases, err := releasesFromGitHubResponse(200, []byte(gitHubReleasesResponse))
+		require.NoError(t, err)
+		require.Equal(t, []domain.PrismRelease{
+			{
+				Version:     "v1.13.0-rc1",
+				Name:        "Prism v1.13.0 RC 1",
+				URL:         "https://github.com/Amund211/prism/releases/tag/v1.13.0-rc1",
+				Prerelease:  true,
+				PublishedAt: time.Date(2026, time.September, 20, 18, 2, 11, 0, time.UTC),
+			},
+			{
+				Version:     "v1.12.0",
+				Name:        "Prism v1.12.0",
+				URL:         "https://github.com/Amund211/prism/releases/tag/v1.12.0",
+				PublishedAt: time.Date(2026, time.June, 1, 12, 30, 0, 0, time.UTC),
+			},
+		}, releases)
+	})
+
+	t.Run("empty feed", func(t *testing.T) {
+		t.Parallel()
+
+		releases, err := releasesFromGitHubResponse(200, []byte(`[]`))
+		require.NoError(t, err)
+		require.Empty(t, releases)
+	})
+
+	for _, statusCode := range []int{403, 429, 502, 503, 504} {
+		t.Run("temporarily unavailable "+http.StatusText(statusCode), func(t *testing.T) {
+			t.Parallel()
+
+			_, err := releasesFromGitHubResponse(statusCode, []byte(`{"message": "API rate limit exceeded"}`))
+			require.ErrorIs(t, err, domain.ErrTemporarilyUnavailable)
+		})
+	}
+
+	t.Run("not found", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := releasesFromGitHubResponse(404, []byte(`{"message": "Not Found"}`))
+		require.Error(t, err)
+		require.NotErrorIs(t, err, domain.ErrTemporarilyUnavailable)
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := releasesFromGitHubResponse(200, []byte(`{"message": "not a list"}`))
+		require.Error(t, err)
+	})
+}
+
+type mockedClient struct {
+	responseData []byte
+	statusCode   int
+	err          error
+
+	lastRequest *http.Request
+}
+
+func (m *mockedClient) Do(req *http.Request) (*http.Response, error) {
+	m.lastRequest = req
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	return &http.Response{
+		StatusCode: m.statusCode,
+		Body:       io.NopCloser(bytes.NewReader(m.responseData)),
+		Header:     make(http.Header),
+	}, nil
+}
+
+func TestGitHub(t *testing.T) {
+	t.Parallel()
+
+	t.Run("invalid repository", func(t *testing.T) {
+		t.Parallel()
+
+		for _, repository := range []string{"", "prism", "/prism", "Amund211/", "Amund211/prism/releases"} {
+			_, err := NewGitHub(&mockedClient{}, repository)
+			require.Error(t, err, repository)
+		}
+	})
+
+	t.Run("list releases", func(t *testing.T) {
+		t.Parallel()
+		ctx := t.Context()
+
+		client := &mockedClient{responseData: []byte(gitHubReleasesResponse), statusCode: 200}
+		provider, err := NewGitHub(client, "Amund211/prism")
+		require.NoError(t, err)
+
+		releases, err := provider.ListPrismReleases(ctx)
+		require.NoError(t, err)
+		require.Len(t, releases, 2)
+
+		require.Equal(t, "https://api.github.com/repos/Amund211/prism/releases?per_page=30", client.lastRequest.URL.String())
+		require.Equal(t, "application/vnd.github+json", client.lastRequest.Header.Get("Accept"))
+		require.NotEmpty(t, client.lastRequest.Header.Get("User-Agent"))
+
+		client.err = assert.AnError
+		_, err = provider.ListPrismReleases(ctx)
+		require.ErrorIs(t, err, assert.AnError)
+		require.ErrorIs(t, err, domain.ErrTemporarilyUnavailable)
+	})
+}
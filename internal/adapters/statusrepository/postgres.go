@@ -0,0 +1,177 @@
+package statusrepository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/Amund211/flashlight/internal/domain"
+	"github.com/Amund211/flashlight/internal/logging"
+	"github.com/Amund211/flashlight/internal/reporting"
+	"github.com/Amund211/flashlight/internal/strutils"
+)
+
+type Postgres struct {
+	db     *sqlx.DB
+	schema string
+	tracer trace.Tracer
+}
+
+func NewPostgres(db *sqlx.DB, schema string) *Postgres {
+	return &Postgres{
+		db:     db,
+		schema: schema,
+		tracer: otel.Tracer("flashlight/statusrepository/postgres"),
+	}
+}
+
+type dbOnlineTransition struct {
+	ObservedAt time.Time `db:"observed_at"`
+	Online     bool      `db:"online"`
+}
+
+// StoreStatus stores the player's online status when it differs from the most
+// recently stored one.
+//
+// Players that hide their status always appear offline, so nothing is stored
+// until the player has been seen online.
+func (p *Postgres) StoreStatus(ctx context.Context, status *domain.PlayerStatus) error {
+	ctx, span := p.tracer.Start(ctx, "Postgres.StoreStatus")
+	defer span.End()
+
+	if status == nil {
+		err := fmt.Errorf("status is nil")
+		reporting.Report(ctx, err)
+		return err
+	}
+
+	if !strutils.UUIDIsNormalized(status.UUID) {
+		err := fmt.Errorf("uuid is not normalized")
+		reporting.Report(ctx, err, map[string]string{
+			"uuid": status.UUID,
+		})
+		return err
+	}
+
+	dbID, err := uuid.NewV7()
+	if err != nil {
+		err := fmt.Errorf("failed to generate db id: %w", err)
+		reporting.Report(ctx, err)
+		return err
+	}
+
+	txx, err := p.db.BeginTxx(ctx, nil)
+	if err != nil {
+		err := fmt.Errorf("failed to start transaction: %w", err)
+		reporting.Report(ctx, err)
+		return err
+	}
+	defer txx.Rollback()
+
+	_, err = txx.ExecContext(ctx, fmt.Sprintf("SET search_path TO %s", pq.QuoteIdentifier(p.schema)))
+	if err != nil {
+		err := fmt.Errorf("failed to set search path: %w", err)
+		reporting.Report(ctx, err, map[string]string{
+			"schema": p.schema,
+		})
+		return err
+	}
+
+	var lastOnline bool
+	err = txx.QueryRowxContext(
+		ctx,
+		`SELECT online
+		FROM online_transitions
+		WHERE player_uuid = $1
+		ORDER BY observed_at DESC LIMIT 1`,
+		status.UUID,
+	).Scan(&lastOnline)
+	if errors.Is(err, sql.ErrNoRows) {
+		if !status.Online {
+			// Never seen online -> the status may be hidden
+			return nil
+		}
+	} else if err != nil {
+		err := fmt.Errorf("failed to query last online status: %w", err)
+		reporting.Report(ctx, err, map[string]string{
+			"uuid": status.UUID,
+		})
+		return err
+	} else if lastOnline == status.Online {
+		// Unchanged -> don't store
+		return nil
+	}
+
+	_, err = txx.ExecContext(
+		ctx,
+		`INSERT INTO online_transitions
+		(id, player_uuid, observed_at, online)
+		VALUES ($1, $2, $3, $4)`,
+		dbID.String(),
+		status.UUID,
+		status.QueriedAt,
+		status.Online,
+	)
+	if err != nil {
+		err := fmt.Errorf("failed to insert online transition: %w", err)
+		reporting.Report(ctx, err, map[string]string{
+			"uuid": status.UUID,
+		})
+		return err
+	}
+
+	err = txx.Commit()
+	if err != nil {
+		err := fmt.Errorf("failed to commit transaction: %w", err)
+		reporting.Report(ctx, err)
+		return err
+	}
+
+	logging.FromContext(ctx).InfoContext(ctx, "Stored online transition", "uuid", status.UUID, "online", status.Online)
+
+	return nil
+}
+
+// GetOnlineTransitions returns the stored transitions for the player in
+// [start, end], oldest first
+func (p *Postgres) GetOnlineTransitions(ctx context.Context, playerUUID string, start, end time.Time) ([]domain.OnlineTransition, error) {
+	ctx, span := p.tracer.Start(ctx, "Postgres.GetOnlineTransitions")
+	defer span.End()
+
+	var rows []dbOnlineTransition
+	err := p.db.SelectContext(
+		ctx,
+		&rows,
+		fmt.Sprintf(`SELECT observed_at, online
+		FROM %s.online_transitions
+		WHERE player_uuid = $1 AND observed_at BETWEEN $2 AND $3
+		ORDER BY observed_at ASC`,
+			pq.QuoteIdentifier(p.schema)),
+		playerUUID, start, end,
+	)
+	if err != nil {
+		err := fmt.Errorf("failed to select online transitions: %w", err)
+		reporting.Report(ctx, err, map[string]string{
+			"uuid": playerUUID,
+		})
+		return nil, err
+	}
+
+	transitions := make([]domain.OnlineTransition, 0, len(rows))
+	for _, row := range rows {
+		transitions = append(transitions, domain.OnlineTransition{
+			At:     row.ObservedAt.UTC(),
+			Online: row.Online,
+		})
+	}
+
+	return transitions, nil
+}
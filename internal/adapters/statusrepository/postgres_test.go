@@ -0,0 +1,113 @@
+package statusrepository
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Amund211/flashlight/internal/adapters/database"
+	"github.com/Amund211/flashlight/internal/domain"
+	"github.com/Amund211/flashlight/internal/domaintest"
+)
+
+func newPostgres(t *testing.T, db *sqlx.DB, schemaSuffix string) *Postgres {
+	require.NotEmpty(t, schemaSuffix, "schemaSuffix must not be empty")
+	schema := fmt.Sprintf("status_repo_test_%s", schemaSuffix)
+
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+	db.MustExec(fmt.Sprintf("DROP SCHEMA IF EXISTS %s CASCADE", pq.QuoteIdentifier(schema)))
+
+	migrator := database.NewDatabaseMigrator(db, logger)
+
+	err := migrator.Migrate(t.Context(), schema)
+	require.NoError(t, err)
+
+	return NewPostgres(db, schema)
+}
+
+func TestPostgresOnlineTransitions(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping db tests in short mode.")
+	}
+	t.Parallel()
+
+	db, err := database.NewPostgresDatabase(database.LocalConnectionString)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	p := newPostgres(t, db, "online_transitions")
+
+	now := time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC)
+
+	makeStatus := func(uuid string, queriedAt time.Time, online bool) *domain.PlayerStatus {
+		return &domain.PlayerStatus{
+			UUID:      uuid,
+			QueriedAt: queriedAt,
+			Online:    online,
+		}
+	}
+
+	t.Run("stores changes only", func(t *testing.T) {
+		t.Parallel()
+		ctx := t.Context()
+		uuid := domaintest.NewUUID(t)
+
+		// Never seen online -> not stored
+		require.NoError(t, p.StoreStatus(ctx, makeStatus(uuid, now, false)))
+
+		require.NoError(t, p.StoreStatus(ctx, makeStatus(uuid, now.Add(1*time.Minute), true)))
+		// Unchanged -> not stored
+		require.NoError(t, p.StoreStatus(ctx, makeStatus(uuid, now.Add(2*time.Minute), true)))
+		require.NoError(t, p.StoreStatus(ctx, makeStatus(uuid, now.Add(3*time.Minute), false)))
+		require.NoError(t, p.StoreStatus(ctx, makeStatus(uuid, now.Add(4*time.Minute), false)))
+		require.NoError(t, p.StoreStatus(ctx, makeStatus(uuid, now.Add(5*time.Minute), true)))
+
+		transitions, err := p.GetOnlineTransitions(ctx, uuid, now.Add(-time.Hour), now.Add(time.Hour))
+		require.NoError(t, err)
+		require.Equal(t, []domain.OnlineTransition{
+			{At: now.Add(1 * time.Minute), Online: true},
+			{At: now.Add(3 * time.Minute), Online: false},
+			{At: now.Add(5 * time.Minute), Online: true},
+		}, transitions)
+
+		transitions, err = p.GetOnlineTransitions(ctx, uuid, now.Add(2*time.Minute), now.Add(4*time.Minute))
+		require.NoError(t, err)
+		require.Equal(t, []domain.OnlineTransition{
+			{At: now.Add(3 * time.Minute), Online: false},
+		}, transitions)
+	})
+
+	t.Run("players are separate", func(t *testing.T) {
+		t.Parallel()
+		ctx := t.Context()
+		a := domaintest.NewUUID(t)
+		b := domaintest.NewUUID(t)
+
+		require.NoError(t, p.StoreStatus(ctx, makeStatus(a, now, true)))
+		require.NoError(t, p.StoreStatus(ctx, makeStatus(b, now, true)))
+		require.NoError(t, p.StoreStatus(ctx, makeStatus(b, now.Add(time.Minute), false)))
+
+		transitions, err := p.GetOnlineTransitions(ctx, a, now.Add(-time.Hour), now.Add(time.Hour))
+		require.NoError(t, err)
+		require.Equal(t, []domain.OnlineTransition{{At: now, Online: true}}, transitions)
+
+		transitions, err = p.GetOnlineTransitions(ctx, domaintest.NewUUID(t), now.Add(-time.Hour), now.Add(time.Hour))
+		require.NoError(t, err)
+		require.Empty(t, transitions)
+	})
+
+	t.Run("rejects invalid statuses", func(t *testing.T) {
+		t.Parallel()
+		ctx := t.Context()
+
+		require.Error(t, p.StoreStatus(ctx, nil))
+		require.Error(t, p.StoreStatus(ctx, makeStatus("not-a-uuid", now, true)))
+	})
+}
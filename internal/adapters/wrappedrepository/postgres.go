@@ -0,0 +1,134 @@
+package wrappedrepository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/Amund211/flashlight/internal/domain"
+	"github.com/Amund211/flashlight/internal/reporting"
+	"github.com/Amund211/flashlight/internal/strutils"
+)
+
+type Postgres struct {
+	db     *sqlx.DB
+	schema string
+	tracer trace.Tracer
+}
+
+func NewPostgres(db *sqlx.DB, schema string) *Postgres {
+	return &Postgres{
+		db:     db,
+		schema: schema,
+		tracer: otel.Tracer("flashlight/wrappedrepository/postgres"),
+	}
+}
+
+type dbWrapped struct {
+	PlayerUUID string    `db:"player_uuid"`
+	Year       int       `db:"year"`
+	Version    int       `db:"version"`
+	Complete   bool      `db:"complete"`
+	Data       []byte    `db:"data"`
+	ComputedAt time.Time `db:"computed_at"`
+}
+
+// GetWrapped returns domain.ErrWrappedNotFound when nothing is stored for the
+// key.
+func (p *Postgres) GetWrapped(ctx context.Context, playerUUID string, year int, version int) (domain.StoredWrapped, error) {
+	ctx, span := p.tracer.Start(ctx, "Postgres.GetWrapped")
+	defer span.End()
+
+	if !strutils.UUIDIsNormalized(playerUUID) {
+		err := fmt.Errorf("uuid is not normalized")
+		reporting.Report(ctx, err, map[string]string{
+			"uuid": playerUUID,
+		})
+		return domain.StoredWrapped{}, err
+	}
+
+	var row dbWrapped
+	err := p.db.QueryRowxContext(
+		ctx,
+		fmt.Sprintf(`SELECT player_uuid, year, version, complete, data, computed_at
+		FROM %s.wrapped
+		WHERE player_uuid = $1 AND year = $2 AND version = $3`,
+			pq.QuoteIdentifier(p.schema)),
+		playerUUID, year, version,
+	).StructScan(&row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.StoredWrapped{}, domain.ErrWrappedNotFound
+	}
+	if err != nil {
+		err := fmt.Errorf("failed to get wrapped: %w", err)
+		reporting.Report(ctx, err, map[string]string{
+			"uuid":    playerUUID,
+			"year":    strconv.Itoa(year),
+			"version": strconv.Itoa(version),
+		})
+		return domain.StoredWrapped{}, err
+	}
+
+	return domain.StoredWrapped{
+		UUID:       row.PlayerUUID,
+		Year:       row.Year,
+		Version:    row.Version,
+		Complete:   row.Complete,
+		Data:       row.Data,
+		ComputedAt: row.ComputedAt.UTC(),
+	}, nil
+}
+
+// StoreWrapped inserts the wrapped, replacing anything stored for its key.
+func (p *Postgres) StoreWrapped(ctx context.Context, wrapped domain.StoredWrapped) error {
+	ctx, span := p.tracer.Start(ctx, "Postgres.StoreWrapped")
+	defer span.End()
+
+	if !strutils.UUIDIsNormalized(wrapped.UUID) {
+		err := fmt.Errorf("uuid is not normalized")
+		reporting.Report(ctx, err, map[string]string{
+			"uuid": wrapped.UUID,
+		})
+		return err
+	}
+
+	_, err := p.db.NamedExecContext(
+		ctx,
+		fmt.Sprintf(`INSERT INTO %s.wrapped
+		(player_uuid, year, version, complete, data, computed_at)
+		VALUES (:player_uuid, :year, :version, :complete, :data, :computed_at)
+		ON CONFLICT (player_uuid, year, version)
+		DO UPDATE SET
+			complete = EXCLUDED.complete,
+			data = EXCLUDED.data,
+			computed_at = EXCLUDED.computed_at`,
+			pq.QuoteIdentifier(p.schema)),
+		dbWrapped{
+			PlayerUUID: wrapped.UUID,
+			Year:       wrapped.Year,
+			Version:    wrapped.Version,
+			Complete:   wrapped.Complete,
+			Data:       wrapped.Data,
+			ComputedAt: wrapped.ComputedAt,
+		},
+	)
+	if err != nil {
+		err := fmt.Errorf("failed to store wrapped: %w", err)
+		reporting.Report(ctx, err, map[string]string{
+			"uuid":    wrapped.UUID,
+			"year":    strconv.Itoa(wrapped.Year),
+			"version": strconv.Itoa(wrapped.Version),
+		})
+		return err
+	}
+
+	return nil
+}
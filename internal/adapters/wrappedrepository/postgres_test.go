@@ -0,0 +1,109 @@
+package wrappedrepository
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Amund211/flashlight/internal/adapters/database"
+	"github.com/Amund211/flashlight/internal/domain"
+	"github.com/Amund211/flashlight/internal/domaintest"
+)
+
+func newPostgres(t *testing.T, db *sqlx.DB, schemaSuffix string) *Postgres {
+	require.NotEmpty(t, schemaSuffix, "schemaSuffix must not be empty")
+	schema := fmt.Sprintf("wrapped_repo_test_%s", schemaSuffix)
+
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+	db.MustExec(fmt.Sprintf("DROP SCHEMA IF EXISTS %s CASCADE", pq.QuoteIdentifier(schema)))
+
+	migrator := database.NewDatabaseMigrator(db, logger)
+
+	err := migrator.Migrate(t.Context(), schema)
+	require.NoError(t, err)
+
+	return NewPostgres(db, schema)
+}
+
+func TestPostgresWrapped(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping db tests in short mode.")
+	}
+	t.Parallel()
+
+	db, err := database.NewPostgresDatabase(database.LocalConnectionString)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	p := newPostgres(t, db, "wrapped")
+
+	now := time.Date(2025, time.January, 3, 12, 0, 0, 0, time.UTC)
+
+	t.Run("get missing", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := p.GetWrapped(t.Context(), domaintest.NewUUID(t), 2024, 1)
+		require.ErrorIs(t, err, domain.ErrWrappedNotFound)
+	})
+
+	t.Run("store, get and replace", func(t *testing.T) {
+		t.Parallel()
+		ctx := t.Context()
+
+		playerUUID := domaintest.NewUUID(t)
+		stored := domain.StoredWrapped{
+			UUID:       playerUUID,
+			Year:       2024,
+			Version:    1,
+			Complete:   false,
+			Data:       []byte(`{"resumeAt":"2024-06-01T00:00:00Z"}`),
+			ComputedAt: now,
+		}
+		require.NoError(t, p.StoreWrapped(ctx, stored))
+
+		got, err := p.GetWrapped(ctx, playerUUID, 2024, 1)
+		require.NoError(t, err)
+		require.JSONEq(t, string(stored.Data), string(got.Data))
+		got.Data, stored.Data = nil, nil
+		require.Equal(t, stored, got)
+
+		// Different keys are separate
+		_, err = p.GetWrapped(ctx, playerUUID, 2024, 2)
+		require.ErrorIs(t, err, domain.ErrWrappedNotFound)
+		_, err = p.GetWrapped(ctx, playerUUID, 2023, 1)
+		require.ErrorIs(t, err, domain.ErrWrappedNotFound)
+
+		replacement := domain.StoredWrapped{
+			UUID:       playerUUID,
+			Year:       2024,
+			Version:    1,
+			Complete:   true,
+			Data:       []byte(`{"resumeAt":"2025-01-01T00:00:00Z"}`),
+			ComputedAt: now.Add(time.Hour),
+		}
+		require.NoError(t, p.StoreWrapped(ctx, replacement))
+
+		got, err = p.GetWrapped(ctx, playerUUID, 2024, 1)
+		require.NoError(t, err)
+		require.True(t, got.Complete)
+		require.JSONEq(t, string(replacement.Data), string(got.Data))
+		require.Equal(t, replacement.ComputedAt, got.ComputedAt)
+	})
+
+	t.Run("errors on un-normalized uuid", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := p.GetWrapped(t.Context(), "not-a-uuid", 2024, 1)
+		require.Error(t, err)
+
+		err = p.StoreWrapped(t.Context(), domain.StoredWrapped{UUID: "not-a-uuid", Data: []byte(`{}`)})
+		require.Error(t, err)
+	})
+}
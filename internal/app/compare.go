@@ -0,0 +1,188 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Amund211/flashlight/internal/domain"
+	"github.com/Amund211/flashlight/internal/reporting"
+	"github.com/Amund211/flashlight/internal/strutils"
+)
+
+// compareBuffer is how far outside the requested range we look for stats, so
+// sessions crossing the range borders are completed
+const compareBuffer = 24 * time.Hour
+
+const (
+	MinComparePlayers = 2
+	MaxComparePlayers = 8
+)
+
+// PlayerComparison is how one player progressed in the compared range
+type PlayerComparison struct {
+	UUID string
+	// First and Last are the first and last stats in the range. Both are nil
+	// when the player has no stats in the range.
+	First *domain.PlayerPIT
+	Last  *domain.PlayerPIT
+	// Sessions are the player's sessions overlapping the range
+	Sessions []domain.Session
+}
+
+// PlayedTogether is how much two players' sessions overlapped in the range,
+// suggesting that they played together
+type PlayedTogether struct {
+	UUIDs [2]string
+	// Duration is the total time both players were in a session, counting
+	// only the part of the sessions inside the range
+	Duration time.Duration
+	// OverlappingSessions is the number of pairs of sessions that overlapped
+	OverlappingSessions int
+}
+
+// Comparison holds a PlayerComparison for each compared player, in the
+// requested order, and a PlayedTogether for each pair of them
+type Comparison struct {
+	Players        []PlayerComparison
+	PlayedTogether []PlayedTogether
+}
+
+type Compare = func(
+	ctx context.Context,
+	uuids []string,
+	start, end time.Time,
+	options SessionOptions,
+) (Comparison, error)
+
+// BuildCompare constructs a Compare that computes each player's stats and
+// sessions in [start, end] like the history and sessions endpoints do.
+func BuildCompare(
+	getPlayerPITs GetPlayerPITs,
+	computeSessions ComputeSessions,
+) Compare {
+	return func(ctx context.Context, uuids []string, start, end time.Time, options SessionOptions) (Comparison, error) {
+		if len(uuids) < MinComparePlayers || len(uuids) > MaxComparePlayers {
+			err := fmt.Errorf("invalid number of players in app.Compare")
+			reporting.Report(ctx, err, map[string]string{
+				"players": strconv.Itoa(len(uuids)),
+			})
+			return Comparison{}, err
+		}
+
+		for _, uuid := range uuids {
+			if !strutils.UUIDIsNormalized(uuid) {
+				err := fmt.Errorf("UUID is not normalized")
+				reporting.Report(ctx, err, map[string]string{
+					"uuid": uuid,
+				})
+				return Comparison{}, err
+			}
+		}
+
+		if start.After(end) {
+			err := fmt.Errorf("start time is after end time")
+			reporting.Report(ctx, err)
+			return Comparison{}, err
+		}
+
+		players := make([]PlayerComparison, len(uuids))
+		errs := make([]error, len(uuids))
+		var wg sync.WaitGroup
+		for i, uuid := range uuids {
+			wg.Go(func() {
+				stats, err := getPlayerPITs(ctx, uuid, start.Add(-compareBuffer), end.Add(compareBuffer))
+				if err != nil {
+					// NOTE: GetPlayerPITs implementations handle their own error reporting
+					errs[i] = fmt.Errorf("failed to get player pits: %w", err)
+					return
+				}
+				players[i] = comparePlayer(ctx, computeSessions, uuid, stats, start, end, options)
+			})
+		}
+		wg.Wait()
+
+		for _, err := range errs {
+			if err != nil {
+				return Comparison{}, err
+			}
+		}
+
+		playedTogether := make([]PlayedTogether, 0, len(players)*(len(players)-1)/2)
+		for i := range players {
+			for j := i + 1; j < len(players); j++ {
+				playedTogether = append(playedTogether, computePlayedTogether(&players[i], &players[j], start, end))
+			}
+		}
+
+		return Comparison{
+			Players:        players,
+			PlayedTogether: playedTogether,
+		}, nil
+	}
+}
+
+func comparePlayer(ctx context.Context, computeSessions ComputeSessions, uuid string, stats []domain.PlayerPIT, start, end time.Time, options SessionOptions) PlayerComparison {
+	// NOTE: Sorts stats
+	sessions := computeSessions(ctx, stats, start, end, options)
+
+	comparison := PlayerComparison{
+		UUID:     uuid,
+		Sessions: sessions,
+	}
+	for i := range stats {
+		stat := &stats[i]
+		if stat.QueriedAt.Before(start) || stat.QueriedAt.After(end) {
+			continue
+		}
+		if comparison.First == nil {
+			comparison.First = stat
+		}
+		comparison.Last = stat
+	}
+
+	return comparison
+}
+
+// computePlayedTogether sums the overlap of the two players' sessions, clamped
+// to [start, end]
+func computePlayedTogether(a, b *PlayerComparison, start, end time.Time) PlayedTogether {
+	clamp := func(session *domain.Session) (time.Time, time.Time) {
+		sessionStart := session.Start.QueriedAt
+		if sessionStart.Before(start) {
+			sessionStart = start
+		}
+		sessionEnd := session.End.QueriedAt
+		if sessionEnd.After(end) {
+			sessionEnd = end
+		}
+		return sessionStart, sessionEnd
+	}
+
+	together := PlayedTogether{UUIDs: [2]string{a.UUID, b.UUID}}
+	for i := range a.Sessions {
+		aStart, aEnd := clamp(&a.Sessions[i])
+		for j := range b.Sessions {
+			bStart, bEnd := clamp(&b.Sessions[j])
+
+			overlapStart := aStart
+			if bStart.After(overlapStart) {
+				overlapStart = bStart
+			}
+			overlapEnd := aEnd
+			if bEnd.Before(overlapEnd) {
+				overlapEnd = bEnd
+			}
+
+			if !overlapEnd.After(overlapStart) {
+				continue
+			}
+			together.Duration += overlapEnd.Sub(overlapStart)
+			together.OverlappingSessions++
+		}
+	}
+
+	return together
+}
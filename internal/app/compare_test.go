@@ -0,0 +1,113 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Amund211/flashlight/internal/app"
+	"github.com/Amund211/flashlight/internal/domain"
+	"github.com/Amund211/flashlight/internal/domaintest"
+)
+
+func TestBuildCompare(t *testing.T) {
+	t.Parallel()
+
+	uuidA := "01234567-89ab-cdef-0123-456789abcdef"
+	uuidB := "11234567-89ab-cdef-0123-456789abcdef"
+	uuidC := "21234567-89ab-cdef-0123-456789abcdef"
+	start := time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC)
+	end := start.Add(24 * time.Hour)
+
+	computeSessions := app.BuildComputeSessions(func() time.Time { return end.Add(365 * 24 * time.Hour) })
+
+	// A session of three fours games starting at sessionStart
+	session := func(uuid string, sessionStart time.Time, gamesPlayed int) []domain.PlayerPIT {
+		b := domaintest.NewPlayerBuilder(uuid).FromDB().Fours()
+		return []domain.PlayerPIT{
+			b.WithGamesPlayed(gamesPlayed).WithWins(gamesPlayed).Build(sessionStart),
+			b.WithGamesPlayed(gamesPlayed + 1).WithWins(gamesPlayed + 1).Build(sessionStart.Add(20 * time.Minute)),
+			b.WithGamesPlayed(gamesPlayed + 2).WithLosses(1).Build(sessionStart.Add(40 * time.Minute)),
+			b.WithGamesPlayed(gamesPlayed + 3).WithWins(gamesPlayed + 2).Build(sessionStart.Add(60 * time.Minute)),
+		}
+	}
+
+	statsByUUID := map[string][]domain.PlayerPIT{
+		// Starts before the range
+		uuidA: append(session(uuidA, start.Add(-30*time.Minute), 0), session(uuidA, start.Add(10*time.Hour), 3)...),
+		uuidB: session(uuidB, start.Add(10*time.Hour+30*time.Minute), 0),
+		uuidC: {},
+	}
+	getPlayerPITs := func(ctx context.Context, uuid string, _, _ time.Time) ([]domain.PlayerPIT, error) {
+		stats, ok := statsByUUID[uuid]
+		require.True(t, ok)
+		// Copy, since ComputeSessions sorts the stats
+		return append([]domain.PlayerPIT{}, stats...), nil
+	}
+
+	t.Run("compares players", func(t *testing.T) {
+		t.Parallel()
+
+		compare := app.BuildCompare(getPlayerPITs, computeSessions)
+		comparison, err := compare(t.Context(), []string{uuidA, uuidB, uuidC}, start, end, app.SessionOptions{})
+		require.NoError(t, err)
+
+		require.Len(t, comparison.Players, 3)
+
+		a := comparison.Players[0]
+		require.Equal(t, uuidA, a.UUID)
+		require.Len(t, a.Sessions, 2)
+		require.NotNil(t, a.First)
+		require.NotNil(t, a.Last)
+		// The first stats in the range, not in the padding before it
+		require.Equal(t, start.Add(10*time.Minute), a.First.QueriedAt)
+		require.Equal(t, start.Add(11*time.Hour), a.Last.QueriedAt)
+
+		b := comparison.Players[1]
+		require.Equal(t, uuidB, b.UUID)
+		require.Len(t, b.Sessions, 1)
+
+		c := comparison.Players[2]
+		require.Equal(t, uuidC, c.UUID)
+		require.Empty(t, c.Sessions)
+		require.Nil(t, c.First)
+		require.Nil(t, c.Last)
+
+		require.Equal(t, []app.PlayedTogether{
+			{UUIDs: [2]string{uuidA, uuidB}, Duration: 30 * time.Minute, OverlappingSessions: 1},
+			{UUIDs: [2]string{uuidA, uuidC}},
+			{UUIDs: [2]string{uuidB, uuidC}},
+		}, comparison.PlayedTogether)
+	})
+
+	t.Run("invalid arguments", func(t *testing.T) {
+		t.Parallel()
+
+		compare := app.BuildCompare(getPlayerPITs, computeSessions)
+
+		_, err := compare(t.Context(), []string{uuidA}, start, end, app.SessionOptions{})
+		require.Error(t, err)
+
+		_, err = compare(t.Context(), []string{uuidA, "not-a-uuid"}, start, end, app.SessionOptions{})
+		require.Error(t, err)
+
+		_, err = compare(t.Context(), []string{uuidA, uuidB}, end, start, app.SessionOptions{})
+		require.Error(t, err)
+	})
+
+	t.Run("failing to get stats", func(t *testing.T) {
+		t.Parallel()
+
+		compare := app.BuildCompare(func(ctx context.Context, uuid string, _, _ time.Time) ([]domain.PlayerPIT, error) {
+			if uuid == uuidB {
+				return nil, domain.ErrTemporarilyUnavailable
+			}
+			return []domain.PlayerPIT{}, nil
+		}, computeSessions)
+
+		_, err := compare(t.Context(), []string{uuidA, uuidB}, start, end, app.SessionOptions{})
+		require.ErrorIs(t, err, domain.ErrTemporarilyUnavailable)
+	})
+}
@@ -0,0 +1,100 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Amund211/flashlight/internal/reporting"
+	"github.com/Amund211/flashlight/internal/strutils"
+)
+
+// gamesBuffer is how far outside the requested range we look for stats, so
+// sessions crossing the range borders are segmented the same way on every
+// page.
+const gamesBuffer = 24 * time.Hour
+
+// MaxGamesPageSize is the most games GetGames returns at once
+const MaxGamesPageSize = 100
+
+// GamesPage is a page of the game segments a player played in a range, in
+// chronological order.
+type GamesPage struct {
+	Games []GameSegment
+	// HasMore is whether more segments follow the last one in Games. The
+	// next page starts at the End of the last segment.
+	HasMore bool
+}
+
+type GetGames = func(
+	ctx context.Context,
+	uuid string,
+	start, end time.Time,
+	limit int,
+	options SessionOptions,
+) (GamesPage, error)
+
+// BuildGetGames constructs a GetGames that segments every session in the
+// range into games, like GetSessionAt does for a single session. Segments
+// are included when they start within [start, end).
+func BuildGetGames(
+	getPlayerPITs GetPlayerPITs,
+	computeSessions ComputeSessions,
+) GetGames {
+	return func(ctx context.Context, uuid string, start, end time.Time, limit int, options SessionOptions) (GamesPage, error) {
+		if !strutils.UUIDIsNormalized(uuid) {
+			err := fmt.Errorf("UUID is not normalized")
+			reporting.Report(ctx, err)
+			return GamesPage{}, err
+		}
+
+		if start.After(end) {
+			err := fmt.Errorf("start time is after end time")
+			reporting.Report(ctx, err)
+			return GamesPage{}, err
+		}
+
+		if limit < 1 || limit > MaxGamesPageSize {
+			err := fmt.Errorf("invalid limit in app.GetGames")
+			reporting.Report(ctx, err, map[string]string{
+				"limit": strconv.Itoa(limit),
+			})
+			return GamesPage{}, err
+		}
+
+		stats, err := getPlayerPITs(ctx, uuid, start.Add(-gamesBuffer), end.Add(gamesBuffer))
+		if err != nil {
+			// NOTE: GetPlayerPITs implementations handle their own error reporting
+			return GamesPage{}, fmt.Errorf("failed to get player pits: %w", err)
+		}
+
+		options = options.withDefaults()
+		sessions := computeSessions(ctx, stats, start, end, options)
+
+		// Collect one segment past the limit to tell if there are more
+		games := make([]GameSegment, 0, min(limit+1, len(stats)))
+		for i := range sessions {
+			segments, err := ComputeGameSegments(ctx, stats, &sessions[i])
+			if err != nil {
+				// NOTE: ComputeGameSegments reports its own errors
+				return GamesPage{}, fmt.Errorf("failed to compute game segments: %w", err)
+			}
+
+			for _, segment := range segments {
+				if segment.Start.QueriedAt.Before(start) {
+					continue
+				}
+				if !segment.Start.QueriedAt.Before(end) {
+					break
+				}
+				games = append(games, segment)
+				if len(games) > limit {
+					return GamesPage{Games: games[:limit], HasMore: true}, nil
+				}
+			}
+		}
+
+		return GamesPage{Games: games, HasMore: false}, nil
+	}
+}
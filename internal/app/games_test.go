@@ -0,0 +1,151 @@
+package app_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Amund211/flashlight/internal/app"
+	"github.com/Amund211/flashlight/internal/domain"
+	"github.com/Amund211/flashlight/internal/domaintest"
+)
+
+func TestBuildGetGames(t *testing.T) {
+	t.Parallel()
+
+	uuid := "01234567-89ab-cdef-0123-456789abcdef"
+	start := time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC)
+	end := start.Add(24 * time.Hour)
+
+	fixedStats := func(stats []domain.PlayerPIT) app.GetPlayerPITs {
+		return func(ctx context.Context, _ string, _, _ time.Time) ([]domain.PlayerPIT, error) {
+			return stats, nil
+		}
+	}
+	computeSessions := app.BuildComputeSessions(func() time.Time { return end.Add(365 * 24 * time.Hour) })
+
+	// Two sessions: three fours games, then a jump of two solo games and a
+	// solo win
+	b := domaintest.NewPlayerBuilder(uuid).WithExperience(1000).FromDB()
+	stats := []domain.PlayerPIT{
+		b.Fours().WithGamesPlayed(10).Build(start.Add(1 * time.Hour)),
+		b.Fours().WithGamesPlayed(11).WithWins(1).Build(start.Add(1*time.Hour + 15*time.Minute)),
+		b.Fours().WithGamesPlayed(12).WithLosses(1).Build(start.Add(1*time.Hour + 30*time.Minute)),
+		b.Fours().WithGamesPlayed(13).WithWins(2).Build(start.Add(1*time.Hour + 45*time.Minute)),
+
+		b.Solo().WithGamesPlayed(0).Build(start.Add(10 * time.Hour)),
+		b.Solo().WithGamesPlayed(2).WithWins(1).WithLosses(1).Build(start.Add(10*time.Hour + 20*time.Minute)),
+		b.Solo().WithGamesPlayed(3).WithWins(2).Build(start.Add(10*time.Hour + 30*time.Minute)),
+	}
+
+	outcomes := func(page app.GamesPage) []domain.GameOutcome {
+		result := make([]domain.GameOutcome, 0, len(page.Games))
+		for _, segment := range page.Games {
+			if segment.Game == nil {
+				result = append(result, "")
+				continue
+			}
+			result = append(result, segment.Game.Outcome)
+		}
+		return result
+	}
+
+	t.Run("every session in the range", func(t *testing.T) {
+		t.Parallel()
+
+		var gotStart, gotEnd time.Time
+		getPlayerPITs := func(ctx context.Context, _ string, start, end time.Time) ([]domain.PlayerPIT, error) {
+			gotStart = start
+			gotEnd = end
+			return stats, nil
+		}
+		getGames := app.BuildGetGames(getPlayerPITs, computeSessions)
+
+		page, err := getGames(t.Context(), uuid, start, end, 100, app.SessionOptions{})
+		require.NoError(t, err)
+		require.False(t, page.HasMore)
+		require.Equal(t, []domain.GameOutcome{
+			domain.GameOutcomeWin, domain.GameOutcomeLoss, domain.GameOutcomeWin,
+			"", domain.GameOutcomeWin,
+		}, outcomes(page))
+		require.Equal(t, domain.GamemodeSolo, page.Games[4].Game.Gamemode)
+		require.Equal(t, stats[4].QueriedAt, page.Games[3].Start.QueriedAt, "the unattributable stretch is one segment")
+		require.Equal(t, stats[5].QueriedAt, page.Games[3].End.QueriedAt)
+
+		require.Equal(t, start.Add(-24*time.Hour), gotStart)
+		require.Equal(t, end.Add(24*time.Hour), gotEnd)
+	})
+
+	t.Run("pages continue at the end of the last segment", func(t *testing.T) {
+		t.Parallel()
+
+		getGames := app.BuildGetGames(fixedStats(stats), computeSessions)
+
+		page, err := getGames(t.Context(), uuid, start, end, 2, app.SessionOptions{})
+		require.NoError(t, err)
+		require.True(t, page.HasMore)
+		require.Len(t, page.Games, 2)
+
+		page, err = getGames(t.Context(), uuid, page.Games[1].End.QueriedAt, end, 2, app.SessionOptions{})
+		require.NoError(t, err)
+		require.True(t, page.HasMore)
+		require.Equal(t, []domain.GameOutcome{domain.GameOutcomeWin, ""}, outcomes(page))
+
+		page, err = getGames(t.Context(), uuid, page.Games[1].End.QueriedAt, end, 2, app.SessionOptions{})
+		require.NoError(t, err)
+		require.False(t, page.HasMore)
+		require.Equal(t, []domain.GameOutcome{domain.GameOutcomeWin}, outcomes(page))
+	})
+
+	t.Run("games starting outside the range are left out", func(t *testing.T) {
+		t.Parallel()
+
+		getGames := app.BuildGetGames(fixedStats(stats), computeSessions)
+
+		page, err := getGames(t.Context(), uuid, stats[1].QueriedAt, stats[5].QueriedAt, 100, app.SessionOptions{})
+		require.NoError(t, err)
+		require.Equal(t, []domain.GameOutcome{domain.GameOutcomeLoss, domain.GameOutcomeWin, ""}, outcomes(page))
+	})
+
+	t.Run("gamemode", func(t *testing.T) {
+		t.Parallel()
+
+		getGames := app.BuildGetGames(fixedStats(stats), computeSessions)
+
+		page, err := getGames(t.Context(), uuid, start, end, 100, app.SessionOptions{Gamemode: domain.GamemodeSolo})
+		require.NoError(t, err)
+		require.Equal(t, []domain.GameOutcome{"", domain.GameOutcomeWin}, outcomes(page))
+	})
+
+	t.Run("invalid arguments", func(t *testing.T) {
+		t.Parallel()
+
+		getGames := app.BuildGetGames(fixedStats(stats), computeSessions)
+
+		_, err := getGames(t.Context(), "not-a-uuid", start, end, 10, app.SessionOptions{})
+		require.Error(t, err)
+
+		_, err = getGames(t.Context(), uuid, end, start, 10, app.SessionOptions{})
+		require.Error(t, err)
+
+		for _, limit := range []int{0, app.MaxGamesPageSize + 1} {
+			_, err = getGames(t.Context(), uuid, start, end, limit, app.SessionOptions{})
+			require.Error(t, err)
+		}
+	})
+
+	t.Run("failed stats", func(t *testing.T) {
+		t.Parallel()
+
+		getPlayerPITs := func(ctx context.Context, _ string, _, _ time.Time) ([]domain.PlayerPIT, error) {
+			return nil, errors.New("db down")
+		}
+		getGames := app.BuildGetGames(getPlayerPITs, computeSessions)
+
+		_, err := getGames(t.Context(), uuid, start, end, 10, app.SessionOptions{})
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,106 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+
+	"github.com/Amund211/flashlight/internal/adapters/cache"
+	"github.com/Amund211/flashlight/internal/domain"
+	"github.com/Amund211/flashlight/internal/reporting"
+)
+
+// MaxAccountsByUsernames is the most usernames resolved in one lookup. Large
+// enough for a full lobby.
+const MaxAccountsByUsernames = 100
+
+// GetAccountsByUsernames resolves several usernames at once. The result is
+// keyed by the lowercased username, and usernames without an account are left
+// out.
+type GetAccountsByUsernames func(ctx context.Context, usernames []string) (map[string]domain.Account, error)
+
+// BuildGetAccountsByUsernamesWithCache constructs a GetAccountsByUsernames
+// that looks up every username concurrently through accountByUsernameCache,
+// so it shares entries with GetAccountByUsernameWithCache when given the same
+// cache. The lookups missing the cache and the repository can then be batched
+// by the account provider.
+func BuildGetAccountsByUsernamesWithCache(
+	accountByUsernameCache cache.Cache[domain.Account],
+	provider accountProviderByUsername,
+	repo accountRepositoryByUsername,
+	nowFunc func() time.Time,
+) (GetAccountsByUsernames, error) {
+	const name = "flashlight/app/get_accounts_by_usernames_with_cache"
+
+	meter := otel.Meter(name)
+
+	metrics, err := setupGetAccountByUsernameMetrics(meter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up metrics: %w", err)
+	}
+
+	getAccountByUsernameWithoutCache := buildGetAccountByUsernameWithoutCache(provider, repo, nowFunc, metrics)
+
+	return func(ctx context.Context, usernames []string) (map[string]domain.Account, error) {
+		if len(usernames) > MaxAccountsByUsernames {
+			err := fmt.Errorf("too many usernames in app.GetAccountsByUsernames")
+			reporting.Report(ctx, err, map[string]string{
+				"usernames": strconv.Itoa(len(usernames)),
+			})
+			return nil, err
+		}
+
+		// No two accounts can have the same username with case-insensitive comparison
+		keys := make([]string, 0, len(usernames))
+		for _, username := range usernames {
+			if len(username) == 0 || len(username) > 100 {
+				err := fmt.Errorf("invalid username length")
+				reporting.Report(ctx, err, map[string]string{
+					"username": username,
+					"length":   strconv.Itoa(len(username)),
+				})
+				return nil, err
+			}
+
+			key := strings.ToLower(username)
+			if !slices.Contains(keys, key) {
+				keys = append(keys, key)
+			}
+		}
+
+		accounts := make([]domain.Account, len(keys))
+		errs := make([]error, len(keys))
+		var wg sync.WaitGroup
+		for i, key := range keys {
+			wg.Go(func() {
+				accounts[i], _, errs[i] = cache.GetOrCreate(ctx, accountByUsernameCache, key, func() (domain.Account, error) {
+					return getAccountByUsernameWithoutCache(ctx, key)
+				})
+			})
+		}
+		wg.Wait()
+
+		result := make(map[string]domain.Account, len(keys))
+		for i, key := range keys {
+			if errors.Is(errs[i], domain.ErrUsernameNotFound) {
+				continue
+			} else if errs[i] != nil {
+				// NOTE: The error is either create()'s —
+				// getAccountByUsernameWithoutCache handles its own error
+				// reporting — or GetOrCreate giving up on a done context or a
+				// contended entry, which it logs itself.
+				return nil, fmt.Errorf("failed to cache.GetOrCreate account for username: %w", errs[i])
+			}
+			result[key] = accounts[i]
+		}
+
+		return result, nil
+	}, nil
+}
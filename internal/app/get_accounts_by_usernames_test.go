@@ -0,0 +1,169 @@
+package app_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Amund211/flashlight/internal/adapters/cache"
+	"github.com/Amund211/flashlight/internal/app"
+	"github.com/Amund211/flashlight/internal/domain"
+)
+
+// mapAccountProvider resolves any number of usernames from accounts
+type mapAccountProvider struct {
+	mu sync.Mutex
+
+	accounts map[string]domain.Account
+	errs     map[string]error
+	called   []string
+}
+
+func (m *mapAccountProvider) GetAccountByUsername(ctx context.Context, username string) (domain.Account, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.called = append(m.called, username)
+	if err, ok := m.errs[username]; ok {
+		return domain.Account{}, err
+	}
+	account, ok := m.accounts[username]
+	if !ok {
+		return domain.Account{}, domain.ErrUsernameNotFound
+	}
+	return account, nil
+}
+
+// emptyAccountRepository has no accounts stored and discards writes
+type emptyAccountRepository struct{}
+
+func (emptyAccountRepository) GetAccountByUsername(ctx context.Context, username string) (domain.Account, error) {
+	return domain.Account{}, domain.ErrUsernameNotFound
+}
+
+func (emptyAccountRepository) RemoveUsername(ctx context.Context, username string) error {
+	return nil
+}
+
+func (emptyAccountRepository) StoreAccount(ctx context.Context, account domain.Account) error {
+	return nil
+}
+
+func TestBuildGetAccountsByUsernamesWithCache(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	nowFunc := func() time.Time {
+		return now
+	}
+	accounts := map[string]domain.Account{
+		"skydeath": {
+			UUID:      "a937646b-f115-44c3-8dbf-9ae4a65669a0",
+			Username:  "Skydeath",
+			QueriedAt: now,
+		},
+		"dinnerbone": {
+			UUID:      "61699b2e-d327-4a01-9f1e-0ea8c3f06bc6",
+			Username:  "Dinnerbone",
+			QueriedAt: now,
+		},
+	}
+
+	build := func(t *testing.T, c cache.Cache[domain.Account], provider *mapAccountProvider) app.GetAccountsByUsernames {
+		t.Helper()
+
+		getAccountsByUsernames, err := app.BuildGetAccountsByUsernamesWithCache(c, provider, emptyAccountRepository{}, nowFunc)
+		require.NoError(t, err)
+		return getAccountsByUsernames
+	}
+
+	t.Run("resolves every username once", func(t *testing.T) {
+		t.Parallel()
+
+		provider := &mapAccountProvider{accounts: accounts}
+		getAccountsByUsernames := build(t, cache.NewBasicCache[domain.Account](), provider)
+
+		result, err := getAccountsByUsernames(t.Context(), []string{"Skydeath", "dinnerbone", "SKYDEATH", "somenickeduser"})
+		require.NoError(t, err)
+		require.Equal(t, accounts, result)
+		require.ElementsMatch(t, []string{"skydeath", "dinnerbone", "somenickeduser"}, provider.called)
+	})
+
+	t.Run("cached usernames are not looked up again", func(t *testing.T) {
+		t.Parallel()
+
+		c := cache.NewBasicCache[domain.Account]()
+
+		// Shares the cache with the single username lookup
+		singleProvider := &mapAccountProvider{accounts: accounts}
+		getAccountByUsername, err := app.BuildGetAccountByUsernameWithCache(c, singleProvider, emptyAccountRepository{}, nowFunc)
+		require.NoError(t, err)
+		_, err = getAccountByUsername(t.Context(), "skydeath")
+		require.NoError(t, err)
+
+		provider := &mapAccountProvider{accounts: accounts}
+		getAccountsByUsernames := build(t, c, provider)
+
+		result, err := getAccountsByUsernames(t.Context(), []string{"skydeath", "dinnerbone"})
+		require.NoError(t, err)
+		require.Equal(t, accounts, result)
+		require.Equal(t, []string{"dinnerbone"}, provider.called)
+	})
+
+	t.Run("no usernames", func(t *testing.T) {
+		t.Parallel()
+
+		provider := &mapAccountProvider{accounts: accounts}
+		getAccountsByUsernames := build(t, cache.NewBasicCache[domain.Account](), provider)
+
+		result, err := getAccountsByUsernames(t.Context(), []string{})
+		require.NoError(t, err)
+		require.Empty(t, result)
+		require.Empty(t, provider.called)
+	})
+
+	t.Run("too many usernames", func(t *testing.T) {
+		t.Parallel()
+
+		provider := &mapAccountProvider{accounts: accounts}
+		getAccountsByUsernames := build(t, cache.NewBasicCache[domain.Account](), provider)
+
+		usernames := make([]string, app.MaxAccountsByUsernames+1)
+		for i := range usernames {
+			usernames[i] = "skydeath"
+		}
+		_, err := getAccountsByUsernames(t.Context(), usernames)
+		require.Error(t, err)
+		require.Empty(t, provider.called)
+	})
+
+	t.Run("invalid username length", func(t *testing.T) {
+		t.Parallel()
+
+		provider := &mapAccountProvider{accounts: accounts}
+		getAccountsByUsernames := build(t, cache.NewBasicCache[domain.Account](), provider)
+
+		_, err := getAccountsByUsernames(t.Context(), []string{"skydeath", ""})
+		require.Error(t, err)
+		require.Empty(t, provider.called)
+	})
+
+	t.Run("a failed lookup fails the whole lookup", func(t *testing.T) {
+		t.Parallel()
+
+		provider := &mapAccountProvider{
+			accounts: accounts,
+			errs: map[string]error{
+				"dinnerbone": assert.AnError,
+			},
+		}
+		getAccountsByUsernames := build(t, cache.NewBasicCache[domain.Account](), provider)
+
+		_, err := getAccountsByUsernames(t.Context(), []string{"skydeath", "dinnerbone"})
+		require.ErrorIs(t, err, assert.AnError)
+	})
+}
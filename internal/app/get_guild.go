@@ -0,0 +1,132 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/Amund211/flashlight/internal/adapters/cache"
+	"github.com/Amund211/flashlight/internal/domain"
+	"github.com/Amund211/flashlight/internal/logging"
+	"github.com/Amund211/flashlight/internal/reporting"
+	"github.com/Amund211/flashlight/internal/strutils"
+)
+
+// GetGuildByPlayer returns the guild the player is in, or nil if they are not
+// in a guild
+type GetGuildByPlayer func(ctx context.Context, uuid string) (*domain.Guild, error)
+
+type getGuildMetricsCollection struct {
+	returnCount metric.Int64Counter
+}
+
+func setupGetGuildMetrics(meter metric.Meter) (getGuildMetricsCollection, error) {
+	returnCount, err := meter.Int64Counter("app/get_guild_by_player/return_count")
+	if err != nil {
+		return getGuildMetricsCollection{}, fmt.Errorf("failed to create return count metric: %w", err)
+	}
+
+	return getGuildMetricsCollection{
+		returnCount: returnCount,
+	}, nil
+}
+
+type guildProvider interface {
+	GetGuildByPlayer(ctx context.Context, uuid string) (*domain.Guild, error)
+}
+
+type guildRepository interface {
+	StoreGuild(ctx context.Context, guild *domain.Guild) error
+}
+
+func getAndPersistGuildWithoutCache(ctx context.Context, provider guildProvider, repo guildRepository, uuid string) (*domain.Guild, error) {
+	getCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	guild, err := provider.GetGuildByPlayer(getCtx, uuid)
+	if err != nil {
+		// NOTE: guildProvider implementations handle their own error reporting
+		return nil, fmt.Errorf("could not get guild for uuid: %w", err)
+	}
+
+	if guild == nil {
+		return nil, nil
+	}
+
+	// Ignore cancellations from the request context and try to store the data anyway
+	// Take a maximum of 1 second to not block the request for too long
+	storeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 1*time.Second)
+	defer cancel()
+	err = repo.StoreGuild(storeCtx, guild)
+	if err != nil {
+		// NOTE: guildRepository implementations handle their own error reporting
+		logging.FromContext(ctx).ErrorContext(ctx, "failed to store guild", "error", err.Error())
+
+		// NOTE: We still return the guild to fulfill the request even though storing failed
+	}
+
+	return guild, nil
+}
+
+func BuildGetGuildByPlayerWithCache(
+	guildByPlayerCache cache.Cache[*domain.Guild],
+	provider guildProvider,
+	repo guildRepository,
+) (GetGuildByPlayer, error) {
+	const name = "flashlight/app/get_guild_by_player"
+
+	meter := otel.Meter(name)
+
+	metrics, err := setupGetGuildMetrics(meter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up metrics: %w", err)
+	}
+
+	type trackingInfo struct {
+		cached       bool
+		success      bool
+		invalidInput bool
+		inGuild      bool
+	}
+
+	track := func(ctx context.Context, info trackingInfo) {
+		metrics.returnCount.Add(
+			ctx,
+			1,
+			metric.WithAttributes(
+				attribute.Bool("cached", info.cached),
+				attribute.Bool("success", info.success),
+				attribute.Bool("invalid_input", info.invalidInput),
+				attribute.Bool("in_guild", info.inGuild),
+			),
+		)
+	}
+
+	return func(ctx context.Context, uuid string) (*domain.Guild, error) {
+		if !strutils.UUIDIsNormalized(uuid) {
+			err := fmt.Errorf("UUID is not normalized")
+			reporting.Report(ctx, err)
+			track(ctx, trackingInfo{success: false, invalidInput: true})
+			return nil, err
+		}
+
+		// NOTE: Players not in a guild are cached as nil, so we don't keep
+		//       asking Hypixel about them
+		guild, created, err := cache.GetOrCreate(ctx, guildByPlayerCache, uuid, func() (*domain.Guild, error) {
+			return getAndPersistGuildWithoutCache(ctx, provider, repo, uuid)
+		})
+		if err != nil {
+			// NOTE: The error is either create()'s — getAndPersistGuildWithoutCache
+			// handles its own error reporting — or GetOrCreate giving up on a
+			// done context or a contended entry, which it logs itself.
+			track(ctx, trackingInfo{success: false})
+			return nil, fmt.Errorf("failed to cache.GetOrCreate guild for uuid: %w", err)
+		}
+
+		track(ctx, trackingInfo{success: true, cached: !created, inGuild: guild != nil})
+		return guild, nil
+	}, nil
+}
@@ -0,0 +1,125 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Amund211/flashlight/internal/adapters/cache"
+	"github.com/Amund211/flashlight/internal/app"
+	"github.com/Amund211/flashlight/internal/domain"
+)
+
+type mockGuildProvider struct {
+	t *testing.T
+
+	uuid  string
+	calls int
+	guild *domain.Guild
+	err   error
+}
+
+func (m *mockGuildProvider) GetGuildByPlayer(ctx context.Context, uuid string) (*domain.Guild, error) {
+	m.t.Helper()
+	require.Equal(m.t, m.uuid, uuid)
+
+	m.calls++
+	return m.guild, m.err
+}
+
+type mockGuildRepository struct {
+	stored []*domain.Guild
+	err    error
+}
+
+func (m *mockGuildRepository) StoreGuild(ctx context.Context, guild *domain.Guild) error {
+	m.stored = append(m.stored, guild)
+	return m.err
+}
+
+func TestBuildGetGuildByPlayerWithCache(t *testing.T) {
+	t.Parallel()
+
+	UUID := "12345678-1234-1234-1234-123456789012"
+	guild := &domain.Guild{
+		ID:        "abc",
+		QueriedAt: time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC),
+		Name:      "Guild",
+		Members:   []domain.GuildMember{{UUID: UUID, Rank: "Guild Master"}},
+	}
+
+	t.Run("gets, stores and caches the guild", func(t *testing.T) {
+		t.Parallel()
+
+		provider := &mockGuildProvider{t: t, uuid: UUID, guild: guild}
+		repo := &mockGuildRepository{}
+		getGuild, err := app.BuildGetGuildByPlayerWithCache(cache.NewBasicCache[*domain.Guild](), provider, repo)
+		require.NoError(t, err)
+
+		for range 2 {
+			got, err := getGuild(t.Context(), UUID)
+			require.NoError(t, err)
+			require.Equal(t, guild, got)
+		}
+
+		require.Equal(t, 1, provider.calls)
+		require.Equal(t, []*domain.Guild{guild}, repo.stored)
+	})
+
+	t.Run("not in a guild is cached", func(t *testing.T) {
+		t.Parallel()
+
+		provider := &mockGuildProvider{t: t, uuid: UUID}
+		repo := &mockGuildRepository{}
+		getGuild, err := app.BuildGetGuildByPlayerWithCache(cache.NewBasicCache[*domain.Guild](), provider, repo)
+		require.NoError(t, err)
+
+		for range 2 {
+			got, err := getGuild(t.Context(), UUID)
+			require.NoError(t, err)
+			require.Nil(t, got)
+		}
+
+		require.Equal(t, 1, provider.calls)
+		require.Empty(t, repo.stored)
+	})
+
+	t.Run("store error still returns the guild", func(t *testing.T) {
+		t.Parallel()
+
+		provider := &mockGuildProvider{t: t, uuid: UUID, guild: guild}
+		repo := &mockGuildRepository{err: assert.AnError}
+		getGuild, err := app.BuildGetGuildByPlayerWithCache(cache.NewBasicCache[*domain.Guild](), provider, repo)
+		require.NoError(t, err)
+
+		got, err := getGuild(t.Context(), UUID)
+		require.NoError(t, err)
+		require.Equal(t, guild, got)
+	})
+
+	t.Run("provider error", func(t *testing.T) {
+		t.Parallel()
+
+		provider := &mockGuildProvider{t: t, uuid: UUID, err: domain.ErrTemporarilyUnavailable}
+		getGuild, err := app.BuildGetGuildByPlayerWithCache(cache.NewBasicCache[*domain.Guild](), provider, &mockGuildRepository{})
+		require.NoError(t, err)
+
+		_, err = getGuild(t.Context(), UUID)
+		require.ErrorIs(t, err, domain.ErrTemporarilyUnavailable)
+	})
+
+	t.Run("non-normalized uuid", func(t *testing.T) {
+		t.Parallel()
+
+		provider := &mockGuildProvider{t: t}
+		getGuild, err := app.BuildGetGuildByPlayerWithCache(cache.NewBasicCache[*domain.Guild](), provider, &mockGuildRepository{})
+		require.NoError(t, err)
+
+		_, err = getGuild(t.Context(), "1234567812341234123412345678901")
+		require.Error(t, err)
+		require.Equal(t, 0, provider.calls)
+	})
+}
@@ -0,0 +1,134 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/Amund211/flashlight/internal/adapters/cache"
+	"github.com/Amund211/flashlight/internal/domain"
+	"github.com/Amund211/flashlight/internal/logging"
+	"github.com/Amund211/flashlight/internal/reporting"
+	"github.com/Amund211/flashlight/internal/strutils"
+)
+
+type GetStatus func(ctx context.Context, uuid string) (domain.PlayerStatus, error)
+
+type getStatusMetricsCollection struct {
+	returnCount metric.Int64Counter
+}
+
+func setupGetStatusMetrics(meter metric.Meter) (getStatusMetricsCollection, error) {
+	returnCount, err := meter.Int64Counter("app/get_status/return_count")
+	if err != nil {
+		return getStatusMetricsCollection{}, fmt.Errorf("failed to create return count metric: %w", err)
+	}
+
+	return getStatusMetricsCollection{
+		returnCount: returnCount,
+	}, nil
+}
+
+type statusProvider interface {
+	GetStatus(ctx context.Context, uuid string) (domain.PlayerStatus, error)
+}
+
+type statusRepository interface {
+	StoreStatus(ctx context.Context, status *domain.PlayerStatus) error
+}
+
+func buildGetStatusWithoutCache(
+	provider statusProvider,
+	repo statusRepository,
+) func(ctx context.Context, uuid string) (domain.PlayerStatus, error) {
+	return func(ctx context.Context, uuid string) (domain.PlayerStatus, error) {
+		getCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		status, err := provider.GetStatus(getCtx, uuid)
+		if err != nil {
+			// NOTE: statusProvider implementations handle their own error reporting
+			return domain.PlayerStatus{}, fmt.Errorf("could not get status for uuid: %w", err)
+		}
+
+		// Ignore cancellations from the request context and try to store the data anyway
+		// Take a maximum of 1 second to not block the request for too long
+		storeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 1*time.Second)
+		defer cancel()
+		err = repo.StoreStatus(storeCtx, &status)
+		if err != nil {
+			// NOTE: statusRepository implementations handle their own error reporting
+			logging.FromContext(ctx).ErrorContext(ctx, "failed to store status", "error", err.Error())
+
+			// NOTE: We still return the status to fulfill the request even though storing failed
+		}
+
+		return status, nil
+	}
+}
+
+// BuildGetStatusWithCache returns the online status of a player. Changes in
+// the status are stored in repo, to split sessions when the player goes
+// offline.
+//
+// The status changes frequently, so statusByUUIDCache should have a short TTL.
+func BuildGetStatusWithCache(
+	statusByUUIDCache cache.Cache[domain.PlayerStatus],
+	provider statusProvider,
+	repo statusRepository,
+) (GetStatus, error) {
+	const name = "flashlight/app/get_status"
+
+	meter := otel.Meter(name)
+
+	metrics, err := setupGetStatusMetrics(meter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up metrics: %w", err)
+	}
+
+	getStatusWithoutCache := buildGetStatusWithoutCache(provider, repo)
+
+	type trackingInfo struct {
+		cached       bool
+		success      bool
+		invalidInput bool
+	}
+
+	track := func(ctx context.Context, info trackingInfo) {
+		metrics.returnCount.Add(
+			ctx,
+			1,
+			metric.WithAttributes(
+				attribute.Bool("cached", info.cached),
+				attribute.Bool("success", info.success),
+				attribute.Bool("invalid_input", info.invalidInput),
+			),
+		)
+	}
+
+	return func(ctx context.Context, uuid string) (domain.PlayerStatus, error) {
+		if !strutils.UUIDIsNormalized(uuid) {
+			err := fmt.Errorf("UUID is not normalized")
+			reporting.Report(ctx, err)
+			track(ctx, trackingInfo{success: false, invalidInput: true})
+			return domain.PlayerStatus{}, err
+		}
+
+		status, created, err := cache.GetOrCreate(ctx, statusByUUIDCache, uuid, func() (domain.PlayerStatus, error) {
+			return getStatusWithoutCache(ctx, uuid)
+		})
+		if err != nil {
+			// NOTE: The error is either create()'s — getStatusWithoutCache
+			// handles its own error reporting — or GetOrCreate giving up on a
+			// done context or a contended entry, which it logs itself.
+			track(ctx, trackingInfo{success: false})
+			return domain.PlayerStatus{}, fmt.Errorf("failed to cache.GetOrCreate status for uuid: %w", err)
+		}
+
+		track(ctx, trackingInfo{success: true, cached: !created})
+		return status, nil
+	}, nil
+}
@@ -0,0 +1,116 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Amund211/flashlight/internal/adapters/cache"
+	"github.com/Amund211/flashlight/internal/app"
+	"github.com/Amund211/flashlight/internal/domain"
+)
+
+type mockStatusProvider struct {
+	t *testing.T
+
+	uuid   string
+	calls  int
+	status domain.PlayerStatus
+	err    error
+}
+
+func (m *mockStatusProvider) GetStatus(ctx context.Context, uuid string) (domain.PlayerStatus, error) {
+	m.t.Helper()
+	require.Equal(m.t, m.uuid, uuid)
+
+	m.calls++
+	return m.status, m.err
+}
+
+type mockStatusRepository struct {
+	stored []domain.PlayerStatus
+	err    error
+}
+
+func (m *mockStatusRepository) StoreStatus(ctx context.Context, status *domain.PlayerStatus) error {
+	m.stored = append(m.stored, *status)
+	return m.err
+}
+
+func TestBuildGetStatusWithCache(t *testing.T) {
+	t.Parallel()
+
+	UUID := "12345678-1234-1234-1234-123456789012"
+	now := time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC)
+
+	t.Run("calls provider once and caches the result", func(t *testing.T) {
+		t.Parallel()
+
+		c := cache.NewBasicCache[domain.PlayerStatus]()
+		status := domain.PlayerStatus{
+			UUID:      UUID,
+			QueriedAt: now,
+			Online:    true,
+			GameType:  new("BEDWARS"),
+			Mode:      new("LOBBY"),
+		}
+		provider := &mockStatusProvider{t: t, uuid: UUID, status: status}
+		repo := &mockStatusRepository{}
+		getStatus, err := app.BuildGetStatusWithCache(c, provider, repo)
+		require.NoError(t, err)
+
+		for range 2 {
+			got, err := getStatus(t.Context(), UUID)
+			require.NoError(t, err)
+			require.Equal(t, status, got)
+		}
+
+		require.Equal(t, 1, provider.calls)
+		require.Equal(t, []domain.PlayerStatus{status}, repo.stored)
+	})
+
+	t.Run("storage error still returns the status", func(t *testing.T) {
+		t.Parallel()
+
+		c := cache.NewBasicCache[domain.PlayerStatus]()
+		status := domain.PlayerStatus{UUID: UUID, QueriedAt: now}
+		provider := &mockStatusProvider{t: t, uuid: UUID, status: status}
+		repo := &mockStatusRepository{err: assert.AnError}
+		getStatus, err := app.BuildGetStatusWithCache(c, provider, repo)
+		require.NoError(t, err)
+
+		got, err := getStatus(t.Context(), UUID)
+		require.NoError(t, err)
+		require.Equal(t, status, got)
+	})
+
+	t.Run("provider error", func(t *testing.T) {
+		t.Parallel()
+
+		c := cache.NewBasicCache[domain.PlayerStatus]()
+		provider := &mockStatusProvider{t: t, uuid: UUID, err: domain.ErrTemporarilyUnavailable}
+		repo := &mockStatusRepository{}
+		getStatus, err := app.BuildGetStatusWithCache(c, provider, repo)
+		require.NoError(t, err)
+
+		_, err = getStatus(t.Context(), UUID)
+		require.ErrorIs(t, err, domain.ErrTemporarilyUnavailable)
+		require.Empty(t, repo.stored)
+	})
+
+	t.Run("non-normalized uuid", func(t *testing.T) {
+		t.Parallel()
+
+		c := cache.NewBasicCache[domain.PlayerStatus]()
+		provider := &mockStatusProvider{t: t, err: assert.AnError}
+		getStatus, err := app.BuildGetStatusWithCache(c, provider, &mockStatusRepository{})
+		require.NoError(t, err)
+
+		_, err = getStatus(t.Context(), "1234567812341234123412345678901")
+		require.Error(t, err)
+		require.Equal(t, 0, provider.calls)
+	})
+}
@@ -0,0 +1,73 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Amund211/flashlight/internal/domain"
+	"github.com/Amund211/flashlight/internal/logging"
+	"github.com/Amund211/flashlight/internal/reporting"
+	"github.com/Amund211/flashlight/internal/strutils"
+)
+
+// GetOnlineTransitions returns the observed changes in the player's online
+// status in [start, end], oldest first
+type GetOnlineTransitions func(ctx context.Context, uuid string, start, end time.Time) ([]domain.OnlineTransition, error)
+
+type onlineTransitionRepository interface {
+	GetOnlineTransitions(ctx context.Context, uuid string, start, end time.Time) ([]domain.OnlineTransition, error)
+}
+
+// BuildGetOnlineTransitions constructs a GetOnlineTransitions. Transitions
+// are only observed when the status is looked up, so most players have none.
+func BuildGetOnlineTransitions(repo onlineTransitionRepository) GetOnlineTransitions {
+	return func(ctx context.Context, uuid string, start, end time.Time) ([]domain.OnlineTransition, error) {
+		if !strutils.UUIDIsNormalized(uuid) {
+			err := fmt.Errorf("UUID is not normalized")
+			reporting.Report(ctx, err, map[string]string{
+				"uuid": uuid,
+			})
+			return nil, err
+		}
+
+		transitions, err := repo.GetOnlineTransitions(ctx, uuid, start, end)
+		if err != nil {
+			// NOTE: onlineTransitionRepository implementations handle their own error reporting
+			return nil, fmt.Errorf("failed to get online transitions: %w", err)
+		}
+
+		return transitions, nil
+	}
+}
+
+// BuildComputeSessionsWithOnlineTransitions wraps computeSessions to also end
+// sessions where the player was seen going offline. The transitions are
+// fetched for the interval the stats span. When that fails the sessions are
+// only split on inactivity.
+func BuildComputeSessionsWithOnlineTransitions(computeSessions ComputeSessions, getOnlineTransitions GetOnlineTransitions) ComputeSessions {
+	return func(ctx context.Context, stats []domain.PlayerPIT, start, end time.Time, options SessionOptions) []domain.Session {
+		if len(stats) <= 1 {
+			return computeSessions(ctx, stats, start, end, options)
+		}
+
+		first, last := stats[0].QueriedAt, stats[0].QueriedAt
+		for i := range stats {
+			if stats[i].QueriedAt.Before(first) {
+				first = stats[i].QueriedAt
+			}
+			if stats[i].QueriedAt.After(last) {
+				last = stats[i].QueriedAt
+			}
+		}
+
+		transitions, err := getOnlineTransitions(ctx, stats[0].UUID, first, last)
+		if err != nil {
+			// NOTE: GetOnlineTransitions implementations handle their own error reporting
+			logging.FromContext(ctx).WarnContext(ctx, "Failed to get online transitions", "error", err)
+		}
+		options.OnlineTransitions = transitions
+
+		return computeSessions(ctx, stats, start, end, options)
+	}
+}
@@ -0,0 +1,166 @@
+package app_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Amund211/flashlight/internal/app"
+	"github.com/Amund211/flashlight/internal/domain"
+	"github.com/Amund211/flashlight/internal/domaintest"
+)
+
+type mockOnlineTransitionRepository struct {
+	t *testing.T
+
+	uuid        string
+	start       time.Time
+	end         time.Time
+	transitions []domain.OnlineTransition
+	err         error
+}
+
+func (m *mockOnlineTransitionRepository) GetOnlineTransitions(ctx context.Context, uuid string, start, end time.Time) ([]domain.OnlineTransition, error) {
+	m.t.Helper()
+	require.Equal(m.t, m.uuid, uuid)
+	require.Equal(m.t, m.start, start)
+	require.Equal(m.t, m.end, end)
+
+	return m.transitions, m.err
+}
+
+func TestBuildGetOnlineTransitions(t *testing.T) {
+	t.Parallel()
+
+	uuid := "01234567-89ab-cdef-0123-456789abcdef"
+	start := time.Date(2026, time.October, 17, 12, 0, 0, 0, time.UTC)
+	end := time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC)
+	transitions := []domain.OnlineTransition{
+		{At: start.Add(time.Hour), Online: true},
+		{At: start.Add(2 * time.Hour), Online: false},
+	}
+
+	t.Run("returns the stored transitions", func(t *testing.T) {
+		t.Parallel()
+
+		repo := &mockOnlineTransitionRepository{t: t, uuid: uuid, start: start, end: end, transitions: transitions}
+		getOnlineTransitions := app.BuildGetOnlineTransitions(repo)
+
+		result, err := getOnlineTransitions(t.Context(), uuid, start, end)
+		require.NoError(t, err)
+		require.Equal(t, transitions, result)
+	})
+
+	t.Run("repository failure", func(t *testing.T) {
+		t.Parallel()
+
+		repo := &mockOnlineTransitionRepository{t: t, uuid: uuid, start: start, end: end, err: assert.AnError}
+		getOnlineTransitions := app.BuildGetOnlineTransitions(repo)
+
+		_, err := getOnlineTransitions(t.Context(), uuid, start, end)
+		require.ErrorIs(t, err, assert.AnError)
+	})
+
+	t.Run("invalid uuid", func(t *testing.T) {
+		t.Parallel()
+
+		repo := &mockOnlineTransitionRepository{t: t, err: assert.AnError}
+		getOnlineTransitions := app.BuildGetOnlineTransitions(repo)
+
+		_, err := getOnlineTransitions(t.Context(), "0123456789abcdef0123456789abcdef", start, end)
+		require.Error(t, err)
+		require.NotErrorIs(t, err, assert.AnError)
+	})
+}
+
+func TestBuildComputeSessionsWithOnlineTransitions(t *testing.T) {
+	t.Parallel()
+
+	uuid := "01234567-89ab-cdef-0123-456789abcdef"
+	start := time.Date(2026, time.October, 17, 12, 0, 0, 0, time.UTC)
+	pb := domaintest.NewPlayerBuilder(uuid).FromDB().Fours()
+	// Out of order, as the stats may be passed to ComputeSessions
+	stats := []domain.PlayerPIT{
+		pb.WithGamesPlayed(1).WithExperience(600).Build(start.Add(20 * time.Minute)),
+		pb.WithGamesPlayed(0).WithExperience(500).Build(start),
+		pb.WithGamesPlayed(2).WithExperience(700).Build(start.Add(40 * time.Minute)),
+	}
+	transitions := []domain.OnlineTransition{
+		{At: start.Add(30 * time.Minute), Online: false},
+	}
+
+	// Records the options it is called with
+	makeComputeSessions := func() (app.ComputeSessions, *[]app.SessionOptions) {
+		calls := []app.SessionOptions{}
+		return func(ctx context.Context, _ []domain.PlayerPIT, _, _ time.Time, options app.SessionOptions) []domain.Session {
+			calls = append(calls, options)
+			return []domain.Session{}
+		}, &calls
+	}
+
+	t.Run("passes on the transitions in the span of the stats", func(t *testing.T) {
+		t.Parallel()
+
+		computeSessions, calls := makeComputeSessions()
+		getOnlineTransitions := app.BuildGetOnlineTransitions(&mockOnlineTransitionRepository{
+			t: t, uuid: uuid, start: start, end: start.Add(40 * time.Minute), transitions: transitions,
+		})
+
+		withTransitions := app.BuildComputeSessionsWithOnlineTransitions(computeSessions, getOnlineTransitions)
+		withTransitions(t.Context(), stats, start, start.Add(time.Hour), app.SessionOptions{Gamemode: domain.GamemodeFours})
+
+		require.Equal(t, []app.SessionOptions{{Gamemode: domain.GamemodeFours, OnlineTransitions: transitions}}, *calls)
+	})
+
+	t.Run("failure falls back to inactivity", func(t *testing.T) {
+		t.Parallel()
+
+		computeSessions, calls := makeComputeSessions()
+		getOnlineTransitions := func(ctx context.Context, uuid string, start, end time.Time) ([]domain.OnlineTransition, error) {
+			return nil, assert.AnError
+		}
+
+		withTransitions := app.BuildComputeSessionsWithOnlineTransitions(computeSessions, getOnlineTransitions)
+		withTransitions(t.Context(), stats, start, start.Add(time.Hour), app.SessionOptions{})
+
+		require.Equal(t, []app.SessionOptions{{}}, *calls)
+	})
+
+	t.Run("too few stats for a session", func(t *testing.T) {
+		t.Parallel()
+
+		computeSessions, calls := makeComputeSessions()
+		getOnlineTransitions := func(ctx context.Context, uuid string, start, end time.Time) ([]domain.OnlineTransition, error) {
+			t.Fatal("transitions should not be fetched")
+			return nil, nil
+		}
+
+		withTransitions := app.BuildComputeSessionsWithOnlineTransitions(computeSessions, getOnlineTransitions)
+		withTransitions(t.Context(), stats[:1], start, start.Add(time.Hour), app.SessionOptions{})
+
+		require.Len(t, *calls, 1)
+	})
+
+	t.Run("going offline ends the session", func(t *testing.T) {
+		t.Parallel()
+
+		getOnlineTransitions := func(ctx context.Context, uuid string, start, end time.Time) ([]domain.OnlineTransition, error) {
+			return transitions, nil
+		}
+		now := func() time.Time { return start.Add(48 * time.Hour) }
+
+		withTransitions := app.BuildComputeSessionsWithOnlineTransitions(app.BuildComputeSessions(now), getOnlineTransitions)
+		sessions := withTransitions(t.Context(), slices.Clone(stats), start, start.Add(time.Hour), app.SessionOptions{})
+		require.Len(t, sessions, 1)
+		require.Equal(t, start, sessions[0].Start.QueriedAt)
+		require.Equal(t, start.Add(20*time.Minute), sessions[0].End.QueriedAt)
+
+		withoutTransitions := app.BuildComputeSessions(now)(t.Context(), slices.Clone(stats), start, start.Add(time.Hour), app.SessionOptions{})
+		require.Len(t, withoutTransitions, 1)
+		require.Equal(t, start.Add(40*time.Minute), withoutTransitions[0].End.QueriedAt)
+	})
+}
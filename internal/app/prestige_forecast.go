@@ -0,0 +1,235 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/Amund211/flashlight/internal/domain"
+	"github.com/Amund211/flashlight/internal/reporting"
+	"github.com/Amund211/flashlight/internal/strutils"
+)
+
+// forecastWindow is how far back we look for the experience gain rates a
+// forecast is based on
+const forecastWindow = 30 * 24 * time.Hour
+
+// forecastZ is the z-score of the confidence bounds (95%)
+const forecastZ = 1.96
+
+const MaxForecastTargetStars = 10000
+
+// ExperienceRates are the recent experience gain rates a forecast is based on
+type ExperienceRates struct {
+	// DailyExperience is the experience gained each UTC calendar day in the
+	// window, oldest first. The window starts at the first day we have stats
+	// for, so players we just started tracking aren't punished for the days
+	// before that.
+	DailyExperience []DailyExperience
+	// MeanPerDay and StdDevPerDay are the mean and sample standard deviation
+	// of DailyExperience
+	MeanPerDay   float64
+	StdDevPerDay float64
+
+	Sessions          int
+	SessionDuration   time.Duration
+	SessionExperience int64
+	// ExperiencePerSessionHour is 0 when there are no sessions in the window
+	ExperiencePerSessionHour float64
+}
+
+type DailyExperience struct {
+	Day        time.Time
+	Experience int64
+}
+
+// MilestoneForecast is when a player is expected to reach some amount of stars
+type MilestoneForecast struct {
+	Stars      int
+	Experience int64
+	// Remaining is 0 when the milestone is already reached
+	Remaining int64
+
+	// Expected is when the milestone is reached at the mean daily rate, while
+	// Earliest and Latest use the bounds of the rate's confidence interval.
+	// Each is nil when the rate it uses isn't positive. They are all the time
+	// of the current stats when the milestone is already reached.
+	Expected *time.Time
+	Earliest *time.Time
+	Latest   *time.Time
+
+	// PlayTime is how long the player has to play to reach the milestone at
+	// their experience per session hour. Nil when that rate is unknown.
+	PlayTime *time.Duration
+}
+
+type PrestigeForecast struct {
+	Current      domain.PlayerPIT
+	Rates        ExperienceRates
+	NextStar     MilestoneForecast
+	NextPrestige MilestoneForecast
+	// Target is nil when no target was requested
+	Target *MilestoneForecast
+}
+
+type ForecastPrestige = func(ctx context.Context, uuid string, targetStars *int) (PrestigeForecast, error)
+
+// BuildForecastPrestige constructs a ForecastPrestige that extrapolates the
+// player's experience gain over the last 30 days.
+func BuildForecastPrestige(
+	getPlayerPITs GetPlayerPITs,
+	computeSessions ComputeSessions,
+	nowFunc func() time.Time,
+) ForecastPrestige {
+	return func(ctx context.Context, uuid string, targetStars *int) (PrestigeForecast, error) {
+		if !strutils.UUIDIsNormalized(uuid) {
+			err := fmt.Errorf("UUID is not normalized")
+			reporting.Report(ctx, err, map[string]string{
+				"uuid": uuid,
+			})
+			return PrestigeForecast{}, err
+		}
+
+		if targetStars != nil && (*targetStars < 1 || *targetStars > MaxForecastTargetStars) {
+			err := fmt.Errorf("invalid target stars in app.ForecastPrestige")
+			reporting.Report(ctx, err, map[string]string{
+				"targetStars": strconv.Itoa(*targetStars),
+			})
+			return PrestigeForecast{}, err
+		}
+
+		now := nowFunc()
+		windowStart := now.Add(-forecastWindow)
+
+		// NOTE: Also fetches the current stats of the player, since the window ends now
+		stats, err := getPlayerPITs(ctx, uuid, windowStart, now)
+		if err != nil {
+			// NOTE: GetPlayerPITs implementations handle their own error reporting
+			return PrestigeForecast{}, fmt.Errorf("failed to get player pits: %w", err)
+		}
+
+		// NOTE: Sorts stats
+		sessions := computeSessions(ctx, stats, windowStart, now, SessionOptions{})
+
+		if len(stats) == 0 {
+			return PrestigeForecast{}, domain.ErrPlayerNotFound
+		}
+
+		current := stats[len(stats)-1]
+		rates := computeExperienceRates(stats, sessions, now)
+
+		currentStars := int(current.Stars())
+		forecast := PrestigeForecast{
+			Current:      current,
+			Rates:        rates,
+			NextStar:     forecastMilestone(&current, &rates, currentStars+1),
+			NextPrestige: forecastMilestone(&current, &rates, (currentStars/100+1)*100),
+		}
+		if targetStars != nil {
+			forecast.Target = new(forecastMilestone(&current, &rates, *targetStars))
+		}
+
+		return forecast, nil
+	}
+}
+
+// computeExperienceRates computes the rates from stats sorted by QueriedAt
+func computeExperienceRates(stats []domain.PlayerPIT, sessions []domain.Session, now time.Time) ExperienceRates {
+	rates := ExperienceRates{
+		DailyExperience: []DailyExperience{},
+		Sessions:        len(sessions),
+	}
+
+	for i := range sessions {
+		session := &sessions[i]
+		rates.SessionDuration += session.End.QueriedAt.Sub(session.Start.QueriedAt)
+		rates.SessionExperience += session.End.Experience - session.Start.Experience
+	}
+	if hours := rates.SessionDuration.Hours(); hours > 0 {
+		rates.ExperiencePerSessionHour = float64(rates.SessionExperience) / hours
+	}
+
+	today := utcDay(now)
+	previousExperience := stats[0].Experience
+	i := 0
+	for day := utcDay(stats[0].QueriedAt); !day.After(today); day = day.AddDate(0, 0, 1) {
+		nextDay := day.AddDate(0, 0, 1)
+		experience := previousExperience
+		for ; i < len(stats) && stats[i].QueriedAt.Before(nextDay); i++ {
+			experience = stats[i].Experience
+		}
+		rates.DailyExperience = append(rates.DailyExperience, DailyExperience{
+			Day:        day,
+			Experience: experience - previousExperience,
+		})
+		previousExperience = experience
+	}
+
+	days := float64(len(rates.DailyExperience))
+	var sum float64
+	for _, daily := range rates.DailyExperience {
+		sum += float64(daily.Experience)
+	}
+	rates.MeanPerDay = sum / days
+
+	if len(rates.DailyExperience) > 1 {
+		var squares float64
+		for _, daily := range rates.DailyExperience {
+			diff := float64(daily.Experience) - rates.MeanPerDay
+			squares += diff * diff
+		}
+		rates.StdDevPerDay = math.Sqrt(squares / (days - 1))
+	}
+
+	return rates
+}
+
+func utcDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func forecastMilestone(current *domain.PlayerPIT, rates *ExperienceRates, stars int) MilestoneForecast {
+	experience := domain.StarsToExperience(stars)
+	forecast := MilestoneForecast{
+		Stars:      stars,
+		Experience: experience,
+		Remaining:  max(experience-current.Experience, 0),
+	}
+
+	if forecast.Remaining == 0 {
+		forecast.Expected = new(current.QueriedAt)
+		forecast.Earliest = new(current.QueriedAt)
+		forecast.Latest = new(current.QueriedAt)
+		forecast.PlayTime = new(time.Duration(0))
+		return forecast
+	}
+
+	eta := func(perDay float64) *time.Time {
+		if perDay <= 0 {
+			return nil
+		}
+		days := float64(forecast.Remaining) / perDay
+		// Guard against overflowing time.Duration for tiny rates
+		if days > 100*365 {
+			return nil
+		}
+		return new(current.QueriedAt.Add(time.Duration(days * float64(24*time.Hour))))
+	}
+
+	margin := forecastZ * rates.StdDevPerDay / math.Sqrt(float64(len(rates.DailyExperience)))
+	forecast.Expected = eta(rates.MeanPerDay)
+	forecast.Earliest = eta(rates.MeanPerDay + margin)
+	forecast.Latest = eta(rates.MeanPerDay - margin)
+
+	if rates.ExperiencePerSessionHour > 0 {
+		hours := float64(forecast.Remaining) / rates.ExperiencePerSessionHour
+		if hours <= 100*365*24 {
+			forecast.PlayTime = new(time.Duration(hours * float64(time.Hour)))
+		}
+	}
+
+	return forecast
+}
@@ -0,0 +1,152 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Amund211/flashlight/internal/app"
+	"github.com/Amund211/flashlight/internal/domain"
+	"github.com/Amund211/flashlight/internal/domaintest"
+)
+
+func TestBuildForecastPrestige(t *testing.T) {
+	t.Parallel()
+
+	uuid := "01234567-89ab-cdef-0123-456789abcdef"
+	now := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+	nowFunc := func() time.Time { return now }
+	computeSessions := app.BuildComputeSessions(nowFunc)
+
+	// 150 stars and 2000 experience toward the next star
+	startExperience := domain.StarsToExperience(150) + 2000
+
+	// One hour long session per day from three days ago, gaining 5000 experience each
+	makeStats := func() []domain.PlayerPIT {
+		b := domaintest.NewPlayerBuilder(uuid).FromDB()
+		stats := []domain.PlayerPIT{}
+		for day := range 3 {
+			sessionStart := time.Date(2024, 6, 12+day, 18, 0, 0, 0, time.UTC)
+			experience := startExperience + int64(day)*5000
+			for minutes := 0; minutes <= 60; minutes += 20 {
+				gamesPlayed := day*4 + minutes/20
+				stats = append(stats, b.WithExperience(experience+int64(minutes)*5000/60).Fours().WithGamesPlayed(gamesPlayed).Build(sessionStart.Add(time.Duration(minutes)*time.Minute)))
+			}
+		}
+		return stats
+	}
+
+	makeGetPlayerPITs := func(stats []domain.PlayerPIT) app.GetPlayerPITs {
+		return func(ctx context.Context, gotUUID string, start, end time.Time) ([]domain.PlayerPIT, error) {
+			require.Equal(t, uuid, gotUUID)
+			require.Equal(t, now.Add(-30*24*time.Hour), start)
+			require.Equal(t, now, end)
+			return stats, nil
+		}
+	}
+
+	t.Run("forecasts milestones", func(t *testing.T) {
+		t.Parallel()
+
+		forecastPrestige := app.BuildForecastPrestige(makeGetPlayerPITs(makeStats()), computeSessions, nowFunc)
+		forecast, err := forecastPrestige(t.Context(), uuid, new(200))
+		require.NoError(t, err)
+
+		current := startExperience + 15000
+		require.Equal(t, current, forecast.Current.Experience)
+
+		// The window starts at the first day with stats: the 12th through the 15th
+		rates := forecast.Rates
+		require.Len(t, rates.DailyExperience, 4)
+		require.Equal(t, time.Date(2024, 6, 12, 0, 0, 0, 0, time.UTC), rates.DailyExperience[0].Day)
+		// The first stats are the baseline, so the session on the first day counts fully
+		require.Equal(t, int64(5000), rates.DailyExperience[0].Experience)
+		require.Equal(t, int64(5000), rates.DailyExperience[1].Experience)
+		require.Equal(t, int64(5000), rates.DailyExperience[2].Experience)
+		require.Equal(t, int64(0), rates.DailyExperience[3].Experience)
+		require.InDelta(t, 3750.0, rates.MeanPerDay, 1e-9)
+		require.InDelta(t, 2500.0, rates.StdDevPerDay, 1e-9)
+
+		require.Equal(t, 3, rates.Sessions)
+		require.Equal(t, 3*time.Hour, rates.SessionDuration)
+		require.Equal(t, int64(15000), rates.SessionExperience)
+		require.InDelta(t, 5000.0, rates.ExperiencePerSessionHour, 1e-9)
+
+		nextStar := forecast.NextStar
+		require.Equal(t, 154, nextStar.Stars)
+		require.Equal(t, domain.StarsToExperience(154), nextStar.Experience)
+		require.Equal(t, domain.StarsToExperience(154)-current, nextStar.Remaining)
+		require.NotNil(t, nextStar.Expected)
+		require.NotNil(t, nextStar.Earliest)
+		require.NotNil(t, nextStar.Latest)
+		require.True(t, nextStar.Earliest.Before(*nextStar.Expected))
+		require.True(t, nextStar.Latest.After(*nextStar.Expected))
+		days := float64(nextStar.Remaining) / 3750
+		require.WithinDuration(t, forecast.Current.QueriedAt.Add(time.Duration(days*float64(24*time.Hour))), *nextStar.Expected, time.Second)
+		require.NotNil(t, nextStar.PlayTime)
+		require.InDelta(t, float64(nextStar.Remaining)/5000, nextStar.PlayTime.Hours(), 1e-9)
+
+		require.Equal(t, 200, forecast.NextPrestige.Stars)
+		require.Equal(t, domain.StarsToExperience(200)-current, forecast.NextPrestige.Remaining)
+
+		require.NotNil(t, forecast.Target)
+		require.Equal(t, forecast.NextPrestige, *forecast.Target)
+	})
+
+	t.Run("target already reached", func(t *testing.T) {
+		t.Parallel()
+
+		forecastPrestige := app.BuildForecastPrestige(makeGetPlayerPITs(makeStats()), computeSessions, nowFunc)
+		forecast, err := forecastPrestige(t.Context(), uuid, new(100))
+		require.NoError(t, err)
+
+		require.NotNil(t, forecast.Target)
+		require.Equal(t, int64(0), forecast.Target.Remaining)
+		require.Equal(t, forecast.Current.QueriedAt, *forecast.Target.Expected)
+		require.Equal(t, forecast.Current.QueriedAt, *forecast.Target.Latest)
+	})
+
+	t.Run("inactive player", func(t *testing.T) {
+		t.Parallel()
+
+		stats := []domain.PlayerPIT{
+			domaintest.NewPlayerBuilder(uuid).FromDB().WithExperience(startExperience).Build(now.Add(-time.Minute)),
+		}
+		forecastPrestige := app.BuildForecastPrestige(makeGetPlayerPITs(stats), computeSessions, nowFunc)
+		forecast, err := forecastPrestige(t.Context(), uuid, nil)
+		require.NoError(t, err)
+
+		require.Nil(t, forecast.Target)
+		require.Len(t, forecast.Rates.DailyExperience, 1)
+		require.Zero(t, forecast.Rates.MeanPerDay)
+		require.Nil(t, forecast.NextStar.Expected)
+		require.Nil(t, forecast.NextStar.Earliest)
+		require.Nil(t, forecast.NextStar.Latest)
+		require.Nil(t, forecast.NextStar.PlayTime)
+	})
+
+	t.Run("no stats", func(t *testing.T) {
+		t.Parallel()
+
+		forecastPrestige := app.BuildForecastPrestige(makeGetPlayerPITs([]domain.PlayerPIT{}), computeSessions, nowFunc)
+		_, err := forecastPrestige(t.Context(), uuid, nil)
+		require.ErrorIs(t, err, domain.ErrPlayerNotFound)
+	})
+
+	t.Run("invalid arguments", func(t *testing.T) {
+		t.Parallel()
+
+		forecastPrestige := app.BuildForecastPrestige(makeGetPlayerPITs(makeStats()), computeSessions, nowFunc)
+
+		_, err := forecastPrestige(t.Context(), "not-a-uuid", nil)
+		require.Error(t, err)
+
+		_, err = forecastPrestige(t.Context(), uuid, new(0))
+		require.Error(t, err)
+
+		_, err = forecastPrestige(t.Context(), uuid, new(10001))
+		require.Error(t, err)
+	})
+}
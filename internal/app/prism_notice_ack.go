@@ -0,0 +1,32 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// AcknowledgePrismNotice records that the user dismissed a notice, which
+// stops it from being shown to them unless its frequency is always.
+// Returns domain.ErrPrismNoticeNotFound for an unknown notice id.
+type AcknowledgePrismNotice func(ctx context.Context, userID string, noticeID string) error
+
+type acknowledgePrismNoticeRepository interface {
+	AcknowledgePrismNotice(ctx context.Context, userID string, noticeID string, acknowledgedAt time.Time) error
+}
+
+func BuildAcknowledgePrismNotice(
+	repo acknowledgePrismNoticeRepository,
+	nowFunc func() time.Time,
+) AcknowledgePrismNotice {
+	return func(ctx context.Context, userID string, noticeID string) error {
+		if userID == "" {
+			return fmt.Errorf("user id is required to acknowledge a notice")
+		}
+
+		if err := repo.AcknowledgePrismNotice(ctx, userID, noticeID, nowFunc().UTC()); err != nil {
+			return fmt.Errorf("failed to acknowledge prism notice: %w", err)
+		}
+		return nil
+	}
+}
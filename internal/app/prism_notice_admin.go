@@ -0,0 +1,178 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Amund211/flashlight/internal/adapters/cache"
+	"github.com/Amund211/flashlight/internal/domain"
+	"github.com/Amund211/flashlight/internal/version"
+)
+
+type ListPrismNotices func(ctx context.Context) ([]domain.PrismNotice, error)
+
+// GetPrismNotice returns domain.ErrPrismNoticeNotFound for an unknown id.
+type GetPrismNotice func(ctx context.Context, id string) (domain.PrismNotice, error)
+
+// CreatePrismNotice validates and stores a new notice. The id and
+// timestamps on the input are ignored. Returns the stored notice.
+type CreatePrismNotice func(ctx context.Context, notice domain.PrismNotice) (domain.PrismNotice, error)
+
+// UpdatePrismNotice replaces the notice with notice.ID. CreatedAt and
+// UpdatedAt on the input are ignored. Returns the stored notice.
+type UpdatePrismNotice func(ctx context.Context, notice domain.PrismNotice) (domain.PrismNotice, error)
+
+type DeletePrismNotice func(ctx context.Context, id string) error
+
+type prismNoticeRepository interface {
+	ListPrismNotices(ctx context.Context) ([]domain.PrismNotice, error)
+	GetPrismNotice(ctx context.Context, id string) (domain.PrismNotice, error)
+	CreatePrismNotice(ctx context.Context, notice domain.PrismNotice) error
+	UpdatePrismNotice(ctx context.Context, notice domain.PrismNotice) error
+	DeletePrismNotice(ctx context.Context, id string) error
+}
+
+// ValidatePrismNotice returns an error wrapping domain.ErrInvalidPrismNotice
+// when notice can't be saved.
+func ValidatePrismNotice(notice domain.PrismNotice) error {
+	invalid := func(format string, args ...any) error {
+		return fmt.Errorf("%w: %s", domain.ErrInvalidPrismNotice, fmt.Sprintf(format, args...))
+	}
+
+	if notice.Message == "" {
+		return invalid("message is empty")
+	}
+
+	if notice.URL != "" {
+		parsed, err := url.Parse(notice.URL)
+		if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+			return invalid("url must be an absolute http(s) url")
+		}
+	}
+
+	switch notice.Severity {
+	case domain.NoticeSeverityInfo, domain.NoticeSeverityUpdate, domain.NoticeSeverityWarning, domain.NoticeSeverityCritical:
+	default:
+		return invalid("unknown severity %q", string(notice.Severity))
+	}
+
+	switch notice.Frequency {
+	case domain.NoticeFrequencyAlways, domain.NoticeFrequencyOnce, domain.NoticeFrequencyDaily, domain.NoticeFrequencyUntilAcknowledged:
+	default:
+		return invalid("unknown frequency %q", string(notice.Frequency))
+	}
+
+	if notice.DurationSeconds != nil && *notice.DurationSeconds <= 0 {
+		return invalid("duration must be positive")
+	}
+
+	if notice.ActiveFrom != nil && notice.ActiveUntil != nil && !notice.ActiveFrom.Before(*notice.ActiveUntil) {
+		return invalid("active window is empty")
+	}
+
+	targeting := notice.Targeting
+	var minimum, maximum version.Version
+	if targeting.MinPrismVersion != "" {
+		parsed, err := version.Parse(targeting.MinPrismVersion)
+		if err != nil {
+			return invalid("invalid min prism version: %s", err.Error())
+		}
+		minimum = parsed
+	}
+	if targeting.MaxPrismVersion != "" {
+		parsed, err := version.Parse(targeting.MaxPrismVersion)
+		if err != nil {
+			return invalid("invalid max prism version: %s", err.Error())
+		}
+		maximum = parsed
+	}
+	if targeting.MinPrismVersion != "" && targeting.MaxPrismVersion != "" && minimum.IsAtLeast(maximum) {
+		return invalid("prism version range is empty")
+	}
+
+	if targeting.RolloutPercentage < 0 || targeting.RolloutPercentage > 100 {
+		return invalid("rollout percentage must be between 0 and 100")
+	}
+
+	return nil
+}
+
+func BuildListPrismNotices(repo prismNoticeRepository) ListPrismNotices {
+	return repo.ListPrismNotices
+}
+
+func BuildGetPrismNotice(repo prismNoticeRepository) GetPrismNotice {
+	return repo.GetPrismNotice
+}
+
+// The admin writes drop the cached notice list once the write is durable,
+// so the instance that made the change serves it right away. Other
+// instances pick it up when their entry expires.
+
+func BuildCreatePrismNotice(
+	repo prismNoticeRepository,
+	noticeCache cache.Cache[[]domain.PrismNotice],
+	nowFunc func() time.Time,
+) CreatePrismNotice {
+	return func(ctx context.Context, notice domain.PrismNotice) (domain.PrismNotice, error) {
+		if err := ValidatePrismNotice(notice); err != nil {
+			return domain.PrismNotice{}, err
+		}
+
+		now := nowFunc().UTC()
+		notice.ID = uuid.New().String()
+		notice.CreatedAt = now
+		notice.UpdatedAt = now
+
+		if err := repo.CreatePrismNotice(ctx, notice); err != nil {
+			return domain.PrismNotice{}, fmt.Errorf("failed to create prism notice: %w", err)
+		}
+		cache.Delete(noticeCache, prismNoticesCacheKey)
+
+		return notice, nil
+	}
+}
+
+func BuildUpdatePrismNotice(
+	repo prismNoticeRepository,
+	noticeCache cache.Cache[[]domain.PrismNotice],
+	nowFunc func() time.Time,
+) UpdatePrismNotice {
+	return func(ctx context.Context, notice domain.PrismNotice) (domain.PrismNotice, error) {
+		if err := ValidatePrismNotice(notice); err != nil {
+			return domain.PrismNotice{}, err
+		}
+
+		existing, err := repo.GetPrismNotice(ctx, notice.ID)
+		if err != nil {
+			return domain.PrismNotice{}, fmt.Errorf("failed to get prism notice: %w", err)
+		}
+
+		notice.CreatedAt = existing.CreatedAt
+		notice.UpdatedAt = nowFunc().UTC()
+
+		if err := repo.UpdatePrismNotice(ctx, notice); err != nil {
+			return domain.PrismNotice{}, fmt.Errorf("failed to update prism notice: %w", err)
+		}
+		cache.Delete(noticeCache, prismNoticesCacheKey)
+
+		return notice, nil
+	}
+}
+
+func BuildDeletePrismNotice(
+	repo prismNoticeRepository,
+	noticeCache cache.Cache[[]domain.PrismNotice],
+) DeletePrismNotice {
+	return func(ctx context.Context, id string) error {
+		if err := repo.DeletePrismNotice(ctx, id); err != nil {
+			return fmt.Errorf("failed to delete prism notice: %w", err)
+		}
+		cache.Delete(noticeCache, prismNoticesCacheKey)
+		return nil
+	}
+}
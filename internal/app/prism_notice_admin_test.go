@@ -0,0 +1,158 @@
+package app_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Amund211/flashlight/internal/adapters/cache"
+	"github.com/Amund211/flashlight/internal/adapters/releaseprovider"
+	"github.com/Amund211/flashlight/internal/app"
+	"github.com/Amund211/flashlight/internal/domain"
+)
+
+func TestValidatePrismNotice(t *testing.T) {
+	t.Parallel()
+
+	valid := func() domain.PrismNotice {
+		return domain.PrismNotice{
+			Message:         "Hello",
+			URL:             "https://prismoverlay.com",
+			Severity:        domain.NoticeSeverityInfo,
+			DurationSeconds: new(10.0),
+			Frequency:       domain.NoticeFrequencyDaily,
+			ActiveFrom:      new(time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)),
+			ActiveUntil:     new(time.Date(2026, time.January, 2, 0, 0, 0, 0, time.UTC)),
+			Targeting: domain.NoticeTargeting{
+				MinPrismVersion:   "v1.12.0",
+				MaxPrismVersion:   "v1.13.0",
+				RolloutPercentage: 100,
+			},
+		}
+	}
+
+	cases := []struct {
+		name   string
+		modify func(*domain.PrismNotice)
+		valid  bool
+	}{
+		{name: "valid", modify: func(*domain.PrismNotice) {}, valid: true},
+		{name: "no url", modify: func(n *domain.PrismNotice) { n.URL = "" }, valid: true},
+		{name: "unbounded", modify: func(n *domain.PrismNotice) {
+			n.ActiveFrom, n.ActiveUntil, n.DurationSeconds = nil, nil, nil
+			n.Targeting.MinPrismVersion, n.Targeting.MaxPrismVersion = "", ""
+		}, valid: true},
+		{name: "no message", modify: func(n *domain.PrismNotice) { n.Message = "" }},
+		{name: "relative url", modify: func(n *domain.PrismNotice) { n.URL = "/wrapped" }},
+		{name: "javascript url", modify: func(n *domain.PrismNotice) { n.URL = "javascript:alert(1)" }},
+		{name: "unknown severity", modify: func(n *domain.PrismNotice) { n.Severity = "loud" }},
+		{name: "unknown frequency", modify: func(n *domain.PrismNotice) { n.Frequency = "hourly" }},
+		{name: "zero duration", modify: func(n *domain.PrismNotice) { n.DurationSeconds = new(0.0) }},
+		{name: "empty window", modify: func(n *domain.PrismNotice) { n.ActiveUntil = n.ActiveFrom }},
+		{name: "bad min version", modify: func(n *domain.PrismNotice) { n.Targeting.MinPrismVersion = "1.2" }},
+		{name: "bad max version", modify: func(n *domain.PrismNotice) { n.Targeting.MaxPrismVersion = "latest" }},
+		{name: "empty version range", modify: func(n *domain.PrismNotice) { n.Targeting.MaxPrismVersion = "v1.12.0" }},
+		{name: "negative rollout", modify: func(n *domain.PrismNotice) { n.Targeting.RolloutPercentage = -1 }},
+		{name: "rollout above 100", modify: func(n *domain.PrismNotice) { n.Targeting.RolloutPercentage = 101 }},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			notice := valid()
+			tc.modify(&notice)
+
+			err := app.ValidatePrismNotice(notice)
+			if tc.valid {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, domain.ErrInvalidPrismNotice)
+		})
+	}
+}
+
+func TestPrismNoticeAdmin(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+	nowFunc := func() time.Time { return now }
+
+	newNotice := domain.PrismNotice{
+		ID:        "ignored",
+		Message:   "Maintenance tonight",
+		Severity:  domain.NoticeSeverityWarning,
+		Frequency: domain.NoticeFrequencyAlways,
+		Targeting: domain.NoticeTargeting{RolloutPercentage: 100},
+	}
+
+	t.Run("writes are visible through the notice cache right away", func(t *testing.T) {
+		t.Parallel()
+		ctx := t.Context()
+
+		repo := &fakePrismNoticeRepository{}
+		noticeCache := cache.NewBasicCache[[]domain.PrismNotice]()
+		getPrismNotices := app.BuildGetPrismNotices(app.NewPrismReleases(releaseprovider.NewFake()), noticeCache, repo, nowFunc)
+		create := app.BuildCreatePrismNotice(repo, noticeCache, nowFunc)
+		update := app.BuildUpdatePrismNotice(repo, noticeCache, nowFunc)
+		remove := app.BuildDeletePrismNotice(repo, noticeCache)
+
+		get := func() []app.PrismNotice {
+			return getPrismNotices(ctx, "user", "v1.12.0", "prism", app.UpdateSelectionNone)
+		}
+
+		require.Empty(t, get())
+
+		created, err := create(ctx, newNotice)
+		require.NoError(t, err)
+		require.NotEqual(t, "ignored", created.ID)
+		require.Equal(t, now, created.CreatedAt)
+		require.Equal(t, now, created.UpdatedAt)
+		require.Equal(t, []app.PrismNotice{{ID: created.ID, Message: "Maintenance tonight", Severity: domain.NoticeSeverityWarning}}, get())
+
+		created.Message = "Maintenance moved to tomorrow"
+		created.CreatedAt = time.Time{}
+		updated, err := update(ctx, created)
+		require.NoError(t, err)
+		require.Equal(t, now, updated.CreatedAt, "CreatedAt is kept from the stored notice")
+		require.Equal(t, []app.PrismNotice{{ID: created.ID, Message: "Maintenance moved to tomorrow", Severity: domain.NoticeSeverityWarning}}, get())
+
+		require.NoError(t, remove(ctx, created.ID))
+		require.Empty(t, get())
+	})
+
+	t.Run("invalid notices are not stored", func(t *testing.T) {
+		t.Parallel()
+		ctx := t.Context()
+
+		repo := &fakePrismNoticeRepository{}
+		noticeCache := cache.NewBasicCache[[]domain.PrismNotice]()
+
+		invalid := newNotice
+		invalid.Message = ""
+		_, err := app.BuildCreatePrismNotice(repo, noticeCache, nowFunc)(ctx, invalid)
+		require.ErrorIs(t, err, domain.ErrInvalidPrismNotice)
+		require.Empty(t, repo.notices)
+	})
+
+	t.Run("unknown ids", func(t *testing.T) {
+		t.Parallel()
+		ctx := t.Context()
+
+		repo := &fakePrismNoticeRepository{}
+		noticeCache := cache.NewBasicCache[[]domain.PrismNotice]()
+
+		unknown := newNotice
+		unknown.ID = "unknown"
+		_, err := app.BuildUpdatePrismNotice(repo, noticeCache, nowFunc)(ctx, unknown)
+		require.ErrorIs(t, err, domain.ErrPrismNoticeNotFound)
+
+		err = app.BuildDeletePrismNotice(repo, noticeCache)(ctx, "unknown")
+		require.ErrorIs(t, err, domain.ErrPrismNoticeNotFound)
+
+		_, err = app.BuildGetPrismNotice(repo)(ctx, "unknown")
+		require.ErrorIs(t, err, domain.ErrPrismNoticeNotFound)
+	})
+}
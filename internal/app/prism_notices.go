@@ -2,21 +2,27 @@ package app
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/binary"
 	"fmt"
 	"slices"
 	"time"
 
+	"github.com/Amund211/flashlight/internal/adapters/cache"
+	"github.com/Amund211/flashlight/internal/domain"
 	"github.com/Amund211/flashlight/internal/logging"
 	"github.com/Amund211/flashlight/internal/version"
 )
 
-type Severity string
+// Severity is domain.NoticeSeverity, kept under its old name for the
+// handler's wire conversion.
+type Severity = domain.NoticeSeverity
 
 const (
-	SeverityInfo     Severity = "info"
-	SeverityUpdate   Severity = "update"
-	SeverityWarning  Severity = "warning"
-	SeverityCritical Severity = "critical"
+	SeverityInfo     = domain.NoticeSeverityInfo
+	SeverityUpdate   = domain.NoticeSeverityUpdate
+	SeverityWarning  = domain.NoticeSeverityWarning
+	SeverityCritical = domain.NoticeSeverityCritical
 )
 
 type UpdateSelection int
@@ -28,6 +34,9 @@ const (
 )
 
 type PrismNotice struct {
+	// ID is the stored notice's id, for acknowledging it. Empty for the
+	// computed version update and wrapped notices.
+	ID              string
 	Message         string
 	URL             string
 	Severity        Severity
@@ -38,13 +47,19 @@ type GetPrismNotices = func(
 	ctx context.Context,
 	userID string,
 	prismVersion string,
+	clientType string,
 	updateSelection UpdateSelection,
 ) []PrismNotice
 
-// latestPrism is the most recent released prism version. Bump this when
-// cutting a new prism release; clients running an older version will be told
-// (via the prism-notices endpoint) that an update is available.
-var latestPrism = version.MustParse("v1.11.0")
+type getPrismNoticesRepository interface {
+	ListPrismNotices(ctx context.Context) ([]domain.PrismNotice, error)
+	GetPrismNoticeViews(ctx context.Context, userID string, noticeIDs []string) (map[string]domain.PrismNoticeView, error)
+	RecordPrismNoticesShown(ctx context.Context, userID string, noticeIDs []string, shownAt time.Time) error
+}
+
+// prismNoticesCacheKey is the single entry in the notice cache: every
+// request filters the same full list, so there is nothing to key on.
+const prismNoticesCacheKey = "all"
 
 // firstPrismVersionWithoutLocalChecker is the first prism release that does
 // not include the in-process GitHub update checker — those clients rely on
@@ -52,64 +67,224 @@ var latestPrism = version.MustParse("v1.11.0")
 // themselves, so flashlight must not duplicate the notice for them.
 var firstPrismVersionWithoutLocalChecker = version.MustParse("v1.12.0")
 
-const latestPrismReleaseURL = "https://github.com/Amund211/prism/releases/latest/"
-
-var unicodeReplacementCharacterUsers = []string{
-	"b1b6ead3b357467298c0a186a891940f",
-	"e104fb8b4b8a4a40ba70334e8239c0e1",
-	"b3c71ddfb808414d80e932110dae5716",
-	"9c90ae7b927347a787ddb9c9e85cca16",
-	"a3ec8094a2bb427f81c11faadb33c2ba",
-	"ea2aa5221a614dc1a502f01e33f4ceaa",
-	"47e7859bb33246ef8494fb81a9ac4e01",
-	"426d836cdc7740bd9ff887d1d8a358f3",
-
-	"3eedaf7ed5964d8981835b8f0de2c9d4",
-	"bb683d98dc634a5783be9a4895ab75af",
-	"a55dfa5ddaa7426b87f2a5dbc3ad5254",
+const prismWrappedURL = "https://prismoverlay.com/wrapped"
+
+// WrappedSeason returns the year whose wrapped is shown at now. A year's
+// wrapped is shown through its December and the January after it.
+func WrappedSeason(now time.Time) (int, bool) {
+	now = now.UTC()
+	switch now.Month() {
+	case time.December:
+		return now.Year(), true
+	case time.January:
+		return now.Year() - 1, true
+	default:
+		return 0, false
+	}
 }
 
-func BuildGetPrismNotices(nowFunc func() time.Time) GetPrismNotices {
-	return func(ctx context.Context, userID string, prismVersion string, updateSelection UpdateSelection) []PrismNotice {
+// wrappedNotices returns the notice for the ongoing wrapped season, if any
+func wrappedNotices(now time.Time) []PrismNotice {
+	year, ok := WrappedSeason(now)
+	if !ok {
+		return nil
+	}
+
+	duration := 60.0
+	return []PrismNotice{{
+		Message:         fmt.Sprintf("Click here to view your Prism Wrapped %d", year),
+		URL:             prismWrappedURL,
+		Severity:        SeverityInfo,
+		DurationSeconds: &duration,
+	}}
+}
+
+// BuildGetPrismNotices returns the version update notice, for the latest
+// release in releases, followed by the stored notices that target the
+// request, and the wrapped notice during the wrapped season. The stored
+// notices are read through noticeCache, so an edit can take up to its ttl to
+// show up on instances other than the one that made it. Notices with a
+// frequency cap are filtered on, and recorded as shown to, the user.
+func BuildGetPrismNotices(
+	releases *PrismReleases,
+	noticeCache cache.Cache[[]domain.PrismNotice],
+	repo getPrismNoticesRepository,
+	nowFunc func() time.Time,
+) GetPrismNotices {
+	return func(ctx context.Context, userID string, prismVersion string, clientType string, updateSelection UpdateSelection) []PrismNotice {
 		notices := []PrismNotice{}
 
 		now := nowFunc().UTC()
 
-		notices = append(notices, versionUpdateNotices(ctx, prismVersion, updateSelection)...)
+		notices = append(notices, versionUpdateNotices(ctx, releases, prismVersion, updateSelection)...)
 
-		if slices.Contains(unicodeReplacementCharacterUsers, userID) {
-			// These users sometimes include a unicode replacement character at the end of
-			// usernames sent to the account endpoint, causing issues.
-			// https://prism-overlay.sentry.io/issues/7078120764/?project=4506886744768512
-			duration := 120.0
-			logging.FromContext(ctx).InfoContext(ctx, "Adding critical notice for user with known unicode replacement character issue", "userID", userID)
-			notices = append(notices, PrismNotice{
-				Message:         "We've detected a potential issue with your Prism client.\nPlease click here to create a ticket in the discord server",
-				URL:             "https://discord.gg/NGpRrdh6Fx",
-				Severity:        SeverityWarning,
-				DurationSeconds: &duration,
-			})
+		stored, _, err := cache.GetOrCreate(ctx, noticeCache, prismNoticesCacheKey, func() ([]domain.PrismNotice, error) {
+			return repo.ListPrismNotices(ctx)
+		})
+		if err != nil {
+			// NOTE: The repository reports its own errors. Notices are best
+			// effort, so the request still gets the computed notices.
+			logging.FromContext(ctx).ErrorContext(ctx, "Failed to list prism notices", "error", err)
+			return append(notices, wrappedNotices(now)...)
 		}
 
-		if now.Month() == time.December || now.Month() == time.January {
-			duration := 60.0
-			year := now.Year()
-			if now.Month() == time.January {
-				year = year - 1
+		target := noticeTarget{userID: userID, prismVersion: prismVersion, clientType: clientType}
+		targeted := []domain.PrismNotice{}
+		cappedIDs := []string{}
+		for _, notice := range stored {
+			if !noticeIsActive(notice, now) || !noticeTargets(notice, target) {
+				continue
 			}
+			if noticeIsCapped(notice) {
+				if userID == "" {
+					// Can't keep count without a user id. Not showing the
+					// notice beats showing it on every request.
+					continue
+				}
+				cappedIDs = append(cappedIDs, notice.ID)
+			}
+			targeted = append(targeted, notice)
+		}
+
+		views := map[string]domain.PrismNoticeView{}
+		if len(cappedIDs) > 0 {
+			views, err = repo.GetPrismNoticeViews(ctx, userID, cappedIDs)
+			if err != nil {
+				// NOTE: The repository reports its own errors. Showing a
+				// notice again is better than hiding a warning.
+				logging.FromContext(ctx).ErrorContext(ctx, "Failed to get prism notice views", "error", err)
+				views = map[string]domain.PrismNoticeView{}
+			}
+		}
+
+		shownIDs := []string{}
+		for _, notice := range targeted {
+			if !noticeIsDue(notice, views[notice.ID], now) {
+				continue
+			}
+			if noticeIsCapped(notice) {
+				shownIDs = append(shownIDs, notice.ID)
+			}
+			logging.FromContext(ctx).InfoContext(ctx, "Adding stored prism notice", "noticeID", notice.ID)
 			notices = append(notices, PrismNotice{
-				Message:         fmt.Sprintf("Click here to view your Prism Wrapped %d", year),
-				URL:             "https://prismoverlay.com/wrapped",
-				Severity:        SeverityInfo,
-				DurationSeconds: &duration,
+				ID:              notice.ID,
+				Message:         notice.Message,
+				URL:             notice.URL,
+				Severity:        notice.Severity,
+				DurationSeconds: notice.DurationSeconds,
 			})
 		}
 
-		return notices
+		if len(shownIDs) > 0 {
+			err := repo.RecordPrismNoticesShown(ctx, userID, shownIDs, now)
+			if err != nil {
+				// NOTE: The repository reports its own errors. The notices
+				// will just be shown again on the next request.
+				logging.FromContext(ctx).ErrorContext(ctx, "Failed to record prism notices shown", "error", err)
+			}
+		}
+
+		return append(notices, wrappedNotices(now)...)
+	}
+}
+
+// noticeIsCapped is whether how often a notice is shown depends on what the
+// user has seen. Anything else is shown like NoticeFrequencyAlways.
+func noticeIsCapped(notice domain.PrismNotice) bool {
+	switch notice.Frequency {
+	case domain.NoticeFrequencyOnce, domain.NoticeFrequencyDaily, domain.NoticeFrequencyUntilAcknowledged:
+		return true
+	default:
+		return false
+	}
+}
+
+// noticeIsDue is whether a user who has seen view of the notice should be
+// shown it at now.
+func noticeIsDue(notice domain.PrismNotice, view domain.PrismNoticeView, now time.Time) bool {
+	if !noticeIsCapped(notice) {
+		return true
+	}
+	if view.AcknowledgedAt != nil {
+		return false
+	}
+
+	switch notice.Frequency {
+	case domain.NoticeFrequencyOnce:
+		return view.LastShownAt == nil
+	case domain.NoticeFrequencyDaily:
+		return view.LastShownAt == nil || now.Sub(*view.LastShownAt) >= 24*time.Hour
+	default:
+		return true
+	}
+}
+
+// noticeTarget is who a prism-notices request is from
+type noticeTarget struct {
+	userID       string
+	prismVersion string
+	clientType   string
+}
+
+func noticeIsActive(notice domain.PrismNotice, now time.Time) bool {
+	if notice.ActiveFrom != nil && now.Before(*notice.ActiveFrom) {
+		return false
+	}
+	if notice.ActiveUntil != nil && !now.Before(*notice.ActiveUntil) {
+		return false
+	}
+	return true
+}
+
+func noticeTargets(notice domain.PrismNotice, target noticeTarget) bool {
+	targeting := notice.Targeting
+
+	if len(targeting.UserIDs) > 0 && !slices.Contains(targeting.UserIDs, target.userID) {
+		return false
+	}
+
+	if len(targeting.ClientTypes) > 0 && !slices.Contains(targeting.ClientTypes, target.clientType) {
+		return false
+	}
+
+	if targeting.MinPrismVersion != "" || targeting.MaxPrismVersion != "" {
+		current, err := version.Parse(target.prismVersion)
+		if err != nil {
+			return false
+		}
+		if targeting.MinPrismVersion != "" {
+			// Validated on save, see ValidatePrismNotice
+			minimum, err := version.Parse(targeting.MinPrismVersion)
+			if err != nil || !current.IsAtLeast(minimum) {
+				return false
+			}
+		}
+		if targeting.MaxPrismVersion != "" {
+			maximum, err := version.Parse(targeting.MaxPrismVersion)
+			if err != nil || current.IsAtLeast(maximum) {
+				return false
+			}
+		}
+	}
+
+	if targeting.RolloutPercentage < 100 {
+		if target.userID == "" || rolloutBucket(notice.ID, target.userID) >= targeting.RolloutPercentage {
+			return false
+		}
 	}
+
+	return true
+}
+
+// rolloutBucket places a user in one of 100 buckets for a notice. The
+// notice id is part of the hash so the same users aren't first in line for
+// every rollout.
+func rolloutBucket(noticeID string, userID string) int {
+	sum := sha256.Sum256([]byte(noticeID + ":" + userID))
+	return int(binary.BigEndian.Uint64(sum[:8]) % 100)
 }
 
-func versionUpdateNotices(ctx context.Context, prismVersion string, updateSelection UpdateSelection) []PrismNotice {
+func versionUpdateNotices(ctx context.Context, releases *PrismReleases, prismVersion string, updateSelection UpdateSelection) []PrismNotice {
 	if updateSelection == UpdateSelectionNone {
 		return nil
 	}
@@ -126,15 +301,17 @@ func versionUpdateNotices(ctx context.Context, prismVersion string, updateSelect
 		return nil
 	}
 
-	if !current.UpdateAvailable(latestPrism, !includePatchUpdates) {
+	// Read once so the check and the notice are for the same release
+	state := releases.state.Load()
+	if !current.UpdateAvailable(state.latestVersion, !includePatchUpdates) {
 		return nil
 	}
 
-	logging.FromContext(ctx).InfoContext(ctx, "Adding prism update notice", "prismVersion", prismVersion, "latest", latestPrism)
+	logging.FromContext(ctx).InfoContext(ctx, "Adding prism update notice", "prismVersion", prismVersion, "latest", state.latest.Version)
 	duration := 60.0
 	return []PrismNotice{{
-		Message:         "New update available! Click here to download.",
-		URL:             latestPrismReleaseURL,
+		Message:         fmt.Sprintf("New update available: %s! Click here to see what's new and download.", state.latest.Name),
+		URL:             state.latest.URL,
 		Severity:        SeverityUpdate,
 		DurationSeconds: &duration,
 	}}
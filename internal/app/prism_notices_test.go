@@ -1,24 +1,214 @@
 package app_test
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/Amund211/flashlight/internal/adapters/cache"
+	"github.com/Amund211/flashlight/internal/adapters/releaseprovider"
 	"github.com/Amund211/flashlight/internal/app"
+	"github.com/Amund211/flashlight/internal/domain"
 	"github.com/Amund211/flashlight/internal/domaintest"
 )
 
+// fakePrismNoticeRepository is an in-memory prism notice store
+type fakePrismNoticeRepository struct {
+	mu        sync.Mutex
+	notices   []domain.PrismNotice
+	listCalls int
+	err       error
+	// views is keyed by user id and then notice id
+	views    map[string]map[string]domain.PrismNoticeView
+	viewsErr error
+}
+
+func (r *fakePrismNoticeRepository) GetPrismNoticeViews(ctx context.Context, userID string, noticeIDs []string) (map[string]domain.PrismNoticeView, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.viewsErr != nil {
+		return nil, r.viewsErr
+	}
+	views := map[string]domain.PrismNoticeView{}
+	for _, id := range noticeIDs {
+		if view, ok := r.views[userID][id]; ok {
+			views[id] = view
+		}
+	}
+	return views, nil
+}
+
+func (r *fakePrismNoticeRepository) updateView(userID string, noticeID string, update func(*domain.PrismNoticeView)) {
+	if r.views == nil {
+		r.views = map[string]map[string]domain.PrismNoticeView{}
+	}
+	if r.views[userID] == nil {
+		r.views[userID] = map[string]domain.PrismNoticeView{}
+	}
+	view := r.views[userID][noticeID]
+	update(&view)
+	r.views[userID][noticeID] = view
+}
+
+func (r *fakePrismNoticeRepository) RecordPrismNoticesShown(ctx context.Context, userID string, noticeIDs []string, shownAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.viewsErr != nil {
+		return r.viewsErr
+	}
+	for _, id := range noticeIDs {
+		r.updateView(userID, id, func(view *domain.PrismNoticeView) { view.LastShownAt = &shownAt })
+	}
+	return nil
+}
+
+func (r *fakePrismNoticeRepository) AcknowledgePrismNotice(ctx context.Context, userID string, noticeID string, acknowledgedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !slices.ContainsFunc(r.notices, func(notice domain.PrismNotice) bool { return notice.ID == noticeID }) {
+		return domain.ErrPrismNoticeNotFound
+	}
+	r.updateView(userID, noticeID, func(view *domain.PrismNoticeView) {
+		if view.AcknowledgedAt == nil {
+			view.AcknowledgedAt = &acknowledgedAt
+		}
+	})
+	return nil
+}
+
+func (r *fakePrismNoticeRepository) ListPrismNotices(ctx context.Context) ([]domain.PrismNotice, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.listCalls++
+	if r.err != nil {
+		return nil, r.err
+	}
+	return slices.Clone(r.notices), nil
+}
+
+func (r *fakePrismNoticeRepository) GetPrismNotice(ctx context.Context, id string) (domain.PrismNotice, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, notice := range r.notices {
+		if notice.ID == id {
+			return notice, nil
+		}
+	}
+	return domain.PrismNotice{}, domain.ErrPrismNoticeNotFound
+}
+
+func (r *fakePrismNoticeRepository) CreatePrismNotice(ctx context.Context, notice domain.PrismNotice) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.notices = append(r.notices, notice)
+	return nil
+}
+
+func (r *fakePrismNoticeRepository) UpdatePrismNotice(ctx context.Context, notice domain.PrismNotice) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.notices {
+		if r.notices[i].ID == notice.ID {
+			r.notices[i] = notice
+			return nil
+		}
+	}
+	return domain.ErrPrismNoticeNotFound
+}
+
+func (r *fakePrismNoticeRepository) DeletePrismNotice(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.notices {
+		if r.notices[i].ID == id {
+			r.notices = slices.Delete(r.notices, i, i+1)
+			return nil
+		}
+	}
+	return domain.ErrPrismNoticeNotFound
+}
+
 func TestBuildGetPrismNotices(t *testing.T) {
 	t.Parallel()
 
 	defaultTime := time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC)
 
+	// The notice migration 10 seeds, in place of the one that used to be
+	// hardcoded
+	unicodeUserID := "b1b6ead3b357467298c0a186a891940f"
+	unicodeNotice := domain.PrismNotice{
+		ID:              "b6c0f5a4-4f7e-4d1c-9a51-0c6f2d8e7a10",
+		Message:         "We've detected a potential issue with your Prism client.\nPlease click here to create a ticket in the discord server",
+		URL:             "https://discord.gg/NGpRrdh6Fx",
+		Severity:        domain.NoticeSeverityWarning,
+		DurationSeconds: new(120.0),
+		Frequency:       domain.NoticeFrequencyAlways,
+		Targeting: domain.NoticeTargeting{
+			UserIDs:           []string{unicodeUserID, "e104fb8b4b8a4a40ba70334e8239c0e1"},
+			RolloutPercentage: 100,
+		},
+	}
+	wrappedNotice := func(year int) app.PrismNotice {
+		return app.PrismNotice{
+			Message:         fmt.Sprintf("Click here to view your Prism Wrapped %d", year),
+			URL:             "https://prismoverlay.com/wrapped",
+			Severity:        app.SeverityInfo,
+			DurationSeconds: new(60.0),
+		}
+	}
+	versionedNotice := domain.PrismNotice{
+		ID:        "versioned",
+		Message:   "Your version has a known bug",
+		Severity:  domain.NoticeSeverityCritical,
+		Frequency: domain.NoticeFrequencyAlways,
+		Targeting: domain.NoticeTargeting{
+			MinPrismVersion:   "v1.12.0",
+			MaxPrismVersion:   "v1.12.2",
+			ClientTypes:       []string{"prism"},
+			RolloutPercentage: 100,
+		},
+	}
+	stored := []domain.PrismNotice{unicodeNotice, versionedNotice}
+
+	latestRelease := domain.PrismRelease{
+		Version: "v1.12.1",
+		Name:    "Prism v1.12.1",
+		URL:     "https://github.com/Amund211/prism/releases/tag/v1.12.1",
+	}
+	releases := app.NewPrismReleases(releaseprovider.NewFake(
+		domain.PrismRelease{Version: "v1.13.0-rc1", Name: "Prism v1.13.0 RC 1", Prerelease: true},
+		latestRelease,
+		domain.PrismRelease{Version: "v1.12.0", Name: "Prism v1.12.0"},
+	))
+	require.NoError(t, releases.Refresh(t.Context()))
+	updateNotice := app.PrismNotice{
+		Message:         "New update available: Prism v1.12.1! Click here to see what's new and download.",
+		URL:             latestRelease.URL,
+		Severity:        app.SeverityUpdate,
+		DurationSeconds: new(60.0),
+	}
+
+	toAppNotice := func(notice domain.PrismNotice) app.PrismNotice {
+		return app.PrismNotice{
+			ID:              notice.ID,
+			Message:         notice.Message,
+			URL:             notice.URL,
+			Severity:        notice.Severity,
+			DurationSeconds: notice.DurationSeconds,
+		}
+	}
+
 	cases := []struct {
 		name            string
 		userID          string
 		prismVersion    string
+		clientType      string
 		updateSelection app.UpdateSelection
 		now             time.Time
 		want            []app.PrismNotice
@@ -35,6 +225,7 @@ func TestBuildGetPrismNotices(t *testing.T) {
 			name:            "v1.10.1-dev outside wrapped window",
 			userID:          domaintest.NewUUID(t),
 			prismVersion:    "v1.10.1-dev",
+			clientType:      "prism",
 			updateSelection: app.UpdateSelectionNone,
 			now:             defaultTime,
 			want:            []app.PrismNotice{},
@@ -43,69 +234,73 @@ func TestBuildGetPrismNotices(t *testing.T) {
 			name:            "wrapped notice surfaced at start of December",
 			userID:          domaintest.NewUUID(t),
 			prismVersion:    "v1.11.0",
+			clientType:      "prism",
 			updateSelection: app.UpdateSelectionNone,
 			now:             time.Date(2025, time.December, 1, 0, 0, 0, 0, time.UTC),
-			want: []app.PrismNotice{
-				{
-					Message:         "Click here to view your Prism Wrapped 2025",
-					URL:             "https://prismoverlay.com/wrapped",
-					Severity:        app.SeverityInfo,
-					DurationSeconds: new(60.0),
-				},
-			},
+			want:            []app.PrismNotice{wrappedNotice(2025)},
 		},
 		{
-			name:            "wrapped notice surfaced on Christmas Eve",
-			userID:          domaintest.NewUUID(t),
-			prismVersion:    "v1.11.0",
-			updateSelection: app.UpdateSelectionNone,
-			now:             time.Date(2025, time.December, 24, 0, 0, 0, 0, time.UTC),
-			want: []app.PrismNotice{
-				{
-					Message:         "Click here to view your Prism Wrapped 2025",
-					URL:             "https://prismoverlay.com/wrapped",
-					Severity:        app.SeverityInfo,
-					DurationSeconds: new(60.0),
-				},
-			},
-		},
-		{
-			name:            "wrapped notice surfaced on last day of January reports prior year",
+			name:            "wrapped notice surfaced on last day of January",
 			userID:          domaintest.NewUUID(t),
 			prismVersion:    "v1.11.0",
+			clientType:      "prism",
 			updateSelection: app.UpdateSelectionNone,
 			now:             time.Date(2026, time.January, 31, 23, 59, 59, 0, time.UTC),
-			want: []app.PrismNotice{
-				{
-					Message:         "Click here to view your Prism Wrapped 2025",
-					URL:             "https://prismoverlay.com/wrapped",
-					Severity:        app.SeverityInfo,
-					DurationSeconds: new(60.0),
-				},
-			},
+			want:            []app.PrismNotice{wrappedNotice(2025)},
 		},
 		{
 			name:            "wrapped window closed in February",
 			userID:          domaintest.NewUUID(t),
 			prismVersion:    "v1.11.0",
+			clientType:      "prism",
 			updateSelection: app.UpdateSelectionNone,
 			now:             time.Date(2026, time.February, 1, 0, 0, 0, 0, time.UTC),
 			want:            []app.PrismNotice{},
 		},
+		{
+			name:            "wrapped notice follows the stored notices",
+			userID:          unicodeUserID,
+			prismVersion:    "v1.11.0",
+			clientType:      "prism",
+			updateSelection: app.UpdateSelectionNone,
+			now:             time.Date(2027, time.December, 24, 0, 0, 0, 0, time.UTC),
+			want:            []app.PrismNotice{toAppNotice(unicodeNotice), wrappedNotice(2027)},
+		},
 		{
 			name:            "known unicode replacement character user gets warning notice",
-			userID:          "b1b6ead3b357467298c0a186a891940f",
+			userID:          unicodeUserID,
 			prismVersion:    "v1.11.0",
+			clientType:      "prism",
+			updateSelection: app.UpdateSelectionNone,
+			now:             defaultTime,
+			want:            []app.PrismNotice{toAppNotice(unicodeNotice)},
+		},
+		{
+			name:            "version range includes the minimum",
+			userID:          domaintest.NewUUID(t),
+			prismVersion:    "v1.12.0",
+			clientType:      "prism",
 			updateSelection: app.UpdateSelectionNone,
 			now:             defaultTime,
-			want: []app.PrismNotice{
-				{
-					Message:         "We've detected a potential issue with your Prism client.\nPlease click here to create a ticket in the discord server",
-					URL:             "https://discord.gg/NGpRrdh6Fx",
-					Severity:        app.SeverityWarning,
-					DurationSeconds: new(120.0),
-				},
-			},
+			want:            []app.PrismNotice{toAppNotice(versionedNotice)},
+		},
+		{
+			name:            "version range excludes the maximum",
+			userID:          domaintest.NewUUID(t),
+			prismVersion:    "v1.12.2",
+			clientType:      "prism",
+			updateSelection: app.UpdateSelectionNone,
+			now:             defaultTime,
+			want:            []app.PrismNotice{},
+		},
+		{
+			name:            "version range with an untargeted client type",
+			userID:          domaintest.NewUUID(t),
+			prismVersion:    "v1.12.1",
+			clientType:      "rainbow",
+			updateSelection: app.UpdateSelectionNone,
+			now:             defaultTime,
+			want:            []app.PrismNotice{},
 		},
 
 		// Version-update-focused cases.
@@ -114,6 +309,44 @@ func TestBuildGetPrismNotices(t *testing.T) {
 			name:            "v1.11.0 still has local checker",
 			userID:          domaintest.NewUUID(t),
 			prismVersion:    "v1.11.0",
+			clientType:      "prism",
+			updateSelection: app.UpdateSelectionAll,
+			now:             defaultTime,
+			want:            []app.PrismNotice{},
+		},
+		{
+			name:            "selection=all with a patch update",
+			userID:          domaintest.NewUUID(t),
+			prismVersion:    "v1.12.0",
+			clientType:      "prism",
+			updateSelection: app.UpdateSelectionAll,
+			now:             defaultTime,
+			want:            []app.PrismNotice{updateNotice, toAppNotice(versionedNotice)},
+		},
+		{
+			name:            "selection=minor ignores a patch update",
+			userID:          domaintest.NewUUID(t),
+			prismVersion:    "v1.12.0",
+			clientType:      "prism",
+			updateSelection: app.UpdateSelectionMinor,
+			now:             defaultTime,
+			want:            []app.PrismNotice{toAppNotice(versionedNotice)},
+		},
+		{
+			name:            "dev build after the latest release",
+			userID:          domaintest.NewUUID(t),
+			prismVersion:    "v1.12.2-dev",
+			clientType:      "prism",
+			updateSelection: app.UpdateSelectionAll,
+			now:             defaultTime,
+			want:            []app.PrismNotice{},
+		},
+		{
+			// Prereleases are never offered as an update
+			name:            "latest stable release",
+			userID:          domaintest.NewUUID(t),
+			prismVersion:    "v1.12.2",
+			clientType:      "prism",
 			updateSelection: app.UpdateSelectionAll,
 			now:             defaultTime,
 			want:            []app.PrismNotice{},
@@ -149,11 +382,188 @@ func TestBuildGetPrismNotices(t *testing.T) {
 			t.Parallel()
 
 			nowFunc := func() time.Time { return tc.now }
-			getPrismNotices := app.BuildGetPrismNotices(nowFunc)
+			repo := &fakePrismNoticeRepository{notices: stored}
+			getPrismNotices := app.BuildGetPrismNotices(releases, cache.NewBasicCache[[]domain.PrismNotice](), repo, nowFunc)
 
-			got := getPrismNotices(t.Context(), tc.userID, tc.prismVersion, tc.updateSelection)
+			got := getPrismNotices(t.Context(), tc.userID, tc.prismVersion, tc.clientType, tc.updateSelection)
 
 			require.Equal(t, tc.want, got)
 		})
 	}
+
+	t.Run("stored notices are cached", func(t *testing.T) {
+		t.Parallel()
+
+		repo := &fakePrismNoticeRepository{notices: stored}
+		getPrismNotices := app.BuildGetPrismNotices(app.NewPrismReleases(releaseprovider.NewFake()), cache.NewBasicCache[[]domain.PrismNotice](), repo, func() time.Time { return defaultTime })
+
+		for range 3 {
+			got := getPrismNotices(t.Context(), unicodeUserID, "v1.11.0", "prism", app.UpdateSelectionNone)
+			require.Equal(t, []app.PrismNotice{toAppNotice(unicodeNotice)}, got)
+		}
+		require.Equal(t, 1, repo.listCalls)
+	})
+
+	t.Run("repository errors are not fatal", func(t *testing.T) {
+		t.Parallel()
+
+		repo := &fakePrismNoticeRepository{notices: stored, err: errors.New("db down")}
+		getPrismNotices := app.BuildGetPrismNotices(app.NewPrismReleases(releaseprovider.NewFake()), cache.NewBasicCache[[]domain.PrismNotice](), repo, func() time.Time { return defaultTime })
+
+		got := getPrismNotices(t.Context(), unicodeUserID, "v1.11.0", "prism", app.UpdateSelectionNone)
+		require.Equal(t, []app.PrismNotice{}, got)
+
+		// The computed notices are still shown
+		getPrismNotices = app.BuildGetPrismNotices(app.NewPrismReleases(releaseprovider.NewFake()), cache.NewBasicCache[[]domain.PrismNotice](), repo, func() time.Time {
+			return time.Date(2026, time.December, 1, 0, 0, 0, 0, time.UTC)
+		})
+		got = getPrismNotices(t.Context(), unicodeUserID, "v1.11.0", "prism", app.UpdateSelectionNone)
+		require.Equal(t, []app.PrismNotice{wrappedNotice(2026)}, got)
+	})
+
+	t.Run("percentage rollout", func(t *testing.T) {
+		t.Parallel()
+
+		rollout := func(percentage int) domain.PrismNotice {
+			return domain.PrismNotice{
+				ID:        "rollout",
+				Message:   "Try the new feature",
+				Severity:  domain.NoticeSeverityInfo,
+				Frequency: domain.NoticeFrequencyAlways,
+				Targeting: domain.NoticeTargeting{RolloutPercentage: percentage},
+			}
+		}
+
+		const users = 1000
+		reached := func(percentage int) map[string]bool {
+			repo := &fakePrismNoticeRepository{notices: []domain.PrismNotice{rollout(percentage)}}
+			getPrismNotices := app.BuildGetPrismNotices(app.NewPrismReleases(releaseprovider.NewFake()), cache.NewBasicCache[[]domain.PrismNotice](), repo, func() time.Time { return defaultTime })
+
+			result := map[string]bool{}
+			for i := range users {
+				userID := fmt.Sprintf("user-%d", i)
+				if len(getPrismNotices(t.Context(), userID, "v1.12.0", "prism", app.UpdateSelectionNone)) > 0 {
+					result[userID] = true
+				}
+			}
+			return result
+		}
+
+		require.Empty(t, reached(0))
+		require.Len(t, reached(100), users)
+
+		tenPercent := reached(10)
+		require.InDelta(t, users/10, len(tenPercent), users/20)
+
+		// Growing the rollout keeps everyone who already had it
+		fiftyPercent := reached(50)
+		require.InDelta(t, users/2, len(fiftyPercent), users/10)
+		for userID := range tenPercent {
+			require.True(t, fiftyPercent[userID], "user %s dropped out of the rollout", userID)
+		}
+
+		repo := &fakePrismNoticeRepository{notices: []domain.PrismNotice{rollout(50)}}
+		getPrismNotices := app.BuildGetPrismNotices(app.NewPrismReleases(releaseprovider.NewFake()), cache.NewBasicCache[[]domain.PrismNotice](), repo, func() time.Time { return defaultTime })
+		require.Empty(t, getPrismNotices(t.Context(), "", "v1.12.0", "prism", app.UpdateSelectionNone),
+			"a partial rollout needs a user id to bucket on")
+	})
+
+	t.Run("frequency", func(t *testing.T) {
+		t.Parallel()
+
+		capped := func(frequency domain.NoticeFrequency) domain.PrismNotice {
+			return domain.PrismNotice{
+				ID:        string(frequency),
+				Message:   "Notice shown " + string(frequency),
+				Severity:  domain.NoticeSeverityInfo,
+				Frequency: frequency,
+				Targeting: domain.NoticeTargeting{RolloutPercentage: 100},
+			}
+		}
+		allFrequencies := []domain.PrismNotice{
+			capped(domain.NoticeFrequencyAlways),
+			capped(domain.NoticeFrequencyOnce),
+			capped(domain.NoticeFrequencyDaily),
+			capped(domain.NoticeFrequencyUntilAcknowledged),
+		}
+
+		shownIDs := func(notices []app.PrismNotice) []string {
+			ids := []string{}
+			for _, notice := range notices {
+				ids = append(ids, notice.ID)
+			}
+			return ids
+		}
+
+		t.Run("repeat requests", func(t *testing.T) {
+			t.Parallel()
+			ctx := t.Context()
+
+			now := defaultTime
+			repo := &fakePrismNoticeRepository{notices: allFrequencies}
+			getPrismNotices := app.BuildGetPrismNotices(app.NewPrismReleases(releaseprovider.NewFake()), cache.NewBasicCache[[]domain.PrismNotice](), repo, func() time.Time { return now })
+			acknowledge := app.BuildAcknowledgePrismNotice(repo, func() time.Time { return now })
+			get := func() []string {
+				return shownIDs(getPrismNotices(ctx, "user", "v1.12.0", "prism", app.UpdateSelectionNone))
+			}
+
+			require.Equal(t, []string{"always", "once", "daily", "until_acknowledged"}, get())
+
+			now = defaultTime.Add(time.Hour)
+			require.Equal(t, []string{"always", "until_acknowledged"}, get())
+
+			now = defaultTime.Add(24 * time.Hour)
+			require.Equal(t, []string{"always", "daily", "until_acknowledged"}, get())
+
+			for _, notice := range allFrequencies {
+				require.NoError(t, acknowledge(ctx, "user", notice.ID))
+			}
+
+			now = defaultTime.Add(48 * time.Hour)
+			require.Equal(t, []string{"always"}, get(), "only always survives an acknowledgement")
+
+			require.Equal(t, []string{"always", "once", "daily", "until_acknowledged"},
+				shownIDs(getPrismNotices(ctx, "other-user", "v1.12.0", "prism", app.UpdateSelectionNone)),
+				"views are per user")
+		})
+
+		t.Run("capped notices need a user id", func(t *testing.T) {
+			t.Parallel()
+
+			repo := &fakePrismNoticeRepository{notices: allFrequencies}
+			getPrismNotices := app.BuildGetPrismNotices(app.NewPrismReleases(releaseprovider.NewFake()), cache.NewBasicCache[[]domain.PrismNotice](), repo, func() time.Time { return defaultTime })
+
+			require.Equal(t, []string{"always"}, shownIDs(getPrismNotices(t.Context(), "", "v1.12.0", "prism", app.UpdateSelectionNone)))
+		})
+
+		t.Run("view errors show the notices", func(t *testing.T) {
+			t.Parallel()
+
+			repo := &fakePrismNoticeRepository{notices: allFrequencies, viewsErr: errors.New("db down")}
+			getPrismNotices := app.BuildGetPrismNotices(app.NewPrismReleases(releaseprovider.NewFake()), cache.NewBasicCache[[]domain.PrismNotice](), repo, func() time.Time { return defaultTime })
+
+			for range 2 {
+				require.Equal(t, []string{"always", "once", "daily", "until_acknowledged"},
+					shownIDs(getPrismNotices(t.Context(), "user", "v1.12.0", "prism", app.UpdateSelectionNone)))
+			}
+		})
+	})
+}
+
+func TestAcknowledgePrismNotice(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+	repo := &fakePrismNoticeRepository{notices: []domain.PrismNotice{{ID: "notice"}}}
+	acknowledge := app.BuildAcknowledgePrismNotice(repo, func() time.Time { return now })
+
+	require.NoError(t, acknowledge(t.Context(), "user", "notice"))
+	require.Equal(t, now, *repo.views["user"]["notice"].AcknowledgedAt)
+
+	require.NoError(t, acknowledge(t.Context(), "user", "notice"), "acknowledging twice is fine")
+
+	err := acknowledge(t.Context(), "user", "unknown")
+	require.ErrorIs(t, err, domain.ErrPrismNoticeNotFound)
+
+	require.Error(t, acknowledge(t.Context(), "", "notice"))
 }
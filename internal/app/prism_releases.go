@@ -0,0 +1,190 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Amund211/flashlight/internal/domain"
+	"github.com/Amund211/flashlight/internal/logging"
+	"github.com/Amund211/flashlight/internal/version"
+)
+
+// knownPrismReleaseCount is how many of the newest releases clients are
+// accepted from. Keep it SHORT to bound the client_version metric
+// cardinality.
+const knownPrismReleaseCount = 5
+
+type prismReleaseProvider interface {
+	ListPrismReleases(ctx context.Context) ([]domain.PrismRelease, error)
+}
+
+// fallbackPrismRelease is used as the latest release until the feed has
+// been read
+var fallbackPrismRelease = domain.PrismRelease{
+	Version: "v1.11.0",
+	Name:    "v1.11.0",
+	URL:     "https://github.com/Amund211/prism/releases/latest/",
+}
+
+// fallbackKnownPrismVersions are accepted along with the fallback release
+// until the feed has been read, so current clients aren't labelled unknown
+// for as long as it is down
+var fallbackKnownPrismVersions = []string{"v1.12.0", "v1.12.1-dev"}
+
+// PrismReleases is the latest stable prism release and the client versions
+// in use, as discovered from a release feed. Safe for concurrent use.
+type PrismReleases struct {
+	provider prismReleaseProvider
+	state    atomic.Pointer[prismReleaseState]
+}
+
+type prismReleaseState struct {
+	latest        domain.PrismRelease
+	latestVersion version.Version
+	knownVersions map[string]struct{}
+}
+
+// NewPrismReleases returns the releases in provider's feed. Until the first
+// Refresh succeeds the latest release is the one that was current when
+// flashlight stopped hardcoding it.
+func NewPrismReleases(provider prismReleaseProvider) *PrismReleases {
+	state, err := prismReleaseStateFrom([]domain.PrismRelease{fallbackPrismRelease})
+	if err != nil {
+		panic(fmt.Sprintf("invalid fallback prism release: %v", err))
+	}
+	for _, version := range fallbackKnownPrismVersions {
+		state.knownVersions[version] = struct{}{}
+	}
+
+	releases := &PrismReleases{
+		provider: provider,
+	}
+	releases.state.Store(state)
+	return releases
+}
+
+// Latest returns the newest stable release
+func (p *PrismReleases) Latest() domain.PrismRelease {
+	return p.state.Load().latest
+}
+
+// IsKnownVersion is whether a prism client may report version: one of the
+// newest releases, or the dev build that follows one of them. Safe to call
+// on every request.
+func (p *PrismReleases) IsKnownVersion(version string) bool {
+	_, ok := p.state.Load().knownVersions[version]
+	return ok
+}
+
+// Refresh reads the feed. On error the previous releases are kept.
+func (p *PrismReleases) Refresh(ctx context.Context) error {
+	releases, err := p.provider.ListPrismReleases(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list prism releases: %w", err)
+	}
+
+	state, err := prismReleaseStateFrom(releases)
+	if err != nil {
+		return fmt.Errorf("failed to read prism releases: %w", err)
+	}
+
+	previous := p.state.Swap(state)
+	if previous.latest.Version != state.latest.Version {
+		logging.FromContext(ctx).InfoContext(ctx, "Discovered new latest prism release",
+			"previous", previous.latest.Version,
+			"latest", state.latest.Version,
+		)
+	}
+
+	return nil
+}
+
+// StartRefreshing refreshes right away and then every interval, until the
+// returned stop func is called. ctx carries the logger for failed refreshes.
+func (p *PrismReleases) StartRefreshing(ctx context.Context, interval time.Duration) func() {
+	ctx, cancel := context.WithCancel(ctx)
+
+	var wg sync.WaitGroup
+	wg.Go(func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			if err := p.Refresh(ctx); err != nil && ctx.Err() == nil {
+				// NOTE: The provider reports its own errors. The last known
+				// releases are kept until the next refresh.
+				logging.FromContext(ctx).WarnContext(ctx, "Failed to refresh prism releases", "error", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	})
+
+	return func() {
+		cancel()
+		wg.Wait()
+	}
+}
+
+func prismReleaseStateFrom(releases []domain.PrismRelease) (*prismReleaseState, error) {
+	type parsedRelease struct {
+		release domain.PrismRelease
+		version version.Version
+	}
+
+	parsed := make([]parsedRelease, 0, len(releases))
+	for _, release := range releases {
+		v, err := version.Parse(release.Version)
+		if err != nil {
+			// Not every tag has to be a prism version
+			continue
+		}
+		parsed = append(parsed, parsedRelease{release: release, version: v})
+	}
+
+	// Newest first
+	slices.SortStableFunc(parsed, func(a, b parsedRelease) int {
+		switch {
+		case !a.version.IsAtLeast(b.version):
+			return 1
+		case !b.version.IsAtLeast(a.version):
+			return -1
+		default:
+			return 0
+		}
+	})
+
+	latestIndex := slices.IndexFunc(parsed, func(r parsedRelease) bool {
+		return !r.release.Prerelease && !r.version.Dev
+	})
+	if latestIndex == -1 {
+		return nil, fmt.Errorf("no stable release among %d releases", len(releases))
+	}
+
+	knownVersions := map[string]struct{}{}
+	for _, r := range parsed[:min(len(parsed), knownPrismReleaseCount)] {
+		knownVersions[r.release.Version] = struct{}{}
+		// Dev builds report the version after the release they are built on
+		next := fmt.Sprintf("v%d.%d.%d-dev", r.version.Major, r.version.Minor, r.version.Patch+1)
+		knownVersions[next] = struct{}{}
+	}
+
+	latest := parsed[latestIndex]
+	if latest.release.Name == "" {
+		latest.release.Name = latest.release.Version
+	}
+
+	return &prismReleaseState{
+		latest:        latest.release,
+		latestVersion: latest.version,
+		knownVersions: knownVersions,
+	}, nil
+}
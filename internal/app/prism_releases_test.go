@@ -0,0 +1,126 @@
+package app_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Amund211/flashlight/internal/adapters/releaseprovider"
+	"github.com/Amund211/flashlight/internal/app"
+	"github.com/Amund211/flashlight/internal/domain"
+)
+
+func TestPrismReleases(t *testing.T) {
+	t.Parallel()
+
+	release := func(version string, prerelease bool) domain.PrismRelease {
+		return domain.PrismRelease{
+			Version:    version,
+			Name:       "Prism " + version,
+			URL:        "https://github.com/Amund211/prism/releases/tag/" + version,
+			Prerelease: prerelease,
+		}
+	}
+
+	t.Run("fallback until the feed has been read", func(t *testing.T) {
+		t.Parallel()
+
+		releases := app.NewPrismReleases(releaseprovider.NewFake(release("v1.13.0", false)))
+
+		require.Equal(t, "v1.11.0", releases.Latest().Version)
+		require.True(t, releases.IsKnownVersion("v1.11.0"))
+		require.True(t, releases.IsKnownVersion("v1.11.1-dev"))
+		require.True(t, releases.IsKnownVersion("v1.12.0"), "current clients are known before the feed is read")
+		require.False(t, releases.IsKnownVersion("v1.13.0"))
+	})
+
+	t.Run("refresh", func(t *testing.T) {
+		t.Parallel()
+
+		provider := releaseprovider.NewFake(
+			release("v1.13.0-rc1", true),
+			release("v1.12.1", false),
+			release("nightly", false),
+			release("v1.12.0", false),
+		)
+		releases := app.NewPrismReleases(provider)
+		require.NoError(t, releases.Refresh(t.Context()))
+
+		require.Equal(t, release("v1.12.1", false), releases.Latest(), "prereleases are not stable")
+
+		for _, version := range []string{"v1.13.0-rc1", "v1.13.1-dev", "v1.12.1", "v1.12.2-dev", "v1.12.0", "v1.12.1-dev"} {
+			require.True(t, releases.IsKnownVersion(version), version)
+		}
+		for _, version := range []string{"v1.11.0", "v1.12.3-dev", "nightly", ""} {
+			require.False(t, releases.IsKnownVersion(version), version)
+		}
+
+		t.Run("a published release replaces the latest", func(t *testing.T) {
+			provider.SetReleases(release("v1.13.0", false), release("v1.12.1", false))
+			require.NoError(t, releases.Refresh(t.Context()))
+
+			require.Equal(t, "v1.13.0", releases.Latest().Version)
+			require.True(t, releases.IsKnownVersion("v1.13.1-dev"))
+			require.False(t, releases.IsKnownVersion("v1.13.0-rc1"), "versions drop out with the feed")
+		})
+
+		t.Run("errors keep the previous releases", func(t *testing.T) {
+			provider.SetError(errors.New("github down"))
+			require.Error(t, releases.Refresh(t.Context()))
+			require.Equal(t, "v1.13.0", releases.Latest().Version)
+
+			provider.SetError(nil)
+			provider.SetReleases(release("v1.14.0-rc1", true))
+			require.Error(t, releases.Refresh(t.Context()), "a feed without a stable release is rejected")
+			require.Equal(t, "v1.13.0", releases.Latest().Version)
+			require.False(t, releases.IsKnownVersion("v1.14.0-rc1"))
+		})
+	})
+
+	t.Run("only the newest releases are known", func(t *testing.T) {
+		t.Parallel()
+
+		provider := releaseprovider.NewFake(
+			release("v1.5.0", false),
+			release("v1.10.0", false),
+			release("v1.9.0", false),
+			release("v1.8.0", false),
+			release("v1.7.0", false),
+			release("v1.6.0", false),
+		)
+		releases := app.NewPrismReleases(provider)
+		require.NoError(t, releases.Refresh(t.Context()))
+
+		require.Equal(t, "v1.10.0", releases.Latest().Version)
+		require.True(t, releases.IsKnownVersion("v1.6.0"))
+		require.False(t, releases.IsKnownVersion("v1.5.0"))
+		require.False(t, releases.IsKnownVersion("v1.5.1-dev"))
+	})
+
+	t.Run("a missing name falls back to the version", func(t *testing.T) {
+		t.Parallel()
+
+		releases := app.NewPrismReleases(releaseprovider.NewFake(domain.PrismRelease{Version: "v1.12.0"}))
+		require.NoError(t, releases.Refresh(t.Context()))
+
+		require.Equal(t, "v1.12.0", releases.Latest().Name)
+	})
+
+	t.Run("start refreshing", func(t *testing.T) {
+		t.Parallel()
+
+		provider := releaseprovider.NewFake(release("v1.12.0", false))
+		releases := app.NewPrismReleases(provider)
+
+		stop := releases.StartRefreshing(t.Context(), time.Hour)
+		require.Eventually(t, func() bool {
+			return releases.Latest().Version == "v1.12.0"
+		}, time.Second, time.Millisecond, "the feed is read right away")
+		stop()
+
+		require.Equal(t, 1, provider.Calls(), "the next refresh is an hour away")
+	})
+
+}
@@ -10,9 +10,9 @@ import (
 	"github.com/Amund211/flashlight/internal/strutils"
 )
 
-// sessionAtBuffer is how far before and after the requested time we look
+// SessionAtBuffer is how far before and after the requested time we look
 // for stats to compute the session over.
-const sessionAtBuffer = 24 * time.Hour
+const SessionAtBuffer = 24 * time.Hour
 
 // GameSegment is a stretch of a session bracketed by two player
 // snapshots that saw game-relevant stat movement.
@@ -40,26 +40,28 @@ type GetSessionAt = func(
 	ctx context.Context,
 	uuid string,
 	at time.Time,
+	options SessionOptions,
 ) (SessionAtResult, error)
 
 // BuildGetSessionAt constructs a GetSessionAt that fetches the player's
 // stats in a window around the requested time (which transparently
 // updates the player's data via UpdatePlayerInInterval inside
 // GetPlayerPITs), computes the session that brackets the time, and
-// derives the per-game segments inside that session.
+// derives the per-game segments inside that session. With a gamemode in
+// options only that mode's games are segmented.
 func BuildGetSessionAt(
 	getPlayerPITs GetPlayerPITs,
 	computeSessions ComputeSessions,
 ) GetSessionAt {
-	return func(ctx context.Context, uuid string, at time.Time) (SessionAtResult, error) {
+	return func(ctx context.Context, uuid string, at time.Time, options SessionOptions) (SessionAtResult, error) {
 		if !strutils.UUIDIsNormalized(uuid) {
 			err := fmt.Errorf("UUID is not normalized")
 			reporting.Report(ctx, err)
 			return SessionAtResult{}, err
 		}
 
-		fetchStart := at.Add(-sessionAtBuffer)
-		fetchEnd := at.Add(sessionAtBuffer)
+		fetchStart := at.Add(-SessionAtBuffer)
+		fetchEnd := at.Add(SessionAtBuffer)
 
 		// GetPlayerPITs also runs UpdatePlayerInInterval so the buffered
 		// window is freshly populated before we read it.
@@ -69,7 +71,8 @@ func BuildGetSessionAt(
 			return SessionAtResult{}, fmt.Errorf("failed to get player pits: %w", err)
 		}
 
-		sessions := computeSessions(ctx, stats, fetchStart, fetchEnd)
+		options = options.withDefaults()
+		sessions := computeSessions(ctx, stats, fetchStart, fetchEnd, options)
 
 		var session *domain.Session
 		for i := range sessions {
@@ -80,11 +83,11 @@ func BuildGetSessionAt(
 			endMs := s.End.QueriedAt
 			if s.Ongoing {
 				// Ongoing means a fresh stat could still extend the
-				// session up to End + sessionInactivityThreshold, so the
+				// session up to End + the inactivity threshold, so the
 				// bracket extends to cover that window. Without this, a
 				// caller asking for `at = now` would miss a session whose
 				// last snapshot just happened.
-				endMs = endMs.Add(sessionInactivityThreshold)
+				endMs = endMs.Add(options.InactivityThreshold)
 			}
 
 			if !endMs.Before(at) && !startMs.After(at) {
@@ -121,47 +124,78 @@ func BuildGetSessionAt(
 			)
 		}
 
-		// Filter down to stats within the session window
-		windowed := make([]domain.PlayerPIT, 0, len(stats))
-		for _, stat := range stats {
-			if stat.QueriedAt.Before(session.Start.QueriedAt) || stat.QueriedAt.After(session.End.QueriedAt) {
-				continue
-			}
-			windowed = append(windowed, stat)
+		games, err := ComputeGameSegments(ctx, stats, session)
+		if err != nil {
+			return SessionAtResult{}, err
 		}
 
-		if len(windowed) < 2 {
-			// Unreachable - a session must have at least two stats
-			err := fmt.Errorf("session has fewer than 2 stats in window, cannot compute game segments")
-			reporting.Report(ctx, err, map[string]string{
-				"sessionStart": session.Start.QueriedAt.Format(time.RFC3339),
-				"sessionEnd":   session.End.QueriedAt.Format(time.RFC3339),
-				"statsCount":   fmt.Sprintf("%d", len(windowed)),
-			})
-			return SessionAtResult{}, err
+		return SessionAtResult{Session: session, Games: games}, nil
+	}
+}
+
+// ComputeGameSegments derives the per-game segments inside session from the
+// stats the session was computed from. Only the session's gamemode is
+// segmented.
+func ComputeGameSegments(ctx context.Context, stats []domain.PlayerPIT, session *domain.Session) ([]GameSegment, error) {
+	gamemode := session.Gamemode
+	if gamemode == "" {
+		gamemode = domain.GamemodeOverall
+	}
+
+	// Filter down to stats within the session window
+	windowed := make([]domain.PlayerPIT, 0, len(stats))
+	for _, stat := range stats {
+		if stat.QueriedAt.Before(session.Start.QueriedAt) || stat.QueriedAt.After(session.End.QueriedAt) {
+			continue
 		}
+		windowed = append(windowed, stat)
+	}
 
-		// Add segments for each adjacent pair of stats, then merge consecutive
-		// non-game stats. Filter out stats that don't move Experience or GamesPlayed.
-		games := make([]GameSegment, 0, len(windowed)-1)
-		prev := windowed[0]
-		for _, curr := range windowed[1:] {
-			if prev.Experience == curr.Experience &&
-				prev.Overall.GamesPlayed == curr.Overall.GamesPlayed {
-				prev = curr
-				continue
-			}
-			seg := buildGameSegment(ctx, prev, curr)
-			if seg.Game == nil && len(games) > 0 && games[len(games)-1].Game == nil {
-				games[len(games)-1].End = curr
-			} else {
-				games = append(games, seg)
-			}
+	if len(windowed) < 2 {
+		// Unreachable - a session must have at least two stats
+		err := fmt.Errorf("session has fewer than 2 stats in window, cannot compute game segments")
+		reporting.Report(ctx, err, map[string]string{
+			"sessionStart": session.Start.QueriedAt.Format(time.RFC3339),
+			"sessionEnd":   session.End.QueriedAt.Format(time.RFC3339),
+			"statsCount":   fmt.Sprintf("%d", len(windowed)),
+		})
+		return nil, err
+	}
+
+	// Add segments for each adjacent pair of stats, then merge consecutive
+	// non-game stats. Filter out stats that don't progress the tracked
+	// gamemode.
+	games := make([]GameSegment, 0, len(windowed)-1)
+	prev := windowed[0]
+	for _, curr := range windowed[1:] {
+		prevGamesPlayed, prevActivity := sessionProgress(&prev, gamemode)
+		currGamesPlayed, currActivity := sessionProgress(&curr, gamemode)
+		if prevGamesPlayed == currGamesPlayed && prevActivity == currActivity {
 			prev = curr
+			continue
+		}
+		seg := buildGameSegment(ctx, prev, curr)
+		if seg.Game == nil && len(games) > 0 && games[len(games)-1].Game == nil {
+			games[len(games)-1].End = curr
+		} else {
+			games = append(games, seg)
 		}
+		prev = curr
+	}
 
-		return SessionAtResult{Session: session, Games: games}, nil
+	return games, nil
+}
+
+// SummarizeSession summarizes session, counting the games in its segments
+// that could be attributed
+func SummarizeSession(session *domain.Session, games []GameSegment) domain.SessionSummary {
+	attributedGames := 0
+	for _, seg := range games {
+		if seg.Game != nil {
+			attributedGames++
+		}
 	}
+	return domain.NewSessionSummary(session, attributedGames)
 }
 
 func gamesPlayedDelta(prev, curr domain.GamemodeStatsPIT) int {
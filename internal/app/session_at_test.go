@@ -13,6 +13,14 @@ import (
 	"github.com/Amund211/flashlight/internal/domaintest"
 )
 
+// overallSession fills in what ComputeSessions derives for a session over
+// every gamemode
+func overallSession(session domain.Session) *domain.Session {
+	session.Gamemode = domain.GamemodeOverall
+	session.Deltas = domain.NewSessionDeltas(&session.Start, &session.End)
+	return &session
+}
+
 func TestBuildGetSessionAt(t *testing.T) {
 	t.Parallel()
 
@@ -47,7 +55,7 @@ func TestBuildGetSessionAt(t *testing.T) {
 		}
 		getSessionAt := app.BuildGetSessionAt(getPlayerPITs, computeSessions)
 
-		result, err := getSessionAt(t.Context(), uuid, at)
+		result, err := getSessionAt(t.Context(), uuid, at, app.SessionOptions{})
 		require.NoError(t, err)
 		require.Equal(t, app.SessionAtResult{}, result)
 		require.Equal(t, uuid, gotUUID)
@@ -100,10 +108,10 @@ func TestBuildGetSessionAt(t *testing.T) {
 			computeSessions,
 		)
 
-		result, err := getSessionAt(t.Context(), uuid, at)
+		result, err := getSessionAt(t.Context(), uuid, at, app.SessionOptions{})
 		require.NoError(t, err)
 		require.Equal(t, app.SessionAtResult{
-			Session: &domain.Session{Start: p0, End: p3, Consecutive: true},
+			Session: overallSession(domain.Session{Start: p0, End: p3, Consecutive: true}),
 			Games: []app.GameSegment{
 				{Start: p0, End: p1, Game: &domain.GameResult{
 					Gamemode:   domain.GamemodeDoubles,
@@ -169,10 +177,10 @@ func TestBuildGetSessionAt(t *testing.T) {
 			computeSessions,
 		)
 
-		result, err := getSessionAt(t.Context(), uuid, at)
+		result, err := getSessionAt(t.Context(), uuid, at, app.SessionOptions{})
 		require.NoError(t, err)
 		require.Equal(t, app.SessionAtResult{
-			Session: &domain.Session{Start: p0, End: p1, Consecutive: true},
+			Session: overallSession(domain.Session{Start: p0, End: p1, Consecutive: true}),
 			Games: []app.GameSegment{
 				{Start: p0, End: p1, Game: nil},
 			},
@@ -215,10 +223,10 @@ func TestBuildGetSessionAt(t *testing.T) {
 			computeSessions,
 		)
 
-		result, err := getSessionAt(t.Context(), uuid, at)
+		result, err := getSessionAt(t.Context(), uuid, at, app.SessionOptions{})
 		require.NoError(t, err)
 		require.Equal(t, app.SessionAtResult{
-			Session: &domain.Session{Start: p0, End: p1, Consecutive: true},
+			Session: overallSession(domain.Session{Start: p0, End: p1, Consecutive: true}),
 			Games: []app.GameSegment{
 				{Start: p0, End: p1, Game: nil},
 			},
@@ -298,10 +306,10 @@ func TestBuildGetSessionAt(t *testing.T) {
 			Experience: 300,
 		}
 
-		result, err := getSessionAt(t.Context(), uuid, at)
+		result, err := getSessionAt(t.Context(), uuid, at, app.SessionOptions{})
 		require.NoError(t, err)
 		require.Equal(t, app.SessionAtResult{
-			Session: &domain.Session{Start: p2, End: p9, Consecutive: true},
+			Session: overallSession(domain.Session{Start: p2, End: p9, Consecutive: true}),
 			Games: []app.GameSegment{
 				{Start: p2, End: p3, Game: wonGame},
 				{Start: p5, End: p6, Game: wonGame},
@@ -347,10 +355,10 @@ func TestBuildGetSessionAt(t *testing.T) {
 			computeSessions,
 		)
 
-		result, err := getSessionAt(t.Context(), uuid, at)
+		result, err := getSessionAt(t.Context(), uuid, at, app.SessionOptions{})
 		require.NoError(t, err)
 		require.Equal(t, app.SessionAtResult{
-			Session: &domain.Session{Start: p0, End: p2, Consecutive: true},
+			Session: overallSession(domain.Session{Start: p0, End: p2, Consecutive: true}),
 			Games: []app.GameSegment{
 				{Start: p0, End: p2, Game: nil},
 			},
@@ -394,10 +402,10 @@ func TestBuildGetSessionAt(t *testing.T) {
 			computeSessions,
 		)
 
-		result, err := getSessionAt(t.Context(), uuid, at)
+		result, err := getSessionAt(t.Context(), uuid, at, app.SessionOptions{})
 		require.NoError(t, err)
 		require.Equal(t, app.SessionAtResult{
-			Session: &domain.Session{Start: p0, End: p2, Consecutive: true},
+			Session: overallSession(domain.Session{Start: p0, End: p2, Consecutive: true}),
 			Games: []app.GameSegment{
 				{Start: p0, End: p1, Game: &domain.GameResult{
 					Gamemode:   domain.GamemodeDoubles,
@@ -449,10 +457,10 @@ func TestBuildGetSessionAt(t *testing.T) {
 			computeSessions,
 		)
 
-		result, err := getSessionAt(t.Context(), uuid, at)
+		result, err := getSessionAt(t.Context(), uuid, at, app.SessionOptions{})
 		require.NoError(t, err)
 		require.Equal(t, app.SessionAtResult{
-			Session: &domain.Session{Start: p0, End: p2, Consecutive: true},
+			Session: overallSession(domain.Session{Start: p0, End: p2, Consecutive: true}),
 			Games: []app.GameSegment{
 				{Start: p0, End: p1, Game: nil},
 				{Start: p1, End: p2, Game: &domain.GameResult{
@@ -508,10 +516,10 @@ func TestBuildGetSessionAt(t *testing.T) {
 			computeSessions,
 		)
 
-		result, err := getSessionAt(t.Context(), uuid, atFromURL)
+		result, err := getSessionAt(t.Context(), uuid, atFromURL, app.SessionOptions{})
 		require.NoError(t, err)
 		require.Equal(t, app.SessionAtResult{
-			Session: &domain.Session{Start: p0, End: p1, Consecutive: true},
+			Session: overallSession(domain.Session{Start: p0, End: p1, Consecutive: true}),
 			Games: []app.GameSegment{
 				{Start: p0, End: p1, Game: &domain.GameResult{
 					Gamemode:   domain.GamemodeDoubles,
@@ -562,10 +570,10 @@ func TestBuildGetSessionAt(t *testing.T) {
 			ongoingCompute,
 		)
 
-		result, err := getSessionAt(t.Context(), uuid, at)
+		result, err := getSessionAt(t.Context(), uuid, at, app.SessionOptions{})
 		require.NoError(t, err)
 		require.Equal(t, app.SessionAtResult{
-			Session: &domain.Session{Start: p0, End: p1, Consecutive: true, Ongoing: true},
+			Session: overallSession(domain.Session{Start: p0, End: p1, Consecutive: true, Ongoing: true}),
 			Games: []app.GameSegment{
 				{Start: p0, End: p1, Game: &domain.GameResult{
 					Gamemode:   domain.GamemodeDoubles,
@@ -615,7 +623,7 @@ func TestBuildGetSessionAt(t *testing.T) {
 			ongoingCompute,
 		)
 
-		result, err := getSessionAt(t.Context(), uuid, at)
+		result, err := getSessionAt(t.Context(), uuid, at, app.SessionOptions{})
 		require.NoError(t, err)
 		require.Equal(t, app.SessionAtResult{}, result)
 	})
@@ -645,7 +653,7 @@ func TestBuildGetSessionAt(t *testing.T) {
 			computeSessions,
 		)
 
-		result, err := getSessionAt(t.Context(), uuid, at)
+		result, err := getSessionAt(t.Context(), uuid, at, app.SessionOptions{})
 		require.NoError(t, err)
 		require.Equal(t, app.SessionAtResult{}, result)
 	})
@@ -678,10 +686,10 @@ func TestBuildGetSessionAt(t *testing.T) {
 				computeSessions,
 			)
 
-			result, err := getSessionAt(t.Context(), uuid, at)
+			result, err := getSessionAt(t.Context(), uuid, at, app.SessionOptions{})
 			require.NoError(t, err)
 			require.Equal(t, app.SessionAtResult{
-				Session: &domain.Session{Start: p0, End: p1, Consecutive: true},
+				Session: overallSession(domain.Session{Start: p0, End: p1, Consecutive: true}),
 				Games: []app.GameSegment{
 					{Start: p0, End: p1, Game: nil},
 				},
@@ -715,10 +723,10 @@ func TestBuildGetSessionAt(t *testing.T) {
 				computeSessions,
 			)
 
-			result, err := getSessionAt(t.Context(), uuid, at)
+			result, err := getSessionAt(t.Context(), uuid, at, app.SessionOptions{})
 			require.NoError(t, err)
 			require.Equal(t, app.SessionAtResult{
-				Session: &domain.Session{Start: p0, End: p1, Consecutive: true},
+				Session: overallSession(domain.Session{Start: p0, End: p1, Consecutive: true}),
 				Games: []app.GameSegment{
 					{Start: p0, End: p1, Game: nil},
 				},
@@ -752,10 +760,10 @@ func TestBuildGetSessionAt(t *testing.T) {
 				computeSessions,
 			)
 
-			result, err := getSessionAt(t.Context(), uuid, at)
+			result, err := getSessionAt(t.Context(), uuid, at, app.SessionOptions{})
 			require.NoError(t, err)
 			require.Equal(t, app.SessionAtResult{
-				Session: &domain.Session{Start: p0, End: p1, Consecutive: true},
+				Session: overallSession(domain.Session{Start: p0, End: p1, Consecutive: true}),
 				Games: []app.GameSegment{
 					{Start: p0, End: p1, Game: nil},
 				},
@@ -789,10 +797,10 @@ func TestBuildGetSessionAt(t *testing.T) {
 				computeSessions,
 			)
 
-			result, err := getSessionAt(t.Context(), uuid, at)
+			result, err := getSessionAt(t.Context(), uuid, at, app.SessionOptions{})
 			require.NoError(t, err)
 			require.Equal(t, app.SessionAtResult{
-				Session: &domain.Session{Start: p0, End: p1, Consecutive: true},
+				Session: overallSession(domain.Session{Start: p0, End: p1, Consecutive: true}),
 				Games: []app.GameSegment{
 					{Start: p0, End: p1, Game: nil},
 				},
@@ -809,7 +817,7 @@ func TestBuildGetSessionAt(t *testing.T) {
 		}
 		getSessionAt := app.BuildGetSessionAt(getPlayerPITs, computeSessions)
 
-		_, err := getSessionAt(t.Context(), uuid, at)
+		_, err := getSessionAt(t.Context(), uuid, at, app.SessionOptions{})
 		require.Error(t, err)
 		require.ErrorIs(t, err, boom)
 	})
@@ -824,7 +832,7 @@ func TestBuildGetSessionAt(t *testing.T) {
 		}
 		getSessionAt := app.BuildGetSessionAt(getPlayerPITs, computeSessions)
 
-		_, err := getSessionAt(t.Context(), "0123456789abcdef0123456789abcdef", at)
+		_, err := getSessionAt(t.Context(), "0123456789abcdef0123456789abcdef", at, app.SessionOptions{})
 		require.Error(t, err)
 	})
 
@@ -870,10 +878,10 @@ func TestBuildGetSessionAt(t *testing.T) {
 						computeSessions,
 					)
 
-					result, err := getSessionAt(t.Context(), uuid, at)
+					result, err := getSessionAt(t.Context(), uuid, at, app.SessionOptions{})
 					require.NoError(t, err)
 					require.Equal(t, app.SessionAtResult{
-						Session: &domain.Session{Start: prev, End: curr, Consecutive: true},
+						Session: overallSession(domain.Session{Start: prev, End: curr, Consecutive: true}),
 						Games: []app.GameSegment{
 							{Start: prev, End: curr, Game: &domain.GameResult{
 								Gamemode:   tc.gamemode,
@@ -911,10 +919,10 @@ func TestBuildGetSessionAt(t *testing.T) {
 						computeSessions,
 					)
 
-					result, err := getSessionAt(t.Context(), uuid, at)
+					result, err := getSessionAt(t.Context(), uuid, at, app.SessionOptions{})
 					require.NoError(t, err)
 					require.Equal(t, app.SessionAtResult{
-						Session: &domain.Session{Start: prev, End: curr, Consecutive: true},
+						Session: overallSession(domain.Session{Start: prev, End: curr, Consecutive: true}),
 						Games: []app.GameSegment{
 							{Start: prev, End: curr, Game: &domain.GameResult{
 								Gamemode:   tc.gamemode,
@@ -952,10 +960,10 @@ func TestBuildGetSessionAt(t *testing.T) {
 						computeSessions,
 					)
 
-					result, err := getSessionAt(t.Context(), uuid, at)
+					result, err := getSessionAt(t.Context(), uuid, at, app.SessionOptions{})
 					require.NoError(t, err)
 					require.Equal(t, app.SessionAtResult{
-						Session: &domain.Session{Start: prev, End: curr, Consecutive: true},
+						Session: overallSession(domain.Session{Start: prev, End: curr, Consecutive: true}),
 						Games: []app.GameSegment{
 							{Start: prev, End: curr, Game: &domain.GameResult{
 								Gamemode:   tc.gamemode,
@@ -4,28 +4,90 @@ import (
 	"context"
 	"fmt"
 	"slices"
+	"sort"
 	"time"
 
 	"github.com/Amund211/flashlight/internal/domain"
 	"github.com/Amund211/flashlight/internal/reporting"
 )
 
-const sessionInactivityThreshold = 60 * time.Minute
+const (
+	// DefaultSessionInactivityThreshold is how long a player can go without
+	// progress before their session ends, unless the caller asks otherwise
+	DefaultSessionInactivityThreshold = 60 * time.Minute
+	// MinSessionInactivityThreshold and MaxSessionInactivityThreshold bound
+	// a caller supplied threshold. Below the minimum a queue or a long game
+	// splits a session, above the maximum a night's sleep doesn't.
+	MinSessionInactivityThreshold = 10 * time.Minute
+	MaxSessionInactivityThreshold = 6 * time.Hour
+)
+
+// SessionOptions controls how stats are split into sessions. The zero value
+// tracks progress in any gamemode with DefaultSessionInactivityThreshold.
+type SessionOptions struct {
+	InactivityThreshold time.Duration
+	// Gamemode limits the progress that starts and extends a session to
+	// one mode, e.g. to find a player's 4v4 sessions
+	Gamemode domain.Gamemode
+	// OnlineTransitions are observed changes in the player's online status.
+	// Going offline between two stats ends the session even when the
+	// inactivity threshold hasn't passed. Filled in by
+	// BuildComputeSessionsWithOnlineTransitions.
+	OnlineTransitions []domain.OnlineTransition
+}
+
+func (o SessionOptions) withDefaults() SessionOptions {
+	if o.InactivityThreshold == 0 {
+		o.InactivityThreshold = DefaultSessionInactivityThreshold
+	}
+	if o.Gamemode == "" {
+		o.Gamemode = domain.GamemodeOverall
+	}
+	return o
+}
 
 type ComputeSessions = func(
 	ctx context.Context,
 	stats []domain.PlayerPIT,
 	start, end time.Time,
+	options SessionOptions,
 ) []domain.Session
 
+// sessionProgress returns the games played and a measure of in-game
+// activity for the tracked gamemode. Stats that move neither are not
+// progress.
+func sessionProgress(stat *domain.PlayerPIT, gamemode domain.Gamemode) (int, int64) {
+	if gamemode == domain.GamemodeOverall {
+		return stat.Overall.GamesPlayed, stat.Experience
+	}
+
+	// Experience is shared between modes, so use the mode's own counters,
+	// which also move during a game
+	stats, ok := stat.GamemodeStats(gamemode)
+	if !ok {
+		return 0, 0
+	}
+	activity := stats.Wins + stats.Losses + stats.BedsBroken + stats.BedsLost +
+		stats.FinalKills + stats.FinalDeaths + stats.Kills + stats.Deaths
+	return stats.GamesPlayed, int64(activity)
+}
+
 // NOTE: All domain.PlayerPIT entries must be for the same player
 func BuildComputeSessions(nowFunc func() time.Time) ComputeSessions {
-	return func(ctx context.Context, stats []domain.PlayerPIT, start, end time.Time) []domain.Session {
+	return func(ctx context.Context, stats []domain.PlayerPIT, start, end time.Time, options SessionOptions) []domain.Session {
 		if len(stats) <= 1 {
 			// Need at least a start and end to create a session
 			return []domain.Session{}
 		}
 
+		options = options.withDefaults()
+		if _, ok := stats[0].GamemodeStats(options.Gamemode); !ok {
+			reporting.Report(ctx, fmt.Errorf("unknown gamemode in ComputeSessions"), map[string]string{
+				"gamemode": string(options.Gamemode),
+			})
+			return []domain.Session{}
+		}
+
 		slices.SortStableFunc(stats, func(a, b domain.PlayerPIT) int {
 			if a.QueriedAt.Before(b.QueriedAt) {
 				return -1
@@ -36,10 +98,40 @@ func BuildComputeSessions(nowFunc func() time.Time) ComputeSessions {
 			return 0
 		})
 
+		transitions := slices.SortedStableFunc(slices.Values(options.OnlineTransitions), func(a, b domain.OnlineTransition) int {
+			return a.At.Compare(b.At)
+		})
+		wentOffline := make([]time.Time, 0, len(transitions))
+		for _, transition := range transitions {
+			if !transition.Online {
+				wentOffline = append(wentOffline, transition.At)
+			}
+		}
+
+		// wentOfflineBetween reports whether the player was seen going offline
+		// in the interval (from, to]
+		wentOfflineBetween := func(from, to time.Time) bool {
+			// The first time the player went offline after from
+			i := sort.Search(len(wentOffline), func(i int) bool {
+				return wentOffline[i].After(from)
+			})
+			return i < len(wentOffline) && !wentOffline[i].After(to)
+		}
+
 		sessions := []domain.Session{}
 
 		getProgressStats := func(stat *domain.PlayerPIT) (int, int64) {
-			return stat.Overall.GamesPlayed, stat.Experience
+			return sessionProgress(stat, options.Gamemode)
+		}
+
+		newSession := func(sessionStart, lastEventfulEntry *domain.PlayerPIT, consecutive bool) domain.Session {
+			return domain.Session{
+				Start:       *sessionStart,
+				End:         *lastEventfulEntry,
+				Consecutive: consecutive,
+				Gamemode:    options.Gamemode,
+				Deltas:      domain.NewSessionDeltas(sessionStart, lastEventfulEntry),
+			}
 		}
 
 		includeSession := func(sessionStart, lastEventfulEntry *domain.PlayerPIT) bool {
@@ -94,14 +186,12 @@ func BuildComputeSessions(nowFunc func() time.Time) ComputeSessions {
 				continue
 			}
 
-			// If more than the inactivity threshold since last activity, end session
-			if stat.QueriedAt.Sub(lastEventfulEntry.QueriedAt) > sessionInactivityThreshold {
+			// If more than the inactivity threshold since last activity, or the
+			// player went offline since, end session
+			if stat.QueriedAt.Sub(lastEventfulEntry.QueriedAt) > options.InactivityThreshold ||
+				wentOfflineBetween(lastEventfulEntry.QueriedAt, stat.QueriedAt) {
 				if includeSession(sessionStart, lastEventfulEntry) {
-					sessions = append(sessions, domain.Session{
-						Start:       *sessionStart,
-						End:         *lastEventfulEntry,
-						Consecutive: consecutive,
-					})
+					sessions = append(sessions, newSession(sessionStart, lastEventfulEntry, consecutive))
 				}
 				// Jump back to right after the last eventful entry (loop adds one)
 				// This makes sure we include any non-eventful trailing entries, as they could
@@ -136,20 +226,24 @@ func BuildComputeSessions(nowFunc func() time.Time) ComputeSessions {
 		lastEventfulEntry := &stats[lastEventfulIndex]
 
 		if includeSession(sessionStart, lastEventfulEntry) {
-			sessions = append(sessions, domain.Session{
-				Start:       *sessionStart,
-				End:         *lastEventfulEntry,
-				Consecutive: consecutive,
-			})
+			sessions = append(sessions, newSession(sessionStart, lastEventfulEntry, consecutive))
 		}
 
 		if len(sessions) > 0 {
 			now := nowFunc()
 			last := &sessions[len(sessions)-1]
-			if !now.Before(last.Start.QueriedAt) && !now.After(last.End.QueriedAt.Add(sessionInactivityThreshold)) {
+			if !now.Before(last.Start.QueriedAt) && !now.After(last.End.QueriedAt.Add(options.InactivityThreshold)) {
 				// A stat increase at `now` could extend the session, so we mark
-				// it as ongoing.
+				// it as ongoing, unless the player has been seen going
+				// offline since and not come back.
 				last.Ongoing = true
+				// The last transition up to now
+				i := sort.Search(len(transitions), func(i int) bool {
+					return transitions[i].At.After(now)
+				})
+				if i > 0 && transitions[i-1].At.After(last.End.QueriedAt) {
+					last.Ongoing = transitions[i-1].Online
+				}
 			}
 		}
 
@@ -114,7 +114,7 @@ func TestComputeSessions(t *testing.T) {
 		players[24] = domaintest.NewPlayerBuilder(playerUUID).WithExperience(9_500).FromDB().Fours().WithGamesPlayed(18).Build(start.Add(3 * time.Hour).Add(56 * time.Minute))
 		players[25] = domaintest.NewPlayerBuilder(playerUUID).WithExperience(10_800).FromDB().Fours().WithGamesPlayed(19).Build(start.Add(4 * time.Hour).Add(16 * time.Minute))
 
-		sessions := computeSessions(ctx, players, start, start.Add(24*time.Hour))
+		sessions := computeSessions(ctx, players, start, start.Add(24*time.Hour), app.SessionOptions{})
 
 		expectedSessions := []domain.Session{
 			{
@@ -141,7 +141,7 @@ func TestComputeSessions(t *testing.T) {
 		players := make([]domain.PlayerPIT, 1)
 		players[0] = domaintest.NewPlayerBuilder(playerUUID).WithExperience(1_300).FromDB().Fours().WithGamesPlayed(11).Build(start.Add(6 * time.Hour).Add(7 * time.Minute))
 
-		sessions := computeSessions(ctx, players, start, start.Add(24*time.Hour))
+		sessions := computeSessions(ctx, players, start, start.Add(24*time.Hour), app.SessionOptions{})
 
 		require.Len(t, sessions, 0)
 	})
@@ -159,7 +159,7 @@ func TestComputeSessions(t *testing.T) {
 		players[1] = domaintest.NewPlayerBuilder(playerUUID).WithExperience(1_100).FromDB().Fours().WithGamesPlayed(10).Build(start.Add(8 * time.Hour).Add(-1 * time.Minute))
 		players[2] = domaintest.NewPlayerBuilder(playerUUID).WithExperience(1_300).FromDB().Fours().WithGamesPlayed(11).Build(start.Add(8 * time.Hour).Add(7 * time.Minute))
 
-		sessions := computeSessions(ctx, players, start, start.Add(24*time.Hour))
+		sessions := computeSessions(ctx, players, start, start.Add(24*time.Hour), app.SessionOptions{})
 
 		expectedSessions := []domain.Session{
 			{
@@ -184,7 +184,7 @@ func TestComputeSessions(t *testing.T) {
 
 		players[2] = domaintest.NewPlayerBuilder(playerUUID).WithExperience(1_600).FromDB().Fours().WithGamesPlayed(12).Build(start.Add(8 * time.Hour).Add(7 * time.Minute))
 
-		sessions := computeSessions(ctx, players, start, start.Add(24*time.Hour))
+		sessions := computeSessions(ctx, players, start, start.Add(24*time.Hour), app.SessionOptions{})
 
 		expectedSessions := []domain.Session{
 			{
@@ -211,7 +211,7 @@ func TestComputeSessions(t *testing.T) {
 
 		players[3] = domaintest.NewPlayerBuilder(playerUUID).WithExperience(1_600).FromDB().Fours().WithGamesPlayed(12).Build(start.Add(10 * time.Hour).Add(7 * time.Minute))
 
-		sessions := computeSessions(ctx, players, start, start.Add(24*time.Hour))
+		sessions := computeSessions(ctx, players, start, start.Add(24*time.Hour), app.SessionOptions{})
 
 		expectedSessions := []domain.Session{
 			{
@@ -231,7 +231,7 @@ func TestComputeSessions(t *testing.T) {
 
 		players := make([]domain.PlayerPIT, 0)
 
-		sessions := computeSessions(ctx, players, start, start.Add(24*time.Hour))
+		sessions := computeSessions(ctx, players, start, start.Add(24*time.Hour), app.SessionOptions{})
 
 		require.Len(t, sessions, 0)
 	})
@@ -258,7 +258,7 @@ func TestComputeSessions(t *testing.T) {
 		players[11] = domaintest.NewPlayerBuilder(playerUUID).WithExperience(10_800).FromDB().Fours().WithGamesPlayed(19).Build(start.Add(4 * time.Hour).Add(16 * time.Minute))
 		players[12] = domaintest.NewPlayerBuilder(playerUUID).WithExperience(10_800).FromDB().Fours().WithGamesPlayed(19).Build(start.Add(4 * time.Hour).Add(20 * time.Minute))
 
-		sessions := computeSessions(ctx, players, start, start.Add(24*time.Hour))
+		sessions := computeSessions(ctx, players, start, start.Add(24*time.Hour), app.SessionOptions{})
 
 		expectedSessions := []domain.Session{
 			{
@@ -290,7 +290,7 @@ func TestComputeSessions(t *testing.T) {
 		players[2] = domaintest.NewPlayerBuilder(playerUUID).WithExperience(9_400).FromDB().Fours().WithGamesPlayed(17).Build(start.Add(1 * time.Hour).Add(45 * time.Minute))
 		players[3] = domaintest.NewPlayerBuilder(playerUUID).WithExperience(10_800).FromDB().Fours().WithGamesPlayed(18).Build(start.Add(2 * time.Hour).Add(31 * time.Minute))
 
-		sessions := computeSessions(ctx, players, start, start.Add(24*time.Hour))
+		sessions := computeSessions(ctx, players, start, start.Add(24*time.Hour), app.SessionOptions{})
 
 		expectedSessions := []domain.Session{
 			{
@@ -327,7 +327,7 @@ func TestComputeSessions(t *testing.T) {
 		players[6] = domaintest.NewPlayerBuilder(playerUUID).WithExperience(10_900).FromDB().Fours().WithGamesPlayed(19).Build(start.Add(45 * time.Hour).Add(5 * time.Minute))
 		players[7] = domaintest.NewPlayerBuilder(playerUUID).WithExperience(11_900).FromDB().Fours().WithGamesPlayed(20).Build(start.Add(45 * time.Hour).Add(30 * time.Minute))
 
-		sessions := computeSessions(ctx, players, start, start.Add(24*time.Hour))
+		sessions := computeSessions(ctx, players, start, start.Add(24*time.Hour), app.SessionOptions{})
 
 		expectedSessions := []domain.Session{}
 		requireEqualSessions(t, expectedSessions, sessions)
@@ -348,7 +348,7 @@ func TestComputeSessions(t *testing.T) {
 		players[2] = domaintest.NewPlayerBuilder(playerUUID).WithExperience(9_400).FromDB().Fours().WithGamesPlayed(16).Build(start.Add(1 * time.Hour).Add(45 * time.Minute))
 		players[3] = domaintest.NewPlayerBuilder(playerUUID).WithExperience(10_800).FromDB().Fours().WithGamesPlayed(16).Build(start.Add(2 * time.Hour).Add(31 * time.Minute))
 
-		sessions := computeSessions(ctx, players, start, start.Add(24*time.Hour))
+		sessions := computeSessions(ctx, players, start, start.Add(24*time.Hour), app.SessionOptions{})
 
 		expectedSessions := []domain.Session{
 			{
@@ -380,7 +380,7 @@ func TestComputeSessions(t *testing.T) {
 		players[2] = domaintest.NewPlayerBuilder(playerUUID).WithExperience(9_200).FromDB().Fours().WithGamesPlayed(17).Build(start.Add(1 * time.Hour).Add(45 * time.Minute))
 		players[3] = domaintest.NewPlayerBuilder(playerUUID).WithExperience(9_200).FromDB().Fours().WithGamesPlayed(18).Build(start.Add(2 * time.Hour).Add(31 * time.Minute))
 
-		sessions := computeSessions(ctx, players, start, start.Add(24*time.Hour))
+		sessions := computeSessions(ctx, players, start, start.Add(24*time.Hour), app.SessionOptions{})
 
 		expectedSessions := []domain.Session{
 			{
@@ -397,6 +397,174 @@ func TestComputeSessions(t *testing.T) {
 		requireEqualSessions(t, expectedSessions, sessions)
 	})
 
+	t.Run("custom inactivity threshold", func(t *testing.T) {
+		ctx := context.Background()
+		t.Parallel()
+		computeSessions := app.BuildComputeSessions(nowFarFuture)
+		playerUUID := domaintest.NewUUID(t)
+		start := time.Date(2024, time.August, 2, 1, 47, 34, 987_654_321, time.UTC)
+
+		players := make([]domain.PlayerPIT, 4)
+		players[0] = domaintest.NewPlayerBuilder(playerUUID).WithExperience(9_200).FromDB().Fours().WithGamesPlayed(16).Build(start.Add(5 * time.Minute))
+		players[1] = domaintest.NewPlayerBuilder(playerUUID).WithExperience(9_400).FromDB().Fours().WithGamesPlayed(17).Build(start.Add(25 * time.Minute))
+		// 40 minutes of inactivity
+		players[2] = domaintest.NewPlayerBuilder(playerUUID).WithExperience(9_600).FromDB().Fours().WithGamesPlayed(18).Build(start.Add(65 * time.Minute))
+		players[3] = domaintest.NewPlayerBuilder(playerUUID).WithExperience(9_800).FromDB().Fours().WithGamesPlayed(19).Build(start.Add(85 * time.Minute))
+
+		t.Run("default threshold", func(t *testing.T) {
+			t.Parallel()
+
+			sessions := computeSessions(ctx, players, start, start.Add(24*time.Hour), app.SessionOptions{})
+			requireEqualSessions(t, []domain.Session{
+				{Start: players[0], End: players[3], Consecutive: true},
+			}, sessions)
+		})
+
+		t.Run("shorter threshold", func(t *testing.T) {
+			t.Parallel()
+
+			sessions := computeSessions(ctx, players, start, start.Add(24*time.Hour), app.SessionOptions{
+				InactivityThreshold: 30 * time.Minute,
+			})
+			requireEqualSessions(t, []domain.Session{
+				{Start: players[0], End: players[1], Consecutive: true},
+				{Start: players[2], End: players[3], Consecutive: true},
+			}, sessions)
+		})
+	})
+
+	t.Run("online transitions", func(t *testing.T) {
+		ctx := context.Background()
+		t.Parallel()
+		playerUUID := domaintest.NewUUID(t)
+		start := time.Date(2024, time.August, 2, 1, 47, 34, 987_654_321, time.UTC)
+
+		players := make([]domain.PlayerPIT, 4)
+		players[0] = domaintest.NewPlayerBuilder(playerUUID).WithExperience(9_200).FromDB().Fours().WithGamesPlayed(16).Build(start.Add(5 * time.Minute))
+		players[1] = domaintest.NewPlayerBuilder(playerUUID).WithExperience(9_400).FromDB().Fours().WithGamesPlayed(17).Build(start.Add(25 * time.Minute))
+		players[2] = domaintest.NewPlayerBuilder(playerUUID).WithExperience(9_600).FromDB().Fours().WithGamesPlayed(18).Build(start.Add(45 * time.Minute))
+		players[3] = domaintest.NewPlayerBuilder(playerUUID).WithExperience(9_800).FromDB().Fours().WithGamesPlayed(19).Build(start.Add(65 * time.Minute))
+
+		nowFunc := func() time.Time { return start.Add(70 * time.Minute) }
+		computeSessions := app.BuildComputeSessions(nowFunc)
+
+		t.Run("no transitions", func(t *testing.T) {
+			t.Parallel()
+
+			sessions := computeSessions(ctx, players, start, start.Add(24*time.Hour), app.SessionOptions{})
+			requireEqualSessions(t, []domain.Session{
+				{Start: players[0], End: players[3], Consecutive: true, Ongoing: true},
+			}, sessions)
+		})
+
+		t.Run("offline splits session", func(t *testing.T) {
+			t.Parallel()
+
+			sessions := computeSessions(ctx, players, start, start.Add(24*time.Hour), app.SessionOptions{
+				OnlineTransitions: []domain.OnlineTransition{
+					{At: start.Add(35 * time.Minute), Online: true},
+					{At: start.Add(30 * time.Minute), Online: false},
+				},
+			})
+			requireEqualSessions(t, []domain.Session{
+				{Start: players[0], End: players[1], Consecutive: true},
+				{Start: players[2], End: players[3], Consecutive: true, Ongoing: true},
+			}, sessions)
+		})
+
+		t.Run("offline after last session is not ongoing", func(t *testing.T) {
+			t.Parallel()
+
+			sessions := computeSessions(ctx, players, start, start.Add(24*time.Hour), app.SessionOptions{
+				OnlineTransitions: []domain.OnlineTransition{
+					{At: start.Add(67 * time.Minute), Online: false},
+				},
+			})
+			requireEqualSessions(t, []domain.Session{
+				{Start: players[0], End: players[3], Consecutive: true},
+			}, sessions)
+		})
+
+		t.Run("back online after last session is ongoing", func(t *testing.T) {
+			t.Parallel()
+
+			sessions := computeSessions(ctx, players, start, start.Add(24*time.Hour), app.SessionOptions{
+				OnlineTransitions: []domain.OnlineTransition{
+					{At: start.Add(67 * time.Minute), Online: false},
+					{At: start.Add(68 * time.Minute), Online: true},
+				},
+			})
+			requireEqualSessions(t, []domain.Session{
+				{Start: players[0], End: players[3], Consecutive: true, Ongoing: true},
+			}, sessions)
+		})
+	})
+
+	t.Run("per gamemode", func(t *testing.T) {
+		ctx := context.Background()
+		t.Parallel()
+		computeSessions := app.BuildComputeSessions(nowFarFuture)
+		playerUUID := domaintest.NewUUID(t)
+		start := time.Date(2024, time.August, 2, 1, 47, 34, 987_654_321, time.UTC)
+
+		players := make([]domain.PlayerPIT, 4)
+		// Solo session
+		players[0] = domaintest.NewPlayerBuilder(playerUUID).WithExperience(9_200).FromDB().Solo().WithGamesPlayed(4).Fours().WithGamesPlayed(16).Build(start.Add(5 * time.Minute))
+		players[1] = domaintest.NewPlayerBuilder(playerUUID).WithExperience(9_400).FromDB().Solo().WithGamesPlayed(5).WithFinalKills(1).Fours().WithGamesPlayed(16).Build(start.Add(25 * time.Minute))
+		// Fours session
+		players[2] = domaintest.NewPlayerBuilder(playerUUID).WithExperience(9_400).FromDB().Solo().WithGamesPlayed(5).WithFinalKills(1).Fours().WithGamesPlayed(16).Build(start.Add(3 * time.Hour))
+		players[3] = domaintest.NewPlayerBuilder(playerUUID).WithExperience(9_700).FromDB().Solo().WithGamesPlayed(5).WithFinalKills(1).Fours().WithGamesPlayed(17).WithWins(1).Build(start.Add(3*time.Hour + 20*time.Minute))
+
+		t.Run("overall", func(t *testing.T) {
+			t.Parallel()
+
+			sessions := computeSessions(ctx, players, start, start.Add(24*time.Hour), app.SessionOptions{})
+			requireEqualSessions(t, []domain.Session{
+				{Start: players[0], End: players[1], Consecutive: true},
+				{Start: players[2], End: players[3], Consecutive: true},
+			}, sessions)
+			require.Equal(t, domain.GamemodeOverall, sessions[0].Gamemode)
+			require.Equal(t, int64(200), sessions[0].Deltas.Experience)
+			require.Equal(t, 1, sessions[0].Deltas.Solo.GamesPlayed)
+			require.Equal(t, 1, sessions[0].Deltas.Overall.GamesPlayed)
+		})
+
+		t.Run("solo", func(t *testing.T) {
+			t.Parallel()
+
+			sessions := computeSessions(ctx, players, start, start.Add(24*time.Hour), app.SessionOptions{
+				Gamemode: domain.GamemodeSolo,
+			})
+			requireEqualSessions(t, []domain.Session{
+				{Start: players[0], End: players[1], Consecutive: true},
+			}, sessions)
+			require.Equal(t, domain.GamemodeSolo, sessions[0].Gamemode)
+			require.Equal(t, domain.GamemodeStatsDelta{GamesPlayed: 1, FinalKills: 1}, sessions[0].Deltas.Solo)
+			require.Equal(t, domain.GamemodeStatsDelta{}, sessions[0].Deltas.Fours)
+		})
+
+		t.Run("fours", func(t *testing.T) {
+			t.Parallel()
+
+			sessions := computeSessions(ctx, players, start, start.Add(24*time.Hour), app.SessionOptions{
+				Gamemode: domain.GamemodeFours,
+			})
+			requireEqualSessions(t, []domain.Session{
+				{Start: players[2], End: players[3], Consecutive: true},
+			}, sessions)
+			require.Equal(t, domain.GamemodeStatsDelta{GamesPlayed: 1, Wins: 1}, sessions[0].Deltas.Fours)
+		})
+
+		t.Run("unknown gamemode", func(t *testing.T) {
+			t.Parallel()
+
+			sessions := computeSessions(ctx, players, start, start.Add(24*time.Hour), app.SessionOptions{
+				Gamemode: domain.Gamemode("skywars"),
+			})
+			require.Empty(t, sessions)
+		})
+	})
+
 	t.Run("gaps in sessions", func(t *testing.T) {
 		ctx := context.Background()
 		t.Parallel()
@@ -427,7 +595,7 @@ func TestComputeSessions(t *testing.T) {
 
 		players[9] = domaintest.NewPlayerBuilder(playerUUID).WithExperience(38_800).FromDB().Fours().WithGamesPlayed(44).Build(start.Add(17 * time.Hour).Add(15 * time.Minute))
 
-		sessions := computeSessions(ctx, players, start, start.Add(24*time.Hour))
+		sessions := computeSessions(ctx, players, start, start.Add(24*time.Hour), app.SessionOptions{})
 
 		expectedSessions := []domain.Session{
 			{
@@ -461,7 +629,7 @@ func TestComputeSessions(t *testing.T) {
 		players[1] = domaintest.NewPlayerBuilder(playerUUID).WithExperience(9_500).FromDB().Fours().WithGamesPlayed(17).Build(start.Add(23 * time.Hour).Add(40 * time.Minute))
 		players[2] = domaintest.NewPlayerBuilder(playerUUID).WithExperience(9_900).FromDB().Fours().WithGamesPlayed(18).Build(start.Add(24 * time.Hour).Add(05 * time.Minute))
 
-		sessions := computeSessions(ctx, players, start, start.Add(24*time.Hour))
+		sessions := computeSessions(ctx, players, start, start.Add(24*time.Hour), app.SessionOptions{})
 
 		expectedSessions := []domain.Session{
 			{
@@ -488,7 +656,7 @@ func TestComputeSessions(t *testing.T) {
 		players[4] = domaintest.NewPlayerBuilder(playerUUID).WithExperience(11_900).FromDB().Fours().WithGamesPlayed(21).Build(start.Add(4 * time.Hour).Add(55 * time.Minute))
 		players[5] = domaintest.NewPlayerBuilder(playerUUID).WithExperience(12_900).FromDB().Fours().WithGamesPlayed(22).Build(start.Add(5 * time.Hour).Add(15 * time.Minute))
 
-		sessions := computeSessions(ctx, players, start, start.Add(24*time.Hour))
+		sessions := computeSessions(ctx, players, start, start.Add(24*time.Hour), app.SessionOptions{})
 
 		expectedSessions := []domain.Session{
 			{
@@ -515,7 +683,7 @@ func TestComputeSessions(t *testing.T) {
 		players[4] = domaintest.NewPlayerBuilder(playerUUID).WithExperience(10_900).FromDB().Fours().WithGamesPlayed(17).Build(start.Add(2 * time.Hour).Add(55 * time.Minute))
 		players[5] = domaintest.NewPlayerBuilder(playerUUID).WithExperience(11_900).FromDB().Fours().WithGamesPlayed(17).Build(start.Add(3 * time.Hour).Add(15 * time.Minute))
 
-		sessions := computeSessions(ctx, players, start, start.Add(24*time.Hour))
+		sessions := computeSessions(ctx, players, start, start.Add(24*time.Hour), app.SessionOptions{})
 
 		expectedSessions := []domain.Session{
 			{
@@ -542,7 +710,7 @@ func TestComputeSessions(t *testing.T) {
 		players[4] = domaintest.NewPlayerBuilder(playerUUID).WithExperience(11_900).FromDB().Fours().WithGamesPlayed(21).Build(start.Add(4 * time.Hour).Add(55 * time.Minute))
 		players[5] = domaintest.NewPlayerBuilder(playerUUID).WithExperience(12_900).FromDB().Fours().WithGamesPlayed(22).Build(start.Add(5 * time.Hour).Add(15 * time.Minute))
 
-		sessions := computeSessions(ctx, players, start, start.Add(24*time.Hour))
+		sessions := computeSessions(ctx, players, start, start.Add(24*time.Hour), app.SessionOptions{})
 
 		expectedSessions := []domain.Session{
 			{
@@ -664,7 +832,7 @@ func TestComputeSessions(t *testing.T) {
 				t.Parallel()
 				nowFunc := func() time.Time { return c.now }
 				computeSessions := app.BuildComputeSessions(nowFunc)
-				got := computeSessions(ctx, c.stats, intervalStart, intervalEnd)
+				got := computeSessions(ctx, c.stats, intervalStart, intervalEnd, app.SessionOptions{})
 				requireEqualSessions(t, c.wantSessions, got)
 			})
 		}
@@ -691,7 +859,7 @@ func TestComputeSessions(t *testing.T) {
 			nowFunc := func() time.Time { return start.Add(5 * time.Minute) }
 			computeSessions := app.BuildComputeSessions(nowFunc)
 
-			sessions := computeSessions(ctx, stats, start.Add(-12*time.Hour), start.Add(12*time.Hour))
+			sessions := computeSessions(ctx, stats, start.Add(-12*time.Hour), start.Add(12*time.Hour), app.SessionOptions{})
 
 			requireEqualSessions(t, []domain.Session{
 				{Start: stats[0], End: stats[1], Consecutive: true, Ongoing: true},
@@ -716,7 +884,7 @@ func TestComputeSessions(t *testing.T) {
 			nowFunc := func() time.Time { return start.Add(-1 * time.Minute) }
 			computeSessions := app.BuildComputeSessions(nowFunc)
 
-			sessions := computeSessions(ctx, stats, start.Add(-12*time.Hour), start.Add(12*time.Hour))
+			sessions := computeSessions(ctx, stats, start.Add(-12*time.Hour), start.Add(12*time.Hour), app.SessionOptions{})
 
 			requireEqualSessions(t, []domain.Session{
 				{Start: stats[0], End: stats[1], Consecutive: true},
@@ -0,0 +1,165 @@
+package app
+
+import (
+	"time"
+
+	"github.com/Amund211/flashlight/internal/domain"
+)
+
+// Streak is the highest streak that ended in a gamemode
+type Streak struct {
+	Highest int
+	// Uncertain is set when games between two stats could have made the
+	// streak longer than Highest
+	Uncertain bool
+	// When is the last time the highest streak was held
+	When time.Time
+}
+
+// GamemodeStreaks holds a streak in each gamemode
+type GamemodeStreaks struct {
+	Overall Streak
+	Solo    Streak
+	Doubles Streak
+	Threes  Streak
+	Fours   Streak
+	Fourv4  Streak
+}
+
+// streakTracker follows a streak of gains in one stat that any gain in
+// another stat breaks. Ongoing streaks don't count towards Highest.
+type streakTracker struct {
+	Highest int `json:"highest"`
+	// HighestMax is the longest the highest streak could have been, when the
+	// order of its games is unknown
+	HighestMax int `json:"highestMax,omitempty"`
+	// HighestHeldAt is the last time the highest streak was held
+	HighestHeldAt time.Time `json:"highestHeldAt"`
+
+	Current       int       `json:"current"`
+	CurrentMax    int       `json:"currentMax,omitempty"`
+	PrevGains     int       `json:"prevGains"`
+	PrevBreaks    int       `json:"prevBreaks"`
+	PrevQueriedAt time.Time `json:"prevQueriedAt"`
+}
+
+func newStreakTracker(gains, breaks int, known *int, queriedAt time.Time) streakTracker {
+	tracker := streakTracker{
+		HighestHeldAt: queriedAt,
+		PrevGains:     gains,
+		PrevBreaks:    breaks,
+		PrevQueriedAt: queriedAt,
+	}
+	if known != nil {
+		tracker.Current = *known
+		tracker.CurrentMax = *known
+	}
+	return tracker
+}
+
+func (s *streakTracker) add(gains, breaks int, known *int, queriedAt time.Time) {
+	current := domain.StreakEstimate{Length: s.Current, MaxLength: max(s.CurrentMax, s.Current)}
+	ended, next := domain.StepStreak(current, gains-s.PrevGains, breaks-s.PrevBreaks, known)
+
+	// Don't count ongoing streaks
+	if ended != nil && ended.Length > s.Highest {
+		s.Highest = ended.Length
+		s.HighestMax = ended.MaxLength
+		s.HighestHeldAt = s.PrevQueriedAt
+	}
+
+	s.Current = next.Length
+	s.CurrentMax = next.MaxLength
+	s.PrevGains = gains
+	s.PrevBreaks = breaks
+	s.PrevQueriedAt = queriedAt
+}
+
+func (s *streakTracker) streak() Streak {
+	return Streak{Highest: s.Highest, Uncertain: s.HighestMax > s.Highest, When: s.HighestHeldAt}
+}
+
+// streakStat picks the stat a streak is made of, the stat that breaks it,
+// and the exact streak when it is known
+type streakStat = func(stats *domain.GamemodeStatsPIT) (gains, breaks int, known *int)
+
+// winsAndLosses uses the winstreak from the API when it is visible, and
+// falls back to reconstructing it from the wins and losses
+func winsAndLosses(stats *domain.GamemodeStatsPIT) (int, int, *int) {
+	return stats.Wins, stats.Losses, stats.Winstreak
+}
+
+func finalKillsAndFinalDeaths(stats *domain.GamemodeStatsPIT) (int, int, *int) {
+	return stats.FinalKills, stats.FinalDeaths, nil
+}
+
+// streakTrackers tracks a streak in each gamemode
+type streakTrackers struct {
+	Overall streakTracker `json:"overall"`
+	Solo    streakTracker `json:"solo"`
+	Doubles streakTracker `json:"doubles"`
+	Threes  streakTracker `json:"threes"`
+	Fours   streakTracker `json:"fours"`
+	Fourv4  streakTracker `json:"4v4"`
+}
+
+func (t *streakTrackers) forEach(player *domain.PlayerPIT, f func(tracker *streakTracker, stats *domain.GamemodeStatsPIT)) {
+	f(&t.Overall, &player.Overall)
+	f(&t.Solo, &player.Solo)
+	f(&t.Doubles, &player.Doubles)
+	f(&t.Threes, &player.Threes)
+	f(&t.Fours, &player.Fours)
+	f(&t.Fourv4, &player.Fourv4)
+}
+
+// newStreakTrackers starts tracking streaks from the stats of firstPlayer
+func newStreakTrackers(firstPlayer *domain.PlayerPIT, stat streakStat) streakTrackers {
+	var trackers streakTrackers
+	trackers.forEach(firstPlayer, func(tracker *streakTracker, stats *domain.GamemodeStatsPIT) {
+		gains, breaks, known := stat(stats)
+		*tracker = newStreakTracker(gains, breaks, known, firstPlayer.QueriedAt)
+	})
+	return trackers
+}
+
+func (t *streakTrackers) add(player *domain.PlayerPIT, stat streakStat) {
+	t.forEach(player, func(tracker *streakTracker, stats *domain.GamemodeStatsPIT) {
+		gains, breaks, known := stat(stats)
+		tracker.add(gains, breaks, known, player.QueriedAt)
+	})
+}
+
+func (t *streakTrackers) streaks() GamemodeStreaks {
+	return GamemodeStreaks{
+		Overall: t.Overall.streak(),
+		Solo:    t.Solo.streak(),
+		Doubles: t.Doubles.streak(),
+		Threes:  t.Threes.streak(),
+		Fours:   t.Fours.streak(),
+		Fourv4:  t.Fourv4.streak(),
+	}
+}
+
+// computeStreaks tracks the streaks of stat through playerPITs
+// Assumes at least one playerPIT exists
+func computeStreaks(playerPITs []domain.PlayerPIT, stat streakStat) GamemodeStreaks {
+	trackers := newStreakTrackers(&playerPITs[0], stat)
+	for i := range playerPITs {
+		trackers.add(&playerPITs[i], stat)
+	}
+	return trackers.streaks()
+}
+
+// ComputeWinstreaks returns the highest ended winstreak in each gamemode
+// through playerPITs, which are sorted by QueriedAt
+// Assumes at least one playerPIT exists
+func ComputeWinstreaks(playerPITs []domain.PlayerPIT) GamemodeStreaks {
+	return computeStreaks(playerPITs, winsAndLosses)
+}
+
+// ComputeFinalKillStreaks returns the highest ended final kill streak in each
+// gamemode through playerPITs, which are sorted by QueriedAt
+// Assumes at least one playerPIT exists
+func ComputeFinalKillStreaks(playerPITs []domain.PlayerPIT) GamemodeStreaks {
+	return computeStreaks(playerPITs, finalKillsAndFinalDeaths)
+}
@@ -0,0 +1,111 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Amund211/flashlight/internal/domain"
+	"github.com/Amund211/flashlight/internal/reporting"
+	"github.com/Amund211/flashlight/internal/strutils"
+)
+
+// GetUsernameHistory returns every username seen on the account, ordered by
+// when it was first seen
+type GetUsernameHistory func(ctx context.Context, uuid string) ([]domain.UsernameObservation, error)
+
+// GetUsernameOwnerAt returns the account that had the username at the given
+// time, which may no longer be the account that has it
+type GetUsernameOwnerAt func(ctx context.Context, username string, at time.Time) (domain.UsernameObservation, error)
+
+type usernameHistoryRepository interface {
+	GetUsernameHistory(ctx context.Context, uuid string) ([]domain.UsernameObservation, error)
+	GetUsernameObservations(ctx context.Context, username string) ([]domain.UsernameObservation, error)
+}
+
+// BuildGetUsernameHistory constructs a GetUsernameHistory. The account is
+// looked up first so the current username is part of the history.
+func BuildGetUsernameHistory(
+	getAccountByUUID GetAccountByUUID,
+	repo usernameHistoryRepository,
+) GetUsernameHistory {
+	return func(ctx context.Context, uuid string) ([]domain.UsernameObservation, error) {
+		if !strutils.UUIDIsNormalized(uuid) {
+			err := fmt.Errorf("UUID is not normalized")
+			reporting.Report(ctx, err, map[string]string{
+				"uuid": uuid,
+			})
+			return nil, err
+		}
+
+		_, err := getAccountByUUID(ctx, uuid)
+		if errors.Is(err, domain.ErrUsernameNotFound) {
+			return nil, err
+		} else if err != nil { //nolint:staticcheck // SA9003: intentionally empty
+			// NOTE: GetAccountByUUID implementations handle their own error reporting
+			// The stored history is still useful without the current username
+		}
+
+		history, err := repo.GetUsernameHistory(ctx, uuid)
+		if err != nil {
+			// NOTE: usernameHistoryRepository implementations handle their own error reporting
+			return nil, fmt.Errorf("failed to get username history: %w", err)
+		}
+
+		if len(history) == 0 {
+			return nil, domain.ErrUsernameNotFound
+		}
+
+		return history, nil
+	}
+}
+
+// BuildGetUsernameOwnerAt constructs a GetUsernameOwnerAt. The username is
+// looked up first so its current owner is part of the history.
+//
+// Names are only observed when looked up, so the owner at a time is the
+// account most recently seen taking the name before then.
+func BuildGetUsernameOwnerAt(
+	getAccountByUsername GetAccountByUsername,
+	repo usernameHistoryRepository,
+) GetUsernameOwnerAt {
+	return func(ctx context.Context, username string, at time.Time) (domain.UsernameObservation, error) {
+		_, err := getAccountByUsername(ctx, username)
+		if err != nil { //nolint:staticcheck // SA9003: intentionally empty
+			// NOTE: GetAccountByUsername implementations handle their own error reporting
+			// The name may be free now, or the provider down - either way
+			// the stored observations can still answer
+		}
+
+		observations, err := repo.GetUsernameObservations(ctx, username)
+		if err != nil {
+			// NOTE: usernameHistoryRepository implementations handle their own error reporting
+			return domain.UsernameObservation{}, fmt.Errorf("failed to get username observations: %w", err)
+		}
+
+		owner, ok := usernameOwnerAt(observations, at)
+		if !ok {
+			return domain.UsernameObservation{}, domain.ErrUsernameNotFound
+		}
+
+		return owner, nil
+	}
+}
+
+// usernameOwnerAt picks the observation first seen most recently at or before
+// at
+func usernameOwnerAt(observations []domain.UsernameObservation, at time.Time) (domain.UsernameObservation, bool) {
+	var owner domain.UsernameObservation
+	found := false
+	for _, observation := range observations {
+		if observation.FirstSeen.After(at) {
+			continue
+		}
+		if !found || observation.FirstSeen.After(owner.FirstSeen) {
+			owner = observation
+			found = true
+		}
+	}
+	return owner, found
+}
@@ -0,0 +1,169 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Amund211/flashlight/internal/app"
+	"github.com/Amund211/flashlight/internal/domain"
+)
+
+type mockUsernameHistoryRepository struct {
+	history      []domain.UsernameObservation
+	observations []domain.UsernameObservation
+	err          error
+}
+
+func (m *mockUsernameHistoryRepository) GetUsernameHistory(ctx context.Context, uuid string) ([]domain.UsernameObservation, error) {
+	return m.history, m.err
+}
+
+func (m *mockUsernameHistoryRepository) GetUsernameObservations(ctx context.Context, username string) ([]domain.UsernameObservation, error) {
+	return m.observations, m.err
+}
+
+func TestBuildGetUsernameHistory(t *testing.T) {
+	t.Parallel()
+
+	uuid := "01234567-89ab-cdef-0123-456789abcdef"
+	t1 := time.Date(2026, time.October, 1, 12, 0, 0, 0, time.UTC)
+	t2 := time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC)
+	history := []domain.UsernameObservation{
+		{UUID: uuid, Username: "Leto", FirstSeen: t1, LastSeen: t1},
+		{UUID: uuid, Username: "MuadDib", FirstSeen: t2, LastSeen: t2},
+	}
+
+	makeGetAccountByUUID := func(err error) (app.GetAccountByUUID, *bool) {
+		called := false
+		return func(ctx context.Context, gotUUID string) (domain.Account, error) {
+			require.Equal(t, uuid, gotUUID)
+			called = true
+			return domain.Account{UUID: uuid, Username: "MuadDib", QueriedAt: t2}, err
+		}, &called
+	}
+
+	t.Run("returns the stored history", func(t *testing.T) {
+		t.Parallel()
+
+		getAccountByUUID, called := makeGetAccountByUUID(nil)
+		getUsernameHistory := app.BuildGetUsernameHistory(getAccountByUUID, &mockUsernameHistoryRepository{history: history})
+
+		result, err := getUsernameHistory(t.Context(), uuid)
+		require.NoError(t, err)
+		require.Equal(t, history, result)
+		require.True(t, *called)
+	})
+
+	t.Run("provider failure falls back to the stored history", func(t *testing.T) {
+		t.Parallel()
+
+		getAccountByUUID, _ := makeGetAccountByUUID(domain.ErrTemporarilyUnavailable)
+		getUsernameHistory := app.BuildGetUsernameHistory(getAccountByUUID, &mockUsernameHistoryRepository{history: history})
+
+		result, err := getUsernameHistory(t.Context(), uuid)
+		require.NoError(t, err)
+		require.Equal(t, history, result)
+	})
+
+	t.Run("unknown account", func(t *testing.T) {
+		t.Parallel()
+
+		getAccountByUUID, _ := makeGetAccountByUUID(domain.ErrUsernameNotFound)
+		getUsernameHistory := app.BuildGetUsernameHistory(getAccountByUUID, &mockUsernameHistoryRepository{history: history})
+
+		_, err := getUsernameHistory(t.Context(), uuid)
+		require.ErrorIs(t, err, domain.ErrUsernameNotFound)
+	})
+
+	t.Run("no stored history", func(t *testing.T) {
+		t.Parallel()
+
+		getAccountByUUID, _ := makeGetAccountByUUID(domain.ErrTemporarilyUnavailable)
+		getUsernameHistory := app.BuildGetUsernameHistory(getAccountByUUID, &mockUsernameHistoryRepository{})
+
+		_, err := getUsernameHistory(t.Context(), uuid)
+		require.ErrorIs(t, err, domain.ErrUsernameNotFound)
+	})
+
+	t.Run("repository failure", func(t *testing.T) {
+		t.Parallel()
+
+		getAccountByUUID, _ := makeGetAccountByUUID(nil)
+		getUsernameHistory := app.BuildGetUsernameHistory(getAccountByUUID, &mockUsernameHistoryRepository{err: assert.AnError})
+
+		_, err := getUsernameHistory(t.Context(), uuid)
+		require.ErrorIs(t, err, assert.AnError)
+	})
+
+	t.Run("invalid uuid", func(t *testing.T) {
+		t.Parallel()
+
+		getAccountByUUID, called := makeGetAccountByUUID(nil)
+		getUsernameHistory := app.BuildGetUsernameHistory(getAccountByUUID, &mockUsernameHistoryRepository{history: history})
+
+		_, err := getUsernameHistory(t.Context(), "0123456789abcdef0123456789abcdef")
+		require.Error(t, err)
+		require.False(t, *called)
+	})
+}
+
+func TestBuildGetUsernameOwnerAt(t *testing.T) {
+	t.Parallel()
+
+	leto := "01234567-89ab-cdef-0123-456789abcdef"
+	ghanima := "fedcba98-7654-3210-fedc-ba9876543210"
+	t1 := time.Date(2026, time.January, 1, 12, 0, 0, 0, time.UTC)
+	t2 := time.Date(2026, time.February, 1, 12, 0, 0, 0, time.UTC)
+	t3 := time.Date(2026, time.June, 1, 12, 0, 0, 0, time.UTC)
+	t4 := time.Date(2026, time.July, 1, 12, 0, 0, 0, time.UTC)
+	observations := []domain.UsernameObservation{
+		{UUID: leto, Username: "MuadDib", FirstSeen: t1, LastSeen: t2},
+		{UUID: ghanima, Username: "muaddib", FirstSeen: t3, LastSeen: t4},
+	}
+
+	getAccountByUsername := func(ctx context.Context, username string) (domain.Account, error) {
+		require.Equal(t, "MuadDib", username)
+		return domain.Account{}, domain.ErrTemporarilyUnavailable
+	}
+
+	getUsernameOwnerAt := app.BuildGetUsernameOwnerAt(getAccountByUsername, &mockUsernameHistoryRepository{observations: observations})
+
+	cases := []struct {
+		name     string
+		at       time.Time
+		expected *domain.UsernameObservation
+	}{
+		{name: "before anyone had it", at: t1.Add(-time.Hour)},
+		{name: "while seen on the first owner", at: t1.Add(time.Hour), expected: &observations[0]},
+		{name: "after the first owner was last seen", at: t2.Add(24 * time.Hour), expected: &observations[0]},
+		{name: "when the second owner was first seen", at: t3, expected: &observations[1]},
+		{name: "after everything", at: t4.Add(24 * time.Hour), expected: &observations[1]},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+
+			owner, err := getUsernameOwnerAt(t.Context(), "MuadDib", c.at)
+			if c.expected == nil {
+				require.ErrorIs(t, err, domain.ErrUsernameNotFound)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, *c.expected, owner)
+		})
+	}
+
+	t.Run("repository failure", func(t *testing.T) {
+		t.Parallel()
+
+		getUsernameOwnerAt := app.BuildGetUsernameOwnerAt(getAccountByUsername, &mockUsernameHistoryRepository{err: assert.AnError})
+
+		_, err := getUsernameOwnerAt(t.Context(), "MuadDib", t4)
+		require.ErrorIs(t, err, assert.AnError)
+	})
+}
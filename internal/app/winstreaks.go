@@ -0,0 +1,57 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/Amund211/flashlight/internal/domain"
+	"github.com/Amund211/flashlight/internal/reporting"
+	"github.com/Amund211/flashlight/internal/strutils"
+)
+
+type GetWinstreaks = func(
+	ctx context.Context,
+	uuid string,
+	start, end time.Time,
+	gamemode domain.Gamemode,
+) ([]domain.ReconstructedWinstreak, error)
+
+// BuildGetWinstreaks constructs a GetWinstreaks that reconstructs the
+// winstreaks in the gamemode from the stats in [start, end]
+func BuildGetWinstreaks(getPlayerPITs GetPlayerPITs) GetWinstreaks {
+	return func(ctx context.Context, uuid string, start, end time.Time, gamemode domain.Gamemode) ([]domain.ReconstructedWinstreak, error) {
+		if !strutils.UUIDIsNormalized(uuid) {
+			err := fmt.Errorf("UUID is not normalized")
+			reporting.Report(ctx, err)
+			return nil, err
+		}
+
+		if start.After(end) {
+			err := fmt.Errorf("start time is after end time")
+			reporting.Report(ctx, err)
+			return nil, err
+		}
+
+		if _, ok := (&domain.PlayerPIT{}).GamemodeStats(gamemode); !ok {
+			err := fmt.Errorf("unknown gamemode in app.GetWinstreaks")
+			reporting.Report(ctx, err, map[string]string{
+				"gamemode": string(gamemode),
+			})
+			return nil, err
+		}
+
+		stats, err := getPlayerPITs(ctx, uuid, start, end)
+		if err != nil {
+			// NOTE: GetPlayerPITs implementations handle their own error reporting
+			return nil, fmt.Errorf("failed to get player pits: %w", err)
+		}
+
+		slices.SortStableFunc(stats, func(a, b domain.PlayerPIT) int {
+			return a.QueriedAt.Compare(b.QueriedAt)
+		})
+
+		return domain.ReconstructWinstreaks(stats, gamemode), nil
+	}
+}
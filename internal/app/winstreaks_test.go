@@ -0,0 +1,85 @@
+package app_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Amund211/flashlight/internal/app"
+	"github.com/Amund211/flashlight/internal/domain"
+	"github.com/Amund211/flashlight/internal/domaintest"
+)
+
+func TestBuildGetWinstreaks(t *testing.T) {
+	t.Parallel()
+
+	uuid := "01234567-89ab-cdef-0123-456789abcdef"
+	start := time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC)
+	end := start.Add(24 * time.Hour)
+
+	fours := func(wins, losses int, queriedAt time.Time) domain.PlayerPIT {
+		return domaintest.NewPlayerBuilder(uuid).FromDB().Fours().WithWins(wins).WithLosses(losses).Build(queriedAt)
+	}
+
+	t.Run("stats are sorted before reconstructing", func(t *testing.T) {
+		t.Parallel()
+
+		var gotStart, gotEnd time.Time
+		getWinstreaks := app.BuildGetWinstreaks(func(ctx context.Context, _ string, start, end time.Time) ([]domain.PlayerPIT, error) {
+			gotStart = start
+			gotEnd = end
+			return []domain.PlayerPIT{
+				fours(13, 5, start.Add(3*time.Hour)),
+				fours(10, 5, start.Add(1*time.Hour)),
+				fours(13, 6, start.Add(4*time.Hour)),
+				fours(12, 5, start.Add(2*time.Hour)),
+			}, nil
+		})
+
+		winstreaks, err := getWinstreaks(t.Context(), uuid, start, end, domain.GamemodeFours)
+		require.NoError(t, err)
+		require.Equal(t, []domain.ReconstructedWinstreak{
+			{
+				StreakEstimate: domain.StreakEstimate{Length: 3, MaxLength: 3},
+				StartedAfter:   new(start.Add(1 * time.Hour)),
+				HeldAt:         start.Add(3 * time.Hour),
+				EndedBefore:    new(start.Add(4 * time.Hour)),
+			},
+		}, winstreaks)
+
+		require.Equal(t, start, gotStart)
+		require.Equal(t, end, gotEnd)
+	})
+
+	t.Run("invalid arguments", func(t *testing.T) {
+		t.Parallel()
+
+		getWinstreaks := app.BuildGetWinstreaks(func(context.Context, string, time.Time, time.Time) ([]domain.PlayerPIT, error) {
+			require.False(t, true, "GetPlayerPITs should not have been called")
+			return nil, nil
+		})
+
+		_, err := getWinstreaks(t.Context(), "0123456789abcdef0123456789abcdef", start, end, domain.GamemodeFours)
+		require.Error(t, err)
+
+		_, err = getWinstreaks(t.Context(), uuid, end, start, domain.GamemodeFours)
+		require.Error(t, err)
+
+		_, err = getWinstreaks(t.Context(), uuid, start, end, domain.Gamemode("eights"))
+		require.Error(t, err)
+	})
+
+	t.Run("errors are propagated", func(t *testing.T) {
+		t.Parallel()
+
+		getWinstreaks := app.BuildGetWinstreaks(func(context.Context, string, time.Time, time.Time) ([]domain.PlayerPIT, error) {
+			return nil, domain.ErrTemporarilyUnavailable
+		})
+
+		_, err := getWinstreaks(t.Context(), uuid, start, end, domain.GamemodeFours)
+		require.True(t, errors.Is(err, domain.ErrTemporarilyUnavailable))
+	})
+}
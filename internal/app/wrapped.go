@@ -0,0 +1,292 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/Amund211/flashlight/internal/domain"
+	"github.com/Amund211/flashlight/internal/logging"
+	"github.com/Amund211/flashlight/internal/reporting"
+)
+
+// wrappedComputationVersion is part of the key of stored wrapped states.
+// Bump it whenever the stored state changes, so nothing computed by an older
+// version is used.
+const wrappedComputationVersion = 3
+
+// Wrapped is a player's sessions and stats through a year
+type Wrapped struct {
+	// Sessions are the consecutive sessions
+	Sessions               []domain.Session
+	NonConsecutiveSessions int
+
+	// YearStart and YearEnd are the first and last stats in the year. Nil
+	// when there are none.
+	YearStart *domain.PlayerPIT
+	YearEnd   *domain.PlayerPIT
+
+	// Winstreaks and FinalKillStreaks are through the year and the padding
+	// around it. Nil when there are no stats.
+	Winstreaks       *GamemodeStreaks
+	FinalKillStreaks *GamemodeStreaks
+}
+
+// GetWrapped returns the player's wrapped for the year
+type GetWrapped = func(ctx context.Context, uuid string, year int) (Wrapped, error)
+
+type wrappedRepository interface {
+	GetWrapped(ctx context.Context, playerUUID string, year int, version int) (domain.StoredWrapped, error)
+	StoreWrapped(ctx context.Context, wrapped domain.StoredWrapped) error
+}
+
+// wrappedState is everything needed to compute the wrapped for a year, folded
+// from the player stats up to ResumeAt. Stats from ResumeAt and on can be
+// added later to continue the computation.
+type wrappedState struct {
+	// ResumeAt is the start of the first session that could still change
+	ResumeAt time.Time `json:"resumeAt"`
+
+	// Sessions are the consecutive sessions
+	Sessions               []wrappedStateSession `json:"sessions"`
+	NonConsecutiveSessions int                   `json:"nonConsecutiveSessions"`
+
+	YearStart *domain.PlayerPIT `json:"yearStart,omitempty"`
+	YearEnd   *domain.PlayerPIT `json:"yearEnd,omitempty"`
+
+	Winstreaks       *streakTrackers `json:"winstreaks,omitempty"`
+	FinalKillStreaks *streakTrackers `json:"finalKillStreaks,omitempty"`
+	// FoldedThrough is the time of the last stats added to the streaks and
+	// the year boundary
+	FoldedThrough time.Time `json:"foldedThrough"`
+}
+
+type wrappedStateSession struct {
+	Start    domain.PlayerPIT `json:"start"`
+	End      domain.PlayerPIT `json:"end"`
+	Gamemode domain.Gamemode  `json:"gamemode"`
+	Ongoing  bool             `json:"ongoing,omitempty"`
+}
+
+func (s wrappedState) clone() wrappedState {
+	s.Sessions = slices.Clone(s.Sessions)
+	if s.Winstreaks != nil {
+		s.Winstreaks = new(*s.Winstreaks)
+	}
+	if s.FinalKillStreaks != nil {
+		s.FinalKillStreaks = new(*s.FinalKillStreaks)
+	}
+	return s
+}
+
+func (s *wrappedState) addSessions(sessions []domain.Session) {
+	for _, session := range sessions {
+		if !session.Consecutive {
+			s.NonConsecutiveSessions++
+			continue
+		}
+
+		s.Sessions = append(s.Sessions, wrappedStateSession{
+			Start:    session.Start,
+			End:      session.End,
+			Gamemode: session.Gamemode,
+			Ongoing:  session.Ongoing,
+		})
+	}
+}
+
+// addPlayerPITs folds the stats into the streaks and the year boundary.
+// Stats at or before FoldedThrough have already been added and are skipped.
+func (s *wrappedState) addPlayerPITs(playerPITs []domain.PlayerPIT, year int) {
+	yearStart := time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
+	nextYearStart := time.Date(year+1, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for i := range playerPITs {
+		pit := &playerPITs[i]
+		if !s.FoldedThrough.IsZero() && !pit.QueriedAt.After(s.FoldedThrough) {
+			continue
+		}
+
+		if s.Winstreaks == nil {
+			s.Winstreaks = new(newStreakTrackers(pit, winsAndLosses))
+		}
+		if s.FinalKillStreaks == nil {
+			s.FinalKillStreaks = new(newStreakTrackers(pit, finalKillsAndFinalDeaths))
+		}
+		s.Winstreaks.add(pit, winsAndLosses)
+		s.FinalKillStreaks.add(pit, finalKillsAndFinalDeaths)
+
+		if !pit.QueriedAt.Before(yearStart) && pit.QueriedAt.Before(nextYearStart) {
+			if s.YearStart == nil {
+				s.YearStart = new(*pit)
+			}
+			if s.YearEnd == nil || pit.QueriedAt.After(s.YearEnd.QueriedAt) {
+				s.YearEnd = new(*pit)
+			}
+		}
+
+		s.FoldedThrough = pit.QueriedAt
+	}
+}
+
+// advance adds the player stats from ResumeAt and on to the state. closed only
+// contains what later stats can't change, while current contains everything.
+// When final, no more stats will be added, and closed equals current.
+func (s wrappedState) advance(ctx context.Context, computeSessions ComputeSessions, playerPITs []domain.PlayerPIT, year int, final bool) (closed, current wrappedState) {
+	yearStart := time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
+	yearEnd := time.Date(year+1, 1, 1, 0, 0, 0, 0, time.UTC).Add(-time.Nanosecond)
+
+	resuming := !s.ResumeAt.IsZero()
+	start := yearStart
+	if resuming {
+		start = s.ResumeAt
+	}
+
+	// NOTE: Sorts playerPITs
+	sessions := computeSessions(ctx, playerPITs, start, yearEnd, SessionOptions{})
+	if resuming {
+		sessions = slices.DeleteFunc(sessions, func(session domain.Session) bool {
+			return session.Start.QueriedAt.Before(s.ResumeAt)
+		})
+	}
+
+	// The last session could be extended by later stats
+	closedCount := len(sessions)
+	if !final && closedCount > 0 {
+		closedCount--
+	}
+
+	resumeAt := s.ResumeAt
+	if closedCount < len(sessions) {
+		resumeAt = sessions[closedCount].Start.QueriedAt
+	}
+
+	closed = s.clone()
+	closed.addSessions(sessions[:closedCount])
+	if final {
+		closed.addPlayerPITs(playerPITs, year)
+	} else {
+		closedPITs := slices.DeleteFunc(slices.Clone(playerPITs), func(pit domain.PlayerPIT) bool {
+			return !pit.QueriedAt.Before(resumeAt)
+		})
+		closed.addPlayerPITs(closedPITs, year)
+	}
+	closed.ResumeAt = resumeAt
+
+	current = closed.clone()
+	current.addSessions(sessions[closedCount:])
+	current.addPlayerPITs(playerPITs, year)
+
+	return closed, current
+}
+
+func (s *wrappedState) toWrapped() Wrapped {
+	sessions := make([]domain.Session, 0, len(s.Sessions))
+	for i := range s.Sessions {
+		stateSession := &s.Sessions[i]
+		sessions = append(sessions, domain.Session{
+			Start:       stateSession.Start,
+			End:         stateSession.End,
+			Consecutive: true,
+			Ongoing:     stateSession.Ongoing,
+			Gamemode:    stateSession.Gamemode,
+			Deltas:      domain.NewSessionDeltas(&stateSession.Start, &stateSession.End),
+		})
+	}
+
+	wrapped := Wrapped{
+		Sessions:               sessions,
+		NonConsecutiveSessions: s.NonConsecutiveSessions,
+		YearStart:              s.YearStart,
+		YearEnd:                s.YearEnd,
+	}
+	if s.Winstreaks != nil {
+		wrapped.Winstreaks = new(s.Winstreaks.streaks())
+	}
+	if s.FinalKillStreaks != nil {
+		wrapped.FinalKillStreaks = new(s.FinalKillStreaks.streaks())
+	}
+	return wrapped
+}
+
+// BuildGetWrapped returns a GetWrapped that stores its results. Completed
+// years are served from storage. For the ongoing year the sessions that can't
+// change are stored, so only the stats after them are loaded on later
+// requests.
+func BuildGetWrapped(
+	getPlayerPITs GetPlayerPITs,
+	computeSessions ComputeSessions,
+	repo wrappedRepository,
+	nowFunc func() time.Time,
+) GetWrapped {
+	return func(ctx context.Context, uuid string, year int) (Wrapped, error) {
+		ctx = reporting.AddExtrasToContext(ctx, map[string]string{
+			"wrappedVersion": strconv.Itoa(wrappedComputationVersion),
+		})
+
+		var state wrappedState
+		stored, err := repo.GetWrapped(ctx, uuid, year, wrappedComputationVersion)
+		switch {
+		case errors.Is(err, domain.ErrWrappedNotFound):
+		case err != nil:
+			// NOTE: The repository reports its own errors
+			logging.FromContext(ctx).WarnContext(ctx, "Failed to get stored wrapped", "error", err.Error())
+		default:
+			if err := json.Unmarshal(stored.Data, &state); err != nil {
+				reporting.Report(ctx, fmt.Errorf("failed to unmarshal stored wrapped state: %w", err))
+				state = wrappedState{}
+			} else if stored.Complete {
+				return state.toWrapped(), nil
+			}
+		}
+
+		// NOTE: 24-hour padding to ensure we can complete sessions at year boundaries
+		playerPITsStart := time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC).Add(-24 * time.Hour)
+		playerPITsEnd := time.Date(year+1, 1, 1, 0, 0, 0, 0, time.UTC).Add(-time.Nanosecond).Add(24 * time.Hour)
+		if !state.ResumeAt.IsZero() {
+			playerPITsStart = state.ResumeAt
+		}
+
+		// The padded interval is over, so no more stats will be added
+		now := nowFunc()
+		final := now.After(playerPITsEnd)
+
+		playerPITs, err := getPlayerPITs(ctx, uuid, playerPITsStart, playerPITsEnd)
+		if err != nil {
+			// NOTE: GetPlayerPITs implementations handle their own error reporting
+			return Wrapped{}, fmt.Errorf("failed to get player stats: %w", err)
+		}
+
+		closed, current := state.advance(ctx, computeSessions, playerPITs, year, final)
+
+		if !final && closed.ResumeAt.Equal(state.ResumeAt) {
+			// Nothing new was closed
+			return current.toWrapped(), nil
+		}
+
+		data, err := json.Marshal(closed)
+		if err != nil {
+			reporting.Report(ctx, fmt.Errorf("failed to marshal wrapped state: %w", err))
+			return current.toWrapped(), nil
+		}
+
+		err = repo.StoreWrapped(ctx, domain.StoredWrapped{
+			UUID:       uuid,
+			Year:       year,
+			Version:    wrappedComputationVersion,
+			Complete:   final,
+			Data:       data,
+			ComputedAt: now,
+		})
+		if err != nil {
+			// NOTE: The repository reports its own errors
+			logging.FromContext(ctx).WarnContext(ctx, "Failed to store wrapped", "error", err.Error())
+		}
+
+		return current.toWrapped(), nil
+	}
+}
@@ -0,0 +1,105 @@
+package app
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Amund211/flashlight/internal/domain"
+	"github.com/Amund211/flashlight/internal/domaintest"
+)
+
+func TestWrappedStateAdvance(t *testing.T) {
+	t.Parallel()
+
+	playerUUID := domaintest.NewUUID(t)
+
+	pb := domaintest.NewPlayerBuilder(playerUUID).FromDB().Fours()
+	gamesPlayed, wins, losses := 0, 0, 0
+	playerPITs := []domain.PlayerPIT{}
+	addSession := func(start time.Time, outcomes string) {
+		playerPITs = append(playerPITs, pb.Build(start))
+		for i, outcome := range outcomes {
+			switch outcome {
+			case 'w':
+				wins++
+				gamesPlayed++
+			case 'l':
+				losses++
+				gamesPlayed++
+			case 's':
+				// Skip ahead without the games in between -> not consecutive
+				gamesPlayed += 5
+				wins += 5
+			}
+			pb.WithGamesPlayed(gamesPlayed).WithWins(wins).WithLosses(losses).WithExperience(int64(500 + 100*gamesPlayed))
+			playerPITs = append(playerPITs, pb.Build(start.Add(time.Duration(i+1)*15*time.Minute)))
+		}
+		// Idle at the end of the session
+		playerPITs = append(playerPITs, pb.Build(start.Add(time.Duration(len(outcomes))*15*time.Minute+5*time.Minute)))
+	}
+
+	// Spans the start of the year
+	addSession(time.Date(2023, time.December, 31, 23, 0, 0, 0, time.UTC), "wwlw")
+	addSession(time.Date(2024, time.February, 3, 18, 0, 0, 0, time.UTC), "wwwwl")
+	addSession(time.Date(2024, time.February, 3, 22, 0, 0, 0, time.UTC), "lwws")
+	addSession(time.Date(2024, time.June, 14, 9, 0, 0, 0, time.UTC), "wlwwwwwwl")
+	addSession(time.Date(2024, time.June, 15, 9, 0, 0, 0, time.UTC), "ww")
+	addSession(time.Date(2024, time.October, 1, 20, 0, 0, 0, time.UTC), "llwl")
+	// Spans the end of the year
+	addSession(time.Date(2024, time.December, 31, 23, 30, 0, 0, time.UTC), "wwwl")
+
+	computeSessions := BuildComputeSessions(func() time.Time {
+		return time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC)
+	})
+
+	_, full := wrappedState{}.advance(t.Context(), computeSessions, slices.Clone(playerPITs), 2024, true)
+	want := full.toWrapped()
+	require.Len(t, want.Sessions, 6)
+	require.Equal(t, 1, want.NonConsecutiveSessions)
+	require.NotNil(t, want.Winstreaks)
+
+	// Advance through the year, as if requested at each of these times
+	requestTimes := []time.Time{
+		time.Date(2024, time.January, 1, 0, 30, 0, 0, time.UTC),
+		time.Date(2024, time.February, 3, 19, 0, 0, 0, time.UTC),
+		time.Date(2024, time.February, 3, 22, 30, 0, 0, time.UTC),
+		time.Date(2024, time.June, 14, 10, 0, 0, 0, time.UTC),
+		time.Date(2024, time.August, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, time.December, 31, 23, 45, 0, 0, time.UTC),
+	}
+
+	var state wrappedState
+	for _, requestTime := range requestTimes {
+		from := time.Date(2023, time.December, 31, 0, 0, 0, 0, time.UTC)
+		if !state.ResumeAt.IsZero() {
+			from = state.ResumeAt
+		}
+		available := []domain.PlayerPIT{}
+		for _, pit := range playerPITs {
+			if !pit.QueriedAt.Before(from) && !pit.QueriedAt.After(requestTime) {
+				available = append(available, pit)
+			}
+		}
+
+		closed, _ := state.advance(t.Context(), computeSessions, available, 2024, false)
+
+		// Stored and loaded between requests
+		marshalled, err := json.Marshal(closed)
+		require.NoError(t, err)
+		state = wrappedState{}
+		require.NoError(t, json.Unmarshal(marshalled, &state))
+	}
+
+	remaining := []domain.PlayerPIT{}
+	for _, pit := range playerPITs {
+		if !pit.QueriedAt.Before(state.ResumeAt) {
+			remaining = append(remaining, pit)
+		}
+	}
+	_, current := state.advance(t.Context(), computeSessions, remaining, 2024, true)
+	require.Equal(t, want, current.toWrapped())
+}
@@ -0,0 +1,194 @@
+package app_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Amund211/flashlight/internal/app"
+	"github.com/Amund211/flashlight/internal/domain"
+	"github.com/Amund211/flashlight/internal/domaintest"
+)
+
+type wrappedKey struct {
+	uuid    string
+	year    int
+	version int
+}
+
+type memoryWrappedRepository struct {
+	mu       sync.Mutex
+	wrapped  map[wrappedKey]domain.StoredWrapped
+	getErr   error
+	storeErr error
+}
+
+func newMemoryWrappedRepository() *memoryWrappedRepository {
+	return &memoryWrappedRepository{wrapped: map[wrappedKey]domain.StoredWrapped{}}
+}
+
+func (r *memoryWrappedRepository) GetWrapped(ctx context.Context, playerUUID string, year int, version int) (domain.StoredWrapped, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.getErr != nil {
+		return domain.StoredWrapped{}, r.getErr
+	}
+	wrapped, ok := r.wrapped[wrappedKey{playerUUID, year, version}]
+	if !ok {
+		return domain.StoredWrapped{}, domain.ErrWrappedNotFound
+	}
+	return wrapped, nil
+}
+
+func (r *memoryWrappedRepository) StoreWrapped(ctx context.Context, wrapped domain.StoredWrapped) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.storeErr != nil {
+		return r.storeErr
+	}
+	r.wrapped[wrappedKey{wrapped.UUID, wrapped.Year, wrapped.Version}] = wrapped
+	return nil
+}
+
+func (r *memoryWrappedRepository) stored(t *testing.T) []domain.StoredWrapped {
+	t.Helper()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := make([]domain.StoredWrapped, 0, len(r.wrapped))
+	for _, wrapped := range r.wrapped {
+		stored = append(stored, wrapped)
+	}
+	return stored
+}
+
+func TestBuildGetWrapped(t *testing.T) {
+	t.Parallel()
+
+	uuid := "01234567-89ab-cdef-0123-456789abcdef"
+
+	type getPlayerPITsCall struct {
+		start, end time.Time
+	}
+
+	// Returns the stats in the requested interval, recording the calls
+	makeGetPlayerPITs := func(playerPITs []domain.PlayerPIT) (app.GetPlayerPITs, *[]getPlayerPITsCall) {
+		calls := []getPlayerPITsCall{}
+		return func(ctx context.Context, _ string, start, end time.Time) ([]domain.PlayerPIT, error) {
+			calls = append(calls, getPlayerPITsCall{start: start, end: end})
+			inInterval := []domain.PlayerPIT{}
+			for _, pit := range playerPITs {
+				if !pit.QueriedAt.Before(start) && !pit.QueriedAt.After(end) {
+					inInterval = append(inInterval, pit)
+				}
+			}
+			return inInterval, nil
+		}, &calls
+	}
+
+	session := func(start time.Time, gamesPlayed int) []domain.PlayerPIT {
+		pb := domaintest.NewPlayerBuilder(uuid).FromDB().Fours()
+		return []domain.PlayerPIT{
+			pb.WithGamesPlayed(gamesPlayed).WithExperience(int64(gamesPlayed) * 100).Build(start),
+			pb.WithGamesPlayed(gamesPlayed + 1).WithExperience(int64(gamesPlayed+1) * 100).Build(start.Add(20 * time.Minute)),
+			pb.WithGamesPlayed(gamesPlayed + 2).WithExperience(int64(gamesPlayed+2) * 100).Build(start.Add(40 * time.Minute)),
+		}
+	}
+
+	t.Run("completed year is computed once", func(t *testing.T) {
+		t.Parallel()
+
+		now := time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)
+		playerPITs := append(
+			session(time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC), 0),
+			session(time.Date(2024, time.April, 1, 12, 0, 0, 0, time.UTC), 2)...,
+		)
+		getPlayerPITs, calls := makeGetPlayerPITs(playerPITs)
+		repo := newMemoryWrappedRepository()
+		getWrapped := app.BuildGetWrapped(getPlayerPITs, app.BuildComputeSessions(func() time.Time { return now }), repo, func() time.Time { return now })
+
+		first, err := getWrapped(t.Context(), uuid, 2024)
+		require.NoError(t, err)
+		require.Len(t, *calls, 1)
+		require.True(t, time.Date(2023, time.December, 31, 0, 0, 0, 0, time.UTC).Equal((*calls)[0].start))
+
+		stored := repo.stored(t)
+		require.Len(t, stored, 1)
+		require.True(t, stored[0].Complete)
+		require.Len(t, first.Sessions, 2)
+
+		second, err := getWrapped(t.Context(), uuid, 2024)
+		require.NoError(t, err)
+		require.Len(t, *calls, 1)
+		require.Equal(t, first, second)
+	})
+
+	t.Run("ongoing year resumes from the last session", func(t *testing.T) {
+		t.Parallel()
+
+		secondSessionStart := time.Date(2024, time.April, 1, 12, 0, 0, 0, time.UTC)
+		playerPITs := append(
+			session(time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC), 0),
+			session(secondSessionStart, 2)...,
+		)
+		now := secondSessionStart.Add(time.Hour)
+		getPlayerPITs, calls := makeGetPlayerPITs(playerPITs)
+		repo := newMemoryWrappedRepository()
+		computeSessions := app.BuildComputeSessions(func() time.Time { return now })
+		getWrapped := app.BuildGetWrapped(getPlayerPITs, computeSessions, repo, func() time.Time { return now })
+
+		first, err := getWrapped(t.Context(), uuid, 2024)
+		require.NoError(t, err)
+
+		stored := repo.stored(t)
+		require.Len(t, stored, 1)
+		require.False(t, stored[0].Complete)
+
+		second, err := getWrapped(t.Context(), uuid, 2024)
+		require.NoError(t, err)
+		require.Len(t, *calls, 2)
+		require.True(t, secondSessionStart.Equal((*calls)[1].start))
+		require.Equal(t, first, second)
+
+		// The same as computing it in one go
+		fresh, err := app.BuildGetWrapped(getPlayerPITs, computeSessions, newMemoryWrappedRepository(), func() time.Time { return now })(t.Context(), uuid, 2024)
+		require.NoError(t, err)
+		require.Equal(t, fresh, second)
+	})
+
+	t.Run("repository errors are not fatal", func(t *testing.T) {
+		t.Parallel()
+
+		now := time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)
+		getPlayerPITs, calls := makeGetPlayerPITs(session(time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC), 0))
+		repo := newMemoryWrappedRepository()
+		repo.getErr = errors.New("db down")
+		repo.storeErr = errors.New("db down")
+		getWrapped := app.BuildGetWrapped(getPlayerPITs, app.BuildComputeSessions(func() time.Time { return now }), repo, func() time.Time { return now })
+
+		_, err := getWrapped(t.Context(), uuid, 2024)
+		require.NoError(t, err)
+		require.Len(t, *calls, 1)
+	})
+
+	t.Run("failing to get stats", func(t *testing.T) {
+		t.Parallel()
+
+		now := time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)
+		repo := newMemoryWrappedRepository()
+		getPlayerPITs := func(ctx context.Context, _ string, _, _ time.Time) ([]domain.PlayerPIT, error) {
+			return nil, domain.ErrTemporarilyUnavailable
+		}
+		getWrapped := app.BuildGetWrapped(getPlayerPITs, app.BuildComputeSessions(func() time.Time { return now }), repo, func() time.Time { return now })
+
+		_, err := getWrapped(t.Context(), uuid, 2024)
+		require.ErrorIs(t, err, domain.ErrTemporarilyUnavailable)
+		require.Empty(t, repo.stored(t))
+	})
+}
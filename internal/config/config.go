@@ -36,6 +36,13 @@ type Config struct {
 	// first one signs; the rest are still accepted, which is how a key is
 	// rotated without invalidating outstanding challenges. Secret.
 	authChallengeSigningKeys []string
+	// adminAPIKeys unlock the /v1/admin endpoints, newline-delimited.
+	// Optional: without any the admin endpoints reject every request.
+	// Secret.
+	adminAPIKeys []string
+	// prismReleasesRepository is the GitHub repository, as owner/name, whose
+	// releases feed prism versions are discovered from
+	prismReleasesRepository string
 }
 
 func (c *Config) CloudSQLUnixSocketPath() string {
@@ -98,6 +105,14 @@ func (c *Config) AuthChallengeSigningKeys() []string {
 	return c.authChallengeSigningKeys
 }
 
+func (c *Config) AdminAPIKeys() []string {
+	return c.adminAPIKeys
+}
+
+func (c *Config) PrismReleasesRepository() string {
+	return c.prismReleasesRepository
+}
+
 // Return a string representation suitable for logging etc
 func (c *Config) NonSensitiveString() string {
 	return fmt.Sprintf("Config{env: %s, port: %s ...}", string(c.env), c.port)
@@ -193,6 +208,13 @@ func ConfigFromEnv() (Config, error) {
 	if requireEnv && len(authChallengeSigningKeys) == 0 {
 		return missingKey("AUTH_CHALLENGE_SIGNING_KEYS")
 	}
+	adminAPIKeys, _ := lookupNewlineDelimitedEnv("ADMIN_API_KEYS")
+
+	prismReleasesRepository := "Amund211/prism"
+	rawPrismReleasesRepository, ok := os.LookupEnv("PRISM_RELEASES_REPOSITORY")
+	if ok {
+		prismReleasesRepository = rawPrismReleasesRepository
+	}
 
 	return Config{
 		cloudSQLUnixSocketPath: cloudSQLUnixSocketPath,
@@ -209,6 +231,8 @@ func ConfigFromEnv() (Config, error) {
 		blockedIPsSHA256Hex:    blockedIPsSHA256Hex,
 
 		authChallengeSigningKeys: authChallengeSigningKeys,
+		adminAPIKeys:             adminAPIKeys,
+		prismReleasesRepository:  prismReleasesRepository,
 	}, nil
 }
 
@@ -170,6 +170,36 @@ func TestGetConfig(t *testing.T) {
 			"the first key signs and the rest are only accepted, so the order is load-bearing for rotation")
 	})
 
+	t.Run("admin api keys are optional", func(t *testing.T) {
+		for _, variable := range allVariablesExceptEnv {
+			t.Setenv(variable, "placeholder_value")
+		}
+		t.Setenv("FLASHLIGHT_ENVIRONMENT", string(production))
+
+		conf, err := config.ConfigFromEnv()
+		require.NoError(t, err)
+		require.Empty(t, conf.AdminAPIKeys())
+
+		t.Setenv("ADMIN_API_KEYS", "first\n# rotated out: old\nsecond\n")
+		conf, err = config.ConfigFromEnv()
+		require.NoError(t, err)
+		require.Equal(t, []string{"first", "second"}, conf.AdminAPIKeys())
+		require.NotContains(t, conf.NonSensitiveString(), "first")
+	})
+
+	t.Run("prism releases repository defaults to prism", func(t *testing.T) {
+		t.Setenv("FLASHLIGHT_ENVIRONMENT", string(development))
+
+		conf, err := config.ConfigFromEnv()
+		require.NoError(t, err)
+		require.Equal(t, "Amund211/prism", conf.PrismReleasesRepository())
+
+		t.Setenv("PRISM_RELEASES_REPOSITORY", "someone/prism-fork")
+		conf, err = config.ConfigFromEnv()
+		require.NoError(t, err)
+		require.Equal(t, "someone/prism-fork", conf.PrismReleasesRepository())
+	})
+
 	t.Run("blocked IPs, user agents, and user ids are parsed correctly", func(t *testing.T) {
 		// Set all variables
 		for _, variable := range allVariablesExceptEnv {
@@ -7,3 +7,12 @@ type Account struct {
 	Username  string
 	QueriedAt time.Time
 }
+
+// UsernameObservation is a username we have seen on an account. FirstSeen and
+// LastSeen are the first and last time we saw the account with that username.
+type UsernameObservation struct {
+	UUID      string
+	Username  string
+	FirstSeen time.Time
+	LastSeen  time.Time
+}
@@ -0,0 +1,93 @@
+package domain
+
+// Game names a Hypixel game flashlight tracks stats for
+type Game string
+
+const (
+	GameBedwars Game = "bedwars"
+	GameSkyWars Game = "skywars"
+	GameDuels   Game = "duels"
+)
+
+// GameModeOverall is the mode holding a player's stats across every mode of a
+// game. The other modes are specific to each game.
+const GameModeOverall = "overall"
+
+const (
+	SkyWarsModeSolo  = "solo"
+	SkyWarsModeTeams = "teams"
+)
+
+const (
+	DuelsModeUHC     = "uhc"
+	DuelsModeSkyWars = "skywars"
+	DuelsModeClassic = "classic"
+	DuelsModeBridge  = "bridge"
+	DuelsModeSumo    = "sumo"
+	DuelsModeOP      = "op"
+	DuelsModeBow     = "bow"
+)
+
+// GameStatsPIT is a game-agnostic view of a player's stats in one game. It
+// maps each mode the player has played, and always GameModeOverall, to the
+// player's stats in it. A stat is missing when the game doesn't track it in
+// that mode, or the player hides it, like winstreaks in the Hypixel API.
+type GameStatsPIT map[string]GameModeStatsPIT
+
+// GameModeStatsPIT is a player's stats in one mode of a game
+type GameModeStatsPIT map[Stat]int
+
+// Stat returns the value of stat in mode, and false if it is missing
+func (s GameStatsPIT) Stat(mode string, stat Stat) (int, bool) {
+	value, ok := s[mode][stat]
+	return value, ok
+}
+
+func gamemodeStatsToGameModeStats(stats *GamemodeStatsPIT) GameModeStatsPIT {
+	modeStats := GameModeStatsPIT{
+		StatGamesPlayed: stats.GamesPlayed,
+		StatWins:        stats.Wins,
+		StatLosses:      stats.Losses,
+		StatBedsBroken:  stats.BedsBroken,
+		StatBedsLost:    stats.BedsLost,
+		StatFinalKills:  stats.FinalKills,
+		StatFinalDeaths: stats.FinalDeaths,
+		StatKills:       stats.Kills,
+		StatDeaths:      stats.Deaths,
+	}
+	if stats.Winstreak != nil {
+		modeStats[StatWinstreak] = *stats.Winstreak
+	}
+	return modeStats
+}
+
+// BedwarsStats returns the player's bedwars stats as a GameStatsPIT. The
+// modes are the gamemodes and the dream modes the player has played, and the
+// experience is included in GameModeOverall.
+func (p *PlayerPIT) BedwarsStats() GameStatsPIT {
+	stats := GameStatsPIT{
+		string(GamemodeSolo):    gamemodeStatsToGameModeStats(&p.Solo),
+		string(GamemodeDoubles): gamemodeStatsToGameModeStats(&p.Doubles),
+		string(GamemodeThrees):  gamemodeStatsToGameModeStats(&p.Threes),
+		string(GamemodeFours):   gamemodeStatsToGameModeStats(&p.Fours),
+		string(GamemodeFourv4):  gamemodeStatsToGameModeStats(&p.Fourv4),
+		GameModeOverall:         gamemodeStatsToGameModeStats(&p.Overall),
+	}
+	stats[GameModeOverall][StatExperience] = int(p.Experience)
+
+	for mode, modeStats := range p.DreamModes {
+		stats[string(mode)] = gamemodeStatsToGameModeStats(&modeStats)
+	}
+
+	return stats
+}
+
+// GameStats returns the player's stats in game, and false if they weren't
+// captured, like for stats predating the game being tracked
+func (p *PlayerPIT) GameStats(game Game) (GameStatsPIT, bool) {
+	if game == GameBedwars {
+		return p.BedwarsStats(), true
+	}
+	stats, ok := p.Games[game]
+	return stats, ok
+}
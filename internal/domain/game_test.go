@@ -0,0 +1,89 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Amund211/flashlight/internal/domain"
+	"github.com/Amund211/flashlight/internal/domaintest"
+)
+
+func TestGameStats(t *testing.T) {
+	t.Parallel()
+
+	uuid := domaintest.NewUUID(t)
+	queriedAt := time.Date(2024, time.May, 4, 18, 0, 0, 0, time.UTC)
+
+	t.Run("bedwars", func(t *testing.T) {
+		t.Parallel()
+
+		player := domaintest.NewPlayerBuilder(uuid).
+			WithExperience(1000).
+			WithDreamMode(domain.DreamModeCastle, domain.GamemodeStatsPIT{GamesPlayed: 2, Wins: 1}).
+			Doubles().WithWinstreak(3).WithWins(4).WithFinalKills(5).
+			Build(queriedAt)
+
+		stats, ok := player.GameStats(domain.GameBedwars)
+		require.True(t, ok)
+		require.Equal(t, player.BedwarsStats(), stats)
+		require.Len(t, stats, 7)
+
+		wins, ok := stats.Stat(string(domain.GamemodeDoubles), domain.StatWins)
+		require.True(t, ok)
+		require.Equal(t, 4, wins)
+
+		finalKills, ok := stats.Stat(domain.GameModeOverall, domain.StatFinalKills)
+		require.True(t, ok)
+		require.Equal(t, 5, finalKills)
+
+		experience, ok := stats.Stat(domain.GameModeOverall, domain.StatExperience)
+		require.True(t, ok)
+		require.Equal(t, 1000, experience)
+
+		winstreak, ok := stats.Stat(string(domain.GamemodeDoubles), domain.StatWinstreak)
+		require.True(t, ok)
+		require.Equal(t, 3, winstreak)
+
+		castleWins, ok := stats.Stat(string(domain.DreamModeCastle), domain.StatWins)
+		require.True(t, ok)
+		require.Equal(t, 1, castleWins)
+
+		// Experience is only tracked overall
+		_, ok = stats.Stat(string(domain.GamemodeDoubles), domain.StatExperience)
+		require.False(t, ok)
+	})
+
+	t.Run("hidden winstreak is missing", func(t *testing.T) {
+		t.Parallel()
+
+		player := domaintest.NewPlayerBuilder(uuid).Build(queriedAt)
+		stats := player.BedwarsStats()
+
+		_, ok := stats.Stat(domain.GameModeOverall, domain.StatWinstreak)
+		require.False(t, ok)
+	})
+
+	t.Run("other games", func(t *testing.T) {
+		t.Parallel()
+
+		duels := domain.GameStatsPIT{
+			domain.GameModeOverall: {domain.StatWins: 10},
+			domain.DuelsModeBridge: {domain.StatWins: 4},
+		}
+		player := domaintest.NewPlayerBuilder(uuid).
+			WithGames(map[domain.Game]domain.GameStatsPIT{domain.GameDuels: duels}).
+			Build(queriedAt)
+
+		stats, ok := player.GameStats(domain.GameDuels)
+		require.True(t, ok)
+		require.Equal(t, duels, stats)
+
+		_, ok = stats.Stat(domain.DuelsModeSumo, domain.StatWins)
+		require.False(t, ok)
+
+		_, ok = player.GameStats(domain.GameSkyWars)
+		require.False(t, ok)
+	})
+}
@@ -14,3 +14,28 @@ const (
 	GamemodeFourv4  Gamemode = "4v4"
 	GamemodeOverall Gamemode = "overall"
 )
+
+// DreamMode names a rotating Bedwars mode, like the dream modes and castle.
+// Their stats are tracked separately by Hypixel and do not count towards any
+// Gamemode, including GamemodeOverall.
+type DreamMode string
+
+const (
+	DreamModeCastle            DreamMode = "castle"
+	DreamModeRushSolo          DreamMode = "rush_solo"
+	DreamModeRushDoubles       DreamMode = "rush_doubles"
+	DreamModeRushFours         DreamMode = "rush_fours"
+	DreamModeUltimateSolo      DreamMode = "ultimate_solo"
+	DreamModeUltimateDoubles   DreamMode = "ultimate_doubles"
+	DreamModeUltimateFours     DreamMode = "ultimate_fours"
+	DreamModeLuckyDoubles      DreamMode = "lucky_doubles"
+	DreamModeLuckyFours        DreamMode = "lucky_fours"
+	DreamModeVoidlessDoubles   DreamMode = "voidless_doubles"
+	DreamModeVoidlessFours     DreamMode = "voidless_fours"
+	DreamModeArmedDoubles      DreamMode = "armed_doubles"
+	DreamModeArmedFours        DreamMode = "armed_fours"
+	DreamModeSwapDoubles       DreamMode = "swap_doubles"
+	DreamModeSwapFours         DreamMode = "swap_fours"
+	DreamModeUnderworldDoubles DreamMode = "underworld_doubles"
+	DreamModeUnderworldFours   DreamMode = "underworld_fours"
+)
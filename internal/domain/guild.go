@@ -0,0 +1,24 @@
+package domain
+
+import "time"
+
+type Guild struct {
+	ID        string
+	QueriedAt time.Time
+
+	Name string
+	// Not all guilds have a tag
+	Tag *string
+	// The Minecraft colour name of the tag, e.g. DARK_AQUA. Nil when unset,
+	// in which case the tag is shown in the default colour.
+	TagColor *string
+
+	Members []GuildMember
+}
+
+type GuildMember struct {
+	UUID string
+	// Guild Master for the owner, otherwise one of the guild's custom ranks
+	Rank   string
+	Joined *time.Time
+}
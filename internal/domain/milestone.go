@@ -1,13 +1,5 @@
 package domain
 
-// Stat represents different statistical measures
-type Stat string
-
-const (
-	StatStars      Stat = "stars"
-	StatExperience Stat = "experience"
-)
-
 // MilestoneAchievement represents when a milestone was reached
 type MilestoneAchievement struct {
 	Milestone int64                      // The milestone value that was reached
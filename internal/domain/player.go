@@ -15,6 +15,11 @@ type PlayerPIT struct {
 	LastLogin   *time.Time
 	LastLogout  *time.Time
 
+	// Rank and NetworkExperience are nil when the stats predate them being
+	// captured
+	Rank              *RankPIT
+	NetworkExperience *float64
+
 	// TODO: Remove? -> Can be derived from checking gamesplayed == 0
 	MissingBedwarsStats bool
 
@@ -25,6 +30,30 @@ type PlayerPIT struct {
 	Fours      GamemodeStatsPIT
 	Fourv4     GamemodeStatsPIT
 	Overall    GamemodeStatsPIT
+
+	// DreamModes holds the stats of the dream modes the player has played.
+	// Nil when there are none, or the stats predate them being captured.
+	DreamModes map[DreamMode]GamemodeStatsPIT
+
+	// Economy is nil when the stats predate it being captured
+	Economy *EconomyStatsPIT
+
+	// Games holds the player's stats in the games other than bedwars, whose
+	// stats are the fields above. Nil when the stats predate them being
+	// captured.
+	Games map[Game]GameStatsPIT
+}
+
+// EconomyStatsPIT holds the player's bedwars currency and shop stats. Hypixel
+// only tracks coins and challenges across all modes, so these are all overall.
+type EconomyStatsPIT struct {
+	Coins               int
+	ChallengesCompleted int
+	ItemsPurchased      int
+	IronCollected       int
+	GoldCollected       int
+	DiamondsCollected   int
+	EmeraldsCollected   int
 }
 
 type GamemodeStatsPIT struct {
@@ -44,6 +73,27 @@ type GamemodeStatsPIT struct {
 	Deaths      int
 }
 
+// GamemodeStats returns the player's stats in gamemode, and false if it is
+// not a known gamemode
+func (p *PlayerPIT) GamemodeStats(gamemode Gamemode) (*GamemodeStatsPIT, bool) {
+	switch gamemode {
+	case GamemodeSolo:
+		return &p.Solo, true
+	case GamemodeDoubles:
+		return &p.Doubles, true
+	case GamemodeThrees:
+		return &p.Threes, true
+	case GamemodeFours:
+		return &p.Fours, true
+	case GamemodeFourv4:
+		return &p.Fourv4, true
+	case GamemodeOverall:
+		return &p.Overall, true
+	default:
+		return nil, false
+	}
+}
+
 // Stars calculates the player's stars based on their experience
 func (p *PlayerPIT) Stars() float64 {
 	return ExperienceToStars(p.Experience)
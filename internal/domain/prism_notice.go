@@ -0,0 +1,86 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// NoticeSeverity controls how prism presents a notice.
+type NoticeSeverity string
+
+const (
+	NoticeSeverityInfo     NoticeSeverity = "info"
+	NoticeSeverityUpdate   NoticeSeverity = "update"
+	NoticeSeverityWarning  NoticeSeverity = "warning"
+	NoticeSeverityCritical NoticeSeverity = "critical"
+)
+
+// NoticeFrequency is how often a targeted user is shown a notice. Every
+// frequency except NoticeFrequencyAlways stops once the user acknowledges
+// the notice.
+type NoticeFrequency string
+
+const (
+	// NoticeFrequencyAlways shows the notice on every request, acknowledged
+	// or not
+	NoticeFrequencyAlways NoticeFrequency = "always"
+	// NoticeFrequencyOnce shows the notice on the first request only
+	NoticeFrequencyOnce NoticeFrequency = "once"
+	// NoticeFrequencyDaily shows the notice at most once every 24 hours
+	NoticeFrequencyDaily NoticeFrequency = "daily"
+	// NoticeFrequencyUntilAcknowledged shows the notice on every request
+	// until the user acknowledges it
+	NoticeFrequencyUntilAcknowledged NoticeFrequency = "until_acknowledged"
+)
+
+// PrismNotice is one row in the prism_notices table: a message for prism
+// users, who it is for and when it is shown.
+type PrismNotice struct {
+	ID              string
+	Message         string
+	URL             string
+	Severity        NoticeSeverity
+	DurationSeconds *float64
+	Frequency       NoticeFrequency
+
+	// ActiveFrom and ActiveUntil bound when the notice is shown, as
+	// [ActiveFrom, ActiveUntil). nil is unbounded on that side.
+	ActiveFrom  *time.Time
+	ActiveUntil *time.Time
+
+	Targeting NoticeTargeting
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// NoticeTargeting narrows who sees a notice. The zero value of each field
+// matches everyone, and a request has to match every field.
+type NoticeTargeting struct {
+	// UserIDs limits the notice to these user ids
+	UserIDs []string
+	// MinPrismVersion is the first prism version to show the notice to, and
+	// MaxPrismVersion the first one not to. Empty is unbounded. A client
+	// with an unparseable version never matches a bounded range.
+	MinPrismVersion string
+	MaxPrismVersion string
+	// ClientTypes limits the notice to these normalized client types
+	ClientTypes []string
+	// RolloutPercentage shows the notice to this share of users, 0-100. A
+	// user stays in or out of the rollout for a given notice as it grows.
+	RolloutPercentage int
+}
+
+// PrismNoticeView is what a user has seen of a notice. A nil time means it
+// hasn't happened.
+type PrismNoticeView struct {
+	LastShownAt    *time.Time
+	AcknowledgedAt *time.Time
+}
+
+// ErrPrismNoticeNotFound is returned when a notice id is unknown to the repo.
+var ErrPrismNoticeNotFound = errors.New("prism notice not found")
+
+// ErrInvalidPrismNotice is wrapped by the validation errors for a notice an
+// admin is trying to save. The wrapping error says what is wrong.
+var ErrInvalidPrismNotice = errors.New("invalid prism notice")
@@ -0,0 +1,15 @@
+package domain
+
+import "time"
+
+// PrismRelease is a published prism release, as listed in the releases feed
+// of the prism repository.
+type PrismRelease struct {
+	// Version is the release tag, e.g. "v1.12.0"
+	Version string
+	Name    string
+	// URL is the release page, which holds the changelog
+	URL         string
+	Prerelease  bool
+	PublishedAt time.Time
+}
@@ -0,0 +1,60 @@
+package domain
+
+import "math"
+
+// RankPIT holds a player's rank as Hypixel reports it. Any field can be nil
+// when the player doesn't have that kind of rank.
+type RankPIT struct {
+	// StaffRank is the special rank of staff and content creators, e.g.
+	// ADMIN or YOUTUBER. Takes precedence over the purchased ranks.
+	StaffRank *string
+	// PackageRank is the purchased rank: VIP, VIP_PLUS, MVP or MVP_PLUS
+	PackageRank *string
+	// MonthlyPackageRank is SUPERSTAR while the player has MVP++
+	MonthlyPackageRank *string
+	// RankPlusColor is the Minecraft colour name of the plus in MVP+ and
+	// MVP++, e.g. RED
+	RankPlusColor *string
+}
+
+// staffRankDisplays are the in-game prefixes of the staff ranks, where they
+// differ from the API name
+var staffRankDisplays = map[string]string{
+	"GAME_MASTER": "GM",
+	"MODERATOR":   "MOD",
+	"YOUTUBER":    "YOUTUBE",
+}
+
+var packageRankDisplays = map[string]string{
+	"VIP":      "VIP",
+	"VIP_PLUS": "VIP+",
+	"MVP":      "MVP",
+	"MVP_PLUS": "MVP+",
+}
+
+// Display returns the rank as shown in game, e.g. MVP++, or an empty string
+// for players without a rank
+func (r RankPIT) Display() string {
+	if r.StaffRank != nil && *r.StaffRank != "NORMAL" {
+		if display, ok := staffRankDisplays[*r.StaffRank]; ok {
+			return display
+		}
+		return *r.StaffRank
+	}
+
+	if r.MonthlyPackageRank != nil && *r.MonthlyPackageRank == "SUPERSTAR" {
+		return "MVP++"
+	}
+
+	if r.PackageRank != nil {
+		return packageRankDisplays[*r.PackageRank]
+	}
+
+	return ""
+}
+
+// NetworkExperienceToLevel returns the Hypixel network level, starting at 1
+// with no experience
+func NetworkExperienceToLevel(experience float64) float64 {
+	return math.Sqrt(2*experience+30625)/50 - 2.5
+}
@@ -0,0 +1,48 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Amund211/flashlight/internal/domain"
+)
+
+func TestRankDisplay(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name string
+		rank domain.RankPIT
+		want string
+	}{
+		{name: "no rank", rank: domain.RankPIT{}, want: ""},
+		{name: "none package rank", rank: domain.RankPIT{PackageRank: new("NONE")}, want: ""},
+		{name: "vip", rank: domain.RankPIT{PackageRank: new("VIP")}, want: "VIP"},
+		{name: "vip+", rank: domain.RankPIT{PackageRank: new("VIP_PLUS")}, want: "VIP+"},
+		{name: "mvp", rank: domain.RankPIT{PackageRank: new("MVP")}, want: "MVP"},
+		{name: "mvp+", rank: domain.RankPIT{PackageRank: new("MVP_PLUS"), RankPlusColor: new("RED")}, want: "MVP+"},
+		{name: "mvp++", rank: domain.RankPIT{PackageRank: new("MVP_PLUS"), MonthlyPackageRank: new("SUPERSTAR")}, want: "MVP++"},
+		{name: "expired mvp++", rank: domain.RankPIT{PackageRank: new("MVP_PLUS"), MonthlyPackageRank: new("NONE")}, want: "MVP+"},
+		{name: "normal staff rank", rank: domain.RankPIT{StaffRank: new("NORMAL"), PackageRank: new("VIP")}, want: "VIP"},
+		{name: "youtuber", rank: domain.RankPIT{StaffRank: new("YOUTUBER"), PackageRank: new("MVP_PLUS")}, want: "YOUTUBE"},
+		{name: "game master", rank: domain.RankPIT{StaffRank: new("GAME_MASTER")}, want: "GM"},
+		{name: "admin", rank: domain.RankPIT{StaffRank: new("ADMIN"), MonthlyPackageRank: new("SUPERSTAR")}, want: "ADMIN"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, c.want, c.rank.Display())
+		})
+	}
+}
+
+func TestNetworkExperienceToLevel(t *testing.T) {
+	t.Parallel()
+
+	require.InDelta(t, 1.0, domain.NetworkExperienceToLevel(0), 1e-9)
+	require.InDelta(t, 2.0, domain.NetworkExperienceToLevel(10_000), 1e-9)
+	require.InDelta(t, 3.0, domain.NetworkExperienceToLevel(22_500), 1e-9)
+	require.InDelta(t, 100.0, domain.NetworkExperienceToLevel(13_117_500), 1e-9)
+}
@@ -0,0 +1,103 @@
+package domain
+
+import "time"
+
+// SessionSummary is the derived statistics of a session, so clients don't
+// have to recompute them from the start and end stats.
+type SessionSummary struct {
+	Duration         time.Duration
+	ExperienceGained int64
+	StarsGained      float64
+	// GamesPerHour counts games in the session's gamemode. Zero for a
+	// session without duration.
+	GamesPerHour float64
+	// AttributedGames is how many of the session's games could be pinned to
+	// a single game each. Zero when the session was not segmented.
+	AttributedGames int
+
+	Solo    GamemodeSummary
+	Doubles GamemodeSummary
+	Threes  GamemodeSummary
+	Fours   GamemodeSummary
+	Fourv4  GamemodeSummary
+	Overall GamemodeSummary
+}
+
+// GamemodeSummary is the stats gained in a gamemode during a session and the
+// ratios between them.
+type GamemodeSummary struct {
+	GamemodeStatsDelta
+
+	FKDR float64
+	KDR  float64
+	WLR  float64
+	BBLR float64
+}
+
+// NewSessionSummary summarizes session. attributedGames is the number of
+// games in the session that could be attributed individually.
+func NewSessionSummary(session *Session, attributedGames int) SessionSummary {
+	// Derived from the endpoints so sessions built by hand summarize too
+	deltas := NewSessionDeltas(&session.Start, &session.End)
+	duration := session.End.QueriedAt.Sub(session.Start.QueriedAt)
+
+	summary := SessionSummary{
+		Duration:         duration,
+		ExperienceGained: deltas.Experience,
+		StarsGained:      session.End.Stars() - session.Start.Stars(),
+		AttributedGames:  attributedGames,
+
+		Solo:    newGamemodeSummary(deltas.Solo),
+		Doubles: newGamemodeSummary(deltas.Doubles),
+		Threes:  newGamemodeSummary(deltas.Threes),
+		Fours:   newGamemodeSummary(deltas.Fours),
+		Fourv4:  newGamemodeSummary(deltas.Fourv4),
+		Overall: newGamemodeSummary(deltas.Overall),
+	}
+
+	if gamemodeDelta, ok := deltas.GamemodeDelta(session.Gamemode); ok && duration > 0 {
+		summary.GamesPerHour = float64(gamemodeDelta.GamesPlayed) / duration.Hours()
+	}
+
+	return summary
+}
+
+// GamemodeDelta returns the deltas in gamemode, and false if it is not a
+// known gamemode. An unset gamemode is overall.
+func (d *SessionDeltas) GamemodeDelta(gamemode Gamemode) (GamemodeStatsDelta, bool) {
+	switch gamemode {
+	case GamemodeSolo:
+		return d.Solo, true
+	case GamemodeDoubles:
+		return d.Doubles, true
+	case GamemodeThrees:
+		return d.Threes, true
+	case GamemodeFours:
+		return d.Fours, true
+	case GamemodeFourv4:
+		return d.Fourv4, true
+	case GamemodeOverall, "":
+		return d.Overall, true
+	default:
+		return GamemodeStatsDelta{}, false
+	}
+}
+
+func newGamemodeSummary(delta GamemodeStatsDelta) GamemodeSummary {
+	return GamemodeSummary{
+		GamemodeStatsDelta: delta,
+		FKDR:               Ratio(delta.FinalKills, delta.FinalDeaths),
+		KDR:                Ratio(delta.Kills, delta.Deaths),
+		WLR:                Ratio(delta.Wins, delta.Losses),
+		BBLR:               Ratio(delta.BedsBroken, delta.BedsLost),
+	}
+}
+
+// Ratio is numerator / denominator, or numerator when the denominator is
+// zero, in line with how Hypixel players read e.g. FKDR.
+func Ratio(numerator, denominator int) float64 {
+	if denominator == 0 {
+		return float64(numerator)
+	}
+	return float64(numerator) / float64(denominator)
+}
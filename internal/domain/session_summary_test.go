@@ -0,0 +1,83 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Amund211/flashlight/internal/domain"
+	"github.com/Amund211/flashlight/internal/domaintest"
+)
+
+func TestNewSessionSummary(t *testing.T) {
+	t.Parallel()
+
+	uuid := domaintest.NewUUID(t)
+	start := time.Date(2024, time.May, 4, 18, 0, 0, 0, time.UTC)
+
+	startStats := domaintest.NewPlayerBuilder(uuid).
+		WithExperience(domain.StarsToExperience(100)).
+		Solo().WithGamesPlayed(10).WithWins(5).WithLosses(5).WithFinalKills(10).WithFinalDeaths(5).
+		Fours().WithGamesPlayed(20).WithKills(40).WithDeaths(20).WithBedsBroken(8).
+		Build(start)
+	endStats := domaintest.NewPlayerBuilder(uuid).
+		WithExperience(domain.StarsToExperience(102)).
+		Solo().WithGamesPlayed(14).WithWins(8).WithLosses(6).WithFinalKills(16).WithFinalDeaths(6).
+		Fours().WithGamesPlayed(22).WithKills(45).WithDeaths(20).WithBedsBroken(10).WithBedsLost(0).
+		Build(start.Add(2 * time.Hour))
+
+	t.Run("overall", func(t *testing.T) {
+		t.Parallel()
+
+		session := domain.Session{Start: startStats, End: endStats, Gamemode: domain.GamemodeOverall}
+		summary := domain.NewSessionSummary(&session, 4)
+
+		require.Equal(t, 2*time.Hour, summary.Duration)
+		require.Equal(t, domain.StarsToExperience(102)-domain.StarsToExperience(100), summary.ExperienceGained)
+		require.InDelta(t, 2.0, summary.StarsGained, 1e-9)
+		require.Equal(t, 3.0, summary.GamesPerHour)
+		require.Equal(t, 4, summary.AttributedGames)
+
+		require.Equal(t, domain.GamemodeSummary{
+			GamemodeStatsDelta: domain.GamemodeStatsDelta{GamesPlayed: 4, Wins: 3, Losses: 1, FinalKills: 6, FinalDeaths: 1},
+			FKDR:               6,
+			KDR:                0,
+			WLR:                3,
+			BBLR:               0,
+		}, summary.Solo)
+
+		require.Equal(t, 5.0, summary.Fours.KDR, "no deaths gives the kills")
+		require.Equal(t, 2.0, summary.Fours.BBLR, "no beds lost gives the beds broken")
+		require.Equal(t, domain.GamemodeSummary{}, summary.Threes)
+	})
+
+	t.Run("per gamemode", func(t *testing.T) {
+		t.Parallel()
+
+		session := domain.Session{Start: startStats, End: endStats, Gamemode: domain.GamemodeFours}
+		summary := domain.NewSessionSummary(&session, 0)
+
+		require.Equal(t, 1.0, summary.GamesPerHour)
+	})
+
+	t.Run("no duration", func(t *testing.T) {
+		t.Parallel()
+
+		session := domain.Session{Start: startStats, End: startStats}
+		summary := domain.NewSessionSummary(&session, 0)
+
+		require.Zero(t, summary.Duration)
+		require.Zero(t, summary.GamesPerHour)
+		require.Zero(t, summary.StarsGained)
+	})
+}
+
+func TestRatio(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, 2.5, domain.Ratio(5, 2))
+	require.Equal(t, 5.0, domain.Ratio(5, 0))
+	require.Equal(t, 0.0, domain.Ratio(0, 0))
+	require.Equal(t, 0.0, domain.Ratio(0, 3))
+}
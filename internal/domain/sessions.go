@@ -6,4 +6,89 @@ type Session struct {
 	Consecutive bool
 	// Ongoing is true iff this session could be extended.
 	Ongoing bool
+	// Gamemode is the mode whose progress the session was computed from.
+	// GamemodeOverall counts activity in any mode.
+	Gamemode Gamemode
+	// Deltas is how much the stats moved from Start to End
+	Deltas SessionDeltas
+}
+
+// SessionDeltas summarizes a session per gamemode
+type SessionDeltas struct {
+	Experience int64
+	Solo       GamemodeStatsDelta
+	Doubles    GamemodeStatsDelta
+	Threes     GamemodeStatsDelta
+	Fours      GamemodeStatsDelta
+	Fourv4     GamemodeStatsDelta
+	Overall    GamemodeStatsDelta
+	// Economy is nil unless both ends of the session have economy stats
+	Economy *EconomyStatsDelta
+}
+
+// GamemodeStatsDelta is the difference between two GamemodeStatsPIT.
+// Winstreak is left out as it resets rather than accumulates.
+type GamemodeStatsDelta struct {
+	GamesPlayed int
+	Wins        int
+	Losses      int
+	BedsBroken  int
+	BedsLost    int
+	FinalKills  int
+	FinalDeaths int
+	Kills       int
+	Deaths      int
+}
+
+// EconomyStatsDelta is the difference between two EconomyStatsPIT. Coins can
+// decrease, as they are spent.
+type EconomyStatsDelta struct {
+	Coins               int
+	ChallengesCompleted int
+	ItemsPurchased      int
+	IronCollected       int
+	GoldCollected       int
+	DiamondsCollected   int
+	EmeraldsCollected   int
+}
+
+// NewSessionDeltas returns how much the stats moved from start to end
+func NewSessionDeltas(start, end *PlayerPIT) SessionDeltas {
+	var economy *EconomyStatsDelta
+	if start.Economy != nil && end.Economy != nil {
+		economy = &EconomyStatsDelta{
+			Coins:               end.Economy.Coins - start.Economy.Coins,
+			ChallengesCompleted: end.Economy.ChallengesCompleted - start.Economy.ChallengesCompleted,
+			ItemsPurchased:      end.Economy.ItemsPurchased - start.Economy.ItemsPurchased,
+			IronCollected:       end.Economy.IronCollected - start.Economy.IronCollected,
+			GoldCollected:       end.Economy.GoldCollected - start.Economy.GoldCollected,
+			DiamondsCollected:   end.Economy.DiamondsCollected - start.Economy.DiamondsCollected,
+			EmeraldsCollected:   end.Economy.EmeraldsCollected - start.Economy.EmeraldsCollected,
+		}
+	}
+
+	return SessionDeltas{
+		Experience: end.Experience - start.Experience,
+		Solo:       newGamemodeStatsDelta(&start.Solo, &end.Solo),
+		Doubles:    newGamemodeStatsDelta(&start.Doubles, &end.Doubles),
+		Threes:     newGamemodeStatsDelta(&start.Threes, &end.Threes),
+		Fours:      newGamemodeStatsDelta(&start.Fours, &end.Fours),
+		Fourv4:     newGamemodeStatsDelta(&start.Fourv4, &end.Fourv4),
+		Overall:    newGamemodeStatsDelta(&start.Overall, &end.Overall),
+		Economy:    economy,
+	}
+}
+
+func newGamemodeStatsDelta(start, end *GamemodeStatsPIT) GamemodeStatsDelta {
+	return GamemodeStatsDelta{
+		GamesPlayed: end.GamesPlayed - start.GamesPlayed,
+		Wins:        end.Wins - start.Wins,
+		Losses:      end.Losses - start.Losses,
+		BedsBroken:  end.BedsBroken - start.BedsBroken,
+		BedsLost:    end.BedsLost - start.BedsLost,
+		FinalKills:  end.FinalKills - start.FinalKills,
+		FinalDeaths: end.FinalDeaths - start.FinalDeaths,
+		Kills:       end.Kills - start.Kills,
+		Deaths:      end.Deaths - start.Deaths,
+	}
 }
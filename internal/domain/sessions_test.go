@@ -0,0 +1,56 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Amund211/flashlight/internal/domain"
+	"github.com/Amund211/flashlight/internal/domaintest"
+)
+
+func TestNewSessionDeltasEconomy(t *testing.T) {
+	t.Parallel()
+
+	uuid := domaintest.NewUUID(t)
+	start := time.Date(2024, time.May, 4, 18, 0, 0, 0, time.UTC)
+
+	withEconomy := domaintest.NewPlayerBuilder(uuid).WithEconomy(domain.EconomyStatsPIT{
+		Coins:          5000,
+		ItemsPurchased: 100,
+		IronCollected:  1000,
+	}).Build(start)
+	moreEconomy := domaintest.NewPlayerBuilder(uuid).WithEconomy(domain.EconomyStatsPIT{
+		Coins:               4500,
+		ChallengesCompleted: 1,
+		ItemsPurchased:      130,
+		IronCollected:       1400,
+		GoldCollected:       80,
+		DiamondsCollected:   4,
+		EmeraldsCollected:   2,
+	}).Build(start.Add(time.Hour))
+	withoutEconomy := domaintest.NewPlayerBuilder(uuid).Build(start)
+
+	t.Run("both ends have economy stats", func(t *testing.T) {
+		t.Parallel()
+
+		deltas := domain.NewSessionDeltas(&withEconomy, &moreEconomy)
+		require.Equal(t, &domain.EconomyStatsDelta{
+			Coins:               -500,
+			ChallengesCompleted: 1,
+			ItemsPurchased:      30,
+			IronCollected:       400,
+			GoldCollected:       80,
+			DiamondsCollected:   4,
+			EmeraldsCollected:   2,
+		}, deltas.Economy)
+	})
+
+	t.Run("start predates economy stats", func(t *testing.T) {
+		t.Parallel()
+
+		deltas := domain.NewSessionDeltas(&withoutEconomy, &moreEconomy)
+		require.Nil(t, deltas.Economy)
+	})
+}
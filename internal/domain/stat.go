@@ -0,0 +1,22 @@
+package domain
+
+// Stat names a number tracked in a player's stats. Milestones are only found
+// for some of them.
+type Stat string
+
+const (
+	// StatStars and StatExperience are only tracked overall
+	StatStars      Stat = "stars"
+	StatExperience Stat = "experience"
+
+	StatWins        Stat = "wins"
+	StatLosses      Stat = "losses"
+	StatWinstreak   Stat = "winstreak"
+	StatGamesPlayed Stat = "gamesPlayed"
+	StatKills       Stat = "kills"
+	StatDeaths      Stat = "deaths"
+	StatFinalKills  Stat = "finalKills"
+	StatFinalDeaths Stat = "finalDeaths"
+	StatBedsBroken  Stat = "bedsBroken"
+	StatBedsLost    Stat = "bedsLost"
+)
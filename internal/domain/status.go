@@ -0,0 +1,22 @@
+package domain
+
+import "time"
+
+type PlayerStatus struct {
+	UUID      string
+	QueriedAt time.Time
+
+	// Players can hide their online status, in which case they always appear offline
+	Online bool
+
+	// Only set when the player is online
+	GameType *string
+	Mode     *string
+	Map      *string
+}
+
+// OnlineTransition is an observed change in a player's online status
+type OnlineTransition struct {
+	At     time.Time
+	Online bool
+}
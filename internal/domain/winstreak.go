@@ -0,0 +1,124 @@
+package domain
+
+import "time"
+
+// StreakEstimate is the length of a streak reconstructed from the stats
+// between snapshots. When the stats breaking the streak changed in the same
+// interval as the streak grew, the order of the games is unknown, and the
+// streak is only known to be between Length and MaxLength.
+type StreakEstimate struct {
+	Length    int
+	MaxLength int
+}
+
+// Uncertain is whether the order of the games left the length unknown
+func (e StreakEstimate) Uncertain() bool {
+	return e.MaxLength > e.Length
+}
+
+// StepStreak advances the current streak across the games between two
+// consecutive snapshots. gained and broken are how much the stat making up the
+// streak and the stat breaking it increased. known is the exact streak after
+// the games when available, like the winstreak shown by the Hypixel API.
+// ended is the streak a break ended, and is nil when nothing broke the streak.
+func StepStreak(current StreakEstimate, gained, broken int, known *int) (ended *StreakEstimate, next StreakEstimate) {
+	gained = max(gained, 0)
+
+	if broken <= 0 {
+		next = StreakEstimate{Length: current.Length + gained, MaxLength: current.MaxLength + gained}
+		if known != nil {
+			next = StreakEstimate{Length: *known, MaxLength: *known}
+		}
+		return nil, next
+	}
+
+	if known == nil {
+		// The gains may have come before the first break, after the last one,
+		// or in between
+		return &StreakEstimate{Length: current.Length, MaxLength: current.MaxLength + gained},
+			StreakEstimate{Length: 0, MaxLength: gained}
+	}
+
+	// The known streak was gained after the last break, so only the rest
+	// could have extended the ended streak
+	before := max(gained-*known, 0)
+	if broken == 1 {
+		// All of the rest came before the only break
+		ended = &StreakEstimate{Length: current.Length + before, MaxLength: current.MaxLength + before}
+	} else {
+		ended = &StreakEstimate{Length: current.Length, MaxLength: current.MaxLength + before}
+	}
+	return ended, StreakEstimate{Length: *known, MaxLength: *known}
+}
+
+// ReconstructedWinstreak is a winstreak in one gamemode inferred from the
+// wins and losses between consecutive stats
+type ReconstructedWinstreak struct {
+	StreakEstimate
+	// StartedAfter is the last stats before the first win of the streak. Nil
+	// when the streak started before the first stats.
+	StartedAfter *time.Time
+	// HeldAt is the last stats before the loss ending the streak, or the last
+	// stats when the streak is ongoing
+	HeldAt time.Time
+	// EndedBefore is the first stats including the loss ending the streak. Nil
+	// when the streak is ongoing.
+	EndedBefore *time.Time
+}
+
+// ReconstructWinstreaks infers the winstreaks in gamemode from stats sorted by
+// QueriedAt. Streaks that could have had wins are included, in the order they
+// ended, followed by the ongoing streak. The winstreak shown by the Hypixel
+// API is used when it is visible, while a hidden streak ongoing at the first
+// stats only counts the wins after them.
+func ReconstructWinstreaks(stats []PlayerPIT, gamemode Gamemode) []ReconstructedWinstreak {
+	streaks := []ReconstructedWinstreak{}
+	if len(stats) == 0 {
+		return streaks
+	}
+
+	first, ok := stats[0].GamemodeStats(gamemode)
+	if !ok {
+		return streaks
+	}
+
+	current := ReconstructedWinstreak{HeldAt: stats[0].QueriedAt}
+	if first.Winstreak != nil {
+		current.Length = *first.Winstreak
+		current.MaxLength = *first.Winstreak
+	}
+
+	previous := first
+	for i := 1; i < len(stats); i++ {
+		pit := &stats[i]
+		gamemodeStats, _ := pit.GamemodeStats(gamemode)
+
+		ended, next := StepStreak(current.StreakEstimate, gamemodeStats.Wins-previous.Wins, gamemodeStats.Losses-previous.Losses, gamemodeStats.Winstreak)
+
+		if ended != nil {
+			if ended.MaxLength > 0 {
+				streaks = append(streaks, ReconstructedWinstreak{
+					StreakEstimate: *ended,
+					StartedAfter:   current.StartedAfter,
+					HeldAt:         current.HeldAt,
+					EndedBefore:    new(pit.QueriedAt),
+				})
+			}
+			current = ReconstructedWinstreak{}
+		}
+
+		if current.MaxLength == 0 && next.MaxLength > 0 {
+			current.StartedAfter = new(stats[i-1].QueriedAt)
+		}
+		current.StreakEstimate = next
+		current.HeldAt = pit.QueriedAt
+
+		previous = gamemodeStats
+	}
+
+	if current.MaxLength > 0 {
+		streaks = append(streaks, current)
+	}
+
+	return streaks
+}
@@ -0,0 +1,184 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Amund211/flashlight/internal/domain"
+	"github.com/Amund211/flashlight/internal/domaintest"
+)
+
+func TestStepStreak(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		current   domain.StreakEstimate
+		gained    int
+		broken    int
+		known     *int
+		wantEnded *domain.StreakEstimate
+		wantNext  domain.StreakEstimate
+	}{
+		{
+			name:     "gains extend the streak",
+			current:  domain.StreakEstimate{Length: 3, MaxLength: 5},
+			gained:   2,
+			wantNext: domain.StreakEstimate{Length: 5, MaxLength: 7},
+		},
+		{
+			name:     "nothing happened",
+			current:  domain.StreakEstimate{Length: 3, MaxLength: 3},
+			wantNext: domain.StreakEstimate{Length: 3, MaxLength: 3},
+		},
+		{
+			name:      "break without gains",
+			current:   domain.StreakEstimate{Length: 3, MaxLength: 3},
+			broken:    1,
+			wantEnded: &domain.StreakEstimate{Length: 3, MaxLength: 3},
+			wantNext:  domain.StreakEstimate{Length: 0, MaxLength: 0},
+		},
+		{
+			name:      "gains and breaks in unknown order",
+			current:   domain.StreakEstimate{Length: 3, MaxLength: 3},
+			gained:    4,
+			broken:    2,
+			wantEnded: &domain.StreakEstimate{Length: 3, MaxLength: 7},
+			wantNext:  domain.StreakEstimate{Length: 0, MaxLength: 4},
+		},
+		{
+			name:     "known streak overrides the estimate",
+			current:  domain.StreakEstimate{Length: 0, MaxLength: 2},
+			gained:   1,
+			known:    new(10),
+			wantNext: domain.StreakEstimate{Length: 10, MaxLength: 10},
+		},
+		{
+			name:      "known streak after a single break",
+			current:   domain.StreakEstimate{Length: 3, MaxLength: 3},
+			gained:    4,
+			broken:    1,
+			known:     new(1),
+			wantEnded: &domain.StreakEstimate{Length: 6, MaxLength: 6},
+			wantNext:  domain.StreakEstimate{Length: 1, MaxLength: 1},
+		},
+		{
+			name:      "known streak after several breaks",
+			current:   domain.StreakEstimate{Length: 3, MaxLength: 3},
+			gained:    4,
+			broken:    2,
+			known:     new(1),
+			wantEnded: &domain.StreakEstimate{Length: 3, MaxLength: 6},
+			wantNext:  domain.StreakEstimate{Length: 1, MaxLength: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ended, next := domain.StepStreak(tt.current, tt.gained, tt.broken, tt.known)
+			require.Equal(t, tt.wantEnded, ended)
+			require.Equal(t, tt.wantNext, next)
+		})
+	}
+}
+
+func TestReconstructWinstreaks(t *testing.T) {
+	t.Parallel()
+
+	uuid := domaintest.NewUUID(t)
+	start := time.Date(2024, time.May, 4, 18, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time {
+		return start.Add(time.Duration(minutes) * time.Minute)
+	}
+
+	// NOTE: Builders are mutable, so each snapshot gets a new one
+	doubles := func(wins, losses int, queriedAt time.Time) domain.PlayerPIT {
+		return domaintest.NewPlayerBuilder(uuid).Doubles().WithWins(wins).WithLosses(losses).Build(queriedAt)
+	}
+
+	t.Run("no stats", func(t *testing.T) {
+		t.Parallel()
+
+		require.Empty(t, domain.ReconstructWinstreaks(nil, domain.GamemodeDoubles))
+	})
+
+	t.Run("streaks", func(t *testing.T) {
+		t.Parallel()
+
+		stats := []domain.PlayerPIT{
+			doubles(10, 5, at(0)),
+			doubles(12, 5, at(10)),
+			doubles(13, 5, at(20)),
+			// A loss ends the streak of 3
+			doubles(13, 6, at(30)),
+			doubles(15, 6, at(40)),
+			// Two wins and a loss in unknown order
+			doubles(17, 7, at(50)),
+			doubles(18, 7, at(60)),
+		}
+
+		streaks := domain.ReconstructWinstreaks(stats, domain.GamemodeDoubles)
+		require.Equal(t, []domain.ReconstructedWinstreak{
+			{
+				StreakEstimate: domain.StreakEstimate{Length: 3, MaxLength: 3},
+				StartedAfter:   new(at(0)),
+				HeldAt:         at(20),
+				EndedBefore:    new(at(30)),
+			},
+			{
+				StreakEstimate: domain.StreakEstimate{Length: 2, MaxLength: 4},
+				StartedAfter:   new(at(30)),
+				HeldAt:         at(40),
+				EndedBefore:    new(at(50)),
+			},
+			{
+				// Ongoing
+				StreakEstimate: domain.StreakEstimate{Length: 1, MaxLength: 3},
+				StartedAfter:   new(at(40)),
+				HeldAt:         at(60),
+			},
+		}, streaks)
+		require.True(t, streaks[1].Uncertain())
+		require.False(t, streaks[0].Uncertain())
+
+		// Other gamemodes had no wins
+		require.Empty(t, domain.ReconstructWinstreaks(stats, domain.GamemodeSolo))
+	})
+
+	t.Run("visible winstreak", func(t *testing.T) {
+		t.Parallel()
+
+		withWinstreak := func(winstreak, wins, losses int, queriedAt time.Time) domain.PlayerPIT {
+			b := domaintest.NewPlayerBuilder(uuid).WithOverallWinstreak(winstreak)
+			b.Doubles().WithWinstreak(winstreak).WithWins(wins).WithLosses(losses)
+			return b.Build(queriedAt)
+		}
+
+		stats := []domain.PlayerPIT{
+			// The streak started before the first stats
+			withWinstreak(20, 100, 5, at(0)),
+			withWinstreak(22, 102, 5, at(10)),
+			// The streak tells that the first win came before the loss
+			withWinstreak(1, 104, 6, at(20)),
+		}
+
+		streaks := domain.ReconstructWinstreaks(stats, domain.GamemodeDoubles)
+		require.Equal(t, []domain.ReconstructedWinstreak{
+			{
+				StreakEstimate: domain.StreakEstimate{Length: 23, MaxLength: 23},
+				StartedAfter:   nil,
+				HeldAt:         at(10),
+				EndedBefore:    new(at(20)),
+			},
+			{
+				StreakEstimate: domain.StreakEstimate{Length: 1, MaxLength: 1},
+				StartedAfter:   new(at(10)),
+				HeldAt:         at(20),
+			},
+		}, streaks)
+	})
+}
@@ -0,0 +1,23 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// StoredWrapped is a persisted wrapped computation for a player's year. Data
+// is owned by the computation. When Complete no more stats will be added to
+// it, otherwise it is the state the ongoing year is resumed from.
+type StoredWrapped struct {
+	UUID string
+	Year int
+	// Version is the version of the computation that produced Data. Entries
+	// from other versions are not read.
+	Version    int
+	Complete   bool
+	Data       []byte
+	ComputedAt time.Time
+}
+
+// ErrWrappedNotFound is returned when no wrapped is stored for the key.
+var ErrWrappedNotFound = errors.New("wrapped not found")
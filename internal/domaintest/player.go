@@ -2,6 +2,7 @@ package domaintest
 
 import (
 	"fmt"
+	"maps"
 	"testing"
 	"time"
 
@@ -51,6 +52,34 @@ func (pb *playerBuilder) WithOverallWinstreak(winstreak int) *playerBuilder {
 	return pb
 }
 
+func (pb *playerBuilder) WithDreamMode(mode domain.DreamMode, stats domain.GamemodeStatsPIT) *playerBuilder {
+	if pb.player.DreamModes == nil {
+		pb.player.DreamModes = map[domain.DreamMode]domain.GamemodeStatsPIT{}
+	}
+	pb.player.DreamModes[mode] = stats
+	return pb
+}
+
+func (pb *playerBuilder) WithEconomy(economy domain.EconomyStatsPIT) *playerBuilder {
+	pb.player.Economy = &economy
+	return pb
+}
+
+func (pb *playerBuilder) WithGames(games map[domain.Game]domain.GameStatsPIT) *playerBuilder {
+	pb.player.Games = games
+	return pb
+}
+
+func (pb *playerBuilder) WithRank(rank domain.RankPIT) *playerBuilder {
+	pb.player.Rank = &rank
+	return pb
+}
+
+func (pb *playerBuilder) WithNetworkExperience(networkExperience float64) *playerBuilder {
+	pb.player.NetworkExperience = &networkExperience
+	return pb
+}
+
 func (pb *playerBuilder) Build(queriedAt time.Time) domain.PlayerPIT {
 	player := *pb.player
 	player.QueriedAt = queriedAt
@@ -66,6 +95,33 @@ func (pb *playerBuilder) Build(queriedAt time.Time) domain.PlayerPIT {
 	player.Threes.Winstreak = clonePtr(player.Threes.Winstreak)
 	player.Fours.Winstreak = clonePtr(player.Fours.Winstreak)
 	player.Fourv4.Winstreak = clonePtr(player.Fourv4.Winstreak)
+	player.Economy = clonePtr(player.Economy)
+	player.NetworkExperience = clonePtr(player.NetworkExperience)
+	if pb.player.Rank != nil {
+		player.Rank = &domain.RankPIT{
+			StaffRank:          clonePtr(pb.player.Rank.StaffRank),
+			PackageRank:        clonePtr(pb.player.Rank.PackageRank),
+			MonthlyPackageRank: clonePtr(pb.player.Rank.MonthlyPackageRank),
+			RankPlusColor:      clonePtr(pb.player.Rank.RankPlusColor),
+		}
+	}
+	if pb.player.DreamModes != nil {
+		player.DreamModes = make(map[domain.DreamMode]domain.GamemodeStatsPIT, len(pb.player.DreamModes))
+		for mode, stats := range pb.player.DreamModes {
+			stats.Winstreak = clonePtr(stats.Winstreak)
+			player.DreamModes[mode] = stats
+		}
+	}
+
+	if pb.player.Games != nil {
+		player.Games = make(map[domain.Game]domain.GameStatsPIT, len(pb.player.Games))
+		for game, stats := range pb.player.Games {
+			player.Games[game] = make(domain.GameStatsPIT, len(stats))
+			for mode, modeStats := range stats {
+				player.Games[game][mode] = maps.Clone(modeStats)
+			}
+		}
+	}
 
 	if pb.fromDB {
 		uuidv7, err := uuid.NewV7()
@@ -0,0 +1,55 @@
+package ports
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/Amund211/flashlight/internal/logging"
+)
+
+// AdminAPIKeyHeader carries the key for the /v1/admin endpoints.
+const AdminAPIKeyHeader = "X-Admin-Api-Key"
+
+// NewAdminAuthMiddleware rejects requests that don't carry one of
+// adminAPIKeys in AdminAPIKeyHeader. With no keys every request is rejected,
+// which is how the admin endpoints stay closed where none are configured.
+//
+// Mount it where the bearer middleware would go: behind the IP rate limits,
+// so the keys can't be guessed at full speed.
+func NewAdminAuthMiddleware(adminAPIKeys []string) func(http.HandlerFunc) http.HandlerFunc {
+	// Compare fixed length digests so the comparison time doesn't depend
+	// on how long the configured keys are
+	keyDigests := make([][sha256.Size]byte, 0, len(adminAPIKeys))
+	for _, key := range adminAPIKeys {
+		if key == "" {
+			continue
+		}
+		keyDigests = append(keyDigests, sha256.Sum256([]byte(key)))
+	}
+
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+
+			presented := r.Header.Get(AdminAPIKeyHeader)
+			if presented == "" {
+				writeErrorResponse(ctx, w, newAPIError(http.StatusUnauthorized, errorCodeUnauthorized, "Missing admin API key"))
+				return
+			}
+
+			presentedDigest := sha256.Sum256([]byte(presented))
+			matched := 0
+			for _, digest := range keyDigests {
+				matched |= subtle.ConstantTimeCompare(presentedDigest[:], digest[:])
+			}
+			if matched != 1 {
+				logging.FromContext(ctx).WarnContext(ctx, "Rejected invalid admin API key")
+				writeErrorResponse(ctx, w, newAPIError(http.StatusUnauthorized, errorCodeUnauthorized, "Invalid admin API key"))
+				return
+			}
+
+			next(w, r)
+		}
+	}
+}
@@ -0,0 +1,371 @@
+package ports
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/Amund211/flashlight/internal/app"
+	"github.com/Amund211/flashlight/internal/domain"
+	"github.com/Amund211/flashlight/internal/logging"
+	"github.com/Amund211/flashlight/internal/reporting"
+)
+
+// Wire-format types for the /v1/admin/prism-notices endpoints. Snake case,
+// like the public notices they configure.
+
+type adminNoticeTargeting struct {
+	UserIDs           []string `json:"user_ids"`
+	MinPrismVersion   string   `json:"min_prism_version"`
+	MaxPrismVersion   string   `json:"max_prism_version"`
+	ClientTypes       []string `json:"client_types"`
+	RolloutPercentage int      `json:"rollout_percentage"`
+}
+
+type adminPrismNotice struct {
+	ID              string               `json:"id"`
+	Message         string               `json:"message"`
+	URL             string               `json:"url"`
+	Severity        severity             `json:"severity"`
+	DurationSeconds *float64             `json:"duration_seconds"`
+	Frequency       string               `json:"frequency"`
+	ActiveFrom      *time.Time           `json:"active_from"`
+	ActiveUntil     *time.Time           `json:"active_until"`
+	Targeting       adminNoticeTargeting `json:"targeting"`
+	CreatedAt       time.Time            `json:"created_at"`
+	UpdatedAt       time.Time            `json:"updated_at"`
+}
+
+type adminPrismNoticesResponse struct {
+	Notices []adminPrismNotice `json:"notices"`
+}
+
+// adminPrismNoticeRequest is the body of a create or a replace. Every
+// targeting field is optional; rollout_percentage defaults to 100 and
+// frequency to always.
+type adminPrismNoticeRequest struct {
+	Message         string     `json:"message"`
+	URL             string     `json:"url"`
+	Severity        string     `json:"severity"`
+	DurationSeconds *float64   `json:"duration_seconds"`
+	Frequency       string     `json:"frequency"`
+	ActiveFrom      *time.Time `json:"active_from"`
+	ActiveUntil     *time.Time `json:"active_until"`
+	Targeting       struct {
+		UserIDs           []string `json:"user_ids"`
+		MinPrismVersion   string   `json:"min_prism_version"`
+		MaxPrismVersion   string   `json:"max_prism_version"`
+		ClientTypes       []string `json:"client_types"`
+		RolloutPercentage *int     `json:"rollout_percentage"`
+	} `json:"targeting"`
+}
+
+const adminBodyMaxBytes = 64 << 10
+
+func adminPrismNoticeFromDomain(notice domain.PrismNotice) (adminPrismNotice, error) {
+	wireSeverity, err := severityFromApp(notice.Severity)
+	if err != nil {
+		return adminPrismNotice{}, err
+	}
+
+	nonNil := func(s []string) []string {
+		if s == nil {
+			return []string{}
+		}
+		return s
+	}
+
+	return adminPrismNotice{
+		ID:              notice.ID,
+		Message:         notice.Message,
+		URL:             notice.URL,
+		Severity:        wireSeverity,
+		DurationSeconds: notice.DurationSeconds,
+		Frequency:       string(notice.Frequency),
+		ActiveFrom:      notice.ActiveFrom,
+		ActiveUntil:     notice.ActiveUntil,
+		Targeting: adminNoticeTargeting{
+			UserIDs:           nonNil(notice.Targeting.UserIDs),
+			MinPrismVersion:   notice.Targeting.MinPrismVersion,
+			MaxPrismVersion:   notice.Targeting.MaxPrismVersion,
+			ClientTypes:       nonNil(notice.Targeting.ClientTypes),
+			RolloutPercentage: notice.Targeting.RolloutPercentage,
+		},
+		CreatedAt: notice.CreatedAt,
+		UpdatedAt: notice.UpdatedAt,
+	}, nil
+}
+
+// parseAdminPrismNoticeRequest reads a create or replace body. Unknown
+// fields are rejected so a misspelled targeting rule can't silently widen
+// a notice to everyone. Validation beyond the shape is left to the app.
+func parseAdminPrismNoticeRequest(w http.ResponseWriter, r *http.Request) (domain.PrismNotice, *apiError) {
+	if !hasJSONContentType(r) {
+		e := newAPIError(http.StatusUnsupportedMediaType, errorCodeUnsupportedMediaType, "Content-Type must be application/json")
+		return domain.PrismNotice{}, &e
+	}
+
+	defer r.Body.Close()
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, adminBodyMaxBytes))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			e := newAPIError(http.StatusRequestEntityTooLarge, errorCodeRequestTooLarge, "Request body too large")
+			return domain.PrismNotice{}, &e
+		}
+		e := badRequestError("Failed to read request body")
+		return domain.PrismNotice{}, &e
+	}
+
+	var request adminPrismNoticeRequest
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&request); err != nil {
+		e := badRequestError(fmt.Sprintf("Invalid request body: %s", err.Error()))
+		return domain.PrismNotice{}, &e
+	}
+
+	rolloutPercentage := 100
+	if request.Targeting.RolloutPercentage != nil {
+		rolloutPercentage = *request.Targeting.RolloutPercentage
+	}
+
+	frequency := domain.NoticeFrequencyAlways
+	if request.Frequency != "" {
+		frequency = domain.NoticeFrequency(request.Frequency)
+	}
+
+	return domain.PrismNotice{
+		Message:         request.Message,
+		URL:             request.URL,
+		Severity:        domain.NoticeSeverity(request.Severity),
+		DurationSeconds: request.DurationSeconds,
+		Frequency:       frequency,
+		ActiveFrom:      request.ActiveFrom,
+		ActiveUntil:     request.ActiveUntil,
+		Targeting: domain.NoticeTargeting{
+			UserIDs:           request.Targeting.UserIDs,
+			MinPrismVersion:   request.Targeting.MinPrismVersion,
+			MaxPrismVersion:   request.Targeting.MaxPrismVersion,
+			ClientTypes:       request.Targeting.ClientTypes,
+			RolloutPercentage: rolloutPercentage,
+		},
+	}, nil
+}
+
+// writeAdminPrismNoticeError writes the response for an error from one of
+// the admin use cases. Validation errors are ours, so their text is safe to
+// return.
+func writeAdminPrismNoticeError(ctx context.Context, w http.ResponseWriter, err error, fallbackMessage string) {
+	if errors.Is(err, domain.ErrInvalidPrismNotice) {
+		writeErrorResponse(ctx, w, badRequestError(err.Error()))
+		return
+	}
+	if !errors.Is(err, domain.ErrPrismNoticeNotFound) {
+		logging.FromContext(ctx).ErrorContext(ctx, fallbackMessage, "error", err.Error())
+	}
+	writeErrorResponse(ctx, w, apiErrorFromDomain(err, fallbackMessage))
+}
+
+func writeAdminJSONResponse(ctx context.Context, w http.ResponseWriter, statusCode int, body any) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		reporting.Report(ctx, fmt.Errorf("failed to marshal admin response: %w", err))
+		writeErrorResponse(ctx, w, internalError())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(statusCode)
+	if _, err := w.Write(data); err != nil {
+		logging.FromContext(ctx).ErrorContext(ctx, "Failed to write response", "error", err.Error())
+	}
+}
+
+func writeAdminPrismNotice(ctx context.Context, w http.ResponseWriter, statusCode int, notice domain.PrismNotice) {
+	wire, err := adminPrismNoticeFromDomain(notice)
+	if err != nil {
+		reporting.Report(ctx, fmt.Errorf("failed to convert prism notice: %w", err))
+		writeErrorResponse(ctx, w, internalError())
+		return
+	}
+	writeAdminJSONResponse(ctx, w, statusCode, wire)
+}
+
+func mustBuildAdminRouteMiddleware(
+	name string,
+	adminAuthMiddleware func(http.HandlerFunc) http.HandlerFunc,
+	rootLogger *slog.Logger,
+	sentryMiddleware func(http.HandlerFunc) http.HandlerFunc,
+	blocklistConfig BlocklistConfig,
+) (func(http.HandlerFunc) http.HandlerFunc, func()) {
+	return mustBuildRouteMiddleware(
+		RouteSpec{
+			Name: name,
+			// Admin tooling calls these directly, never a browser
+			AllowedOrigins: nil,
+			BearerAuth:     adminAuthMiddleware,
+			RateLimits: []RateLimit{
+				// Low: a handful of admins, and the key check sits behind it
+				IPRateLimit(0.5, 30),
+			},
+		},
+		rootLogger,
+		sentryMiddleware,
+		blocklistConfig,
+	)
+}
+
+func MakeAdminListPrismNoticesHandler(
+	listPrismNotices app.ListPrismNotices,
+	adminAuthMiddleware func(http.HandlerFunc) http.HandlerFunc,
+	rootLogger *slog.Logger,
+	sentryMiddleware func(http.HandlerFunc) http.HandlerFunc,
+	blocklistConfig BlocklistConfig,
+) (http.HandlerFunc, func()) {
+	middleware, stop := mustBuildAdminRouteMiddleware("admin-list-prism-notices", adminAuthMiddleware, rootLogger, sentryMiddleware, blocklistConfig)
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		notices, err := listPrismNotices(ctx)
+		if err != nil {
+			writeAdminPrismNoticeError(ctx, w, err, "Failed to list prism notices")
+			return
+		}
+
+		wireNotices := make([]adminPrismNotice, 0, len(notices))
+		for _, notice := range notices {
+			wire, err := adminPrismNoticeFromDomain(notice)
+			if err != nil {
+				reporting.Report(ctx, fmt.Errorf("failed to convert prism notice: %w", err))
+				writeErrorResponse(ctx, w, internalError())
+				return
+			}
+			wireNotices = append(wireNotices, wire)
+		}
+
+		writeAdminJSONResponse(ctx, w, http.StatusOK, adminPrismNoticesResponse{Notices: wireNotices})
+	}
+
+	return middleware(handler), stop
+}
+
+func MakeAdminGetPrismNoticeHandler(
+	getPrismNotice app.GetPrismNotice,
+	adminAuthMiddleware func(http.HandlerFunc) http.HandlerFunc,
+	rootLogger *slog.Logger,
+	sentryMiddleware func(http.HandlerFunc) http.HandlerFunc,
+	blocklistConfig BlocklistConfig,
+) (http.HandlerFunc, func()) {
+	middleware, stop := mustBuildAdminRouteMiddleware("admin-get-prism-notice", adminAuthMiddleware, rootLogger, sentryMiddleware, blocklistConfig)
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		id := r.PathValue("id")
+
+		notice, err := getPrismNotice(ctx, id)
+		if err != nil {
+			writeAdminPrismNoticeError(ctx, w, err, "Failed to get prism notice")
+			return
+		}
+
+		writeAdminPrismNotice(ctx, w, http.StatusOK, notice)
+	}
+
+	return middleware(handler), stop
+}
+
+func MakeAdminCreatePrismNoticeHandler(
+	createPrismNotice app.CreatePrismNotice,
+	adminAuthMiddleware func(http.HandlerFunc) http.HandlerFunc,
+	rootLogger *slog.Logger,
+	sentryMiddleware func(http.HandlerFunc) http.HandlerFunc,
+	blocklistConfig BlocklistConfig,
+) (http.HandlerFunc, func()) {
+	middleware, stop := mustBuildAdminRouteMiddleware("admin-create-prism-notice", adminAuthMiddleware, rootLogger, sentryMiddleware, blocklistConfig)
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		notice, apiErr := parseAdminPrismNoticeRequest(w, r)
+		if apiErr != nil {
+			writeErrorResponse(ctx, w, *apiErr)
+			return
+		}
+
+		created, err := createPrismNotice(ctx, notice)
+		if err != nil {
+			writeAdminPrismNoticeError(ctx, w, err, "Failed to create prism notice")
+			return
+		}
+
+		logging.FromContext(ctx).InfoContext(ctx, "Created prism notice", "noticeID", created.ID)
+		writeAdminPrismNotice(ctx, w, http.StatusCreated, created)
+	}
+
+	return middleware(handler), stop
+}
+
+func MakeAdminUpdatePrismNoticeHandler(
+	updatePrismNotice app.UpdatePrismNotice,
+	adminAuthMiddleware func(http.HandlerFunc) http.HandlerFunc,
+	rootLogger *slog.Logger,
+	sentryMiddleware func(http.HandlerFunc) http.HandlerFunc,
+	blocklistConfig BlocklistConfig,
+) (http.HandlerFunc, func()) {
+	middleware, stop := mustBuildAdminRouteMiddleware("admin-update-prism-notice", adminAuthMiddleware, rootLogger, sentryMiddleware, blocklistConfig)
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		notice, apiErr := parseAdminPrismNoticeRequest(w, r)
+		if apiErr != nil {
+			writeErrorResponse(ctx, w, *apiErr)
+			return
+		}
+		notice.ID = r.PathValue("id")
+
+		updated, err := updatePrismNotice(ctx, notice)
+		if err != nil {
+			writeAdminPrismNoticeError(ctx, w, err, "Failed to update prism notice")
+			return
+		}
+
+		logging.FromContext(ctx).InfoContext(ctx, "Updated prism notice", "noticeID", updated.ID)
+		writeAdminPrismNotice(ctx, w, http.StatusOK, updated)
+	}
+
+	return middleware(handler), stop
+}
+
+func MakeAdminDeletePrismNoticeHandler(
+	deletePrismNotice app.DeletePrismNotice,
+	adminAuthMiddleware func(http.HandlerFunc) http.HandlerFunc,
+	rootLogger *slog.Logger,
+	sentryMiddleware func(http.HandlerFunc) http.HandlerFunc,
+	blocklistConfig BlocklistConfig,
+) (http.HandlerFunc, func()) {
+	middleware, stop := mustBuildAdminRouteMiddleware("admin-delete-prism-notice", adminAuthMiddleware, rootLogger, sentryMiddleware, blocklistConfig)
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		id := r.PathValue("id")
+
+		if err := deletePrismNotice(ctx, id); err != nil {
+			writeAdminPrismNoticeError(ctx, w, err, "Failed to delete prism notice")
+			return
+		}
+
+		logging.FromContext(ctx).InfoContext(ctx, "Deleted prism notice", "noticeID", id)
+		w.WriteHeader(http.StatusNoContent)
+	}
+
+	return middleware(handler), stop
+}
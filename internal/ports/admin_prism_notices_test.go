@@ -0,0 +1,296 @@
+package ports_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Amund211/flashlight/internal/domain"
+	"github.com/Amund211/flashlight/internal/ports"
+)
+
+const testAdminAPIKey = "admin-key"
+
+func TestAdminPrismNoticeHandlers(t *testing.T) {
+	t.Parallel()
+
+	createdAt := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+	stored := domain.PrismNotice{
+		ID:              "3d1e9b62-8c4a-4f0b-b7d5-2a9e6c1f4b83",
+		Message:         "Check out your wrapped",
+		URL:             "https://prismoverlay.com/wrapped",
+		Severity:        domain.NoticeSeverityInfo,
+		DurationSeconds: new(60.0),
+		Frequency:       domain.NoticeFrequencyUntilAcknowledged,
+		ActiveUntil:     new(time.Date(2027, time.February, 1, 0, 0, 0, 0, time.UTC)),
+		Targeting:       domain.NoticeTargeting{ClientTypes: []string{"prism"}, RolloutPercentage: 100},
+		CreatedAt:       createdAt,
+		UpdatedAt:       createdAt,
+	}
+
+	adminAuth := ports.NewAdminAuthMiddleware([]string{testAdminAPIKey})
+
+	do := func(t *testing.T, handler http.HandlerFunc, method, target, body string, configure func(*http.Request)) *httptest.ResponseRecorder {
+		t.Helper()
+		r := httptest.NewRequestWithContext(t.Context(), method, target, strings.NewReader(body))
+		r.Header.Set(ports.AdminAPIKeyHeader, testAdminAPIKey)
+		if body != "" {
+			withJSONContentType(r)
+		}
+		withRequestIP(r, "1.2.3.4")
+		if configure != nil {
+			configure(r)
+		}
+		w := httptest.NewRecorder()
+		handler(w, r)
+		return w
+	}
+
+	newListHandler := func(t *testing.T, auth func(http.HandlerFunc) http.HandlerFunc) http.HandlerFunc {
+		t.Helper()
+		handler, stop := ports.MakeAdminListPrismNoticesHandler(
+			func(ctx context.Context) ([]domain.PrismNotice, error) {
+				return []domain.PrismNotice{stored}, nil
+			},
+			auth,
+			authTestLogger,
+			noopAuthMiddleware,
+			emptyBlocklistConfig,
+		)
+		t.Cleanup(stop)
+		return handler
+	}
+
+	t.Run("requests without a valid key are rejected", func(t *testing.T) {
+		t.Parallel()
+
+		for name, tc := range map[string]struct {
+			auth func(http.HandlerFunc) http.HandlerFunc
+			key  string
+		}{
+			"missing key":          {auth: adminAuth, key: ""},
+			"wrong key":            {auth: adminAuth, key: "admin-key-but-longer"},
+			"no keys configured":   {auth: ports.NewAdminAuthMiddleware(nil), key: testAdminAPIKey},
+			"blank key configured": {auth: ports.NewAdminAuthMiddleware([]string{""}), key: ""},
+		} {
+			t.Run(name, func(t *testing.T) {
+				t.Parallel()
+
+				w := do(t, newListHandler(t, tc.auth), http.MethodGet, "/v1/admin/prism-notices", "", func(r *http.Request) {
+					r.Header.Set(ports.AdminAPIKeyHeader, tc.key)
+				})
+
+				require.Equal(t, http.StatusUnauthorized, w.Code)
+				requireOpenAPIResponse(t, "GET /v1/admin/prism-notices", w)
+			})
+		}
+	})
+
+	t.Run("list", func(t *testing.T) {
+		t.Parallel()
+
+		w := do(t, newListHandler(t, adminAuth), http.MethodGet, "/v1/admin/prism-notices", "", nil)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		requireOpenAPIResponse(t, "GET /v1/admin/prism-notices", w)
+		require.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+		require.JSONEq(t, `{"notices": [{
+			"id": "3d1e9b62-8c4a-4f0b-b7d5-2a9e6c1f4b83",
+			"message": "Check out your wrapped",
+			"url": "https://prismoverlay.com/wrapped",
+			"severity": "info",
+			"duration_seconds": 60,
+			"frequency": "until_acknowledged",
+			"active_from": null,
+			"active_until": "2027-02-01T00:00:00Z",
+			"targeting": {
+				"user_ids": [],
+				"min_prism_version": "",
+				"max_prism_version": "",
+				"client_types": ["prism"],
+				"rollout_percentage": 100
+			},
+			"created_at": "2026-03-01T12:00:00Z",
+			"updated_at": "2026-03-01T12:00:00Z"
+		}]}`, w.Body.String())
+	})
+
+	t.Run("get", func(t *testing.T) {
+		t.Parallel()
+
+		handler, stop := ports.MakeAdminGetPrismNoticeHandler(
+			func(ctx context.Context, id string) (domain.PrismNotice, error) {
+				if id != stored.ID {
+					return domain.PrismNotice{}, fmt.Errorf("failed to get: %w", domain.ErrPrismNoticeNotFound)
+				}
+				return stored, nil
+			},
+			adminAuth,
+			authTestLogger,
+			noopAuthMiddleware,
+			emptyBlocklistConfig,
+		)
+		t.Cleanup(stop)
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("GET /v1/admin/prism-notices/{id}", handler)
+
+		w := do(t, mux.ServeHTTP, http.MethodGet, "/v1/admin/prism-notices/"+stored.ID, "", nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		requireOpenAPIResponse(t, "GET /v1/admin/prism-notices/{id}", w)
+
+		w = do(t, mux.ServeHTTP, http.MethodGet, "/v1/admin/prism-notices/unknown", "", nil)
+		require.Equal(t, http.StatusNotFound, w.Code)
+		requireOpenAPIResponse(t, "GET /v1/admin/prism-notices/{id}", w)
+	})
+
+	t.Run("create", func(t *testing.T) {
+		t.Parallel()
+
+		var received domain.PrismNotice
+		handler, stop := ports.MakeAdminCreatePrismNoticeHandler(
+			func(ctx context.Context, notice domain.PrismNotice) (domain.PrismNotice, error) {
+				if notice.Message == "" {
+					return domain.PrismNotice{}, fmt.Errorf("%w: message is empty", domain.ErrInvalidPrismNotice)
+				}
+				received = notice
+				notice.ID = stored.ID
+				notice.CreatedAt = createdAt
+				notice.UpdatedAt = createdAt
+				return notice, nil
+			},
+			adminAuth,
+			authTestLogger,
+			noopAuthMiddleware,
+			emptyBlocklistConfig,
+		)
+		t.Cleanup(stop)
+
+		t.Run("omitted fields default to an untargeted notice", func(t *testing.T) {
+			w := do(t, handler, http.MethodPost, "/v1/admin/prism-notices",
+				`{"message": "Maintenance tonight", "severity": "warning", "targeting": {"min_prism_version": "v1.12.0"}}`, nil)
+
+			require.Equal(t, http.StatusCreated, w.Code)
+			requireOpenAPIResponse(t, "POST /v1/admin/prism-notices", w)
+			require.Equal(t, domain.PrismNotice{
+				Message:   "Maintenance tonight",
+				Severity:  domain.NoticeSeverityWarning,
+				Frequency: domain.NoticeFrequencyAlways,
+				Targeting: domain.NoticeTargeting{
+					MinPrismVersion:   "v1.12.0",
+					RolloutPercentage: 100,
+				},
+			}, received)
+
+			var response struct {
+				ID string `json:"id"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			require.Equal(t, stored.ID, response.ID)
+		})
+
+		t.Run("an explicit zero rollout is kept", func(t *testing.T) {
+			w := do(t, handler, http.MethodPost, "/v1/admin/prism-notices",
+				`{"message": "Dark launch", "severity": "info", "targeting": {"rollout_percentage": 0}}`, nil)
+
+			require.Equal(t, http.StatusCreated, w.Code)
+			require.Equal(t, 0, received.Targeting.RolloutPercentage)
+		})
+
+		t.Run("frequency", func(t *testing.T) {
+			w := do(t, handler, http.MethodPost, "/v1/admin/prism-notices",
+				`{"message": "Once a day", "severity": "info", "frequency": "daily"}`, nil)
+
+			require.Equal(t, http.StatusCreated, w.Code)
+			requireOpenAPIResponse(t, "POST /v1/admin/prism-notices", w)
+			require.Equal(t, domain.NoticeFrequencyDaily, received.Frequency)
+		})
+
+		for name, tc := range map[string]struct {
+			body      string
+			configure func(*http.Request)
+			status    int
+		}{
+			"invalid notice":     {body: `{"severity": "info"}`, status: http.StatusBadRequest},
+			"unknown field":      {body: `{"message": "Hi", "severity": "info", "targeting": {"user_id": ["typo"]}}`, status: http.StatusBadRequest},
+			"malformed json":     {body: `{"message": `, status: http.StatusBadRequest},
+			"body too large":     {body: `{"message": "` + strings.Repeat("a", 64<<10) + `"}`, status: http.StatusRequestEntityTooLarge},
+			"wrong content type": {body: `{"message": "Hi", "severity": "info"}`, configure: func(r *http.Request) { r.Header.Set("Content-Type", "text/plain") }, status: http.StatusUnsupportedMediaType},
+		} {
+			t.Run(name, func(t *testing.T) {
+				w := do(t, handler, http.MethodPost, "/v1/admin/prism-notices", tc.body, tc.configure)
+
+				require.Equal(t, tc.status, w.Code)
+				requireOpenAPIResponse(t, "POST /v1/admin/prism-notices", w)
+			})
+		}
+	})
+
+	t.Run("update takes the id from the path", func(t *testing.T) {
+		t.Parallel()
+
+		handler, stop := ports.MakeAdminUpdatePrismNoticeHandler(
+			func(ctx context.Context, notice domain.PrismNotice) (domain.PrismNotice, error) {
+				if notice.ID != stored.ID {
+					return domain.PrismNotice{}, domain.ErrPrismNoticeNotFound
+				}
+				notice.CreatedAt = createdAt
+				notice.UpdatedAt = createdAt.Add(time.Hour)
+				return notice, nil
+			},
+			adminAuth,
+			authTestLogger,
+			noopAuthMiddleware,
+			emptyBlocklistConfig,
+		)
+		t.Cleanup(stop)
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("PUT /v1/admin/prism-notices/{id}", handler)
+
+		body := `{"message": "Wrapped is out", "severity": "info"}`
+		w := do(t, mux.ServeHTTP, http.MethodPut, "/v1/admin/prism-notices/"+stored.ID, body, nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		requireOpenAPIResponse(t, "PUT /v1/admin/prism-notices/{id}", w)
+
+		w = do(t, mux.ServeHTTP, http.MethodPut, "/v1/admin/prism-notices/unknown", body, nil)
+		require.Equal(t, http.StatusNotFound, w.Code)
+		requireOpenAPIResponse(t, "PUT /v1/admin/prism-notices/{id}", w)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		t.Parallel()
+
+		handler, stop := ports.MakeAdminDeletePrismNoticeHandler(
+			func(ctx context.Context, id string) error {
+				if id != stored.ID {
+					return domain.ErrPrismNoticeNotFound
+				}
+				return nil
+			},
+			adminAuth,
+			authTestLogger,
+			noopAuthMiddleware,
+			emptyBlocklistConfig,
+		)
+		t.Cleanup(stop)
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("DELETE /v1/admin/prism-notices/{id}", handler)
+
+		w := do(t, mux.ServeHTTP, http.MethodDelete, "/v1/admin/prism-notices/"+stored.ID, "", nil)
+		require.Equal(t, http.StatusNoContent, w.Code)
+		requireOpenAPIResponse(t, "DELETE /v1/admin/prism-notices/{id}", w)
+
+		w = do(t, mux.ServeHTTP, http.MethodDelete, "/v1/admin/prism-notices/unknown", "", nil)
+		require.Equal(t, http.StatusNotFound, w.Code)
+		requireOpenAPIResponse(t, "DELETE /v1/admin/prism-notices/{id}", w)
+	})
+}
@@ -11,7 +11,6 @@ import (
 
 	"github.com/Amund211/flashlight/internal/logging"
 	"github.com/Amund211/flashlight/internal/proofofwork"
-	"github.com/Amund211/flashlight/internal/ratelimiting"
 	"github.com/Amund211/flashlight/internal/reporting"
 )
 
@@ -51,42 +50,23 @@ func MakeAnonymousChallengeHandler(
 	sentryMiddleware func(http.HandlerFunc) http.HandlerFunc,
 	blocklistConfig BlocklistConfig,
 ) (http.HandlerFunc, func()) {
-	// Same budget as the login endpoint it feeds: the handshake is one
-	// challenge per login, so a caller that can't log in any faster has no
-	// use for challenges any faster either.
-	ipLimiter, stopIPLimiter := ratelimiting.NewTokenBucketRateLimiter(
-		ratelimiting.RefillPerSecond(1),
-		ratelimiting.BurstSize(60),
-	)
-	ipRateLimiter := ratelimiting.NewRequestBasedRateLimiter(
-		ipLimiter,
-		IPHashKeyFunc,
-	)
-	ipLimiterLong, stopIPLimiterLong := ratelimiting.NewTokenBucketRateLimiter(
-		ratelimiting.RefillPerSecond(0.1),
-		ratelimiting.BurstSize(200),
-	)
-	ipRateLimiterLong := ratelimiting.NewRequestBasedRateLimiter(
-		ipLimiterLong,
-		IPHashKeyFunc,
-	)
-
-	middleware := ComposeMiddlewares(
-		NewRequestLoggerMiddleware(rootLogger),
+	middleware, stop := mustBuildRouteMiddleware(
+		RouteSpec{
+			Name:           "auth-anonymous-challenge",
+			AllowedOrigins: allowedOrigins,
+			RateLimits: []RateLimit{
+				// Same budget as the login endpoint it feeds: the handshake is one
+				// challenge per login, so a caller that can't log in any faster has no
+				// use for challenges any faster either.
+				IPRateLimit(1, 60),
+				IPRateLimit(0.1, 200),
+			},
+		},
+		rootLogger,
 		sentryMiddleware,
-		BuildBlocklistMiddleware(blocklistConfig),
-		buildMetricsMiddleware("auth-anonymous-challenge"),
-		NewReportingMetaMiddleware("auth-anonymous-challenge"),
-		BuildCORSMiddleware(allowedOrigins),
-		NewRateLimitMiddleware(ipRateLimiter, makeOnAuthLimitExceeded(ipRateLimiter)),
-		NewRateLimitMiddleware(ipRateLimiterLong, makeOnAuthLimitExceeded(ipRateLimiterLong)),
+		blocklistConfig,
 	)
 
-	stop := func() {
-		stopIPLimiter()
-		stopIPLimiterLong()
-	}
-
 	handler := func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 
@@ -99,14 +79,14 @@ func MakeAnonymousChallengeHandler(
 			logging.FromContext(ctx).InfoContext(ctx, "Rejected anonymous challenge with non-JSON content type",
 				slog.String("contentType", r.Header.Get("Content-Type")),
 			)
-			http.Error(w, "Content-Type must be application/json", http.StatusUnsupportedMediaType)
+			writeErrorResponse(ctx, w, newAPIError(http.StatusUnsupportedMediaType, errorCodeUnsupportedMediaType, "Content-Type must be application/json"))
 			return
 		}
 
 		var body anonymousChallengeRequest
 		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, authBodyMaxBytes)).Decode(&body); err != nil {
 			logging.FromContext(ctx).InfoContext(ctx, "Failed to decode anonymous challenge body", "error", err.Error())
-			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			writeErrorResponse(ctx, w, badRequestError("Invalid request body"))
 			return
 		}
 		// The same validation as login, because the two have to agree on
@@ -115,7 +95,7 @@ func MakeAnonymousChallengeHandler(
 		// of that agreement is challengeMaxLength, which has to cover the
 		// largest blob any userId passing this check can mint.
 		if !validAnonymousUserID(body.UserID) {
-			http.Error(w, "Invalid userId", http.StatusBadRequest)
+			writeErrorResponse(ctx, w, badRequestError("Invalid userId"))
 			return
 		}
 
@@ -126,7 +106,7 @@ func MakeAnonymousChallengeHandler(
 		if err != nil {
 			logging.FromContext(ctx).ErrorContext(ctx, "Failed to issue anonymous challenge", "error", err.Error())
 			reporting.Report(ctx, fmt.Errorf("issue anonymous challenge: %w", err))
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			writeErrorResponse(ctx, w, internalError())
 			return
 		}
 
@@ -49,6 +49,7 @@ func TestAnonymousChallengeHandler(t *testing.T) {
 		w := postChallenge(t, handler, "1.2.3.4")
 
 		require.Equal(t, http.StatusOK, w.Code)
+		requireOpenAPIResponse(t, "POST /v1/auth/anonymous/challenge", w)
 		require.Equal(t, "application/json", w.Header().Get("Content-Type"))
 		require.Equal(t, "no-store", w.Header().Get("Cache-Control"),
 			"a challenge is single-use and bound to one ip; no intermediary should hand it out twice")
@@ -146,6 +147,7 @@ func TestAnonymousChallengeHandler(t *testing.T) {
 		handler(w, r)
 
 		require.Equal(t, http.StatusOK, w.Code)
+		requireOpenAPIResponse(t, "POST /v1/auth/anonymous/challenge", w)
 		require.Equal(t, "unknown", sawClientType)
 	})
 
@@ -164,6 +166,7 @@ func TestAnonymousChallengeHandler(t *testing.T) {
 		handler(w, r)
 
 		require.Equal(t, http.StatusOK, w.Code)
+		requireOpenAPIResponse(t, "POST /v1/auth/anonymous/challenge", w)
 		require.Equal(t, origin, w.Header().Get("Access-Control-Allow-Origin"))
 	})
 
@@ -192,6 +195,7 @@ func TestAnonymousChallengeHandler(t *testing.T) {
 				handler(w, r)
 
 				require.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+				requireOpenAPIResponse(t, "POST /v1/auth/anonymous/challenge", w)
 			})
 		}
 	})
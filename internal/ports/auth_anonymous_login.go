@@ -15,7 +15,6 @@ import (
 	"github.com/Amund211/flashlight/internal/app"
 	"github.com/Amund211/flashlight/internal/logging"
 	"github.com/Amund211/flashlight/internal/proofofwork"
-	"github.com/Amund211/flashlight/internal/ratelimiting"
 	"github.com/Amund211/flashlight/internal/reporting"
 )
 
@@ -93,7 +92,7 @@ func rejectProofOfWork(ctx context.Context, w http.ResponseWriter, r *http.Reque
 	metrics.powRejectedLoginCount.Add(ctx, 1, metric.WithAttributes(
 		append(GetClient(r).MetricAttributes(), attribute.String("reason", reason))...,
 	))
-	http.Error(w, "Invalid proof of work", http.StatusForbidden)
+	writeErrorResponse(ctx, w, newAPIError(http.StatusForbidden, errorCodeInvalidProofOfWork, "Invalid proof of work"))
 }
 
 // MakeAnonymousLoginHandler returns a handler for POST /v1/auth/anonymous/login.
@@ -107,44 +106,25 @@ func MakeAnonymousLoginHandler(
 	sentryMiddleware func(http.HandlerFunc) http.HandlerFunc,
 	blocklistConfig BlocklistConfig,
 ) (http.HandlerFunc, func()) {
-	// Every login costs an IP-cap UPDATE plus a multi-statement transaction, and
-	// the endpoint is unauthenticated by definition — so it is rate limited on
-	// the only thing we have before doing any of that work, the request IP.
-	// There is no userId bucket: the body is attacker-controlled, so keying on
-	// it would just make the limit free to evade.
-	ipLimiter, stopIPLimiter := ratelimiting.NewTokenBucketRateLimiter(
-		ratelimiting.RefillPerSecond(1),
-		ratelimiting.BurstSize(60),
-	)
-	ipRateLimiter := ratelimiting.NewRequestBasedRateLimiter(
-		ipLimiter,
-		IPHashKeyFunc,
-	)
-	ipLimiterLong, stopIPLimiterLong := ratelimiting.NewTokenBucketRateLimiter(
-		ratelimiting.RefillPerSecond(0.1),
-		ratelimiting.BurstSize(200),
-	)
-	ipRateLimiterLong := ratelimiting.NewRequestBasedRateLimiter(
-		ipLimiterLong,
-		IPHashKeyFunc,
-	)
-
-	middleware := ComposeMiddlewares(
-		NewRequestLoggerMiddleware(rootLogger),
+	middleware, stop := mustBuildRouteMiddleware(
+		RouteSpec{
+			Name:           "auth-anonymous-login",
+			AllowedOrigins: allowedOrigins,
+			RateLimits: []RateLimit{
+				// Every login costs an IP-cap UPDATE plus a multi-statement transaction, and
+				// the endpoint is unauthenticated by definition — so it is rate limited on
+				// the only thing we have before doing any of that work, the request IP.
+				// There is no userId bucket: the body is attacker-controlled, so keying on
+				// it would just make the limit free to evade.
+				IPRateLimit(1, 60),
+				IPRateLimit(0.1, 200),
+			},
+		},
+		rootLogger,
 		sentryMiddleware,
-		BuildBlocklistMiddleware(blocklistConfig),
-		buildMetricsMiddleware("auth-anonymous-login"),
-		NewReportingMetaMiddleware("auth-anonymous-login"),
-		BuildCORSMiddleware(allowedOrigins),
-		NewRateLimitMiddleware(ipRateLimiter, makeOnAuthLimitExceeded(ipRateLimiter)),
-		NewRateLimitMiddleware(ipRateLimiterLong, makeOnAuthLimitExceeded(ipRateLimiterLong)),
+		blocklistConfig,
 	)
 
-	stop := func() {
-		stopIPLimiter()
-		stopIPLimiterLong()
-	}
-
 	handler := func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 
@@ -165,24 +145,24 @@ func MakeAnonymousLoginHandler(
 			logging.FromContext(ctx).InfoContext(ctx, "Rejected anonymous login with non-JSON content type",
 				slog.String("contentType", r.Header.Get("Content-Type")),
 			)
-			http.Error(w, "Content-Type must be application/json", http.StatusUnsupportedMediaType)
+			writeErrorResponse(ctx, w, newAPIError(http.StatusUnsupportedMediaType, errorCodeUnsupportedMediaType, "Content-Type must be application/json"))
 			return
 		}
 
 		var body anonymousLoginRequest
 		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, authBodyMaxBytes)).Decode(&body); err != nil {
 			logging.FromContext(ctx).InfoContext(ctx, "Failed to decode anonymous login body", "error", err.Error())
-			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			writeErrorResponse(ctx, w, badRequestError("Invalid request body"))
 			return
 		}
 
 		if !validAnonymousUserID(body.UserID) {
-			http.Error(w, "Invalid userId", http.StatusBadRequest)
+			writeErrorResponse(ctx, w, badRequestError("Invalid userId"))
 			return
 		}
 
 		if body.Challenge == "" || len(body.Challenge) > challengeMaxLength {
-			http.Error(w, "Invalid challenge", http.StatusBadRequest)
+			writeErrorResponse(ctx, w, badRequestError("Invalid challenge"))
 			return
 		}
 		// A non-empty solution is required even though at difficulty 0 the
@@ -191,7 +171,7 @@ func MakeAnonymousLoginHandler(
 		// discovering that the day we raise the difficulty is exactly the
 		// retrofit this whole mechanism exists to avoid.
 		if body.Solution == "" || len(body.Solution) > solutionMaxLength {
-			http.Error(w, "Invalid solution", http.StatusBadRequest)
+			writeErrorResponse(ctx, w, badRequestError("Invalid solution"))
 			return
 		}
 
@@ -231,7 +211,7 @@ func MakeAnonymousLoginHandler(
 		if err != nil {
 			logging.FromContext(ctx).ErrorContext(ctx, "Anonymous login failed", "error", err.Error())
 			reporting.Report(ctx, fmt.Errorf("anonymous login: %w", err))
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			writeErrorResponse(ctx, w, internalError())
 			return
 		}
 
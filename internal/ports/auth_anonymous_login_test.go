@@ -55,6 +55,7 @@ func TestAnonymousLoginHandler(t *testing.T) {
 		handler(w, r)
 
 		require.Equal(t, http.StatusOK, w.Code)
+		requireOpenAPIResponse(t, "POST /v1/auth/anonymous/login", w)
 		require.Equal(t, "application/json", w.Header().Get("Content-Type"))
 
 		var resp struct {
@@ -98,6 +99,7 @@ func TestAnonymousLoginHandler(t *testing.T) {
 		w := httptest.NewRecorder()
 		handler(w, r)
 		require.Equal(t, http.StatusOK, w.Code)
+		requireOpenAPIResponse(t, "POST /v1/auth/anonymous/login", w)
 		require.Equal(t, "no-store", w.Header().Get("Cache-Control"),
 			"session responses carry a bearer token; no intermediary should cache them")
 	})
@@ -127,6 +129,7 @@ func TestAnonymousLoginHandler(t *testing.T) {
 		w := httptest.NewRecorder()
 		handler(w, r)
 		require.Equal(t, http.StatusOK, w.Code)
+		requireOpenAPIResponse(t, "POST /v1/auth/anonymous/login", w)
 
 		var resp struct {
 			ExpiresInSeconds int64 `json:"expiresInSeconds"`
@@ -165,6 +168,7 @@ func TestAnonymousLoginHandler(t *testing.T) {
 		handler(w, r)
 
 		require.Equal(t, http.StatusOK, w.Code)
+		requireOpenAPIResponse(t, "POST /v1/auth/anonymous/login", w)
 		require.Equal(t, origin, w.Header().Get("Access-Control-Allow-Origin"))
 	})
 
@@ -199,6 +203,7 @@ func TestAnonymousLoginHandler(t *testing.T) {
 				handler(w, r)
 
 				require.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+				requireOpenAPIResponse(t, "POST /v1/auth/anonymous/login", w)
 			})
 		}
 	})
@@ -227,6 +232,7 @@ func TestAnonymousLoginHandler(t *testing.T) {
 		handler(w, r)
 
 		require.Equal(t, http.StatusOK, w.Code)
+		requireOpenAPIResponse(t, "POST /v1/auth/anonymous/login", w)
 	})
 
 	t.Run("rate limits per ip", func(t *testing.T) {
@@ -294,6 +300,7 @@ func TestAnonymousLoginHandler(t *testing.T) {
 		w := httptest.NewRecorder()
 		handler(w, r)
 		require.Equal(t, http.StatusBadRequest, w.Code)
+		requireOpenAPIResponse(t, "POST /v1/auth/anonymous/login", w)
 	})
 
 	t.Run("400 on userId longer than the hard cap", func(t *testing.T) {
@@ -311,6 +318,7 @@ func TestAnonymousLoginHandler(t *testing.T) {
 		w := httptest.NewRecorder()
 		handler(w, r)
 		require.Equal(t, http.StatusBadRequest, w.Code)
+		requireOpenAPIResponse(t, "POST /v1/auth/anonymous/login", w)
 	})
 
 	t.Run("accepts a userId longer than the legacy header truncation point", func(t *testing.T) {
@@ -341,6 +349,7 @@ func TestAnonymousLoginHandler(t *testing.T) {
 		w := httptest.NewRecorder()
 		handler(w, r)
 		require.Equal(t, http.StatusOK, w.Code)
+		requireOpenAPIResponse(t, "POST /v1/auth/anonymous/login", w)
 		require.Equal(t, longID, sawUserID)
 	})
 
@@ -357,6 +366,7 @@ func TestAnonymousLoginHandler(t *testing.T) {
 		w := httptest.NewRecorder()
 		handler(w, r)
 		require.Equal(t, http.StatusBadRequest, w.Code)
+		requireOpenAPIResponse(t, "POST /v1/auth/anonymous/login", w)
 	})
 }
 
@@ -513,6 +523,7 @@ func TestAnonymousLoginProofOfWork(t *testing.T) {
 		w := httptest.NewRecorder()
 		challengeHandler(w, r)
 		require.Equal(t, http.StatusOK, w.Code)
+		requireOpenAPIResponse(t, "POST /v1/auth/anonymous/challenge", w)
 
 		var challenge struct {
 			Challenge  string `json:"challenge"`
@@ -75,7 +75,7 @@ func NewBearerAuthMiddleware(validate app.ValidateSession, nowFunc func() time.T
 
 			sessionID, ok := bearerFromAuthorization(r)
 			if !ok {
-				http.Error(w, "Malformed Authorization header", http.StatusUnauthorized)
+				writeErrorResponse(r.Context(), w, newAPIError(http.StatusUnauthorized, errorCodeUnauthorized, "Malformed Authorization header"))
 				return
 			}
 
@@ -85,11 +85,11 @@ func NewBearerAuthMiddleware(validate app.ValidateSession, nowFunc func() time.T
 			case errors.Is(err, domain.ErrAuthSessionNotFound),
 				errors.Is(err, domain.ErrAuthSessionRevoked),
 				errors.Is(err, domain.ErrAuthSessionExpired):
-				http.Error(w, "Invalid or expired session", http.StatusUnauthorized)
+				writeErrorResponse(ctx, w, apiErrorFromDomain(err, "Internal server error"))
 				return
 			case err != nil:
 				logging.FromContext(ctx).ErrorContext(ctx, "Failed to validate bearer session", "error", err.Error())
-				http.Error(w, "Internal server error", http.StatusInternalServerError)
+				writeErrorResponse(ctx, w, internalError())
 				return
 			}
 
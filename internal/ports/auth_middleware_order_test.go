@@ -15,12 +15,11 @@ import (
 	"github.com/Amund211/flashlight/internal/ports"
 )
 
-// The nine handlers below mount the bearer auth middleware, each assembling
-// its own chain by hand. They all have to mount it in the same slot: behind
-// the blocklist and the IP limiters, inside CORS, and ahead of the user-id
-// limiter. See NewBearerAuthMiddleware for why. Nothing but this test keeps
-// the nine in agreement — both arrangements serve correct responses, one just
-// does it without a throttle in front.
+// The ten handlers below mount the bearer auth middleware. They all have to
+// mount it in the same slot: behind the blocklist and the IP limiters, inside
+// CORS, and ahead of the user-id limiter. See NewBearerAuthMiddleware for why.
+// BuildRouteMiddleware puts it there by construction; this test checks that
+// every handler actually goes through it with the spec it should have.
 
 // The bearer probe rejects every request, so it short-circuits ahead of the
 // handler bodies and these are never called. They exist to satisfy the
@@ -37,7 +36,11 @@ func unusedGetTags(context.Context, string, *string) (domain.Tags, error) {
 	return domain.Tags{}, nil
 }
 
-func unusedGetPrismNotices(context.Context, string, string, app.UpdateSelection) []app.PrismNotice {
+func unusedGetPrismNotices(context.Context, string, string, string, app.UpdateSelection) []app.PrismNotice {
+	return nil
+}
+
+func unusedAcknowledgePrismNotice(context.Context, string, string) error {
 	return nil
 }
 
@@ -49,14 +52,30 @@ func unusedGetPlayerPITs(context.Context, string, time.Time, time.Time) ([]domai
 	return nil, nil
 }
 
-func unusedComputeSessions(context.Context, []domain.PlayerPIT, time.Time, time.Time) []domain.Session {
+func unusedGetWrapped(context.Context, string, int) (app.Wrapped, error) {
+	return app.Wrapped{}, nil
+}
+
+func unusedComputeSessions(context.Context, []domain.PlayerPIT, time.Time, time.Time, app.SessionOptions) []domain.Session {
 	return nil
 }
 
-func unusedGetSessionAt(context.Context, string, time.Time) (app.SessionAtResult, error) {
+func unusedGetSessionAt(context.Context, string, time.Time, app.SessionOptions) (app.SessionAtResult, error) {
 	return app.SessionAtResult{}, nil
 }
 
+func unusedCompare(context.Context, []string, time.Time, time.Time, app.SessionOptions) (app.Comparison, error) {
+	return app.Comparison{}, nil
+}
+
+func unusedGetGames(context.Context, string, time.Time, time.Time, int, app.SessionOptions) (app.GamesPage, error) {
+	return app.GamesPage{}, nil
+}
+
+func unusedGetWinstreaks(context.Context, string, time.Time, time.Time, domain.Gamemode) ([]domain.ReconstructedWinstreak, error) {
+	return nil, nil
+}
+
 func unusedGetAccountByUsername(context.Context, string) (domain.Account, error) {
 	return domain.Account{}, nil
 }
@@ -135,6 +154,22 @@ func bearerMountCases(t *testing.T) []bearerMountCase {
 				return handler
 			},
 		},
+		{
+			name:             "prism-notices-ack",
+			aboveUserIDBurst: 40,
+			path:             "/v1/prism-notices/3d1e9b62-8c4a-4f0b-b7d5-2a9e6c1f4b83/ack",
+			build: func(t *testing.T, bearerAuthMiddleware func(http.HandlerFunc) http.HandlerFunc, blocklistConfig ports.BlocklistConfig) http.HandlerFunc {
+				handler, stop := ports.MakeAcknowledgePrismNoticeHandler(
+					unusedAcknowledgePrismNotice,
+					authTestLogger,
+					noopAuthMiddleware,
+					bearerAuthMiddleware,
+					blocklistConfig,
+				)
+				t.Cleanup(stop)
+				return handler
+			},
+		},
 		{
 			name:             "history",
 			aboveUserIDBurst: 100,
@@ -193,6 +228,63 @@ func bearerMountCases(t *testing.T) []bearerMountCase {
 				return handler
 			},
 		},
+		{
+			name:             "games",
+			aboveUserIDBurst: 50,
+			path:             "/v1/games",
+			hasCORS:          true,
+			build: func(t *testing.T, bearerAuthMiddleware func(http.HandlerFunc) http.HandlerFunc, blocklistConfig ports.BlocklistConfig) http.HandlerFunc {
+				handler, stop := ports.MakeGetGamesHandler(
+					unusedGetGames,
+					unusedRegisterUserVisit,
+					allowedOrigins,
+					authTestLogger,
+					noopAuthMiddleware,
+					bearerAuthMiddleware,
+					blocklistConfig,
+				)
+				t.Cleanup(stop)
+				return handler
+			},
+		},
+		{
+			name:             "winstreaks",
+			aboveUserIDBurst: 50,
+			path:             "/v1/winstreaks",
+			hasCORS:          true,
+			build: func(t *testing.T, bearerAuthMiddleware func(http.HandlerFunc) http.HandlerFunc, blocklistConfig ports.BlocklistConfig) http.HandlerFunc {
+				handler, stop := ports.MakeGetWinstreaksHandler(
+					unusedGetWinstreaks,
+					unusedRegisterUserVisit,
+					allowedOrigins,
+					authTestLogger,
+					noopAuthMiddleware,
+					bearerAuthMiddleware,
+					blocklistConfig,
+				)
+				t.Cleanup(stop)
+				return handler
+			},
+		},
+		{
+			name:             "compare",
+			aboveUserIDBurst: 25,
+			path:             "/v1/compare",
+			hasCORS:          true,
+			build: func(t *testing.T, bearerAuthMiddleware func(http.HandlerFunc) http.HandlerFunc, blocklistConfig ports.BlocklistConfig) http.HandlerFunc {
+				handler, stop := ports.MakeCompareHandler(
+					unusedCompare,
+					unusedRegisterUserVisit,
+					allowedOrigins,
+					authTestLogger,
+					noopAuthMiddleware,
+					bearerAuthMiddleware,
+					blocklistConfig,
+				)
+				t.Cleanup(stop)
+				return handler
+			},
+		},
 		{
 			name:             "wrapped",
 			aboveUserIDBurst: 100,
@@ -200,6 +292,25 @@ func bearerMountCases(t *testing.T) []bearerMountCase {
 			hasCORS:          true,
 			build: func(t *testing.T, bearerAuthMiddleware func(http.HandlerFunc) http.HandlerFunc, blocklistConfig ports.BlocklistConfig) http.HandlerFunc {
 				handler, stop := ports.MakeGetWrappedHandler(
+					unusedGetWrapped,
+					unusedRegisterUserVisit,
+					allowedOrigins,
+					authTestLogger,
+					noopAuthMiddleware,
+					bearerAuthMiddleware,
+					blocklistConfig,
+				)
+				t.Cleanup(stop)
+				return handler
+			},
+		},
+		{
+			name:             "wrapped-period",
+			aboveUserIDBurst: 100,
+			path:             "/v1/wrapped/01234567-89ab-cdef-0123-456789abcdef?month=2024-05",
+			hasCORS:          true,
+			build: func(t *testing.T, bearerAuthMiddleware func(http.HandlerFunc) http.HandlerFunc, blocklistConfig ports.BlocklistConfig) http.HandlerFunc {
+				handler, stop := ports.MakeGetWrappedPeriodHandler(
 					unusedGetPlayerPITs,
 					unusedComputeSessions,
 					unusedRegisterUserVisit,
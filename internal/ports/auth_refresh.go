@@ -10,7 +10,6 @@ import (
 	"github.com/Amund211/flashlight/internal/app"
 	"github.com/Amund211/flashlight/internal/domain"
 	"github.com/Amund211/flashlight/internal/logging"
-	"github.com/Amund211/flashlight/internal/ratelimiting"
 	"github.com/Amund211/flashlight/internal/reporting"
 )
 
@@ -26,50 +25,31 @@ func MakeAuthRefreshHandler(
 	sentryMiddleware func(http.HandlerFunc) http.HandlerFunc,
 	blocklistConfig BlocklistConfig,
 ) (http.HandlerFunc, func()) {
-	// A refresh costs a SELECT-FOR-UPDATE transaction on the session row (no
-	// IP-cap UPDATE — that one is on the login path only), and the bearer is
-	// only checked inside that transaction, so an unknown token is just as
-	// expensive as a valid one. The request IP is all we can key on before
-	// touching the database.
-	ipLimiter, stopIPLimiter := ratelimiting.NewTokenBucketRateLimiter(
-		ratelimiting.RefillPerSecond(1),
-		ratelimiting.BurstSize(60),
-	)
-	ipRateLimiter := ratelimiting.NewRequestBasedRateLimiter(
-		ipLimiter,
-		IPHashKeyFunc,
-	)
-	ipLimiterLong, stopIPLimiterLong := ratelimiting.NewTokenBucketRateLimiter(
-		ratelimiting.RefillPerSecond(0.1),
-		ratelimiting.BurstSize(200),
-	)
-	ipRateLimiterLong := ratelimiting.NewRequestBasedRateLimiter(
-		ipLimiterLong,
-		IPHashKeyFunc,
-	)
-
-	middleware := ComposeMiddlewares(
-		NewRequestLoggerMiddleware(rootLogger),
+	middleware, stop := mustBuildRouteMiddleware(
+		RouteSpec{
+			Name:           "auth-refresh",
+			AllowedOrigins: allowedOrigins,
+			RateLimits: []RateLimit{
+				// A refresh costs a SELECT-FOR-UPDATE transaction on the session row (no
+				// IP-cap UPDATE — that one is on the login path only), and the bearer is
+				// only checked inside that transaction, so an unknown token is just as
+				// expensive as a valid one. The request IP is all we can key on before
+				// touching the database.
+				IPRateLimit(1, 60),
+				IPRateLimit(0.1, 200),
+			},
+		},
+		rootLogger,
 		sentryMiddleware,
-		BuildBlocklistMiddleware(blocklistConfig),
-		buildMetricsMiddleware("auth-refresh"),
-		NewReportingMetaMiddleware("auth-refresh"),
-		BuildCORSMiddleware(allowedOrigins),
-		NewRateLimitMiddleware(ipRateLimiter, makeOnAuthLimitExceeded(ipRateLimiter)),
-		NewRateLimitMiddleware(ipRateLimiterLong, makeOnAuthLimitExceeded(ipRateLimiterLong)),
+		blocklistConfig,
 	)
 
-	stop := func() {
-		stopIPLimiter()
-		stopIPLimiterLong()
-	}
-
 	handler := func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 
 		sessionID, ok := bearerFromAuthorization(r)
 		if !ok {
-			http.Error(w, "Missing bearer token", http.StatusUnauthorized)
+			writeErrorResponse(ctx, w, newAPIError(http.StatusUnauthorized, errorCodeUnauthorized, "Missing bearer token"))
 			return
 		}
 
@@ -80,17 +60,17 @@ func MakeAuthRefreshHandler(
 		case errors.Is(err, domain.ErrAuthSessionRefreshTooSoon):
 			// Not a 401: the session is fine, so re-auth is the wrong reaction.
 			logging.FromContext(ctx).InfoContext(ctx, "Session refreshed too soon", "statusCode", http.StatusTooManyRequests)
-			http.Error(w, "Session refreshed too recently", http.StatusTooManyRequests)
+			writeErrorResponse(ctx, w, apiErrorFromDomain(err, "Internal server error"))
 			return
 		case errors.Is(err, domain.ErrAuthSessionNotFound),
 			errors.Is(err, domain.ErrAuthSessionRevoked),
 			errors.Is(err, domain.ErrAuthSessionRefreshExpired):
-			http.Error(w, "Session is no longer refreshable", http.StatusUnauthorized)
+			writeErrorResponse(ctx, w, newAPIError(http.StatusUnauthorized, errorCodeInvalidSession, "Session is no longer refreshable"))
 			return
 		case err != nil:
 			logging.FromContext(ctx).ErrorContext(ctx, "Session refresh failed", "error", err.Error())
 			reporting.Report(ctx, fmt.Errorf("session refresh: %w", err))
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			writeErrorResponse(ctx, w, internalError())
 			return
 		}
 
@@ -37,6 +37,7 @@ func TestAuthRefreshHandler(t *testing.T) {
 		w := httptest.NewRecorder()
 		handler(w, r)
 		require.Equal(t, http.StatusOK, w.Code)
+		requireOpenAPIResponse(t, "POST /v1/auth/refresh", w)
 		require.Equal(t, "my-session-id", sawSessionID)
 	})
 
@@ -60,6 +61,7 @@ func TestAuthRefreshHandler(t *testing.T) {
 		w := httptest.NewRecorder()
 		handler(w, r)
 		require.Equal(t, http.StatusOK, w.Code)
+		requireOpenAPIResponse(t, "POST /v1/auth/refresh", w)
 		require.Equal(t, "no-store", w.Header().Get("Cache-Control"),
 			"refresh responses carry a bearer token; no intermediary should cache them")
 	})
@@ -89,6 +91,7 @@ func TestAuthRefreshHandler(t *testing.T) {
 		handler(w, r)
 
 		require.Equal(t, http.StatusOK, w.Code)
+		requireOpenAPIResponse(t, "POST /v1/auth/refresh", w)
 		require.Equal(t, origin, w.Header().Get("Access-Control-Allow-Origin"))
 	})
 
@@ -151,6 +154,7 @@ func TestAuthRefreshHandler(t *testing.T) {
 		w := httptest.NewRecorder()
 		handler(w, r)
 		require.Equal(t, http.StatusUnauthorized, w.Code)
+		requireOpenAPIResponse(t, "POST /v1/auth/refresh", w)
 	})
 
 	t.Run("429 on "+domain.ErrAuthSessionRefreshTooSoon.Error(), func(t *testing.T) {
@@ -167,6 +171,7 @@ func TestAuthRefreshHandler(t *testing.T) {
 		w := httptest.NewRecorder()
 		handler(w, r)
 		require.Equal(t, http.StatusTooManyRequests, w.Code)
+		requireOpenAPIResponse(t, "POST /v1/auth/refresh", w)
 	})
 
 	for _, sentinel := range []error{
@@ -187,6 +192,7 @@ func TestAuthRefreshHandler(t *testing.T) {
 			w := httptest.NewRecorder()
 			handler(w, r)
 			require.Equal(t, http.StatusUnauthorized, w.Code)
+			requireOpenAPIResponse(t, "POST /v1/auth/refresh", w)
 		})
 	}
 }
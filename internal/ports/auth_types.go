@@ -11,7 +11,6 @@ import (
 	"github.com/Amund211/flashlight/internal/app"
 	"github.com/Amund211/flashlight/internal/domain"
 	"github.com/Amund211/flashlight/internal/logging"
-	"github.com/Amund211/flashlight/internal/ratelimiting"
 	"github.com/Amund211/flashlight/internal/reporting"
 )
 
@@ -142,7 +141,7 @@ func writeAuthJSONResponse(ctx context.Context, w http.ResponseWriter, what stri
 	if err != nil {
 		logging.FromContext(ctx).ErrorContext(ctx, "Failed to marshal auth response", "what", what, "error", err.Error())
 		reporting.Report(ctx, fmt.Errorf("marshal %s response: %w", what, err))
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		writeErrorResponse(ctx, w, internalError())
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
@@ -154,21 +153,6 @@ func writeAuthJSONResponse(ctx context.Context, w http.ResponseWriter, what stri
 	}
 }
 
-// makeOnAuthLimitExceeded is the 429 responder shared by the auth
-// endpoints. Same shape as the per-endpoint ones elsewhere; shared here
-// because login and refresh want identical behaviour.
-func makeOnAuthLimitExceeded(rateLimiter ratelimiting.RequestRateLimiter) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		ctx := r.Context()
-
-		statusCode := http.StatusTooManyRequests
-
-		logging.FromContext(ctx).InfoContext(ctx, "Rate limit exceeded", "statusCode", statusCode, "reason", "ratelimit exceeded", "key", rateLimiter.KeyFor(r))
-
-		http.Error(w, "Rate limit exceeded", statusCode)
-	}
-}
-
 // bearerFromAuthorization extracts the bearer token from an Authorization
 // header. Case-insensitive on the scheme per RFC 6750. Returns ok=false
 // if the header is missing or malformed.
@@ -1,6 +1,7 @@
 package ports
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 
@@ -18,16 +19,6 @@ const (
 	clientVersionMissing   = "missing"
 )
 
-// knownPrismVersions is the allowlist of accepted X-Client-Version values for
-// the prism client. Keep it SHORT — prune old versions as clients age out — to
-// bound metric cardinality. No prism client sends these headers yet; v1.12.0
-// (the upcoming release) is seeded so the pipeline is ready when it ships.
-var knownPrismVersions = map[string]struct{}{
-	"v1.11.1-dev": {},
-	"v1.12.0":     {},
-	"v1.12.1-dev": {},
-}
-
 // Client is the normalized identity of the calling client, derived from the
 // X-Client-Type and X-Client-Version request headers. Raw values are retained
 // (truncated) for logging; Type and Version are the bounded, allowlisted values
@@ -39,14 +30,37 @@ type Client struct {
 	Version    string
 }
 
-// GetClient reads and normalizes the client identity from the request headers.
+type clientCtxKey struct{}
+
+// NewClientMiddleware reads and normalizes the client identity from the
+// request headers, for GetClient. isKnownPrismVersion is the allowlist of
+// accepted X-Client-Version values for the prism client. It must stay SHORT
+// to bound metric cardinality, and is called on every request.
+func NewClientMiddleware(isKnownPrismVersion func(version string) bool) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			client := readClient(r, isKnownPrismVersion)
+			next(w, r.WithContext(context.WithValue(r.Context(), clientCtxKey{}, client)))
+		}
+	}
+}
+
+// GetClient returns the client identity read by NewClientMiddleware. Requests
+// that didn't pass through it have no prism versions allowlisted.
 func GetClient(r *http.Request) Client {
+	if client, ok := r.Context().Value(clientCtxKey{}).(Client); ok {
+		return client
+	}
+	return readClient(r, func(string) bool { return false })
+}
+
+func readClient(r *http.Request, isKnownPrismVersion func(version string) bool) Client {
 	// Truncate raw values before retaining them — they are client-controlled
 	// and must not bloat logs (mirrors GetUserID).
 	rawType := fmt.Sprintf("%.50s", r.Header.Get("X-Client-Type"))
 	rawVersion := fmt.Sprintf("%.50s", r.Header.Get("X-Client-Version"))
 
-	clientType, clientVersion := normalizeClient(rawType, rawVersion)
+	clientType, clientVersion := normalizeClient(rawType, rawVersion, isKnownPrismVersion)
 
 	return Client{
 		RawType:    rawType,
@@ -61,7 +75,7 @@ func GetClient(r *http.Request) Client {
 // never a cross-product — it is one of the allowlisted prism pairs,
 // (rainbow, evergreen), (missing, missing), or (unknown, unknown) — which keeps
 // the metric label cardinality bounded regardless of client input.
-func normalizeClient(rawType, rawVersion string) (clientType string, clientVersion string) {
+func normalizeClient(rawType, rawVersion string, isKnownPrismVersion func(version string) bool) (clientType string, clientVersion string) {
 	// Both headers absent.
 	if rawType == "" && rawVersion == "" {
 		return clientTypeMissing, clientVersionMissing
@@ -69,7 +83,7 @@ func normalizeClient(rawType, rawVersion string) (clientType string, clientVersi
 
 	// prism with an allowlisted version.
 	if rawType == clientTypePrism {
-		if _, ok := knownPrismVersions[rawVersion]; ok {
+		if isKnownPrismVersion(rawVersion) {
 			return clientTypePrism, rawVersion
 		}
 	}
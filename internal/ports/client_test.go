@@ -2,6 +2,7 @@ package ports_test
 
 import (
 	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 
@@ -10,6 +11,19 @@ import (
 	"github.com/Amund211/flashlight/internal/ports"
 )
 
+// getClient is the client GetClient returns for request behind a
+// NewClientMiddleware that only allowlists prism v1.12.0
+func getClient(request *http.Request) ports.Client {
+	var client ports.Client
+	middleware := ports.NewClientMiddleware(func(version string) bool {
+		return version == "v1.12.0"
+	})
+	middleware(func(w http.ResponseWriter, r *http.Request) {
+		client = ports.GetClient(r)
+	})(httptest.NewRecorder(), request)
+	return client
+}
+
 func TestGetClient(t *testing.T) {
 	t.Parallel()
 
@@ -147,7 +161,7 @@ func TestGetClient(t *testing.T) {
 			}
 			request := &http.Request{Header: header}
 
-			client := ports.GetClient(request)
+			client := getClient(request)
 			require.Equal(t, c.expectedType, client.Type)
 			require.Equal(t, c.expectedVersion, client.Version)
 		})
@@ -167,7 +181,7 @@ func TestGetClientTruncatesRawValues(t *testing.T) {
 		},
 	}
 
-	client := ports.GetClient(request)
+	client := getClient(request)
 
 	require.Len(t, client.RawType, 50)
 	require.Len(t, client.RawVersion, 50)
@@ -188,7 +202,7 @@ func TestClientMetricAttributes(t *testing.T) {
 		},
 	}
 
-	attrs := ports.GetClient(request).MetricAttributes()
+	attrs := getClient(request).MetricAttributes()
 	require.Len(t, attrs, 2)
 
 	got := map[string]string{}
@@ -198,3 +212,19 @@ func TestClientMetricAttributes(t *testing.T) {
 	require.Equal(t, "prism", got["client_type"])
 	require.Equal(t, "v1.12.0", got["client_version"])
 }
+
+func TestGetClientWithoutMiddleware(t *testing.T) {
+	t.Parallel()
+
+	get := func(clientType, version string) ports.Client {
+		return ports.GetClient(&http.Request{
+			Header: http.Header{
+				"X-Client-Type":    []string{clientType},
+				"X-Client-Version": []string{version},
+			},
+		})
+	}
+
+	require.Equal(t, "unknown", get("prism", "v1.12.0").Version, "no prism versions are allowlisted")
+	require.Equal(t, "evergreen", get("rainbow", "evergreen").Version)
+}
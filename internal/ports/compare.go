@@ -0,0 +1,244 @@
+package ports
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/Amund211/flashlight/internal/app"
+	"github.com/Amund211/flashlight/internal/domain"
+	"github.com/Amund211/flashlight/internal/logging"
+	"github.com/Amund211/flashlight/internal/reporting"
+	"github.com/Amund211/flashlight/internal/strutils"
+)
+
+// rainbowComparePlayer is how a player progressed in the compared range. The
+// deltas and ratios are for the requested gamemode.
+type rainbowComparePlayer struct {
+	UUID string `json:"uuid"`
+	// Start and End are nil when the player has no stats in the range
+	Start            *rainbowPlayerDataPIT `json:"start"`
+	End              *rainbowPlayerDataPIT `json:"end"`
+	ExperienceGained int64                 `json:"experienceGained"`
+	StarsGained      float64               `json:"starsGained"`
+	Deltas           rainbowStatsDelta     `json:"deltas"`
+	Ratios           rainbowRatios         `json:"ratios"`
+	Sessions         int                   `json:"sessions"`
+	SessionHours     float64               `json:"sessionHours"`
+}
+
+// rainbowPlayedTogether is how long two players' sessions overlapped
+type rainbowPlayedTogether struct {
+	UUIDs               [2]string `json:"uuids"`
+	Hours               float64   `json:"hours"`
+	OverlappingSessions int       `json:"overlappingSessions"`
+}
+
+type rainbowCompareResponse struct {
+	Gamemode       string                  `json:"gamemode"`
+	Players        []rainbowComparePlayer  `json:"players"`
+	PlayedTogether []rainbowPlayedTogether `json:"playedTogether"`
+}
+
+func comparisonToRainbowCompareResponse(comparison *app.Comparison, gamemode domain.Gamemode) (rainbowCompareResponse, error) {
+	rainbowGamemode, err := gamemodeToRainbowGamemode(gamemode)
+	if err != nil {
+		return rainbowCompareResponse{}, err
+	}
+
+	response := rainbowCompareResponse{
+		Gamemode:       rainbowGamemode,
+		Players:        make([]rainbowComparePlayer, 0, len(comparison.Players)),
+		PlayedTogether: make([]rainbowPlayedTogether, 0, len(comparison.PlayedTogether)),
+	}
+
+	for _, player := range comparison.Players {
+		rainbowPlayer := rainbowComparePlayer{
+			UUID:     player.UUID,
+			Sessions: len(player.Sessions),
+		}
+		for _, session := range player.Sessions {
+			rainbowPlayer.SessionHours += session.End.QueriedAt.Sub(session.Start.QueriedAt).Hours()
+		}
+
+		if player.First != nil && player.Last != nil {
+			rainbowPlayer.Start = new(playerToRainbowPlayerDataPIT(player.First))
+			rainbowPlayer.End = new(playerToRainbowPlayerDataPIT(player.Last))
+
+			deltas := domain.NewSessionDeltas(player.First, player.Last)
+			gamemodeDelta, ok := deltas.GamemodeDelta(gamemode)
+			if !ok {
+				return rainbowCompareResponse{}, fmt.Errorf("unknown gamemode: %q", gamemode)
+			}
+			rainbowPlayer.ExperienceGained = deltas.Experience
+			rainbowPlayer.StarsGained = player.Last.Stars() - player.First.Stars()
+			rainbowPlayer.Deltas = statsDeltaToRainbowStatsDelta(&gamemodeDelta)
+			rainbowPlayer.Ratios = rainbowRatios{
+				FKDR: domain.Ratio(gamemodeDelta.FinalKills, gamemodeDelta.FinalDeaths),
+				KDR:  domain.Ratio(gamemodeDelta.Kills, gamemodeDelta.Deaths),
+				WLR:  domain.Ratio(gamemodeDelta.Wins, gamemodeDelta.Losses),
+				BBLR: domain.Ratio(gamemodeDelta.BedsBroken, gamemodeDelta.BedsLost),
+			}
+		}
+
+		response.Players = append(response.Players, rainbowPlayer)
+	}
+
+	for _, together := range comparison.PlayedTogether {
+		response.PlayedTogether = append(response.PlayedTogether, rainbowPlayedTogether{
+			UUIDs:               together.UUIDs,
+			Hours:               together.Duration.Hours(),
+			OverlappingSessions: together.OverlappingSessions,
+		})
+	}
+
+	return response, nil
+}
+
+func MakeCompareHandler(
+	compare app.Compare,
+	registerUserVisit app.RegisterUserVisit,
+	allowedOrigins *DomainSuffixes,
+	rootLogger *slog.Logger,
+	sentryMiddleware func(http.HandlerFunc) http.HandlerFunc,
+	bearerAuthMiddleware func(http.HandlerFunc) http.HandlerFunc,
+	blocklistConfig BlocklistConfig,
+) (http.HandlerFunc, func()) {
+	middleware, stop := mustBuildRouteMiddleware(
+		RouteSpec{
+			Name:           "compare",
+			AllowedOrigins: allowedOrigins,
+			BearerAuth:     bearerAuthMiddleware,
+			// Loads the stats of several players per request
+			RateLimits: []RateLimit{
+				IPRateLimit(1, 40),
+				IdentityRateLimit(0.5, 10),
+			},
+			RegisterUserVisit: registerUserVisit,
+			Compress:          true,
+		},
+		rootLogger,
+		sentryMiddleware,
+		blocklistConfig,
+	)
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		defer r.Body.Close()
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, 4<<10))
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				writeErrorResponse(ctx, w, newAPIError(http.StatusRequestEntityTooLarge, errorCodeRequestTooLarge, "Request body too large"))
+				return
+			}
+			reporting.Report(ctx, fmt.Errorf("failed to read request body: %w", err))
+			writeErrorResponse(ctx, w, badRequestError("Failed to read request body"))
+			return
+		}
+		request := struct {
+			UUIDs                      []string  `json:"uuids"`
+			Start                      time.Time `json:"start"`
+			End                        time.Time `json:"end"`
+			InactivityThresholdMinutes *int      `json:"inactivityThresholdMinutes"`
+			Gamemode                   string    `json:"gamemode"`
+		}{}
+		err = json.Unmarshal(body, &request)
+		if err != nil {
+			logging.FromContext(ctx).WarnContext(ctx, "Failed to parse request body", "error", err)
+			writeErrorResponse(ctx, w, badRequestError("Failed to parse request body"))
+			return
+		}
+
+		ctx = reporting.AddExtrasToContext(ctx, map[string]string{
+			"start": request.Start.Format(time.RFC3339),
+			"end":   request.End.Format(time.RFC3339),
+		})
+
+		if len(request.UUIDs) < app.MinComparePlayers || len(request.UUIDs) > app.MaxComparePlayers {
+			writeErrorResponse(ctx, w, badRequestError(fmt.Sprintf("uuids must contain between %d and %d players", app.MinComparePlayers, app.MaxComparePlayers)))
+			return
+		}
+
+		uuids := make([]string, 0, len(request.UUIDs))
+		for _, rawUUID := range request.UUIDs {
+			uuid, err := strutils.NormalizeUUID(rawUUID)
+			if err != nil {
+				logging.FromContext(ctx).WarnContext(ctx, "Failed to normalize uuid", "error", err, "rawUUID", rawUUID)
+				writeErrorResponse(ctx, w, badRequestError("invalid uuid"))
+				return
+			}
+			if slices.Contains(uuids, uuid) {
+				writeErrorResponse(ctx, w, badRequestError("duplicate uuid"))
+				return
+			}
+			uuids = append(uuids, uuid)
+		}
+
+		ctx = logging.AddMetaToContext(ctx,
+			slog.Any("uuids", uuids),
+			slog.String("start", request.Start.Format(time.RFC3339)),
+			slog.String("end", request.End.Format(time.RFC3339)),
+		)
+
+		if request.Start.After(request.End) {
+			writeErrorResponse(ctx, w, badRequestError("Start time cannot be after end time"))
+			return
+		}
+
+		if request.End.Sub(request.Start) >= 400*24*time.Hour {
+			writeErrorResponse(ctx, w, badRequestError("Time interval is too long"))
+			return
+		}
+
+		sessionOptions, err := sessionOptionsFromRequest(request.InactivityThresholdMinutes, request.Gamemode)
+		if err != nil {
+			writeErrorResponse(ctx, w, badRequestError(err.Error()))
+			return
+		}
+		gamemode := sessionOptions.Gamemode
+		if gamemode == "" {
+			gamemode = domain.GamemodeOverall
+		}
+
+		logging.FromContext(ctx).InfoContext(ctx, "Handling compare request",
+			slog.Int("players", len(uuids)),
+			slog.String("gamemode", string(gamemode)),
+		)
+
+		comparison, err := compare(ctx, uuids, request.Start, request.End, sessionOptions)
+		if err != nil {
+			// NOTE: Compare implementations handle their own error reporting
+			writeErrorResponse(ctx, w, apiErrorFromDomain(err, "Failed to compare players"))
+			return
+		}
+
+		response, err := comparisonToRainbowCompareResponse(&comparison, gamemode)
+		if err != nil {
+			reporting.Report(ctx, fmt.Errorf("failed to convert comparison to response: %w", err))
+			writeErrorResponse(ctx, w, newAPIError(http.StatusInternalServerError, errorCodeInternal, "Failed to serialise response"))
+			return
+		}
+
+		marshalled, err := json.Marshal(response)
+		if err != nil {
+			reporting.Report(ctx, fmt.Errorf("failed to marshal response: %w", err))
+			writeErrorResponse(ctx, w, newAPIError(http.StatusInternalServerError, errorCodeInternal, "Failed to marshal response"))
+			return
+		}
+
+		logging.FromContext(ctx).InfoContext(ctx, "Returning comparison")
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(marshalled)
+	}
+
+	return middleware(handler), stop
+}
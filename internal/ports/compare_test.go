@@ -0,0 +1,235 @@
+package ports_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Amund211/flashlight/internal/app"
+	"github.com/Amund211/flashlight/internal/domain"
+	"github.com/Amund211/flashlight/internal/domaintest"
+	"github.com/Amund211/flashlight/internal/ports"
+)
+
+func TestMakeCompareHandler(t *testing.T) {
+	t.Parallel()
+
+	allowedOrigins, err := ports.NewDomainSuffixes("example.com", "test.com")
+	require.NoError(t, err)
+
+	testLogger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	noopMiddleware := func(h http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			h(w, r)
+		}
+	}
+
+	makeHandler := func(compare app.Compare) http.HandlerFunc {
+		stubRegisterUserVisit := func(ctx context.Context, userID string, ipHash string, userAgent string) (domain.User, error) {
+			return domain.User{}, nil
+		}
+		handler, stop := ports.MakeCompareHandler(
+			compare,
+			stubRegisterUserVisit,
+			allowedOrigins,
+			testLogger,
+			noopMiddleware,
+			noopMiddleware,
+			emptyBlocklistConfig,
+		)
+		t.Cleanup(stop)
+		return handler
+	}
+
+	makeRequest := func(body string) *http.Request {
+		return httptest.NewRequestWithContext(t.Context(), "POST", "/compare", strings.NewReader(body))
+	}
+
+	uuidA := "01234567-89ab-cdef-0123-456789abcdef"
+	uuidB := "11234567-89ab-cdef-0123-456789abcdef"
+	start := time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 6, 16, 0, 0, 0, 0, time.UTC)
+	rangeBody := `"start":"2024-06-15T00:00:00Z","end":"2024-06-16T00:00:00Z"`
+	uuidsBody := `"uuids":["` + uuidA + `","` + strings.ReplaceAll(uuidB, "-", "") + `"]`
+
+	bA := domaintest.NewPlayerBuilder(uuidA).FromDB()
+	firstA := bA.Fours().WithGamesPlayed(10).WithWins(5).WithLosses(5).WithFinalKills(20).WithFinalDeaths(10).Build(start.Add(time.Hour))
+	lastA := bA.WithExperience(1500).Fours().WithGamesPlayed(14).WithWins(8).WithLosses(6).WithFinalKills(26).WithFinalDeaths(11).Build(start.Add(2 * time.Hour))
+	sessionA := domain.Session{Start: firstA, End: lastA, Consecutive: true, Gamemode: domain.GamemodeFours}
+
+	comparison := app.Comparison{
+		Players: []app.PlayerComparison{
+			{UUID: uuidA, First: &firstA, Last: &lastA, Sessions: []domain.Session{sessionA}},
+			{UUID: uuidB, Sessions: []domain.Session{}},
+		},
+		PlayedTogether: []app.PlayedTogether{
+			{UUIDs: [2]string{uuidA, uuidB}, Duration: 90 * time.Minute, OverlappingSessions: 2},
+		},
+	}
+
+	type compareResponse struct {
+		Gamemode string `json:"gamemode"`
+		Players  []struct {
+			UUID             string  `json:"uuid"`
+			Start            *any    `json:"start"`
+			End              *any    `json:"end"`
+			ExperienceGained int64   `json:"experienceGained"`
+			Sessions         int     `json:"sessions"`
+			SessionHours     float64 `json:"sessionHours"`
+			Deltas           struct {
+				GamesPlayed int `json:"gamesPlayed"`
+				Wins        int `json:"wins"`
+			} `json:"deltas"`
+			Ratios struct {
+				FKDR float64 `json:"fkdr"`
+				WLR  float64 `json:"wlr"`
+			} `json:"ratios"`
+		} `json:"players"`
+		PlayedTogether []struct {
+			UUIDs               []string `json:"uuids"`
+			Hours               float64  `json:"hours"`
+			OverlappingSessions int      `json:"overlappingSessions"`
+		} `json:"playedTogether"`
+	}
+
+	t.Run("renders the comparison", func(t *testing.T) {
+		t.Parallel()
+
+		var gotUUIDs []string
+		var gotOptions app.SessionOptions
+		handler := makeHandler(func(ctx context.Context, uuids []string, gotStart, gotEnd time.Time, options app.SessionOptions) (app.Comparison, error) {
+			require.True(t, start.Equal(gotStart))
+			require.True(t, end.Equal(gotEnd))
+			gotUUIDs = uuids
+			gotOptions = options
+			return comparison, nil
+		})
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, makeRequest(`{`+uuidsBody+`,`+rangeBody+`,"gamemode":"fours"}`))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		requireOpenAPIResponse(t, "POST /v1/compare", w)
+		require.Equal(t, []string{uuidA, uuidB}, gotUUIDs)
+		require.Equal(t, domain.GamemodeFours, gotOptions.Gamemode)
+
+		var response compareResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.Equal(t, "fours", response.Gamemode)
+		require.Len(t, response.Players, 2)
+
+		a := response.Players[0]
+		require.Equal(t, uuidA, a.UUID)
+		require.NotNil(t, a.Start)
+		require.NotNil(t, a.End)
+		require.Equal(t, int64(1000), a.ExperienceGained)
+		require.Equal(t, 4, a.Deltas.GamesPlayed)
+		require.Equal(t, 3, a.Deltas.Wins)
+		require.InDelta(t, 6.0, a.Ratios.FKDR, 1e-9)
+		require.InDelta(t, 3.0, a.Ratios.WLR, 1e-9)
+		require.Equal(t, 1, a.Sessions)
+		require.InDelta(t, 1.0, a.SessionHours, 1e-9)
+
+		b := response.Players[1]
+		require.Equal(t, uuidB, b.UUID)
+		require.Nil(t, b.Start)
+		require.Nil(t, b.End)
+		require.Equal(t, 0, b.Sessions)
+
+		require.Len(t, response.PlayedTogether, 1)
+		require.Equal(t, []string{uuidA, uuidB}, response.PlayedTogether[0].UUIDs)
+		require.InDelta(t, 1.5, response.PlayedTogether[0].Hours, 1e-9)
+		require.Equal(t, 2, response.PlayedTogether[0].OverlappingSessions)
+	})
+
+	t.Run("defaults to overall", func(t *testing.T) {
+		t.Parallel()
+
+		handler := makeHandler(func(context.Context, []string, time.Time, time.Time, app.SessionOptions) (app.Comparison, error) {
+			return comparison, nil
+		})
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, makeRequest(`{`+uuidsBody+`,`+rangeBody+`}`))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		requireOpenAPIResponse(t, "POST /v1/compare", w)
+
+		var response compareResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.Equal(t, "overall", response.Gamemode)
+		require.Equal(t, 4, response.Players[0].Deltas.GamesPlayed)
+	})
+
+	t.Run("bad requests", func(t *testing.T) {
+		t.Parallel()
+
+		nine := make([]string, 0, 9)
+		for range 9 {
+			nine = append(nine, `"`+domaintest.NewUUID(t)+`"`)
+		}
+
+		for name, body := range map[string]string{
+			"one player":       `{"uuids":["` + uuidA + `"],` + rangeBody + `}`,
+			"too many players": `{"uuids":[` + strings.Join(nine, ",") + `],` + rangeBody + `}`,
+			"invalid uuid":     `{"uuids":["` + uuidA + `","nope"],` + rangeBody + `}`,
+			"duplicate uuid":   `{"uuids":["` + uuidA + `","` + strings.ToUpper(uuidA) + `"],` + rangeBody + `}`,
+			"start after end":  `{` + uuidsBody + `,"start":"2024-06-16T00:00:00Z","end":"2024-06-15T00:00:00Z"}`,
+			"range too long":   `{` + uuidsBody + `,"start":"2023-01-01T00:00:00Z","end":"2024-06-15T00:00:00Z"}`,
+			"invalid gamemode": `{` + uuidsBody + `,` + rangeBody + `,"gamemode":"eights"}`,
+			"malformed body":   `{`,
+		} {
+			t.Run(name, func(t *testing.T) {
+				t.Parallel()
+
+				called := false
+				handler := makeHandler(func(context.Context, []string, time.Time, time.Time, app.SessionOptions) (app.Comparison, error) {
+					called = true
+					return app.Comparison{}, nil
+				})
+
+				w := httptest.NewRecorder()
+				handler.ServeHTTP(w, makeRequest(body))
+
+				require.Equal(t, http.StatusBadRequest, w.Code)
+				requireOpenAPIResponse(t, "POST /v1/compare", w)
+				require.False(t, called)
+			})
+		}
+	})
+
+	t.Run("app errors", func(t *testing.T) {
+		t.Parallel()
+
+		for name, tc := range map[string]struct {
+			err  error
+			code int
+		}{
+			"internal":    {err: errors.New("db down"), code: http.StatusInternalServerError},
+			"unavailable": {err: domain.ErrTemporarilyUnavailable, code: http.StatusServiceUnavailable},
+		} {
+			t.Run(name, func(t *testing.T) {
+				t.Parallel()
+
+				handler := makeHandler(func(context.Context, []string, time.Time, time.Time, app.SessionOptions) (app.Comparison, error) {
+					return app.Comparison{}, tc.err
+				})
+
+				w := httptest.NewRecorder()
+				handler.ServeHTTP(w, makeRequest(`{`+uuidsBody+`,`+rangeBody+`}`))
+
+				require.Equal(t, tc.code, w.Code)
+				requireOpenAPIResponse(t, "POST /v1/compare", w)
+			})
+		}
+	})
+}
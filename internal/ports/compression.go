@@ -0,0 +1,254 @@
+package ports
+
+import (
+	"compress/gzip"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+
+	"github.com/Amund211/flashlight/internal/logging"
+)
+
+// compressMinSize is the smallest body worth compressing. Below this the
+// encoding overhead eats most of the savings, and error envelopes and the
+// like stay readable in a proxy log.
+const compressMinSize = 1 << 10
+
+const (
+	contentEncodingBrotli = "br"
+	contentEncodingGzip   = "gzip"
+)
+
+// negotiateContentEncoding picks the encoding for a response given the
+// request's Accept-Encoding, or "" for no encoding. Brotli is preferred over
+// gzip when the client weights them the same.
+func negotiateContentEncoding(acceptEncoding string) string {
+	weights := map[string]float64{}
+	wildcard, hasWildcard := 0.0, false
+
+	for part := range strings.SplitSeq(acceptEncoding, ",") {
+		coding, params, _ := strings.Cut(part, ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding == "" {
+			continue
+		}
+
+		weight := parseQualityValue(params)
+		if coding == "*" {
+			wildcard, hasWildcard = weight, true
+			continue
+		}
+		weights[coding] = weight
+	}
+
+	weightOf := func(coding string) float64 {
+		if weight, ok := weights[coding]; ok {
+			return weight
+		}
+		if hasWildcard {
+			return wildcard
+		}
+		return 0
+	}
+
+	best, bestWeight := "", 0.0
+	for _, coding := range []string{contentEncodingBrotli, contentEncodingGzip} {
+		if weight := weightOf(coding); weight > bestWeight {
+			best, bestWeight = coding, weight
+		}
+	}
+	return best
+}
+
+// parseQualityValue returns the q weight in the parameters of one element of
+// an Accept style header, 1 if there is none. A malformed weight is treated
+// as a refusal.
+func parseQualityValue(params string) float64 {
+	weight := 1.0
+	for param := range strings.SplitSeq(params, ";") {
+		name, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+		if !ok || !strings.EqualFold(strings.TrimSpace(name), "q") {
+			continue
+		}
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return 0
+		}
+		weight = parsed
+	}
+	return weight
+}
+
+// NewCompressionMiddleware compresses response bodies with brotli or gzip,
+// as negotiated with Accept-Encoding. Bodies shorter than compressMinSize
+// are sent as is.
+func NewCompressionMiddleware() func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Accept-Encoding")
+
+			encoding := negotiateContentEncoding(r.Header.Get("Accept-Encoding"))
+			if encoding == "" || r.Method == http.MethodHead {
+				next(w, r)
+				return
+			}
+
+			cw := &compressingResponseWriter{
+				ResponseWriter: w,
+				encoding:       encoding,
+				status:         http.StatusOK,
+			}
+			defer func() {
+				if err := cw.finish(); err != nil {
+					ctx := r.Context()
+					logging.FromContext(ctx).ErrorContext(ctx, "Failed to finish compressed response", slog.String("error", err.Error()))
+				}
+			}()
+
+			next(cw, r)
+		}
+	}
+}
+
+// compressingResponseWriter holds back the body until it knows whether it is
+// long enough to compress, then either streams it through the encoder or
+// writes it out unchanged.
+type compressingResponseWriter struct {
+	http.ResponseWriter
+
+	encoding string
+	status   int
+
+	headerWritten bool
+	buffer        []byte
+
+	// decided is set once the response has been committed, with encoder
+	// nil when the body goes out uncompressed
+	decided bool
+	encoder io.WriteCloser
+}
+
+func (cw *compressingResponseWriter) WriteHeader(status int) {
+	if cw.headerWritten {
+		return
+	}
+	cw.headerWritten = true
+	cw.status = status
+
+	if !bodyAllowedForStatus(status) {
+		cw.commit(false)
+	}
+}
+
+func (cw *compressingResponseWriter) Write(p []byte) (int, error) {
+	if !cw.headerWritten {
+		cw.WriteHeader(http.StatusOK)
+	}
+
+	if cw.decided {
+		if cw.encoder != nil {
+			return cw.encoder.Write(p)
+		}
+		return cw.ResponseWriter.Write(p)
+	}
+
+	cw.buffer = append(cw.buffer, p...)
+	if len(cw.buffer) < compressMinSize {
+		return len(p), nil
+	}
+
+	if err := cw.flushBuffer(cw.commit(true)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (cw *compressingResponseWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+// commit writes the headers, compressed or not, and returns the writer the
+// body should go to.
+func (cw *compressingResponseWriter) commit(compress bool) io.Writer {
+	cw.decided = true
+
+	header := cw.Header()
+	if header.Get("Content-Encoding") != "" {
+		// Already encoded by the handler
+		compress = false
+	}
+
+	if compress {
+		header.Set("Content-Encoding", cw.encoding)
+		header.Del("Content-Length")
+		// The compressed bytes are a different representation, so a strong
+		// ETag of the uncompressed body no longer holds. A weak one still
+		// matches on If-None-Match.
+		if etag := header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+			header.Set("ETag", "W/"+etag)
+		}
+
+		switch cw.encoding {
+		case contentEncodingBrotli:
+			cw.encoder = brotli.NewWriterLevel(cw.ResponseWriter, brotli.DefaultCompression)
+		default:
+			cw.encoder = gzip.NewWriter(cw.ResponseWriter)
+		}
+	}
+
+	cw.ResponseWriter.WriteHeader(cw.status)
+
+	if cw.encoder != nil {
+		return cw.encoder
+	}
+	return cw.ResponseWriter
+}
+
+func (cw *compressingResponseWriter) flushBuffer(dst io.Writer) error {
+	buffer := cw.buffer
+	cw.buffer = nil
+	if len(buffer) == 0 {
+		return nil
+	}
+	_, err := dst.Write(buffer)
+	return err
+}
+
+// finish sends whatever is still held back once the handler has returned
+func (cw *compressingResponseWriter) finish() error {
+	if !cw.decided {
+		if !cw.headerWritten {
+			// The handler wrote nothing at all
+			cw.WriteHeader(http.StatusOK)
+		}
+		if !cw.decided {
+			if err := cw.flushBuffer(cw.commit(false)); err != nil {
+				return err
+			}
+		}
+	}
+
+	if cw.encoder != nil {
+		return cw.encoder.Close()
+	}
+	return nil
+}
+
+// bodyAllowedForStatus mirrors the net/http rule for which statuses may carry
+// a body.
+func bodyAllowedForStatus(status int) bool {
+	switch {
+	case status >= 100 && status <= 199:
+		return false
+	case status == http.StatusNoContent:
+		return false
+	case status == http.StatusNotModified:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,167 @@
+package ports_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Amund211/flashlight/internal/ports"
+)
+
+// decodeBody returns the response body with its Content-Encoding undone
+func decodeBody(t *testing.T, w *httptest.ResponseRecorder) string {
+	t.Helper()
+
+	var reader io.Reader
+	switch encoding := w.Header().Get("Content-Encoding"); encoding {
+	case "":
+		return w.Body.String()
+	case "gzip":
+		gzipReader, err := gzip.NewReader(bytes.NewReader(w.Body.Bytes()))
+		require.NoError(t, err)
+		reader = gzipReader
+	case "br":
+		reader = brotli.NewReader(bytes.NewReader(w.Body.Bytes()))
+	default:
+		require.FailNow(t, "unexpected Content-Encoding", encoding)
+	}
+
+	decoded, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	return string(decoded)
+}
+
+func TestCompressionMiddleware(t *testing.T) {
+	t.Parallel()
+
+	largeBody := strings.Repeat(`{"gamesPlayed":1234,"wins":567},`, 200)
+	smallBody := `{"success":false}`
+
+	makeHandler := func(status int, body string, writeSize int) http.HandlerFunc {
+		return ports.NewCompressionMiddleware()(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("ETag", `"abc"`)
+			w.WriteHeader(status)
+			// Write in chunks to exercise the buffering
+			for remaining := body; remaining != ""; {
+				chunk := remaining[:min(writeSize, len(remaining))]
+				remaining = remaining[len(chunk):]
+				_, err := w.Write([]byte(chunk))
+				require.NoError(t, err)
+			}
+		})
+	}
+
+	serve := func(t *testing.T, handler http.HandlerFunc, acceptEncoding string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/", nil)
+		if acceptEncoding != "" {
+			req.Header.Set("Accept-Encoding", acceptEncoding)
+		}
+		w := httptest.NewRecorder()
+		handler(w, req)
+		return w
+	}
+
+	t.Run("negotiation", func(t *testing.T) {
+		t.Parallel()
+
+		testCases := []struct {
+			acceptEncoding string
+			encoding       string
+		}{
+			{acceptEncoding: "", encoding: ""},
+			{acceptEncoding: "identity", encoding: ""},
+			{acceptEncoding: "gzip", encoding: "gzip"},
+			{acceptEncoding: "br", encoding: "br"},
+			{acceptEncoding: "gzip, deflate, br", encoding: "br"},
+			{acceptEncoding: "GZIP", encoding: "gzip"},
+			{acceptEncoding: "br;q=0.5, gzip", encoding: "gzip"},
+			{acceptEncoding: "br;q=0, gzip;q=0.1", encoding: "gzip"},
+			{acceptEncoding: "gzip;q=0", encoding: ""},
+			{acceptEncoding: "gzip;q=nonsense", encoding: ""},
+			{acceptEncoding: "*", encoding: "br"},
+			{acceptEncoding: "*;q=0.5, br;q=0", encoding: "gzip"},
+			{acceptEncoding: "*;q=0", encoding: ""},
+			{acceptEncoding: "deflate", encoding: ""},
+		}
+
+		handler := makeHandler(http.StatusOK, largeBody, len(largeBody))
+		for _, testCase := range testCases {
+			t.Run(testCase.acceptEncoding, func(t *testing.T) {
+				t.Parallel()
+
+				w := serve(t, handler, testCase.acceptEncoding)
+
+				require.Equal(t, http.StatusOK, w.Code)
+				require.Equal(t, testCase.encoding, w.Header().Get("Content-Encoding"))
+				require.Contains(t, w.Header().Values("Vary"), "Accept-Encoding")
+				require.Equal(t, largeBody, decodeBody(t, w))
+			})
+		}
+	})
+
+	t.Run("compressed bodies round trip", func(t *testing.T) {
+		t.Parallel()
+
+		for _, encoding := range []string{"gzip", "br"} {
+			for _, writeSize := range []int{1, 100, len(largeBody)} {
+				w := serve(t, makeHandler(http.StatusOK, largeBody, writeSize), encoding)
+
+				require.Equal(t, encoding, w.Header().Get("Content-Encoding"))
+				require.Less(t, w.Body.Len(), len(largeBody))
+				require.Equal(t, largeBody, decodeBody(t, w))
+				require.Equal(t, `W/"abc"`, w.Header().Get("ETag"), "the strong ETag is for the uncompressed body")
+				require.Equal(t, "application/json", w.Header().Get("Content-Type"))
+			}
+		}
+	})
+
+	t.Run("small bodies are not compressed", func(t *testing.T) {
+		t.Parallel()
+
+		for _, writeSize := range []int{1, len(smallBody)} {
+			w := serve(t, makeHandler(http.StatusBadRequest, smallBody, writeSize), "gzip, br")
+
+			require.Equal(t, http.StatusBadRequest, w.Code)
+			require.Empty(t, w.Header().Get("Content-Encoding"))
+			require.Equal(t, smallBody, w.Body.String())
+			require.Equal(t, `"abc"`, w.Header().Get("ETag"))
+		}
+	})
+
+	t.Run("bodiless responses", func(t *testing.T) {
+		t.Parallel()
+
+		w := serve(t, makeHandler(http.StatusNotModified, "", 1), "gzip, br")
+		require.Equal(t, http.StatusNotModified, w.Code)
+		require.Empty(t, w.Header().Get("Content-Encoding"))
+		require.Empty(t, w.Body.String())
+
+		w = serve(t, ports.NewCompressionMiddleware()(func(w http.ResponseWriter, r *http.Request) {}), "gzip")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Empty(t, w.Header().Get("Content-Encoding"))
+		require.Empty(t, w.Body.String())
+	})
+
+	t.Run("already encoded bodies are left alone", func(t *testing.T) {
+		t.Parallel()
+
+		handler := ports.NewCompressionMiddleware()(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Encoding", "identity")
+			_, err := w.Write([]byte(largeBody))
+			require.NoError(t, err)
+		})
+
+		w := serve(t, handler, "gzip")
+		require.Equal(t, "identity", w.Header().Get("Content-Encoding"))
+		require.Equal(t, largeBody, w.Body.String())
+	})
+}
@@ -6,13 +6,14 @@ import (
 	"strings"
 )
 
-// exposedHeaders is every auth header a browser is allowed to read.
+// exposedHeaders is every non-safelisted response header a browser is
+// allowed to read: the auth headers, and the ETag to revalidate with.
 // Concatenated rather than written out so adding one is an edit here and
 // nowhere else: Access-Control-Expose-Headers is a single comma-joined
 // value and Set overwrites, so a second Set — or a hand-written list that
 // forgets a name — makes a shipped header silently unreadable to rainbow,
 // with no error anywhere.
-const exposedHeaders = AuthRefreshHeader + ", " + AuthSessionHeader
+const exposedHeaders = AuthRefreshHeader + ", " + AuthSessionHeader + ", ETag"
 
 type DomainSuffixes struct {
 	suffixes []string
@@ -77,7 +78,7 @@ func BuildCORSMiddleware(allowedSuffixes *DomainSuffixes) func(http.HandlerFunc)
 
 				if r.Method == http.MethodOptions {
 					w.Header().Set("Access-Control-Allow-Methods", "GET,POST")
-					w.Header().Set("Access-Control-Allow-Headers", "Content-Type, X-User-Id, X-Client-Type, X-Client-Version, Authorization")
+					w.Header().Set("Access-Control-Allow-Headers", "Content-Type, X-User-Id, X-Client-Type, X-Client-Version, Authorization, If-None-Match")
 					// TODO: Add longer max age (default 5s) when it works well
 					// w.Header().Set("Access-Control-Max-Age", "3600")
 					w.WriteHeader(http.StatusNoContent)
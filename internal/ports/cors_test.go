@@ -158,7 +158,7 @@ func TestCORS(t *testing.T) {
 
 			if method == "OPTIONS" {
 				require.Equal(t, "GET,POST", resp.Header.Get("Access-Control-Allow-Methods"))
-				require.Equal(t, "Content-Type, X-User-Id, X-Client-Type, X-Client-Version, Authorization", resp.Header.Get("Access-Control-Allow-Headers"))
+				require.Equal(t, "Content-Type, X-User-Id, X-Client-Type, X-Client-Version, Authorization, If-None-Match", resp.Header.Get("Access-Control-Allow-Headers"))
 				require.Empty(t, resp.Header.Get("Access-Control-Expose-Headers"), "exposed headers are read off the actual response")
 			} else {
 				require.Empty(t, resp.Header.Get("Access-Control-Allow-Methods"))
@@ -169,6 +169,7 @@ func TestCORS(t *testing.T) {
 				exposed := strings.Split(resp.Header.Get("Access-Control-Expose-Headers"), ", ")
 				require.Contains(t, exposed, ports.AuthRefreshHeader)
 				require.Contains(t, exposed, ports.AuthSessionHeader)
+				require.Contains(t, exposed, "ETag")
 			}
 		} else {
 			require.Empty(t, resp.Header.Get("Access-Control-Allow-Origin"))
@@ -0,0 +1,175 @@
+package ports
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Amund211/flashlight/internal/domain"
+	"github.com/Amund211/flashlight/internal/logging"
+	"github.com/Amund211/flashlight/internal/ratelimiting"
+	"github.com/Amund211/flashlight/internal/reporting"
+)
+
+// errorCode is the machine-readable reason on an error response. Clients
+// branch on it, so existing values are never renamed — only added.
+type errorCode string
+
+const (
+	errorCodeBadRequest             errorCode = "bad_request"
+	errorCodeBlocked                errorCode = "blocked"
+	errorCodeUnauthorized           errorCode = "unauthorized"
+	errorCodeInvalidSession         errorCode = "invalid_session"
+	errorCodeInvalidAPIKey          errorCode = "invalid_api_key"
+	errorCodeInvalidProofOfWork     errorCode = "invalid_proof_of_work"
+	errorCodeNotFound               errorCode = "not_found"
+	errorCodeRequestTooLarge        errorCode = "request_too_large"
+	errorCodeUnsupportedMediaType   errorCode = "unsupported_media_type"
+	errorCodeRateLimited            errorCode = "rate_limited"
+	errorCodeTemporarilyUnavailable errorCode = "temporarily_unavailable"
+	errorCodeInternal               errorCode = "internal_error"
+)
+
+// errorResponse is the body of every error response from the v1 endpoints.
+//
+// success and cause predate the envelope: they are what the account,
+// prestiges and wrapped endpoints used to send, and what prism reads off a
+// Hypixel style error. Keeping them makes the envelope a superset of both,
+// so no existing client has to change to keep reading errors.
+type errorResponse struct {
+	Success           bool      `json:"success"`
+	Code              errorCode `json:"code"`
+	Message           string    `json:"message"`
+	Cause             string    `json:"cause"`
+	Retryable         bool      `json:"retryable"`
+	RetryAfterSeconds *int64    `json:"retryAfterSeconds,omitempty"`
+}
+
+// apiError is an error response before it is written.
+type apiError struct {
+	statusCode int
+	code       errorCode
+	message    string
+	retryable  bool
+	// retryAfter is sent when non-zero, both in the body and as Retry-After
+	retryAfter time.Duration
+}
+
+func newAPIError(statusCode int, code errorCode, message string) apiError {
+	return apiError{
+		statusCode: statusCode,
+		code:       code,
+		message:    message,
+		// Server side failures may succeed on a retry, client errors won't
+		retryable: statusCode >= 500,
+	}
+}
+
+func badRequestError(message string) apiError {
+	return newAPIError(http.StatusBadRequest, errorCodeBadRequest, message)
+}
+
+func internalError() apiError {
+	return newAPIError(http.StatusInternalServerError, errorCodeInternal, "Internal server error")
+}
+
+func rateLimitedError(retryAfter time.Duration) apiError {
+	return apiError{
+		statusCode: http.StatusTooManyRequests,
+		code:       errorCodeRateLimited,
+		message:    "Rate limit exceeded",
+		retryable:  true,
+		retryAfter: retryAfter,
+	}
+}
+
+// apiErrorFromDomain maps the domain sentinel errors to their response.
+// Anything unrecognized is an internal error with fallbackMessage, so
+// internal error strings are never sent to the client.
+func apiErrorFromDomain(err error, fallbackMessage string) apiError {
+	switch {
+	case errors.Is(err, domain.ErrPlayerNotFound),
+		errors.Is(err, domain.ErrUsernameNotFound),
+		errors.Is(err, domain.ErrUserNotFound),
+		errors.Is(err, domain.ErrPrismNoticeNotFound):
+		return newAPIError(http.StatusNotFound, errorCodeNotFound, "Not found")
+	case errors.Is(err, domain.ErrTemporarilyUnavailable):
+		return newAPIError(http.StatusServiceUnavailable, errorCodeTemporarilyUnavailable, "Temporarily unavailable")
+	case errors.Is(err, domain.ErrInvalidAPIKey):
+		return newAPIError(http.StatusUnauthorized, errorCodeInvalidAPIKey, "Invalid API key")
+	case errors.Is(err, domain.ErrAuthSessionNotFound),
+		errors.Is(err, domain.ErrAuthSessionRevoked),
+		errors.Is(err, domain.ErrAuthSessionExpired),
+		errors.Is(err, domain.ErrAuthSessionRefreshExpired):
+		return newAPIError(http.StatusUnauthorized, errorCodeInvalidSession, "Invalid or expired session")
+	case errors.Is(err, domain.ErrAuthSessionRefreshTooSoon):
+		// Not a 401: the session is fine, so re-auth is the wrong reaction
+		return apiError{
+			statusCode: http.StatusTooManyRequests,
+			code:       errorCodeRateLimited,
+			message:    "Session refreshed too recently",
+			retryable:  true,
+		}
+	}
+
+	e := internalError()
+	e.message = fallbackMessage
+	return e
+}
+
+func (e apiError) response() errorResponse {
+	response := errorResponse{
+		Success:   false,
+		Code:      e.code,
+		Message:   e.message,
+		Cause:     e.message,
+		Retryable: e.retryable,
+	}
+	if e.retryAfter > 0 {
+		seconds := retryAfterSeconds(e.retryAfter)
+		response.RetryAfterSeconds = &seconds
+	}
+	return response
+}
+
+// retryAfterSeconds rounds up, so a client that waits exactly this long is
+// never early.
+func retryAfterSeconds(d time.Duration) int64 {
+	return int64(math.Ceil(d.Seconds()))
+}
+
+func writeErrorResponse(ctx context.Context, w http.ResponseWriter, e apiError) {
+	data, err := json.Marshal(e.response())
+	if err != nil {
+		// Only string and bool fields, so this is unreachable
+		reporting.Report(ctx, fmt.Errorf("failed to marshal error response: %w", err))
+		data = []byte(`{"success":false,"code":"internal_error","message":"Internal server error","cause":"Internal server error","retryable":true}`)
+		e.statusCode = http.StatusInternalServerError
+	}
+
+	if e.retryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.FormatInt(retryAfterSeconds(e.retryAfter), 10))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(e.statusCode)
+	w.Write(data)
+
+	logging.FromContext(ctx).InfoContext(ctx, "Returning error response", "statusCode", e.statusCode, "code", string(e.code))
+}
+
+// makeOnLimitExceeded is the 429 responder for NewRateLimitMiddleware.
+func makeOnLimitExceeded(rateLimiter ratelimiting.RequestRateLimiter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		logging.FromContext(ctx).InfoContext(ctx, "Rate limit exceeded", "statusCode", http.StatusTooManyRequests, "reason", "ratelimit exceeded", "key", rateLimiter.KeyFor(r))
+
+		writeErrorResponse(ctx, w, rateLimitedError(rateLimiter.RetryAfter(r)))
+	}
+}
@@ -0,0 +1,93 @@
+package ports
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Amund211/flashlight/internal/domain"
+)
+
+func TestAPIErrorFromDomain(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		err            error
+		expectedStatus int
+		expectedCode   errorCode
+		retryable      bool
+	}{
+		{err: fmt.Errorf("lookup: %w", domain.ErrPlayerNotFound), expectedStatus: 404, expectedCode: errorCodeNotFound},
+		{err: domain.ErrUsernameNotFound, expectedStatus: 404, expectedCode: errorCodeNotFound},
+		{err: domain.ErrUserNotFound, expectedStatus: 404, expectedCode: errorCodeNotFound},
+		{err: fmt.Errorf("hypixel: %w", domain.ErrTemporarilyUnavailable), expectedStatus: 503, expectedCode: errorCodeTemporarilyUnavailable, retryable: true},
+		{err: domain.ErrInvalidAPIKey, expectedStatus: 401, expectedCode: errorCodeInvalidAPIKey},
+		{err: domain.ErrAuthSessionNotFound, expectedStatus: 401, expectedCode: errorCodeInvalidSession},
+		{err: domain.ErrAuthSessionRevoked, expectedStatus: 401, expectedCode: errorCodeInvalidSession},
+		{err: domain.ErrAuthSessionExpired, expectedStatus: 401, expectedCode: errorCodeInvalidSession},
+		{err: domain.ErrAuthSessionRefreshExpired, expectedStatus: 401, expectedCode: errorCodeInvalidSession},
+		{err: domain.ErrAuthSessionRefreshTooSoon, expectedStatus: 429, expectedCode: errorCodeRateLimited, retryable: true},
+		{err: fmt.Errorf("db exploded"), expectedStatus: 500, expectedCode: errorCodeInternal, retryable: true},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.err.Error(), func(t *testing.T) {
+			t.Parallel()
+
+			e := apiErrorFromDomain(testCase.err, "Failed to do the thing")
+			require.Equal(t, testCase.expectedStatus, e.statusCode)
+			require.Equal(t, testCase.expectedCode, e.code)
+			require.Equal(t, testCase.retryable, e.retryable)
+			require.NotContains(t, e.message, testCase.err.Error(), "internal error strings must not reach the client")
+		})
+	}
+
+	t.Run("unknown errors use the fallback message", func(t *testing.T) {
+		t.Parallel()
+
+		e := apiErrorFromDomain(fmt.Errorf("secret connection string"), "Failed to do the thing")
+		require.Equal(t, "Failed to do the thing", e.message)
+	})
+}
+
+func TestWriteErrorResponse(t *testing.T) {
+	t.Parallel()
+
+	t.Run("bad request", func(t *testing.T) {
+		t.Parallel()
+
+		w := httptest.NewRecorder()
+		writeErrorResponse(t.Context(), w, badRequestError("Invalid UUID"))
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Equal(t, "application/json", w.Header().Get("Content-Type"))
+		require.Empty(t, w.Header().Get("Retry-After"))
+		require.JSONEq(t, `{"success":false,"code":"bad_request","message":"Invalid UUID","cause":"Invalid UUID","retryable":false}`, w.Body.String())
+	})
+
+	t.Run("rate limited rounds retry after up", func(t *testing.T) {
+		t.Parallel()
+
+		w := httptest.NewRecorder()
+		writeErrorResponse(t.Context(), w, rateLimitedError(1500*time.Millisecond))
+
+		require.Equal(t, http.StatusTooManyRequests, w.Code)
+		require.Equal(t, "2", w.Header().Get("Retry-After"))
+		require.JSONEq(t, `{"success":false,"code":"rate_limited","message":"Rate limit exceeded","cause":"Rate limit exceeded","retryable":true,"retryAfterSeconds":2}`, w.Body.String())
+	})
+
+	t.Run("rate limited without a known retry after", func(t *testing.T) {
+		t.Parallel()
+
+		w := httptest.NewRecorder()
+		writeErrorResponse(t.Context(), w, rateLimitedError(0))
+
+		require.Equal(t, http.StatusTooManyRequests, w.Code)
+		require.Empty(t, w.Header().Get("Retry-After"))
+		require.JSONEq(t, `{"success":false,"code":"rate_limited","message":"Rate limit exceeded","cause":"Rate limit exceeded","retryable":true}`, w.Body.String())
+	})
+}
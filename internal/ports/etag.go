@@ -0,0 +1,71 @@
+package ports
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Amund211/flashlight/internal/logging"
+)
+
+// cacheControlFor is the Cache-Control of a response that is served out of a
+// cache with the given TTL. A client that caches it for the same duration is
+// never staler than the server would be.
+//
+// private: the responses are keyed on headers (X-User-Id, Authorization)
+// that a shared cache would ignore, and serving them from one would skip the
+// rate limits and visit tracking.
+func cacheControlFor(ttl time.Duration) string {
+	return fmt.Sprintf("private, max-age=%d", int64(ttl.Seconds()))
+}
+
+// computeETag is a strong ETag for a response body. Equal bodies get equal
+// ETags, so an unchanged snapshot revalidates no matter which instance
+// serialized it.
+func computeETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// ifNoneMatchMatches reports whether the If-None-Match header lists etag.
+// If-None-Match uses the weak comparison (RFC 9110 13.1.2), so a W/ prefix
+// on either side is ignored.
+func ifNoneMatchMatches(header string, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for candidate := range strings.SplitSeq(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		if strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// writeConditionalJSON writes body as a 200 with an ETag, or an empty 304 if
+// the request already has it. Returns the status code written.
+func writeConditionalJSON(ctx context.Context, w http.ResponseWriter, r *http.Request, body []byte, cacheControl string) int {
+	etag := computeETag(body)
+
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", cacheControl)
+
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" && ifNoneMatchMatches(ifNoneMatch, etag) {
+		logging.FromContext(ctx).InfoContext(ctx, "Response not modified", "etag", etag)
+		w.WriteHeader(http.StatusNotModified)
+		return http.StatusNotModified
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(body); err != nil {
+		logging.FromContext(ctx).ErrorContext(ctx, "Failed to write response", "error", err)
+	}
+	return http.StatusOK
+}
@@ -0,0 +1,93 @@
+package ports_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Amund211/flashlight/internal/domain"
+	"github.com/Amund211/flashlight/internal/ports"
+)
+
+// requireConditionalGET checks that handler serves an ETag and the given
+// Cache-Control on a 200, and a bodiless 304 when that ETag is sent back.
+// makeRequest must return a new request for the same resource every time.
+func requireConditionalGET(t *testing.T, pattern string, handler http.HandlerFunc, makeRequest func() *http.Request, cacheControl string) {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, makeRequest())
+	require.Equal(t, http.StatusOK, w.Code)
+	requireOpenAPIResponse(t, pattern, w)
+	etag := w.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+	require.NotContains(t, etag, "W/", "the ETag should be strong")
+	require.Equal(t, cacheControl, w.Header().Get("Cache-Control"))
+	body := w.Body.String()
+
+	for _, ifNoneMatch := range []string{etag, "W/" + etag, `"something-else", ` + etag, "*"} {
+		req := makeRequest()
+		req.Header.Set("If-None-Match", ifNoneMatch)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusNotModified, w.Code, "If-None-Match: %s", ifNoneMatch)
+		requireOpenAPIResponse(t, pattern, w)
+		require.Empty(t, w.Body.String())
+		require.Equal(t, etag, w.Header().Get("ETag"))
+		require.Equal(t, cacheControl, w.Header().Get("Cache-Control"))
+	}
+
+	req := makeRequest()
+	req.Header.Set("If-None-Match", `"something-else"`)
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, body, w.Body.String())
+	require.Equal(t, etag, w.Header().Get("ETag"), "the same payload gets the same ETag")
+}
+
+func TestConditionalGETChangedPayload(t *testing.T) {
+	t.Parallel()
+
+	uuid := "01234567-89ab-cdef-0123-456789abcdef"
+	tags := domain.Tags{Cheating: domain.TagSeverityNone, Sniping: domain.TagSeverityNone}
+
+	handler, stop := ports.MakeGetTagsHandler(
+		func(ctx context.Context, uuid string, apiKey *string) (domain.Tags, error) {
+			return tags, nil
+		},
+		unusedRegisterUserVisit,
+		authTestLogger,
+		noopAuthMiddleware,
+		noopAuthMiddleware,
+		emptyBlocklistConfig,
+	)
+	t.Cleanup(stop)
+
+	makeRequest := func() *http.Request {
+		req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/v1/tags/"+uuid, nil)
+		req.SetPathValue("uuid", uuid)
+		return req
+	}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, makeRequest())
+	require.Equal(t, http.StatusOK, w.Code)
+	etag := w.Header().Get("ETag")
+
+	// The tags changed since the client last saw them
+	tags.Cheating = domain.TagSeverityHigh
+
+	req := makeRequest()
+	req.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NotEqual(t, etag, w.Header().Get("ETag"))
+	require.Contains(t, w.Body.String(), `"cheating":"high"`)
+}
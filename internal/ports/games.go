@@ -0,0 +1,240 @@
+package ports
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/Amund211/flashlight/internal/app"
+	"github.com/Amund211/flashlight/internal/domain"
+	"github.com/Amund211/flashlight/internal/logging"
+	"github.com/Amund211/flashlight/internal/reporting"
+	"github.com/Amund211/flashlight/internal/strutils"
+)
+
+const defaultGamesPageSize = 50
+
+// rainbowGamesEntry is a game, or a stretch of games that could not be told
+// apart. The game happened between the two snapshot times.
+type rainbowGamesEntry struct {
+	StartedAfter time.Time          `json:"startedAfter"`
+	EndedBefore  time.Time          `json:"endedBefore"`
+	Game         *rainbowGameResult `json:"game"`
+	// Aggregate is the combined stats of an unattributable stretch. nil
+	// when Game is set.
+	Aggregate *rainbowSessionDeltas `json:"aggregate"`
+}
+
+type rainbowGamesResponse struct {
+	Games []rainbowGamesEntry `json:"games"`
+	// NextCursor continues the listing, and is nil on the last page
+	NextCursor *string `json:"nextCursor"`
+}
+
+// encodeGamesCursor returns an opaque cursor for the page starting at start
+func encodeGamesCursor(start time.Time) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(start.UTC().Format(time.RFC3339Nano)))
+}
+
+func decodeGamesCursor(cursor string) (time.Time, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to decode cursor: %w", err)
+	}
+	start, err := time.Parse(time.RFC3339Nano, string(raw))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse cursor: %w", err)
+	}
+	return start, nil
+}
+
+func gamesPageToRainbowGamesResponse(page *app.GamesPage) (rainbowGamesResponse, error) {
+	response := rainbowGamesResponse{
+		Games:      make([]rainbowGamesEntry, 0, len(page.Games)),
+		NextCursor: nil,
+	}
+
+	for _, segment := range page.Games {
+		game, err := gameResultToRainbowGameResult(segment.Game)
+		if err != nil {
+			return rainbowGamesResponse{}, err
+		}
+
+		entry := rainbowGamesEntry{
+			StartedAfter: segment.Start.QueriedAt,
+			EndedBefore:  segment.End.QueriedAt,
+			Game:         game,
+			Aggregate:    nil,
+		}
+		if game == nil {
+			deltas := sessionDeltasToRainbowSessionDeltas(new(domain.NewSessionDeltas(&segment.Start, &segment.End)))
+			entry.Aggregate = &deltas
+		}
+		response.Games = append(response.Games, entry)
+	}
+
+	if page.HasMore && len(page.Games) > 0 {
+		response.NextCursor = new(encodeGamesCursor(page.Games[len(page.Games)-1].End.QueriedAt))
+	}
+
+	return response, nil
+}
+
+func MakeGetGamesHandler(
+	getGames app.GetGames,
+	registerUserVisit app.RegisterUserVisit,
+	allowedOrigins *DomainSuffixes,
+	rootLogger *slog.Logger,
+	sentryMiddleware func(http.HandlerFunc) http.HandlerFunc,
+	bearerAuthMiddleware func(http.HandlerFunc) http.HandlerFunc,
+	blocklistConfig BlocklistConfig,
+) (http.HandlerFunc, func()) {
+	middleware, stop := mustBuildRouteMiddleware(
+		RouteSpec{
+			Name:           "games",
+			AllowedOrigins: allowedOrigins,
+			BearerAuth:     bearerAuthMiddleware,
+			RateLimits: []RateLimit{
+				IPRateLimit(4, 80),
+				IdentityRateLimit(1, 20),
+			},
+			RegisterUserVisit: registerUserVisit,
+			Compress:          true,
+		},
+		rootLogger,
+		sentryMiddleware,
+		blocklistConfig,
+	)
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		defer r.Body.Close()
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, 4<<10))
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				writeErrorResponse(ctx, w, newAPIError(http.StatusRequestEntityTooLarge, errorCodeRequestTooLarge, "Request body too large"))
+				return
+			}
+			reporting.Report(ctx, fmt.Errorf("failed to read request body: %w", err))
+			writeErrorResponse(ctx, w, badRequestError("Failed to read request body"))
+			return
+		}
+		request := struct {
+			UUID                       string    `json:"uuid"`
+			Start                      time.Time `json:"start"`
+			End                        time.Time `json:"end"`
+			Limit                      *int      `json:"limit"`
+			Cursor                     string    `json:"cursor"`
+			InactivityThresholdMinutes *int      `json:"inactivityThresholdMinutes"`
+			Gamemode                   string    `json:"gamemode"`
+		}{}
+		err = json.Unmarshal(body, &request)
+		if err != nil {
+			logging.FromContext(ctx).WarnContext(ctx, "Failed to parse request body", "error", err)
+			writeErrorResponse(ctx, w, badRequestError("Failed to parse request body"))
+			return
+		}
+
+		ctx = reporting.AddExtrasToContext(ctx, map[string]string{
+			"start": request.Start.Format(time.RFC3339),
+			"end":   request.End.Format(time.RFC3339),
+		})
+
+		uuid, err := strutils.NormalizeUUID(request.UUID)
+		if err != nil {
+			logging.FromContext(ctx).WarnContext(ctx, "Failed to normalize uuid", "error", err, "rawUUID", request.UUID)
+			writeErrorResponse(ctx, w, badRequestError("invalid uuid"))
+			return
+		}
+
+		ctx = reporting.AddExtrasToContext(ctx, map[string]string{
+			"uuid": uuid,
+		})
+		ctx = logging.AddMetaToContext(ctx,
+			slog.String("uuid", uuid),
+			slog.String("start", request.Start.Format(time.RFC3339)),
+			slog.String("end", request.End.Format(time.RFC3339)),
+		)
+
+		if request.Start.After(request.End) {
+			writeErrorResponse(ctx, w, badRequestError("Start time cannot be after end time"))
+			return
+		}
+
+		if request.End.Sub(request.Start) >= 400*24*time.Hour {
+			writeErrorResponse(ctx, w, badRequestError("Time interval is too long"))
+			return
+		}
+
+		limit := defaultGamesPageSize
+		if request.Limit != nil {
+			limit = *request.Limit
+		}
+		if limit < 1 || limit > app.MaxGamesPageSize {
+			writeErrorResponse(ctx, w, badRequestError(fmt.Sprintf("limit must be between 1 and %d", app.MaxGamesPageSize)))
+			return
+		}
+
+		sessionOptions, err := sessionOptionsFromRequest(request.InactivityThresholdMinutes, request.Gamemode)
+		if err != nil {
+			writeErrorResponse(ctx, w, badRequestError(err.Error()))
+			return
+		}
+
+		// The cursor moves the start of the range forward
+		start := request.Start
+		if request.Cursor != "" {
+			cursorStart, err := decodeGamesCursor(request.Cursor)
+			if err != nil || cursorStart.Before(request.Start) || cursorStart.After(request.End) {
+				logging.FromContext(ctx).WarnContext(ctx, "Invalid cursor", "error", err, "cursor", request.Cursor)
+				writeErrorResponse(ctx, w, badRequestError("invalid cursor"))
+				return
+			}
+			start = cursorStart
+		}
+
+		logging.FromContext(ctx).InfoContext(ctx, "Handling games request",
+			slog.String("pageStart", start.Format(time.RFC3339Nano)),
+			slog.Int("limit", limit),
+		)
+
+		page, err := getGames(ctx, uuid, start, request.End, limit, sessionOptions)
+		if err != nil {
+			// NOTE: GetGames implementations handle their own error reporting
+			writeErrorResponse(ctx, w, newAPIError(http.StatusInternalServerError, errorCodeInternal, "Failed to get games"))
+			return
+		}
+
+		response, err := gamesPageToRainbowGamesResponse(&page)
+		if err != nil {
+			reporting.Report(ctx, fmt.Errorf("failed to convert games to response: %w", err))
+			writeErrorResponse(ctx, w, newAPIError(http.StatusInternalServerError, errorCodeInternal, "Failed to serialise response"))
+			return
+		}
+
+		marshalled, err := json.Marshal(response)
+		if err != nil {
+			reporting.Report(ctx, fmt.Errorf("failed to marshal response: %w", err))
+			writeErrorResponse(ctx, w, newAPIError(http.StatusInternalServerError, errorCodeInternal, "Failed to marshal response"))
+			return
+		}
+
+		logging.FromContext(ctx).InfoContext(ctx, "Returning games",
+			"gamesLength", len(response.Games),
+			"hasMore", page.HasMore,
+		)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(marshalled)
+	}
+
+	return middleware(handler), stop
+}
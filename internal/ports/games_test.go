@@ -0,0 +1,206 @@
+package ports_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Amund211/flashlight/internal/app"
+	"github.com/Amund211/flashlight/internal/domain"
+	"github.com/Amund211/flashlight/internal/domaintest"
+	"github.com/Amund211/flashlight/internal/ports"
+)
+
+func TestMakeGetGamesHandler(t *testing.T) {
+	t.Parallel()
+
+	allowedOrigins, err := ports.NewDomainSuffixes("example.com", "test.com")
+	require.NoError(t, err)
+
+	testLogger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	noopMiddleware := func(h http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			h(w, r)
+		}
+	}
+
+	makeHandler := func(getGames app.GetGames) http.HandlerFunc {
+		stubRegisterUserVisit := func(ctx context.Context, userID string, ipHash string, userAgent string) (domain.User, error) {
+			return domain.User{}, nil
+		}
+		handler, stop := ports.MakeGetGamesHandler(
+			getGames,
+			stubRegisterUserVisit,
+			allowedOrigins,
+			testLogger,
+			noopMiddleware,
+			noopMiddleware,
+			emptyBlocklistConfig,
+		)
+		t.Cleanup(stop)
+		return handler
+	}
+
+	makeRequest := func(body string) *http.Request {
+		return httptest.NewRequestWithContext(t.Context(), "POST", "/games", strings.NewReader(body))
+	}
+
+	uuid := "01234567-89ab-cdef-0123-456789abcdef"
+	start := time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 6, 16, 0, 0, 0, 0, time.UTC)
+	rangeBody := `"uuid":"` + uuid + `","start":"2024-06-15T00:00:00Z","end":"2024-06-16T00:00:00Z"`
+
+	b := domaintest.NewPlayerBuilder(uuid).WithExperience(1000).FromDB()
+	p0 := b.Fours().WithGamesPlayed(10).Build(start.Add(time.Hour))
+	p1 := b.WithExperience(1200).Fours().WithGamesPlayed(11).WithWins(1).WithFinalKills(3).Build(start.Add(time.Hour + 15*time.Minute))
+	p2 := b.WithExperience(1700).Fours().WithGamesPlayed(14).WithWins(3).Build(start.Add(2 * time.Hour))
+
+	page := app.GamesPage{
+		Games: []app.GameSegment{
+			{Start: p0, End: p1, Game: &domain.GameResult{
+				Gamemode: domain.GamemodeFours, Outcome: domain.GameOutcomeWin, FinalKills: 3, Experience: 200,
+			}},
+			{Start: p1, End: p2, Game: nil},
+		},
+		HasMore: true,
+	}
+
+	type gamesResponse struct {
+		Games []struct {
+			StartedAfter time.Time `json:"startedAfter"`
+			EndedBefore  time.Time `json:"endedBefore"`
+			Game         *struct {
+				Gamemode   string `json:"gamemode"`
+				Outcome    string `json:"outcome"`
+				FinalKills int    `json:"finalKills"`
+			} `json:"game"`
+			Aggregate *struct {
+				Experience int64 `json:"experience"`
+				Fours      struct {
+					GamesPlayed int `json:"gamesPlayed"`
+					Wins        int `json:"wins"`
+				} `json:"fours"`
+			} `json:"aggregate"`
+		} `json:"games"`
+		NextCursor *string `json:"nextCursor"`
+	}
+
+	t.Run("renders games and follows the cursor", func(t *testing.T) {
+		t.Parallel()
+
+		var gotStarts []time.Time
+		var gotLimit int
+		var gotOptions app.SessionOptions
+		getGames := func(ctx context.Context, gotUUID string, start, gotEnd time.Time, limit int, options app.SessionOptions) (app.GamesPage, error) {
+			require.Equal(t, uuid, gotUUID)
+			require.True(t, end.Equal(gotEnd))
+			gotStarts = append(gotStarts, start)
+			gotLimit = limit
+			gotOptions = options
+			if len(gotStarts) > 1 {
+				return app.GamesPage{}, nil
+			}
+			return page, nil
+		}
+		handler := makeHandler(getGames)
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, makeRequest(`{`+rangeBody+`,"gamemode":"fours"}`))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		requireOpenAPIResponse(t, "POST /v1/games", w)
+		require.Equal(t, 50, gotLimit, "default page size")
+		require.Equal(t, domain.GamemodeFours, gotOptions.Gamemode)
+
+		var response gamesResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.Len(t, response.Games, 2)
+
+		require.True(t, p0.QueriedAt.Equal(response.Games[0].StartedAfter))
+		require.True(t, p1.QueriedAt.Equal(response.Games[0].EndedBefore))
+		require.NotNil(t, response.Games[0].Game)
+		require.Equal(t, "fours", response.Games[0].Game.Gamemode)
+		require.Equal(t, "win", response.Games[0].Game.Outcome)
+		require.Equal(t, 3, response.Games[0].Game.FinalKills)
+		require.Nil(t, response.Games[0].Aggregate)
+
+		require.Nil(t, response.Games[1].Game)
+		require.NotNil(t, response.Games[1].Aggregate)
+		require.Equal(t, int64(500), response.Games[1].Aggregate.Experience)
+		require.Equal(t, 3, response.Games[1].Aggregate.Fours.GamesPlayed)
+		require.Equal(t, 2, response.Games[1].Aggregate.Fours.Wins)
+
+		require.NotNil(t, response.NextCursor)
+
+		w = httptest.NewRecorder()
+		handler.ServeHTTP(w, makeRequest(`{`+rangeBody+`,"limit":10,"cursor":"`+*response.NextCursor+`"}`))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		requireOpenAPIResponse(t, "POST /v1/games", w)
+		require.Equal(t, 10, gotLimit)
+		require.Len(t, gotStarts, 2)
+		require.True(t, start.Equal(gotStarts[0]))
+		require.True(t, p2.QueriedAt.Equal(gotStarts[1]), "the next page starts at the end of the last segment")
+
+		response = gamesResponse{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.Empty(t, response.Games)
+		require.Nil(t, response.NextCursor)
+	})
+
+	t.Run("bad requests", func(t *testing.T) {
+		t.Parallel()
+
+		for name, body := range map[string]string{
+			"invalid uuid":      `{"uuid":"nope","start":"2024-06-15T00:00:00Z","end":"2024-06-16T00:00:00Z"}`,
+			"start after end":   `{"uuid":"` + uuid + `","start":"2024-06-16T00:00:00Z","end":"2024-06-15T00:00:00Z"}`,
+			"range too long":    `{"uuid":"` + uuid + `","start":"2023-01-01T00:00:00Z","end":"2024-06-15T00:00:00Z"}`,
+			"zero limit":        `{` + rangeBody + `,"limit":0}`,
+			"limit too large":   `{` + rangeBody + `,"limit":101}`,
+			"malformed cursor":  `{` + rangeBody + `,"cursor":"%%%"}`,
+			"cursor past range": `{` + rangeBody + `,"cursor":"MjAyNC0wNi0xN1QwMDowMDowMFo"}`,
+			"invalid gamemode":  `{` + rangeBody + `,"gamemode":"eights"}`,
+			"malformed body":    `{`,
+		} {
+			t.Run(name, func(t *testing.T) {
+				t.Parallel()
+
+				called := false
+				handler := makeHandler(func(context.Context, string, time.Time, time.Time, int, app.SessionOptions) (app.GamesPage, error) {
+					called = true
+					return app.GamesPage{}, nil
+				})
+
+				w := httptest.NewRecorder()
+				handler.ServeHTTP(w, makeRequest(body))
+
+				require.Equal(t, http.StatusBadRequest, w.Code)
+				requireOpenAPIResponse(t, "POST /v1/games", w)
+				require.False(t, called)
+			})
+		}
+	})
+
+	t.Run("app error", func(t *testing.T) {
+		t.Parallel()
+
+		handler := makeHandler(func(context.Context, string, time.Time, time.Time, int, app.SessionOptions) (app.GamesPage, error) {
+			return app.GamesPage{}, errors.New("db down")
+		})
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, makeRequest(`{`+rangeBody+`}`))
+
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+		requireOpenAPIResponse(t, "POST /v1/games", w)
+	})
+}
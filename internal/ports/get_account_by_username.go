@@ -3,16 +3,15 @@ package ports
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/Amund211/flashlight/internal/app"
 	"github.com/Amund211/flashlight/internal/domain"
 	"github.com/Amund211/flashlight/internal/logging"
-	"github.com/Amund211/flashlight/internal/ratelimiting"
 	"github.com/Amund211/flashlight/internal/reporting"
 )
 
@@ -20,9 +19,12 @@ type accountResponse struct {
 	Success  bool   `json:"success"`
 	Username string `json:"username,omitempty"`
 	UUID     string `json:"uuid,omitempty"`
-	Cause    string `json:"cause,omitempty"`
 }
 
+// accountByUsernameMaxAge is the TTL of the account by username cache in
+// main.go.
+const accountByUsernameMaxAge = 24 * time.Hour
+
 func MakeGetAccountByUsernameHandler(
 	getAccountByUsername app.GetAccountByUsername,
 	registerUserVisit app.RegisterUserVisit,
@@ -32,43 +34,20 @@ func MakeGetAccountByUsernameHandler(
 	bearerAuthMiddleware func(http.HandlerFunc) http.HandlerFunc,
 	blocklistConfig BlocklistConfig,
 ) (http.HandlerFunc, func()) {
-	ipLimiter, stopIPLimiter := ratelimiting.NewTokenBucketRateLimiter(
-		ratelimiting.RefillPerSecond(8),
-		ratelimiting.BurstSize(480),
-	)
-	ipRateLimiter := ratelimiting.NewRequestBasedRateLimiter(
-		ipLimiter,
-		IPHashKeyFunc,
-	)
-	userIDLimiter, stopUserIDLimiter := ratelimiting.NewTokenBucketRateLimiter(
-		ratelimiting.RefillPerSecond(2),
-		ratelimiting.BurstSize(120),
-	)
-	userIDRateLimiter := ratelimiting.NewRequestBasedRateLimiter(
-		// NOTE: Verified identity when there is one, user controlled value otherwise
-		userIDLimiter,
-		UserIDKeyFunc,
-	)
-
-	makeOnLimitExceeded := func(rateLimiter ratelimiting.RequestRateLimiter) http.HandlerFunc {
-		return func(w http.ResponseWriter, r *http.Request) {
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusTooManyRequests)
-			w.Write([]byte(`{"success":false,"cause":"rate limit exceeded"}`))
-		}
-	}
-
-	middleware := ComposeMiddlewares(
-		NewRequestLoggerMiddleware(rootLogger),
+	middleware, stop := mustBuildRouteMiddleware(
+		RouteSpec{
+			Name:           "get_account_by_username",
+			AllowedOrigins: allowedOrigins,
+			BearerAuth:     bearerAuthMiddleware,
+			RateLimits: []RateLimit{
+				IPRateLimit(8, 480),
+				IdentityRateLimit(2, 120),
+			},
+			RegisterUserVisit: registerUserVisit,
+		},
+		rootLogger,
 		sentryMiddleware,
-		BuildBlocklistMiddleware(blocklistConfig),
-		buildMetricsMiddleware("get_account_by_username"),
-		NewReportingMetaMiddleware("get_account_by_username"),
-		BuildCORSMiddleware(allowedOrigins),
-		NewRateLimitMiddleware(ipRateLimiter, makeOnLimitExceeded(ipRateLimiter)),
-		bearerAuthMiddleware,
-		NewRateLimitMiddleware(userIDRateLimiter, makeOnLimitExceeded(userIDRateLimiter)),
-		BuildRegisterUserVisitMiddleware(registerUserVisit),
+		blocklistConfig,
 	)
 
 	handler := func(w http.ResponseWriter, r *http.Request) {
@@ -79,21 +58,6 @@ func MakeGetAccountByUsernameHandler(
 			slog.String("username", username),
 		)
 
-		handleError := func(ctx context.Context, cause string, statusCode int) {
-			response, err := makeErrorAccountResponseForUsername(ctx, username, cause)
-			if err != nil {
-				reporting.Report(ctx, fmt.Errorf("failed to marshal error response: %w", err))
-				w.Header().Set("Content-Type", "application/json")
-				w.WriteHeader(http.StatusInternalServerError)
-				w.Write([]byte(`{"success":false,"cause":"internal server error"}`))
-				return
-			}
-
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(statusCode)
-			w.Write(response)
-		}
-
 		ctx = logging.AddMetaToContext(ctx,
 			slog.String("username", username),
 		)
@@ -105,29 +69,20 @@ func MakeGetAccountByUsernameHandler(
 
 		usernameLength := len(username)
 		if usernameLength == 0 || usernameLength > 100 {
-			username = "<invalid>"
-			handleError(ctx, "invalid username length", http.StatusBadRequest)
+			writeErrorResponse(ctx, w, badRequestError("invalid username length"))
 			return
 		}
 
 		if strings.ContainsAny(username, "§�") {
 			logging.FromContext(ctx).WarnContext(ctx, "Rejecting username with disallowed character")
-			handleError(ctx, "invalid username", http.StatusBadRequest)
+			writeErrorResponse(ctx, w, badRequestError("invalid username"))
 			return
 		}
 
 		account, err := getAccountByUsername(ctx, username)
-		if errors.Is(err, domain.ErrUsernameNotFound) {
-			handleError(ctx, "not found", http.StatusNotFound)
-			return
-		} else if errors.Is(err, domain.ErrTemporarilyUnavailable) {
-			handleError(ctx, "temporarily unavailable", http.StatusServiceUnavailable)
-			return
-		}
-
 		if err != nil {
 			// NOTE: GetAccountByUsername implementations handle their own error reporting
-			handleError(ctx, "internal server error", http.StatusInternalServerError)
+			writeErrorResponse(ctx, w, apiErrorFromDomain(err, "Internal server error"))
 			return
 		}
 
@@ -141,29 +96,21 @@ func MakeGetAccountByUsernameHandler(
 		response, err := makeSuccessAccountResponse(ctx, account)
 		if err != nil {
 			reporting.Report(ctx, fmt.Errorf("failed to create success response: %w", err))
-			handleError(ctx, "internal server error", http.StatusInternalServerError)
+			writeErrorResponse(ctx, w, internalError())
 			return
 		}
 
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		w.Write(response)
-	}
-
-	stop := func() {
-		stopIPLimiter()
-		stopUserIDLimiter()
+		writeConditionalJSON(ctx, w, r, response, cacheControlFor(accountByUsernameMaxAge))
 	}
 
 	return middleware(handler), stop
 }
 
-func makeAccountResponse(ctx context.Context, username string, success bool, uuid string, cause string) ([]byte, error) {
+func makeSuccessAccountResponse(ctx context.Context, account domain.Account) ([]byte, error) {
 	resp := accountResponse{
-		Success:  success,
-		Username: username,
-		UUID:     uuid,
-		Cause:    cause,
+		Success:  true,
+		Username: account.Username,
+		UUID:     account.UUID,
 	}
 	data, err := json.Marshal(resp)
 	if err != nil {
@@ -171,11 +118,3 @@ func makeAccountResponse(ctx context.Context, username string, success bool, uui
 	}
 	return data, nil
 }
-
-func makeSuccessAccountResponse(ctx context.Context, account domain.Account) ([]byte, error) {
-	return makeAccountResponse(ctx, account.Username, true, account.UUID, "")
-}
-
-func makeErrorAccountResponseForUsername(ctx context.Context, username string, cause string) ([]byte, error) {
-	return makeAccountResponse(ctx, username, false, "", cause)
-}
@@ -8,6 +8,7 @@ import (
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -67,6 +68,7 @@ func TestMakeGetAccountByUsernameHandler(t *testing.T) {
 		Success  *bool   `json:"success"`
 		Username *string `json:"username"`
 		UUID     *string `json:"uuid"`
+		Code     *string `json:"code"`
 		Cause    *string `json:"cause"`
 	}
 
@@ -101,6 +103,7 @@ func TestMakeGetAccountByUsernameHandler(t *testing.T) {
 		handler.ServeHTTP(w, req)
 
 		require.Equal(t, http.StatusOK, w.Code)
+		requireOpenAPIResponse(t, "GET /v1/account/username/{username}", w)
 		body := w.Body.String()
 		require.JSONEq(t, successJSON, body)
 		parsed := parseResponse(t, body)
@@ -116,6 +119,21 @@ func TestMakeGetAccountByUsernameHandler(t *testing.T) {
 		require.Equal(t, "application/json", w.Result().Header.Get("Content-Type"))
 	})
 
+	t.Run("conditional get", func(t *testing.T) {
+		t.Parallel()
+
+		getAccountByUsername, _ := makeGetAccountByUsername(t, "someguy", domain.Account{
+			Username:  "SomeGuy",
+			UUID:      uuid,
+			QueriedAt: now.Add(-time.Hour),
+		}, nil)
+		handler := makeGetAccountByUsernameHandler(getAccountByUsername)
+
+		requireConditionalGET(t, "GET /v1/account/username/{username}", handler, func() *http.Request {
+			return makeRequest("someguy")
+		}, "private, max-age=86400")
+	})
+
 	t.Run("username does not exist", func(t *testing.T) {
 		t.Parallel()
 
@@ -128,15 +146,17 @@ func TestMakeGetAccountByUsernameHandler(t *testing.T) {
 		handler.ServeHTTP(w, req)
 
 		require.Equal(t, http.StatusNotFound, w.Code)
+		requireOpenAPIResponse(t, "GET /v1/account/username/{username}", w)
 		body := w.Body.String()
 		parsed := parseResponse(t, body)
 		require.NotNil(t, parsed.Success)
 		require.False(t, *parsed.Success)
 		require.Nil(t, parsed.UUID)
-		require.NotNil(t, parsed.Username)
-		require.Equal(t, username, *parsed.Username)
+		require.Nil(t, parsed.Username)
+		require.NotNil(t, parsed.Code)
+		require.Equal(t, "not_found", *parsed.Code)
 		require.NotNil(t, parsed.Cause)
-		require.Contains(t, *parsed.Cause, "not found")
+		require.Contains(t, strings.ToLower(*parsed.Cause), "not found")
 
 		require.True(t, *called)
 		require.Equal(t, "application/json", w.Result().Header.Get("Content-Type"))
@@ -154,15 +174,17 @@ func TestMakeGetAccountByUsernameHandler(t *testing.T) {
 		handler.ServeHTTP(w, req)
 
 		require.Equal(t, http.StatusServiceUnavailable, w.Code)
+		requireOpenAPIResponse(t, "GET /v1/account/username/{username}", w)
 		body := w.Body.String()
 		parsed := parseResponse(t, body)
 		require.NotNil(t, parsed.Success)
 		require.False(t, *parsed.Success)
 		require.Nil(t, parsed.UUID)
-		require.NotNil(t, parsed.Username)
-		require.Equal(t, username, *parsed.Username)
+		require.Nil(t, parsed.Username)
+		require.NotNil(t, parsed.Code)
+		require.Equal(t, "temporarily_unavailable", *parsed.Code)
 		require.NotNil(t, parsed.Cause)
-		require.Contains(t, *parsed.Cause, "temporarily unavailable")
+		require.Contains(t, strings.ToLower(*parsed.Cause), "temporarily unavailable")
 
 		require.True(t, *called)
 		require.Equal(t, "application/json", w.Result().Header.Get("Content-Type"))
@@ -180,15 +202,17 @@ func TestMakeGetAccountByUsernameHandler(t *testing.T) {
 		handler.ServeHTTP(w, req)
 
 		require.Equal(t, http.StatusBadRequest, w.Code)
+		requireOpenAPIResponse(t, "GET /v1/account/username/{username}", w)
 		body := w.Body.String()
 		parsed := parseResponse(t, body)
 		require.NotNil(t, parsed.Success)
 		require.False(t, *parsed.Success)
 		require.Nil(t, parsed.UUID)
-		require.NotNil(t, parsed.Username)
-		require.Equal(t, "<invalid>", *parsed.Username)
+		require.Nil(t, parsed.Username)
+		require.NotNil(t, parsed.Code)
+		require.Equal(t, "bad_request", *parsed.Code)
 		require.NotNil(t, parsed.Cause)
-		require.Contains(t, *parsed.Cause, "invalid username length")
+		require.Contains(t, strings.ToLower(*parsed.Cause), "invalid username length")
 
 		require.False(t, *called)
 		require.Equal(t, "application/json", w.Result().Header.Get("Content-Type"))
@@ -206,13 +230,16 @@ func TestMakeGetAccountByUsernameHandler(t *testing.T) {
 		handler.ServeHTTP(w, req)
 
 		require.Equal(t, http.StatusBadRequest, w.Code)
+		requireOpenAPIResponse(t, "GET /v1/account/username/{username}", w)
 		body := w.Body.String()
 		parsed := parseResponse(t, body)
 		require.NotNil(t, parsed.Success)
 		require.False(t, *parsed.Success)
 		require.Nil(t, parsed.UUID)
+		require.NotNil(t, parsed.Code)
+		require.Equal(t, "bad_request", *parsed.Code)
 		require.NotNil(t, parsed.Cause)
-		require.Contains(t, *parsed.Cause, "invalid username")
+		require.Contains(t, strings.ToLower(*parsed.Cause), "invalid username")
 
 		require.False(t, *called)
 		require.Equal(t, "application/json", w.Result().Header.Get("Content-Type"))
@@ -230,13 +257,16 @@ func TestMakeGetAccountByUsernameHandler(t *testing.T) {
 		handler.ServeHTTP(w, req)
 
 		require.Equal(t, http.StatusBadRequest, w.Code)
+		requireOpenAPIResponse(t, "GET /v1/account/username/{username}", w)
 		body := w.Body.String()
 		parsed := parseResponse(t, body)
 		require.NotNil(t, parsed.Success)
 		require.False(t, *parsed.Success)
 		require.Nil(t, parsed.UUID)
+		require.NotNil(t, parsed.Code)
+		require.Equal(t, "bad_request", *parsed.Code)
 		require.NotNil(t, parsed.Cause)
-		require.Contains(t, *parsed.Cause, "invalid username")
+		require.Contains(t, strings.ToLower(*parsed.Cause), "invalid username")
 
 		require.False(t, *called)
 		require.Equal(t, "application/json", w.Result().Header.Get("Content-Type"))
@@ -261,6 +291,7 @@ func TestMakeGetAccountByUsernameHandler(t *testing.T) {
 		handler.ServeHTTP(w, req)
 
 		require.Equal(t, http.StatusOK, w.Code)
+		requireOpenAPIResponse(t, "GET /v1/account/username/{username}", w)
 		body := w.Body.String()
 		require.JSONEq(t, successJSON, body)
 		require.True(t, *called)
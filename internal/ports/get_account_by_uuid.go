@@ -1,20 +1,21 @@
 package ports
 
 import (
-	"context"
-	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
+	"time"
 
 	"github.com/Amund211/flashlight/internal/app"
-	"github.com/Amund211/flashlight/internal/domain"
 	"github.com/Amund211/flashlight/internal/logging"
-	"github.com/Amund211/flashlight/internal/ratelimiting"
 	"github.com/Amund211/flashlight/internal/reporting"
 	"github.com/Amund211/flashlight/internal/strutils"
 )
 
+// accountByUUIDMaxAge is the TTL of the account by uuid cache in main.go,
+// kept short so name changes show up quickly.
+const accountByUUIDMaxAge = 1 * time.Minute
+
 func MakeGetAccountByUUIDHandler(
 	getAccountByUUID app.GetAccountByUUID,
 	registerUserVisit app.RegisterUserVisit,
@@ -24,67 +25,29 @@ func MakeGetAccountByUUIDHandler(
 	bearerAuthMiddleware func(http.HandlerFunc) http.HandlerFunc,
 	blocklistConfig BlocklistConfig,
 ) (http.HandlerFunc, func()) {
-	ipLimiter, stopIPLimiter := ratelimiting.NewTokenBucketRateLimiter(
-		ratelimiting.RefillPerSecond(8),
-		ratelimiting.BurstSize(480),
-	)
-	ipRateLimiter := ratelimiting.NewRequestBasedRateLimiter(
-		ipLimiter,
-		IPHashKeyFunc,
-	)
-	userIDLimiter, stopUserIDLimiter := ratelimiting.NewTokenBucketRateLimiter(
-		ratelimiting.RefillPerSecond(2),
-		ratelimiting.BurstSize(120),
-	)
-	userIDRateLimiter := ratelimiting.NewRequestBasedRateLimiter(
-		// NOTE: Verified identity when there is one, user controlled value otherwise
-		userIDLimiter,
-		UserIDKeyFunc,
-	)
-
-	makeOnLimitExceeded := func(rateLimiter ratelimiting.RequestRateLimiter) http.HandlerFunc {
-		return func(w http.ResponseWriter, r *http.Request) {
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusTooManyRequests)
-			w.Write([]byte(`{"success":false,"cause":"rate limit exceeded"}`))
-		}
-	}
-
-	middleware := ComposeMiddlewares(
-		NewRequestLoggerMiddleware(rootLogger),
+	middleware, stop := mustBuildRouteMiddleware(
+		RouteSpec{
+			Name:           "get_account_by_uuid",
+			AllowedOrigins: allowedOrigins,
+			BearerAuth:     bearerAuthMiddleware,
+			RateLimits: []RateLimit{
+				IPRateLimit(8, 480),
+				IdentityRateLimit(2, 120),
+			},
+			RegisterUserVisit: registerUserVisit,
+		},
+		rootLogger,
 		sentryMiddleware,
-		BuildBlocklistMiddleware(blocklistConfig),
-		buildMetricsMiddleware("get_account_by_uuid"),
-		NewReportingMetaMiddleware("get_account_by_uuid"),
-		BuildCORSMiddleware(allowedOrigins),
-		NewRateLimitMiddleware(ipRateLimiter, makeOnLimitExceeded(ipRateLimiter)),
-		bearerAuthMiddleware,
-		NewRateLimitMiddleware(userIDRateLimiter, makeOnLimitExceeded(userIDRateLimiter)),
-		BuildRegisterUserVisitMiddleware(registerUserVisit),
+		blocklistConfig,
 	)
 
 	handler := func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 		rawUUID := r.PathValue("uuid")
 
-		handleError := func(ctx context.Context, cause string, statusCode int) {
-			response, err := makeErrorAccountResponseForUUID(ctx, rawUUID, cause)
-			if err != nil {
-				reporting.Report(ctx, fmt.Errorf("failed to marshal error response: %w", err))
-				w.Header().Set("Content-Type", "application/json")
-				w.WriteHeader(http.StatusInternalServerError)
-				w.Write([]byte(`{"success":false,"cause":"internal server error"}`))
-				return
-			}
-
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(statusCode)
-			w.Write(response)
-		}
-
 		uuid, err := strutils.NormalizeUUID(rawUUID)
 		if err != nil {
-			handleError(ctx, "invalid uuid", http.StatusBadRequest)
+			writeErrorResponse(ctx, w, badRequestError("invalid uuid"))
 			return
 		}
 
@@ -102,17 +65,9 @@ func MakeGetAccountByUUIDHandler(
 		)
 
 		account, err := getAccountByUUID(ctx, uuid)
-		if errors.Is(err, domain.ErrUsernameNotFound) {
-			handleError(ctx, "not found", http.StatusNotFound)
-			return
-		} else if errors.Is(err, domain.ErrTemporarilyUnavailable) {
-			handleError(ctx, "temporarily unavailable", http.StatusServiceUnavailable)
-			return
-		}
-
 		if err != nil {
 			// NOTE: GetAccountByUUID implementations handle their own error reporting
-			handleError(ctx, "internal server error", http.StatusInternalServerError)
+			writeErrorResponse(ctx, w, apiErrorFromDomain(err, "Internal server error"))
 			return
 		}
 
@@ -126,23 +81,12 @@ func MakeGetAccountByUUIDHandler(
 		response, err := makeSuccessAccountResponse(ctx, account)
 		if err != nil {
 			reporting.Report(ctx, fmt.Errorf("failed to create success response: %w", err))
-			handleError(ctx, "internal server error", http.StatusInternalServerError)
+			writeErrorResponse(ctx, w, internalError())
 			return
 		}
 
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		w.Write(response)
-	}
-
-	stop := func() {
-		stopIPLimiter()
-		stopUserIDLimiter()
+		writeConditionalJSON(ctx, w, r, response, cacheControlFor(accountByUUIDMaxAge))
 	}
 
 	return middleware(handler), stop
 }
-
-func makeErrorAccountResponseForUUID(ctx context.Context, uuid string, cause string) ([]byte, error) {
-	return makeAccountResponse(ctx, "", false, uuid, cause)
-}
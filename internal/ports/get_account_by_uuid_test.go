@@ -8,6 +8,7 @@ import (
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -68,6 +69,7 @@ func TestMakeGetAccountByUUIDHandler(t *testing.T) {
 		Success  *bool   `json:"success"`
 		Username *string `json:"username"`
 		UUID     *string `json:"uuid"`
+		Code     *string `json:"code"`
 		Cause    *string `json:"cause"`
 	}
 
@@ -102,6 +104,7 @@ func TestMakeGetAccountByUUIDHandler(t *testing.T) {
 		handler.ServeHTTP(w, req)
 
 		require.Equal(t, http.StatusOK, w.Code)
+		requireOpenAPIResponse(t, "GET /v1/account/uuid/{uuid}", w)
 		body := w.Body.String()
 		require.JSONEq(t, successJSON, body)
 		parsed := parseResponse(t, body)
@@ -133,6 +136,7 @@ func TestMakeGetAccountByUUIDHandler(t *testing.T) {
 		handler.ServeHTTP(w, req)
 
 		require.Equal(t, http.StatusOK, w.Code)
+		requireOpenAPIResponse(t, "GET /v1/account/uuid/{uuid}", w)
 		body := w.Body.String()
 		require.JSONEq(t, successJSON, body)
 		parsed := parseResponse(t, body)
@@ -148,6 +152,21 @@ func TestMakeGetAccountByUUIDHandler(t *testing.T) {
 		require.Equal(t, "application/json", w.Result().Header.Get("Content-Type"))
 	})
 
+	t.Run("conditional get", func(t *testing.T) {
+		t.Parallel()
+
+		getAccountByUUID, _ := makeGetAccountByUUID(t, uuid, domain.Account{
+			Username:  "SomeGuy",
+			UUID:      uuid,
+			QueriedAt: now.Add(-time.Hour),
+		}, nil)
+		handler := makeGetAccountByUUIDHandler(getAccountByUUID)
+
+		requireConditionalGET(t, "GET /v1/account/uuid/{uuid}", handler, func() *http.Request {
+			return makeRequest(uuid)
+		}, "private, max-age=60")
+	})
+
 	t.Run("uuid does not exist", func(t *testing.T) {
 		t.Parallel()
 
@@ -160,15 +179,17 @@ func TestMakeGetAccountByUUIDHandler(t *testing.T) {
 		handler.ServeHTTP(w, req)
 
 		require.Equal(t, http.StatusNotFound, w.Code)
+		requireOpenAPIResponse(t, "GET /v1/account/uuid/{uuid}", w)
 		body := w.Body.String()
 		parsed := parseResponse(t, body)
 		require.NotNil(t, parsed.Success)
 		require.False(t, *parsed.Success)
 		require.Nil(t, parsed.Username)
-		require.NotNil(t, parsed.UUID)
-		require.Equal(t, uuid, *parsed.UUID)
+		require.Nil(t, parsed.UUID)
+		require.NotNil(t, parsed.Code)
+		require.Equal(t, "not_found", *parsed.Code)
 		require.NotNil(t, parsed.Cause)
-		require.Contains(t, *parsed.Cause, "not found")
+		require.Contains(t, strings.ToLower(*parsed.Cause), "not found")
 
 		require.True(t, *called)
 		require.Equal(t, "application/json", w.Result().Header.Get("Content-Type"))
@@ -186,15 +207,17 @@ func TestMakeGetAccountByUUIDHandler(t *testing.T) {
 		handler.ServeHTTP(w, req)
 
 		require.Equal(t, http.StatusServiceUnavailable, w.Code)
+		requireOpenAPIResponse(t, "GET /v1/account/uuid/{uuid}", w)
 		body := w.Body.String()
 		parsed := parseResponse(t, body)
 		require.NotNil(t, parsed.Success)
 		require.False(t, *parsed.Success)
 		require.Nil(t, parsed.Username)
-		require.NotNil(t, parsed.UUID)
-		require.Equal(t, uuid, *parsed.UUID)
+		require.Nil(t, parsed.UUID)
+		require.NotNil(t, parsed.Code)
+		require.Equal(t, "temporarily_unavailable", *parsed.Code)
 		require.NotNil(t, parsed.Cause)
-		require.Contains(t, *parsed.Cause, "temporarily unavailable")
+		require.Contains(t, strings.ToLower(*parsed.Cause), "temporarily unavailable")
 
 		require.True(t, *called)
 		require.Equal(t, "application/json", w.Result().Header.Get("Content-Type"))
@@ -212,15 +235,17 @@ func TestMakeGetAccountByUUIDHandler(t *testing.T) {
 		handler.ServeHTTP(w, req)
 
 		require.Equal(t, http.StatusBadRequest, w.Code)
+		requireOpenAPIResponse(t, "GET /v1/account/uuid/{uuid}", w)
 		body := w.Body.String()
 		parsed := parseResponse(t, body)
 		require.NotNil(t, parsed.Success)
 		require.False(t, *parsed.Success)
 		require.Nil(t, parsed.Username)
-		require.NotNil(t, parsed.UUID)
-		require.Equal(t, "not-a-uuid", *parsed.UUID)
+		require.Nil(t, parsed.UUID)
+		require.NotNil(t, parsed.Code)
+		require.Equal(t, "bad_request", *parsed.Code)
 		require.NotNil(t, parsed.Cause)
-		require.Contains(t, *parsed.Cause, "invalid uuid")
+		require.Contains(t, strings.ToLower(*parsed.Cause), "invalid uuid")
 
 		require.False(t, *called)
 		require.Equal(t, "application/json", w.Result().Header.Get("Content-Type"))
@@ -245,6 +270,7 @@ func TestMakeGetAccountByUUIDHandler(t *testing.T) {
 		handler.ServeHTTP(w, req)
 
 		require.Equal(t, http.StatusOK, w.Code)
+		requireOpenAPIResponse(t, "GET /v1/account/uuid/{uuid}", w)
 		body := w.Body.String()
 		require.JSONEq(t, successJSON, body)
 		require.True(t, *called)
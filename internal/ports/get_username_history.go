@@ -0,0 +1,120 @@
+package ports
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/Amund211/flashlight/internal/app"
+	"github.com/Amund211/flashlight/internal/domain"
+	"github.com/Amund211/flashlight/internal/logging"
+	"github.com/Amund211/flashlight/internal/reporting"
+	"github.com/Amund211/flashlight/internal/strutils"
+)
+
+// usernameHistoryMaxAge is kept short as the history changes whenever a new
+// username is observed
+const usernameHistoryMaxAge = 1 * time.Minute
+
+type usernameObservationResponse struct {
+	Username  string    `json:"username"`
+	FirstSeen time.Time `json:"firstSeen"`
+	LastSeen  time.Time `json:"lastSeen"`
+}
+
+type usernameHistoryResponse struct {
+	Success bool                          `json:"success"`
+	UUID    string                        `json:"uuid"`
+	Names   []usernameObservationResponse `json:"names"`
+}
+
+func makeSuccessUsernameHistoryResponse(uuid string, history []domain.UsernameObservation) ([]byte, error) {
+	names := make([]usernameObservationResponse, 0, len(history))
+	for _, observation := range history {
+		names = append(names, usernameObservationResponse{
+			Username:  observation.Username,
+			FirstSeen: observation.FirstSeen,
+			LastSeen:  observation.LastSeen,
+		})
+	}
+
+	data, err := json.Marshal(usernameHistoryResponse{
+		Success: true,
+		UUID:    uuid,
+		Names:   names,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal response: %w", err)
+	}
+	return data, nil
+}
+
+func MakeGetUsernameHistoryHandler(
+	getUsernameHistory app.GetUsernameHistory,
+	registerUserVisit app.RegisterUserVisit,
+	allowedOrigins *DomainSuffixes,
+	rootLogger *slog.Logger,
+	sentryMiddleware func(http.HandlerFunc) http.HandlerFunc,
+	bearerAuthMiddleware func(http.HandlerFunc) http.HandlerFunc,
+	blocklistConfig BlocklistConfig,
+) (http.HandlerFunc, func()) {
+	middleware, stop := mustBuildRouteMiddleware(
+		RouteSpec{
+			Name:           "get_username_history",
+			AllowedOrigins: allowedOrigins,
+			BearerAuth:     bearerAuthMiddleware,
+			RateLimits: []RateLimit{
+				IPRateLimit(8, 480),
+				IdentityRateLimit(2, 120),
+			},
+			RegisterUserVisit: registerUserVisit,
+		},
+		rootLogger,
+		sentryMiddleware,
+		blocklistConfig,
+	)
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		rawUUID := r.PathValue("uuid")
+
+		uuid, err := strutils.NormalizeUUID(rawUUID)
+		if err != nil {
+			writeErrorResponse(ctx, w, badRequestError("invalid uuid"))
+			return
+		}
+
+		logging.FromContext(ctx).InfoContext(ctx, "Handling get_username_history request",
+			slog.String("uuid", uuid),
+		)
+
+		ctx = logging.AddMetaToContext(ctx,
+			slog.String("uuid", uuid),
+		)
+		ctx = reporting.AddExtrasToContext(ctx,
+			map[string]string{
+				"uuid": uuid,
+			},
+		)
+
+		history, err := getUsernameHistory(ctx, uuid)
+		if err != nil {
+			// NOTE: GetUsernameHistory implementations handle their own error reporting
+			writeErrorResponse(ctx, w, apiErrorFromDomain(err, "Internal server error"))
+			return
+		}
+
+		response, err := makeSuccessUsernameHistoryResponse(uuid, history)
+		if err != nil {
+			reporting.Report(ctx, fmt.Errorf("failed to create success response: %w", err))
+			writeErrorResponse(ctx, w, internalError())
+			return
+		}
+
+		writeConditionalJSON(ctx, w, r, response, cacheControlFor(usernameHistoryMaxAge))
+	}
+
+	return middleware(handler), stop
+}
@@ -0,0 +1,143 @@
+package ports_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Amund211/flashlight/internal/app"
+	"github.com/Amund211/flashlight/internal/domain"
+	"github.com/Amund211/flashlight/internal/ports"
+)
+
+func TestMakeGetUsernameHistoryHandler(t *testing.T) {
+	t.Parallel()
+
+	testLogger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	allowedOrigins, err := ports.NewDomainSuffixes("example.com", "test.com")
+	require.NoError(t, err)
+	noopMiddleware := func(h http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			h(w, r)
+		}
+	}
+
+	uuid := "01234567-89ab-cdef-0123-456789abcdef"
+	history := []domain.UsernameObservation{
+		{
+			UUID:      uuid,
+			Username:  "Leto",
+			FirstSeen: time.Date(2026, time.January, 1, 12, 0, 0, 0, time.UTC),
+			LastSeen:  time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			UUID:      uuid,
+			Username:  "MuadDib",
+			FirstSeen: time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC),
+			LastSeen:  time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC),
+		},
+	}
+
+	makeGetUsernameHistory := func(t *testing.T, history []domain.UsernameObservation, err error) (app.GetUsernameHistory, *bool) {
+		called := false
+		return func(ctx context.Context, gotUUID string) ([]domain.UsernameObservation, error) {
+			t.Helper()
+			require.Equal(t, uuid, gotUUID)
+
+			called = true
+
+			return history, err
+		}, &called
+	}
+
+	makeHandler := func(getUsernameHistory app.GetUsernameHistory) http.HandlerFunc {
+		stubRegisterUserVisit := func(ctx context.Context, userID string, ipHash string, userAgent string) (domain.User, error) {
+			return domain.User{}, nil
+		}
+		handler, stop := ports.MakeGetUsernameHistoryHandler(
+			getUsernameHistory,
+			stubRegisterUserVisit,
+			allowedOrigins,
+			testLogger,
+			noopMiddleware,
+			noopMiddleware,
+			emptyBlocklistConfig,
+		)
+		t.Cleanup(stop)
+		return handler
+	}
+
+	makeRequest := func(uuid string) *http.Request {
+		req := httptest.NewRequestWithContext(t.Context(), "GET", fmt.Sprintf("/v1/account/uuid/%s/names", uuid), nil)
+		req.SetPathValue("uuid", uuid)
+		return req
+	}
+
+	t.Run("history", func(t *testing.T) {
+		t.Parallel()
+
+		getUsernameHistory, called := makeGetUsernameHistory(t, history, nil)
+		handler := makeHandler(getUsernameHistory)
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, makeRequest("0123456789ABCDEF0123456789ABCDEF"))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		requireOpenAPIResponse(t, "GET /v1/account/uuid/{uuid}/names", w)
+		require.JSONEq(t, fmt.Sprintf(`{
+			"success": true,
+			"uuid": "%s",
+			"names": [
+				{"username": "Leto", "firstSeen": "2026-01-01T12:00:00Z", "lastSeen": "2026-03-01T12:00:00Z"},
+				{"username": "MuadDib", "firstSeen": "2026-10-18T12:00:00Z", "lastSeen": "2026-10-18T12:00:00Z"}
+			]
+		}`, uuid), w.Body.String())
+		require.True(t, *called)
+	})
+
+	t.Run("conditional get", func(t *testing.T) {
+		t.Parallel()
+
+		getUsernameHistory, _ := makeGetUsernameHistory(t, history, nil)
+		handler := makeHandler(getUsernameHistory)
+
+		requireConditionalGET(t, "GET /v1/account/uuid/{uuid}/names", handler, func() *http.Request {
+			return makeRequest(uuid)
+		}, "private, max-age=60")
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		t.Parallel()
+
+		getUsernameHistory, called := makeGetUsernameHistory(t, nil, domain.ErrUsernameNotFound)
+		handler := makeHandler(getUsernameHistory)
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, makeRequest(uuid))
+
+		require.Equal(t, http.StatusNotFound, w.Code)
+		requireOpenAPIResponse(t, "GET /v1/account/uuid/{uuid}/names", w)
+		require.True(t, *called)
+	})
+
+	t.Run("invalid uuid", func(t *testing.T) {
+		t.Parallel()
+
+		getUsernameHistory, called := makeGetUsernameHistory(t, history, nil)
+		handler := makeHandler(getUsernameHistory)
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, makeRequest("not-a-uuid"))
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		requireOpenAPIResponse(t, "GET /v1/account/uuid/{uuid}/names", w)
+		require.False(t, *called)
+	})
+}
@@ -0,0 +1,137 @@
+package ports
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Amund211/flashlight/internal/app"
+	"github.com/Amund211/flashlight/internal/domain"
+	"github.com/Amund211/flashlight/internal/logging"
+	"github.com/Amund211/flashlight/internal/reporting"
+)
+
+type usernameOwnerResponse struct {
+	Success bool `json:"success"`
+	// Username is the casing the owner had
+	Username  string    `json:"username"`
+	UUID      string    `json:"uuid"`
+	FirstSeen time.Time `json:"firstSeen"`
+	LastSeen  time.Time `json:"lastSeen"`
+}
+
+func makeSuccessUsernameOwnerResponse(owner domain.UsernameObservation) ([]byte, error) {
+	data, err := json.Marshal(usernameOwnerResponse{
+		Success:   true,
+		Username:  owner.Username,
+		UUID:      owner.UUID,
+		FirstSeen: owner.FirstSeen,
+		LastSeen:  owner.LastSeen,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal response: %w", err)
+	}
+	return data, nil
+}
+
+// MakeGetUsernameOwnerHandler serves the account that had a username at the
+// time given by the `at` query parameter, or the latest one when it is left
+// out. Unlike the account by username endpoint this answers for names that
+// have since been changed.
+func MakeGetUsernameOwnerHandler(
+	getUsernameOwnerAt app.GetUsernameOwnerAt,
+	nowFunc func() time.Time,
+	registerUserVisit app.RegisterUserVisit,
+	allowedOrigins *DomainSuffixes,
+	rootLogger *slog.Logger,
+	sentryMiddleware func(http.HandlerFunc) http.HandlerFunc,
+	bearerAuthMiddleware func(http.HandlerFunc) http.HandlerFunc,
+	blocklistConfig BlocklistConfig,
+) (http.HandlerFunc, func()) {
+	middleware, stop := mustBuildRouteMiddleware(
+		RouteSpec{
+			Name:           "get_username_owner",
+			AllowedOrigins: allowedOrigins,
+			BearerAuth:     bearerAuthMiddleware,
+			RateLimits: []RateLimit{
+				IPRateLimit(8, 480),
+				IdentityRateLimit(2, 120),
+			},
+			RegisterUserVisit: registerUserVisit,
+		},
+		rootLogger,
+		sentryMiddleware,
+		blocklistConfig,
+	)
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		username := r.PathValue("username")
+		rawAt := r.URL.Query().Get("at")
+
+		logging.FromContext(ctx).InfoContext(ctx, "Handling get_username_owner request",
+			slog.String("username", username),
+			slog.String("at", rawAt),
+		)
+
+		ctx = logging.AddMetaToContext(ctx,
+			slog.String("username", username),
+		)
+		ctx = reporting.AddExtrasToContext(ctx,
+			map[string]string{
+				"username": username,
+				"at":       rawAt,
+			},
+		)
+
+		usernameLength := len(username)
+		if usernameLength == 0 || usernameLength > 100 {
+			writeErrorResponse(ctx, w, badRequestError("invalid username length"))
+			return
+		}
+
+		if strings.ContainsAny(username, "§�") {
+			logging.FromContext(ctx).WarnContext(ctx, "Rejecting username with disallowed character")
+			writeErrorResponse(ctx, w, badRequestError("invalid username"))
+			return
+		}
+
+		at := nowFunc()
+		if rawAt != "" {
+			parsed, err := time.Parse(time.RFC3339, rawAt)
+			if err != nil {
+				writeErrorResponse(ctx, w, badRequestError("invalid at, expected an RFC 3339 timestamp"))
+				return
+			}
+			at = parsed
+		}
+
+		owner, err := getUsernameOwnerAt(ctx, username, at)
+		if err != nil {
+			// NOTE: GetUsernameOwnerAt implementations handle their own error reporting
+			writeErrorResponse(ctx, w, apiErrorFromDomain(err, "Internal server error"))
+			return
+		}
+
+		ctx = reporting.AddExtrasToContext(ctx,
+			map[string]string{
+				"uuid": owner.UUID,
+			},
+		)
+		ctx = logging.AddMetaToContext(ctx, slog.String("uuid", owner.UUID))
+
+		response, err := makeSuccessUsernameOwnerResponse(owner)
+		if err != nil {
+			reporting.Report(ctx, fmt.Errorf("failed to create success response: %w", err))
+			writeErrorResponse(ctx, w, internalError())
+			return
+		}
+
+		writeConditionalJSON(ctx, w, r, response, cacheControlFor(usernameHistoryMaxAge))
+	}
+
+	return middleware(handler), stop
+}
@@ -0,0 +1,168 @@
+package ports_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Amund211/flashlight/internal/app"
+	"github.com/Amund211/flashlight/internal/domain"
+	"github.com/Amund211/flashlight/internal/ports"
+)
+
+func TestMakeGetUsernameOwnerHandler(t *testing.T) {
+	t.Parallel()
+
+	testLogger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	allowedOrigins, err := ports.NewDomainSuffixes("example.com", "test.com")
+	require.NoError(t, err)
+	noopMiddleware := func(h http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			h(w, r)
+		}
+	}
+
+	now := time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC)
+	owner := domain.UsernameObservation{
+		UUID:      "01234567-89ab-cdef-0123-456789abcdef",
+		Username:  "MuadDib",
+		FirstSeen: time.Date(2026, time.January, 1, 12, 0, 0, 0, time.UTC),
+		LastSeen:  time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	makeGetUsernameOwnerAt := func(t *testing.T, expectedAt time.Time, owner domain.UsernameObservation, err error) (app.GetUsernameOwnerAt, *bool) {
+		called := false
+		return func(ctx context.Context, username string, at time.Time) (domain.UsernameObservation, error) {
+			t.Helper()
+			require.Equal(t, "muaddib", username)
+			require.True(t, expectedAt.Equal(at), "expected %s, got %s", expectedAt, at)
+
+			called = true
+
+			return owner, err
+		}, &called
+	}
+
+	makeHandler := func(getUsernameOwnerAt app.GetUsernameOwnerAt) http.HandlerFunc {
+		stubRegisterUserVisit := func(ctx context.Context, userID string, ipHash string, userAgent string) (domain.User, error) {
+			return domain.User{}, nil
+		}
+		handler, stop := ports.MakeGetUsernameOwnerHandler(
+			getUsernameOwnerAt,
+			func() time.Time { return now },
+			stubRegisterUserVisit,
+			allowedOrigins,
+			testLogger,
+			noopMiddleware,
+			noopMiddleware,
+			emptyBlocklistConfig,
+		)
+		t.Cleanup(stop)
+		return handler
+	}
+
+	makeRequest := func(username string, query string) *http.Request {
+		req := httptest.NewRequestWithContext(t.Context(), "GET", fmt.Sprintf("/v1/account/username/%s/owner%s", username, query), nil)
+		req.SetPathValue("username", username)
+		return req
+	}
+
+	expectedBody := `{
+		"success": true,
+		"username": "MuadDib",
+		"uuid": "01234567-89ab-cdef-0123-456789abcdef",
+		"firstSeen": "2026-01-01T12:00:00Z",
+		"lastSeen": "2026-03-01T12:00:00Z"
+	}`
+
+	t.Run("at a time", func(t *testing.T) {
+		t.Parallel()
+
+		at := time.Date(2026, time.February, 1, 13, 0, 0, 0, time.UTC)
+		getUsernameOwnerAt, called := makeGetUsernameOwnerAt(t, at, owner, nil)
+		handler := makeHandler(getUsernameOwnerAt)
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, makeRequest("muaddib", "?at=2026-02-01T14:00:00%2B01:00"))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		requireOpenAPIResponse(t, "GET /v1/account/username/{username}/owner", w)
+		require.JSONEq(t, expectedBody, w.Body.String())
+		require.True(t, *called)
+	})
+
+	t.Run("defaults to now", func(t *testing.T) {
+		t.Parallel()
+
+		getUsernameOwnerAt, called := makeGetUsernameOwnerAt(t, now, owner, nil)
+		handler := makeHandler(getUsernameOwnerAt)
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, makeRequest("muaddib", ""))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		requireOpenAPIResponse(t, "GET /v1/account/username/{username}/owner", w)
+		require.JSONEq(t, expectedBody, w.Body.String())
+		require.True(t, *called)
+	})
+
+	t.Run("conditional get", func(t *testing.T) {
+		t.Parallel()
+
+		getUsernameOwnerAt, _ := makeGetUsernameOwnerAt(t, now, owner, nil)
+		handler := makeHandler(getUsernameOwnerAt)
+
+		requireConditionalGET(t, "GET /v1/account/username/{username}/owner", handler, func() *http.Request {
+			return makeRequest("muaddib", "")
+		}, "private, max-age=60")
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		t.Parallel()
+
+		getUsernameOwnerAt, called := makeGetUsernameOwnerAt(t, now, domain.UsernameObservation{}, domain.ErrUsernameNotFound)
+		handler := makeHandler(getUsernameOwnerAt)
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, makeRequest("muaddib", ""))
+
+		require.Equal(t, http.StatusNotFound, w.Code)
+		requireOpenAPIResponse(t, "GET /v1/account/username/{username}/owner", w)
+		require.True(t, *called)
+	})
+
+	t.Run("invalid at", func(t *testing.T) {
+		t.Parallel()
+
+		getUsernameOwnerAt, called := makeGetUsernameOwnerAt(t, now, owner, nil)
+		handler := makeHandler(getUsernameOwnerAt)
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, makeRequest("muaddib", "?at=yesterday"))
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		requireOpenAPIResponse(t, "GET /v1/account/username/{username}/owner", w)
+		require.False(t, *called)
+	})
+
+	t.Run("invalid username", func(t *testing.T) {
+		t.Parallel()
+
+		getUsernameOwnerAt, called := makeGetUsernameOwnerAt(t, now, owner, nil)
+		handler := makeHandler(getUsernameOwnerAt)
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, makeRequest("§ko01Y", ""))
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		requireOpenAPIResponse(t, "GET /v1/account/username/{username}/owner", w)
+		require.False(t, *called)
+	})
+}
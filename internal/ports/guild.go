@@ -0,0 +1,141 @@
+package ports
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/Amund211/flashlight/internal/app"
+	"github.com/Amund211/flashlight/internal/domain"
+	"github.com/Amund211/flashlight/internal/logging"
+	"github.com/Amund211/flashlight/internal/reporting"
+	"github.com/Amund211/flashlight/internal/strutils"
+)
+
+// guildMaxAge is the TTL of the guild cache in main.go
+const guildMaxAge = 10 * time.Minute
+
+type guildMemberResponse struct {
+	UUID   string     `json:"uuid"`
+	Rank   string     `json:"rank"`
+	Joined *time.Time `json:"joined,omitempty"`
+}
+
+type guildResponseData struct {
+	ID        string                `json:"id"`
+	Name      string                `json:"name"`
+	Tag       *string               `json:"tag,omitempty"`
+	TagColor  *string               `json:"tagColor,omitempty"`
+	Members   []guildMemberResponse `json:"members"`
+	QueriedAt time.Time             `json:"queriedAt"`
+}
+
+type guildResponse struct {
+	Success bool   `json:"success"`
+	UUID    string `json:"uuid"`
+	// Null when the player is not in a guild
+	Guild *guildResponseData `json:"guild"`
+}
+
+func makeSuccessGuildResponse(uuid string, guild *domain.Guild) ([]byte, error) {
+	response := guildResponse{
+		Success: true,
+		UUID:    uuid,
+	}
+
+	if guild != nil {
+		members := make([]guildMemberResponse, 0, len(guild.Members))
+		for _, member := range guild.Members {
+			members = append(members, guildMemberResponse{
+				UUID:   member.UUID,
+				Rank:   member.Rank,
+				Joined: member.Joined,
+			})
+		}
+		response.Guild = &guildResponseData{
+			ID:        guild.ID,
+			Name:      guild.Name,
+			Tag:       guild.Tag,
+			TagColor:  guild.TagColor,
+			Members:   members,
+			QueriedAt: guild.QueriedAt,
+		}
+	}
+
+	data, err := json.Marshal(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal response: %w", err)
+	}
+	return data, nil
+}
+
+func MakeGetGuildByPlayerHandler(
+	getGuildByPlayer app.GetGuildByPlayer,
+	registerUserVisit app.RegisterUserVisit,
+	allowedOrigins *DomainSuffixes,
+	rootLogger *slog.Logger,
+	sentryMiddleware func(http.HandlerFunc) http.HandlerFunc,
+	bearerAuthMiddleware func(http.HandlerFunc) http.HandlerFunc,
+	blocklistConfig BlocklistConfig,
+) (http.HandlerFunc, func()) {
+	middleware, stop := mustBuildRouteMiddleware(
+		RouteSpec{
+			Name:           "get_guild_by_player",
+			AllowedOrigins: allowedOrigins,
+			BearerAuth:     bearerAuthMiddleware,
+			RateLimits: []RateLimit{
+				IPRateLimit(8, 480),
+				IdentityRateLimit(2, 120),
+			},
+			RegisterUserVisit: registerUserVisit,
+			Compress:          true,
+		},
+		rootLogger,
+		sentryMiddleware,
+		blocklistConfig,
+	)
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		rawUUID := r.PathValue("uuid")
+
+		uuid, err := strutils.NormalizeUUID(rawUUID)
+		if err != nil {
+			writeErrorResponse(ctx, w, badRequestError("invalid uuid"))
+			return
+		}
+
+		logging.FromContext(ctx).InfoContext(ctx, "Handling get_guild_by_player request",
+			slog.String("uuid", uuid),
+		)
+
+		ctx = logging.AddMetaToContext(ctx,
+			slog.String("uuid", uuid),
+		)
+		ctx = reporting.AddExtrasToContext(ctx,
+			map[string]string{
+				"uuid": uuid,
+			},
+		)
+
+		guild, err := getGuildByPlayer(ctx, uuid)
+		if err != nil {
+			// NOTE: GetGuildByPlayer implementations handle their own error reporting
+			writeErrorResponse(ctx, w, apiErrorFromDomain(err, "Internal server error"))
+			return
+		}
+
+		response, err := makeSuccessGuildResponse(uuid, guild)
+		if err != nil {
+			reporting.Report(ctx, fmt.Errorf("failed to create success response: %w", err))
+			writeErrorResponse(ctx, w, internalError())
+			return
+		}
+
+		writeConditionalJSON(ctx, w, r, response, cacheControlFor(guildMaxAge))
+	}
+
+	return middleware(handler), stop
+}
@@ -0,0 +1,163 @@
+package ports_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Amund211/flashlight/internal/app"
+	"github.com/Amund211/flashlight/internal/domain"
+	"github.com/Amund211/flashlight/internal/ports"
+)
+
+func TestMakeGetGuildByPlayerHandler(t *testing.T) {
+	t.Parallel()
+
+	testLogger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	allowedOrigins, err := ports.NewDomainSuffixes("example.com", "test.com")
+	require.NoError(t, err)
+	noopMiddleware := func(h http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			h(w, r)
+		}
+	}
+
+	uuid := "01234567-89ab-cdef-0123-456789abcdef"
+	queriedAt := time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC)
+
+	makeGetGuild := func(t *testing.T, guild *domain.Guild, err error) (app.GetGuildByPlayer, *bool) {
+		called := false
+		return func(ctx context.Context, gotUUID string) (*domain.Guild, error) {
+			t.Helper()
+			require.Equal(t, uuid, gotUUID)
+
+			called = true
+
+			return guild, err
+		}, &called
+	}
+
+	makeHandler := func(getGuild app.GetGuildByPlayer) http.HandlerFunc {
+		stubRegisterUserVisit := func(ctx context.Context, userID string, ipHash string, userAgent string) (domain.User, error) {
+			return domain.User{}, nil
+		}
+		handler, stop := ports.MakeGetGuildByPlayerHandler(
+			getGuild,
+			stubRegisterUserVisit,
+			allowedOrigins,
+			testLogger,
+			noopMiddleware,
+			noopMiddleware,
+			emptyBlocklistConfig,
+		)
+		t.Cleanup(stop)
+		return handler
+	}
+
+	makeRequest := func(uuid string) *http.Request {
+		req := httptest.NewRequestWithContext(t.Context(), "GET", fmt.Sprintf("/v1/guild/player/%s", uuid), nil)
+		req.SetPathValue("uuid", uuid)
+		return req
+	}
+
+	guild := &domain.Guild{
+		ID:        "5af718d40cf2cbe7a9eeb063",
+		QueriedAt: queriedAt,
+		Name:      "Example Guild",
+		Tag:       new("EXMPL"),
+		TagColor:  new("DARK_AQUA"),
+		Members: []domain.GuildMember{
+			{UUID: uuid, Rank: "Guild Master", Joined: new(time.Date(2018, time.May, 12, 16, 39, 48, 0, time.UTC))},
+			{UUID: "fedcba98-7654-3210-fedc-ba9876543210", Rank: "Member"},
+		},
+	}
+
+	t.Run("in a guild", func(t *testing.T) {
+		t.Parallel()
+
+		getGuild, called := makeGetGuild(t, guild, nil)
+		handler := makeHandler(getGuild)
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, makeRequest("0123456789ABCDEF0123456789ABCDEF"))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		requireOpenAPIResponse(t, "GET /v1/guild/player/{uuid}", w)
+		require.JSONEq(t, fmt.Sprintf(`{
+			"success": true,
+			"uuid": "%s",
+			"guild": {
+				"id": "5af718d40cf2cbe7a9eeb063",
+				"name": "Example Guild",
+				"tag": "EXMPL",
+				"tagColor": "DARK_AQUA",
+				"members": [
+					{"uuid": "%s", "rank": "Guild Master", "joined": "2018-05-12T16:39:48Z"},
+					{"uuid": "fedcba98-7654-3210-fedc-ba9876543210", "rank": "Member"}
+				],
+				"queriedAt": "2026-10-18T12:00:00Z"
+			}
+		}`, uuid, uuid), w.Body.String())
+		require.True(t, *called)
+	})
+
+	t.Run("not in a guild", func(t *testing.T) {
+		t.Parallel()
+
+		getGuild, _ := makeGetGuild(t, nil, nil)
+		handler := makeHandler(getGuild)
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, makeRequest(uuid))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		requireOpenAPIResponse(t, "GET /v1/guild/player/{uuid}", w)
+		require.JSONEq(t, fmt.Sprintf(`{"success":true,"uuid":"%s","guild":null}`, uuid), w.Body.String())
+	})
+
+	t.Run("conditional get", func(t *testing.T) {
+		t.Parallel()
+
+		getGuild, _ := makeGetGuild(t, guild, nil)
+		handler := makeHandler(getGuild)
+
+		requireConditionalGET(t, "GET /v1/guild/player/{uuid}", handler, func() *http.Request {
+			return makeRequest(uuid)
+		}, "private, max-age=600")
+	})
+
+	t.Run("temporarily unavailable", func(t *testing.T) {
+		t.Parallel()
+
+		getGuild, called := makeGetGuild(t, nil, domain.ErrTemporarilyUnavailable)
+		handler := makeHandler(getGuild)
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, makeRequest(uuid))
+
+		require.Equal(t, http.StatusServiceUnavailable, w.Code)
+		requireOpenAPIResponse(t, "GET /v1/guild/player/{uuid}", w)
+		require.True(t, *called)
+	})
+
+	t.Run("invalid uuid", func(t *testing.T) {
+		t.Parallel()
+
+		getGuild, called := makeGetGuild(t, nil, nil)
+		handler := makeHandler(getGuild)
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, makeRequest("not-a-uuid"))
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		requireOpenAPIResponse(t, "GET /v1/guild/player/{uuid}", w)
+		require.False(t, *called)
+	})
+}
@@ -8,11 +8,12 @@ import (
 	"log/slog"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/Amund211/flashlight/internal/app"
+	"github.com/Amund211/flashlight/internal/domain"
 	"github.com/Amund211/flashlight/internal/logging"
-	"github.com/Amund211/flashlight/internal/ratelimiting"
 	"github.com/Amund211/flashlight/internal/reporting"
 	"github.com/Amund211/flashlight/internal/strutils"
 )
@@ -26,64 +27,24 @@ func MakeGetHistoryHandler(
 	bearerAuthMiddleware func(http.HandlerFunc) http.HandlerFunc,
 	blocklistConfig BlocklistConfig,
 ) (http.HandlerFunc, func()) {
-	ipLimiter, stopIPLimiter := ratelimiting.NewTokenBucketRateLimiter(
-		ratelimiting.RefillPerSecond(4),
-		ratelimiting.BurstSize(240),
-	)
-	ipRateLimiter := ratelimiting.NewRequestBasedRateLimiter(
-		ipLimiter,
-		IPHashKeyFunc,
-	)
-	ipLimiterLong, stopIPLimiterLong := ratelimiting.NewTokenBucketRateLimiter(
-		ratelimiting.RefillPerSecond(0.1),
-		ratelimiting.BurstSize(200),
-	)
-	ipRateLimiterLong := ratelimiting.NewRequestBasedRateLimiter(
-		ipLimiterLong,
-		IPHashKeyFunc,
-	)
-	userIDLimiter, stopUserIDLimiter := ratelimiting.NewTokenBucketRateLimiter(
-		ratelimiting.RefillPerSecond(1),
-		ratelimiting.BurstSize(60),
-	)
-	userIDRateLimiter := ratelimiting.NewRequestBasedRateLimiter(
-		// NOTE: Verified identity when there is one, user controlled value otherwise
-		userIDLimiter,
-		UserIDKeyFunc,
-	)
-
-	makeOnLimitExceeded := func(rateLimiter ratelimiting.RequestRateLimiter) http.HandlerFunc {
-		return func(w http.ResponseWriter, r *http.Request) {
-			ctx := r.Context()
-
-			statusCode := http.StatusTooManyRequests
-
-			logging.FromContext(ctx).InfoContext(ctx, "Rate limit exceeded", "statusCode", statusCode, "reason", "ratelimit exceeded", "key", rateLimiter.KeyFor(r))
-
-			http.Error(w, "Rate limit exceeded", statusCode)
-		}
-	}
-
-	middleware := ComposeMiddlewares(
-		NewRequestLoggerMiddleware(rootLogger),
+	middleware, stop := mustBuildRouteMiddleware(
+		RouteSpec{
+			Name:           "history",
+			AllowedOrigins: allowedOrigins,
+			BearerAuth:     bearerAuthMiddleware,
+			RateLimits: []RateLimit{
+				IPRateLimit(4, 240),
+				IPRateLimit(0.1, 200),
+				IdentityRateLimit(1, 60),
+			},
+			RegisterUserVisit: registerUserVisit,
+			Compress:          true,
+		},
+		rootLogger,
 		sentryMiddleware,
-		BuildBlocklistMiddleware(blocklistConfig),
-		buildMetricsMiddleware("history"),
-		NewReportingMetaMiddleware("history"),
-		BuildCORSMiddleware(allowedOrigins),
-		NewRateLimitMiddleware(ipRateLimiter, makeOnLimitExceeded(ipRateLimiter)),
-		NewRateLimitMiddleware(ipRateLimiterLong, makeOnLimitExceeded(ipRateLimiterLong)),
-		bearerAuthMiddleware,
-		NewRateLimitMiddleware(userIDRateLimiter, makeOnLimitExceeded(userIDRateLimiter)),
-		BuildRegisterUserVisitMiddleware(registerUserVisit),
+		blocklistConfig,
 	)
 
-	stop := func() {
-		stopIPLimiter()
-		stopIPLimiterLong()
-		stopUserIDLimiter()
-	}
-
 	handler := func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 
@@ -92,11 +53,11 @@ func MakeGetHistoryHandler(
 		if err != nil {
 			var maxBytesErr *http.MaxBytesError
 			if errors.As(err, &maxBytesErr) {
-				http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+				writeErrorResponse(ctx, w, newAPIError(http.StatusRequestEntityTooLarge, errorCodeRequestTooLarge, "Request body too large"))
 				return
 			}
 			reporting.Report(ctx, fmt.Errorf("failed to read request body: %w", err))
-			http.Error(w, "Failed to read request body", http.StatusBadRequest)
+			writeErrorResponse(ctx, w, badRequestError("Failed to read request body"))
 			return
 		}
 		request := struct {
@@ -104,11 +65,12 @@ func MakeGetHistoryHandler(
 			Start time.Time `json:"start"`
 			End   time.Time `json:"end"`
 			Limit int       `json:"limit"`
+			Game  string    `json:"game"`
 		}{}
 		err = json.Unmarshal(body, &request)
 		if err != nil {
 			logging.FromContext(ctx).WarnContext(ctx, "Failed to parse request body", "error", err, "body", string(body))
-			http.Error(w, "Failed to parse request body", http.StatusBadRequest)
+			writeErrorResponse(ctx, w, badRequestError("Failed to parse request body"))
 			return
 		}
 
@@ -121,7 +83,7 @@ func MakeGetHistoryHandler(
 		uuid, err := strutils.NormalizeUUID(request.UUID)
 		if err != nil {
 			logging.FromContext(ctx).WarnContext(ctx, "Failed to normalize UUID", "error", err, "rawUUID", request.UUID)
-			http.Error(w, "invalid uuid", http.StatusBadRequest)
+			writeErrorResponse(ctx, w, badRequestError("invalid uuid"))
 			return
 		}
 
@@ -144,37 +106,87 @@ func MakeGetHistoryHandler(
 
 		if request.Start.After(request.End) {
 			logging.FromContext(ctx).WarnContext(ctx, "Start time is after end time")
-			http.Error(w, "Start time cannot be after end time", http.StatusBadRequest)
+			writeErrorResponse(ctx, w, badRequestError("Start time cannot be after end time"))
 			return
 		}
 
 		if request.Limit < 2 || request.Limit > 100 {
-			http.Error(w, "invalid limit", http.StatusBadRequest)
+			writeErrorResponse(ctx, w, badRequestError("invalid limit"))
 			return
 		}
 
+		game := domain.GameBedwars
+		if request.Game != "" {
+			game, err = rainbowGameToGame(request.Game)
+			if err != nil {
+				writeErrorResponse(ctx, w, badRequestError("invalid game"))
+				return
+			}
+		}
+
 		history, err := getHistory(ctx, uuid, request.Start, request.End, request.Limit)
 		if err != nil {
 			// NOTE: GetHistory implementations handle their own error reporting
-			http.Error(w, "Failed to get history", http.StatusInternalServerError)
+			writeErrorResponse(ctx, w, newAPIError(http.StatusInternalServerError, errorCodeInternal, "Failed to get history"))
 			return
 		}
 
-		marshalled, err := HistoryToRainbowHistoryData(history)
+		w.Header().Add("Vary", "Accept")
+		columnar := acceptsMediaType(r.Header.Get("Accept"), RainbowColumnarMediaType)
+		contentType := "application/json"
+		if columnar {
+			contentType = RainbowColumnarMediaType
+		}
+
+		// NOTE: Bedwars keeps its own layout, so existing clients are unaffected
+		var convert func(history []domain.PlayerPIT) ([]byte, error)
+		switch {
+		case game == domain.GameBedwars && columnar:
+			convert = HistoryToRainbowHistoryColumnsData
+		case game == domain.GameBedwars:
+			convert = HistoryToRainbowHistoryData
+		case columnar:
+			convert = func(history []domain.PlayerPIT) ([]byte, error) {
+				return GameHistoryToRainbowGameHistoryColumnsData(history, game)
+			}
+		default:
+			convert = func(history []domain.PlayerPIT) ([]byte, error) {
+				return GameHistoryToRainbowGameHistoryData(history, game)
+			}
+		}
+
+		marshalled, err := convert(history)
 		if err != nil {
 			reporting.Report(ctx, fmt.Errorf("failed to convert history to response: %w", err), map[string]string{
 				"length": strconv.Itoa(len(history)),
 			})
-			http.Error(w, "Failed to marshal response", http.StatusInternalServerError)
+			writeErrorResponse(ctx, w, newAPIError(http.StatusInternalServerError, errorCodeInternal, "Failed to marshal response"))
 			return
 		}
 
-		logging.FromContext(ctx).InfoContext(ctx, "Returning history data")
+		logging.FromContext(ctx).InfoContext(ctx, "Returning history data", "contentType", contentType, "game", string(game))
 
-		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Type", contentType)
 		w.WriteHeader(http.StatusOK)
 		w.Write(marshalled)
 	}
 
 	return middleware(handler), stop
 }
+
+// acceptsMediaType reports whether the Accept header explicitly lists
+// mediaType with a non-zero weight. Wildcards don't count, so clients that
+// send */* keep getting plain JSON.
+func acceptsMediaType(accept string, mediaType string) bool {
+	for part := range strings.SplitSeq(accept, ",") {
+		candidate, params, _ := strings.Cut(part, ";")
+		if !strings.EqualFold(strings.TrimSpace(candidate), mediaType) {
+			continue
+		}
+
+		if parseQualityValue(params) > 0 {
+			return true
+		}
+	}
+	return false
+}
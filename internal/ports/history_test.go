@@ -127,6 +127,7 @@ func TestMakeGetHistoryHandler(t *testing.T) {
 		handler.ServeHTTP(w, req)
 
 		require.Equal(t, http.StatusOK, w.Code)
+		requireOpenAPIResponse(t, "POST /v1/history", w)
 		require.NoError(t, err)
 		require.JSONEq(t, string(historyJSON), w.Body.String())
 		require.True(t, *called)
@@ -146,6 +147,7 @@ func TestMakeGetHistoryHandler(t *testing.T) {
 					handler.ServeHTTP(w, req)
 
 					require.Equal(t, http.StatusOK, w.Code)
+					requireOpenAPIResponse(t, "POST /v1/history", w)
 					require.NoError(t, err)
 					require.JSONEq(t, string(historyJSON), w.Body.String())
 					require.True(t, *called)
@@ -154,6 +156,88 @@ func TestMakeGetHistoryHandler(t *testing.T) {
 		})
 	})
 
+	t.Run("encodings", func(t *testing.T) {
+		t.Parallel()
+
+		longHistory := make([]domain.PlayerPIT, 0, limit)
+		for i := range limit {
+			builder := domaintest.NewPlayerBuilder(uuid).WithExperience(int64(500 + i*100))
+			// The older stats predate dream mode and economy stats
+			if i >= limit/2 {
+				builder.
+					WithDreamMode(domain.DreamModeRushDoubles, domain.GamemodeStatsPIT{GamesPlayed: i, Wins: i / 2}).
+					WithEconomy(domain.EconomyStatsPIT{Coins: 1000 + i, IronCollected: 10 * i})
+			}
+			longHistory = append(longHistory, builder.
+				Fours().WithGamesPlayed(i).WithFinalKills(2*i).
+				Build(start.Add(time.Duration(i)*time.Hour)))
+		}
+		rowsJSON, err := ports.HistoryToRainbowHistoryData(longHistory)
+		require.NoError(t, err)
+		columnsJSON, err := ports.HistoryToRainbowHistoryColumnsData(longHistory)
+		require.NoError(t, err)
+
+		testCases := []struct {
+			name           string
+			accept         string
+			acceptEncoding string
+			contentType    string
+			body           []byte
+		}{
+			{name: "plain", contentType: "application/json", body: rowsJSON},
+			{name: "gzip", acceptEncoding: "gzip", contentType: "application/json", body: rowsJSON},
+			{name: "brotli", acceptEncoding: "gzip, br", contentType: "application/json", body: rowsJSON},
+			{name: "wildcard accept is plain json", accept: "*/*", contentType: "application/json", body: rowsJSON},
+			{name: "columnar", accept: ports.RainbowColumnarMediaType, contentType: ports.RainbowColumnarMediaType, body: columnsJSON},
+			{
+				name:           "columnar and brotli",
+				accept:         "application/json;q=0.5, " + ports.RainbowColumnarMediaType,
+				acceptEncoding: "br",
+				contentType:    ports.RainbowColumnarMediaType,
+				body:           columnsJSON,
+			},
+			{name: "refused columnar", accept: ports.RainbowColumnarMediaType + ";q=0", contentType: "application/json", body: rowsJSON},
+		}
+
+		for _, testCase := range testCases {
+			t.Run(testCase.name, func(t *testing.T) {
+				t.Parallel()
+
+				getHistoryFunc, _ := makeGetHistory(t, uuid, start, end, limit, longHistory, nil)
+				handler := makeGetHistoryHandler(getHistoryFunc)
+
+				req := makeRequest(uuid, startStr, endStr, limit)
+				req.Header.Set("Accept", testCase.accept)
+				req.Header.Set("Accept-Encoding", testCase.acceptEncoding)
+				w := httptest.NewRecorder()
+
+				handler.ServeHTTP(w, req)
+
+				require.Equal(t, http.StatusOK, w.Code)
+				require.Equal(t, testCase.contentType, w.Header().Get("Content-Type"))
+				require.Contains(t, w.Header().Values("Vary"), "Accept")
+
+				encoding := w.Header().Get("Content-Encoding")
+				if testCase.acceptEncoding == "" {
+					require.Empty(t, encoding)
+				} else {
+					require.NotEmpty(t, encoding)
+					require.Contains(t, testCase.acceptEncoding, encoding)
+				}
+
+				body := decodeBody(t, w)
+				require.JSONEq(t, string(testCase.body), body)
+
+				decoded := httptest.NewRecorder()
+				decoded.Code = w.Code
+				decoded.Header().Set("Content-Type", w.Header().Get("Content-Type"))
+				_, err := decoded.WriteString(body)
+				require.NoError(t, err)
+				requireOpenAPIResponse(t, "POST /v1/history", decoded)
+			})
+		}
+	})
+
 	t.Run("start time == end time", func(t *testing.T) {
 		t.Parallel()
 
@@ -171,6 +255,7 @@ func TestMakeGetHistoryHandler(t *testing.T) {
 		handler.ServeHTTP(w, req)
 
 		require.Equal(t, http.StatusOK, w.Code)
+		requireOpenAPIResponse(t, "POST /v1/history", w)
 		require.NoError(t, err)
 		require.JSONEq(t, string(historyJSON), w.Body.String())
 		require.True(t, *called)
@@ -188,6 +273,7 @@ func TestMakeGetHistoryHandler(t *testing.T) {
 		handler.ServeHTTP(w, req)
 
 		require.Equal(t, http.StatusBadRequest, w.Code)
+		requireOpenAPIResponse(t, "POST /v1/history", w)
 		require.Contains(t, w.Body.String(), "invalid uuid")
 		require.False(t, *called)
 	})
@@ -204,6 +290,7 @@ func TestMakeGetHistoryHandler(t *testing.T) {
 		handler.ServeHTTP(w, req)
 
 		require.Equal(t, http.StatusBadRequest, w.Code)
+		requireOpenAPIResponse(t, "POST /v1/history", w)
 		require.Contains(t, w.Body.String(), "Start time cannot be after end time")
 		require.False(t, *called)
 	})
@@ -229,6 +316,7 @@ func TestMakeGetHistoryHandler(t *testing.T) {
 		handler.ServeHTTP(w, req)
 
 		require.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+		requireOpenAPIResponse(t, "POST /v1/history", w)
 		require.False(t, *called)
 	})
 
@@ -253,6 +341,7 @@ func TestMakeGetHistoryHandler(t *testing.T) {
 		handler.ServeHTTP(w, req)
 
 		require.Equal(t, http.StatusOK, w.Code)
+		requireOpenAPIResponse(t, "POST /v1/history", w)
 		require.True(t, bearerMiddlewareRan)
 		require.True(t, *called)
 	})
@@ -327,6 +416,99 @@ func TestMakeGetHistoryHandler(t *testing.T) {
 		require.False(t, *called)
 	})
 
+	t.Run("games", func(t *testing.T) {
+		t.Parallel()
+
+		history := []domain.PlayerPIT{
+			domaintest.NewPlayerBuilder(uuid).WithGames(map[domain.Game]domain.GameStatsPIT{
+				domain.GameSkyWars: {
+					domain.GameModeOverall: {domain.StatExperience: 1000, domain.StatWins: 10},
+					domain.SkyWarsModeSolo: {domain.StatWins: 6},
+				},
+			}).Build(start),
+		}
+
+		makeGameRequest := func(game string) *http.Request {
+			body := fmt.Sprintf(`{"uuid":"%s","start":"%s","end":"%s","limit":%d,"game":"%s"}`, uuid, startStr, endStr, limit, game)
+			return httptest.NewRequestWithContext(t.Context(), "POST", "/history", strings.NewReader(body))
+		}
+
+		t.Run("bedwars", func(t *testing.T) {
+			t.Parallel()
+
+			getHistoryFunc, called := makeGetHistory(t, uuid, start, end, limit, history, nil)
+			handler := makeGetHistoryHandler(getHistoryFunc)
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, makeGameRequest("bedwars"))
+
+			require.Equal(t, http.StatusOK, w.Code)
+			requireOpenAPIResponse(t, "POST /v1/history", w)
+			expected, err := ports.HistoryToRainbowHistoryData(history)
+			require.NoError(t, err)
+			require.JSONEq(t, string(expected), w.Body.String())
+			require.True(t, *called)
+		})
+
+		t.Run("skywars", func(t *testing.T) {
+			t.Parallel()
+
+			getHistoryFunc, called := makeGetHistory(t, uuid, start, end, limit, history, nil)
+			handler := makeGetHistoryHandler(getHistoryFunc)
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, makeGameRequest("skywars"))
+
+			require.Equal(t, http.StatusOK, w.Code)
+			requireOpenAPIResponse(t, "POST /v1/history", w)
+			require.JSONEq(t, `[{
+				"uuid": "01234567-89ab-cdef-0123-456789abcdef",
+				"queriedAt": "2023-01-01T00:00:00Z",
+				"game": "skywars",
+				"modes": {"overall": {"experience": 1000, "wins": 10}, "solo": {"wins": 6}}
+			}]`, w.Body.String())
+			require.True(t, *called)
+		})
+
+		t.Run("skywars columnar", func(t *testing.T) {
+			t.Parallel()
+
+			getHistoryFunc, called := makeGetHistory(t, uuid, start, end, limit, history, nil)
+			handler := makeGetHistoryHandler(getHistoryFunc)
+
+			req := makeGameRequest("skywars")
+			req.Header.Set("Accept", ports.RainbowColumnarMediaType)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			require.Equal(t, http.StatusOK, w.Code)
+			require.Equal(t, ports.RainbowColumnarMediaType, w.Header().Get("Content-Type"))
+			requireOpenAPIResponse(t, "POST /v1/history", w)
+			require.JSONEq(t, `{
+				"game": "skywars",
+				"uuid": ["01234567-89ab-cdef-0123-456789abcdef"],
+				"queriedAt": ["2023-01-01T00:00:00Z"],
+				"modes": {"overall": {"experience": [1000], "wins": [10]}, "solo": {"wins": [6]}}
+			}`, w.Body.String())
+			require.True(t, *called)
+		})
+
+		t.Run("invalid game", func(t *testing.T) {
+			t.Parallel()
+
+			getHistoryFunc, called := makeGetHistory(t, uuid, start, end, limit, history, nil)
+			handler := makeGetHistoryHandler(getHistoryFunc)
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, makeGameRequest("pit"))
+
+			require.Equal(t, http.StatusBadRequest, w.Code)
+			requireOpenAPIResponse(t, "POST /v1/history", w)
+			require.Contains(t, w.Body.String(), "invalid game")
+			require.False(t, *called)
+		})
+	})
+
 	t.Run("invalid limits", func(t *testing.T) {
 		t.Parallel()
 		for _, invalidLimit := range []int{-1, 0, 1, 101, 1001} {
@@ -342,6 +524,7 @@ func TestMakeGetHistoryHandler(t *testing.T) {
 				handler.ServeHTTP(w, req)
 
 				require.Equal(t, http.StatusBadRequest, w.Code)
+				requireOpenAPIResponse(t, "POST /v1/history", w)
 				require.Contains(t, w.Body.String(), "invalid limit")
 				require.False(t, *called)
 			})
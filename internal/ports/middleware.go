@@ -203,7 +203,7 @@ func BuildBlocklistMiddleware(config BlocklistConfig) func(http.HandlerFunc) htt
 				attributes = append(attributes, GetClient(r).MetricAttributes()...)
 				metrics.blockedRequestCount.Add(ctx, 1, metric.WithAttributes(attributes...))
 
-				http.Error(w, `{"success": false, "detail": "This API does not allow third-party use. Reach out on the Prism discord if you have questions :^) (https://discord.gg/k4FGUnEHYg)"}`, http.StatusBadRequest)
+				writeErrorResponse(ctx, w, newAPIError(http.StatusBadRequest, errorCodeBlocked, "This API does not allow third-party use. Reach out on the Prism discord if you have questions :^) (https://discord.gg/k4FGUnEHYg)"))
 				return
 			}
 			next(w, r)
@@ -791,7 +791,11 @@ func TestRequestLoggerMiddleware(t *testing.T) {
 			logging.FromContext(r.Context()).InfoContext(r.Context(), "test")
 		}
 
-		handler := middleware(logRequest)
+		// Mounted outside the route middleware, as in main
+		clientMiddleware := NewClientMiddleware(func(version string) bool {
+			return version == "v1.12.0"
+		})
+		handler := clientMiddleware(middleware(logRequest))
 
 		w := httptest.NewRecorder()
 		handler(w, request)
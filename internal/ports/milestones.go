@@ -0,0 +1,254 @@
+package ports
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Amund211/flashlight/internal/app"
+	"github.com/Amund211/flashlight/internal/domain"
+	"github.com/Amund211/flashlight/internal/logging"
+	"github.com/Amund211/flashlight/internal/reporting"
+	"github.com/Amund211/flashlight/internal/strutils"
+)
+
+const (
+	maxMilestones     = 100
+	maxMilestoneValue = 1_000_000_000
+)
+
+// milestonesMaxAge matches the player cache: a milestone is only reached in a
+// new snapshot.
+const milestonesMaxAge = 1 * time.Minute
+
+type milestonesResponse struct {
+	Success    bool                   `json:"success"`
+	UUID       string                 `json:"uuid"`
+	Stat       string                 `json:"stat"`
+	Gamemode   string                 `json:"gamemode"`
+	Milestones []milestoneAchievement `json:"milestones"`
+}
+
+type milestoneAchievement struct {
+	Milestone int64                      `json:"milestone"`
+	FirstSeen *milestoneAchievementStats `json:"first_seen,omitempty"`
+}
+
+type milestoneAchievementStats struct {
+	Experience int64     `json:"experience"`
+	Value      int64     `json:"value"`
+	QueriedAt  time.Time `json:"queried_at"`
+}
+
+func parseMilestoneStat(rawStat string) (domain.Stat, error) {
+	switch stat := domain.Stat(rawStat); stat {
+	case domain.StatStars,
+		domain.StatExperience,
+		domain.StatWins,
+		domain.StatFinalKills,
+		domain.StatBedsBroken,
+		domain.StatKills,
+		domain.StatGamesPlayed:
+		return stat, nil
+	default:
+		return "", fmt.Errorf("unknown stat: %q", rawStat)
+	}
+}
+
+// milestonesFromQuery reads either an explicit comma separated list of
+// milestones, or a step size and an optional count. The returned error is
+// safe to show the caller.
+func milestonesFromQuery(rawMilestones, rawStep, rawCount string) ([]int64, error) {
+	parseValue := func(name, raw string) (int64, error) {
+		value, err := strconv.ParseInt(strings.TrimSpace(raw), 10, 64)
+		if err != nil || value < 1 || value > maxMilestoneValue {
+			return 0, fmt.Errorf("%s must be an integer between 1 and %d", name, maxMilestoneValue)
+		}
+		return value, nil
+	}
+
+	switch {
+	case rawMilestones != "" && rawStep != "":
+		return nil, fmt.Errorf("milestones and step are mutually exclusive")
+	case rawMilestones != "":
+		if rawCount != "" {
+			return nil, fmt.Errorf("count can only be used with step")
+		}
+		milestones := []int64{}
+		for rawMilestone := range strings.SplitSeq(rawMilestones, ",") {
+			milestone, err := parseValue("milestones", rawMilestone)
+			if err != nil {
+				return nil, err
+			}
+			milestones = append(milestones, milestone)
+		}
+		if len(milestones) > maxMilestones {
+			return nil, fmt.Errorf("at most %d milestones are allowed", maxMilestones)
+		}
+		return milestones, nil
+	case rawStep != "":
+		step, err := parseValue("step", rawStep)
+		if err != nil {
+			return nil, err
+		}
+		count := maxMilestones
+		if rawCount != "" {
+			count, err = strconv.Atoi(rawCount)
+			if err != nil || count < 1 || count > maxMilestones {
+				return nil, fmt.Errorf("count must be an integer between 1 and %d", maxMilestones)
+			}
+		}
+		milestones := make([]int64, 0, count)
+		for i := 1; i <= count && step*int64(i) <= maxMilestoneValue; i++ {
+			milestones = append(milestones, step*int64(i))
+		}
+		return milestones, nil
+	default:
+		return nil, fmt.Errorf("either milestones or step is required")
+	}
+}
+
+func MakeGetMilestonesHandler(
+	findMilestoneAchievements app.FindMilestoneAchievements,
+	registerUserVisit app.RegisterUserVisit,
+	allowedOrigins *DomainSuffixes,
+	rootLogger *slog.Logger,
+	sentryMiddleware func(http.HandlerFunc) http.HandlerFunc,
+	blocklistConfig BlocklistConfig,
+) (http.HandlerFunc, func()) {
+	middleware, stop := mustBuildRouteMiddleware(
+		RouteSpec{
+			Name:           "milestones",
+			AllowedOrigins: allowedOrigins,
+			RateLimits: []RateLimit{
+				IPRateLimit(4, 240),
+				// NOTE: Rate limiting based on user controlled value — this handler
+				//       mounts no bearer middleware, so there is no verified identity
+				//       for UserIDKeyFunc to prefer
+				IdentityRateLimit(1, 60),
+			},
+			RegisterUserVisit: registerUserVisit,
+		},
+		rootLogger,
+		sentryMiddleware,
+		blocklistConfig,
+	)
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		rawUUID := r.PathValue("uuid")
+		query := r.URL.Query()
+		rawStat := query.Get("stat")
+		rawGamemode := query.Get("gamemode")
+
+		logging.FromContext(ctx).InfoContext(ctx, "Handling milestones request",
+			slog.String("uuid", rawUUID),
+			slog.String("stat", rawStat),
+			slog.String("gamemode", rawGamemode),
+		)
+
+		ctx = logging.AddMetaToContext(ctx,
+			slog.String("uuid", rawUUID),
+		)
+
+		ctx = reporting.AddExtrasToContext(ctx,
+			map[string]string{
+				"rawUUID":  rawUUID,
+				"stat":     rawStat,
+				"gamemode": rawGamemode,
+			},
+		)
+
+		uuid, err := strutils.NormalizeUUID(rawUUID)
+		if err != nil {
+			writeErrorResponse(ctx, w, badRequestError("Invalid UUID"))
+			return
+		}
+
+		ctx = reporting.AddExtrasToContext(ctx, map[string]string{
+			"uuid": uuid,
+		})
+		ctx = logging.AddMetaToContext(ctx, slog.String("normalizedUUID", uuid))
+
+		stat, err := parseMilestoneStat(rawStat)
+		if err != nil {
+			writeErrorResponse(ctx, w, badRequestError("Invalid stat"))
+			return
+		}
+
+		gamemode := domain.GamemodeOverall
+		if rawGamemode != "" {
+			gamemode, err = rainbowGamemodeToGamemode(rawGamemode)
+			if err != nil {
+				writeErrorResponse(ctx, w, badRequestError("Invalid gamemode"))
+				return
+			}
+		}
+
+		if (stat == domain.StatStars || stat == domain.StatExperience) && gamemode != domain.GamemodeOverall {
+			writeErrorResponse(ctx, w, badRequestError(fmt.Sprintf("%s is only tracked overall", stat)))
+			return
+		}
+
+		milestones, err := milestonesFromQuery(query.Get("milestones"), query.Get("step"), query.Get("count"))
+		if err != nil {
+			writeErrorResponse(ctx, w, badRequestError(err.Error()))
+			return
+		}
+
+		achievements, err := findMilestoneAchievements(ctx, uuid, gamemode, stat, milestones)
+		if err != nil {
+			// NOTE: FindMilestoneAchievements implementations handle their own error reporting
+			writeErrorResponse(ctx, w, apiErrorFromDomain(err, "Failed to get milestones"))
+			return
+		}
+
+		rainbowGamemode, err := gamemodeToRainbowGamemode(gamemode)
+		if err != nil {
+			reporting.Report(ctx, fmt.Errorf("failed to convert gamemode: %w", err))
+			writeErrorResponse(ctx, w, newAPIError(http.StatusInternalServerError, errorCodeInternal, "Failed to serialise response"))
+			return
+		}
+
+		responseAchievements := make([]milestoneAchievement, 0, len(achievements))
+		for _, achievement := range achievements {
+			responseAchievement := milestoneAchievement{
+				Milestone: achievement.Milestone,
+			}
+			if achievement.After != nil {
+				responseAchievement.FirstSeen = &milestoneAchievementStats{
+					Experience: achievement.After.Player.Experience,
+					Value:      achievement.After.Value,
+					QueriedAt:  achievement.After.Player.QueriedAt,
+				}
+			}
+			responseAchievements = append(responseAchievements, responseAchievement)
+		}
+
+		marshalled, err := json.Marshal(milestonesResponse{
+			Success:    true,
+			UUID:       uuid,
+			Stat:       string(stat),
+			Gamemode:   rainbowGamemode,
+			Milestones: responseAchievements,
+		})
+		if err != nil {
+			reporting.Report(ctx, fmt.Errorf("failed to marshal milestones response: %w", err), map[string]string{
+				"length": strconv.Itoa(len(responseAchievements)),
+			})
+			writeErrorResponse(ctx, w, newAPIError(http.StatusInternalServerError, errorCodeInternal, "Failed to marshal response"))
+			return
+		}
+
+		logging.FromContext(ctx).InfoContext(ctx, "Returning milestones", "achievements", len(achievements))
+
+		writeConditionalJSON(ctx, w, r, marshalled, cacheControlFor(milestonesMaxAge))
+	}
+
+	return middleware(handler), stop
+}
@@ -0,0 +1,204 @@
+package ports_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Amund211/flashlight/internal/app"
+	"github.com/Amund211/flashlight/internal/domain"
+	"github.com/Amund211/flashlight/internal/domaintest"
+	"github.com/Amund211/flashlight/internal/ports"
+)
+
+func TestGetMilestonesHandler(t *testing.T) {
+	t.Parallel()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	sentryMiddleware := func(next http.HandlerFunc) http.HandlerFunc { return next }
+	allowedOrigins, err := ports.NewDomainSuffixes("example.com", "test.com")
+	require.NoError(t, err)
+
+	playerUUID := "550e8400-e29b-41d4-a716-446655440000"
+	queriedAt := time.Date(2021, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	makeHandler := func(findMilestoneAchievements app.FindMilestoneAchievements) http.HandlerFunc {
+		stubRegisterUserVisit := func(ctx context.Context, userID string, ipHash string, userAgent string) (domain.User, error) {
+			return domain.User{}, nil
+		}
+		handler, stop := ports.MakeGetMilestonesHandler(findMilestoneAchievements, stubRegisterUserVisit, allowedOrigins, logger, sentryMiddleware, emptyBlocklistConfig)
+		t.Cleanup(stop)
+		return handler
+	}
+
+	makeRequest := func(uuid string, query string) *http.Request {
+		req := httptest.NewRequestWithContext(t.Context(), "GET", "/v1/milestones/"+uuid+query, nil)
+		req.SetPathValue("uuid", uuid)
+		return req
+	}
+
+	assertNotCalled := func(t *testing.T) app.FindMilestoneAchievements {
+		return func(ctx context.Context, playerUUID string, gamemode domain.Gamemode, stat domain.Stat, milestones []int64) ([]domain.MilestoneAchievement, error) {
+			t.Helper()
+			require.False(t, true, "FindMilestoneAchievements should not have been called")
+			return nil, nil
+		}
+	}
+
+	t.Run("Explicit milestones", func(t *testing.T) {
+		t.Parallel()
+
+		handler := makeHandler(func(ctx context.Context, gotUUID string, gamemode domain.Gamemode, stat domain.Stat, milestones []int64) ([]domain.MilestoneAchievement, error) {
+			require.Equal(t, playerUUID, gotUUID)
+			require.Equal(t, domain.GamemodeFours, gamemode)
+			require.Equal(t, domain.StatWins, stat)
+			require.Equal(t, []int64{100, 500, 1000}, milestones)
+			return []domain.MilestoneAchievement{
+				{
+					Milestone: 100,
+					After: &domain.MilestoneAchievementStats{
+						Player: domaintest.NewPlayerBuilder(playerUUID).WithExperience(10_000).Build(queriedAt),
+						Value:  101,
+					},
+				},
+			}, nil
+		})
+
+		w := httptest.NewRecorder()
+		handler(w, makeRequest("550e8400e29b41d4a716446655440000", "?stat=wins&gamemode=fours&milestones=100,500,1000"))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		requireOpenAPIResponse(t, "GET /v1/milestones/{uuid}", w)
+		require.JSONEq(t, `
+		{
+			"success": true,
+			"uuid": "550e8400-e29b-41d4-a716-446655440000",
+			"stat": "wins",
+			"gamemode": "fours",
+			"milestones": [
+				{
+					"milestone": 100,
+					"first_seen": {"experience": 10000, "value": 101, "queried_at": "2021-01-01T12:00:00Z"}
+				}
+			]
+		}`, w.Body.String())
+	})
+
+	t.Run("Step", func(t *testing.T) {
+		t.Parallel()
+
+		for name, tc := range map[string]struct {
+			query      string
+			milestones []int64
+		}{
+			"default count": {query: "?stat=finalKills&step=10000000", milestones: func() []int64 {
+				milestones := []int64{}
+				for i := int64(1); i <= 100; i++ {
+					milestones = append(milestones, i*10_000_000)
+				}
+				return milestones
+			}()},
+			"explicit count":          {query: "?stat=kills&step=1000&count=3", milestones: []int64{1000, 2000, 3000}},
+			"capped at the max value": {query: "?stat=kills&step=400000000", milestones: []int64{400_000_000, 800_000_000}},
+		} {
+			t.Run(name, func(t *testing.T) {
+				t.Parallel()
+
+				handler := makeHandler(func(ctx context.Context, gotUUID string, gamemode domain.Gamemode, stat domain.Stat, milestones []int64) ([]domain.MilestoneAchievement, error) {
+					require.Equal(t, domain.GamemodeOverall, gamemode)
+					require.Equal(t, tc.milestones, milestones)
+					return []domain.MilestoneAchievement{}, nil
+				})
+
+				w := httptest.NewRecorder()
+				handler(w, makeRequest(playerUUID, tc.query))
+
+				require.Equal(t, http.StatusOK, w.Code)
+				requireOpenAPIResponse(t, "GET /v1/milestones/{uuid}", w)
+			})
+		}
+	})
+
+	t.Run("Conditional get", func(t *testing.T) {
+		t.Parallel()
+
+		handler := makeHandler(func(ctx context.Context, gotUUID string, gamemode domain.Gamemode, stat domain.Stat, milestones []int64) ([]domain.MilestoneAchievement, error) {
+			return []domain.MilestoneAchievement{}, nil
+		})
+
+		requireConditionalGET(t, "GET /v1/milestones/{uuid}", handler, func() *http.Request {
+			return makeRequest(playerUUID, "?stat=stars&step=100")
+		}, "private, max-age=60")
+	})
+
+	t.Run("Bad requests", func(t *testing.T) {
+		t.Parallel()
+
+		for name, tc := range map[string]struct {
+			uuid  string
+			query string
+		}{
+			"invalid uuid":             {uuid: "invalid-uuid", query: "?stat=wins&step=100"},
+			"missing stat":             {uuid: playerUUID, query: "?step=100"},
+			"invalid stat":             {uuid: playerUUID, query: "?stat=losses&step=100"},
+			"invalid gamemode":         {uuid: playerUUID, query: "?stat=wins&gamemode=eights&step=100"},
+			"stars in a gamemode":      {uuid: playerUUID, query: "?stat=stars&gamemode=solo&step=100"},
+			"experience in a gamemode": {uuid: playerUUID, query: "?stat=experience&gamemode=solo&step=100"},
+			"no milestones or step":    {uuid: playerUUID, query: "?stat=wins"},
+			"milestones and step":      {uuid: playerUUID, query: "?stat=wins&milestones=100&step=100"},
+			"count without step":       {uuid: playerUUID, query: "?stat=wins&milestones=100&count=2"},
+			"invalid milestone":        {uuid: playerUUID, query: "?stat=wins&milestones=100,abc"},
+			"empty milestone":          {uuid: playerUUID, query: "?stat=wins&milestones=100,,200"},
+			"negative milestone":       {uuid: playerUUID, query: "?stat=wins&milestones=-100"},
+			"milestone too large":      {uuid: playerUUID, query: "?stat=wins&milestones=1000000001"},
+			"zero step":                {uuid: playerUUID, query: "?stat=wins&step=0"},
+			"count too large":          {uuid: playerUUID, query: "?stat=wins&step=10&count=101"},
+		} {
+			t.Run(name, func(t *testing.T) {
+				t.Parallel()
+
+				w := httptest.NewRecorder()
+				makeHandler(assertNotCalled(t))(w, makeRequest(tc.uuid, tc.query))
+
+				require.Equal(t, http.StatusBadRequest, w.Code)
+				requireOpenAPIResponse(t, "GET /v1/milestones/{uuid}", w)
+			})
+		}
+	})
+
+	t.Run("Too many milestones", func(t *testing.T) {
+		t.Parallel()
+
+		query := "?stat=wins&milestones=1"
+		for i := 2; i <= 101; i++ {
+			query += ",1"
+		}
+
+		w := httptest.NewRecorder()
+		makeHandler(assertNotCalled(t))(w, makeRequest(playerUUID, query))
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		requireOpenAPIResponse(t, "GET /v1/milestones/{uuid}", w)
+	})
+
+	t.Run("App error", func(t *testing.T) {
+		t.Parallel()
+
+		handler := makeHandler(func(ctx context.Context, gotUUID string, gamemode domain.Gamemode, stat domain.Stat, milestones []int64) ([]domain.MilestoneAchievement, error) {
+			return nil, errors.New("db down")
+		})
+
+		w := httptest.NewRecorder()
+		handler(w, makeRequest(playerUUID, "?stat=wins&step=100"))
+
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+		requireOpenAPIResponse(t, "GET /v1/milestones/{uuid}", w)
+	})
+}
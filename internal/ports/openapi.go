@@ -0,0 +1,53 @@
+package ports
+
+import (
+	_ "embed"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/Amund211/flashlight/internal/logging"
+	"github.com/Amund211/flashlight/internal/reporting"
+)
+
+// openAPISpec is the HTTP contract of every route registered in main.go.
+// It is written by hand, so it is only as good as the contract tests that
+// validate the handler responses against it (see openapi_test.go).
+//
+//go:embed openapi.json
+var openAPISpec []byte
+
+func MakeOpenAPIHandler(
+	allowedOrigins *DomainSuffixes,
+	rootLogger *slog.Logger,
+	sentryMiddleware func(http.HandlerFunc) http.HandlerFunc,
+	blocklistConfig BlocklistConfig,
+) (http.HandlerFunc, func()) {
+	middleware, stop := mustBuildRouteMiddleware(
+		RouteSpec{
+			Name:           "openapi",
+			AllowedOrigins: allowedOrigins,
+			RateLimits: []RateLimit{
+				IPRateLimit(1, 30),
+			},
+		},
+		rootLogger,
+		sentryMiddleware,
+		blocklistConfig,
+	)
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		w.Header().Set("Content-Type", "application/json")
+		// The document only changes on deploy
+		w.Header().Set("Cache-Control", "public, max-age=3600")
+		w.WriteHeader(http.StatusOK)
+		if _, err := w.Write(openAPISpec); err != nil {
+			logging.FromContext(ctx).ErrorContext(ctx, "Failed to write openapi response", "error", err.Error())
+			reporting.Report(ctx, fmt.Errorf("failed to write openapi response: %w", err))
+		}
+	}
+
+	return middleware(handler), stop
+}
@@ -0,0 +1,460 @@
+package ports_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Amund211/flashlight/internal/app"
+	"github.com/Amund211/flashlight/internal/domain"
+	"github.com/Amund211/flashlight/internal/domaintest"
+	"github.com/Amund211/flashlight/internal/ports"
+)
+
+var loadOpenAPIDocument = sync.OnceValues(func() (map[string]any, error) {
+	data, err := os.ReadFile("openapi.json")
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+})
+
+func openAPIDocument(t *testing.T) map[string]any {
+	t.Helper()
+	doc, err := loadOpenAPIDocument()
+	require.NoError(t, err)
+	return doc
+}
+
+// requireOpenAPIResponse fails the test unless the recorded response is
+// documented for the given route in openapi.json: the status code must be
+// listed, the Content-Type must be one of its media types, and the body must
+// validate against that media type's schema.
+//
+// pattern is the route as registered in main.go, e.g. "GET /v1/tags/{uuid}".
+func requireOpenAPIResponse(t *testing.T, pattern string, w *httptest.ResponseRecorder) {
+	t.Helper()
+
+	doc := openAPIDocument(t)
+
+	method, path, ok := strings.Cut(pattern, " ")
+	require.True(t, ok, "pattern must be on the form 'METHOD /path'")
+
+	pathItem, ok := lookup(doc, "paths", path).(map[string]any)
+	require.True(t, ok, "path %s is not documented", path)
+	operation, ok := pathItem[strings.ToLower(method)].(map[string]any)
+	require.True(t, ok, "%s is not documented", pattern)
+
+	response, ok := lookup(operation, "responses", strconv.Itoa(w.Code)).(map[string]any)
+	require.True(t, ok, "status %d is not documented for %s", w.Code, pattern)
+	response = resolveRef(t, doc, response)
+
+	if _, ok := response["content"]; !ok {
+		require.Empty(t, w.Body.String(), "status %d of %s is documented without a body", w.Code, pattern)
+		return
+	}
+
+	mediaType, _, err := mime.ParseMediaType(w.Header().Get("Content-Type"))
+	require.NoError(t, err, "response has no valid Content-Type")
+
+	media, ok := lookup(response, "content", mediaType).(map[string]any)
+	require.True(t, ok, "content type %s is not documented for status %d of %s", mediaType, w.Code, pattern)
+	schema, ok := media["schema"].(map[string]any)
+	require.True(t, ok, "no schema for %s", mediaType)
+
+	var body any = w.Body.String()
+	if mediaType == "application/json" || strings.HasSuffix(mediaType, "+json") {
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body), "body is not valid JSON")
+	}
+
+	require.NoError(t, validateOpenAPISchema(t, doc, schema, body, "$"))
+}
+
+func lookup(node any, keys ...string) any {
+	for _, key := range keys {
+		m, ok := node.(map[string]any)
+		if !ok {
+			return nil
+		}
+		node = m[key]
+	}
+	return node
+}
+
+func resolveRef(t *testing.T, doc map[string]any, node map[string]any) map[string]any {
+	t.Helper()
+
+	for {
+		ref, ok := node["$ref"].(string)
+		if !ok {
+			return node
+		}
+		keys := strings.Split(strings.TrimPrefix(ref, "#/"), "/")
+		resolved, ok := lookup(doc, keys...).(map[string]any)
+		require.True(t, ok, "unresolvable $ref %s", ref)
+		node = resolved
+	}
+}
+
+// validateOpenAPISchema implements the subset of the OpenAPI 3.0 schema
+// object that openapi.json uses.
+func validateOpenAPISchema(t *testing.T, doc map[string]any, schema map[string]any, value any, at string) error {
+	t.Helper()
+
+	schema = resolveRef(t, doc, schema)
+
+	if value == nil {
+		if nullable, _ := schema["nullable"].(bool); nullable {
+			return nil
+		}
+	}
+
+	if allOf, ok := schema["allOf"].([]any); ok {
+		for _, sub := range allOf {
+			if err := validateOpenAPISchema(t, doc, sub.(map[string]any), value, at); err != nil {
+				return err
+			}
+		}
+	}
+
+	if oneOf, ok := schema["oneOf"].([]any); ok {
+		matches := 0
+		for _, sub := range oneOf {
+			if validateOpenAPISchema(t, doc, sub.(map[string]any), value, at) == nil {
+				matches++
+			}
+		}
+		if matches != 1 {
+			return fmt.Errorf("%s: matches %d of the oneOf schemas, expected exactly 1", at, matches)
+		}
+	}
+
+	if enum, ok := schema["enum"].([]any); ok && !slices.Contains(enum, value) {
+		return fmt.Errorf("%s: %v is not one of %v", at, value, enum)
+	}
+
+	typ, _ := schema["type"].(string)
+	switch typ {
+	case "":
+		return nil
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("%s: expected string, got %T", at, value)
+		}
+		if schema["format"] == "date-time" {
+			if _, err := time.Parse(time.RFC3339Nano, s); err != nil {
+				return fmt.Errorf("%s: %q is not a date-time", at, s)
+			}
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("%s: expected boolean, got %T", at, value)
+		}
+	case "integer", "number":
+		n, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("%s: expected %s, got %T", at, typ, value)
+		}
+		if typ == "integer" && n != float64(int64(n)) {
+			return fmt.Errorf("%s: expected integer, got %v", at, n)
+		}
+		if minimum, ok := schema["minimum"].(float64); ok && n < minimum {
+			return fmt.Errorf("%s: %v is below the minimum %v", at, n, minimum)
+		}
+		if maximum, ok := schema["maximum"].(float64); ok && n > maximum {
+			return fmt.Errorf("%s: %v is above the maximum %v", at, n, maximum)
+		}
+	case "array":
+		items, ok := value.([]any)
+		if !ok {
+			return fmt.Errorf("%s: expected array, got %T", at, value)
+		}
+		if minItems, ok := schema["minItems"].(float64); ok && len(items) < int(minItems) {
+			return fmt.Errorf("%s: expected at least %v items, got %d", at, minItems, len(items))
+		}
+		if maxItems, ok := schema["maxItems"].(float64); ok && len(items) > int(maxItems) {
+			return fmt.Errorf("%s: expected at most %v items, got %d", at, maxItems, len(items))
+		}
+		itemSchema, _ := schema["items"].(map[string]any)
+		for i, item := range items {
+			if err := validateOpenAPISchema(t, doc, itemSchema, item, fmt.Sprintf("%s[%d]", at, i)); err != nil {
+				return err
+			}
+		}
+	case "object":
+		object, ok := value.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s: expected object, got %T", at, value)
+		}
+		properties, _ := schema["properties"].(map[string]any)
+		required, _ := schema["required"].([]any)
+		for _, key := range required {
+			if _, ok := object[key.(string)]; !ok {
+				return fmt.Errorf("%s: missing required property %q", at, key)
+			}
+		}
+		for key, property := range object {
+			propertyAt := fmt.Sprintf("%s.%s", at, key)
+			if propertySchema, ok := properties[key].(map[string]any); ok {
+				if err := validateOpenAPISchema(t, doc, propertySchema, property, propertyAt); err != nil {
+					return err
+				}
+				continue
+			}
+			switch additional := schema["additionalProperties"].(type) {
+			case bool:
+				if !additional {
+					return fmt.Errorf("%s: unexpected property", propertyAt)
+				}
+			case map[string]any:
+				if err := validateOpenAPISchema(t, doc, additional, property, propertyAt); err != nil {
+					return err
+				}
+			}
+		}
+	default:
+		return fmt.Errorf("%s: unsupported schema type %q", at, typ)
+	}
+
+	return nil
+}
+
+func TestOpenAPIHandler(t *testing.T) {
+	t.Parallel()
+
+	allowedOrigins, err := ports.NewDomainSuffixes("example.com")
+	require.NoError(t, err)
+
+	handler, stop := ports.MakeOpenAPIHandler(
+		allowedOrigins,
+		slog.New(slog.NewTextHandler(io.Discard, nil)),
+		func(next http.HandlerFunc) http.HandlerFunc { return next },
+		emptyBlocklistConfig,
+	)
+	t.Cleanup(stop)
+
+	req := httptest.NewRequestWithContext(t.Context(), "GET", "/v1/openapi.json", nil)
+	w := httptest.NewRecorder()
+	handler(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	expected, err := os.ReadFile("openapi.json")
+	require.NoError(t, err)
+	require.Equal(t, string(expected), w.Body.String())
+
+	requireOpenAPIResponse(t, "GET /v1/openapi.json", w)
+}
+
+// registeredRoutes returns the routes registered in main.go, except the CORS
+// preflights which are left out of the document. Every route must go through
+// handleFunc with a literal pattern, so none can be missed.
+func registeredRoutes(t *testing.T) []string {
+	t.Helper()
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "../../main.go", nil, 0)
+	require.NoError(t, err)
+
+	routes := []string{}
+	ast.Inspect(file, func(node ast.Node) bool {
+		call, ok := node.(*ast.CallExpr)
+		if !ok || len(call.Args) == 0 {
+			return true
+		}
+
+		switch fun := call.Fun.(type) {
+		case *ast.Ident:
+			if fun.Name != "handleFunc" {
+				return true
+			}
+		case *ast.SelectorExpr:
+			if fun.Sel.Name != "Handle" && fun.Sel.Name != "HandleFunc" {
+				return true
+			}
+			// The registration inside handleFunc itself
+			if arg, ok := call.Args[0].(*ast.Ident); ok && arg.Name == "pattern" {
+				return true
+			}
+			require.Failf(t, "route registered outside handleFunc", "%s", fset.Position(call.Pos()))
+		default:
+			return true
+		}
+
+		literal, ok := call.Args[0].(*ast.BasicLit)
+		require.True(t, ok && literal.Kind == token.STRING, "route pattern is not a string literal at %s", fset.Position(call.Pos()))
+		pattern, err := strconv.Unquote(literal.Value)
+		require.NoError(t, err)
+
+		method, path, ok := strings.Cut(pattern, " ")
+		require.True(t, ok, "route pattern %q has no method", pattern)
+		if method != http.MethodOptions {
+			routes = append(routes, fmt.Sprintf("%s %s", method, path))
+		}
+		return true
+	})
+	return routes
+}
+
+func TestOpenAPIDocument(t *testing.T) {
+	t.Parallel()
+
+	doc := openAPIDocument(t)
+
+	t.Run("every route in main.go is documented, and nothing else", func(t *testing.T) {
+		t.Parallel()
+
+		registered := registeredRoutes(t)
+		require.NotEmpty(t, registered)
+
+		documented := []string{}
+		for path, pathItem := range doc["paths"].(map[string]any) {
+			for method := range pathItem.(map[string]any) {
+				documented = append(documented, fmt.Sprintf("%s %s", strings.ToUpper(method), path))
+			}
+		}
+
+		require.ElementsMatch(t, registered, documented)
+	})
+
+	t.Run("every path parameter is documented", func(t *testing.T) {
+		t.Parallel()
+
+		for path, pathItem := range doc["paths"].(map[string]any) {
+			expected := []string{}
+			for _, match := range regexp.MustCompile(`\{([^}]+)\}`).FindAllStringSubmatch(path, -1) {
+				expected = append(expected, match[1])
+			}
+
+			for method, operation := range pathItem.(map[string]any) {
+				documented := []string{}
+				parameters, _ := lookup(operation, "parameters").([]any)
+				for _, parameter := range parameters {
+					parameter := resolveRef(t, doc, parameter.(map[string]any))
+					if parameter["in"] == "path" {
+						documented = append(documented, parameter["name"].(string))
+					}
+				}
+				require.ElementsMatch(t, expected, documented, "%s %s", method, path)
+			}
+		}
+	})
+
+	t.Run("every $ref resolves", func(t *testing.T) {
+		t.Parallel()
+
+		var walk func(node any)
+		walk = func(node any) {
+			switch n := node.(type) {
+			case map[string]any:
+				if ref, ok := n["$ref"].(string); ok {
+					keys := strings.Split(strings.TrimPrefix(ref, "#/"), "/")
+					require.NotNil(t, lookup(doc, keys...), "unresolvable $ref %s", ref)
+				}
+				for _, child := range n {
+					walk(child)
+				}
+			case []any:
+				for _, child := range n {
+					walk(child)
+				}
+			}
+		}
+		walk(doc)
+	})
+
+	t.Run("every operation documents the rate limit", func(t *testing.T) {
+		t.Parallel()
+
+		for path, pathItem := range doc["paths"].(map[string]any) {
+			for method, operation := range pathItem.(map[string]any) {
+				require.NotNil(t, lookup(operation, "responses", "429"), "%s %s", method, path)
+			}
+		}
+	})
+}
+
+// player_data_test.go is a whitebox test, so the contract for the Hypixel
+// shaped playerdata responses is checked here instead.
+func TestOpenAPIPlayerData(t *testing.T) {
+	t.Parallel()
+
+	const uuid = "01234567-89ab-cdef-0123-456789abcdef"
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	stubRegisterUserVisit := func(ctx context.Context, userID string, ipHash string, userAgent string) (domain.User, error) {
+		return domain.User{}, nil
+	}
+
+	player := domaintest.NewPlayerBuilder(uuid).
+		WithExperience(1000).
+		Solo().WithWinstreak(3).WithGamesPlayed(10).WithWins(4).WithLosses(6).WithFinalKills(12).
+		Fourv4().WithGamesPlayed(2).WithBedsBroken(1).
+		BuildPtr(now)
+
+	for _, pattern := range []string{"GET /v1/playerdata", "GET /playerdata"} {
+		for _, clientType := range []string{"prism", "rainbow"} {
+			for _, tc := range []struct {
+				name   string
+				target string
+				player *domain.PlayerPIT
+				err    error
+			}{
+				{name: "success", target: "/?uuid=" + uuid, player: player},
+				{name: "not found", target: "/?uuid=" + uuid, err: domain.ErrPlayerNotFound},
+				{name: "temporarily unavailable", target: "/?uuid=" + uuid, err: domain.ErrTemporarilyUnavailable},
+				{name: "internal error", target: "/?uuid=" + uuid, err: fmt.Errorf("oops")},
+				{name: "invalid uuid", target: "/?uuid=1234"},
+			} {
+				t.Run(pattern+" "+clientType+" "+tc.name, func(t *testing.T) {
+					t.Parallel()
+
+					handler, stop := ports.MakeGetPlayerDataHandler(
+						func(ctx context.Context, uuid string, providerMode app.ProviderMode, requesterUserID string) (*domain.PlayerPIT, error) {
+							return tc.player, tc.err
+						},
+						stubRegisterUserVisit,
+						slog.New(slog.NewTextHandler(io.Discard, nil)),
+						func(next http.HandlerFunc) http.HandlerFunc { return next },
+						func(next http.HandlerFunc) http.HandlerFunc { return next },
+						emptyBlocklistConfig,
+						pattern == "GET /playerdata",
+					)
+					t.Cleanup(stop)
+
+					req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, tc.target, nil)
+					req.Header.Set("X-Client-Type", clientType)
+					req.Header.Set("X-Client-Version", map[string]string{"prism": "v1.9.0", "rainbow": "evergreen"}[clientType])
+					w := httptest.NewRecorder()
+					handler(w, req)
+
+					requireOpenAPIResponse(t, pattern, w)
+				})
+			}
+		}
+	}
+}
@@ -7,17 +7,21 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"time"
 
 	"go.opentelemetry.io/otel"
 
 	"github.com/Amund211/flashlight/internal/app"
 	"github.com/Amund211/flashlight/internal/domain"
 	"github.com/Amund211/flashlight/internal/logging"
-	"github.com/Amund211/flashlight/internal/ratelimiting"
 	"github.com/Amund211/flashlight/internal/reporting"
 	"github.com/Amund211/flashlight/internal/strutils"
 )
 
+// playerDataMaxAge is the TTL of the player cache in main.go. A fresh
+// snapshot can't be served any sooner than that.
+const playerDataMaxAge = 1 * time.Minute
+
 func MakeGetPlayerDataHandler(
 	getAndPersistPlayerWithCache app.GetAndPersistPlayerWithCache,
 	registerUserVisit app.RegisterUserVisit,
@@ -29,57 +33,20 @@ func MakeGetPlayerDataHandler(
 ) (http.HandlerFunc, func()) {
 	tracer := otel.Tracer("flashlight/ports/player_data_v1")
 
-	ipLimiter, stopIPLimiter := ratelimiting.NewTokenBucketRateLimiter(
-		ratelimiting.RefillPerSecond(8),
-		ratelimiting.BurstSize(480),
-	)
-	ipRateLimiter := ratelimiting.NewRequestBasedRateLimiter(
-		ipLimiter,
-		IPHashKeyFunc,
-	)
-	ipLimiterLong, stopIPLimiterLong := ratelimiting.NewTokenBucketRateLimiter(
-		ratelimiting.RefillPerSecond(0.1),
-		ratelimiting.BurstSize(200),
-	)
-	ipRateLimiterLong := ratelimiting.NewRequestBasedRateLimiter(
-		ipLimiterLong,
-		IPHashKeyFunc,
-	)
-	userIDLimiter, stopUserIDLimiter := ratelimiting.NewTokenBucketRateLimiter(
-		ratelimiting.RefillPerSecond(2),
-		ratelimiting.BurstSize(120),
-	)
-	userIDRateLimiter := ratelimiting.NewRequestBasedRateLimiter(
-		// NOTE: Verified identity when there is one, user controlled value otherwise
-		userIDLimiter,
-		UserIDKeyFunc,
-	)
-
-	makeOnLimitExceeded := func(rateLimiter ratelimiting.RequestRateLimiter) http.HandlerFunc {
-		return func(w http.ResponseWriter, r *http.Request) {
-			ctx := r.Context()
-
-			statusCode := http.StatusTooManyRequests
-
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(statusCode)
-			w.Write([]byte(`{"success":false,"cause":"Rate limit exceeded"}`))
-
-			logging.FromContext(ctx).InfoContext(ctx, "Returning response", "statusCode", statusCode, "reason", "ratelimit exceeded", "key", rateLimiter.KeyFor(r))
-		}
-	}
-
-	middleware := ComposeMiddlewares(
-		NewRequestLoggerMiddleware(rootLogger),
+	middleware, stop := mustBuildRouteMiddleware(
+		RouteSpec{
+			Name:       "playerdata",
+			BearerAuth: bearerAuthMiddleware,
+			RateLimits: []RateLimit{
+				IPRateLimit(8, 480),
+				IPRateLimit(0.1, 200),
+				IdentityRateLimit(2, 120),
+			},
+			RegisterUserVisit: registerUserVisit,
+		},
+		rootLogger,
 		sentryMiddleware,
-		BuildBlocklistMiddleware(blocklistConfig),
-		buildMetricsMiddleware("playerdata"),
-		NewReportingMetaMiddleware("playerdata"),
-		NewRateLimitMiddleware(ipRateLimiter, makeOnLimitExceeded(ipRateLimiter)),
-		NewRateLimitMiddleware(ipRateLimiterLong, makeOnLimitExceeded(ipRateLimiterLong)),
-		bearerAuthMiddleware,
-		NewRateLimitMiddleware(userIDRateLimiter, makeOnLimitExceeded(userIDRateLimiter)),
-		BuildRegisterUserVisitMiddleware(registerUserVisit),
+		blocklistConfig,
 	)
 
 	handler := func(w http.ResponseWriter, r *http.Request) {
@@ -92,6 +59,20 @@ func MakeGetPlayerDataHandler(
 			logging.FromContext(ctx).WarnContext(ctx, "Deprecated endpoint hit", "deprecatedEndpoint", fmt.Sprintf("%s %s", r.Method, r.URL.Path))
 		}
 
+		// Prism reads playerdata errors as if they came straight from the
+		// Hypixel API, so it keeps getting that shape until it has moved
+		// over to the error envelope.
+		hypixelStyleErrors := deprecated || GetClient(r).Type != clientTypeRainbow
+
+		writeError := func(err error) int {
+			if hypixelStyleErrors {
+				return writeHypixelStyleErrorResponse(ctx, w, err)
+			}
+			e := apiErrorFromDomain(err, "Internal server error")
+			writeErrorResponse(ctx, w, e)
+			return e.statusCode
+		}
+
 		rawUUID := r.URL.Query().Get("uuid")
 
 		logging.FromContext(ctx).InfoContext(ctx, "Handling playerdata request",
@@ -112,9 +93,13 @@ func MakeGetPlayerDataHandler(
 		if err != nil {
 			statusCode := http.StatusBadRequest
 
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(statusCode)
-			w.Write([]byte(`{"success":false,"cause":"Invalid UUID"}`))
+			if hypixelStyleErrors {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(statusCode)
+				w.Write([]byte(`{"success":false,"cause":"Invalid UUID"}`))
+			} else {
+				writeErrorResponse(ctx, w, badRequestError("Invalid UUID"))
+			}
 
 			logging.FromContext(ctx).InfoContext(ctx, "Returning response", "statusCode", statusCode, "reason", "invalid uuid")
 			return
@@ -134,13 +119,17 @@ func MakeGetPlayerDataHandler(
 		}
 
 		player, err := getAndPersistPlayerWithCache(ctx, uuid, app.ProviderModeWellKnown, requesterUserID)
-		if errors.Is(err, domain.ErrPlayerNotFound) {
+		if errors.Is(err, domain.ErrPlayerNotFound) && !hypixelStyleErrors {
+			statusCode := writeError(err)
+			logging.FromContext(ctx).InfoContext(ctx, "Returning response", "statusCode", statusCode, "reason", "not found")
+			return
+		} else if errors.Is(err, domain.ErrPlayerNotFound) {
 			hypixelAPIResponseData, err := PlayerToPrismPlayerDataResponseData(nil)
 			if err != nil {
 				logging.FromContext(ctx).ErrorContext(ctx, "Failed to convert player to hypixel API response", "error", err)
 				err = fmt.Errorf("failed to convert player to hypixel API response: %w", err)
 				reporting.Report(ctx, err)
-				statusCode := writeHypixelStyleErrorResponse(ctx, w, err)
+				statusCode := writeError(err)
 				logging.FromContext(ctx).InfoContext(ctx, "Returning response", "statusCode", statusCode, "reason", "error")
 				return
 			}
@@ -156,7 +145,7 @@ func MakeGetPlayerDataHandler(
 		if err != nil {
 			// NOTE: GetAndPersistPlayerWithCache implementations handle their own error reporting
 			logging.FromContext(ctx).ErrorContext(ctx, "Error getting player data", "error", err)
-			statusCode := writeHypixelStyleErrorResponse(ctx, w, err)
+			statusCode := writeError(err)
 			logging.FromContext(ctx).InfoContext(ctx, "Returning response", "statusCode", statusCode, "reason", "error")
 			return
 		}
@@ -168,24 +157,15 @@ func MakeGetPlayerDataHandler(
 			err = fmt.Errorf("failed to convert player to hypixel API response: %w", err)
 			reporting.Report(ctx, err)
 
-			statusCode := writeHypixelStyleErrorResponse(ctx, w, err)
+			statusCode := writeError(err)
 			logging.FromContext(ctx).InfoContext(ctx, "Returning response", "statusCode", statusCode, "reason", "error")
 			return
 		}
 
 		logging.FromContext(ctx).InfoContext(ctx, "Got minified player data", "contentLength", len(hypixelAPIResponseData), "statusCode", 200)
 
-		statusCode := 200
+		statusCode := writeConditionalJSON(ctx, w, r, hypixelAPIResponseData, cacheControlFor(playerDataMaxAge))
 		logging.FromContext(ctx).InfoContext(ctx, "Returning response", "statusCode", statusCode, "reason", "success")
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(statusCode)
-		w.Write(hypixelAPIResponseData)
-	}
-
-	stop := func() {
-		stopIPLimiter()
-		stopIPLimiterLong()
-		stopUserIDLimiter()
 	}
 
 	return middleware(handler), stop
@@ -67,6 +67,33 @@ func TestMakeGetPlayerDataHandler(t *testing.T) {
 		require.Equal(t, "application/json", resp.Header.Get("Content-Type"))
 	})
 
+	t.Run("conditional get", func(t *testing.T) {
+		t.Parallel()
+
+		player := domaintest.NewPlayerBuilder(UUID).WithExperience(1000).BuildPtr(now)
+
+		getPlayerDataHandler, stop := MakeGetPlayerDataHandler(func(ctx context.Context, uuid string, providerMode app.ProviderMode, requesterUserID string) (*domain.PlayerPIT, error) {
+			return player, nil
+		}, stubRegisterUserVisit, logger, sentryMiddleware, bearerAuthMiddleware, emptyBlocklistConfig, false)
+		t.Cleanup(stop)
+
+		w := httptest.NewRecorder()
+		getPlayerDataHandler(w, httptest.NewRequestWithContext(t.Context(), http.MethodGet, target, nil))
+		require.Equal(t, 200, w.Code)
+		etag := w.Header().Get("ETag")
+		require.NotEmpty(t, etag)
+		require.Equal(t, "private, max-age=60", w.Header().Get("Cache-Control"))
+
+		req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, target, nil)
+		req.Header.Set("If-None-Match", etag)
+		w = httptest.NewRecorder()
+		getPlayerDataHandler(w, req)
+
+		require.Equal(t, http.StatusNotModified, w.Code)
+		require.Empty(t, w.Body.String())
+		require.Equal(t, etag, w.Header().Get("ETag"))
+	})
+
 	t.Run("requester user id is passed through from the X-User-Id header", func(t *testing.T) {
 		t.Parallel()
 
@@ -200,8 +227,10 @@ func TestMakeGetPlayerDataHandler(t *testing.T) {
 			}
 
 			require.Equal(t, 429, resp.StatusCode)
-			require.Equal(t, `{"success":false,"cause":"Rate limit exceeded"}`, w.Body.String())
+			// Refilling at 8/s, the next token is always less than a second away
+			require.JSONEq(t, `{"success":false,"code":"rate_limited","message":"Rate limit exceeded","cause":"Rate limit exceeded","retryable":true,"retryAfterSeconds":1}`, w.Body.String())
 			require.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+			require.Equal(t, "1", resp.Header.Get("Retry-After"))
 			return
 		}
 
@@ -209,7 +238,7 @@ func TestMakeGetPlayerDataHandler(t *testing.T) {
 	})
 }
 
-func TestWriteErrorResponse(t *testing.T) {
+func TestWriteHypixelStyleErrorResponse(t *testing.T) {
 	t.Parallel()
 
 	testCases := []struct {
@@ -0,0 +1,202 @@
+package ports
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Amund211/flashlight/internal/app"
+	"github.com/Amund211/flashlight/internal/logging"
+	"github.com/Amund211/flashlight/internal/reporting"
+	"github.com/Amund211/flashlight/internal/strutils"
+)
+
+type prestigeForecastResponse struct {
+	Success      bool                       `json:"success"`
+	UUID         string                     `json:"uuid"`
+	Current      prestigeForecastCurrent    `json:"current"`
+	Rates        prestigeForecastRates      `json:"rates"`
+	NextStar     prestigeForecastMilestone  `json:"next_star"`
+	NextPrestige prestigeForecastMilestone  `json:"next_prestige"`
+	Target       *prestigeForecastMilestone `json:"target,omitempty"`
+}
+
+type prestigeForecastCurrent struct {
+	Experience int64     `json:"experience"`
+	Stars      float64   `json:"stars"`
+	QueriedAt  time.Time `json:"queried_at"`
+}
+
+type prestigeForecastRates struct {
+	DailyExperience          []prestigeForecastDay `json:"daily_experience"`
+	MeanPerDay               float64               `json:"mean_per_day"`
+	StdDevPerDay             float64               `json:"std_dev_per_day"`
+	Sessions                 int                   `json:"sessions"`
+	SessionHours             float64               `json:"session_hours"`
+	SessionExperience        int64                 `json:"session_experience"`
+	ExperiencePerSessionHour float64               `json:"experience_per_session_hour"`
+}
+
+type prestigeForecastDay struct {
+	Day        string `json:"day"`
+	Experience int64  `json:"experience"`
+}
+
+type prestigeForecastMilestone struct {
+	Stars               int        `json:"stars"`
+	Experience          int64      `json:"experience"`
+	ExperienceRemaining int64      `json:"experience_remaining"`
+	Expected            *time.Time `json:"expected"`
+	Earliest            *time.Time `json:"earliest"`
+	Latest              *time.Time `json:"latest"`
+	PlayHours           *float64   `json:"play_hours"`
+}
+
+func milestoneForecastToResponse(forecast *app.MilestoneForecast) prestigeForecastMilestone {
+	response := prestigeForecastMilestone{
+		Stars:               forecast.Stars,
+		Experience:          forecast.Experience,
+		ExperienceRemaining: forecast.Remaining,
+		Expected:            forecast.Expected,
+		Earliest:            forecast.Earliest,
+		Latest:              forecast.Latest,
+	}
+	if forecast.PlayTime != nil {
+		response.PlayHours = new(forecast.PlayTime.Hours())
+	}
+	return response
+}
+
+func prestigeForecastToResponse(uuid string, forecast *app.PrestigeForecast) prestigeForecastResponse {
+	days := make([]prestigeForecastDay, 0, len(forecast.Rates.DailyExperience))
+	for _, daily := range forecast.Rates.DailyExperience {
+		days = append(days, prestigeForecastDay{
+			Day:        daily.Day.Format(time.DateOnly),
+			Experience: daily.Experience,
+		})
+	}
+
+	response := prestigeForecastResponse{
+		Success: true,
+		UUID:    uuid,
+		Current: prestigeForecastCurrent{
+			Experience: forecast.Current.Experience,
+			Stars:      forecast.Current.Stars(),
+			QueriedAt:  forecast.Current.QueriedAt,
+		},
+		Rates: prestigeForecastRates{
+			DailyExperience:          days,
+			MeanPerDay:               forecast.Rates.MeanPerDay,
+			StdDevPerDay:             forecast.Rates.StdDevPerDay,
+			Sessions:                 forecast.Rates.Sessions,
+			SessionHours:             forecast.Rates.SessionDuration.Hours(),
+			SessionExperience:        forecast.Rates.SessionExperience,
+			ExperiencePerSessionHour: forecast.Rates.ExperiencePerSessionHour,
+		},
+		NextStar:     milestoneForecastToResponse(&forecast.NextStar),
+		NextPrestige: milestoneForecastToResponse(&forecast.NextPrestige),
+	}
+	if forecast.Target != nil {
+		response.Target = new(milestoneForecastToResponse(forecast.Target))
+	}
+	return response
+}
+
+// prestigeForecastMaxAge matches the player cache: the rates and the
+// expected times only move when a new snapshot is stored.
+const prestigeForecastMaxAge = 1 * time.Minute
+
+func MakeGetPrestigeForecastHandler(
+	forecastPrestige app.ForecastPrestige,
+	registerUserVisit app.RegisterUserVisit,
+	allowedOrigins *DomainSuffixes,
+	rootLogger *slog.Logger,
+	sentryMiddleware func(http.HandlerFunc) http.HandlerFunc,
+	blocklistConfig BlocklistConfig,
+) (http.HandlerFunc, func()) {
+	middleware, stop := mustBuildRouteMiddleware(
+		RouteSpec{
+			Name:           "prestige-forecast",
+			AllowedOrigins: allowedOrigins,
+			// Loads a month of stats per request
+			RateLimits: []RateLimit{
+				IPRateLimit(2, 120),
+				// NOTE: Rate limiting based on user controlled value — this handler
+				//       mounts no bearer middleware, so there is no verified identity
+				//       for UserIDKeyFunc to prefer
+				IdentityRateLimit(0.5, 30),
+			},
+			RegisterUserVisit: registerUserVisit,
+		},
+		rootLogger,
+		sentryMiddleware,
+		blocklistConfig,
+	)
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		rawUUID := r.PathValue("uuid")
+		rawTarget := r.URL.Query().Get("target")
+
+		logging.FromContext(ctx).InfoContext(ctx, "Handling prestige forecast request",
+			slog.String("uuid", rawUUID),
+			slog.String("target", rawTarget),
+		)
+
+		ctx = logging.AddMetaToContext(ctx,
+			slog.String("uuid", rawUUID),
+		)
+
+		ctx = reporting.AddExtrasToContext(ctx,
+			map[string]string{
+				"rawUUID": rawUUID,
+				"target":  rawTarget,
+			},
+		)
+
+		uuid, err := strutils.NormalizeUUID(rawUUID)
+		if err != nil {
+			writeErrorResponse(ctx, w, badRequestError("Invalid UUID"))
+			return
+		}
+
+		ctx = reporting.AddExtrasToContext(ctx, map[string]string{
+			"uuid": uuid,
+		})
+		ctx = logging.AddMetaToContext(ctx, slog.String("normalizedUUID", uuid))
+
+		var targetStars *int
+		if rawTarget != "" {
+			target, err := strconv.Atoi(rawTarget)
+			if err != nil || target < 1 || target > app.MaxForecastTargetStars {
+				writeErrorResponse(ctx, w, badRequestError(fmt.Sprintf("target must be an integer between 1 and %d", app.MaxForecastTargetStars)))
+				return
+			}
+			targetStars = &target
+		}
+
+		forecast, err := forecastPrestige(ctx, uuid, targetStars)
+		if err != nil {
+			// NOTE: ForecastPrestige implementations handle their own error reporting
+			writeErrorResponse(ctx, w, apiErrorFromDomain(err, "Failed to forecast prestige"))
+			return
+		}
+
+		marshalled, err := json.Marshal(prestigeForecastToResponse(uuid, &forecast))
+		if err != nil {
+			reporting.Report(ctx, fmt.Errorf("failed to marshal prestige forecast response: %w", err))
+			writeErrorResponse(ctx, w, newAPIError(http.StatusInternalServerError, errorCodeInternal, "Failed to marshal response"))
+			return
+		}
+
+		logging.FromContext(ctx).InfoContext(ctx, "Returning prestige forecast")
+
+		writeConditionalJSON(ctx, w, r, marshalled, cacheControlFor(prestigeForecastMaxAge))
+	}
+
+	return middleware(handler), stop
+}
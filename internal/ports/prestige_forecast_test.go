@@ -0,0 +1,227 @@
+package ports_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Amund211/flashlight/internal/app"
+	"github.com/Amund211/flashlight/internal/domain"
+	"github.com/Amund211/flashlight/internal/domaintest"
+	"github.com/Amund211/flashlight/internal/ports"
+)
+
+func TestGetPrestigeForecastHandler(t *testing.T) {
+	t.Parallel()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	sentryMiddleware := func(next http.HandlerFunc) http.HandlerFunc { return next }
+	allowedOrigins, err := ports.NewDomainSuffixes("example.com", "test.com")
+	require.NoError(t, err)
+
+	playerUUID := "550e8400-e29b-41d4-a716-446655440000"
+	queriedAt := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+	expected := queriedAt.Add(36 * time.Hour)
+	earliest := queriedAt.Add(24 * time.Hour)
+
+	forecast := app.PrestigeForecast{
+		Current: domaintest.NewPlayerBuilder(playerUUID).WithExperience(487_000).Build(queriedAt),
+		Rates: app.ExperienceRates{
+			DailyExperience: []app.DailyExperience{
+				{Day: time.Date(2024, 6, 14, 0, 0, 0, 0, time.UTC), Experience: 2000},
+				{Day: time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC), Experience: 0},
+			},
+			MeanPerDay:               1000,
+			StdDevPerDay:             1414.2,
+			Sessions:                 1,
+			SessionDuration:          30 * time.Minute,
+			SessionExperience:        2000,
+			ExperiencePerSessionHour: 4000,
+		},
+		NextStar: app.MilestoneForecast{
+			Stars:      100,
+			Experience: 487_000,
+			Remaining:  0,
+			Expected:   &queriedAt,
+			Earliest:   &queriedAt,
+			Latest:     &queriedAt,
+			PlayTime:   new(time.Duration(0)),
+		},
+		NextPrestige: app.MilestoneForecast{
+			Stars:      100,
+			Experience: 487_000,
+			Remaining:  0,
+			Expected:   &queriedAt,
+			Earliest:   &queriedAt,
+			Latest:     &queriedAt,
+			PlayTime:   new(time.Duration(0)),
+		},
+		Target: &app.MilestoneForecast{
+			Stars:      101,
+			Experience: 487_500,
+			Remaining:  500,
+			Expected:   &expected,
+			Earliest:   &earliest,
+			PlayTime:   new(7*time.Minute + 30*time.Second),
+		},
+	}
+
+	makeHandler := func(forecastPrestige app.ForecastPrestige) http.HandlerFunc {
+		stubRegisterUserVisit := func(ctx context.Context, userID string, ipHash string, userAgent string) (domain.User, error) {
+			return domain.User{}, nil
+		}
+		handler, stop := ports.MakeGetPrestigeForecastHandler(forecastPrestige, stubRegisterUserVisit, allowedOrigins, logger, sentryMiddleware, emptyBlocklistConfig)
+		t.Cleanup(stop)
+		return handler
+	}
+
+	makeRequest := func(uuid string, query string) *http.Request {
+		req := httptest.NewRequestWithContext(t.Context(), "GET", "/v1/prestiges/"+uuid+"/forecast"+query, nil)
+		req.SetPathValue("uuid", uuid)
+		return req
+	}
+
+	t.Run("Successful request", func(t *testing.T) {
+		t.Parallel()
+
+		handler := makeHandler(func(ctx context.Context, uuid string, targetStars *int) (app.PrestigeForecast, error) {
+			require.Equal(t, playerUUID, uuid)
+			require.NotNil(t, targetStars)
+			require.Equal(t, 101, *targetStars)
+			return forecast, nil
+		})
+
+		w := httptest.NewRecorder()
+		handler(w, makeRequest("550e8400e29b41d4a716446655440000", "?target=101"))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		requireOpenAPIResponse(t, "GET /v1/prestiges/{uuid}/forecast", w)
+		require.JSONEq(t, `
+		{
+			"success": true,
+			"uuid": "550e8400-e29b-41d4-a716-446655440000",
+			"current": {"experience": 487000, "stars": 100, "queried_at": "2024-06-15T12:00:00Z"},
+			"rates": {
+				"daily_experience": [
+					{"day": "2024-06-14", "experience": 2000},
+					{"day": "2024-06-15", "experience": 0}
+				],
+				"mean_per_day": 1000,
+				"std_dev_per_day": 1414.2,
+				"sessions": 1,
+				"session_hours": 0.5,
+				"session_experience": 2000,
+				"experience_per_session_hour": 4000
+			},
+			"next_star": {
+				"stars": 100, "experience": 487000, "experience_remaining": 0,
+				"expected": "2024-06-15T12:00:00Z", "earliest": "2024-06-15T12:00:00Z", "latest": "2024-06-15T12:00:00Z",
+				"play_hours": 0
+			},
+			"next_prestige": {
+				"stars": 100, "experience": 487000, "experience_remaining": 0,
+				"expected": "2024-06-15T12:00:00Z", "earliest": "2024-06-15T12:00:00Z", "latest": "2024-06-15T12:00:00Z",
+				"play_hours": 0
+			},
+			"target": {
+				"stars": 101, "experience": 487500, "experience_remaining": 500,
+				"expected": "2024-06-17T00:00:00Z", "earliest": "2024-06-16T12:00:00Z", "latest": null,
+				"play_hours": 0.125
+			}
+		}`, w.Body.String())
+	})
+
+	t.Run("Without target", func(t *testing.T) {
+		t.Parallel()
+
+		handler := makeHandler(func(ctx context.Context, uuid string, targetStars *int) (app.PrestigeForecast, error) {
+			require.Nil(t, targetStars)
+			withoutTarget := forecast
+			withoutTarget.Target = nil
+			return withoutTarget, nil
+		})
+
+		w := httptest.NewRecorder()
+		handler(w, makeRequest(playerUUID, ""))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		requireOpenAPIResponse(t, "GET /v1/prestiges/{uuid}/forecast", w)
+		require.NotContains(t, w.Body.String(), `"target"`)
+	})
+
+	t.Run("Conditional get", func(t *testing.T) {
+		t.Parallel()
+
+		handler := makeHandler(func(ctx context.Context, uuid string, targetStars *int) (app.PrestigeForecast, error) {
+			return forecast, nil
+		})
+
+		requireConditionalGET(t, "GET /v1/prestiges/{uuid}/forecast", handler, func() *http.Request {
+			return makeRequest(playerUUID, "?target=101")
+		}, "private, max-age=60")
+	})
+
+	t.Run("Bad requests", func(t *testing.T) {
+		t.Parallel()
+
+		for name, tc := range map[string]struct {
+			uuid  string
+			query string
+		}{
+			"invalid uuid":       {uuid: "invalid-uuid"},
+			"non-numeric target": {uuid: playerUUID, query: "?target=abc"},
+			"target too low":     {uuid: playerUUID, query: "?target=0"},
+			"target too high":    {uuid: playerUUID, query: "?target=10001"},
+		} {
+			t.Run(name, func(t *testing.T) {
+				t.Parallel()
+
+				handler := makeHandler(func(ctx context.Context, uuid string, targetStars *int) (app.PrestigeForecast, error) {
+					t.Helper()
+					require.False(t, true, "ForecastPrestige should not have been called")
+					return app.PrestigeForecast{}, nil
+				})
+
+				w := httptest.NewRecorder()
+				handler(w, makeRequest(tc.uuid, tc.query))
+
+				require.Equal(t, http.StatusBadRequest, w.Code)
+				requireOpenAPIResponse(t, "GET /v1/prestiges/{uuid}/forecast", w)
+			})
+		}
+	})
+
+	t.Run("App errors", func(t *testing.T) {
+		t.Parallel()
+
+		for name, tc := range map[string]struct {
+			err  error
+			code int
+		}{
+			"internal":    {err: errors.New("db down"), code: http.StatusInternalServerError},
+			"not found":   {err: domain.ErrPlayerNotFound, code: http.StatusNotFound},
+			"unavailable": {err: domain.ErrTemporarilyUnavailable, code: http.StatusServiceUnavailable},
+		} {
+			t.Run(name, func(t *testing.T) {
+				t.Parallel()
+
+				handler := makeHandler(func(ctx context.Context, uuid string, targetStars *int) (app.PrestigeForecast, error) {
+					return app.PrestigeForecast{}, tc.err
+				})
+
+				w := httptest.NewRecorder()
+				handler(w, makeRequest(playerUUID, ""))
+
+				require.Equal(t, tc.code, w.Code)
+				requireOpenAPIResponse(t, "GET /v1/prestiges/{uuid}/forecast", w)
+			})
+		}
+	})
+}
@@ -11,7 +11,6 @@ import (
 	"github.com/Amund211/flashlight/internal/app"
 	"github.com/Amund211/flashlight/internal/domain"
 	"github.com/Amund211/flashlight/internal/logging"
-	"github.com/Amund211/flashlight/internal/ratelimiting"
 	"github.com/Amund211/flashlight/internal/reporting"
 	"github.com/Amund211/flashlight/internal/strutils"
 )
@@ -20,7 +19,6 @@ type prestigeResponse struct {
 	Success   bool                  `json:"success"`
 	UUID      string                `json:"uuid,omitempty"`
 	Prestiges []prestigeAchievement `json:"prestiges"`
-	Cause     string                `json:"cause,omitempty"`
 }
 
 type prestigeAchievement struct {
@@ -34,6 +32,10 @@ type prestigeAchievementStats struct {
 	QueriedAt  time.Time `json:"queried_at"`
 }
 
+// prestigesMaxAge matches the player cache: a new prestige can't show up
+// before a new snapshot does.
+const prestigesMaxAge = 1 * time.Minute
+
 func MakeGetPrestigesHandler(
 	findMilestoneAchievements app.FindMilestoneAchievements,
 	registerUserVisit app.RegisterUserVisit,
@@ -42,44 +44,22 @@ func MakeGetPrestigesHandler(
 	sentryMiddleware func(http.HandlerFunc) http.HandlerFunc,
 	blocklistConfig BlocklistConfig,
 ) (http.HandlerFunc, func()) {
-	ipLimiter, stopIPLimiter := ratelimiting.NewTokenBucketRateLimiter(
-		ratelimiting.RefillPerSecond(4),
-		ratelimiting.BurstSize(240),
-	)
-	ipRateLimiter := ratelimiting.NewRequestBasedRateLimiter(
-		ipLimiter,
-		IPHashKeyFunc,
-	)
-	userIDLimiter, stopUserIDLimiter := ratelimiting.NewTokenBucketRateLimiter(
-		ratelimiting.RefillPerSecond(1),
-		ratelimiting.BurstSize(60),
-	)
-	userIDRateLimiter := ratelimiting.NewRequestBasedRateLimiter(
-		// NOTE: Rate limiting based on user controlled value — this handler
-		//       mounts no bearer middleware, so there is no verified identity
-		//       for UserIDKeyFunc to prefer
-		userIDLimiter,
-		UserIDKeyFunc,
-	)
-
-	makeOnLimitExceeded := func(rateLimiter ratelimiting.RequestRateLimiter) http.HandlerFunc {
-		return func(w http.ResponseWriter, r *http.Request) {
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusTooManyRequests)
-			w.Write([]byte(`{"success":false,"cause":"Rate limit exceeded"}`))
-		}
-	}
-
-	middleware := ComposeMiddlewares(
-		NewRequestLoggerMiddleware(rootLogger),
+	middleware, stop := mustBuildRouteMiddleware(
+		RouteSpec{
+			Name:           "prestiges",
+			AllowedOrigins: allowedOrigins,
+			RateLimits: []RateLimit{
+				IPRateLimit(4, 240),
+				// NOTE: Rate limiting based on user controlled value — this handler
+				//       mounts no bearer middleware, so there is no verified identity
+				//       for UserIDKeyFunc to prefer
+				IdentityRateLimit(1, 60),
+			},
+			RegisterUserVisit: registerUserVisit,
+		},
+		rootLogger,
 		sentryMiddleware,
-		BuildBlocklistMiddleware(blocklistConfig),
-		buildMetricsMiddleware("prestiges"),
-		NewReportingMetaMiddleware("prestiges"),
-		BuildCORSMiddleware(allowedOrigins),
-		NewRateLimitMiddleware(ipRateLimiter, makeOnLimitExceeded(ipRateLimiter)),
-		NewRateLimitMiddleware(userIDRateLimiter, makeOnLimitExceeded(userIDRateLimiter)),
-		BuildRegisterUserVisitMiddleware(registerUserVisit),
+		blocklistConfig,
 	)
 
 	handler := func(w http.ResponseWriter, r *http.Request) {
@@ -103,10 +83,7 @@ func MakeGetPrestigesHandler(
 
 		uuid, err := strutils.NormalizeUUID(rawUUID)
 		if err != nil {
-			statusCode := http.StatusBadRequest
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(statusCode)
-			w.Write([]byte(`{"success":false,"cause":"Invalid UUID"}`))
+			writeErrorResponse(ctx, w, badRequestError("Invalid UUID"))
 			return
 		}
 
@@ -124,10 +101,7 @@ func MakeGetPrestigesHandler(
 		achievements, err := findMilestoneAchievements(ctx, uuid, domain.GamemodeOverall, domain.StatStars, milestones)
 		if err != nil {
 			// NOTE: FindMilestoneAchievements implementations handle their own error reporting
-			statusCode := http.StatusInternalServerError
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(statusCode)
-			w.Write([]byte(`{"success":false,"cause":"Failed to get prestiges"}`))
+			writeErrorResponse(ctx, w, apiErrorFromDomain(err, "Failed to get prestiges"))
 			return
 		}
 
@@ -160,23 +134,13 @@ func MakeGetPrestigesHandler(
 			reporting.Report(ctx, fmt.Errorf("failed to marshal prestiges response: %w", err), map[string]string{
 				"length": strconv.Itoa(len(responseAchievements)),
 			})
-			statusCode := http.StatusInternalServerError
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(statusCode)
-			w.Write([]byte(`{"success":false,"cause":"Failed to marshal response"}`))
+			writeErrorResponse(ctx, w, newAPIError(http.StatusInternalServerError, errorCodeInternal, "Failed to marshal response"))
 			return
 		}
 
 		logging.FromContext(ctx).InfoContext(ctx, "Returning prestiges data", "achievements", len(achievements))
 
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		w.Write(marshalled)
-	}
-
-	stop := func() {
-		stopIPLimiter()
-		stopUserIDLimiter()
+		writeConditionalJSON(ctx, w, r, marshalled, cacheControlFor(prestigesMaxAge))
 	}
 
 	return middleware(handler), stop
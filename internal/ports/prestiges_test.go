@@ -89,6 +89,7 @@ func TestGetPrestigesHandler(t *testing.T) {
 		handler(w, req)
 
 		require.Equal(t, http.StatusOK, w.Code)
+		requireOpenAPIResponse(t, "GET /v1/prestiges/{uuid}", w)
 		require.Equal(t, "application/json", w.Header().Get("Content-Type"))
 
 		require.JSONEq(t, `
@@ -108,6 +109,39 @@ func TestGetPrestigesHandler(t *testing.T) {
 		}`, w.Body.String())
 	})
 
+	t.Run("Conditional get", func(t *testing.T) {
+		t.Parallel()
+
+		rawPlayerUUID := "550e8400e29b41d4a716446655440000"
+		playerUUID, err := strutils.NormalizeUUID(rawPlayerUUID)
+		require.NoError(t, err)
+
+		findMilestoneAchievements := makeFindMilestoneAchievements(
+			playerUUID,
+			[]domain.MilestoneAchievement{
+				{
+					Milestone: 100,
+					After: &domain.MilestoneAchievementStats{
+						Player: domaintest.NewPlayerBuilder(playerUUID).WithExperience(487_550).Build(time.Date(2021, 1, 1, 12, 0, 0, 0, time.UTC)),
+						Value:  101,
+					},
+				},
+			},
+			nil,
+		)
+
+		stubRegisterUserVisit := func(ctx context.Context, userID string, ipHash string, userAgent string) (domain.User, error) {
+			return domain.User{}, nil
+		}
+
+		handler, stop := ports.MakeGetPrestigesHandler(findMilestoneAchievements, stubRegisterUserVisit, allowedOrigins, logger, sentryMiddleware, emptyBlocklistConfig)
+		t.Cleanup(stop)
+
+		requireConditionalGET(t, "GET /v1/prestiges/{uuid}", handler, func() *http.Request {
+			return makeRequest(rawPlayerUUID)
+		}, "private, max-age=60")
+	})
+
 	t.Run("Invalid UUID", func(t *testing.T) {
 		t.Parallel()
 
@@ -124,8 +158,9 @@ func TestGetPrestigesHandler(t *testing.T) {
 		handler(w, req)
 
 		require.Equal(t, http.StatusBadRequest, w.Code)
+		requireOpenAPIResponse(t, "GET /v1/prestiges/{uuid}", w)
 		require.Equal(t, "application/json", w.Header().Get("Content-Type"))
-		require.JSONEq(t, `{"success":false,"cause":"Invalid UUID"}`, w.Body.String())
+		require.JSONEq(t, `{"success":false,"code":"bad_request","message":"Invalid UUID","cause":"Invalid UUID","retryable":false}`, w.Body.String())
 	})
 
 	t.Run("Missing UUID", func(t *testing.T) {
@@ -144,7 +179,8 @@ func TestGetPrestigesHandler(t *testing.T) {
 		handler(w, req)
 
 		require.Equal(t, http.StatusBadRequest, w.Code)
+		requireOpenAPIResponse(t, "GET /v1/prestiges/{uuid}", w)
 		require.Equal(t, "application/json", w.Header().Get("Content-Type"))
-		require.JSONEq(t, `{"success":false,"cause":"Invalid UUID"}`, w.Body.String())
+		require.JSONEq(t, `{"success":false,"code":"bad_request","message":"Invalid UUID","cause":"Invalid UUID","retryable":false}`, w.Body.String())
 	})
 }
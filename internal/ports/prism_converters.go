@@ -19,6 +19,12 @@ type hypixelAPIPlayer struct {
 	LastLogin   *int64           `json:"lastLogin,omitempty"`
 	LastLogout  *int64           `json:"lastLogout,omitempty"`
 	Stats       *hypixelAPIStats `json:"stats,omitempty"`
+
+	Rank               *string  `json:"rank,omitempty"`
+	NewPackageRank     *string  `json:"newPackageRank,omitempty"`
+	MonthlyPackageRank *string  `json:"monthlyPackageRank,omitempty"`
+	RankPlusColor      *string  `json:"rankPlusColor,omitempty"`
+	NetworkExp         *float64 `json:"networkExp,omitempty"`
 }
 
 type hypixelAPIStats struct {
@@ -183,17 +189,26 @@ func playerToPrismPlayerDataResponse(player *domain.PlayerPIT) *hypixelAPIRespon
 		Fourv4Deaths:      player.Fourv4.Deaths,
 	}
 
+	apiPlayer := &hypixelAPIPlayer{
+		UUID:        &player.UUID,
+		Displayname: player.Displayname,
+		LastLogin:   lastLogin,
+		LastLogout:  lastLogout,
+		Stats: &hypixelAPIStats{
+			Bedwars: &bedwarsStats,
+		},
+		NetworkExp: player.NetworkExperience,
+	}
+	if player.Rank != nil {
+		apiPlayer.Rank = player.Rank.StaffRank
+		apiPlayer.NewPackageRank = player.Rank.PackageRank
+		apiPlayer.MonthlyPackageRank = player.Rank.MonthlyPackageRank
+		apiPlayer.RankPlusColor = player.Rank.RankPlusColor
+	}
+
 	return &hypixelAPIResponse{
 		Success: true,
-		Player: &hypixelAPIPlayer{
-			UUID:        &player.UUID,
-			Displayname: player.Displayname,
-			LastLogin:   lastLogin,
-			LastLogout:  lastLogout,
-			Stats: &hypixelAPIStats{
-				Bedwars: &bedwarsStats,
-			},
-		},
+		Player:  apiPlayer,
 	}
 }
 
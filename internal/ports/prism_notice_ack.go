@@ -0,0 +1,63 @@
+package ports
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/Amund211/flashlight/internal/app"
+	"github.com/Amund211/flashlight/internal/domain"
+	"github.com/Amund211/flashlight/internal/logging"
+)
+
+func MakeAcknowledgePrismNoticeHandler(
+	acknowledgePrismNotice app.AcknowledgePrismNotice,
+	rootLogger *slog.Logger,
+	sentryMiddleware func(http.HandlerFunc) http.HandlerFunc,
+	bearerAuthMiddleware func(http.HandlerFunc) http.HandlerFunc,
+	blocklistConfig BlocklistConfig,
+) (http.HandlerFunc, func()) {
+	middleware, stop := mustBuildRouteMiddleware(
+		RouteSpec{
+			Name:       "prism-notices-ack",
+			BearerAuth: bearerAuthMiddleware,
+			RateLimits: []RateLimit{
+				// One request per dismissal, so well below prism-notices
+				IPRateLimit(2, 60),
+				IdentityRateLimit(1, 30),
+			},
+		},
+		rootLogger,
+		sentryMiddleware,
+		blocklistConfig,
+	)
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		userID := GetUserID(r)
+		noticeID := r.PathValue("id")
+
+		ctx = logging.AddMetaToContext(ctx, slog.String("noticeID", noticeID))
+
+		if userID == "" {
+			writeErrorResponse(ctx, w, badRequestError("Missing user id"))
+			return
+		}
+
+		err := acknowledgePrismNotice(ctx, string(userID), noticeID)
+		if err != nil {
+			if !errors.Is(err, domain.ErrPrismNoticeNotFound) {
+				// NOTE: The repository reports its own errors
+				logging.FromContext(ctx).ErrorContext(ctx, "Failed to acknowledge prism notice", "error", err)
+			}
+			writeErrorResponse(ctx, w, apiErrorFromDomain(err, "Failed to acknowledge notice"))
+			return
+		}
+
+		logging.FromContext(ctx).InfoContext(ctx, "Acknowledged prism notice")
+		w.WriteHeader(http.StatusNoContent)
+	}
+
+	return middleware(handler), stop
+}
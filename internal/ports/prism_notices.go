@@ -8,7 +8,6 @@ import (
 
 	"github.com/Amund211/flashlight/internal/app"
 	"github.com/Amund211/flashlight/internal/logging"
-	"github.com/Amund211/flashlight/internal/ratelimiting"
 	"github.com/Amund211/flashlight/internal/reporting"
 )
 
@@ -26,6 +25,9 @@ const (
 )
 
 type prismNotice struct {
+	// ID is what prism acknowledges the notice with. Missing on the
+	// version update notice, which can't be acknowledged.
+	ID              string   `json:"id,omitempty"`
 	Message         string   `json:"message"`
 	URL             string   `json:"url,omitempty"`
 	Severity        severity `json:"severity"`
@@ -59,46 +61,19 @@ func MakePrismNoticesHandler(
 	bearerAuthMiddleware func(http.HandlerFunc) http.HandlerFunc,
 	blocklistConfig BlocklistConfig,
 ) (http.HandlerFunc, func()) {
-	ipLimiter, stopIPLimiter := ratelimiting.NewTokenBucketRateLimiter(
-		ratelimiting.RefillPerSecond(8),
-		ratelimiting.BurstSize(480),
-	)
-	ipRateLimiter := ratelimiting.NewRequestBasedRateLimiter(
-		ipLimiter,
-		IPHashKeyFunc,
-	)
-	userIDLimiter, stopUserIDLimiter := ratelimiting.NewTokenBucketRateLimiter(
-		ratelimiting.RefillPerSecond(2),
-		ratelimiting.BurstSize(120),
-	)
-	userIDRateLimiter := ratelimiting.NewRequestBasedRateLimiter(
-		// NOTE: Verified identity when there is one, user controlled value otherwise
-		userIDLimiter,
-		UserIDKeyFunc,
-	)
-
-	makeOnLimitExceeded := func(rateLimiter ratelimiting.RequestRateLimiter) http.HandlerFunc {
-		return func(w http.ResponseWriter, r *http.Request) {
-			ctx := r.Context()
-
-			statusCode := http.StatusTooManyRequests
-
-			logging.FromContext(ctx).InfoContext(ctx, "Rate limit exceeded", "statusCode", statusCode, "reason", "ratelimit exceeded", "key", rateLimiter.KeyFor(r))
-
-			http.Error(w, "Rate limit exceeded", statusCode)
-		}
-	}
-
-	middleware := ComposeMiddlewares(
-		NewRequestLoggerMiddleware(rootLogger),
+	middleware, stop := mustBuildRouteMiddleware(
+		RouteSpec{
+			Name:       "prism-notices",
+			BearerAuth: bearerAuthMiddleware,
+			RateLimits: []RateLimit{
+				IPRateLimit(8, 480),
+				IdentityRateLimit(2, 120),
+			},
+			RegisterUserVisit: registerUserVisit,
+		},
+		rootLogger,
 		sentryMiddleware,
-		BuildBlocklistMiddleware(blocklistConfig),
-		buildMetricsMiddleware("prism-notices"),
-		NewReportingMetaMiddleware("prism-notices"),
-		NewRateLimitMiddleware(ipRateLimiter, makeOnLimitExceeded(ipRateLimiter)),
-		bearerAuthMiddleware,
-		NewRateLimitMiddleware(userIDRateLimiter, makeOnLimitExceeded(userIDRateLimiter)),
-		BuildRegisterUserVisitMiddleware(registerUserVisit),
+		blocklistConfig,
 	)
 
 	handler := func(w http.ResponseWriter, r *http.Request) {
@@ -145,17 +120,18 @@ func MakePrismNoticesHandler(
 			updateSelection = app.UpdateSelectionAll
 		}
 
-		appNotices := getPrismNotices(ctx, string(userID), prismVersion, updateSelection)
+		appNotices := getPrismNotices(ctx, string(userID), prismVersion, GetClient(r).Type, updateSelection)
 		wireNotices := make([]prismNotice, 0, len(appNotices))
 		for _, n := range appNotices {
 			wireSeverity, err := severityFromApp(n.Severity)
 			if err != nil {
 				logging.FromContext(ctx).ErrorContext(ctx, "Failed to convert severity for wire format", "error", err)
 				reporting.Report(ctx, fmt.Errorf("failed to convert severity: %w", err))
-				http.Error(w, "Internal server error", http.StatusInternalServerError)
+				writeErrorResponse(ctx, w, internalError())
 				return
 			}
 			wireNotices = append(wireNotices, prismNotice{
+				ID:              n.ID,
 				Message:         n.Message,
 				URL:             n.URL,
 				Severity:        wireSeverity,
@@ -167,7 +143,7 @@ func MakePrismNoticesHandler(
 		if err != nil {
 			logging.FromContext(ctx).ErrorContext(ctx, "Failed to marshal notices", "error", err)
 			reporting.Report(ctx, fmt.Errorf("failed to marshal notices: %w", err))
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			writeErrorResponse(ctx, w, internalError())
 			return
 		}
 
@@ -177,15 +153,10 @@ func MakePrismNoticesHandler(
 			logging.FromContext(ctx).ErrorContext(ctx, "Failed to write response", "error", err)
 			reporting.Report(ctx, fmt.Errorf("failed to write notices response: %w", err))
 
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			writeErrorResponse(ctx, w, internalError())
 			return
 		}
 	}
 
-	stop := func() {
-		stopIPLimiter()
-		stopUserIDLimiter()
-	}
-
 	return middleware(handler), stop
 }
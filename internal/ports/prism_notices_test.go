@@ -2,6 +2,8 @@ package ports_test
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
@@ -108,11 +110,12 @@ func TestPrismNoticesHandlerPassesArgsToApp(t *testing.T) {
 			t.Parallel()
 
 			called := false
-			getPrismNotices := func(ctx context.Context, userID, prismVersion string, selection app.UpdateSelection) []app.PrismNotice {
+			getPrismNotices := func(ctx context.Context, userID, prismVersion, clientType string, selection app.UpdateSelection) []app.PrismNotice {
 				require.False(t, called, "getPrismNotices should be called exactly once")
 				called = true
 				require.Equal(t, tc.userID, userID)
 				require.Equal(t, tc.wantPrismVersion, prismVersion)
+				require.Equal(t, "missing", clientType)
 				require.Equal(t, tc.wantSelection, selection)
 				return []app.PrismNotice{}
 			}
@@ -132,6 +135,7 @@ func TestPrismNoticesHandlerPassesArgsToApp(t *testing.T) {
 			handler.ServeHTTP(w, req)
 
 			require.Equal(t, http.StatusOK, w.Code)
+			requireOpenAPIResponse(t, "GET /v1/prism-notices", w)
 			require.True(t, called)
 		})
 	}
@@ -154,6 +158,7 @@ func TestPrismNoticesHandlerSerializesAppNotices(t *testing.T) {
 			name: "single info notice with url and duration",
 			notices: []app.PrismNotice{
 				{
+					ID:              "3d1e9b62-8c4a-4f0b-b7d5-2a9e6c1f4b83",
 					Message:         "Click here to view your Prism Wrapped 2025",
 					URL:             "https://prismoverlay.com/wrapped",
 					Severity:        app.SeverityInfo,
@@ -162,6 +167,7 @@ func TestPrismNoticesHandlerSerializesAppNotices(t *testing.T) {
 			},
 			want: `{"notices":[
 				{
+					"id":"3d1e9b62-8c4a-4f0b-b7d5-2a9e6c1f4b83",
 					"message":"Click here to view your Prism Wrapped 2025",
 					"url":"https://prismoverlay.com/wrapped",
 					"severity":"info",
@@ -253,7 +259,7 @@ func TestPrismNoticesHandlerSerializesAppNotices(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			t.Parallel()
 
-			getPrismNotices := func(ctx context.Context, userID, prismVersion string, selection app.UpdateSelection) []app.PrismNotice {
+			getPrismNotices := func(ctx context.Context, userID, prismVersion, clientType string, selection app.UpdateSelection) []app.PrismNotice {
 				return tc.notices
 			}
 			handler := makePrismNoticesHandler(t, getPrismNotices)
@@ -263,6 +269,7 @@ func TestPrismNoticesHandlerSerializesAppNotices(t *testing.T) {
 			handler.ServeHTTP(w, req)
 
 			require.Equal(t, http.StatusOK, w.Code)
+			requireOpenAPIResponse(t, "GET /v1/prism-notices", w)
 			require.JSONEq(t, tc.want, w.Body.String())
 			require.Equal(t, "application/json", w.Result().Header.Get("Content-Type"))
 		})
@@ -272,7 +279,7 @@ func TestPrismNoticesHandlerSerializesAppNotices(t *testing.T) {
 func TestPrismNoticesHandlerReturns500OnUnknownSeverity(t *testing.T) {
 	t.Parallel()
 
-	getPrismNotices := func(ctx context.Context, userID, prismVersion string, selection app.UpdateSelection) []app.PrismNotice {
+	getPrismNotices := func(ctx context.Context, userID, prismVersion, clientType string, selection app.UpdateSelection) []app.PrismNotice {
 		return []app.PrismNotice{
 			{
 				Message:  "notice with bogus severity",
@@ -287,4 +294,95 @@ func TestPrismNoticesHandlerReturns500OnUnknownSeverity(t *testing.T) {
 	handler.ServeHTTP(w, req)
 
 	require.Equal(t, http.StatusInternalServerError, w.Code)
+	requireOpenAPIResponse(t, "GET /v1/prism-notices", w)
+}
+
+func TestAcknowledgePrismNoticeHandler(t *testing.T) {
+	t.Parallel()
+
+	const noticeID = "3d1e9b62-8c4a-4f0b-b7d5-2a9e6c1f4b83"
+
+	newHandler := func(t *testing.T, acknowledge app.AcknowledgePrismNotice) http.HandlerFunc {
+		t.Helper()
+		handler, stop := ports.MakeAcknowledgePrismNoticeHandler(
+			acknowledge,
+			prismNoticesTestLogger,
+			noopPrismNoticesMiddleware,
+			noopPrismNoticesMiddleware,
+			emptyBlocklistConfig,
+		)
+		t.Cleanup(stop)
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("POST /v1/prism-notices/{id}/ack", handler)
+		return mux.ServeHTTP
+	}
+
+	post := func(t *testing.T, handler http.HandlerFunc, noticeID string, userID string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequestWithContext(t.Context(), http.MethodPost, "/v1/prism-notices/"+noticeID+"/ack", nil)
+		if userID != "" {
+			req.Header.Set("X-User-Id", userID)
+		}
+		w := httptest.NewRecorder()
+		handler(w, req)
+		requireOpenAPIResponse(t, "POST /v1/prism-notices/{id}/ack", w)
+		return w
+	}
+
+	t.Run("acknowledges the notice for the user", func(t *testing.T) {
+		t.Parallel()
+
+		userID := domaintest.NewUUID(t)
+		called := false
+		handler := newHandler(t, func(ctx context.Context, gotUserID string, gotNoticeID string) error {
+			called = true
+			require.Equal(t, userID, gotUserID)
+			require.Equal(t, noticeID, gotNoticeID)
+			return nil
+		})
+
+		w := post(t, handler, noticeID, userID)
+
+		require.Equal(t, http.StatusNoContent, w.Code)
+		require.True(t, called)
+	})
+
+	t.Run("unknown notice", func(t *testing.T) {
+		t.Parallel()
+
+		handler := newHandler(t, func(ctx context.Context, userID string, noticeID string) error {
+			return fmt.Errorf("failed to acknowledge: %w", domain.ErrPrismNoticeNotFound)
+		})
+
+		w := post(t, handler, "unknown", domaintest.NewUUID(t))
+
+		require.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("missing user id", func(t *testing.T) {
+		t.Parallel()
+
+		handler := newHandler(t, func(ctx context.Context, userID string, noticeID string) error {
+			t.Fatal("acknowledge should not be called without a user id")
+			return nil
+		})
+
+		w := post(t, handler, noticeID, "")
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("repository errors", func(t *testing.T) {
+		t.Parallel()
+
+		handler := newHandler(t, func(ctx context.Context, userID string, noticeID string) error {
+			return errors.New("db down")
+		})
+
+		w := post(t, handler, noticeID, domaintest.NewUUID(t))
+
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+		require.NotContains(t, w.Body.String(), "db down")
+	})
 }
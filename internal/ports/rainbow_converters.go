@@ -39,6 +39,74 @@ func gamemodeToRainbowGamemode(g domain.Gamemode) (string, error) {
 	}
 }
 
+func rainbowGamemodeToGamemode(g string) (domain.Gamemode, error) {
+	switch g {
+	case rainbowGamemodeSolo:
+		return domain.GamemodeSolo, nil
+	case rainbowGamemodeDoubles:
+		return domain.GamemodeDoubles, nil
+	case rainbowGamemodeThrees:
+		return domain.GamemodeThrees, nil
+	case rainbowGamemodeFours:
+		return domain.GamemodeFours, nil
+	case rainbowGamemodeFourv4:
+		return domain.GamemodeFourv4, nil
+	case rainbowGamemodeOverall:
+		return domain.GamemodeOverall, nil
+	default:
+		return "", fmt.Errorf("unknown gamemode: %q", g)
+	}
+}
+
+// rainbowDreamModes are the rainbow-format names of the dream modes, used as
+// keys of dreamModes. Kept distinct from domain.DreamMode for the same reason
+// as the gamemode names.
+var rainbowDreamModes = map[domain.DreamMode]string{
+	domain.DreamModeCastle:            "castle",
+	domain.DreamModeRushSolo:          "rushSolo",
+	domain.DreamModeRushDoubles:       "rushDoubles",
+	domain.DreamModeRushFours:         "rushFours",
+	domain.DreamModeUltimateSolo:      "ultimateSolo",
+	domain.DreamModeUltimateDoubles:   "ultimateDoubles",
+	domain.DreamModeUltimateFours:     "ultimateFours",
+	domain.DreamModeLuckyDoubles:      "luckyDoubles",
+	domain.DreamModeLuckyFours:        "luckyFours",
+	domain.DreamModeVoidlessDoubles:   "voidlessDoubles",
+	domain.DreamModeVoidlessFours:     "voidlessFours",
+	domain.DreamModeArmedDoubles:      "armedDoubles",
+	domain.DreamModeArmedFours:        "armedFours",
+	domain.DreamModeSwapDoubles:       "swapDoubles",
+	domain.DreamModeSwapFours:         "swapFours",
+	domain.DreamModeUnderworldDoubles: "underworldDoubles",
+	domain.DreamModeUnderworldFours:   "underworldFours",
+}
+
+func rainbowDreamModeToDreamMode(m string) (domain.DreamMode, error) {
+	for mode, rainbowMode := range rainbowDreamModes {
+		if rainbowMode == m {
+			return mode, nil
+		}
+	}
+	return "", fmt.Errorf("unknown dream mode: %q", m)
+}
+
+// rainbowGames are the rainbow-format names of the games, kept distinct from
+// domain.Game for the same reason as the gamemode names
+var rainbowGames = map[domain.Game]string{
+	domain.GameBedwars: "bedwars",
+	domain.GameSkyWars: "skywars",
+	domain.GameDuels:   "duels",
+}
+
+func rainbowGameToGame(g string) (domain.Game, error) {
+	for game, rainbowGame := range rainbowGames {
+		if rainbowGame == g {
+			return game, nil
+		}
+	}
+	return "", fmt.Errorf("unknown game: %q", g)
+}
+
 // Rainbow-format game outcome names sent on the rainbow JSON responses.
 // Kept distinct from domain.GameOutcome so the JSON contract is fixed
 // regardless of how the domain constants are spelled.
@@ -84,6 +152,63 @@ type rainbowPlayerDataPIT struct {
 	Fours      rainbowStatsPIT `json:"fours"`
 	Fourv4     rainbowStatsPIT `json:"4v4"`
 	Overall    rainbowStatsPIT `json:"overall"`
+	// DreamModes is left out when the player has no dream mode stats
+	DreamModes map[string]rainbowStatsPIT `json:"dreamModes,omitempty"`
+	// Economy is left out when the stats predate it being captured
+	Economy *rainbowEconomyStats `json:"economy,omitempty"`
+	// Rank is left out when the stats predate it being captured
+	Rank *rainbowRank `json:"rank,omitempty"`
+	// NetworkExperience and NetworkLevel are left out when the stats predate
+	// network experience being captured
+	NetworkExperience *float64 `json:"networkExperience,omitempty"`
+	NetworkLevel      *float64 `json:"networkLevel,omitempty"`
+}
+
+// rainbowRank holds the rank as shown in game along with the raw Hypixel
+// fields it was derived from
+type rainbowRank struct {
+	// Display is e.g. MVP++, or empty for players without a rank
+	Display            string  `json:"display"`
+	PlusColor          *string `json:"plusColor,omitempty"`
+	StaffRank          *string `json:"staffRank,omitempty"`
+	PackageRank        *string `json:"packageRank,omitempty"`
+	MonthlyPackageRank *string `json:"monthlyPackageRank,omitempty"`
+}
+
+// rainbowEconomyStats holds the economy stats of a snapshot, or how much they
+// moved in a session
+type rainbowEconomyStats struct {
+	Coins               int `json:"coins"`
+	ChallengesCompleted int `json:"challengesCompleted"`
+	ItemsPurchased      int `json:"itemsPurchased"`
+	IronCollected       int `json:"ironCollected"`
+	GoldCollected       int `json:"goldCollected"`
+	DiamondsCollected   int `json:"diamondsCollected"`
+	EmeraldsCollected   int `json:"emeraldsCollected"`
+}
+
+type rainbowStatsDelta struct {
+	GamesPlayed int `json:"gamesPlayed"`
+	Wins        int `json:"wins"`
+	Losses      int `json:"losses"`
+	BedsBroken  int `json:"bedsBroken"`
+	BedsLost    int `json:"bedsLost"`
+	FinalKills  int `json:"finalKills"`
+	FinalDeaths int `json:"finalDeaths"`
+	Kills       int `json:"kills"`
+	Deaths      int `json:"deaths"`
+}
+
+type rainbowSessionDeltas struct {
+	Experience int64             `json:"experience"`
+	Solo       rainbowStatsDelta `json:"solo"`
+	Doubles    rainbowStatsDelta `json:"doubles"`
+	Threes     rainbowStatsDelta `json:"threes"`
+	Fours      rainbowStatsDelta `json:"fours"`
+	Fourv4     rainbowStatsDelta `json:"4v4"`
+	Overall    rainbowStatsDelta `json:"overall"`
+	// Economy is left out unless both ends of the session have economy stats
+	Economy *rainbowEconomyStats `json:"economy,omitempty"`
 }
 
 type rainbowSession struct {
@@ -91,6 +216,33 @@ type rainbowSession struct {
 	End         rainbowPlayerDataPIT `json:"end"`
 	Consecutive bool                 `json:"consecutive"`
 	Ongoing     bool                 `json:"ongoing"`
+	Gamemode    string               `json:"gamemode"`
+	Deltas      rainbowSessionDeltas `json:"deltas"`
+	// Summary is only included when requested
+	Summary *rainbowSessionSummary `json:"summary,omitempty"`
+}
+
+// rainbowRatios holds the ratios of a gamemode. The deltas they are computed
+// from are in the session's deltas.
+type rainbowRatios struct {
+	FKDR float64 `json:"fkdr"`
+	KDR  float64 `json:"kdr"`
+	WLR  float64 `json:"wlr"`
+	BBLR float64 `json:"bblr"`
+}
+
+type rainbowSessionSummary struct {
+	DurationHours    float64       `json:"durationHours"`
+	ExperienceGained int64         `json:"experienceGained"`
+	StarsGained      float64       `json:"starsGained"`
+	GamesPerHour     float64       `json:"gamesPerHour"`
+	AttributedGames  int           `json:"attributedGames"`
+	Solo             rainbowRatios `json:"solo"`
+	Doubles          rainbowRatios `json:"doubles"`
+	Threes           rainbowRatios `json:"threes"`
+	Fours            rainbowRatios `json:"fours"`
+	Fourv4           rainbowRatios `json:"4v4"`
+	Overall          rainbowRatios `json:"overall"`
 }
 
 func gamemodeStatsPITToRainbowStatsPIT(stats *domain.GamemodeStatsPIT) rainbowStatsPIT {
@@ -109,6 +261,19 @@ func gamemodeStatsPITToRainbowStatsPIT(stats *domain.GamemodeStatsPIT) rainbowSt
 }
 
 func playerToRainbowPlayerDataPIT(player *domain.PlayerPIT) rainbowPlayerDataPIT {
+	var dreamModes map[string]rainbowStatsPIT
+	for mode, stats := range player.DreamModes {
+		rainbowMode, ok := rainbowDreamModes[mode]
+		if !ok {
+			// Not part of the rainbow format yet
+			continue
+		}
+		if dreamModes == nil {
+			dreamModes = make(map[string]rainbowStatsPIT, len(player.DreamModes))
+		}
+		dreamModes[rainbowMode] = gamemodeStatsPITToRainbowStatsPIT(&stats)
+	}
+
 	return rainbowPlayerDataPIT{
 		UUID:       player.UUID,
 		QueriedAt:  player.QueriedAt,
@@ -119,6 +284,123 @@ func playerToRainbowPlayerDataPIT(player *domain.PlayerPIT) rainbowPlayerDataPIT
 		Fours:      gamemodeStatsPITToRainbowStatsPIT(&player.Fours),
 		Fourv4:     gamemodeStatsPITToRainbowStatsPIT(&player.Fourv4),
 		Overall:    gamemodeStatsPITToRainbowStatsPIT(&player.Overall),
+		DreamModes: dreamModes,
+		Economy:    economyStatsPITToRainbowEconomyStats(player.Economy),
+
+		Rank:              rankPITToRainbowRank(player.Rank),
+		NetworkExperience: player.NetworkExperience,
+		NetworkLevel:      networkExperienceToRainbowNetworkLevel(player.NetworkExperience),
+	}
+}
+
+func rankPITToRainbowRank(rank *domain.RankPIT) *rainbowRank {
+	if rank == nil {
+		return nil
+	}
+	return &rainbowRank{
+		Display:            rank.Display(),
+		PlusColor:          rank.RankPlusColor,
+		StaffRank:          rank.StaffRank,
+		PackageRank:        rank.PackageRank,
+		MonthlyPackageRank: rank.MonthlyPackageRank,
+	}
+}
+
+func networkExperienceToRainbowNetworkLevel(experience *float64) *float64 {
+	if experience == nil {
+		return nil
+	}
+	return new(domain.NetworkExperienceToLevel(*experience))
+}
+
+func economyStatsPITToRainbowEconomyStats(economy *domain.EconomyStatsPIT) *rainbowEconomyStats {
+	if economy == nil {
+		return nil
+	}
+	return &rainbowEconomyStats{
+		Coins:               economy.Coins,
+		ChallengesCompleted: economy.ChallengesCompleted,
+		ItemsPurchased:      economy.ItemsPurchased,
+		IronCollected:       economy.IronCollected,
+		GoldCollected:       economy.GoldCollected,
+		DiamondsCollected:   economy.DiamondsCollected,
+		EmeraldsCollected:   economy.EmeraldsCollected,
+	}
+}
+
+func rainbowStatsPITToGamemodeStatsPIT(stats *rainbowStatsPIT) domain.GamemodeStatsPIT {
+	return domain.GamemodeStatsPIT{
+		Winstreak:   stats.Winstreak,
+		GamesPlayed: stats.GamesPlayed,
+		Wins:        stats.Wins,
+		Losses:      stats.Losses,
+		BedsBroken:  stats.BedsBroken,
+		BedsLost:    stats.BedsLost,
+		FinalKills:  stats.FinalKills,
+		FinalDeaths: stats.FinalDeaths,
+		Kills:       stats.Kills,
+		Deaths:      stats.Deaths,
+	}
+}
+
+// rainbowPlayerDataPITToPlayer is the inverse of playerToRainbowPlayerDataPIT.
+// Fields not in the rainbow format are left empty.
+func rainbowPlayerDataPITToPlayer(player *rainbowPlayerDataPIT) domain.PlayerPIT {
+	var dreamModes map[domain.DreamMode]domain.GamemodeStatsPIT
+	for rainbowMode, stats := range player.DreamModes {
+		mode, err := rainbowDreamModeToDreamMode(rainbowMode)
+		if err != nil {
+			// Written by a newer version
+			continue
+		}
+		if dreamModes == nil {
+			dreamModes = make(map[domain.DreamMode]domain.GamemodeStatsPIT, len(player.DreamModes))
+		}
+		dreamModes[mode] = rainbowStatsPITToGamemodeStatsPIT(&stats)
+	}
+
+	return domain.PlayerPIT{
+		UUID:       player.UUID,
+		QueriedAt:  player.QueriedAt,
+		Experience: player.Experience,
+		Solo:       rainbowStatsPITToGamemodeStatsPIT(&player.Solo),
+		Doubles:    rainbowStatsPITToGamemodeStatsPIT(&player.Doubles),
+		Threes:     rainbowStatsPITToGamemodeStatsPIT(&player.Threes),
+		Fours:      rainbowStatsPITToGamemodeStatsPIT(&player.Fours),
+		Fourv4:     rainbowStatsPITToGamemodeStatsPIT(&player.Fourv4),
+		Overall:    rainbowStatsPITToGamemodeStatsPIT(&player.Overall),
+		DreamModes: dreamModes,
+		Economy:    rainbowEconomyStatsToEconomyStatsPIT(player.Economy),
+
+		Rank:              rainbowRankToRankPIT(player.Rank),
+		NetworkExperience: player.NetworkExperience,
+	}
+}
+
+func rainbowRankToRankPIT(rank *rainbowRank) *domain.RankPIT {
+	if rank == nil {
+		return nil
+	}
+	return &domain.RankPIT{
+		StaffRank:          rank.StaffRank,
+		PackageRank:        rank.PackageRank,
+		MonthlyPackageRank: rank.MonthlyPackageRank,
+		RankPlusColor:      rank.PlusColor,
+	}
+}
+
+func rainbowEconomyStatsToEconomyStatsPIT(economy *rainbowEconomyStats) *domain.EconomyStatsPIT {
+	if economy == nil {
+		return nil
+	}
+	return &domain.EconomyStatsPIT{
+		Coins:               economy.Coins,
+		ChallengesCompleted: economy.ChallengesCompleted,
+		ItemsPurchased:      economy.ItemsPurchased,
+		IronCollected:       economy.IronCollected,
+		GoldCollected:       economy.GoldCollected,
+		DiamondsCollected:   economy.DiamondsCollected,
+		EmeraldsCollected:   economy.EmeraldsCollected,
 	}
 }
 
@@ -151,27 +433,466 @@ func HistoryToRainbowHistoryData(history []domain.PlayerPIT) ([]byte, error) {
 	return historyDataJSON, nil
 }
 
+// RainbowColumnarMediaType is the Accept value for the columnar history
+// layout: one object with an array per field instead of an array of
+// objects. Index i of every array is snapshot i.
+const RainbowColumnarMediaType = "application/vnd.flashlight.columnar+json"
+
+type rainbowStatsColumns struct {
+	Winstreak   []*int `json:"winstreak"`
+	GamesPlayed []int  `json:"gamesPlayed"`
+	Wins        []int  `json:"wins"`
+	Losses      []int  `json:"losses"`
+	BedsBroken  []int  `json:"bedsBroken"`
+	BedsLost    []int  `json:"bedsLost"`
+	FinalKills  []int  `json:"finalKills"`
+	FinalDeaths []int  `json:"finalDeaths"`
+	Kills       []int  `json:"kills"`
+	Deaths      []int  `json:"deaths"`
+}
+
+type rainbowHistoryColumns struct {
+	UUID       []string            `json:"uuid"`
+	QueriedAt  []time.Time         `json:"queriedAt"`
+	Experience []int64             `json:"experience"`
+	Solo       rainbowStatsColumns `json:"solo"`
+	Doubles    rainbowStatsColumns `json:"doubles"`
+	Threes     rainbowStatsColumns `json:"threes"`
+	Fours      rainbowStatsColumns `json:"fours"`
+	Fourv4     rainbowStatsColumns `json:"4v4"`
+	Overall    rainbowStatsColumns `json:"overall"`
+	// DreamModes has a column for every dream mode in any snapshot, with
+	// empty stats for the snapshots without the mode
+	DreamModes map[string]rainbowStatsColumns `json:"dreamModes,omitempty"`
+	// Economy is left out when no snapshot has economy stats, and is null
+	// for the snapshots that predate them
+	Economy *rainbowEconomyColumns `json:"economy,omitempty"`
+	// Rank, NetworkExperience and NetworkLevel are left out when no snapshot
+	// has them, and are null for the snapshots that predate them
+	Rank              *rainbowRankColumns `json:"rank,omitempty"`
+	NetworkExperience []*float64          `json:"networkExperience,omitempty"`
+	NetworkLevel      []*float64          `json:"networkLevel,omitempty"`
+}
+
+type rainbowRankColumns struct {
+	Display            []*string `json:"display"`
+	PlusColor          []*string `json:"plusColor"`
+	StaffRank          []*string `json:"staffRank"`
+	PackageRank        []*string `json:"packageRank"`
+	MonthlyPackageRank []*string `json:"monthlyPackageRank"`
+}
+
+func newRainbowRankColumns(length int) *rainbowRankColumns {
+	return &rainbowRankColumns{
+		Display:            make([]*string, 0, length),
+		PlusColor:          make([]*string, 0, length),
+		StaffRank:          make([]*string, 0, length),
+		PackageRank:        make([]*string, 0, length),
+		MonthlyPackageRank: make([]*string, 0, length),
+	}
+}
+
+func (c *rainbowRankColumns) append(rank *domain.RankPIT) {
+	if rank == nil {
+		c.Display = append(c.Display, nil)
+		c.PlusColor = append(c.PlusColor, nil)
+		c.StaffRank = append(c.StaffRank, nil)
+		c.PackageRank = append(c.PackageRank, nil)
+		c.MonthlyPackageRank = append(c.MonthlyPackageRank, nil)
+		return
+	}
+	c.Display = append(c.Display, new(rank.Display()))
+	c.PlusColor = append(c.PlusColor, rank.RankPlusColor)
+	c.StaffRank = append(c.StaffRank, rank.StaffRank)
+	c.PackageRank = append(c.PackageRank, rank.PackageRank)
+	c.MonthlyPackageRank = append(c.MonthlyPackageRank, rank.MonthlyPackageRank)
+}
+
+type rainbowEconomyColumns struct {
+	Coins               []*int `json:"coins"`
+	ChallengesCompleted []*int `json:"challengesCompleted"`
+	ItemsPurchased      []*int `json:"itemsPurchased"`
+	IronCollected       []*int `json:"ironCollected"`
+	GoldCollected       []*int `json:"goldCollected"`
+	DiamondsCollected   []*int `json:"diamondsCollected"`
+	EmeraldsCollected   []*int `json:"emeraldsCollected"`
+}
+
+func newRainbowEconomyColumns(length int) *rainbowEconomyColumns {
+	return &rainbowEconomyColumns{
+		Coins:               make([]*int, 0, length),
+		ChallengesCompleted: make([]*int, 0, length),
+		ItemsPurchased:      make([]*int, 0, length),
+		IronCollected:       make([]*int, 0, length),
+		GoldCollected:       make([]*int, 0, length),
+		DiamondsCollected:   make([]*int, 0, length),
+		EmeraldsCollected:   make([]*int, 0, length),
+	}
+}
+
+func (c *rainbowEconomyColumns) append(economy *domain.EconomyStatsPIT) {
+	if economy == nil {
+		c.Coins = append(c.Coins, nil)
+		c.ChallengesCompleted = append(c.ChallengesCompleted, nil)
+		c.ItemsPurchased = append(c.ItemsPurchased, nil)
+		c.IronCollected = append(c.IronCollected, nil)
+		c.GoldCollected = append(c.GoldCollected, nil)
+		c.DiamondsCollected = append(c.DiamondsCollected, nil)
+		c.EmeraldsCollected = append(c.EmeraldsCollected, nil)
+		return
+	}
+	c.Coins = append(c.Coins, new(economy.Coins))
+	c.ChallengesCompleted = append(c.ChallengesCompleted, new(economy.ChallengesCompleted))
+	c.ItemsPurchased = append(c.ItemsPurchased, new(economy.ItemsPurchased))
+	c.IronCollected = append(c.IronCollected, new(economy.IronCollected))
+	c.GoldCollected = append(c.GoldCollected, new(economy.GoldCollected))
+	c.DiamondsCollected = append(c.DiamondsCollected, new(economy.DiamondsCollected))
+	c.EmeraldsCollected = append(c.EmeraldsCollected, new(economy.EmeraldsCollected))
+}
+
+func newRainbowStatsColumns(length int) rainbowStatsColumns {
+	return rainbowStatsColumns{
+		Winstreak:   make([]*int, 0, length),
+		GamesPlayed: make([]int, 0, length),
+		Wins:        make([]int, 0, length),
+		Losses:      make([]int, 0, length),
+		BedsBroken:  make([]int, 0, length),
+		BedsLost:    make([]int, 0, length),
+		FinalKills:  make([]int, 0, length),
+		FinalDeaths: make([]int, 0, length),
+		Kills:       make([]int, 0, length),
+		Deaths:      make([]int, 0, length),
+	}
+}
+
+func (c *rainbowStatsColumns) append(stats *domain.GamemodeStatsPIT) {
+	c.Winstreak = append(c.Winstreak, stats.Winstreak)
+	c.GamesPlayed = append(c.GamesPlayed, stats.GamesPlayed)
+	c.Wins = append(c.Wins, stats.Wins)
+	c.Losses = append(c.Losses, stats.Losses)
+	c.BedsBroken = append(c.BedsBroken, stats.BedsBroken)
+	c.BedsLost = append(c.BedsLost, stats.BedsLost)
+	c.FinalKills = append(c.FinalKills, stats.FinalKills)
+	c.FinalDeaths = append(c.FinalDeaths, stats.FinalDeaths)
+	c.Kills = append(c.Kills, stats.Kills)
+	c.Deaths = append(c.Deaths, stats.Deaths)
+}
+
+func historyToRainbowHistoryColumns(history []domain.PlayerPIT) rainbowHistoryColumns {
+	columns := rainbowHistoryColumns{
+		UUID:       make([]string, 0, len(history)),
+		QueriedAt:  make([]time.Time, 0, len(history)),
+		Experience: make([]int64, 0, len(history)),
+		Solo:       newRainbowStatsColumns(len(history)),
+		Doubles:    newRainbowStatsColumns(len(history)),
+		Threes:     newRainbowStatsColumns(len(history)),
+		Fours:      newRainbowStatsColumns(len(history)),
+		Fourv4:     newRainbowStatsColumns(len(history)),
+		Overall:    newRainbowStatsColumns(len(history)),
+	}
+
+	for _, player := range history {
+		if player.Economy != nil && columns.Economy == nil {
+			columns.Economy = newRainbowEconomyColumns(len(history))
+		}
+		if player.Rank != nil && columns.Rank == nil {
+			columns.Rank = newRainbowRankColumns(len(history))
+		}
+		if player.NetworkExperience != nil && columns.NetworkExperience == nil {
+			columns.NetworkExperience = make([]*float64, 0, len(history))
+			columns.NetworkLevel = make([]*float64, 0, len(history))
+		}
+		for mode := range player.DreamModes {
+			rainbowMode, ok := rainbowDreamModes[mode]
+			if !ok {
+				continue
+			}
+			if columns.DreamModes == nil {
+				columns.DreamModes = map[string]rainbowStatsColumns{}
+			}
+			if _, ok := columns.DreamModes[rainbowMode]; !ok {
+				columns.DreamModes[rainbowMode] = newRainbowStatsColumns(len(history))
+			}
+		}
+	}
+
+	for _, player := range history {
+		columns.UUID = append(columns.UUID, player.UUID)
+		columns.QueriedAt = append(columns.QueriedAt, player.QueriedAt)
+		columns.Experience = append(columns.Experience, player.Experience)
+		columns.Solo.append(&player.Solo)
+		columns.Doubles.append(&player.Doubles)
+		columns.Threes.append(&player.Threes)
+		columns.Fours.append(&player.Fours)
+		columns.Fourv4.append(&player.Fourv4)
+		columns.Overall.append(&player.Overall)
+		if columns.Economy != nil {
+			columns.Economy.append(player.Economy)
+		}
+		if columns.Rank != nil {
+			columns.Rank.append(player.Rank)
+		}
+		if columns.NetworkExperience != nil {
+			columns.NetworkExperience = append(columns.NetworkExperience, player.NetworkExperience)
+			columns.NetworkLevel = append(columns.NetworkLevel, networkExperienceToRainbowNetworkLevel(player.NetworkExperience))
+		}
+		for mode, rainbowMode := range rainbowDreamModes {
+			modeColumns, ok := columns.DreamModes[rainbowMode]
+			if !ok {
+				continue
+			}
+			stats := player.DreamModes[mode]
+			modeColumns.append(&stats)
+			columns.DreamModes[rainbowMode] = modeColumns
+		}
+	}
+
+	return columns
+}
+
+// HistoryToRainbowHistoryColumnsData is HistoryToRainbowHistoryData in the
+// RainbowColumnarMediaType layout
+func HistoryToRainbowHistoryColumnsData(history []domain.PlayerPIT) ([]byte, error) {
+	historyDataJSON, err := json.Marshal(historyToRainbowHistoryColumns(history))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal history columns: %w", err)
+	}
+	return historyDataJSON, nil
+}
+
+// rainbowGameStatsPIT is a snapshot of a player's stats in a game other than
+// bedwars. The modes and stats are sent with their domain names, as the set
+// of them is open ended.
+type rainbowGameStatsPIT struct {
+	UUID      string    `json:"uuid"`
+	QueriedAt time.Time `json:"queriedAt"`
+	Game      string    `json:"game"`
+	// Modes is null when the stats predate the game being captured
+	Modes map[string]map[string]int `json:"modes"`
+}
+
+func gameStatsToRainbowModes(stats domain.GameStatsPIT) map[string]map[string]int {
+	modes := make(map[string]map[string]int, len(stats))
+	for mode, modeStats := range stats {
+		modes[mode] = make(map[string]int, len(modeStats))
+		for stat, value := range modeStats {
+			modes[mode][string(stat)] = value
+		}
+	}
+	return modes
+}
+
+func gameHistoryToRainbowGameHistory(history []domain.PlayerPIT, game domain.Game) ([]rainbowGameStatsPIT, error) {
+	rainbowGame, ok := rainbowGames[game]
+	if !ok {
+		return nil, fmt.Errorf("unknown game: %q", game)
+	}
+
+	rainbowHistory := make([]rainbowGameStatsPIT, 0, len(history))
+	for _, player := range history {
+		rainbowStats := rainbowGameStatsPIT{
+			UUID:      player.UUID,
+			QueriedAt: player.QueriedAt,
+			Game:      rainbowGame,
+		}
+		if stats, ok := player.GameStats(game); ok {
+			rainbowStats.Modes = gameStatsToRainbowModes(stats)
+		}
+		rainbowHistory = append(rainbowHistory, rainbowStats)
+	}
+	return rainbowHistory, nil
+}
+
+// GameHistoryToRainbowGameHistoryData is HistoryToRainbowHistoryData for
+// the stats in game
+func GameHistoryToRainbowGameHistoryData(history []domain.PlayerPIT, game domain.Game) ([]byte, error) {
+	rainbowHistory, err := gameHistoryToRainbowGameHistory(history, game)
+	if err != nil {
+		return nil, err
+	}
+	historyDataJSON, err := json.Marshal(rainbowHistory)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal game history data: %w", err)
+	}
+	return historyDataJSON, nil
+}
+
+type rainbowGameHistoryColumns struct {
+	Game      string      `json:"game"`
+	UUID      []string    `json:"uuid"`
+	QueriedAt []time.Time `json:"queriedAt"`
+	// Modes has a column for every stat of every mode in any snapshot, which
+	// is null for the snapshots without it
+	Modes map[string]map[string][]*int `json:"modes"`
+}
+
+func gameHistoryToRainbowGameHistoryColumns(history []domain.PlayerPIT, game domain.Game) (rainbowGameHistoryColumns, error) {
+	rainbowGame, ok := rainbowGames[game]
+	if !ok {
+		return rainbowGameHistoryColumns{}, fmt.Errorf("unknown game: %q", game)
+	}
+
+	columns := rainbowGameHistoryColumns{
+		Game:      rainbowGame,
+		UUID:      make([]string, 0, len(history)),
+		QueriedAt: make([]time.Time, 0, len(history)),
+		Modes:     map[string]map[string][]*int{},
+	}
+
+	for i, player := range history {
+		columns.UUID = append(columns.UUID, player.UUID)
+		columns.QueriedAt = append(columns.QueriedAt, player.QueriedAt)
+
+		stats, _ := player.GameStats(game)
+		for mode, modeStats := range stats {
+			if columns.Modes[mode] == nil {
+				columns.Modes[mode] = map[string][]*int{}
+			}
+			for stat, value := range modeStats {
+				column, ok := columns.Modes[mode][string(stat)]
+				if !ok {
+					// The earlier snapshots didn't have this stat
+					column = make([]*int, i, len(history))
+				}
+				columns.Modes[mode][string(stat)] = append(column, new(value))
+			}
+		}
+
+		// Pad the stats this snapshot didn't have
+		for _, modeColumns := range columns.Modes {
+			for stat, column := range modeColumns {
+				if len(column) == i {
+					modeColumns[stat] = append(column, nil)
+				}
+			}
+		}
+	}
+
+	return columns, nil
+}
+
+// GameHistoryToRainbowGameHistoryColumnsData is
+// GameHistoryToRainbowGameHistoryData in the RainbowColumnarMediaType layout
+func GameHistoryToRainbowGameHistoryColumnsData(history []domain.PlayerPIT, game domain.Game) ([]byte, error) {
+	columns, err := gameHistoryToRainbowGameHistoryColumns(history, game)
+	if err != nil {
+		return nil, err
+	}
+	historyDataJSON, err := json.Marshal(columns)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal game history columns: %w", err)
+	}
+	return historyDataJSON, nil
+}
+
+func statsDeltaToRainbowStatsDelta(delta *domain.GamemodeStatsDelta) rainbowStatsDelta {
+	return rainbowStatsDelta{
+		GamesPlayed: delta.GamesPlayed,
+		Wins:        delta.Wins,
+		Losses:      delta.Losses,
+		BedsBroken:  delta.BedsBroken,
+		BedsLost:    delta.BedsLost,
+		FinalKills:  delta.FinalKills,
+		FinalDeaths: delta.FinalDeaths,
+		Kills:       delta.Kills,
+		Deaths:      delta.Deaths,
+	}
+}
+
 func sessionToRainbowSession(session *domain.Session) rainbowSession {
+	// An unset gamemode is the SessionOptions default of every mode
+	gamemode, err := gamemodeToRainbowGamemode(session.Gamemode)
+	if err != nil {
+		gamemode = rainbowGamemodeOverall
+	}
+
 	return rainbowSession{
 		Start:       playerToRainbowPlayerDataPIT(&session.Start),
 		End:         playerToRainbowPlayerDataPIT(&session.End),
 		Consecutive: session.Consecutive,
 		Ongoing:     session.Ongoing,
+		Gamemode:    gamemode,
+		Deltas:      sessionDeltasToRainbowSessionDeltas(&session.Deltas),
 	}
 }
 
-func sessionsToRainbowSessions(sessions []domain.Session) []rainbowSession {
+func sessionDeltasToRainbowSessionDeltas(deltas *domain.SessionDeltas) rainbowSessionDeltas {
+	return rainbowSessionDeltas{
+		Experience: deltas.Experience,
+		Solo:       statsDeltaToRainbowStatsDelta(&deltas.Solo),
+		Doubles:    statsDeltaToRainbowStatsDelta(&deltas.Doubles),
+		Threes:     statsDeltaToRainbowStatsDelta(&deltas.Threes),
+		Fours:      statsDeltaToRainbowStatsDelta(&deltas.Fours),
+		Fourv4:     statsDeltaToRainbowStatsDelta(&deltas.Fourv4),
+		Overall:    statsDeltaToRainbowStatsDelta(&deltas.Overall),
+		Economy:    economyStatsDeltaToRainbowEconomyStats(deltas.Economy),
+	}
+}
+
+func economyStatsDeltaToRainbowEconomyStats(delta *domain.EconomyStatsDelta) *rainbowEconomyStats {
+	if delta == nil {
+		return nil
+	}
+	return &rainbowEconomyStats{
+		Coins:               delta.Coins,
+		ChallengesCompleted: delta.ChallengesCompleted,
+		ItemsPurchased:      delta.ItemsPurchased,
+		IronCollected:       delta.IronCollected,
+		GoldCollected:       delta.GoldCollected,
+		DiamondsCollected:   delta.DiamondsCollected,
+		EmeraldsCollected:   delta.EmeraldsCollected,
+	}
+}
+
+func gamemodeSummaryToRainbowRatios(summary *domain.GamemodeSummary) rainbowRatios {
+	return rainbowRatios{
+		FKDR: summary.FKDR,
+		KDR:  summary.KDR,
+		WLR:  summary.WLR,
+		BBLR: summary.BBLR,
+	}
+}
+
+func sessionSummaryToRainbowSessionSummary(summary *domain.SessionSummary) *rainbowSessionSummary {
+	return &rainbowSessionSummary{
+		DurationHours:    summary.Duration.Hours(),
+		ExperienceGained: summary.ExperienceGained,
+		StarsGained:      summary.StarsGained,
+		GamesPerHour:     summary.GamesPerHour,
+		AttributedGames:  summary.AttributedGames,
+		Solo:             gamemodeSummaryToRainbowRatios(&summary.Solo),
+		Doubles:          gamemodeSummaryToRainbowRatios(&summary.Doubles),
+		Threes:           gamemodeSummaryToRainbowRatios(&summary.Threes),
+		Fours:            gamemodeSummaryToRainbowRatios(&summary.Fours),
+		Fourv4:           gamemodeSummaryToRainbowRatios(&summary.Fourv4),
+		Overall:          gamemodeSummaryToRainbowRatios(&summary.Overall),
+	}
+}
+
+// sessionsToRainbowSessions converts sessions. summaries is either nil or
+// holds the summary of each session.
+func sessionsToRainbowSessions(sessions []domain.Session, summaries []domain.SessionSummary) []rainbowSession {
 	rainbowSessions := make([]rainbowSession, 0, len(sessions))
 
-	for _, session := range sessions {
-		rainbowSessions = append(rainbowSessions, sessionToRainbowSession(&session))
+	for i, session := range sessions {
+		rainbowSession := sessionToRainbowSession(&session)
+		if summaries != nil {
+			rainbowSession.Summary = sessionSummaryToRainbowSessionSummary(&summaries[i])
+		}
+		rainbowSessions = append(rainbowSessions, rainbowSession)
 	}
 
 	return rainbowSessions
 }
 
-func SessionsToRainbowSessionsData(sessions []domain.Session) ([]byte, error) {
-	sessionsDataJSON, err := json.Marshal(sessionsToRainbowSessions(sessions))
+// SessionsToRainbowSessionsData marshals sessions. Pass nil summaries to
+// leave them out.
+func SessionsToRainbowSessionsData(sessions []domain.Session, summaries []domain.SessionSummary) ([]byte, error) {
+	if summaries != nil && len(summaries) != len(sessions) {
+		return nil, fmt.Errorf("got %d summaries for %d sessions", len(summaries), len(sessions))
+	}
+
+	sessionsDataJSON, err := json.Marshal(sessionsToRainbowSessions(sessions, summaries))
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal sessions data: %w", err)
 	}
@@ -1,15 +1,23 @@
 package ports_test
 
 import (
+	"encoding/json"
+	"fmt"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/Amund211/flashlight/internal/domain"
+	"github.com/Amund211/flashlight/internal/domaintest"
 	"github.com/Amund211/flashlight/internal/ports"
 )
 
+const emptyRainbowStats = `{
+	"winstreak": null, "gamesPlayed": 0, "wins": 0, "losses": 0, "bedsBroken": 0,
+	"bedsLost": 0, "finalKills": 0, "finalDeaths": 0, "kills": 0, "deaths": 0
+}`
+
 func TestPlayerToRainbowPlayerPITData(t *testing.T) {
 	t.Parallel()
 
@@ -541,6 +549,87 @@ func TestPlayerToRainbowPlayerPITData(t *testing.T) {
 				}
 			}`),
 		},
+		{
+			name: "dream modes",
+			player: domaintest.NewPlayerBuilder("12345678-90ab-cdef-1234-567890abcdef").
+				WithDreamMode(domain.DreamModeCastle, domain.GamemodeStatsPIT{Winstreak: new(2), GamesPlayed: 3, Wins: 2, Losses: 1}).
+				WithDreamMode(domain.DreamModeRushDoubles, domain.GamemodeStatsPIT{GamesPlayed: 10, FinalKills: 4, Kills: 7, Deaths: 8}).
+				BuildPtr(queriedAt),
+			result: []byte(`{
+				"queriedAt": "2024-02-24T14:17:59.123456789Z",
+				"uuid": "12345678-90ab-cdef-1234-567890abcdef",
+				"experience": 500,
+				"solo": ` + emptyRainbowStats + `,
+				"doubles": ` + emptyRainbowStats + `,
+				"threes": ` + emptyRainbowStats + `,
+				"fours": ` + emptyRainbowStats + `,
+				"4v4": ` + emptyRainbowStats + `,
+				"overall": ` + emptyRainbowStats + `,
+				"dreamModes": {
+					"castle": {
+						"winstreak": 2, "gamesPlayed": 3, "wins": 2, "losses": 1, "bedsBroken": 0,
+						"bedsLost": 0, "finalKills": 0, "finalDeaths": 0, "kills": 0, "deaths": 0
+					},
+					"rushDoubles": {
+						"winstreak": null, "gamesPlayed": 10, "wins": 0, "losses": 0, "bedsBroken": 0,
+						"bedsLost": 0, "finalKills": 4, "finalDeaths": 0, "kills": 7, "deaths": 8
+					}
+				}
+			}`),
+		},
+		{
+			name: "rank and network experience",
+			player: domaintest.NewPlayerBuilder("12345678-90ab-cdef-1234-567890abcdef").
+				WithRank(domain.RankPIT{
+					PackageRank:        new("MVP_PLUS"),
+					MonthlyPackageRank: new("SUPERSTAR"),
+					RankPlusColor:      new("DARK_RED"),
+				}).
+				WithNetworkExperience(22_500).
+				BuildPtr(queriedAt),
+			result: []byte(`{
+				"queriedAt": "2024-02-24T14:17:59.123456789Z",
+				"uuid": "12345678-90ab-cdef-1234-567890abcdef",
+				"experience": 500,
+				"solo": ` + emptyRainbowStats + `,
+				"doubles": ` + emptyRainbowStats + `,
+				"threes": ` + emptyRainbowStats + `,
+				"fours": ` + emptyRainbowStats + `,
+				"4v4": ` + emptyRainbowStats + `,
+				"overall": ` + emptyRainbowStats + `,
+				"rank": {
+					"display": "MVP++",
+					"plusColor": "DARK_RED",
+					"packageRank": "MVP_PLUS",
+					"monthlyPackageRank": "SUPERSTAR"
+				},
+				"networkExperience": 22500,
+				"networkLevel": 3
+			}`),
+		},
+		{
+			name: "no rank",
+			player: domaintest.NewPlayerBuilder("12345678-90ab-cdef-1234-567890abcdef").
+				WithRank(domain.RankPIT{}).
+				WithNetworkExperience(0).
+				BuildPtr(queriedAt),
+			result: []byte(`{
+				"queriedAt": "2024-02-24T14:17:59.123456789Z",
+				"uuid": "12345678-90ab-cdef-1234-567890abcdef",
+				"experience": 500,
+				"solo": ` + emptyRainbowStats + `,
+				"doubles": ` + emptyRainbowStats + `,
+				"threes": ` + emptyRainbowStats + `,
+				"fours": ` + emptyRainbowStats + `,
+				"4v4": ` + emptyRainbowStats + `,
+				"overall": ` + emptyRainbowStats + `,
+				"rank": {
+					"display": ""
+				},
+				"networkExperience": 0,
+				"networkLevel": 1
+			}`),
+		},
 	}
 
 	for _, c := range cases {
@@ -849,6 +938,230 @@ func TestHistoryToRainbowHistoryData(t *testing.T) {
 	}
 }
 
+func TestHistoryToRainbowHistoryColumnsData(t *testing.T) {
+	t.Parallel()
+
+	uuid := "12345678-90ab-cdef-1234-567890abcdef"
+	start := time.Date(2024, time.February, 24, 14, 17, 59, 123_456_789, time.UTC)
+
+	history := []domain.PlayerPIT{
+		domaintest.NewPlayerBuilder(uuid).WithExperience(500).Build(start),
+		domaintest.NewPlayerBuilder(uuid).WithExperience(1_000).
+			Solo().WithWinstreak(3).WithGamesPlayed(4).WithWins(3).WithLosses(1).
+			Fours().WithFinalKills(7).WithKills(12).WithDeaths(2).
+			Build(start.Add(time.Hour)),
+		domaintest.NewPlayerBuilder(uuid).WithExperience(2_500).
+			Doubles().WithBedsBroken(5).WithBedsLost(1).WithFinalDeaths(1).
+			Fourv4().WithGamesPlayed(1).WithWins(1).WithWinstreak(1).
+			Build(start.Add(2 * time.Hour)),
+	}
+
+	// transpose turns the columnar layout back into rows, so the two layouts
+	// can be compared with the row converter as the reference
+	transpose := func(t *testing.T, data []byte) []map[string]any {
+		t.Helper()
+
+		columns := map[string]any{}
+		require.NoError(t, json.Unmarshal(data, &columns))
+
+		rows := []map[string]any{}
+		for key, column := range columns {
+			if nested, ok := column.(map[string]any); ok {
+				for statKey, statColumn := range nested {
+					values := statColumn.([]any)
+					for i, value := range values {
+						for len(rows) <= i {
+							rows = append(rows, map[string]any{})
+						}
+						if rows[i][key] == nil {
+							rows[i][key] = map[string]any{}
+						}
+						rows[i][key].(map[string]any)[statKey] = value
+					}
+				}
+				continue
+			}
+			for i, value := range column.([]any) {
+				for len(rows) <= i {
+					rows = append(rows, map[string]any{})
+				}
+				rows[i][key] = value
+			}
+		}
+		return rows
+	}
+
+	for _, length := range []int{0, 1, len(history)} {
+		t.Run(fmt.Sprintf("length %d", length), func(t *testing.T) {
+			t.Parallel()
+
+			rowsData, err := ports.HistoryToRainbowHistoryData(history[:length])
+			require.NoError(t, err)
+			rows := []map[string]any{}
+			require.NoError(t, json.Unmarshal(rowsData, &rows))
+
+			columnsData, err := ports.HistoryToRainbowHistoryColumnsData(history[:length])
+			require.NoError(t, err)
+
+			require.Equal(t, rows, transpose(t, columnsData))
+		})
+	}
+
+	t.Run("dream modes have a column for every snapshot", func(t *testing.T) {
+		t.Parallel()
+
+		history := []domain.PlayerPIT{
+			domaintest.NewPlayerBuilder(uuid).Build(start),
+			domaintest.NewPlayerBuilder(uuid).
+				WithDreamMode(domain.DreamModeSwapFours, domain.GamemodeStatsPIT{GamesPlayed: 1, Wins: 1}).
+				Build(start.Add(time.Hour)),
+		}
+
+		data, err := ports.HistoryToRainbowHistoryColumnsData(history)
+		require.NoError(t, err)
+
+		columns := struct {
+			DreamModes map[string]map[string][]any `json:"dreamModes"`
+		}{}
+		require.NoError(t, json.Unmarshal(data, &columns))
+		require.Equal(t, map[string]map[string][]any{
+			"swapFours": {
+				"winstreak":   {nil, nil},
+				"gamesPlayed": {0.0, 1.0},
+				"wins":        {0.0, 1.0},
+				"losses":      {0.0, 0.0},
+				"bedsBroken":  {0.0, 0.0},
+				"bedsLost":    {0.0, 0.0},
+				"finalKills":  {0.0, 0.0},
+				"finalDeaths": {0.0, 0.0},
+				"kills":       {0.0, 0.0},
+				"deaths":      {0.0, 0.0},
+			},
+		}, columns.DreamModes)
+	})
+
+	t.Run("rank and network experience are null before they were captured", func(t *testing.T) {
+		t.Parallel()
+
+		history := []domain.PlayerPIT{
+			domaintest.NewPlayerBuilder(uuid).Build(start),
+			domaintest.NewPlayerBuilder(uuid).
+				WithRank(domain.RankPIT{
+					PackageRank:        new("MVP_PLUS"),
+					MonthlyPackageRank: new("SUPERSTAR"),
+					RankPlusColor:      new("DARK_GREEN"),
+				}).
+				WithNetworkExperience(10_000).
+				Build(start.Add(time.Hour)),
+		}
+
+		data, err := ports.HistoryToRainbowHistoryColumnsData(history)
+		require.NoError(t, err)
+
+		columns := struct {
+			Rank              map[string][]any `json:"rank"`
+			NetworkExperience []any            `json:"networkExperience"`
+			NetworkLevel      []any            `json:"networkLevel"`
+		}{}
+		require.NoError(t, json.Unmarshal(data, &columns))
+		require.Equal(t, map[string][]any{
+			"display":            {nil, "MVP++"},
+			"plusColor":          {nil, "DARK_GREEN"},
+			"staffRank":          {nil, nil},
+			"packageRank":        {nil, "MVP_PLUS"},
+			"monthlyPackageRank": {nil, "SUPERSTAR"},
+		}, columns.Rank)
+		require.Equal(t, []any{nil, 10_000.0}, columns.NetworkExperience)
+		require.Equal(t, []any{nil, 2.0}, columns.NetworkLevel)
+	})
+
+	t.Run("empty history has empty columns", func(t *testing.T) {
+		t.Parallel()
+
+		data, err := ports.HistoryToRainbowHistoryColumnsData(nil)
+		require.NoError(t, err)
+
+		emptyStats := `{"winstreak":[],"gamesPlayed":[],"wins":[],"losses":[],"bedsBroken":[],"bedsLost":[],"finalKills":[],"finalDeaths":[],"kills":[],"deaths":[]}`
+		require.JSONEq(t, `{
+			"uuid": [],
+			"queriedAt": [],
+			"experience": [],
+			"solo": `+emptyStats+`,
+			"doubles": `+emptyStats+`,
+			"threes": `+emptyStats+`,
+			"fours": `+emptyStats+`,
+			"4v4": `+emptyStats+`,
+			"overall": `+emptyStats+`
+		}`, string(data))
+	})
+}
+
+func TestGameHistoryToRainbowGameHistoryData(t *testing.T) {
+	t.Parallel()
+
+	uuid := "01234567-89ab-cdef-0123-456789abcdef"
+	start := time.Date(2024, time.May, 4, 18, 0, 0, 0, time.UTC)
+
+	history := []domain.PlayerPIT{
+		// Predates duels stats being captured
+		domaintest.NewPlayerBuilder(uuid).Build(start),
+		domaintest.NewPlayerBuilder(uuid).WithGames(map[domain.Game]domain.GameStatsPIT{
+			domain.GameDuels: {
+				domain.GameModeOverall: {domain.StatWins: 10, domain.StatWinstreak: 2},
+			},
+		}).Build(start.Add(time.Hour)),
+		domaintest.NewPlayerBuilder(uuid).WithGames(map[domain.Game]domain.GameStatsPIT{
+			domain.GameDuels: {
+				domain.GameModeOverall: {domain.StatWins: 12},
+				domain.DuelsModeSumo:   {domain.StatWins: 2},
+			},
+		}).Build(start.Add(2 * time.Hour)),
+	}
+
+	t.Run("rows", func(t *testing.T) {
+		t.Parallel()
+
+		data, err := ports.GameHistoryToRainbowGameHistoryData(history, domain.GameDuels)
+		require.NoError(t, err)
+
+		require.JSONEq(t, `[
+			{"uuid": "01234567-89ab-cdef-0123-456789abcdef", "queriedAt": "2024-05-04T18:00:00Z", "game": "duels", "modes": null},
+			{"uuid": "01234567-89ab-cdef-0123-456789abcdef", "queriedAt": "2024-05-04T19:00:00Z", "game": "duels", "modes": {
+				"overall": {"wins": 10, "winstreak": 2}
+			}},
+			{"uuid": "01234567-89ab-cdef-0123-456789abcdef", "queriedAt": "2024-05-04T20:00:00Z", "game": "duels", "modes": {
+				"overall": {"wins": 12},
+				"sumo": {"wins": 2}
+			}}
+		]`, string(data))
+	})
+
+	t.Run("columns", func(t *testing.T) {
+		t.Parallel()
+
+		data, err := ports.GameHistoryToRainbowGameHistoryColumnsData(history, domain.GameDuels)
+		require.NoError(t, err)
+
+		require.JSONEq(t, `{
+			"game": "duels",
+			"uuid": ["01234567-89ab-cdef-0123-456789abcdef", "01234567-89ab-cdef-0123-456789abcdef", "01234567-89ab-cdef-0123-456789abcdef"],
+			"queriedAt": ["2024-05-04T18:00:00Z", "2024-05-04T19:00:00Z", "2024-05-04T20:00:00Z"],
+			"modes": {
+				"overall": {"wins": [null, 10, 12], "winstreak": [null, 2, null]},
+				"sumo": {"wins": [null, null, 2]}
+			}
+		}`, string(data))
+	})
+
+	t.Run("empty history", func(t *testing.T) {
+		t.Parallel()
+
+		data, err := ports.GameHistoryToRainbowGameHistoryColumnsData(nil, domain.GameSkyWars)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"game": "skywars", "uuid": [], "queriedAt": [], "modes": {}}`, string(data))
+	})
+}
+
 func TestSessionsToRainbowSessionsData(t *testing.T) {
 	t.Parallel()
 
@@ -1124,7 +1437,77 @@ func TestSessionsToRainbowSessionsData(t *testing.T) {
 					}
 				},
 				"consecutive": true,
-				"ongoing": false
+				"ongoing": false,
+				"gamemode": "overall",
+				"deltas": {
+					"experience": 0,
+					"solo": {
+						"gamesPlayed":  0,
+						"wins":         0,
+						"losses":       0,
+						"bedsBroken":   0,
+						"bedsLost":     0,
+						"finalKills":   0,
+						"finalDeaths":  0,
+						"kills":        0,
+						"deaths":       0
+					},
+					"doubles": {
+						"gamesPlayed":  0,
+						"wins":         0,
+						"losses":       0,
+						"bedsBroken":   0,
+						"bedsLost":     0,
+						"finalKills":   0,
+						"finalDeaths":  0,
+						"kills":        0,
+						"deaths":       0
+					},
+					"threes": {
+						"gamesPlayed":  0,
+						"wins":         0,
+						"losses":       0,
+						"bedsBroken":   0,
+						"bedsLost":     0,
+						"finalKills":   0,
+						"finalDeaths":  0,
+						"kills":        0,
+						"deaths":       0
+					},
+					"fours": {
+						"gamesPlayed":  0,
+						"wins":         0,
+						"losses":       0,
+						"bedsBroken":   0,
+						"bedsLost":     0,
+						"finalKills":   0,
+						"finalDeaths":  0,
+						"kills":        0,
+						"deaths":       0
+					},
+					"4v4": {
+						"gamesPlayed":  0,
+						"wins":         0,
+						"losses":       0,
+						"bedsBroken":   0,
+						"bedsLost":     0,
+						"finalKills":   0,
+						"finalDeaths":  0,
+						"kills":        0,
+						"deaths":       0
+					},
+					"overall": {
+						"gamesPlayed":  0,
+						"wins":         0,
+						"losses":       0,
+						"bedsBroken":   0,
+						"bedsLost":     0,
+						"finalKills":   0,
+						"finalDeaths":  0,
+						"kills":        0,
+						"deaths":       0
+					}
+				}
 			}
 			]`),
 		},
@@ -1134,7 +1517,7 @@ func TestSessionsToRainbowSessionsData(t *testing.T) {
 		t.Run(c.name, func(t *testing.T) {
 			t.Parallel()
 
-			data, err := ports.SessionsToRainbowSessionsData(c.sessions)
+			data, err := ports.SessionsToRainbowSessionsData(c.sessions, nil)
 			if c.error {
 				require.Error(t, err)
 				return
@@ -0,0 +1,184 @@
+package ports
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/Amund211/flashlight/internal/app"
+	"github.com/Amund211/flashlight/internal/ratelimiting"
+)
+
+// RateLimitKey is what a rate limit counts requests by.
+type RateLimitKey int
+
+const (
+	// RateLimitByIP keys on the hashed request IP (IPHashKeyFunc).
+	RateLimitByIP RateLimitKey = iota
+	// RateLimitByIdentity keys on the verified identity when the route
+	// accepts bearer sessions and one was presented, and on the user
+	// controlled X-User-Id otherwise (UserIDKeyFunc).
+	RateLimitByIdentity
+)
+
+func (k RateLimitKey) String() string {
+	switch k {
+	case RateLimitByIP:
+		return "ip"
+	case RateLimitByIdentity:
+		return "identity"
+	}
+	return fmt.Sprintf("RateLimitKey(%d)", int(k))
+}
+
+// RateLimit is one token bucket in front of a route.
+type RateLimit struct {
+	Key             RateLimitKey
+	RefillPerSecond ratelimiting.RefillPerSecond
+	BurstSize       ratelimiting.BurstSize
+}
+
+func IPRateLimit(refillPerSecond ratelimiting.RefillPerSecond, burstSize ratelimiting.BurstSize) RateLimit {
+	return RateLimit{Key: RateLimitByIP, RefillPerSecond: refillPerSecond, BurstSize: burstSize}
+}
+
+func IdentityRateLimit(refillPerSecond ratelimiting.RefillPerSecond, burstSize ratelimiting.BurstSize) RateLimit {
+	return RateLimit{Key: RateLimitByIdentity, RefillPerSecond: refillPerSecond, BurstSize: burstSize}
+}
+
+// RouteSpec describes the middleware in front of one endpoint.
+//
+// The chain is always, outermost first: request logger, sentry, blocklist,
+// metrics, reporting meta, CORS, IP limits, bearer auth, identity limits,
+// user visit, compression. Only the pieces the spec asks for are mounted.
+type RouteSpec struct {
+	// Name labels the route in metrics and Sentry
+	Name string
+	// AllowedOrigins enables CORS. nil for the routes only prism calls.
+	AllowedOrigins *DomainSuffixes
+	// BearerAuth is the auth middleware, mounted behind the IP limits:
+	// NewBearerAuthMiddleware on routes that accept a bearer session,
+	// NewAdminAuthMiddleware on the admin routes. nil for routes that
+	// don't authenticate.
+	BearerAuth func(http.HandlerFunc) http.HandlerFunc
+	// RateLimits run in the order given. Every IP limit has to come before
+	// every identity limit, and a route needs at least one IP limit.
+	RateLimits []RateLimit
+	// RegisterUserVisit records the visit once the request is through the
+	// limiters. nil for routes that don't count as a visit.
+	RegisterUserVisit app.RegisterUserVisit
+	// Compress enables gzip/brotli for the routes with large bodies
+	Compress bool
+}
+
+// Validate rejects the specs that can't be built into a safe chain.
+func (s RouteSpec) Validate() error {
+	if s.Name == "" {
+		return errors.New("route spec has no name")
+	}
+
+	ipLimits := 0
+	for i, limit := range s.RateLimits {
+		switch limit.Key {
+		case RateLimitByIP:
+			if i != ipLimits {
+				// The identity limits are mounted behind the bearer
+				// middleware, so an IP limit after one can't be honoured
+				return fmt.Errorf("route %s: ip rate limit %d comes after an identity rate limit", s.Name, i)
+			}
+			ipLimits++
+		case RateLimitByIdentity:
+		default:
+			return fmt.Errorf("route %s: rate limit %d has unknown key %s", s.Name, i, limit.Key)
+		}
+		if limit.RefillPerSecond <= 0 || limit.BurstSize <= 0 {
+			return fmt.Errorf("route %s: rate limit %d must have a positive refill and burst", s.Name, i)
+		}
+	}
+
+	if ipLimits == 0 {
+		// Also what keeps bearer validation, an uncached DB transaction for
+		// a bad token, behind a throttle. See NewBearerAuthMiddleware.
+		return fmt.Errorf("route %s: needs at least one ip rate limit", s.Name)
+	}
+
+	return nil
+}
+
+// BuildRouteMiddleware returns the middleware chain for spec, and a stop func
+// for the rate limiters' eviction goroutines.
+func BuildRouteMiddleware(
+	spec RouteSpec,
+	rootLogger *slog.Logger,
+	sentryMiddleware func(http.HandlerFunc) http.HandlerFunc,
+	blocklistConfig BlocklistConfig,
+) (func(http.HandlerFunc) http.HandlerFunc, func(), error) {
+	if err := spec.Validate(); err != nil {
+		return nil, nil, err
+	}
+
+	middlewares := []func(http.HandlerFunc) http.HandlerFunc{
+		NewRequestLoggerMiddleware(rootLogger),
+		sentryMiddleware,
+		BuildBlocklistMiddleware(blocklistConfig),
+		buildMetricsMiddleware(spec.Name),
+		NewReportingMetaMiddleware(spec.Name),
+	}
+	if spec.AllowedOrigins != nil {
+		middlewares = append(middlewares, BuildCORSMiddleware(spec.AllowedOrigins))
+	}
+
+	var stops []func()
+	bearerMounted := false
+	for _, limit := range spec.RateLimits {
+		keyFunc := IPHashKeyFunc
+		if limit.Key == RateLimitByIdentity {
+			keyFunc = UserIDKeyFunc
+			if !bearerMounted && spec.BearerAuth != nil {
+				middlewares = append(middlewares, spec.BearerAuth)
+				bearerMounted = true
+			}
+		}
+
+		limiter, stop := ratelimiting.NewTokenBucketRateLimiter(limit.RefillPerSecond, limit.BurstSize)
+		stops = append(stops, stop)
+		rateLimiter := ratelimiting.NewRequestBasedRateLimiter(limiter, keyFunc)
+		middlewares = append(middlewares, NewRateLimitMiddleware(rateLimiter, makeOnLimitExceeded(rateLimiter)))
+	}
+	if !bearerMounted && spec.BearerAuth != nil {
+		middlewares = append(middlewares, spec.BearerAuth)
+	}
+
+	if spec.RegisterUserVisit != nil {
+		middlewares = append(middlewares, BuildRegisterUserVisitMiddleware(spec.RegisterUserVisit))
+	}
+
+	if spec.Compress {
+		middlewares = append(middlewares, NewCompressionMiddleware())
+	}
+
+	stop := func() {
+		for _, stop := range stops {
+			stop()
+		}
+	}
+
+	return ComposeMiddlewares(middlewares...), stop, nil
+}
+
+// mustBuildRouteMiddleware is BuildRouteMiddleware for the handler
+// constructors. Their specs are constants, so an invalid one is a bug that
+// should stop the server from starting.
+func mustBuildRouteMiddleware(
+	spec RouteSpec,
+	rootLogger *slog.Logger,
+	sentryMiddleware func(http.HandlerFunc) http.HandlerFunc,
+	blocklistConfig BlocklistConfig,
+) (func(http.HandlerFunc) http.HandlerFunc, func()) {
+	middleware, stop, err := BuildRouteMiddleware(spec, rootLogger, sentryMiddleware, blocklistConfig)
+	if err != nil {
+		panic(fmt.Sprintf("invalid route spec: %s", err.Error()))
+	}
+	return middleware, stop
+}
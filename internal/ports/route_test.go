@@ -0,0 +1,203 @@
+package ports_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Amund211/flashlight/internal/ports"
+)
+
+func TestRouteSpecValidate(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name  string
+		spec  ports.RouteSpec
+		valid bool
+	}{
+		{
+			name: "ip limit only",
+			spec: ports.RouteSpec{
+				Name:       "test",
+				RateLimits: []ports.RateLimit{ports.IPRateLimit(1, 10)},
+			},
+			valid: true,
+		},
+		{
+			name: "ip limits then identity limits",
+			spec: ports.RouteSpec{
+				Name:       "test",
+				BearerAuth: noopAuthMiddleware,
+				RateLimits: []ports.RateLimit{
+					ports.IPRateLimit(1, 10),
+					ports.IPRateLimit(0.1, 100),
+					ports.IdentityRateLimit(1, 5),
+				},
+			},
+			valid: true,
+		},
+		{
+			name: "missing name",
+			spec: ports.RouteSpec{
+				RateLimits: []ports.RateLimit{ports.IPRateLimit(1, 10)},
+			},
+		},
+		{
+			name: "no rate limits",
+			spec: ports.RouteSpec{
+				Name: "test",
+			},
+		},
+		{
+			name: "bearer auth without an ip limit",
+			spec: ports.RouteSpec{
+				Name:       "test",
+				BearerAuth: noopAuthMiddleware,
+				RateLimits: []ports.RateLimit{ports.IdentityRateLimit(1, 5)},
+			},
+		},
+		{
+			name: "ip limit after an identity limit",
+			spec: ports.RouteSpec{
+				Name: "test",
+				RateLimits: []ports.RateLimit{
+					ports.IPRateLimit(1, 10),
+					ports.IdentityRateLimit(1, 5),
+					ports.IPRateLimit(0.1, 100),
+				},
+			},
+		},
+		{
+			name: "zero burst",
+			spec: ports.RouteSpec{
+				Name:       "test",
+				RateLimits: []ports.RateLimit{ports.IPRateLimit(1, 0)},
+			},
+		},
+		{
+			name: "unknown key",
+			spec: ports.RouteSpec{
+				Name:       "test",
+				RateLimits: []ports.RateLimit{{Key: ports.RateLimitKey(7), RefillPerSecond: 1, BurstSize: 1}},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := testCase.spec.Validate()
+			if testCase.valid {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+
+			_, _, err = ports.BuildRouteMiddleware(testCase.spec, authTestLogger, noopAuthMiddleware, emptyBlocklistConfig)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestBuildRouteMiddleware(t *testing.T) {
+	t.Parallel()
+
+	okHandler := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	makeRequest := func(t *testing.T, ip string, userID string) *http.Request {
+		t.Helper()
+		req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/", http.NoBody)
+		withRequestIP(req, ip)
+		req.Header.Set("X-User-Id", userID)
+		req.Header.Set("Origin", "https://subdomain.example.com")
+		return req
+	}
+
+	// order records every request that reached bearer auth
+	build := func(t *testing.T, spec ports.RouteSpec, order *[]string) http.HandlerFunc {
+		t.Helper()
+		spec.BearerAuth = func(next http.HandlerFunc) http.HandlerFunc {
+			return func(w http.ResponseWriter, r *http.Request) {
+				*order = append(*order, "bearer")
+				next(w, r)
+			}
+		}
+		middleware, stop, err := ports.BuildRouteMiddleware(spec, authTestLogger, noopAuthMiddleware, emptyBlocklistConfig)
+		require.NoError(t, err)
+		t.Cleanup(stop)
+		return middleware(okHandler)
+	}
+
+	t.Run("ip limit is in front of bearer auth", func(t *testing.T) {
+		t.Parallel()
+
+		var order []string
+		handler := build(t, ports.RouteSpec{
+			Name:       "test",
+			RateLimits: []ports.RateLimit{ports.IPRateLimit(0.001, 2), ports.IdentityRateLimit(100, 1000)},
+		}, &order)
+
+		codes := []int{}
+		for i := range 3 {
+			w := httptest.NewRecorder()
+			handler(w, makeRequest(t, "203.0.113.1", fmt.Sprintf("user-%d", i)))
+			codes = append(codes, w.Code)
+		}
+
+		require.Equal(t, []int{200, 200, 429}, codes)
+		require.Equal(t, []string{"bearer", "bearer"}, order, "the throttled request must not reach bearer auth")
+	})
+
+	t.Run("identity limit is behind bearer auth", func(t *testing.T) {
+		t.Parallel()
+
+		var order []string
+		handler := build(t, ports.RouteSpec{
+			Name:       "test",
+			RateLimits: []ports.RateLimit{ports.IPRateLimit(100, 1000), ports.IdentityRateLimit(0.001, 1)},
+		}, &order)
+
+		codes := []int{}
+		for range 2 {
+			w := httptest.NewRecorder()
+			handler(w, makeRequest(t, "203.0.113.2", "the-same-user"))
+			codes = append(codes, w.Code)
+		}
+
+		require.Equal(t, []int{200, 429}, codes)
+		require.Equal(t, []string{"bearer", "bearer"}, order, "the throttled request has been through bearer auth")
+	})
+
+	t.Run("cors only when origins are given", func(t *testing.T) {
+		t.Parallel()
+
+		var order []string
+		withCORS := build(t, ports.RouteSpec{
+			Name:           "test",
+			AllowedOrigins: authTestOrigins(t),
+			RateLimits:     []ports.RateLimit{ports.IPRateLimit(0.001, 1)},
+		}, &order)
+		withoutCORS := build(t, ports.RouteSpec{
+			Name:       "test",
+			RateLimits: []ports.RateLimit{ports.IPRateLimit(0.001, 1)},
+		}, &order)
+
+		for range 2 {
+			w := httptest.NewRecorder()
+			withCORS(w, makeRequest(t, "203.0.113.3", "user"))
+			require.Equal(t, "https://subdomain.example.com", w.Header().Get("Access-Control-Allow-Origin"),
+				"the 429 has to carry CORS headers too")
+
+			w = httptest.NewRecorder()
+			withoutCORS(w, makeRequest(t, "203.0.113.3", "user"))
+			require.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+		}
+	})
+}
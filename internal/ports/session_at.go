@@ -10,8 +10,8 @@ import (
 	"time"
 
 	"github.com/Amund211/flashlight/internal/app"
+	"github.com/Amund211/flashlight/internal/domain"
 	"github.com/Amund211/flashlight/internal/logging"
-	"github.com/Amund211/flashlight/internal/ratelimiting"
 	"github.com/Amund211/flashlight/internal/reporting"
 	"github.com/Amund211/flashlight/internal/strutils"
 )
@@ -41,6 +41,35 @@ type rainbowSessionAtResponse struct {
 	Games   []rainbowGameSegment `json:"games"`
 }
 
+// gameResultToRainbowGameResult converts game, which is nil for segments
+// that could not be attributed
+func gameResultToRainbowGameResult(game *domain.GameResult) (*rainbowGameResult, error) {
+	if game == nil {
+		return nil, nil
+	}
+
+	rainbowGamemode, err := gamemodeToRainbowGamemode(game.Gamemode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert gamemode: %w", err)
+	}
+	rainbowOutcome, err := gameOutcomeToRainbowOutcome(game.Outcome)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert outcome: %w", err)
+	}
+
+	return &rainbowGameResult{
+		Gamemode:   rainbowGamemode,
+		Outcome:    rainbowOutcome,
+		FinalKills: game.FinalKills,
+		FinalDeath: game.FinalDeath,
+		BedsBroken: game.BedsBroken,
+		BedLost:    game.BedLost,
+		Kills:      game.Kills,
+		Deaths:     game.Deaths,
+		Experience: game.Experience,
+	}, nil
+}
+
 func MakeGetSessionAtHandler(
 	getSessionAt app.GetSessionAt,
 	registerUserVisit app.RegisterUserVisit,
@@ -50,47 +79,20 @@ func MakeGetSessionAtHandler(
 	bearerAuthMiddleware func(http.HandlerFunc) http.HandlerFunc,
 	blocklistConfig BlocklistConfig,
 ) (http.HandlerFunc, func()) {
-	ipLimiter, stopIPLimiter := ratelimiting.NewTokenBucketRateLimiter(
-		ratelimiting.RefillPerSecond(4),
-		ratelimiting.BurstSize(80),
-	)
-	ipRateLimiter := ratelimiting.NewRequestBasedRateLimiter(
-		ipLimiter,
-		IPHashKeyFunc,
-	)
-	userIDLimiter, stopUserIDLimiter := ratelimiting.NewTokenBucketRateLimiter(
-		ratelimiting.RefillPerSecond(1),
-		ratelimiting.BurstSize(20),
-	)
-	userIDRateLimiter := ratelimiting.NewRequestBasedRateLimiter(
-		// NOTE: Verified identity when there is one, user controlled value otherwise
-		userIDLimiter,
-		UserIDKeyFunc,
-	)
-
-	makeOnLimitExceeded := func(rateLimiter ratelimiting.RequestRateLimiter) http.HandlerFunc {
-		return func(w http.ResponseWriter, r *http.Request) {
-			ctx := r.Context()
-
-			statusCode := http.StatusTooManyRequests
-
-			logging.FromContext(ctx).InfoContext(ctx, "Rate limit exceeded", "statusCode", statusCode, "reason", "ratelimit exceeded", "key", rateLimiter.KeyFor(r))
-
-			http.Error(w, "Rate limit exceeded", statusCode)
-		}
-	}
-
-	middleware := ComposeMiddlewares(
-		NewRequestLoggerMiddleware(rootLogger),
+	middleware, stop := mustBuildRouteMiddleware(
+		RouteSpec{
+			Name:           "session-at",
+			AllowedOrigins: allowedOrigins,
+			BearerAuth:     bearerAuthMiddleware,
+			RateLimits: []RateLimit{
+				IPRateLimit(4, 80),
+				IdentityRateLimit(1, 20),
+			},
+			RegisterUserVisit: registerUserVisit,
+		},
+		rootLogger,
 		sentryMiddleware,
-		BuildBlocklistMiddleware(blocklistConfig),
-		buildMetricsMiddleware("session-at"),
-		NewReportingMetaMiddleware("session-at"),
-		BuildCORSMiddleware(allowedOrigins),
-		NewRateLimitMiddleware(ipRateLimiter, makeOnLimitExceeded(ipRateLimiter)),
-		bearerAuthMiddleware,
-		NewRateLimitMiddleware(userIDRateLimiter, makeOnLimitExceeded(userIDRateLimiter)),
-		BuildRegisterUserVisitMiddleware(registerUserVisit),
+		blocklistConfig,
 	)
 
 	handler := func(w http.ResponseWriter, r *http.Request) {
@@ -101,21 +103,23 @@ func MakeGetSessionAtHandler(
 		if err != nil {
 			var maxBytesErr *http.MaxBytesError
 			if errors.As(err, &maxBytesErr) {
-				http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+				writeErrorResponse(ctx, w, newAPIError(http.StatusRequestEntityTooLarge, errorCodeRequestTooLarge, "Request body too large"))
 				return
 			}
 			reporting.Report(ctx, fmt.Errorf("failed to read request body: %w", err))
-			http.Error(w, "Failed to read request body", http.StatusBadRequest)
+			writeErrorResponse(ctx, w, badRequestError("Failed to read request body"))
 			return
 		}
 		request := struct {
-			UUID string    `json:"uuid"`
-			Time time.Time `json:"time"`
+			UUID                       string    `json:"uuid"`
+			Time                       time.Time `json:"time"`
+			InactivityThresholdMinutes *int      `json:"inactivityThresholdMinutes"`
+			Gamemode                   string    `json:"gamemode"`
 		}{}
 		err = json.Unmarshal(body, &request)
 		if err != nil {
 			logging.FromContext(ctx).WarnContext(ctx, "Failed to parse request body", "error", err)
-			http.Error(w, "Failed to parse request body", http.StatusBadRequest)
+			writeErrorResponse(ctx, w, badRequestError("Failed to parse request body"))
 			return
 		}
 
@@ -126,12 +130,18 @@ func MakeGetSessionAtHandler(
 		uuid, err := strutils.NormalizeUUID(request.UUID)
 		if err != nil {
 			logging.FromContext(ctx).WarnContext(ctx, "Failed to normalize uuid", "error", err, "rawUUID", request.UUID)
-			http.Error(w, "invalid uuid", http.StatusBadRequest)
+			writeErrorResponse(ctx, w, badRequestError("invalid uuid"))
 			return
 		}
 
 		if request.Time.IsZero() {
-			http.Error(w, "missing time", http.StatusBadRequest)
+			writeErrorResponse(ctx, w, badRequestError("missing time"))
+			return
+		}
+
+		sessionOptions, err := sessionOptionsFromRequest(request.InactivityThresholdMinutes, request.Gamemode)
+		if err != nil {
+			writeErrorResponse(ctx, w, badRequestError(err.Error()))
 			return
 		}
 
@@ -148,10 +158,10 @@ func MakeGetSessionAtHandler(
 			slog.String("time", request.Time.Format(time.RFC3339)),
 		)
 
-		result, err := getSessionAt(ctx, uuid, request.Time)
+		result, err := getSessionAt(ctx, uuid, request.Time, sessionOptions)
 		if err != nil {
 			// NOTE: GetSessionAt implementations handle their own error reporting
-			http.Error(w, "Failed to get session", http.StatusInternalServerError)
+			writeErrorResponse(ctx, w, newAPIError(http.StatusInternalServerError, errorCodeInternal, "Failed to get session"))
 			return
 		}
 
@@ -161,34 +171,16 @@ func MakeGetSessionAtHandler(
 		}
 		if result.Session != nil {
 			rbSession := sessionToRainbowSession(result.Session)
+			summary := app.SummarizeSession(result.Session, result.Games)
+			rbSession.Summary = sessionSummaryToRainbowSessionSummary(&summary)
 			response.Session = &rbSession
 		}
 		for _, seg := range result.Games {
-			var game *rainbowGameResult
-			if seg.Game != nil {
-				rainbowGamemode, gErr := gamemodeToRainbowGamemode(seg.Game.Gamemode)
-				if gErr != nil {
-					reporting.Report(ctx, fmt.Errorf("failed to convert gamemode: %w", gErr))
-					http.Error(w, "Failed to serialise response", http.StatusInternalServerError)
-					return
-				}
-				rainbowOutcome, oErr := gameOutcomeToRainbowOutcome(seg.Game.Outcome)
-				if oErr != nil {
-					reporting.Report(ctx, fmt.Errorf("failed to convert outcome: %w", oErr))
-					http.Error(w, "Failed to serialise response", http.StatusInternalServerError)
-					return
-				}
-				game = &rainbowGameResult{
-					Gamemode:   rainbowGamemode,
-					Outcome:    rainbowOutcome,
-					FinalKills: seg.Game.FinalKills,
-					FinalDeath: seg.Game.FinalDeath,
-					BedsBroken: seg.Game.BedsBroken,
-					BedLost:    seg.Game.BedLost,
-					Kills:      seg.Game.Kills,
-					Deaths:     seg.Game.Deaths,
-					Experience: seg.Game.Experience,
-				}
+			game, err := gameResultToRainbowGameResult(seg.Game)
+			if err != nil {
+				reporting.Report(ctx, err)
+				writeErrorResponse(ctx, w, newAPIError(http.StatusInternalServerError, errorCodeInternal, "Failed to serialise response"))
+				return
 			}
 			response.Games = append(response.Games, rainbowGameSegment{
 				Start: playerToRainbowPlayerDataPIT(&seg.Start),
@@ -200,7 +192,7 @@ func MakeGetSessionAtHandler(
 		marshalled, err := json.Marshal(response)
 		if err != nil {
 			reporting.Report(ctx, fmt.Errorf("failed to marshal response: %w", err))
-			http.Error(w, "Failed to marshal response", http.StatusInternalServerError)
+			writeErrorResponse(ctx, w, newAPIError(http.StatusInternalServerError, errorCodeInternal, "Failed to marshal response"))
 			return
 		}
 
@@ -214,10 +206,5 @@ func MakeGetSessionAtHandler(
 		w.Write(marshalled)
 	}
 
-	stop := func() {
-		stopIPLimiter()
-		stopUserIDLimiter()
-	}
-
 	return middleware(handler), stop
 }
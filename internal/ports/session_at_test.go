@@ -81,6 +81,10 @@ func TestMakeGetSessionAtHandler(t *testing.T) {
 			Start       map[string]any `json:"start"`
 			End         map[string]any `json:"end"`
 			Consecutive bool           `json:"consecutive"`
+			Summary     *struct {
+				GamesPerHour    float64 `json:"gamesPerHour"`
+				AttributedGames int     `json:"attributedGames"`
+			} `json:"summary"`
 		} `json:"session"`
 		Games []segmentResponse `json:"games"`
 	}
@@ -127,10 +131,11 @@ func TestMakeGetSessionAtHandler(t *testing.T) {
 		}
 
 		called := false
-		getSessionAt := func(ctx context.Context, gotUUID string, gotAt time.Time) (app.SessionAtResult, error) {
+		getSessionAt := func(ctx context.Context, gotUUID string, gotAt time.Time, gotOptions app.SessionOptions) (app.SessionAtResult, error) {
 			called = true
 			require.Equal(t, uuid, gotUUID)
 			require.WithinDuration(t, at, gotAt, 0)
+			require.Equal(t, app.SessionOptions{}, gotOptions, "options are optional")
 			return result, nil
 		}
 
@@ -140,6 +145,7 @@ func TestMakeGetSessionAtHandler(t *testing.T) {
 		handler.ServeHTTP(w, req)
 
 		require.Equal(t, http.StatusOK, w.Code)
+		requireOpenAPIResponse(t, "POST /v1/session-at", w)
 		require.True(t, called)
 
 		var response sessionAtResponse
@@ -147,6 +153,9 @@ func TestMakeGetSessionAtHandler(t *testing.T) {
 
 		require.NotNil(t, response.Session)
 		require.True(t, response.Session.Consecutive)
+		require.NotNil(t, response.Session.Summary)
+		require.Equal(t, 1.0, response.Session.Summary.GamesPerHour)
+		require.Equal(t, 1, response.Session.Summary.AttributedGames, "only the first segment is a game")
 		require.Equal(t, sessionStart.Format(time.RFC3339), response.Session.Start["queriedAt"])
 		require.Equal(t, sessionEnd.Format(time.RFC3339), response.Session.End["queriedAt"])
 
@@ -191,7 +200,7 @@ func TestMakeGetSessionAtHandler(t *testing.T) {
 			},
 		}
 
-		getSessionAt := func(ctx context.Context, _ string, _ time.Time) (app.SessionAtResult, error) {
+		getSessionAt := func(ctx context.Context, _ string, _ time.Time, _ app.SessionOptions) (app.SessionAtResult, error) {
 			return result, nil
 		}
 
@@ -201,6 +210,7 @@ func TestMakeGetSessionAtHandler(t *testing.T) {
 		handler.ServeHTTP(w, req)
 
 		require.Equal(t, http.StatusOK, w.Code)
+		requireOpenAPIResponse(t, "POST /v1/session-at", w)
 
 		var response sessionAtResponse
 		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
@@ -227,7 +237,7 @@ func TestMakeGetSessionAtHandler(t *testing.T) {
 			},
 		}
 
-		getSessionAt := func(ctx context.Context, _ string, _ time.Time) (app.SessionAtResult, error) {
+		getSessionAt := func(ctx context.Context, _ string, _ time.Time, _ app.SessionOptions) (app.SessionAtResult, error) {
 			return result, nil
 		}
 
@@ -237,12 +247,13 @@ func TestMakeGetSessionAtHandler(t *testing.T) {
 		handler.ServeHTTP(w, req)
 
 		require.Equal(t, http.StatusInternalServerError, w.Code)
+		requireOpenAPIResponse(t, "POST /v1/session-at", w)
 	})
 
 	t.Run("nil session is rendered as null with empty games", func(t *testing.T) {
 		t.Parallel()
 
-		getSessionAt := func(ctx context.Context, gotUUID string, gotAt time.Time) (app.SessionAtResult, error) {
+		getSessionAt := func(ctx context.Context, gotUUID string, gotAt time.Time, gotOptions app.SessionOptions) (app.SessionAtResult, error) {
 			return app.SessionAtResult{Session: nil, Games: nil}, nil
 		}
 
@@ -252,6 +263,7 @@ func TestMakeGetSessionAtHandler(t *testing.T) {
 		handler.ServeHTTP(w, req)
 
 		require.Equal(t, http.StatusOK, w.Code)
+		requireOpenAPIResponse(t, "POST /v1/session-at", w)
 
 		var response sessionAtResponse
 		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
@@ -260,7 +272,7 @@ func TestMakeGetSessionAtHandler(t *testing.T) {
 	})
 
 	makeAssertNotCalled := func(t *testing.T) app.GetSessionAt {
-		return func(ctx context.Context, uuid string, at time.Time) (app.SessionAtResult, error) {
+		return func(ctx context.Context, uuid string, at time.Time, options app.SessionOptions) (app.SessionAtResult, error) {
 			t.Helper()
 			t.Fatal("getSessionAt should not be called")
 			return app.SessionAtResult{}, nil
@@ -276,9 +288,42 @@ func TestMakeGetSessionAtHandler(t *testing.T) {
 		handler.ServeHTTP(w, req)
 
 		require.Equal(t, http.StatusBadRequest, w.Code)
+		requireOpenAPIResponse(t, "POST /v1/session-at", w)
 		require.Contains(t, w.Body.String(), "invalid uuid")
 	})
 
+	t.Run("session options are forwarded", func(t *testing.T) {
+		t.Parallel()
+
+		var gotOptions app.SessionOptions
+		handler := makeHandler(func(ctx context.Context, _ string, _ time.Time, options app.SessionOptions) (app.SessionAtResult, error) {
+			gotOptions = options
+			return app.SessionAtResult{}, nil
+		})
+		body := fmt.Sprintf(`{"uuid":"%s","time":"%s","gamemode":"4v4","inactivityThresholdMinutes":120}`, uuid, at.Format(time.RFC3339))
+		req := httptest.NewRequestWithContext(t.Context(), "POST", "/session-at", strings.NewReader(body))
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		requireOpenAPIResponse(t, "POST /v1/session-at", w)
+		require.Equal(t, app.SessionOptions{InactivityThreshold: 2 * time.Hour, Gamemode: domain.GamemodeFourv4}, gotOptions)
+	})
+
+	t.Run("invalid session options", func(t *testing.T) {
+		t.Parallel()
+
+		handler := makeHandler(makeAssertNotCalled(t))
+		body := fmt.Sprintf(`{"uuid":"%s","time":"%s","inactivityThresholdMinutes":5}`, uuid, at.Format(time.RFC3339))
+		req := httptest.NewRequestWithContext(t.Context(), "POST", "/session-at", strings.NewReader(body))
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		requireOpenAPIResponse(t, "POST /v1/session-at", w)
+		require.Contains(t, w.Body.String(), "inactivityThresholdMinutes must be between 10 and 360")
+	})
+
 	t.Run("missing time", func(t *testing.T) {
 		t.Parallel()
 
@@ -289,6 +334,7 @@ func TestMakeGetSessionAtHandler(t *testing.T) {
 		handler.ServeHTTP(w, req)
 
 		require.Equal(t, http.StatusBadRequest, w.Code)
+		requireOpenAPIResponse(t, "POST /v1/session-at", w)
 		require.Contains(t, w.Body.String(), "missing time")
 	})
 
@@ -308,6 +354,7 @@ func TestMakeGetSessionAtHandler(t *testing.T) {
 		handler.ServeHTTP(w, req)
 
 		require.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+		requireOpenAPIResponse(t, "POST /v1/session-at", w)
 	})
 
 	t.Run("malformed JSON", func(t *testing.T) {
@@ -320,12 +367,13 @@ func TestMakeGetSessionAtHandler(t *testing.T) {
 		handler.ServeHTTP(w, req)
 
 		require.Equal(t, http.StatusBadRequest, w.Code)
+		requireOpenAPIResponse(t, "POST /v1/session-at", w)
 	})
 
 	t.Run("app method failure returns 500", func(t *testing.T) {
 		t.Parallel()
 
-		getSessionAt := func(ctx context.Context, uuid string, at time.Time) (app.SessionAtResult, error) {
+		getSessionAt := func(ctx context.Context, uuid string, at time.Time, options app.SessionOptions) (app.SessionAtResult, error) {
 			return app.SessionAtResult{}, fmt.Errorf("boom")
 		}
 
@@ -335,5 +383,6 @@ func TestMakeGetSessionAtHandler(t *testing.T) {
 		handler.ServeHTTP(w, req)
 
 		require.Equal(t, http.StatusInternalServerError, w.Code)
+		requireOpenAPIResponse(t, "POST /v1/session-at", w)
 	})
 }
@@ -11,8 +11,8 @@ import (
 	"time"
 
 	"github.com/Amund211/flashlight/internal/app"
+	"github.com/Amund211/flashlight/internal/domain"
 	"github.com/Amund211/flashlight/internal/logging"
-	"github.com/Amund211/flashlight/internal/ratelimiting"
 	"github.com/Amund211/flashlight/internal/reporting"
 	"github.com/Amund211/flashlight/internal/strutils"
 )
@@ -27,47 +27,21 @@ func MakeGetSessionsHandler(
 	bearerAuthMiddleware func(http.HandlerFunc) http.HandlerFunc,
 	blocklistConfig BlocklistConfig,
 ) (http.HandlerFunc, func()) {
-	ipLimiter, stopIPLimiter := ratelimiting.NewTokenBucketRateLimiter(
-		ratelimiting.RefillPerSecond(4),
-		ratelimiting.BurstSize(80),
-	)
-	ipRateLimiter := ratelimiting.NewRequestBasedRateLimiter(
-		ipLimiter,
-		IPHashKeyFunc,
-	)
-	userIDLimiter, stopUserIDLimiter := ratelimiting.NewTokenBucketRateLimiter(
-		ratelimiting.RefillPerSecond(1),
-		ratelimiting.BurstSize(20),
-	)
-	userIDRateLimiter := ratelimiting.NewRequestBasedRateLimiter(
-		// NOTE: Verified identity when there is one, user controlled value otherwise
-		userIDLimiter,
-		UserIDKeyFunc,
-	)
-
-	makeOnLimitExceeded := func(rateLimiter ratelimiting.RequestRateLimiter) http.HandlerFunc {
-		return func(w http.ResponseWriter, r *http.Request) {
-			ctx := r.Context()
-
-			statusCode := http.StatusTooManyRequests
-
-			logging.FromContext(ctx).InfoContext(ctx, "Rate limit exceeded", "statusCode", statusCode, "reason", "ratelimit exceeded", "key", rateLimiter.KeyFor(r))
-
-			http.Error(w, "Rate limit exceeded", statusCode)
-		}
-	}
-
-	middleware := ComposeMiddlewares(
-		NewRequestLoggerMiddleware(rootLogger),
+	middleware, stop := mustBuildRouteMiddleware(
+		RouteSpec{
+			Name:           "sessions",
+			AllowedOrigins: allowedOrigins,
+			BearerAuth:     bearerAuthMiddleware,
+			RateLimits: []RateLimit{
+				IPRateLimit(4, 80),
+				IdentityRateLimit(1, 20),
+			},
+			RegisterUserVisit: registerUserVisit,
+			Compress:          true,
+		},
+		rootLogger,
 		sentryMiddleware,
-		BuildBlocklistMiddleware(blocklistConfig),
-		buildMetricsMiddleware("sessions"),
-		NewReportingMetaMiddleware("sessions"),
-		BuildCORSMiddleware(allowedOrigins),
-		NewRateLimitMiddleware(ipRateLimiter, makeOnLimitExceeded(ipRateLimiter)),
-		bearerAuthMiddleware,
-		NewRateLimitMiddleware(userIDRateLimiter, makeOnLimitExceeded(userIDRateLimiter)),
-		BuildRegisterUserVisitMiddleware(registerUserVisit),
+		blocklistConfig,
 	)
 
 	handler := func(w http.ResponseWriter, r *http.Request) {
@@ -78,22 +52,25 @@ func MakeGetSessionsHandler(
 		if err != nil {
 			var maxBytesErr *http.MaxBytesError
 			if errors.As(err, &maxBytesErr) {
-				http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+				writeErrorResponse(ctx, w, newAPIError(http.StatusRequestEntityTooLarge, errorCodeRequestTooLarge, "Request body too large"))
 				return
 			}
 			reporting.Report(ctx, fmt.Errorf("failed to read request body: %w", err))
-			http.Error(w, "Failed to read request body", http.StatusBadRequest)
+			writeErrorResponse(ctx, w, badRequestError("Failed to read request body"))
 			return
 		}
 		request := struct {
-			UUID  string    `json:"uuid"`
-			Start time.Time `json:"start"`
-			End   time.Time `json:"end"`
+			UUID                       string    `json:"uuid"`
+			Start                      time.Time `json:"start"`
+			End                        time.Time `json:"end"`
+			InactivityThresholdMinutes *int      `json:"inactivityThresholdMinutes"`
+			Gamemode                   string    `json:"gamemode"`
+			Summary                    bool      `json:"summary"`
 		}{}
 		err = json.Unmarshal(body, &request)
 		if err != nil {
 			logging.FromContext(ctx).WarnContext(ctx, "Failed to parse request body", "error", err)
-			http.Error(w, "Failed to parse request body", http.StatusBadRequest)
+			writeErrorResponse(ctx, w, badRequestError("Failed to parse request body"))
 			return
 		}
 
@@ -105,7 +82,7 @@ func MakeGetSessionsHandler(
 		uuid, err := strutils.NormalizeUUID(request.UUID)
 		if err != nil {
 			logging.FromContext(ctx).WarnContext(ctx, "Failed to normalize uuid", "error", err, "rawUUID", request.UUID)
-			http.Error(w, "invalid uuid", http.StatusBadRequest)
+			writeErrorResponse(ctx, w, badRequestError("invalid uuid"))
 			return
 		}
 
@@ -126,7 +103,13 @@ func MakeGetSessionsHandler(
 
 		if request.Start.After(request.End) {
 			logging.FromContext(ctx).WarnContext(ctx, "Start time is after end time")
-			http.Error(w, "Start time cannot be after end time", http.StatusBadRequest)
+			writeErrorResponse(ctx, w, badRequestError("Start time cannot be after end time"))
+			return
+		}
+
+		sessionOptions, err := sessionOptionsFromRequest(request.InactivityThresholdMinutes, request.Gamemode)
+		if err != nil {
+			writeErrorResponse(ctx, w, badRequestError(err.Error()))
 			return
 		}
 
@@ -134,7 +117,7 @@ func MakeGetSessionsHandler(
 		timespan := request.End.Sub(request.Start)
 		// TODO: Revert to max 60 days (when no longer using this for "wrapped" page on website)
 		if timespan >= 400*24*time.Hour {
-			http.Error(w, "Time interval is too long", http.StatusBadRequest)
+			writeErrorResponse(ctx, w, badRequestError("Time interval is too long"))
 			return
 		}
 
@@ -145,18 +128,32 @@ func MakeGetSessionsHandler(
 		stats, err := getPlayerPITs(ctx, uuid, filterStart, filterEnd)
 		if err != nil {
 			// NOTE: GetPlayerPITs implementations handle their own error reporting
-			http.Error(w, "Failed to get player data", http.StatusInternalServerError)
+			writeErrorResponse(ctx, w, newAPIError(http.StatusInternalServerError, errorCodeInternal, "Failed to get player data"))
 			return
 		}
 
-		sessions := computeSessions(ctx, stats, request.Start, request.End)
+		sessions := computeSessions(ctx, stats, request.Start, request.End, sessionOptions)
+
+		var summaries []domain.SessionSummary
+		if request.Summary {
+			summaries = make([]domain.SessionSummary, 0, len(sessions))
+			for i := range sessions {
+				games, err := app.ComputeGameSegments(ctx, stats, &sessions[i])
+				if err != nil {
+					// NOTE: ComputeGameSegments reports its own errors
+					writeErrorResponse(ctx, w, newAPIError(http.StatusInternalServerError, errorCodeInternal, "Failed to summarize sessions"))
+					return
+				}
+				summaries = append(summaries, app.SummarizeSession(&sessions[i], games))
+			}
+		}
 
-		marshalled, err := SessionsToRainbowSessionsData(sessions)
+		marshalled, err := SessionsToRainbowSessionsData(sessions, summaries)
 		if err != nil {
 			reporting.Report(ctx, fmt.Errorf("failed to convert sessions to response: %w", err), map[string]string{
 				"length": strconv.Itoa(len(sessions)),
 			})
-			http.Error(w, "Failed to marshal response", http.StatusInternalServerError)
+			writeErrorResponse(ctx, w, newAPIError(http.StatusInternalServerError, errorCodeInternal, "Failed to marshal response"))
 			return
 		}
 
@@ -167,10 +164,37 @@ func MakeGetSessionsHandler(
 		w.Write(marshalled)
 	}
 
-	stop := func() {
-		stopIPLimiter()
-		stopUserIDLimiter()
+	return middleware(handler), stop
+}
+
+// sessionOptionsFromRequest validates the optional inactivity threshold and
+// gamemode accepted by the sessions and session-at requests. The returned
+// error is safe to show the caller.
+func sessionOptionsFromRequest(inactivityThresholdMinutes *int, rawGamemode string) (app.SessionOptions, error) {
+	options := app.SessionOptions{}
+
+	if inactivityThresholdMinutes != nil {
+		// Bounds check the minutes before converting, as a large value
+		// overflows the duration
+		minMinutes := int(app.MinSessionInactivityThreshold.Minutes())
+		maxMinutes := int(app.MaxSessionInactivityThreshold.Minutes())
+		if *inactivityThresholdMinutes < minMinutes || *inactivityThresholdMinutes > maxMinutes {
+			return app.SessionOptions{}, fmt.Errorf(
+				"inactivityThresholdMinutes must be between %d and %d",
+				minMinutes,
+				maxMinutes,
+			)
+		}
+		options.InactivityThreshold = time.Duration(*inactivityThresholdMinutes) * time.Minute
 	}
 
-	return middleware(handler), stop
+	if rawGamemode != "" {
+		gamemode, err := rainbowGamemodeToGamemode(rawGamemode)
+		if err != nil {
+			return app.SessionOptions{}, fmt.Errorf("invalid gamemode")
+		}
+		options.Gamemode = gamemode
+	}
+
+	return options, nil
 }
@@ -2,6 +2,7 @@ package ports_test
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"log/slog"
@@ -75,10 +76,12 @@ func TestMakeGetSessionsHandler(t *testing.T) {
 	stats := []domain.PlayerPIT{
 		domaintest.NewPlayerBuilder(uuid).
 			WithExperience(500).
+			WithEconomy(domain.EconomyStatsPIT{Coins: 2000, ItemsPurchased: 40, IronCollected: 300}).
 			FromDB().
 			Fours().WithGamesPlayed(10).WithFinalKills(10).Build(start),
 		domaintest.NewPlayerBuilder(uuid).
 			WithExperience(1000).
+			WithEconomy(domain.EconomyStatsPIT{Coins: 1800, ItemsPurchased: 52, IronCollected: 420, GoldCollected: 30}).
 			FromDB().
 			Fours().WithGamesPlayed(11).WithFinalKills(11).Build(end),
 	}
@@ -89,9 +92,11 @@ func TestMakeGetSessionsHandler(t *testing.T) {
 			Start:       stats[0],
 			End:         stats[1],
 			Consecutive: true,
+			Gamemode:    domain.GamemodeOverall,
+			Deltas:      domain.NewSessionDeltas(&stats[0], &stats[1]),
 		},
 	}
-	sessionsJSON, err := ports.SessionsToRainbowSessionsData(sessions)
+	sessionsJSON, err := ports.SessionsToRainbowSessionsData(sessions, nil)
 	require.NoError(t, err)
 
 	makeRequest := func(
@@ -124,6 +129,7 @@ func TestMakeGetSessionsHandler(t *testing.T) {
 		handler.ServeHTTP(w, req)
 
 		require.Equal(t, http.StatusOK, w.Code)
+		requireOpenAPIResponse(t, "POST /v1/sessions", w)
 		require.NoError(t, err)
 		require.JSONEq(t, string(sessionsJSON), w.Body.String())
 		require.True(t, *called)
@@ -146,6 +152,7 @@ func TestMakeGetSessionsHandler(t *testing.T) {
 		handler.ServeHTTP(w, req)
 
 		require.Equal(t, http.StatusOK, w.Code)
+		requireOpenAPIResponse(t, "POST /v1/sessions", w)
 		require.NoError(t, err)
 		require.JSONEq(t, string(sessionsJSON), w.Body.String())
 		require.True(t, *called)
@@ -163,6 +170,7 @@ func TestMakeGetSessionsHandler(t *testing.T) {
 		handler.ServeHTTP(w, req)
 
 		require.Equal(t, http.StatusBadRequest, w.Code)
+		requireOpenAPIResponse(t, "POST /v1/sessions", w)
 		require.Contains(t, w.Body.String(), "invalid uuid")
 		require.False(t, *called)
 	})
@@ -179,6 +187,7 @@ func TestMakeGetSessionsHandler(t *testing.T) {
 		handler.ServeHTTP(w, req)
 
 		require.Equal(t, http.StatusBadRequest, w.Code)
+		requireOpenAPIResponse(t, "POST /v1/sessions", w)
 		require.Contains(t, w.Body.String(), "Start time cannot be after end time")
 		require.False(t, *called)
 	})
@@ -200,6 +209,7 @@ func TestMakeGetSessionsHandler(t *testing.T) {
 		handler.ServeHTTP(w, req)
 
 		require.Equal(t, http.StatusBadRequest, w.Code)
+		requireOpenAPIResponse(t, "POST /v1/sessions", w)
 		require.Contains(t, w.Body.String(), "Time interval is too long")
 		require.False(t, *called)
 	})
@@ -221,6 +231,7 @@ func TestMakeGetSessionsHandler(t *testing.T) {
 		handler.ServeHTTP(w, req)
 
 		require.Equal(t, http.StatusBadRequest, w.Code)
+		requireOpenAPIResponse(t, "POST /v1/sessions", w)
 		require.Contains(t, w.Body.String(), "Time interval is too long")
 		require.False(t, *called)
 	})
@@ -245,9 +256,120 @@ func TestMakeGetSessionsHandler(t *testing.T) {
 		handler.ServeHTTP(w, req)
 
 		require.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+		requireOpenAPIResponse(t, "POST /v1/sessions", w)
 		require.False(t, *called)
 	})
 
+	t.Run("session options", func(t *testing.T) {
+		t.Parallel()
+
+		makeOptionsRequest := func(options string) *http.Request {
+			body := fmt.Sprintf(`{"uuid":"%s","start":"%s","end":"%s",%s}`, uuid, startStr, endStr, options)
+			return httptest.NewRequestWithContext(t.Context(), "POST", "/sessions", strings.NewReader(body))
+		}
+
+		t.Run("gamemode and threshold", func(t *testing.T) {
+			t.Parallel()
+
+			getPlayerPITs, called := makeGetPlayerPITs(t, uuid, start, end, stats, nil)
+			handler := makeGetSessionsHandler(getPlayerPITs)
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, makeOptionsRequest(`"gamemode":"fours","inactivityThresholdMinutes":90`))
+
+			require.Equal(t, http.StatusOK, w.Code)
+			requireOpenAPIResponse(t, "POST /v1/sessions", w)
+			require.True(t, *called)
+
+			var response []struct {
+				Gamemode string `json:"gamemode"`
+				Deltas   struct {
+					Experience int64 `json:"experience"`
+					Fours      struct {
+						GamesPlayed int `json:"gamesPlayed"`
+						FinalKills  int `json:"finalKills"`
+					} `json:"fours"`
+				} `json:"deltas"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			require.Len(t, response, 1)
+			require.Equal(t, "fours", response[0].Gamemode)
+			require.Equal(t, int64(500), response[0].Deltas.Experience)
+			require.Equal(t, 1, response[0].Deltas.Fours.GamesPlayed)
+			require.Equal(t, 1, response[0].Deltas.Fours.FinalKills)
+		})
+
+		t.Run("summary", func(t *testing.T) {
+			t.Parallel()
+
+			getPlayerPITs, called := makeGetPlayerPITs(t, uuid, start, end, stats, nil)
+			handler := makeGetSessionsHandler(getPlayerPITs)
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, makeOptionsRequest(`"summary":true`))
+
+			require.Equal(t, http.StatusOK, w.Code)
+			requireOpenAPIResponse(t, "POST /v1/sessions", w)
+			require.True(t, *called)
+
+			var response []struct {
+				Summary *struct {
+					DurationHours    float64 `json:"durationHours"`
+					ExperienceGained int64   `json:"experienceGained"`
+					GamesPerHour     float64 `json:"gamesPerHour"`
+					AttributedGames  int     `json:"attributedGames"`
+					Fours            struct {
+						FKDR float64 `json:"fkdr"`
+					} `json:"fours"`
+				} `json:"summary"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			require.Len(t, response, 1)
+			require.NotNil(t, response[0].Summary)
+			require.Equal(t, 1.0, response[0].Summary.DurationHours)
+			require.Equal(t, int64(500), response[0].Summary.ExperienceGained)
+			require.Equal(t, 1.0, response[0].Summary.GamesPerHour)
+			require.Equal(t, 1, response[0].Summary.AttributedGames)
+			require.Equal(t, 1.0, response[0].Summary.Fours.FKDR, "no final deaths gives the final kills")
+		})
+
+		t.Run("no summary by default", func(t *testing.T) {
+			t.Parallel()
+
+			getPlayerPITs, _ := makeGetPlayerPITs(t, uuid, start, end, stats, nil)
+			handler := makeGetSessionsHandler(getPlayerPITs)
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, makeOptionsRequest(`"summary":false`))
+
+			require.Equal(t, http.StatusOK, w.Code)
+			require.NotContains(t, w.Body.String(), `"summary"`)
+		})
+
+		for name, options := range map[string]string{
+			"threshold below minimum": `"inactivityThresholdMinutes":9`,
+			"threshold above maximum": `"inactivityThresholdMinutes":361`,
+			"zero threshold":          `"inactivityThresholdMinutes":0`,
+			// Wraps around to 30 minutes when converted to a duration
+			"overflowing threshold": `"inactivityThresholdMinutes":9007199254741022`,
+			"unknown gamemode":      `"gamemode":"eights"`,
+		} {
+			t.Run(name, func(t *testing.T) {
+				t.Parallel()
+
+				getPlayerPITs, called := makeGetPlayerPITs(t, uuid, start, end, stats, nil)
+				handler := makeGetSessionsHandler(getPlayerPITs)
+
+				w := httptest.NewRecorder()
+				handler.ServeHTTP(w, makeOptionsRequest(options))
+
+				require.Equal(t, http.StatusBadRequest, w.Code)
+				requireOpenAPIResponse(t, "POST /v1/sessions", w)
+				require.False(t, *called)
+			})
+		}
+	})
+
 	t.Run("interval just under 400 days", func(t *testing.T) {
 		t.Parallel()
 
@@ -265,6 +387,7 @@ func TestMakeGetSessionsHandler(t *testing.T) {
 		handler.ServeHTTP(w, req)
 
 		require.Equal(t, http.StatusOK, w.Code)
+		requireOpenAPIResponse(t, "POST /v1/sessions", w)
 		require.True(t, *called)
 	})
 }
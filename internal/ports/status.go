@@ -0,0 +1,113 @@
+package ports
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/Amund211/flashlight/internal/app"
+	"github.com/Amund211/flashlight/internal/domain"
+	"github.com/Amund211/flashlight/internal/logging"
+	"github.com/Amund211/flashlight/internal/reporting"
+	"github.com/Amund211/flashlight/internal/strutils"
+)
+
+// statusMaxAge is the TTL of the status cache in main.go. Statuses go stale
+// quickly, so both are kept short.
+const statusMaxAge = 30 * time.Second
+
+type statusResponse struct {
+	Success   bool      `json:"success"`
+	UUID      string    `json:"uuid"`
+	Online    bool      `json:"online"`
+	GameType  *string   `json:"gameType,omitempty"`
+	Mode      *string   `json:"mode,omitempty"`
+	Map       *string   `json:"map,omitempty"`
+	QueriedAt time.Time `json:"queriedAt"`
+}
+
+func makeSuccessStatusResponse(status domain.PlayerStatus) ([]byte, error) {
+	data, err := json.Marshal(statusResponse{
+		Success:   true,
+		UUID:      status.UUID,
+		Online:    status.Online,
+		GameType:  status.GameType,
+		Mode:      status.Mode,
+		Map:       status.Map,
+		QueriedAt: status.QueriedAt,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal response: %w", err)
+	}
+	return data, nil
+}
+
+func MakeGetStatusHandler(
+	getStatus app.GetStatus,
+	registerUserVisit app.RegisterUserVisit,
+	allowedOrigins *DomainSuffixes,
+	rootLogger *slog.Logger,
+	sentryMiddleware func(http.HandlerFunc) http.HandlerFunc,
+	bearerAuthMiddleware func(http.HandlerFunc) http.HandlerFunc,
+	blocklistConfig BlocklistConfig,
+) (http.HandlerFunc, func()) {
+	middleware, stop := mustBuildRouteMiddleware(
+		RouteSpec{
+			Name:           "get_status",
+			AllowedOrigins: allowedOrigins,
+			BearerAuth:     bearerAuthMiddleware,
+			RateLimits: []RateLimit{
+				IPRateLimit(8, 480),
+				IdentityRateLimit(2, 120),
+			},
+			RegisterUserVisit: registerUserVisit,
+		},
+		rootLogger,
+		sentryMiddleware,
+		blocklistConfig,
+	)
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		rawUUID := r.PathValue("uuid")
+
+		uuid, err := strutils.NormalizeUUID(rawUUID)
+		if err != nil {
+			writeErrorResponse(ctx, w, badRequestError("invalid uuid"))
+			return
+		}
+
+		logging.FromContext(ctx).InfoContext(ctx, "Handling get_status request",
+			slog.String("uuid", uuid),
+		)
+
+		ctx = logging.AddMetaToContext(ctx,
+			slog.String("uuid", uuid),
+		)
+		ctx = reporting.AddExtrasToContext(ctx,
+			map[string]string{
+				"uuid": uuid,
+			},
+		)
+
+		status, err := getStatus(ctx, uuid)
+		if err != nil {
+			// NOTE: GetStatus implementations handle their own error reporting
+			writeErrorResponse(ctx, w, apiErrorFromDomain(err, "Internal server error"))
+			return
+		}
+
+		response, err := makeSuccessStatusResponse(status)
+		if err != nil {
+			reporting.Report(ctx, fmt.Errorf("failed to create success response: %w", err))
+			writeErrorResponse(ctx, w, internalError())
+			return
+		}
+
+		writeConditionalJSON(ctx, w, r, response, cacheControlFor(statusMaxAge))
+	}
+
+	return middleware(handler), stop
+}
@@ -0,0 +1,153 @@
+package ports_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Amund211/flashlight/internal/app"
+	"github.com/Amund211/flashlight/internal/domain"
+	"github.com/Amund211/flashlight/internal/ports"
+)
+
+func TestMakeGetStatusHandler(t *testing.T) {
+	t.Parallel()
+
+	testLogger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	allowedOrigins, err := ports.NewDomainSuffixes("example.com", "test.com")
+	require.NoError(t, err)
+	noopMiddleware := func(h http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			h(w, r)
+		}
+	}
+
+	uuid := "01234567-89ab-cdef-0123-456789abcdef"
+	queriedAt := time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC)
+
+	makeGetStatus := func(t *testing.T, status domain.PlayerStatus, err error) (app.GetStatus, *bool) {
+		called := false
+		return func(ctx context.Context, gotUUID string) (domain.PlayerStatus, error) {
+			t.Helper()
+			require.Equal(t, uuid, gotUUID)
+
+			called = true
+
+			return status, err
+		}, &called
+	}
+
+	makeHandler := func(getStatus app.GetStatus) http.HandlerFunc {
+		stubRegisterUserVisit := func(ctx context.Context, userID string, ipHash string, userAgent string) (domain.User, error) {
+			return domain.User{}, nil
+		}
+		handler, stop := ports.MakeGetStatusHandler(
+			getStatus,
+			stubRegisterUserVisit,
+			allowedOrigins,
+			testLogger,
+			noopMiddleware,
+			noopMiddleware,
+			emptyBlocklistConfig,
+		)
+		t.Cleanup(stop)
+		return handler
+	}
+
+	makeRequest := func(uuid string) *http.Request {
+		req := httptest.NewRequestWithContext(t.Context(), "GET", fmt.Sprintf("/v1/status/%s", uuid), nil)
+		req.SetPathValue("uuid", uuid)
+		return req
+	}
+
+	t.Run("online", func(t *testing.T) {
+		t.Parallel()
+
+		getStatus, called := makeGetStatus(t, domain.PlayerStatus{
+			UUID:      uuid,
+			QueriedAt: queriedAt,
+			Online:    true,
+			GameType:  new("BEDWARS"),
+			Mode:      new("BEDWARS_EIGHT_ONE"),
+			Map:       new("Lighthouse"),
+		}, nil)
+		handler := makeHandler(getStatus)
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, makeRequest("0123456789ABCDEF0123456789ABCDEF"))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		requireOpenAPIResponse(t, "GET /v1/status/{uuid}", w)
+		require.JSONEq(t, fmt.Sprintf(
+			`{"success":true,"uuid":"%s","online":true,"gameType":"BEDWARS","mode":"BEDWARS_EIGHT_ONE","map":"Lighthouse","queriedAt":"2026-10-18T12:00:00Z"}`,
+			uuid,
+		), w.Body.String())
+		require.True(t, *called)
+	})
+
+	t.Run("offline", func(t *testing.T) {
+		t.Parallel()
+
+		getStatus, _ := makeGetStatus(t, domain.PlayerStatus{
+			UUID:      uuid,
+			QueriedAt: queriedAt,
+		}, nil)
+		handler := makeHandler(getStatus)
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, makeRequest(uuid))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		requireOpenAPIResponse(t, "GET /v1/status/{uuid}", w)
+		require.JSONEq(t, fmt.Sprintf(
+			`{"success":true,"uuid":"%s","online":false,"queriedAt":"2026-10-18T12:00:00Z"}`,
+			uuid,
+		), w.Body.String())
+	})
+
+	t.Run("conditional get", func(t *testing.T) {
+		t.Parallel()
+
+		getStatus, _ := makeGetStatus(t, domain.PlayerStatus{UUID: uuid, QueriedAt: queriedAt}, nil)
+		handler := makeHandler(getStatus)
+
+		requireConditionalGET(t, "GET /v1/status/{uuid}", handler, func() *http.Request {
+			return makeRequest(uuid)
+		}, "private, max-age=30")
+	})
+
+	t.Run("temporarily unavailable", func(t *testing.T) {
+		t.Parallel()
+
+		getStatus, called := makeGetStatus(t, domain.PlayerStatus{}, domain.ErrTemporarilyUnavailable)
+		handler := makeHandler(getStatus)
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, makeRequest(uuid))
+
+		require.Equal(t, http.StatusServiceUnavailable, w.Code)
+		requireOpenAPIResponse(t, "GET /v1/status/{uuid}", w)
+		require.True(t, *called)
+	})
+
+	t.Run("invalid uuid", func(t *testing.T) {
+		t.Parallel()
+
+		getStatus, called := makeGetStatus(t, domain.PlayerStatus{}, nil)
+		handler := makeHandler(getStatus)
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, makeRequest("not-a-uuid"))
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		requireOpenAPIResponse(t, "GET /v1/status/{uuid}", w)
+		require.False(t, *called)
+	})
+}
@@ -7,11 +7,11 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"time"
 
 	"github.com/Amund211/flashlight/internal/app"
 	"github.com/Amund211/flashlight/internal/domain"
 	"github.com/Amund211/flashlight/internal/logging"
-	"github.com/Amund211/flashlight/internal/ratelimiting"
 	"github.com/Amund211/flashlight/internal/reporting"
 	"github.com/Amund211/flashlight/internal/strutils"
 )
@@ -32,6 +32,9 @@ type tagsResponse struct {
 	Sniping  string `json:"sniping"`
 }
 
+// tagsMaxAge is the TTL of the tags cache in main.go.
+const tagsMaxAge = 1 * time.Minute
+
 func MakeGetTagsHandler(
 	getTags app.GetTags,
 	registerUserVisit app.RegisterUserVisit,
@@ -40,46 +43,19 @@ func MakeGetTagsHandler(
 	bearerAuthMiddleware func(http.HandlerFunc) http.HandlerFunc,
 	blocklistConfig BlocklistConfig,
 ) (http.HandlerFunc, func()) {
-	ipLimiter, stopIPLimiter := ratelimiting.NewTokenBucketRateLimiter(
-		ratelimiting.RefillPerSecond(8),
-		ratelimiting.BurstSize(480),
-	)
-	ipRateLimiter := ratelimiting.NewRequestBasedRateLimiter(
-		ipLimiter,
-		IPHashKeyFunc,
-	)
-	userIDLimiter, stopUserIDLimiter := ratelimiting.NewTokenBucketRateLimiter(
-		ratelimiting.RefillPerSecond(2),
-		ratelimiting.BurstSize(120),
-	)
-	userIDRateLimiter := ratelimiting.NewRequestBasedRateLimiter(
-		// NOTE: Verified identity when there is one, user controlled value otherwise
-		userIDLimiter,
-		UserIDKeyFunc,
-	)
-
-	makeOnLimitExceeded := func(rateLimiter ratelimiting.RequestRateLimiter) http.HandlerFunc {
-		return func(w http.ResponseWriter, r *http.Request) {
-			ctx := r.Context()
-
-			statusCode := http.StatusTooManyRequests
-
-			logging.FromContext(ctx).InfoContext(ctx, "Rate limit exceeded", "statusCode", statusCode, "reason", "ratelimit exceeded", "key", rateLimiter.KeyFor(r))
-
-			http.Error(w, "Rate limit exceeded", statusCode)
-		}
-	}
-
-	middleware := ComposeMiddlewares(
-		NewRequestLoggerMiddleware(rootLogger),
+	middleware, stop := mustBuildRouteMiddleware(
+		RouteSpec{
+			Name:       "tags",
+			BearerAuth: bearerAuthMiddleware,
+			RateLimits: []RateLimit{
+				IPRateLimit(8, 480),
+				IdentityRateLimit(2, 120),
+			},
+			RegisterUserVisit: registerUserVisit,
+		},
+		rootLogger,
 		sentryMiddleware,
-		BuildBlocklistMiddleware(blocklistConfig),
-		buildMetricsMiddleware("tags"),
-		NewReportingMetaMiddleware("tags"),
-		NewRateLimitMiddleware(ipRateLimiter, makeOnLimitExceeded(ipRateLimiter)),
-		bearerAuthMiddleware,
-		NewRateLimitMiddleware(userIDRateLimiter, makeOnLimitExceeded(userIDRateLimiter)),
-		BuildRegisterUserVisitMiddleware(registerUserVisit),
+		blocklistConfig,
 	)
 
 	handler := func(w http.ResponseWriter, r *http.Request) {
@@ -106,7 +82,7 @@ func MakeGetTagsHandler(
 		if err != nil {
 			statusCode := http.StatusBadRequest
 			logging.FromContext(ctx).InfoContext(ctx, "Invalid uuid. Returning error", "statusCode", statusCode, "reason", "invalid uuid")
-			http.Error(w, "Invalid uuid", statusCode)
+			writeErrorResponse(ctx, w, badRequestError("Invalid uuid"))
 			return
 		}
 
@@ -125,15 +101,15 @@ func MakeGetTagsHandler(
 		tags, err := getTags(ctx, uuid, urchinAPIKey)
 		if errors.Is(err, domain.ErrTemporarilyUnavailable) {
 			logging.FromContext(ctx).ErrorContext(ctx, "Tags temporarily unavailable", "error", err)
-			http.Error(w, "Temporarily unavailable", http.StatusServiceUnavailable)
+			writeErrorResponse(ctx, w, apiErrorFromDomain(err, "Temporarily unavailable"))
 			return
 		} else if errors.Is(err, domain.ErrInvalidAPIKey) {
 			logging.FromContext(ctx).ErrorContext(ctx, "Invalid urchin API key", "error", err)
-			http.Error(w, "Invalid urchin API key. Fix it or remove the key.", http.StatusUnauthorized)
+			writeErrorResponse(ctx, w, newAPIError(http.StatusUnauthorized, errorCodeInvalidAPIKey, "Invalid urchin API key. Fix it or remove the key."))
 			return
 		} else if err != nil {
 			logging.FromContext(ctx).ErrorContext(ctx, "Error getting tags", "error", err)
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			writeErrorResponse(ctx, w, internalError())
 			return
 		}
 
@@ -144,25 +120,11 @@ func MakeGetTagsHandler(
 			err = fmt.Errorf("failed to convert tags to response: %w", err)
 			reporting.Report(ctx, err)
 
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
-			return
-		}
-
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		if _, err = w.Write(responseData); err != nil {
-			logging.FromContext(ctx).ErrorContext(ctx, "Failed to write response", "error", err)
-			reporting.Report(ctx, fmt.Errorf("failed to write tags response: %w", err))
-
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			writeErrorResponse(ctx, w, internalError())
 			return
 		}
 
-	}
-
-	stop := func() {
-		stopIPLimiter()
-		stopUserIDLimiter()
+		writeConditionalJSON(ctx, w, r, responseData, cacheControlFor(tagsMaxAge))
 	}
 
 	return middleware(handler), stop
@@ -81,11 +81,26 @@ func TestMakeGetTagsHandler(t *testing.T) {
 		handler.ServeHTTP(w, req)
 
 		require.Equal(t, http.StatusOK, w.Code)
+		requireOpenAPIResponse(t, "GET /v1/tags/{uuid}", w)
 		require.JSONEq(t, fmt.Sprintf(`{"uuid":"%s","tags":{"cheating":"none","sniping":"none"}}`, uuid), w.Body.String())
 		require.True(t, *called)
 		require.Equal(t, "application/json", w.Result().Header.Get("Content-Type"))
 	})
 
+	t.Run("conditional get", func(t *testing.T) {
+		t.Parallel()
+
+		getTagsFunc, _ := makeGetTags(t, uuid, domain.Tags{
+			Cheating: domain.TagSeverityMedium,
+			Sniping:  domain.TagSeverityNone,
+		}, nil)
+		handler := makeGetTagsHandler(getTagsFunc)
+
+		requireConditionalGET(t, "GET /v1/tags/{uuid}", handler, func() *http.Request {
+			return makeRequest(uuid)
+		}, "private, max-age=60")
+	})
+
 	t.Run("successful tags retrieval with medium severity", func(t *testing.T) {
 		t.Parallel()
 
@@ -103,6 +118,7 @@ func TestMakeGetTagsHandler(t *testing.T) {
 		handler.ServeHTTP(w, req)
 
 		require.Equal(t, http.StatusOK, w.Code)
+		requireOpenAPIResponse(t, "GET /v1/tags/{uuid}", w)
 		require.JSONEq(t, fmt.Sprintf(`{"uuid":"%s","tags":{"cheating":"medium","sniping":"none"}}`, uuid), w.Body.String())
 		require.True(t, *called)
 		require.Equal(t, "application/json", w.Result().Header.Get("Content-Type"))
@@ -125,6 +141,7 @@ func TestMakeGetTagsHandler(t *testing.T) {
 		handler.ServeHTTP(w, req)
 
 		require.Equal(t, http.StatusOK, w.Code)
+		requireOpenAPIResponse(t, "GET /v1/tags/{uuid}", w)
 		require.JSONEq(t, fmt.Sprintf(`{"uuid":"%s","tags":{"cheating":"high","sniping":"high"}}`, uuid), w.Body.String())
 		require.True(t, *called)
 		require.Equal(t, "application/json", w.Result().Header.Get("Content-Type"))
@@ -163,6 +180,7 @@ func TestMakeGetTagsHandler(t *testing.T) {
 		handler.ServeHTTP(w, req)
 
 		require.Equal(t, http.StatusOK, w.Code)
+		requireOpenAPIResponse(t, "GET /v1/tags/{uuid}", w)
 		require.JSONEq(t, fmt.Sprintf(`{"uuid":"%s","tags":{"cheating":"medium","sniping":"high"}}`, expectedUUID), w.Body.String())
 		require.True(t, called)
 		require.Equal(t, "application/json", w.Result().Header.Get("Content-Type"))
@@ -185,6 +203,7 @@ func TestMakeGetTagsHandler(t *testing.T) {
 		handler.ServeHTTP(w, req)
 
 		require.Equal(t, http.StatusOK, w.Code)
+		requireOpenAPIResponse(t, "GET /v1/tags/{uuid}", w)
 		require.JSONEq(t, fmt.Sprintf(`{"uuid":"%s","tags":{"cheating":"none","sniping":"none"}}`, uuid), w.Body.String())
 		require.True(t, *called)
 		require.Equal(t, "application/json", w.Result().Header.Get("Content-Type"))
@@ -204,6 +223,7 @@ func TestMakeGetTagsHandler(t *testing.T) {
 		handler.ServeHTTP(w, req)
 
 		require.Equal(t, http.StatusBadRequest, w.Code)
+		requireOpenAPIResponse(t, "GET /v1/tags/{uuid}", w)
 		require.Contains(t, w.Body.String(), "Invalid uuid")
 		require.False(t, *called)
 	})
@@ -220,6 +240,7 @@ func TestMakeGetTagsHandler(t *testing.T) {
 		handler.ServeHTTP(w, req)
 
 		require.Equal(t, http.StatusServiceUnavailable, w.Code)
+		requireOpenAPIResponse(t, "GET /v1/tags/{uuid}", w)
 		require.Contains(t, w.Body.String(), "Temporarily unavailable")
 		require.True(t, *called)
 	})
@@ -236,6 +257,7 @@ func TestMakeGetTagsHandler(t *testing.T) {
 		handler.ServeHTTP(w, req)
 
 		require.Equal(t, http.StatusInternalServerError, w.Code)
+		requireOpenAPIResponse(t, "GET /v1/tags/{uuid}", w)
 		require.Contains(t, w.Body.String(), "Internal server error")
 		require.True(t, *called)
 	})
@@ -252,6 +274,7 @@ func TestMakeGetTagsHandler(t *testing.T) {
 		handler.ServeHTTP(w, req)
 
 		require.Equal(t, http.StatusUnauthorized, w.Code)
+		requireOpenAPIResponse(t, "GET /v1/tags/{uuid}", w)
 		require.Contains(t, w.Body.String(), "Invalid urchin API key. Fix it or remove the key.")
 		require.True(t, *called)
 	})
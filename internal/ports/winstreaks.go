@@ -0,0 +1,180 @@
+package ports
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/Amund211/flashlight/internal/app"
+	"github.com/Amund211/flashlight/internal/domain"
+	"github.com/Amund211/flashlight/internal/logging"
+	"github.com/Amund211/flashlight/internal/reporting"
+	"github.com/Amund211/flashlight/internal/strutils"
+)
+
+// rainbowWinstreak is a winstreak reconstructed from the stats. When games
+// between two snapshots could have been in either order, the streak had
+// between wins and maxWins wins.
+type rainbowWinstreak struct {
+	Wins      int  `json:"wins"`
+	MaxWins   int  `json:"maxWins"`
+	Uncertain bool `json:"uncertain"`
+	// StartedAfter is nil when the streak started before the range
+	StartedAfter *time.Time `json:"startedAfter"`
+	HeldAt       time.Time  `json:"heldAt"`
+	// EndedBefore is nil when the streak is ongoing
+	EndedBefore *time.Time `json:"endedBefore"`
+}
+
+type rainbowWinstreaksResponse struct {
+	Gamemode   string             `json:"gamemode"`
+	Winstreaks []rainbowWinstreak `json:"winstreaks"`
+}
+
+func MakeGetWinstreaksHandler(
+	getWinstreaks app.GetWinstreaks,
+	registerUserVisit app.RegisterUserVisit,
+	allowedOrigins *DomainSuffixes,
+	rootLogger *slog.Logger,
+	sentryMiddleware func(http.HandlerFunc) http.HandlerFunc,
+	bearerAuthMiddleware func(http.HandlerFunc) http.HandlerFunc,
+	blocklistConfig BlocklistConfig,
+) (http.HandlerFunc, func()) {
+	middleware, stop := mustBuildRouteMiddleware(
+		RouteSpec{
+			Name:           "winstreaks",
+			AllowedOrigins: allowedOrigins,
+			BearerAuth:     bearerAuthMiddleware,
+			RateLimits: []RateLimit{
+				IPRateLimit(4, 80),
+				IdentityRateLimit(1, 20),
+			},
+			RegisterUserVisit: registerUserVisit,
+			Compress:          true,
+		},
+		rootLogger,
+		sentryMiddleware,
+		blocklistConfig,
+	)
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		defer r.Body.Close()
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, 4<<10))
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				writeErrorResponse(ctx, w, newAPIError(http.StatusRequestEntityTooLarge, errorCodeRequestTooLarge, "Request body too large"))
+				return
+			}
+			reporting.Report(ctx, fmt.Errorf("failed to read request body: %w", err))
+			writeErrorResponse(ctx, w, badRequestError("Failed to read request body"))
+			return
+		}
+		request := struct {
+			UUID     string    `json:"uuid"`
+			Start    time.Time `json:"start"`
+			End      time.Time `json:"end"`
+			Gamemode string    `json:"gamemode"`
+		}{}
+		err = json.Unmarshal(body, &request)
+		if err != nil {
+			logging.FromContext(ctx).WarnContext(ctx, "Failed to parse request body", "error", err)
+			writeErrorResponse(ctx, w, badRequestError("Failed to parse request body"))
+			return
+		}
+
+		ctx = reporting.AddExtrasToContext(ctx, map[string]string{
+			"start": request.Start.Format(time.RFC3339),
+			"end":   request.End.Format(time.RFC3339),
+		})
+
+		uuid, err := strutils.NormalizeUUID(request.UUID)
+		if err != nil {
+			logging.FromContext(ctx).WarnContext(ctx, "Failed to normalize uuid", "error", err, "rawUUID", request.UUID)
+			writeErrorResponse(ctx, w, badRequestError("invalid uuid"))
+			return
+		}
+
+		ctx = reporting.AddExtrasToContext(ctx, map[string]string{
+			"uuid": uuid,
+		})
+		ctx = logging.AddMetaToContext(ctx,
+			slog.String("uuid", uuid),
+			slog.String("start", request.Start.Format(time.RFC3339)),
+			slog.String("end", request.End.Format(time.RFC3339)),
+		)
+
+		if request.Start.After(request.End) {
+			writeErrorResponse(ctx, w, badRequestError("Start time cannot be after end time"))
+			return
+		}
+
+		if request.End.Sub(request.Start) >= 400*24*time.Hour {
+			writeErrorResponse(ctx, w, badRequestError("Time interval is too long"))
+			return
+		}
+
+		gamemode := domain.GamemodeOverall
+		if request.Gamemode != "" {
+			gamemode, err = rainbowGamemodeToGamemode(request.Gamemode)
+			if err != nil {
+				writeErrorResponse(ctx, w, badRequestError("invalid gamemode"))
+				return
+			}
+		}
+
+		logging.FromContext(ctx).InfoContext(ctx, "Handling winstreaks request",
+			slog.String("gamemode", string(gamemode)),
+		)
+
+		winstreaks, err := getWinstreaks(ctx, uuid, request.Start, request.End, gamemode)
+		if err != nil {
+			// NOTE: GetWinstreaks implementations handle their own error reporting
+			writeErrorResponse(ctx, w, apiErrorFromDomain(err, "Failed to get winstreaks"))
+			return
+		}
+
+		rainbowGamemode, err := gamemodeToRainbowGamemode(gamemode)
+		if err != nil {
+			reporting.Report(ctx, fmt.Errorf("failed to convert gamemode: %w", err))
+			writeErrorResponse(ctx, w, newAPIError(http.StatusInternalServerError, errorCodeInternal, "Failed to serialise response"))
+			return
+		}
+
+		response := rainbowWinstreaksResponse{
+			Gamemode:   rainbowGamemode,
+			Winstreaks: make([]rainbowWinstreak, 0, len(winstreaks)),
+		}
+		for _, winstreak := range winstreaks {
+			response.Winstreaks = append(response.Winstreaks, rainbowWinstreak{
+				Wins:         winstreak.Length,
+				MaxWins:      winstreak.MaxLength,
+				Uncertain:    winstreak.Uncertain(),
+				StartedAfter: winstreak.StartedAfter,
+				HeldAt:       winstreak.HeldAt,
+				EndedBefore:  winstreak.EndedBefore,
+			})
+		}
+
+		marshalled, err := json.Marshal(response)
+		if err != nil {
+			reporting.Report(ctx, fmt.Errorf("failed to marshal response: %w", err))
+			writeErrorResponse(ctx, w, newAPIError(http.StatusInternalServerError, errorCodeInternal, "Failed to marshal response"))
+			return
+		}
+
+		logging.FromContext(ctx).InfoContext(ctx, "Returning winstreaks", "winstreaks", len(response.Winstreaks))
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(marshalled)
+	}
+
+	return middleware(handler), stop
+}
@@ -0,0 +1,184 @@
+package ports_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Amund211/flashlight/internal/app"
+	"github.com/Amund211/flashlight/internal/domain"
+	"github.com/Amund211/flashlight/internal/ports"
+)
+
+func TestMakeGetWinstreaksHandler(t *testing.T) {
+	t.Parallel()
+
+	allowedOrigins, err := ports.NewDomainSuffixes("example.com", "test.com")
+	require.NoError(t, err)
+
+	testLogger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	noopMiddleware := func(h http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			h(w, r)
+		}
+	}
+
+	makeHandler := func(getWinstreaks app.GetWinstreaks) http.HandlerFunc {
+		stubRegisterUserVisit := func(ctx context.Context, userID string, ipHash string, userAgent string) (domain.User, error) {
+			return domain.User{}, nil
+		}
+		handler, stop := ports.MakeGetWinstreaksHandler(
+			getWinstreaks,
+			stubRegisterUserVisit,
+			allowedOrigins,
+			testLogger,
+			noopMiddleware,
+			noopMiddleware,
+			emptyBlocklistConfig,
+		)
+		t.Cleanup(stop)
+		return handler
+	}
+
+	makeRequest := func(body string) *http.Request {
+		return httptest.NewRequestWithContext(t.Context(), "POST", "/v1/winstreaks", strings.NewReader(body))
+	}
+
+	uuid := "01234567-89ab-cdef-0123-456789abcdef"
+	start := time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 6, 16, 0, 0, 0, 0, time.UTC)
+	rangeBody := `"start":"2024-06-15T00:00:00Z","end":"2024-06-16T00:00:00Z"`
+	uuidBody := `"uuid":"` + strings.ReplaceAll(uuid, "-", "") + `"`
+
+	winstreaks := []domain.ReconstructedWinstreak{
+		{
+			StreakEstimate: domain.StreakEstimate{Length: 12, MaxLength: 12},
+			HeldAt:         start.Add(1 * time.Hour),
+			EndedBefore:    new(start.Add(2 * time.Hour)),
+		},
+		{
+			StreakEstimate: domain.StreakEstimate{Length: 2, MaxLength: 5},
+			StartedAfter:   new(start.Add(2 * time.Hour)),
+			HeldAt:         start.Add(3 * time.Hour),
+		},
+	}
+
+	t.Run("renders the winstreaks", func(t *testing.T) {
+		t.Parallel()
+
+		handler := makeHandler(func(ctx context.Context, gotUUID string, gotStart, gotEnd time.Time, gamemode domain.Gamemode) ([]domain.ReconstructedWinstreak, error) {
+			require.Equal(t, uuid, gotUUID)
+			require.True(t, start.Equal(gotStart))
+			require.True(t, end.Equal(gotEnd))
+			require.Equal(t, domain.GamemodeFours, gamemode)
+			return winstreaks, nil
+		})
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, makeRequest(`{`+uuidBody+`,`+rangeBody+`,"gamemode":"fours"}`))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		requireOpenAPIResponse(t, "POST /v1/winstreaks", w)
+		require.JSONEq(t, `
+		{
+			"gamemode": "fours",
+			"winstreaks": [
+				{
+					"wins": 12,
+					"maxWins": 12,
+					"uncertain": false,
+					"startedAfter": null,
+					"heldAt": "2024-06-15T01:00:00Z",
+					"endedBefore": "2024-06-15T02:00:00Z"
+				},
+				{
+					"wins": 2,
+					"maxWins": 5,
+					"uncertain": true,
+					"startedAfter": "2024-06-15T02:00:00Z",
+					"heldAt": "2024-06-15T03:00:00Z",
+					"endedBefore": null
+				}
+			]
+		}`, w.Body.String())
+	})
+
+	t.Run("defaults to overall", func(t *testing.T) {
+		t.Parallel()
+
+		handler := makeHandler(func(ctx context.Context, _ string, _, _ time.Time, gamemode domain.Gamemode) ([]domain.ReconstructedWinstreak, error) {
+			require.Equal(t, domain.GamemodeOverall, gamemode)
+			return []domain.ReconstructedWinstreak{}, nil
+		})
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, makeRequest(`{`+uuidBody+`,`+rangeBody+`}`))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		requireOpenAPIResponse(t, "POST /v1/winstreaks", w)
+		require.JSONEq(t, `{"gamemode": "overall", "winstreaks": []}`, w.Body.String())
+	})
+
+	t.Run("bad requests", func(t *testing.T) {
+		t.Parallel()
+
+		for name, body := range map[string]string{
+			"invalid uuid":     `{"uuid":"nope",` + rangeBody + `}`,
+			"start after end":  `{` + uuidBody + `,"start":"2024-06-16T00:00:00Z","end":"2024-06-15T00:00:00Z"}`,
+			"range too long":   `{` + uuidBody + `,"start":"2023-01-01T00:00:00Z","end":"2024-06-15T00:00:00Z"}`,
+			"invalid gamemode": `{` + uuidBody + `,` + rangeBody + `,"gamemode":"eights"}`,
+			"malformed body":   `{`,
+		} {
+			t.Run(name, func(t *testing.T) {
+				t.Parallel()
+
+				called := false
+				handler := makeHandler(func(context.Context, string, time.Time, time.Time, domain.Gamemode) ([]domain.ReconstructedWinstreak, error) {
+					called = true
+					return nil, nil
+				})
+
+				w := httptest.NewRecorder()
+				handler.ServeHTTP(w, makeRequest(body))
+
+				require.Equal(t, http.StatusBadRequest, w.Code)
+				requireOpenAPIResponse(t, "POST /v1/winstreaks", w)
+				require.False(t, called)
+			})
+		}
+	})
+
+	t.Run("app errors", func(t *testing.T) {
+		t.Parallel()
+
+		for name, tc := range map[string]struct {
+			err  error
+			code int
+		}{
+			"internal":    {err: errors.New("db down"), code: http.StatusInternalServerError},
+			"unavailable": {err: domain.ErrTemporarilyUnavailable, code: http.StatusServiceUnavailable},
+		} {
+			t.Run(name, func(t *testing.T) {
+				t.Parallel()
+
+				handler := makeHandler(func(context.Context, string, time.Time, time.Time, domain.Gamemode) ([]domain.ReconstructedWinstreak, error) {
+					return nil, tc.err
+				})
+
+				w := httptest.NewRecorder()
+				handler.ServeHTTP(w, makeRequest(`{`+uuidBody+`,`+rangeBody+`}`))
+
+				require.Equal(t, tc.code, w.Code)
+				requireOpenAPIResponse(t, "POST /v1/winstreaks", w)
+			})
+		}
+	})
+}
@@ -13,7 +13,6 @@ import (
 	"github.com/Amund211/flashlight/internal/app"
 	"github.com/Amund211/flashlight/internal/domain"
 	"github.com/Amund211/flashlight/internal/logging"
-	"github.com/Amund211/flashlight/internal/ratelimiting"
 	"github.com/Amund211/flashlight/internal/reporting"
 	"github.com/Amund211/flashlight/internal/strutils"
 )
@@ -26,14 +25,19 @@ type wrappedResponse struct {
 	NonConsecutiveSessions int                       `json:"nonConsecutiveSessions"`
 	YearStats              *yearBoundaryStatsRainbow `json:"yearStats,omitempty"`
 	SessionStats           *sessionStats             `json:"sessionStats,omitempty"`
-	Cause                  string                    `json:"cause,omitempty"`
 }
 
 // sessionStats contains statistics computed from consecutive sessions
 // This field is only present when there is at least one consecutive session
 type sessionStats struct {
+	sessionAnalysis
+	SessionsPerMonth map[int]int `json:"sessionsPerMonth"`
+}
+
+// sessionAnalysis is the part of the session statistics that doesn't depend
+// on the length of the period, shared with wrapped for other periods
+type sessionAnalysis struct {
 	SessionLengths       sessionLengthStats        `json:"sessionLengths"`
-	SessionsPerMonth     map[int]int               `json:"sessionsPerMonth"`
 	BestSessions         bestSessionsStatsRainbow  `json:"bestSessions"`
 	Averages             averageStats              `json:"averages"`
 	Winstreaks           winstreakStats            `json:"winstreaks"`
@@ -136,8 +140,11 @@ type winstreakStats struct {
 }
 
 type gamemodeWinstreak struct {
-	Highest int       `json:"highest"`
-	When    time.Time `json:"when"`
+	Highest int `json:"highest"`
+	// Uncertain is set when games between two stats could have made the
+	// streak longer than Highest
+	Uncertain bool      `json:"uncertain"`
+	When      time.Time `json:"when"`
 }
 
 type finalKillStreakStats struct {
@@ -150,8 +157,11 @@ type finalKillStreakStats struct {
 }
 
 type gamemodeFinalKillStreak struct {
-	Highest int       `json:"highest"`
-	When    time.Time `json:"when"`
+	Highest int `json:"highest"`
+	// Uncertain is set when games between two stats could have made the
+	// streak longer than Highest
+	Uncertain bool      `json:"uncertain"`
+	When      time.Time `json:"when"`
 }
 
 type coverageStats struct {
@@ -176,8 +186,7 @@ type playtimeDistributionStats struct {
 }
 
 func MakeGetWrappedHandler(
-	getPlayerPITs app.GetPlayerPITs,
-	computeSessions app.ComputeSessions,
+	getWrapped app.GetWrapped,
 	registerUserVisit app.RegisterUserVisit,
 	allowedOrigins *DomainSuffixes,
 	rootLogger *slog.Logger,
@@ -185,43 +194,21 @@ func MakeGetWrappedHandler(
 	bearerAuthMiddleware func(http.HandlerFunc) http.HandlerFunc,
 	blocklistConfig BlocklistConfig,
 ) (http.HandlerFunc, func()) {
-	ipLimiter, stopIPLimiter := ratelimiting.NewTokenBucketRateLimiter(
-		ratelimiting.RefillPerSecond(4),
-		ratelimiting.BurstSize(240),
-	)
-	ipRateLimiter := ratelimiting.NewRequestBasedRateLimiter(
-		ipLimiter,
-		IPHashKeyFunc,
-	)
-	userIDLimiter, stopUserIDLimiter := ratelimiting.NewTokenBucketRateLimiter(
-		ratelimiting.RefillPerSecond(1),
-		ratelimiting.BurstSize(60),
-	)
-	userIDRateLimiter := ratelimiting.NewRequestBasedRateLimiter(
-		// NOTE: Verified identity when there is one, user controlled value otherwise
-		userIDLimiter,
-		UserIDKeyFunc,
-	)
-
-	makeOnLimitExceeded := func(rateLimiter ratelimiting.RequestRateLimiter) http.HandlerFunc {
-		return func(w http.ResponseWriter, r *http.Request) {
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusTooManyRequests)
-			w.Write([]byte(`{"success":false,"cause":"Rate limit exceeded"}`))
-		}
-	}
-
-	middleware := ComposeMiddlewares(
-		NewRequestLoggerMiddleware(rootLogger),
+	middleware, stop := mustBuildRouteMiddleware(
+		RouteSpec{
+			Name:           "wrapped",
+			AllowedOrigins: allowedOrigins,
+			BearerAuth:     bearerAuthMiddleware,
+			RateLimits: []RateLimit{
+				IPRateLimit(4, 240),
+				IdentityRateLimit(1, 60),
+			},
+			RegisterUserVisit: registerUserVisit,
+			Compress:          true,
+		},
+		rootLogger,
 		sentryMiddleware,
-		BuildBlocklistMiddleware(blocklistConfig),
-		buildMetricsMiddleware("wrapped"),
-		NewReportingMetaMiddleware("wrapped"),
-		BuildCORSMiddleware(allowedOrigins),
-		NewRateLimitMiddleware(ipRateLimiter, makeOnLimitExceeded(ipRateLimiter)),
-		bearerAuthMiddleware,
-		NewRateLimitMiddleware(userIDRateLimiter, makeOnLimitExceeded(userIDRateLimiter)),
-		BuildRegisterUserVisitMiddleware(registerUserVisit),
+		blocklistConfig,
 	)
 
 	handler := func(w http.ResponseWriter, r *http.Request) {
@@ -250,19 +237,13 @@ func MakeGetWrappedHandler(
 
 		uuid, err := strutils.NormalizeUUID(rawUUID)
 		if err != nil {
-			statusCode := http.StatusBadRequest
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(statusCode)
-			w.Write([]byte(`{"success":false,"cause":"invalid uuid"}`))
+			writeErrorResponse(ctx, w, badRequestError("invalid uuid"))
 			return
 		}
 
 		year, err := strconv.Atoi(rawYear)
 		if err != nil || year < 2000 || year > 3000 {
-			statusCode := http.StatusBadRequest
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(statusCode)
-			w.Write([]byte(`{"success":false,"cause":"invalid year"}`))
+			writeErrorResponse(ctx, w, badRequestError("invalid year"))
 			return
 		}
 
@@ -274,10 +255,7 @@ func MakeGetWrappedHandler(
 		location, err := time.LoadLocation(timezoneStr)
 		if err != nil {
 			logging.FromContext(ctx).WarnContext(ctx, "Invalid timezone", "tzstring", timezoneStr, "err", err)
-			statusCode := http.StatusBadRequest
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(statusCode)
-			w.Write([]byte(`{"success":false,"cause":"invalid timezone"}`))
+			writeErrorResponse(ctx, w, badRequestError("invalid timezone"))
 			return
 		}
 		ctx = logging.AddMetaToContext(ctx,
@@ -292,30 +270,21 @@ func MakeGetWrappedHandler(
 			slog.Int("parsedYear", year),
 		)
 
-		// NOTE: 24-hour padding to ensure we can complete sessions at year boundaries
-		playerPITsStart := time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC).Add(-24 * time.Hour)
-		playerPITsEnd := time.Date(year+1, 1, 1, 0, 0, 0, 0, time.UTC).Add(-time.Nanosecond).Add(24 * time.Hour)
-		playerPITs, err := getPlayerPITs(ctx, uuid, playerPITsStart, playerPITsEnd)
+		wrapped, err := getWrapped(ctx, uuid, year)
 		if err != nil {
-			// NOTE: GetPlayerPITs implementations handle their own error reporting
-			statusCode := http.StatusInternalServerError
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(statusCode)
-			w.Write([]byte(`{"success":false,"cause":"failed to get player data"}`))
+			// NOTE: GetWrapped implementations handle their own error reporting
+			writeErrorResponse(ctx, w, apiErrorFromDomain(err, "failed to get player data"))
 			return
 		}
 
-		wrappedData := computeWrappedStats(ctx, computeSessions, playerPITs, year, location)
-		wrappedData.Success = true
-		wrappedData.UUID = uuid
+		response := wrappedToResponse(ctx, wrapped, year, location)
+		response.Success = true
+		response.UUID = uuid
 
-		marshalled, err := json.Marshal(wrappedData)
+		marshalled, err := json.Marshal(response)
 		if err != nil {
 			reporting.Report(ctx, fmt.Errorf("failed to marshal wrapped response: %w", err))
-			statusCode := http.StatusInternalServerError
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(statusCode)
-			w.Write([]byte(`{"success":false,"cause":"failed to marshal response"}`))
+			writeErrorResponse(ctx, w, internalError())
 			return
 		}
 
@@ -326,20 +295,49 @@ func MakeGetWrappedHandler(
 		w.Write(marshalled)
 	}
 
-	stop := func() {
-		stopIPLimiter()
-		stopUserIDLimiter()
-	}
-
 	return middleware(handler), stop
 }
 
-func computeWrappedStats(ctx context.Context, computeSessions app.ComputeSessions, playerPITs []domain.PlayerPIT, year int, location *time.Location) wrappedResponse {
-	yearStart := time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
-	yearEnd := time.Date(year+1, 1, 1, 0, 0, 0, 0, time.UTC).Add(-time.Nanosecond)
-	sessions := computeSessions(ctx, playerPITs, yearStart, yearEnd)
+// wrappedToResponse computes the wrapped response from the player's wrapped
+func wrappedToResponse(ctx context.Context, wrapped app.Wrapped, year int, location *time.Location) wrappedResponse {
+	var boundaryStats *yearBoundaryStats
+	if wrapped.YearStart != nil && wrapped.YearEnd != nil {
+		boundaryStats = &yearBoundaryStats{
+			Start: wrapped.YearStart,
+			End:   wrapped.YearEnd,
+		}
+	}
+
+	response := wrappedResponse{
+		Year:                   year,
+		TotalSessions:          len(wrapped.Sessions),
+		NonConsecutiveSessions: wrapped.NonConsecutiveSessions,
+		YearStats:              boundaryStats.ToRainbow(),
+	}
+
+	if len(wrapped.Sessions) == 0 || wrapped.Winstreaks == nil || wrapped.FinalKillStreaks == nil {
+		return response
+	}
+
+	// Compute all session-dependent statistics
+	response.SessionStats = &sessionStats{
+		sessionAnalysis: computeSessionAnalysis(
+			ctx,
+			wrapped.Sessions,
+			toWinstreakStats(*wrapped.Winstreaks),
+			toFinalKillStreakStats(*wrapped.FinalKillStreaks),
+			boundaryStats,
+			location,
+		),
+		SessionsPerMonth: computeSessionsPerMonth(ctx, wrapped.Sessions, year).ToRainbow(),
+	}
+
+	return response
+}
 
-	// Filter to consecutive sessions only
+// splitConsecutiveSessions returns the consecutive sessions, and how many
+// were left out
+func splitConsecutiveSessions(sessions []domain.Session) ([]domain.Session, int) {
 	consecutiveSessions := make([]domain.Session, 0, len(sessions))
 	nonConsecutiveCount := 0
 
@@ -351,45 +349,41 @@ func computeWrappedStats(ctx context.Context, computeSessions app.ComputeSession
 		}
 	}
 
-	yearBoundaryStats := computeYearBoundaryStats(ctx, playerPITs, year)
-
-	response := wrappedResponse{
-		Year:                   year,
-		TotalSessions:          len(consecutiveSessions),
-		NonConsecutiveSessions: nonConsecutiveCount,
-		YearStats:              yearBoundaryStats.ToRainbow(),
-	}
+	return consecutiveSessions, nonConsecutiveCount
+}
 
-	if len(consecutiveSessions) == 0 {
-		return response
+// computeSessionAnalysis computes the statistics that don't depend on the
+// length of the period
+// Assumes at least one session exists
+func computeSessionAnalysis(ctx context.Context, sessions []domain.Session, winstreaks winstreakStats, finalKillStreaks finalKillStreakStats, boundaryStats *yearBoundaryStats, location *time.Location) sessionAnalysis {
+	// Wrapped doesn't segment games, so no games are attributed
+	summaries := make([]domain.SessionSummary, 0, len(sessions))
+	for i := range sessions {
+		summaries = append(summaries, domain.NewSessionSummary(&sessions[i], 0))
 	}
 
-	sessionLengths := computeSessionLengths(ctx, consecutiveSessions)
+	sessionLengths := computeSessionLengths(ctx, summaries)
 
-	// Compute all session-dependent statistics
-	response.SessionStats = &sessionStats{
+	return sessionAnalysis{
 		SessionLengths:       sessionLengths,
-		SessionsPerMonth:     computeSessionsPerMonth(ctx, consecutiveSessions, year).ToRainbow(),
-		BestSessions:         computeBestSessions(ctx, consecutiveSessions).ToRainbow(),
-		Averages:             computeAverages(ctx, consecutiveSessions),
-		Winstreaks:           computeWinstreaks(ctx, playerPITs),
-		FinalKillStreaks:     computeFinalKillStreaks(ctx, playerPITs),
-		SessionCoverage:      computeCoverage(ctx, consecutiveSessions, yearBoundaryStats, sessionLengths.TotalHours),
-		FlawlessSessions:     computeFlawlessSessions(ctx, consecutiveSessions),
-		PlaytimeDistribution: computePlaytimeDistribution(ctx, consecutiveSessions, location),
+		BestSessions:         computeBestSessions(ctx, sessions, summaries).ToRainbow(),
+		Averages:             computeAverages(ctx, summaries),
+		Winstreaks:           winstreaks,
+		FinalKillStreaks:     finalKillStreaks,
+		SessionCoverage:      computeCoverage(ctx, summaries, boundaryStats, sessionLengths.TotalHours),
+		FlawlessSessions:     computeFlawlessSessions(ctx, summaries),
+		PlaytimeDistribution: computePlaytimeDistribution(ctx, sessions, location),
 	}
-
-	return response
 }
 
 // computeSessionLengths calculates session length statistics
 // Assumes at least one session exists
-func computeSessionLengths(ctx context.Context, sessions []domain.Session) sessionLengthStats {
+func computeSessionLengths(ctx context.Context, summaries []domain.SessionSummary) sessionLengthStats {
 	var total, longest, shortest float64
 	shortest = -1
 
-	for _, session := range sessions {
-		duration := session.End.QueriedAt.Sub(session.Start.QueriedAt).Hours()
+	for _, summary := range summaries {
+		duration := summary.Duration.Hours()
 		total += duration
 		if duration > longest {
 			longest = duration
@@ -403,7 +397,7 @@ func computeSessionLengths(ctx context.Context, sessions []domain.Session) sessi
 		TotalHours:    total,
 		LongestHours:  longest,
 		ShortestHours: shortest,
-		AverageHours:  total / float64(len(sessions)),
+		AverageHours:  total / float64(len(summaries)),
 	}
 }
 
@@ -431,18 +425,20 @@ func computeSessionsPerMonth(ctx context.Context, sessions []domain.Session, yea
 	return counts
 }
 
-func maxSession[T cmp.Ordered](sessions []domain.Session, getValue func(*domain.Session) T, include func(*domain.Session) bool) *domain.Session {
+// maxSession returns the session whose summary has the highest value, among
+// the included ones. summaries holds the summary of each session.
+func maxSession[T cmp.Ordered](sessions []domain.Session, summaries []domain.SessionSummary, getValue func(*domain.SessionSummary) T, include func(*domain.SessionSummary) bool) *domain.Session {
 	var bestSession *domain.Session
 	var bestValue T
 
 	for i := range sessions {
-		session := &sessions[i]
-		if include != nil && !include(session) {
+		summary := &summaries[i]
+		if include != nil && !include(summary) {
 			continue
 		}
-		value := getValue(session)
+		value := getValue(summary)
 		if bestSession == nil || value > bestValue {
-			bestSession = session
+			bestSession = &sessions[i]
 			bestValue = value
 		}
 	}
@@ -451,50 +447,33 @@ func maxSession[T cmp.Ordered](sessions []domain.Session, getValue func(*domain.
 }
 
 // Assumes at least one session exists
-func computeBestSessions(ctx context.Context, sessions []domain.Session) bestSessionsStats {
+func computeBestSessions(ctx context.Context, sessions []domain.Session, summaries []domain.SessionSummary) bestSessionsStats {
 	minSessionDuration := 30 * time.Minute
 
-	bestKills := maxSession(sessions, func(s *domain.Session) int {
-		return s.End.Overall.Kills - s.Start.Overall.Kills
+	bestKills := maxSession(sessions, summaries, func(s *domain.SessionSummary) int {
+		return s.Overall.Kills
 	}, nil)
-	bestFinals := maxSession(sessions, func(s *domain.Session) int {
-		return s.End.Overall.FinalKills - s.Start.Overall.FinalKills
+	bestFinals := maxSession(sessions, summaries, func(s *domain.SessionSummary) int {
+		return s.Overall.FinalKills
 	}, nil)
-	bestWins := maxSession(sessions, func(s *domain.Session) int {
-		return s.End.Overall.Wins - s.Start.Overall.Wins
+	bestWins := maxSession(sessions, summaries, func(s *domain.SessionSummary) int {
+		return s.Overall.Wins
 	}, nil)
-	bestLongest := maxSession(sessions, func(s *domain.Session) time.Duration {
-		return s.End.QueriedAt.Sub(s.Start.QueriedAt)
+	bestLongest := maxSession(sessions, summaries, func(s *domain.SessionSummary) time.Duration {
+		return s.Duration
 	}, nil)
-	bestFKDR := maxSession(sessions, func(s *domain.Session) float64 {
-		finals := s.End.Overall.FinalKills - s.Start.Overall.FinalKills
-		deaths := s.End.Overall.FinalDeaths - s.Start.Overall.FinalDeaths
-
-		// If no final deaths, use final kills as FKDR
-		fkdr := float64(finals)
-		if deaths > 0 {
-			fkdr = float64(finals) / float64(deaths)
-		}
-		return fkdr
+	bestFKDR := maxSession(sessions, summaries, func(s *domain.SessionSummary) float64 {
+		return s.Overall.FKDR
 	}, nil)
-	bestWinsPerHour := maxSession(sessions, func(s *domain.Session) float64 {
-		duration := s.End.QueriedAt.Sub(s.Start.QueriedAt)
-		durationHours := duration.Hours()
-		wins := s.End.Overall.Wins - s.Start.Overall.Wins
-		return float64(wins) / durationHours
-	}, func(s *domain.Session) bool {
-		duration := s.End.QueriedAt.Sub(s.Start.QueriedAt)
-		return duration >= minSessionDuration
-	})
-	bestFinalsPerHour := maxSession(sessions, func(s *domain.Session) float64 {
-		duration := s.End.QueriedAt.Sub(s.Start.QueriedAt)
-		durationHours := duration.Hours()
-		finals := s.End.Overall.FinalKills - s.Start.Overall.FinalKills
-		return float64(finals) / durationHours
-	}, func(s *domain.Session) bool {
-		duration := s.End.QueriedAt.Sub(s.Start.QueriedAt)
-		return duration >= minSessionDuration
-	})
+	longEnough := func(s *domain.SessionSummary) bool {
+		return s.Duration >= minSessionDuration
+	}
+	bestWinsPerHour := maxSession(sessions, summaries, func(s *domain.SessionSummary) float64 {
+		return float64(s.Overall.Wins) / s.Duration.Hours()
+	}, longEnough)
+	bestFinalsPerHour := maxSession(sessions, summaries, func(s *domain.SessionSummary) float64 {
+		return float64(s.Overall.FinalKills) / s.Duration.Hours()
+	}, longEnough)
 
 	return bestSessionsStats{
 		HighestFKDR:       *bestFKDR,
@@ -509,19 +488,18 @@ func computeBestSessions(ctx context.Context, sessions []domain.Session) bestSes
 
 // computeAverages calculates average statistics across all sessions
 // Assumes at least one session exists
-func computeAverages(ctx context.Context, sessions []domain.Session) averageStats {
+func computeAverages(ctx context.Context, summaries []domain.SessionSummary) averageStats {
 	var totalDuration float64
 	var totalGames, totalWins, totalFinalKills int
 
-	for _, session := range sessions {
-		duration := session.End.QueriedAt.Sub(session.Start.QueriedAt).Hours()
-		totalDuration += duration
-		totalGames += session.End.Overall.GamesPlayed - session.Start.Overall.GamesPlayed
-		totalWins += session.End.Overall.Wins - session.Start.Overall.Wins
-		totalFinalKills += session.End.Overall.FinalKills - session.Start.Overall.FinalKills
+	for _, summary := range summaries {
+		totalDuration += summary.Duration.Hours()
+		totalGames += summary.Overall.GamesPlayed
+		totalWins += summary.Overall.Wins
+		totalFinalKills += summary.Overall.FinalKills
 	}
 
-	numSessions := float64(len(sessions))
+	numSessions := float64(len(summaries))
 	return averageStats{
 		SessionLength: totalDuration / numSessions,
 		GamesPlayed:   float64(totalGames) / numSessions,
@@ -532,18 +510,23 @@ func computeAverages(ctx context.Context, sessions []domain.Session) averageStat
 
 // computeYearBoundaryStats finds the first and last player stats in the year
 func computeYearBoundaryStats(ctx context.Context, playerPITs []domain.PlayerPIT, year int) *yearBoundaryStats {
+	yearStart := time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
+	nextYearStart := time.Date(year+1, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	return computeBoundaryStats(ctx, playerPITs, yearStart, nextYearStart)
+}
+
+// computeBoundaryStats finds the first and last player stats in [start, end)
+func computeBoundaryStats(ctx context.Context, playerPITs []domain.PlayerPIT, start, end time.Time) *yearBoundaryStats {
 	if len(playerPITs) == 0 {
 		return nil
 	}
 
-	yearStart := time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
-	yearEnd := time.Date(year+1, 1, 1, 0, 0, 0, 0, time.UTC).Add(-time.Nanosecond)
-
 	var firstPIT, lastPIT *domain.PlayerPIT
 
 	for i := range playerPITs {
 		pit := &playerPITs[i]
-		if pit.QueriedAt.Before(yearStart) || pit.QueriedAt.After(yearEnd) {
+		if pit.QueriedAt.Before(start) || !pit.QueriedAt.Before(end) {
 			continue
 		}
 
@@ -565,136 +548,48 @@ func computeYearBoundaryStats(ctx context.Context, playerPITs []domain.PlayerPIT
 	}
 }
 
-// computeWinstreaks calculates the highest ended winstreak for each gamemode during the year
-// Assumes at least one playerPIT exists
-func computeWinstreaks(ctx context.Context, playerPITs []domain.PlayerPIT) winstreakStats {
-	result := winstreakStats{}
-
-	// Helper to compute winstreak for a gamemode
-	computeGamemodeWinstreak := func(getStats func(*domain.PlayerPIT) domain.GamemodeStatsPIT) *gamemodeWinstreak {
-		firstPlayer := &playerPITs[0]
-		firstStats := getStats(firstPlayer)
-
-		maxEndedStreak := 0
-		maxStreakLastHeld := firstPlayer.QueriedAt
-
-		currentStreak := 0
-		prevWins := firstStats.Wins
-		prevLosses := firstStats.Losses
-		prevQueriedAt := firstPlayer.QueriedAt
-
-		for i := range playerPITs {
-			pit := &playerPITs[i]
-			stats := getStats(pit)
-
-			wins := stats.Wins
-			losses := stats.Losses
-
-			winsGained := wins - prevWins
-			lossesGained := losses - prevLosses
-
-			if lossesGained > 0 {
-				// Streak broken
-
-				// Don't count ongoing streaks
-				if currentStreak > maxEndedStreak {
-					maxEndedStreak = currentStreak
-					maxStreakLastHeld = prevQueriedAt
-				}
-
-				currentStreak = 0
-			} else if winsGained > 0 {
-				// Streak continues/starts
-				currentStreak += winsGained
-			}
-
-			prevWins = wins
-			prevLosses = losses
-			prevQueriedAt = pit.QueriedAt
-		}
-
-		return &gamemodeWinstreak{
-			Highest: maxEndedStreak,
-			When:    maxStreakLastHeld,
-		}
+func toWinstreakStats(streaks app.GamemodeStreaks) winstreakStats {
+	toStreak := func(streak app.Streak) *gamemodeWinstreak {
+		return &gamemodeWinstreak{Highest: streak.Highest, Uncertain: streak.Uncertain, When: streak.When}
 	}
+	return winstreakStats{
+		Overall: toStreak(streaks.Overall),
+		Solo:    toStreak(streaks.Solo),
+		Doubles: toStreak(streaks.Doubles),
+		Threes:  toStreak(streaks.Threes),
+		Fours:   toStreak(streaks.Fours),
+		Fourv4:  toStreak(streaks.Fourv4),
+	}
+}
 
-	result.Overall = computeGamemodeWinstreak(func(p *domain.PlayerPIT) domain.GamemodeStatsPIT { return p.Overall })
-	result.Solo = computeGamemodeWinstreak(func(p *domain.PlayerPIT) domain.GamemodeStatsPIT { return p.Solo })
-	result.Doubles = computeGamemodeWinstreak(func(p *domain.PlayerPIT) domain.GamemodeStatsPIT { return p.Doubles })
-	result.Threes = computeGamemodeWinstreak(func(p *domain.PlayerPIT) domain.GamemodeStatsPIT { return p.Threes })
-	result.Fours = computeGamemodeWinstreak(func(p *domain.PlayerPIT) domain.GamemodeStatsPIT { return p.Fours })
-	result.Fourv4 = computeGamemodeWinstreak(func(p *domain.PlayerPIT) domain.GamemodeStatsPIT { return p.Fourv4 })
+func toFinalKillStreakStats(streaks app.GamemodeStreaks) finalKillStreakStats {
+	toStreak := func(streak app.Streak) *gamemodeFinalKillStreak {
+		return &gamemodeFinalKillStreak{Highest: streak.Highest, Uncertain: streak.Uncertain, When: streak.When}
+	}
+	return finalKillStreakStats{
+		Overall: toStreak(streaks.Overall),
+		Solo:    toStreak(streaks.Solo),
+		Doubles: toStreak(streaks.Doubles),
+		Threes:  toStreak(streaks.Threes),
+		Fours:   toStreak(streaks.Fours),
+		Fourv4:  toStreak(streaks.Fourv4),
+	}
+}
 
-	return result
+// computeWinstreaks calculates the highest ended winstreak for each gamemode during the year
+// Assumes at least one playerPIT exists
+func computeWinstreaks(ctx context.Context, playerPITs []domain.PlayerPIT) winstreakStats {
+	return toWinstreakStats(app.ComputeWinstreaks(playerPITs))
 }
 
 // computeFinalKillStreaks calculates the highest final kill streak for each gamemode during the year
 // Assumes at least one playerPIT exists
 func computeFinalKillStreaks(ctx context.Context, playerPITs []domain.PlayerPIT) finalKillStreakStats {
-	result := finalKillStreakStats{}
-
-	// Helper to compute final kill streak for a gamemode
-	computeGamemodeFKStreak := func(getStats func(*domain.PlayerPIT) domain.GamemodeStatsPIT) *gamemodeFinalKillStreak {
-		firstPlayer := &playerPITs[0]
-		firstStats := getStats(firstPlayer)
-
-		maxEndedStreak := 0
-		maxStreakEndedAt := firstPlayer.QueriedAt
-
-		currentStreak := 0
-		prevFKills := firstStats.FinalKills
-		prevFDeaths := firstStats.FinalDeaths
-		prevQueriedAt := firstPlayer.QueriedAt
-
-		for i := range playerPITs {
-			pit := &playerPITs[i]
-			stats := getStats(pit)
-
-			fkills := stats.FinalKills
-			fdeaths := stats.FinalDeaths
-
-			fkillsGained := fkills - prevFKills
-			fdeathsGained := fdeaths - prevFDeaths
-
-			if fdeathsGained > 0 {
-				// Streak broken
-
-				// Don't count ongoing streaks
-				if currentStreak > maxEndedStreak {
-					maxEndedStreak = currentStreak
-					maxStreakEndedAt = prevQueriedAt
-				}
-
-				currentStreak = 0
-			} else if fkillsGained > 0 {
-				// Streak continues/starts
-				currentStreak += fkillsGained
-			}
-
-			prevFKills = fkills
-			prevFDeaths = fdeaths
-			prevQueriedAt = pit.QueriedAt
-		}
-
-		return &gamemodeFinalKillStreak{
-			Highest: maxEndedStreak,
-			When:    maxStreakEndedAt,
-		}
-	}
-
-	result.Overall = computeGamemodeFKStreak(func(p *domain.PlayerPIT) domain.GamemodeStatsPIT { return p.Overall })
-	result.Solo = computeGamemodeFKStreak(func(p *domain.PlayerPIT) domain.GamemodeStatsPIT { return p.Solo })
-	result.Doubles = computeGamemodeFKStreak(func(p *domain.PlayerPIT) domain.GamemodeStatsPIT { return p.Doubles })
-	result.Threes = computeGamemodeFKStreak(func(p *domain.PlayerPIT) domain.GamemodeStatsPIT { return p.Threes })
-	result.Fours = computeGamemodeFKStreak(func(p *domain.PlayerPIT) domain.GamemodeStatsPIT { return p.Fours })
-	result.Fourv4 = computeGamemodeFKStreak(func(p *domain.PlayerPIT) domain.GamemodeStatsPIT { return p.Fourv4 })
-
-	return result
+	return toFinalKillStreakStats(app.ComputeFinalKillStreaks(playerPITs))
 }
 
 // computeCoverage calculates what percentage of games played were covered by sessions
-func computeCoverage(ctx context.Context, sessions []domain.Session, boundaryStats *yearBoundaryStats, totalHours float64) coverageStats {
+func computeCoverage(ctx context.Context, summaries []domain.SessionSummary, boundaryStats *yearBoundaryStats, totalHours float64) coverageStats {
 	if boundaryStats == nil {
 		return coverageStats{
 			GamesPlayedPercentage: 0,
@@ -713,8 +608,8 @@ func computeCoverage(ctx context.Context, sessions []domain.Session, boundarySta
 
 	// Games covered by sessions
 	sessionGames := 0
-	for _, session := range sessions {
-		sessionGames += session.End.Overall.GamesPlayed - session.Start.Overall.GamesPlayed
+	for _, summary := range summaries {
+		sessionGames += summary.Overall.GamesPlayed
 	}
 
 	coverage := float64(sessionGames) / float64(totalGames)
@@ -742,18 +637,16 @@ func computeCoverage(ctx context.Context, sessions []domain.Session, boundarySta
 
 // computeFlawlessSessions counts sessions with no losses and no final deaths
 // Assumes at least one session exists
-func computeFlawlessSessions(ctx context.Context, sessions []domain.Session) flawlessSessionStats {
+func computeFlawlessSessions(ctx context.Context, summaries []domain.SessionSummary) flawlessSessionStats {
 	flawlessCount := 0
-	for _, session := range sessions {
-		losses := session.End.Overall.Losses - session.Start.Overall.Losses
-		finalDeaths := session.End.Overall.FinalDeaths - session.Start.Overall.FinalDeaths
-		wins := session.End.Overall.Wins - session.Start.Overall.Wins
-		if losses == 0 && finalDeaths == 0 && wins > 0 {
+	for _, summary := range summaries {
+		overall := &summary.Overall
+		if overall.Losses == 0 && overall.FinalDeaths == 0 && overall.Wins > 0 {
 			flawlessCount++
 		}
 	}
 
-	percentage := float64(flawlessCount) / float64(len(sessions)) * 100
+	percentage := float64(flawlessCount) / float64(len(summaries)) * 100
 
 	return flawlessSessionStats{
 		Count:      flawlessCount,
@@ -64,7 +64,7 @@ func TestComputeSessionLengths(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
-			got := computeSessionLengths(ctx, tt.sessions)
+			got := computeSessionLengths(ctx, summarizeSessions(tt.sessions))
 			require.Equal(t, tt.want, got)
 		})
 	}
@@ -230,7 +230,7 @@ func TestComputeFlawlessSessions(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
-			require.Equal(t, tt.want, computeFlawlessSessions(ctx, tt.sessions))
+			require.Equal(t, tt.want, computeFlawlessSessions(ctx, summarizeSessions(tt.sessions)))
 		})
 	}
 }
@@ -290,7 +290,7 @@ func TestComputeAverages(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
-			got := computeAverages(ctx, tt.sessions)
+			got := computeAverages(ctx, summarizeSessions(tt.sessions))
 			if tt.want == nil {
 				require.Nil(t, got)
 			} else {
@@ -488,7 +488,7 @@ func TestComputeCoverage(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
-			got := computeCoverage(ctx, tt.sessions, tt.boundaryStats, tt.totalHours)
+			got := computeCoverage(ctx, summarizeSessions(tt.sessions), tt.boundaryStats, tt.totalHours)
 			require.Equal(t, tt.wantCoverage, got.GamesPlayedPercentage)
 			require.Equal(t, got.AdjustedTotalHours, tt.wantAdjustedTotalHours)
 		})
@@ -594,7 +594,7 @@ func TestComputeBestSessions(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
-			got := computeBestSessions(ctx, tt.sessions)
+			got := computeBestSessions(ctx, tt.sessions, summarizeSessions(tt.sessions))
 
 			{
 				finals := got.HighestFKDR.End.Overall.FinalKills - got.HighestFKDR.Start.Overall.FinalKills
@@ -636,6 +636,7 @@ func TestComputeWinstreaks(t *testing.T) {
 		name            string
 		playerPITs      []domain.PlayerPIT
 		wantOverallHigh int
+		wantUncertain   bool
 	}{
 		{
 			name: "winstreak of 5 then loss",
@@ -677,6 +678,21 @@ func TestComputeWinstreaks(t *testing.T) {
 					Fours().WithWins(8).WithLosses(1).Build(time.Date(2023, time.January, 1, 12, 0, 0, 0, time.UTC)),
 			},
 			wantOverallHigh: 5, // The extra 3 wins don't count toward the streak
+			wantUncertain:   true,
+		},
+		{
+			name: "visible winstreak is used",
+			playerPITs: []domain.PlayerPIT{
+				// The streak started before the first stats
+				domaintest.NewPlayerBuilder(playerUUID).WithOverallWinstreak(10).
+					Fours().WithWinstreak(10).WithWins(10).WithLosses(0).Build(time.Date(2023, time.January, 1, 10, 0, 0, 0, time.UTC)),
+				domaintest.NewPlayerBuilder(playerUUID).WithOverallWinstreak(12).
+					Fours().WithWinstreak(12).WithWins(12).WithLosses(0).Build(time.Date(2023, time.January, 1, 11, 0, 0, 0, time.UTC)),
+				// The visible streak tells that the 3 wins came before the loss
+				domaintest.NewPlayerBuilder(playerUUID).WithOverallWinstreak(0).
+					Fours().WithWinstreak(0).WithWins(15).WithLosses(1).Build(time.Date(2023, time.January, 1, 12, 0, 0, 0, time.UTC)),
+			},
+			wantOverallHigh: 15,
 		},
 	}
 
@@ -693,6 +709,7 @@ func TestComputeWinstreaks(t *testing.T) {
 			require.NotNil(t, got.Overall)
 
 			require.Equal(t, tt.wantOverallHigh, got.Overall.Highest)
+			require.Equal(t, tt.wantUncertain, got.Overall.Uncertain)
 		})
 	}
 }
@@ -768,6 +785,14 @@ func TestComputeFinalKillStreaks(t *testing.T) {
 }
 
 // Helper functions
+func summarizeSessions(sessions []domain.Session) []domain.SessionSummary {
+	summaries := make([]domain.SessionSummary, 0, len(sessions))
+	for i := range sessions {
+		summaries = append(summaries, domain.NewSessionSummary(&sessions[i], 0))
+	}
+	return summaries
+}
+
 func timePtr(t time.Time) *time.Time {
 	return &t
 }
@@ -0,0 +1,347 @@
+package ports
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/Amund211/flashlight/internal/app"
+	"github.com/Amund211/flashlight/internal/domain"
+	"github.com/Amund211/flashlight/internal/logging"
+	"github.com/Amund211/flashlight/internal/reporting"
+	"github.com/Amund211/flashlight/internal/strutils"
+)
+
+// periodGranularity is the size of the buckets in the per-period breakdown
+type periodGranularity string
+
+const (
+	periodGranularityDay   periodGranularity = "day"
+	periodGranularityMonth periodGranularity = "month"
+)
+
+// maxDailyBreakdownDays is the longest period broken down per day. Longer
+// periods are broken down per month.
+const maxDailyBreakdownDays = 62
+
+// maxWrappedPeriod bounds custom ranges, like the sessions endpoint
+const maxWrappedPeriod = 400 * 24 * time.Hour
+
+// wrappedPeriod is the period [Start, End) a wrapped covers
+type wrappedPeriod struct {
+	Start       time.Time
+	End         time.Time
+	Granularity periodGranularity
+}
+
+type wrappedPeriodRainbow struct {
+	Start       time.Time         `json:"start"`
+	End         time.Time         `json:"end"`
+	Granularity periodGranularity `json:"granularity"`
+}
+
+type wrappedPeriodResponse struct {
+	Success                bool                      `json:"success"`
+	UUID                   string                    `json:"uuid,omitempty"`
+	Period                 wrappedPeriodRainbow      `json:"period"`
+	TotalSessions          int                       `json:"totalSessions"`
+	NonConsecutiveSessions int                       `json:"nonConsecutiveSessions"`
+	PeriodStats            *yearBoundaryStatsRainbow `json:"periodStats,omitempty"`
+	SessionStats           *periodSessionStats       `json:"sessionStats,omitempty"`
+}
+
+// periodSessionStats is sessionStats with a breakdown that follows the
+// period's granularity
+type periodSessionStats struct {
+	sessionAnalysis
+	SessionsPerPeriod []periodBucket `json:"sessionsPerPeriod"`
+}
+
+type periodBucket struct {
+	Start    time.Time `json:"start"`
+	Sessions int       `json:"sessions"`
+}
+
+// parseWrappedPeriod reads the period from exactly one of the month
+// (2024-05), week (ISO week, 2024-W20) or start and end (RFC3339) query
+// parameters. Months and weeks start at midnight in location.
+func parseWrappedPeriod(query url.Values, location *time.Location) (wrappedPeriod, error) {
+	month, week := query.Get("month"), query.Get("week")
+	rawStart, rawEnd := query.Get("start"), query.Get("end")
+
+	given := 0
+	for _, set := range []bool{month != "", week != "", rawStart != "" || rawEnd != ""} {
+		if set {
+			given++
+		}
+	}
+	if given != 1 {
+		return wrappedPeriod{}, fmt.Errorf("exactly one of month, week or start and end is required")
+	}
+
+	var start, end time.Time
+	switch {
+	case month != "":
+		parsed, err := time.ParseInLocation("2006-01", month, location)
+		if err != nil {
+			return wrappedPeriod{}, fmt.Errorf("invalid month")
+		}
+		start = parsed
+		end = start.AddDate(0, 1, 0)
+	case week != "":
+		var year, weekNumber int
+		n, err := fmt.Sscanf(week, "%4d-W%2d", &year, &weekNumber)
+		if err != nil || n != 2 || fmt.Sprintf("%04d-W%02d", year, weekNumber) != week {
+			return wrappedPeriod{}, fmt.Errorf("invalid week")
+		}
+		// Dec 28th is always in the last ISO week of its year
+		_, weeksInYear := time.Date(year, time.December, 28, 0, 0, 0, 0, location).ISOWeek()
+		if weekNumber < 1 || weekNumber > weeksInYear {
+			return wrappedPeriod{}, fmt.Errorf("invalid week")
+		}
+		// Jan 4th is always in ISO week 1
+		jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, location)
+		daysSinceMonday := (int(jan4.Weekday()) + 6) % 7
+		start = jan4.AddDate(0, 0, -daysSinceMonday+(weekNumber-1)*7)
+		end = start.AddDate(0, 0, 7)
+	default:
+		var err error
+		start, err = time.Parse(time.RFC3339, rawStart)
+		if err != nil {
+			return wrappedPeriod{}, fmt.Errorf("invalid start")
+		}
+		end, err = time.Parse(time.RFC3339, rawEnd)
+		if err != nil {
+			return wrappedPeriod{}, fmt.Errorf("invalid end")
+		}
+		if !start.Before(end) {
+			return wrappedPeriod{}, fmt.Errorf("start must be before end")
+		}
+		if end.Sub(start) > maxWrappedPeriod {
+			return wrappedPeriod{}, fmt.Errorf("period is too long")
+		}
+	}
+
+	if start.Year() < 2000 || end.Year() > 3000 {
+		return wrappedPeriod{}, fmt.Errorf("period out of range")
+	}
+
+	granularity := periodGranularityMonth
+	if !end.After(start.AddDate(0, 0, maxDailyBreakdownDays)) {
+		granularity = periodGranularityDay
+	}
+
+	return wrappedPeriod{Start: start, End: end, Granularity: granularity}, nil
+}
+
+// periodBucketStarts returns the start of each bucket overlapping the period,
+// aligned to the granularity in location. The first bucket may start before
+// the period.
+func periodBucketStarts(period wrappedPeriod, location *time.Location) []time.Time {
+	local := period.Start.In(location)
+
+	var bucketStart time.Time
+	var next func(time.Time) time.Time
+	switch period.Granularity {
+	case periodGranularityMonth:
+		bucketStart = time.Date(local.Year(), local.Month(), 1, 0, 0, 0, 0, location)
+		next = func(t time.Time) time.Time { return t.AddDate(0, 1, 0) }
+	default:
+		bucketStart = time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, location)
+		next = func(t time.Time) time.Time { return t.AddDate(0, 0, 1) }
+	}
+
+	starts := []time.Time{}
+	for ; bucketStart.Before(period.End); bucketStart = next(bucketStart) {
+		starts = append(starts, bucketStart)
+	}
+	return starts
+}
+
+// computeSessionsPerPeriod counts the sessions starting in each bucket.
+// Sessions that started before the period count for the first bucket.
+func computeSessionsPerPeriod(ctx context.Context, sessions []domain.Session, period wrappedPeriod, location *time.Location) []periodBucket {
+	starts := periodBucketStarts(period, location)
+	buckets := make([]periodBucket, 0, len(starts))
+	for _, start := range starts {
+		buckets = append(buckets, periodBucket{Start: start, Sessions: 0})
+	}
+
+	for _, session := range sessions {
+		sessionStart := session.Start.QueriedAt
+		if sessionStart.Before(period.Start) {
+			sessionStart = period.Start
+		}
+		if !sessionStart.Before(period.End) {
+			continue
+		}
+
+		// The last bucket starting at or before the session
+		index, found := slices.BinarySearchFunc(starts, sessionStart, func(bucketStart, t time.Time) int {
+			return bucketStart.Compare(t)
+		})
+		if !found {
+			index--
+		}
+		if index < 0 {
+			// Unreachable - the first bucket starts at or before the period
+			reporting.Report(ctx, fmt.Errorf("session before the first bucket"), map[string]string{
+				"sessionStart": sessionStart.Format(time.RFC3339),
+				"periodStart":  period.Start.Format(time.RFC3339),
+			})
+			continue
+		}
+		buckets[index].Sessions++
+	}
+
+	return buckets
+}
+
+func computeWrappedPeriodStats(ctx context.Context, computeSessions app.ComputeSessions, playerPITs []domain.PlayerPIT, period wrappedPeriod, location *time.Location) wrappedPeriodResponse {
+	sessions := computeSessions(ctx, playerPITs, period.Start, period.End.Add(-time.Nanosecond), app.SessionOptions{})
+	consecutiveSessions, nonConsecutiveCount := splitConsecutiveSessions(sessions)
+
+	boundaryStats := computeBoundaryStats(ctx, playerPITs, period.Start, period.End)
+
+	response := wrappedPeriodResponse{
+		Period: wrappedPeriodRainbow{
+			Start:       period.Start,
+			End:         period.End,
+			Granularity: period.Granularity,
+		},
+		TotalSessions:          len(consecutiveSessions),
+		NonConsecutiveSessions: nonConsecutiveCount,
+		PeriodStats:            boundaryStats.ToRainbow(),
+	}
+
+	if len(consecutiveSessions) == 0 {
+		return response
+	}
+
+	response.SessionStats = &periodSessionStats{
+		sessionAnalysis: computeSessionAnalysis(ctx, consecutiveSessions,
+			computeWinstreaks(ctx, playerPITs), computeFinalKillStreaks(ctx, playerPITs),
+			boundaryStats, location),
+		SessionsPerPeriod: computeSessionsPerPeriod(ctx, consecutiveSessions, period, location),
+	}
+
+	return response
+}
+
+// MakeGetWrappedPeriodHandler serves wrapped for a month, an ISO week or a
+// custom range, sharing the analysis with the yearly wrapped
+func MakeGetWrappedPeriodHandler(
+	getPlayerPITs app.GetPlayerPITs,
+	computeSessions app.ComputeSessions,
+	registerUserVisit app.RegisterUserVisit,
+	allowedOrigins *DomainSuffixes,
+	rootLogger *slog.Logger,
+	sentryMiddleware func(http.HandlerFunc) http.HandlerFunc,
+	bearerAuthMiddleware func(http.HandlerFunc) http.HandlerFunc,
+	blocklistConfig BlocklistConfig,
+) (http.HandlerFunc, func()) {
+	middleware, stop := mustBuildRouteMiddleware(
+		RouteSpec{
+			Name:           "wrapped-period",
+			AllowedOrigins: allowedOrigins,
+			BearerAuth:     bearerAuthMiddleware,
+			RateLimits: []RateLimit{
+				IPRateLimit(4, 240),
+				IdentityRateLimit(1, 60),
+			},
+			RegisterUserVisit: registerUserVisit,
+			Compress:          true,
+		},
+		rootLogger,
+		sentryMiddleware,
+		blocklistConfig,
+	)
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		rawUUID := r.PathValue("uuid")
+		query := r.URL.Query()
+
+		logging.FromContext(ctx).InfoContext(ctx, "Handling wrapped period request",
+			slog.String("uuid", rawUUID),
+			slog.String("query", r.URL.RawQuery),
+		)
+
+		ctx = logging.AddMetaToContext(ctx,
+			slog.String("uuid", rawUUID),
+		)
+		ctx = reporting.AddExtrasToContext(ctx,
+			map[string]string{
+				"rawUUID": rawUUID,
+				"query":   r.URL.RawQuery,
+			},
+		)
+
+		uuid, err := strutils.NormalizeUUID(rawUUID)
+		if err != nil {
+			writeErrorResponse(ctx, w, badRequestError("invalid uuid"))
+			return
+		}
+
+		// Parse and validate timezone parameter (optional, defaults to UTC)
+		timezoneStr := query.Get("timezone")
+		if timezoneStr == "" {
+			timezoneStr = "UTC"
+		}
+		location, err := time.LoadLocation(timezoneStr)
+		if err != nil {
+			logging.FromContext(ctx).WarnContext(ctx, "Invalid timezone", "tzstring", timezoneStr, "err", err)
+			writeErrorResponse(ctx, w, badRequestError("invalid timezone"))
+			return
+		}
+
+		period, err := parseWrappedPeriod(query, location)
+		if err != nil {
+			writeErrorResponse(ctx, w, badRequestError(err.Error()))
+			return
+		}
+
+		ctx = reporting.AddExtrasToContext(ctx, map[string]string{
+			"uuid": uuid,
+		})
+		ctx = logging.AddMetaToContext(ctx,
+			slog.String("normalizedUUID", uuid),
+			slog.String("timezone", timezoneStr),
+			slog.String("periodStart", period.Start.Format(time.RFC3339)),
+			slog.String("periodEnd", period.End.Format(time.RFC3339)),
+		)
+
+		// NOTE: 24-hour padding to ensure we can complete sessions at the period boundaries
+		playerPITs, err := getPlayerPITs(ctx, uuid, period.Start.Add(-24*time.Hour), period.End.Add(24*time.Hour))
+		if err != nil {
+			// NOTE: GetPlayerPITs implementations handle their own error reporting
+			writeErrorResponse(ctx, w, apiErrorFromDomain(err, "failed to get player data"))
+			return
+		}
+
+		wrappedData := computeWrappedPeriodStats(ctx, computeSessions, playerPITs, period, location)
+		wrappedData.Success = true
+		wrappedData.UUID = uuid
+
+		marshalled, err := json.Marshal(wrappedData)
+		if err != nil {
+			reporting.Report(ctx, fmt.Errorf("failed to marshal wrapped period response: %w", err))
+			writeErrorResponse(ctx, w, newAPIError(http.StatusInternalServerError, errorCodeInternal, "failed to marshal response"))
+			return
+		}
+
+		logging.FromContext(ctx).InfoContext(ctx, "Returning wrapped period data")
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(marshalled)
+	}
+
+	return middleware(handler), stop
+}
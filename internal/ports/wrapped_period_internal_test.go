@@ -0,0 +1,199 @@
+package ports
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Amund211/flashlight/internal/domain"
+	"github.com/Amund211/flashlight/internal/domaintest"
+)
+
+func TestParseWrappedPeriod(t *testing.T) {
+	t.Parallel()
+
+	oslo, err := time.LoadLocation("Europe/Oslo")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		query    string
+		location *time.Location
+		want     wrappedPeriod
+	}{
+		{
+			name:     "month",
+			query:    "month=2024-02",
+			location: time.UTC,
+			want: wrappedPeriod{
+				Start:       time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC),
+				End:         time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC),
+				Granularity: periodGranularityDay,
+			},
+		},
+		{
+			name:     "month in timezone",
+			query:    "month=2024-12",
+			location: oslo,
+			want: wrappedPeriod{
+				Start:       time.Date(2024, time.December, 1, 0, 0, 0, 0, oslo),
+				End:         time.Date(2025, time.January, 1, 0, 0, 0, 0, oslo),
+				Granularity: periodGranularityDay,
+			},
+		},
+		{
+			name:     "week",
+			query:    "week=2024-W20",
+			location: time.UTC,
+			want: wrappedPeriod{
+				Start:       time.Date(2024, time.May, 13, 0, 0, 0, 0, time.UTC),
+				End:         time.Date(2024, time.May, 20, 0, 0, 0, 0, time.UTC),
+				Granularity: periodGranularityDay,
+			},
+		},
+		{
+			name:     "first week starting in the previous year",
+			query:    "week=2025-W01",
+			location: time.UTC,
+			want: wrappedPeriod{
+				Start:       time.Date(2024, time.December, 30, 0, 0, 0, 0, time.UTC),
+				End:         time.Date(2025, time.January, 6, 0, 0, 0, 0, time.UTC),
+				Granularity: periodGranularityDay,
+			},
+		},
+		{
+			name:     "week 53",
+			query:    "week=2020-W53",
+			location: time.UTC,
+			want: wrappedPeriod{
+				Start:       time.Date(2020, time.December, 28, 0, 0, 0, 0, time.UTC),
+				End:         time.Date(2021, time.January, 4, 0, 0, 0, 0, time.UTC),
+				Granularity: periodGranularityDay,
+			},
+		},
+		{
+			name:     "short custom range",
+			query:    "start=2024-06-01T00:00:00Z&end=2024-08-01T00:00:00Z",
+			location: time.UTC,
+			want: wrappedPeriod{
+				Start:       time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC),
+				End:         time.Date(2024, time.August, 1, 0, 0, 0, 0, time.UTC),
+				Granularity: periodGranularityDay,
+			},
+		},
+		{
+			name:     "long custom range",
+			query:    "start=2024-06-01T00:00:00Z&end=2024-09-01T00:00:00Z",
+			location: time.UTC,
+			want: wrappedPeriod{
+				Start:       time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC),
+				End:         time.Date(2024, time.September, 1, 0, 0, 0, 0, time.UTC),
+				Granularity: periodGranularityMonth,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			query, err := url.ParseQuery(tt.query)
+			require.NoError(t, err)
+
+			got, err := parseWrappedPeriod(query, tt.location)
+			require.NoError(t, err)
+			require.True(t, tt.want.Start.Equal(got.Start), "start: want %s, got %s", tt.want.Start, got.Start)
+			require.True(t, tt.want.End.Equal(got.End), "end: want %s, got %s", tt.want.End, got.End)
+			require.Equal(t, tt.want.Granularity, got.Granularity)
+		})
+	}
+
+	for _, query := range []string{
+		"",
+		"month=2024-05&week=2024-W20",
+		"month=2024-05&start=2024-05-01T00:00:00Z&end=2024-06-01T00:00:00Z",
+		"month=2024-13",
+		"month=May",
+		"week=2024-W00",
+		"week=2024-W53",
+		"week=2024-W5",
+		"week=2024W20",
+		"start=2024-05-01T00:00:00Z",
+		"end=2024-05-01T00:00:00Z",
+		"start=2024-06-01T00:00:00Z&end=2024-05-01T00:00:00Z",
+		"start=2024-06-01T00:00:00Z&end=2024-06-01T00:00:00Z",
+		"start=2023-01-01T00:00:00Z&end=2024-06-01T00:00:00Z",
+		"month=1999-12",
+	} {
+		t.Run("invalid "+query, func(t *testing.T) {
+			t.Parallel()
+
+			parsed, err := url.ParseQuery(query)
+			require.NoError(t, err)
+
+			_, err = parseWrappedPeriod(parsed, time.UTC)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestComputeSessionsPerPeriod(t *testing.T) {
+	t.Parallel()
+	playerUUID := domaintest.NewUUID(t)
+
+	session := func(start, end time.Time) domain.Session {
+		return domain.Session{
+			Start: domaintest.NewPlayerBuilder(playerUUID).Build(start),
+			End:   domaintest.NewPlayerBuilder(playerUUID).Build(end),
+		}
+	}
+
+	t.Run("per day", func(t *testing.T) {
+		t.Parallel()
+
+		period := wrappedPeriod{
+			Start:       time.Date(2024, time.May, 13, 0, 0, 0, 0, time.UTC),
+			End:         time.Date(2024, time.May, 16, 0, 0, 0, 0, time.UTC),
+			Granularity: periodGranularityDay,
+		}
+		sessions := []domain.Session{
+			// Started before the period - counts for the first day
+			session(time.Date(2024, time.May, 12, 23, 0, 0, 0, time.UTC), time.Date(2024, time.May, 13, 1, 0, 0, 0, time.UTC)),
+			session(time.Date(2024, time.May, 13, 12, 0, 0, 0, time.UTC), time.Date(2024, time.May, 13, 13, 0, 0, 0, time.UTC)),
+			session(time.Date(2024, time.May, 15, 0, 0, 0, 0, time.UTC), time.Date(2024, time.May, 15, 1, 0, 0, 0, time.UTC)),
+			session(time.Date(2024, time.May, 15, 23, 0, 0, 0, time.UTC), time.Date(2024, time.May, 16, 1, 0, 0, 0, time.UTC)),
+		}
+
+		got := computeSessionsPerPeriod(t.Context(), sessions, period, time.UTC)
+		require.Equal(t, []periodBucket{
+			{Start: time.Date(2024, time.May, 13, 0, 0, 0, 0, time.UTC), Sessions: 2},
+			{Start: time.Date(2024, time.May, 14, 0, 0, 0, 0, time.UTC), Sessions: 0},
+			{Start: time.Date(2024, time.May, 15, 0, 0, 0, 0, time.UTC), Sessions: 2},
+		}, got)
+	})
+
+	t.Run("per month in timezone", func(t *testing.T) {
+		t.Parallel()
+
+		newYork, err := time.LoadLocation("America/New_York")
+		require.NoError(t, err)
+
+		period := wrappedPeriod{
+			Start:       time.Date(2024, time.January, 15, 0, 0, 0, 0, newYork),
+			End:         time.Date(2024, time.April, 1, 0, 0, 0, 0, newYork),
+			Granularity: periodGranularityMonth,
+		}
+		sessions := []domain.Session{
+			// Still January in New York
+			session(time.Date(2024, time.February, 1, 3, 0, 0, 0, time.UTC), time.Date(2024, time.February, 1, 4, 0, 0, 0, time.UTC)),
+			session(time.Date(2024, time.March, 10, 12, 0, 0, 0, time.UTC), time.Date(2024, time.March, 10, 13, 0, 0, 0, time.UTC)),
+		}
+
+		got := computeSessionsPerPeriod(t.Context(), sessions, period, newYork)
+		require.Len(t, got, 3)
+		require.True(t, time.Date(2024, time.January, 1, 0, 0, 0, 0, newYork).Equal(got[0].Start))
+		require.Equal(t, []int{1, 0, 1}, []int{got[0].Sessions, got[1].Sessions, got[2].Sessions})
+	})
+}
@@ -0,0 +1,190 @@
+package ports_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Amund211/flashlight/internal/app"
+	"github.com/Amund211/flashlight/internal/domain"
+	"github.com/Amund211/flashlight/internal/domaintest"
+	"github.com/Amund211/flashlight/internal/ports"
+)
+
+func TestMakeGetWrappedPeriodHandler(t *testing.T) {
+	t.Parallel()
+
+	allowedOrigins, err := ports.NewDomainSuffixes("example.com", "test.com")
+	require.NoError(t, err)
+
+	testLogger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	noopMiddleware := func(h http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			h(w, r)
+		}
+	}
+
+	uuid := "01234567-89ab-cdef-0123-456789abcdef"
+
+	makeHandler := func(getPlayerPITs app.GetPlayerPITs) http.HandlerFunc {
+		stubRegisterUserVisit := func(ctx context.Context, userID string, ipHash string, userAgent string) (domain.User, error) {
+			return domain.User{}, nil
+		}
+		handler, stop := ports.MakeGetWrappedPeriodHandler(
+			getPlayerPITs,
+			app.BuildComputeSessions(time.Now),
+			stubRegisterUserVisit,
+			allowedOrigins,
+			testLogger,
+			noopMiddleware,
+			noopMiddleware,
+			emptyBlocklistConfig,
+		)
+		t.Cleanup(stop)
+		return handler
+	}
+
+	makeRequest := func(uuid string, query string) *http.Request {
+		req := httptest.NewRequestWithContext(t.Context(), "GET", "/wrapped/"+uuid+"?"+query, nil)
+		req.SetPathValue("uuid", uuid)
+		return req
+	}
+
+	type periodResponse struct {
+		Success bool   `json:"success"`
+		UUID    string `json:"uuid"`
+		Period  struct {
+			Start       time.Time `json:"start"`
+			End         time.Time `json:"end"`
+			Granularity string    `json:"granularity"`
+		} `json:"period"`
+		TotalSessions int `json:"totalSessions"`
+		SessionStats  *struct {
+			SessionsPerPeriod []struct {
+				Start    time.Time `json:"start"`
+				Sessions int       `json:"sessions"`
+			} `json:"sessionsPerPeriod"`
+			Winstreaks map[string]struct {
+				Highest int `json:"highest"`
+			} `json:"winstreaks"`
+		} `json:"sessionStats"`
+	}
+
+	t.Run("month", func(t *testing.T) {
+		t.Parallel()
+
+		base := time.Date(2024, time.May, 3, 12, 0, 0, 0, time.UTC)
+		pb := domaintest.NewPlayerBuilder(uuid).FromDB().Fours()
+		playerPITs := []domain.PlayerPIT{
+			pb.Build(base),
+			pb.WithGamesPlayed(1).WithWins(1).Build(base.Add(20 * time.Minute)),
+			pb.WithGamesPlayed(2).WithWins(2).Build(base.Add(40 * time.Minute)),
+			pb.WithGamesPlayed(3).WithLosses(1).Build(base.Add(60 * time.Minute)),
+		}
+
+		var gotStart, gotEnd time.Time
+		handler := makeHandler(func(ctx context.Context, gotUUID string, start, end time.Time) ([]domain.PlayerPIT, error) {
+			require.Equal(t, uuid, gotUUID)
+			gotStart = start
+			gotEnd = end
+			return playerPITs, nil
+		})
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, makeRequest(uuid, "month=2024-05"))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		requireOpenAPIResponse(t, "GET /v1/wrapped/{uuid}", w)
+
+		require.True(t, time.Date(2024, time.April, 30, 0, 0, 0, 0, time.UTC).Equal(gotStart))
+		require.True(t, time.Date(2024, time.June, 2, 0, 0, 0, 0, time.UTC).Equal(gotEnd))
+
+		var response periodResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.True(t, response.Success)
+		require.Equal(t, uuid, response.UUID)
+		require.Equal(t, "day", response.Period.Granularity)
+		require.True(t, time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC).Equal(response.Period.Start))
+		require.True(t, time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC).Equal(response.Period.End))
+		require.Equal(t, 1, response.TotalSessions)
+
+		require.NotNil(t, response.SessionStats)
+		require.Len(t, response.SessionStats.SessionsPerPeriod, 31)
+		require.Equal(t, 1, response.SessionStats.SessionsPerPeriod[2].Sessions)
+		require.Equal(t, 2, response.SessionStats.Winstreaks["fours"].Highest)
+	})
+
+	t.Run("custom range without sessions", func(t *testing.T) {
+		t.Parallel()
+
+		handler := makeHandler(func(ctx context.Context, _ string, _, _ time.Time) ([]domain.PlayerPIT, error) {
+			return []domain.PlayerPIT{}, nil
+		})
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, makeRequest(uuid, "start=2024-01-01T00:00:00Z&end=2024-07-01T00:00:00Z&timezone=Europe/Oslo"))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		requireOpenAPIResponse(t, "GET /v1/wrapped/{uuid}", w)
+
+		var response periodResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.Equal(t, "month", response.Period.Granularity)
+		require.Equal(t, 0, response.TotalSessions)
+		require.Nil(t, response.SessionStats)
+	})
+
+	t.Run("bad requests", func(t *testing.T) {
+		t.Parallel()
+
+		for name, request := range map[string]struct {
+			uuid  string
+			query string
+		}{
+			"invalid uuid":     {uuid: "invalid-uuid", query: "month=2024-05"},
+			"invalid timezone": {uuid: uuid, query: "month=2024-05&timezone=Mars/Olympus"},
+			"no period":        {uuid: uuid, query: ""},
+			"invalid week":     {uuid: uuid, query: "week=2024-W60"},
+			"range too long":   {uuid: uuid, query: "start=2023-01-01T00:00:00Z&end=2024-06-01T00:00:00Z"},
+		} {
+			t.Run(name, func(t *testing.T) {
+				t.Parallel()
+
+				called := false
+				handler := makeHandler(func(ctx context.Context, _ string, _, _ time.Time) ([]domain.PlayerPIT, error) {
+					called = true
+					return nil, nil
+				})
+
+				w := httptest.NewRecorder()
+				handler.ServeHTTP(w, makeRequest(request.uuid, request.query))
+
+				require.Equal(t, http.StatusBadRequest, w.Code)
+				requireOpenAPIResponse(t, "GET /v1/wrapped/{uuid}", w)
+				require.False(t, called)
+			})
+		}
+	})
+
+	t.Run("failed to get stats", func(t *testing.T) {
+		t.Parallel()
+
+		handler := makeHandler(func(ctx context.Context, _ string, _, _ time.Time) ([]domain.PlayerPIT, error) {
+			return nil, errors.New("db down")
+		})
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, makeRequest(uuid, "week=2024-W20"))
+
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+		requireOpenAPIResponse(t, "GET /v1/wrapped/{uuid}", w)
+	})
+}
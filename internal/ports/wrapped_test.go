@@ -18,6 +18,16 @@ import (
 	"github.com/Amund211/flashlight/internal/ports"
 )
 
+type emptyWrappedRepository struct{}
+
+func (emptyWrappedRepository) GetWrapped(ctx context.Context, playerUUID string, year int, version int) (domain.StoredWrapped, error) {
+	return domain.StoredWrapped{}, domain.ErrWrappedNotFound
+}
+
+func (emptyWrappedRepository) StoreWrapped(ctx context.Context, wrapped domain.StoredWrapped) error {
+	return nil
+}
+
 func TestMakeGetWrappedHandler(t *testing.T) {
 	t.Parallel()
 
@@ -48,8 +58,7 @@ func TestMakeGetWrappedHandler(t *testing.T) {
 			return domain.User{}, nil
 		}
 		handler, stop := ports.MakeGetWrappedHandler(
-			getPlayerPITs,
-			app.BuildComputeSessions(time.Now),
+			app.BuildGetWrapped(getPlayerPITs, app.BuildComputeSessions(time.Now), emptyWrappedRepository{}, time.Now),
 			stubRegisterUserVisit,
 			allowedOrigins,
 			testLogger,
@@ -83,6 +92,7 @@ func TestMakeGetWrappedHandler(t *testing.T) {
 		handler.ServeHTTP(w, req)
 
 		require.Equal(t, http.StatusOK, w.Code)
+		requireOpenAPIResponse(t, "GET /v1/wrapped/{uuid}/{year}", w)
 		require.True(t, *called)
 
 		var response map[string]interface{}
@@ -114,6 +124,7 @@ func TestMakeGetWrappedHandler(t *testing.T) {
 		handler.ServeHTTP(w, req)
 
 		require.Equal(t, http.StatusOK, w.Code)
+		requireOpenAPIResponse(t, "GET /v1/wrapped/{uuid}/{year}", w)
 		require.True(t, *called)
 
 		var response map[string]interface{}
@@ -152,6 +163,7 @@ func TestMakeGetWrappedHandler(t *testing.T) {
 		handler.ServeHTTP(w, req)
 
 		require.Equal(t, http.StatusOK, w.Code)
+		requireOpenAPIResponse(t, "GET /v1/wrapped/{uuid}/{year}", w)
 		require.True(t, *called)
 
 		var response map[string]interface{}
@@ -173,6 +185,7 @@ func TestMakeGetWrappedHandler(t *testing.T) {
 		handler.ServeHTTP(w, req)
 
 		require.Equal(t, http.StatusBadRequest, w.Code)
+		requireOpenAPIResponse(t, "GET /v1/wrapped/{uuid}/{year}", w)
 		require.False(t, *called)
 
 		var response map[string]interface{}
@@ -195,6 +208,7 @@ func TestMakeGetWrappedHandler(t *testing.T) {
 		handler.ServeHTTP(w, req)
 
 		require.Equal(t, http.StatusBadRequest, w.Code)
+		requireOpenAPIResponse(t, "GET /v1/wrapped/{uuid}/{year}", w)
 		require.False(t, *called)
 
 		var response map[string]interface{}
@@ -217,6 +231,7 @@ func TestMakeGetWrappedHandler(t *testing.T) {
 		handler.ServeHTTP(w, req)
 
 		require.Equal(t, http.StatusBadRequest, w.Code)
+		requireOpenAPIResponse(t, "GET /v1/wrapped/{uuid}/{year}", w)
 		require.False(t, *called)
 
 		var response map[string]interface{}
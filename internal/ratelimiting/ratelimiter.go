@@ -23,6 +23,26 @@ func (rateLimiter *tokenBucketRateLimiter) Consume(key string) bool {
 	return limiter.Value().Allow()
 }
 
+// RetryAfter returns how long until the next Consume for key would succeed.
+// Zero means it would succeed now.
+func (rateLimiter *tokenBucketRateLimiter) RetryAfter(key string) time.Duration {
+	item := rateLimiter.limiterByIP.Get(key, ttlcache.WithDisableTouchOnHit[string, *rate.Limiter]())
+	if item == nil {
+		return 0
+	}
+	missing := 1 - item.Value().Tokens()
+	if missing <= 0 || rateLimiter.refillPerSecond <= 0 {
+		return 0
+	}
+	return time.Duration(missing / rateLimiter.refillPerSecond * float64(time.Second))
+}
+
+// retryAfterRateLimiter is implemented by limiters that can tell when a key
+// will be allowed again.
+type retryAfterRateLimiter interface {
+	RetryAfter(key string) time.Duration
+}
+
 type RefillPerSecond float64
 type BurstSize int
 
@@ -49,6 +69,9 @@ func NewTokenBucketRateLimiter(refillPerSecond RefillPerSecond, burstSize BurstS
 type RequestRateLimiter interface {
 	Consume(r *http.Request) bool
 	KeyFor(r *http.Request) string
+	// RetryAfter returns how long until the request would be allowed, or
+	// zero if that is unknown.
+	RetryAfter(r *http.Request) time.Duration
 }
 
 type requestBasedRateLimiter struct {
@@ -64,6 +87,14 @@ func (rateLimiter *requestBasedRateLimiter) KeyFor(r *http.Request) string {
 	return rateLimiter.keyFunc(r)
 }
 
+func (rateLimiter *requestBasedRateLimiter) RetryAfter(r *http.Request) time.Duration {
+	limiter, ok := rateLimiter.limiter.(retryAfterRateLimiter)
+	if !ok {
+		return 0
+	}
+	return limiter.RetryAfter(rateLimiter.keyFunc(r))
+}
+
 func NewRequestBasedRateLimiter(limiter RateLimiter, keyFunc func(r *http.Request) string) RequestRateLimiter {
 	return &requestBasedRateLimiter{
 		limiter: limiter,
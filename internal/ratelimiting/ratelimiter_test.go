@@ -67,6 +67,30 @@ func TestTokenBucketRateLimiter(t *testing.T) {
 			require.True(t, rateLimiter.Consume("user1"))
 		})
 	})
+
+	t.Run("retry after", func(t *testing.T) {
+		t.Parallel()
+		synctest.Test(t, func(t *testing.T) {
+			rateLimiter, stop := NewTokenBucketRateLimiter(RefillPerSecond(0.5), BurstSize(1))
+			defer stop()
+
+			retryAfter := rateLimiter.(retryAfterRateLimiter).RetryAfter
+
+			// Unknown keys have a full bucket
+			require.Equal(t, time.Duration(0), retryAfter("user1"))
+
+			require.True(t, rateLimiter.Consume("user1"))
+			require.InDelta(t, 2*time.Second, retryAfter("user1"), float64(time.Millisecond))
+
+			time.Sleep(1500 * time.Millisecond)
+			require.InDelta(t, 500*time.Millisecond, retryAfter("user1"), float64(time.Millisecond))
+			require.False(t, rateLimiter.Consume("user1"))
+
+			time.Sleep(500 * time.Millisecond)
+			require.Equal(t, time.Duration(0), retryAfter("user1"))
+			require.True(t, rateLimiter.Consume("user1"))
+		})
+	})
 }
 
 func TestRequestBasedRateLimiter(t *testing.T) {
@@ -23,10 +23,15 @@ import (
 	"github.com/Amund211/flashlight/internal/adapters/authsessionrepository"
 	"github.com/Amund211/flashlight/internal/adapters/cache"
 	"github.com/Amund211/flashlight/internal/adapters/database"
+	"github.com/Amund211/flashlight/internal/adapters/guildrepository"
 	"github.com/Amund211/flashlight/internal/adapters/playerprovider"
 	"github.com/Amund211/flashlight/internal/adapters/playerrepository"
+	"github.com/Amund211/flashlight/internal/adapters/prismnoticerepository"
+	"github.com/Amund211/flashlight/internal/adapters/releaseprovider"
+	"github.com/Amund211/flashlight/internal/adapters/statusrepository"
 	"github.com/Amund211/flashlight/internal/adapters/tagprovider"
 	"github.com/Amund211/flashlight/internal/adapters/userrepository"
+	"github.com/Amund211/flashlight/internal/adapters/wrappedrepository"
 	"github.com/Amund211/flashlight/internal/app"
 	"github.com/Amund211/flashlight/internal/config"
 	"github.com/Amund211/flashlight/internal/domain"
@@ -118,6 +123,11 @@ func main() {
 
 	tagsCache := cache.NewTTLCacheWithMaxSize[domain.Tags](1*time.Minute, 50_000)
 
+	statusCache := cache.NewTTLCacheWithMaxSize[domain.PlayerStatus](30*time.Second, 50_000) // Statuses go stale quickly
+
+	// Guilds hold up to 125 members, so this cache is kept smaller than the others
+	guildCache := cache.NewTTLCacheWithMaxSize[*domain.Guild](10*time.Minute, 10_000)
+
 	httpClient := &http.Client{
 		Timeout: 10 * time.Second,
 	}
@@ -132,7 +142,22 @@ func main() {
 		fail("Failed to initialize HypixelPlayerProvider", "error", err.Error())
 	}
 
+	statusProvider, err := playerprovider.NewHypixelStatusProvider(hypixelAPI)
+	if err != nil {
+		fail("Failed to initialize HypixelStatusProvider", "error", err.Error())
+	}
+
+	guildProvider, err := playerprovider.NewHypixelGuildProvider(hypixelAPI)
+	if err != nil {
+		fail("Failed to initialize HypixelGuildProvider", "error", err.Error())
+	}
+
 	accountProvider := accountprovider.NewMojang(httpClient, time.Now, time.After)
+	// Coalesces username lookups, e.g. for a whole lobby, into bulk requests
+	accountByUsernameProvider, err := accountprovider.NewUsernameBatcher(accountProvider, 50*time.Millisecond, time.After)
+	if err != nil {
+		fail("Failed to initialize UsernameBatcher", "error", err.Error())
+	}
 
 	tagProvider, err := tagprovider.NewUrchin(httpClient, time.Now, time.After, config.UrchinAPIKey())
 	if err != nil {
@@ -173,6 +198,10 @@ func main() {
 
 	accountRepo := accountrepository.NewPostgres(db, repositorySchemaName)
 
+	guildRepo := guildrepository.NewPostgres(db, repositorySchemaName)
+
+	statusRepo := statusrepository.NewPostgres(db, repositorySchemaName)
+
 	userRepo := userrepository.NewPostgres(db, repositorySchemaName, time.Now)
 	logger.InfoContext(ctx, "Initialized UserRepository")
 
@@ -231,7 +260,7 @@ func main() {
 		fail("Failed to initialize allowed origins", "error", err.Error())
 	}
 
-	getAccountByUsernameWithCache, err := app.BuildGetAccountByUsernameWithCache(accountByUsernameCache, accountProvider, accountRepo, time.Now)
+	getAccountByUsernameWithCache, err := app.BuildGetAccountByUsernameWithCache(accountByUsernameCache, accountByUsernameProvider, accountRepo, time.Now)
 	if err != nil {
 		fail("Failed to initialize GetAccountByUsernameWithCache", "error", err.Error())
 	}
@@ -239,6 +268,8 @@ func main() {
 	if err != nil {
 		fail("Failed to initialize GetAccountByUUIDWithCache", "error", err.Error())
 	}
+	getUsernameHistory := app.BuildGetUsernameHistory(getAccountByUUIDWithCache, accountRepo)
+	getUsernameOwnerAt := app.BuildGetUsernameOwnerAt(getAccountByUsernameWithCache, accountRepo)
 
 	// Long TTL: the well-known requester check only uses FirstSeenAt, which
 	// never changes, and SeenCount, which is just a coarse spam guard.
@@ -259,13 +290,32 @@ func main() {
 		fail("Failed to initialize GetTagsWithCache", "error", err.Error())
 	}
 
+	getStatus, err := app.BuildGetStatusWithCache(statusCache, statusProvider, statusRepo)
+	if err != nil {
+		fail("Failed to initialize GetStatusWithCache", "error", err.Error())
+	}
+
+	getGuildByPlayer, err := app.BuildGetGuildByPlayerWithCache(guildCache, guildProvider, guildRepo)
+	if err != nil {
+		fail("Failed to initialize GetGuildByPlayerWithCache", "error", err.Error())
+	}
+
 	getHistory := app.BuildGetHistory(playerRepo, updatePlayerInInterval)
 
 	getPlayerPITs := app.BuildGetPlayerPITs(playerRepo, updatePlayerInInterval)
 
-	computeSessions := app.BuildComputeSessions(time.Now)
+	getOnlineTransitions := app.BuildGetOnlineTransitions(statusRepo)
+
+	computeSessions := app.BuildComputeSessionsWithOnlineTransitions(
+		app.BuildComputeSessions(time.Now),
+		getOnlineTransitions,
+	)
 
 	getSessionAt := app.BuildGetSessionAt(getPlayerPITs, computeSessions)
+	getGames := app.BuildGetGames(getPlayerPITs, computeSessions)
+	getWinstreaks := app.BuildGetWinstreaks(getPlayerPITs)
+	compare := app.BuildCompare(getPlayerPITs, computeSessions)
+	forecastPrestige := app.BuildForecastPrestige(getPlayerPITs, computeSessions, time.Now)
 
 	findMilestoneAchievements := app.BuildFindMilestoneAchievements(
 		playerRepo,
@@ -274,7 +324,23 @@ func main() {
 
 	registerUserVisit := app.BuildRegisterUserVisit(userRepo)
 
-	getPrismNotices := app.BuildGetPrismNotices(time.Now)
+	prismReleaseProvider, err := releaseprovider.NewGitHub(httpClient, config.PrismReleasesRepository())
+	if err != nil {
+		fail("Failed to initialize prism release provider", "error", err.Error())
+	}
+	prismReleases := app.NewPrismReleases(prismReleaseProvider)
+	clientMiddleware := ports.NewClientMiddleware(prismReleases.IsKnownVersion)
+
+	// Short TTL: admin edits go through the instance that made them right
+	// away, and reach the others within this.
+	prismNoticeCache := cache.NewTTLCacheWithMaxSize[[]domain.PrismNotice](30*time.Second, 10)
+	prismNoticeRepo := prismnoticerepository.NewPostgres(db, repositorySchemaName)
+	getPrismNotices := app.BuildGetPrismNotices(prismReleases, prismNoticeCache, prismNoticeRepo, time.Now)
+
+	adminAuthMiddleware := ports.NewAdminAuthMiddleware(config.AdminAPIKeys())
+	if len(config.AdminAPIKeys()) == 0 {
+		logger.InfoContext(ctx, "No admin API keys configured, admin endpoints are disabled")
+	}
 
 	mux := http.NewServeMux()
 
@@ -285,15 +351,34 @@ func main() {
 	// are torn down cleanly on the SIGTERM path.
 	var handlerStops []func()
 
+	// Unauthenticated GitHub API requests are limited to 60 an hour per IP,
+	// so refresh well below that. Stopped along with the handlers.
+	handlerStops = append(handlerStops, prismReleases.StartRefreshing(
+		logging.AddToContext(context.Background(), logger.With("component", "prism-releases")),
+		10*time.Minute,
+	))
+
 	// stops are the rate-limiter eviction-goroutine cleanups for the handler,
 	// collected here so a handler can't be registered without also collecting
 	// them (handlers without rate limiters pass none).
 	handleFunc := func(pattern string, handlerFunc http.HandlerFunc, stops ...func()) {
 		handlerStops = append(handlerStops, stops...)
-		handler := otelhttp.NewHandler(handlerFunc, pattern)
+		handler := otelhttp.NewHandler(clientMiddleware(handlerFunc), pattern)
 		mux.Handle(pattern, handler)
 	}
 
+	handleFunc(
+		"OPTIONS /v1/openapi.json",
+		ports.BuildCORSHandler(allowedOrigins),
+	)
+	openAPIHandler, stopOpenAPI := ports.MakeOpenAPIHandler(
+		allowedOrigins,
+		logger.With("port", "openapi"),
+		sentryMiddleware,
+		blocklistConfig,
+	)
+	handleFunc("GET /v1/openapi.json", openAPIHandler, stopOpenAPI)
+
 	prismNoticesHandler, stopPrismNotices := ports.MakePrismNoticesHandler(
 		getPrismNotices,
 		registerUserVisit,
@@ -304,6 +389,60 @@ func main() {
 	)
 	handleFunc("GET /v1/prism-notices", prismNoticesHandler, stopPrismNotices)
 
+	acknowledgePrismNoticeHandler, stopAcknowledgePrismNotice := ports.MakeAcknowledgePrismNoticeHandler(
+		app.BuildAcknowledgePrismNotice(prismNoticeRepo, time.Now),
+		logger.With("port", "prism-notices-ack"),
+		sentryMiddleware,
+		bearerAuthMiddleware,
+		blocklistConfig,
+	)
+	handleFunc("POST /v1/prism-notices/{id}/ack", acknowledgePrismNoticeHandler, stopAcknowledgePrismNotice)
+
+	adminListPrismNoticesHandler, stopAdminListPrismNotices := ports.MakeAdminListPrismNoticesHandler(
+		app.BuildListPrismNotices(prismNoticeRepo),
+		adminAuthMiddleware,
+		logger.With("port", "admin-prism-notices"),
+		sentryMiddleware,
+		blocklistConfig,
+	)
+	handleFunc("GET /v1/admin/prism-notices", adminListPrismNoticesHandler, stopAdminListPrismNotices)
+
+	adminCreatePrismNoticeHandler, stopAdminCreatePrismNotice := ports.MakeAdminCreatePrismNoticeHandler(
+		app.BuildCreatePrismNotice(prismNoticeRepo, prismNoticeCache, time.Now),
+		adminAuthMiddleware,
+		logger.With("port", "admin-prism-notices"),
+		sentryMiddleware,
+		blocklistConfig,
+	)
+	handleFunc("POST /v1/admin/prism-notices", adminCreatePrismNoticeHandler, stopAdminCreatePrismNotice)
+
+	adminGetPrismNoticeHandler, stopAdminGetPrismNotice := ports.MakeAdminGetPrismNoticeHandler(
+		app.BuildGetPrismNotice(prismNoticeRepo),
+		adminAuthMiddleware,
+		logger.With("port", "admin-prism-notices"),
+		sentryMiddleware,
+		blocklistConfig,
+	)
+	handleFunc("GET /v1/admin/prism-notices/{id}", adminGetPrismNoticeHandler, stopAdminGetPrismNotice)
+
+	adminUpdatePrismNoticeHandler, stopAdminUpdatePrismNotice := ports.MakeAdminUpdatePrismNoticeHandler(
+		app.BuildUpdatePrismNotice(prismNoticeRepo, prismNoticeCache, time.Now),
+		adminAuthMiddleware,
+		logger.With("port", "admin-prism-notices"),
+		sentryMiddleware,
+		blocklistConfig,
+	)
+	handleFunc("PUT /v1/admin/prism-notices/{id}", adminUpdatePrismNoticeHandler, stopAdminUpdatePrismNotice)
+
+	adminDeletePrismNoticeHandler, stopAdminDeletePrismNotice := ports.MakeAdminDeletePrismNoticeHandler(
+		app.BuildDeletePrismNotice(prismNoticeRepo, prismNoticeCache),
+		adminAuthMiddleware,
+		logger.With("port", "admin-prism-notices"),
+		sentryMiddleware,
+		blocklistConfig,
+	)
+	handleFunc("DELETE /v1/admin/prism-notices/{id}", adminDeletePrismNoticeHandler, stopAdminDeletePrismNotice)
+
 	playerDataHandler, stopPlayerData := ports.MakeGetPlayerDataHandler(
 		getAndPersistPlayerWithCache,
 		registerUserVisit,
@@ -397,6 +536,67 @@ func main() {
 	)
 	handleFunc("GET /v1/account/uuid/{uuid}", accountByUUIDHandler, stopAccountByUUID)
 
+	handleFunc(
+		"OPTIONS /v1/account/uuid/{uuid}/names",
+		ports.BuildCORSHandler(allowedOrigins),
+	)
+	usernameHistoryHandler, stopUsernameHistory := ports.MakeGetUsernameHistoryHandler(
+		getUsernameHistory,
+		registerUserVisit,
+		allowedOrigins,
+		logger.With("port", "getusernamehistory"),
+		sentryMiddleware,
+		bearerAuthMiddleware,
+		blocklistConfig,
+	)
+	handleFunc("GET /v1/account/uuid/{uuid}/names", usernameHistoryHandler, stopUsernameHistory)
+
+	handleFunc(
+		"OPTIONS /v1/account/username/{username}/owner",
+		ports.BuildCORSHandler(allowedOrigins),
+	)
+	usernameOwnerHandler, stopUsernameOwner := ports.MakeGetUsernameOwnerHandler(
+		getUsernameOwnerAt,
+		time.Now,
+		registerUserVisit,
+		allowedOrigins,
+		logger.With("port", "getusernameowner"),
+		sentryMiddleware,
+		bearerAuthMiddleware,
+		blocklistConfig,
+	)
+	handleFunc("GET /v1/account/username/{username}/owner", usernameOwnerHandler, stopUsernameOwner)
+
+	handleFunc(
+		"OPTIONS /v1/status/{uuid}",
+		ports.BuildCORSHandler(allowedOrigins),
+	)
+	statusHandler, stopStatus := ports.MakeGetStatusHandler(
+		getStatus,
+		registerUserVisit,
+		allowedOrigins,
+		logger.With("port", "getstatus"),
+		sentryMiddleware,
+		bearerAuthMiddleware,
+		blocklistConfig,
+	)
+	handleFunc("GET /v1/status/{uuid}", statusHandler, stopStatus)
+
+	handleFunc(
+		"OPTIONS /v1/guild/player/{uuid}",
+		ports.BuildCORSHandler(allowedOrigins),
+	)
+	guildHandler, stopGuild := ports.MakeGetGuildByPlayerHandler(
+		getGuildByPlayer,
+		registerUserVisit,
+		allowedOrigins,
+		logger.With("port", "getguildbyplayer"),
+		sentryMiddleware,
+		bearerAuthMiddleware,
+		blocklistConfig,
+	)
+	handleFunc("GET /v1/guild/player/{uuid}", guildHandler, stopGuild)
+
 	handleFunc(
 		"OPTIONS /v1/history",
 		ports.BuildCORSHandler(allowedOrigins),
@@ -443,6 +643,51 @@ func main() {
 	)
 	handleFunc("POST /v1/session-at", sessionAtHandler, stopSessionAt)
 
+	handleFunc(
+		"OPTIONS /v1/games",
+		ports.BuildCORSHandler(allowedOrigins),
+	)
+	gamesHandler, stopGames := ports.MakeGetGamesHandler(
+		getGames,
+		registerUserVisit,
+		allowedOrigins,
+		logger.With("port", "games"),
+		sentryMiddleware,
+		bearerAuthMiddleware,
+		blocklistConfig,
+	)
+	handleFunc("POST /v1/games", gamesHandler, stopGames)
+
+	handleFunc(
+		"OPTIONS /v1/winstreaks",
+		ports.BuildCORSHandler(allowedOrigins),
+	)
+	winstreaksHandler, stopWinstreaks := ports.MakeGetWinstreaksHandler(
+		getWinstreaks,
+		registerUserVisit,
+		allowedOrigins,
+		logger.With("port", "winstreaks"),
+		sentryMiddleware,
+		bearerAuthMiddleware,
+		blocklistConfig,
+	)
+	handleFunc("POST /v1/winstreaks", winstreaksHandler, stopWinstreaks)
+
+	handleFunc(
+		"OPTIONS /v1/compare",
+		ports.BuildCORSHandler(allowedOrigins),
+	)
+	compareHandler, stopCompare := ports.MakeCompareHandler(
+		compare,
+		registerUserVisit,
+		allowedOrigins,
+		logger.With("port", "compare"),
+		sentryMiddleware,
+		bearerAuthMiddleware,
+		blocklistConfig,
+	)
+	handleFunc("POST /v1/compare", compareHandler, stopCompare)
+
 	handleFunc(
 		"OPTIONS /v1/prestiges/{uuid}",
 		ports.BuildCORSHandler(allowedOrigins),
@@ -457,13 +702,42 @@ func main() {
 	)
 	handleFunc("GET /v1/prestiges/{uuid}", prestigesHandler, stopPrestiges)
 
+	handleFunc(
+		"OPTIONS /v1/prestiges/{uuid}/forecast",
+		ports.BuildCORSHandler(allowedOrigins),
+	)
+	prestigeForecastHandler, stopPrestigeForecast := ports.MakeGetPrestigeForecastHandler(
+		forecastPrestige,
+		registerUserVisit,
+		allowedOrigins,
+		logger.With("port", "prestige-forecast"),
+		sentryMiddleware,
+		blocklistConfig,
+	)
+	handleFunc("GET /v1/prestiges/{uuid}/forecast", prestigeForecastHandler, stopPrestigeForecast)
+
+	handleFunc(
+		"OPTIONS /v1/milestones/{uuid}",
+		ports.BuildCORSHandler(allowedOrigins),
+	)
+	milestonesHandler, stopMilestones := ports.MakeGetMilestonesHandler(
+		findMilestoneAchievements,
+		registerUserVisit,
+		allowedOrigins,
+		logger.With("port", "milestones"),
+		sentryMiddleware,
+		blocklistConfig,
+	)
+	handleFunc("GET /v1/milestones/{uuid}", milestonesHandler, stopMilestones)
+
 	handleFunc(
 		"OPTIONS /v1/wrapped/{uuid}/{year}",
 		ports.BuildCORSHandler(allowedOrigins),
 	)
+	wrappedRepo := wrappedrepository.NewPostgres(db, repositorySchemaName)
+	getWrapped := app.BuildGetWrapped(getPlayerPITs, computeSessions, wrappedRepo, time.Now)
 	wrappedHandler, stopWrapped := ports.MakeGetWrappedHandler(
-		getPlayerPITs,
-		computeSessions,
+		getWrapped,
 		registerUserVisit,
 		allowedOrigins,
 		logger.With("port", "wrapped"),
@@ -473,6 +747,22 @@ func main() {
 	)
 	handleFunc("GET /v1/wrapped/{uuid}/{year}", wrappedHandler, stopWrapped)
 
+	handleFunc(
+		"OPTIONS /v1/wrapped/{uuid}",
+		ports.BuildCORSHandler(allowedOrigins),
+	)
+	wrappedPeriodHandler, stopWrappedPeriod := ports.MakeGetWrappedPeriodHandler(
+		getPlayerPITs,
+		computeSessions,
+		registerUserVisit,
+		allowedOrigins,
+		logger.With("port", "wrapped-period"),
+		sentryMiddleware,
+		bearerAuthMiddleware,
+		blocklistConfig,
+	)
+	handleFunc("GET /v1/wrapped/{uuid}", wrappedPeriodHandler, stopWrappedPeriod)
+
 	// TODO: Remove deprecated non-versioned endpoint. Hits are logged with a
 	// "Deprecated endpoint hit" WARN so we can confirm it's safe to drop.
 	legacyPlayerDataHandler, stopLegacyPlayerData := ports.MakeGetPlayerDataHandler(
@@ -543,7 +833,8 @@ func main() {
 		}
 	}
 
-	// 2. Stop the background rate-limiter eviction goroutines.
+	// 2. Stop the background rate-limiter eviction and prism release refresh
+	//    goroutines.
 	for _, stop := range handlerStops {
 		stop()
 	}